@@ -0,0 +1,375 @@
+// Command clientgen generates the typed Go client in pkg/client from the
+// OpenAPI spec in api/openapi.json. It covers the subset of OpenAPI the spec
+// uses: object schemas, arrays, $ref, allOf embedding, nullable fields, and
+// GET operations with path and query parameters.
+//
+//	go run ./cmd/clientgen -spec api/openapi.json -out pkg/client/client_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// spec is the part of an OpenAPI 3 document the generator reads
+type spec struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Nullable    bool               `json:"nullable"`
+	Items       *schema            `json:"items"`
+	Properties  map[string]*schema `json:"properties"`
+	AllOf       []*schema          `json:"allOf"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Schema      *schema `json:"schema"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []parameter `json:"parameters"`
+	Responses   map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+
+	path   string
+	method string
+}
+
+// initialisms are written in capitals in Go names
+var initialisms = map[string]string{"id": "ID", "url": "URL", "api": "API", "json": "JSON"}
+
+func main() {
+	specPath := flag.String("spec", "api/openapi.json", "OpenAPI spec to read")
+	out := flag.String("out", "pkg/client/client_gen.go", "Go file to write")
+	pkg := flag.String("package", "client", "package name of the generated file")
+	flag.Parse()
+
+	raw, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to read spec: %v", err)
+	}
+	var s spec
+	if err := json.Unmarshal(raw, &s); err != nil {
+		log.Fatalf("❌ Failed to parse spec: %v", err)
+	}
+
+	src, err := generate(&s, *pkg)
+	if err != nil {
+		log.Fatalf("❌ Failed to generate client: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("❌ Failed to write client: %v", err)
+	}
+	log.Printf("✅ Wrote %s", *out)
+}
+
+func generate(s *spec, pkg string) ([]byte, error) {
+	var body bytes.Buffer
+
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeModel(&body, name, s.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	var ops []*operation
+	for path, methods := range s.Paths {
+		for method, op := range methods {
+			if method != "get" {
+				return nil, fmt.Errorf("%s %s: only GET operations are supported", strings.ToUpper(method), path)
+			}
+			op.path, op.method = path, method
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	for _, op := range ops {
+		if err := writeOperation(&body, op); err != nil {
+			return nil, fmt.Errorf("operation %s: %w", op.OperationID, err)
+		}
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by clientgen from %s %s. DO NOT EDIT.\n\n", s.Info.Title, s.Info.Version)
+	fmt.Fprintf(&file, "package %s\n\nimport (\n\t\"context\"\n", pkg)
+	for _, imp := range []string{"encoding/json", "net/url", "strconv", "time"} {
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if bytes.Contains(body.Bytes(), []byte(name+".")) {
+			fmt.Fprintf(&file, "\t%q\n", imp)
+		}
+	}
+	file.WriteString(")\n")
+	file.Write(body.Bytes())
+
+	return format.Source(file.Bytes())
+}
+
+func writeModel(w *bytes.Buffer, name string, s *schema) error {
+	writeComment(w, name, s.Description)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	parts := []*schema{s}
+	if len(s.AllOf) > 0 {
+		parts = s.AllOf
+	}
+	for _, part := range parts {
+		if part.Ref != "" {
+			fmt.Fprintf(w, "\t%s\n", refName(part.Ref))
+			continue
+		}
+		if part.Type != "object" {
+			return fmt.Errorf("only object schemas can be models")
+		}
+		props := make([]string, 0, len(part.Properties))
+		for prop := range part.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		for _, prop := range props {
+			p := part.Properties[prop]
+			typ, err := goType(p)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", prop, err)
+			}
+			if p.Description != "" {
+				fmt.Fprintf(w, "\t// %s\n", p.Description)
+			}
+			fmt.Fprintf(w, "\t%s %s `json:\"%s\"`\n", goName(prop), typ, prop)
+		}
+	}
+	w.WriteString("}\n\n")
+	return nil
+}
+
+func writeOperation(w *bytes.Buffer, op *operation) error {
+	name := goName(op.OperationID)
+	ok, found := op.Responses["200"]
+	if !found || ok.Content["application/json"].Schema == nil {
+		return fmt.Errorf("no JSON 200 response")
+	}
+	result, err := goType(ok.Content["application/json"].Schema)
+	if err != nil {
+		return err
+	}
+	// Single objects are returned by pointer, lists as slices
+	result = strings.TrimPrefix(result, "*")
+	ret, out := result, "out"
+	if !strings.HasPrefix(result, "[]") {
+		ret, out = "*"+result, "&out"
+	}
+
+	var args, query []parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			args = append(args, p)
+		case "query":
+			query = append(query, p)
+		default:
+			return fmt.Errorf("parameter %s: unsupported location %q", p.Name, p.In)
+		}
+	}
+
+	if len(query) > 0 {
+		fmt.Fprintf(w, "// %sParams holds the optional query parameters of %s.\n// Zero values are left out so the server defaults apply.\n", name, name)
+		fmt.Fprintf(w, "type %sParams struct {\n", name)
+		for _, p := range query {
+			typ, err := paramType(p.Schema)
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", p.Name, err)
+			}
+			if p.Description != "" {
+				fmt.Fprintf(w, "\t// %s\n", p.Description)
+			}
+			fmt.Fprintf(w, "\t%s %s\n", goName(p.Name), typ)
+		}
+		w.WriteString("}\n\n")
+	}
+
+	summary := strings.TrimSuffix(op.Summary, ".")
+	if summary != "" {
+		summary = strings.ToLower(summary[:1]) + summary[1:]
+	}
+	fmt.Fprintf(w, "// %s %s.\n//\n// %s %s\n", name, summary, strings.ToUpper(op.method), op.path)
+
+	sig := []string{"ctx context.Context"}
+	for _, p := range args {
+		sig = append(sig, lowerName(p.Name)+" string")
+	}
+	if len(query) > 0 {
+		sig = append(sig, "params *"+name+"Params")
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(sig, ", "), ret)
+
+	path := "\"" + op.path + "\""
+	for _, p := range args {
+		path = strings.Replace(path, "{"+p.Name+"}", "\" + url.PathEscape("+lowerName(p.Name)+") + \"", 1)
+	}
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "\"\" + "), " + \"\"")
+
+	queryArg := "nil"
+	if len(query) > 0 {
+		queryArg = "query"
+		w.WriteString("\tquery := url.Values{}\n\tif params != nil {\n")
+		for _, p := range query {
+			field := "params." + goName(p.Name)
+			switch p.Schema.Type {
+			case "integer":
+				fmt.Fprintf(w, "\t\tif %s != 0 {\n\t\t\tquery.Set(%q, strconv.Itoa(%s))\n\t\t}\n", field, p.Name, field)
+			case "boolean":
+				fmt.Fprintf(w, "\t\tif %s != nil {\n\t\t\tquery.Set(%q, strconv.FormatBool(*%s))\n\t\t}\n", field, p.Name, field)
+			default:
+				fmt.Fprintf(w, "\t\tif %s != \"\" {\n\t\t\tquery.Set(%q, %s)\n\t\t}\n", field, p.Name, field)
+			}
+		}
+		w.WriteString("\t}\n")
+	}
+
+	fmt.Fprintf(w, "\tvar out %s\n\tif err := c.get(ctx, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn %s, nil\n}\n\n", result, path, queryArg, out)
+	return nil
+}
+
+// goType maps a schema to a Go type. Nullable scalars and objects become
+// pointers; free-form objects are kept as raw JSON.
+func goType(s *schema) (string, error) {
+	ptr := ""
+	if s.Nullable {
+		ptr = "*"
+	}
+	if s.Ref != "" {
+		return ptr + refName(s.Ref), nil
+	}
+	switch s.Type {
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if len(s.Properties) == 0 {
+			return "json.RawMessage", nil
+		}
+		return "", fmt.Errorf("inline object schemas are not supported; move it to components")
+	case "string":
+		if s.Format == "date-time" {
+			return ptr + "time.Time", nil
+		}
+		return ptr + "string", nil
+	case "integer":
+		if s.Format == "int64" {
+			return ptr + "int64", nil
+		}
+		return ptr + "int", nil
+	case "number":
+		return ptr + "float64", nil
+	case "boolean":
+		return ptr + "bool", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// paramType maps a query parameter to a Go type. Booleans are pointers so
+// false can be told apart from unset.
+func paramType(s *schema) (string, error) {
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "boolean":
+		return "*bool", nil
+	}
+	return "", fmt.Errorf("unsupported query parameter type %q", s.Type)
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// goName turns snake_case and camelCase names into exported Go names
+func goName(name string) string {
+	var words []string
+	var cur []rune
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-':
+			words = append(words, string(cur))
+			cur = nil
+		case unicode.IsUpper(r) && len(cur) > 0:
+			words = append(words, string(cur))
+			cur = []rune{r}
+		default:
+			cur = append(cur, r)
+		}
+	}
+	words = append(words, string(cur))
+
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if up, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// lowerName turns a parameter name into an unexported Go identifier
+func lowerName(name string) string {
+	n := goName(name)
+	if up, ok := initialisms[strings.ToLower(name)]; ok && n == up {
+		return strings.ToLower(n)
+	}
+	return strings.ToLower(n[:1]) + n[1:]
+}
+
+// writeComment writes a model's doc comment from its description, which
+// reads as a noun phrase ("A trading signal")
+func writeComment(w *bytes.Buffer, name, description string) {
+	if description == "" {
+		fmt.Fprintf(w, "// %s is the %s schema\n", name, name)
+		return
+	}
+	d := strings.TrimSuffix(description, ".")
+	fmt.Fprintf(w, "// %s is %s\n", name, strings.ToLower(d[:1])+d[1:])
+}
@@ -1,20 +1,126 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/aggregates"
+	"github.com/trading-chitti/core-api-go/internal/approvals"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/baskets"
+	"github.com/trading-chitti/core-api-go/internal/buildinfo"
+	"github.com/trading-chitti/core-api-go/internal/chaos"
+	"github.com/trading-chitti/core-api-go/internal/compat"
 	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/dataquality"
+	"github.com/trading-chitti/core-api-go/internal/dbstats"
+	"github.com/trading-chitti/core-api-go/internal/environment"
 	"github.com/trading-chitti/core-api-go/internal/events"
+	"github.com/trading-chitti/core-api-go/internal/exports"
+	"github.com/trading-chitti/core-api-go/internal/guest"
 	"github.com/trading-chitti/core-api-go/internal/handlers"
+	"github.com/trading-chitti/core-api-go/internal/health"
+	"github.com/trading-chitti/core-api-go/internal/loadtest"
+	"github.com/trading-chitti/core-api-go/internal/maintenance"
+	"github.com/trading-chitti/core-api-go/internal/movers"
+	"github.com/trading-chitti/core-api-go/internal/newsbrief"
+	"github.com/trading-chitti/core-api-go/internal/newscluster"
+	"github.com/trading-chitti/core-api-go/internal/newsfeeds"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	"github.com/trading-chitti/core-api-go/internal/prices"
+	"github.com/trading-chitti/core-api-go/internal/proximity"
+	"github.com/trading-chitti/core-api-go/internal/push"
+	"github.com/trading-chitti/core-api-go/internal/reconciliation"
+	"github.com/trading-chitti/core-api-go/internal/regime"
+	"github.com/trading-chitti/core-api-go/internal/replay"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+	"github.com/trading-chitti/core-api-go/internal/retention"
+	"github.com/trading-chitti/core-api-go/internal/risk"
+	"github.com/trading-chitti/core-api-go/internal/scans"
+	"github.com/trading-chitti/core-api-go/internal/sentiment"
+	"github.com/trading-chitti/core-api-go/internal/serviceauth"
+	"github.com/trading-chitti/core-api-go/internal/settings"
+	"github.com/trading-chitti/core-api-go/internal/share"
+	"github.com/trading-chitti/core-api-go/internal/signalnews"
+	"github.com/trading-chitti/core-api-go/internal/status"
+	"github.com/trading-chitti/core-api-go/internal/stockhistory"
+	"github.com/trading-chitti/core-api-go/internal/storage"
+	"github.com/trading-chitti/core-api-go/internal/timeline"
+	"github.com/trading-chitti/core-api-go/internal/twoperson"
+	"github.com/trading-chitti/core-api-go/internal/usage"
 	"github.com/trading-chitti/core-api-go/internal/websocket"
 )
 
 func main() {
-	log.Println("🚀 Starting Core API Go service...")
+	// "core-api seed [--force]" loads demo data and exits; SEED_DEMO_DATA=true
+	// does the same on startup and keeps serving
+	seedMode := len(os.Args) > 1 && os.Args[1] == "seed"
+	seedForce := false
+	if seedMode {
+		seedFlags := flag.NewFlagSet("seed", flag.ExitOnError)
+		seedFlags.BoolVar(&seedForce, "force", false, "seed even if stock configs already exist")
+		seedFlags.Parse(os.Args[2:])
+	}
+	seedDemo := seedMode || os.Getenv("SEED_DEMO_DATA") == "true"
+
+	// Internal endpoints identify calling services by tokens signed with
+	// SERVICE_AUTH_SECRET; "core-api service-token <name> [--ttl 720h]"
+	// prints one and exits
+	serviceAuth, err := serviceauth.FromEnv()
+	if err != nil {
+		log.Fatalf("❌ Service auth configuration invalid: %v", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "service-token" {
+		tokenFlags := flag.NewFlagSet("service-token", flag.ExitOnError)
+		ttl := tokenFlags.Duration("ttl", 30*24*time.Hour, "how long the token is valid")
+		// The flag package stops at the first positional argument, so keep
+		// parsing after it to accept --ttl on either side of the name
+		var names []string
+		for args := os.Args[2:]; ; args = tokenFlags.Args()[1:] {
+			tokenFlags.Parse(args)
+			if tokenFlags.NArg() == 0 {
+				break
+			}
+			names = append(names, tokenFlags.Arg(0))
+		}
+		if len(names) != 1 {
+			log.Fatalf("❌ Usage: core-api service-token <name> [--ttl 720h]")
+		}
+		token, err := serviceAuth.Token(names[0], time.Now().Add(*ttl))
+		if err != nil {
+			log.Fatalf("❌ Service token not issued: %v", err)
+		}
+		fmt.Println(token)
+		return
+	}
+	if serviceAuth.Enabled() {
+		log.Printf("✅ Service auth: %d services granted permissions", serviceAuth.Services())
+	}
+
+	build := buildinfo.Get()
+	log.Printf("🚀 Starting Core API Go service %s (commit %s, built %s, %s)...",
+		build.Version, buildinfo.ShortCommit(), build.BuildTime, build.GoVersion)
+
+	// ENVIRONMENT (dev/staging/prod) is reported to clients and decides the
+	// guardrails: demo seeding and test helpers are dev-only
+	env := environment.FromEnv()
+	log.Printf("✅ Environment: %s", env.Name)
+	if seedDemo && !env.TestHelpersEnabled() {
+		if seedMode {
+			log.Fatalf("❌ Demo data can only be seeded in %s, not %s", environment.Dev, env.Name)
+		}
+		log.Printf("⚠️  SEED_DEMO_DATA ignored in %s", env.Name)
+		seedDemo = false
+	}
 
 	// Get database DSN from environment
 	dsn := os.Getenv("TRADING_CHITTI_PG_DSN")
@@ -23,14 +129,100 @@ func main() {
 	}
 
 	// Connect to database
-	db, err := database.NewDB(dsn)
+	db, err := database.NewDB(dsn, database.TimeoutPolicyFromEnv())
 	if err != nil {
 		log.Fatalf("❌ Database connection failed: %v", err)
 	}
 	defer db.Close()
 
+	// Optional TimescaleDB hypertables, compression and time_bucket candles
+	db.SetTimescale(os.Getenv("TIMESCALEDB_ENABLED") == "true")
+
+	// Apply service-owned schema migrations; converting existing tick data to a
+	// hypertable can take a while, so allow longer when Timescale is enabled
+	migrateTimeout := 30 * time.Second
+	if db.TimescaleEnabled() {
+		migrateTimeout = 30 * time.Minute
+		log.Println("✅ TimescaleDB support enabled")
+	}
+	migrateCtx, migrateCancel := context.WithTimeout(context.Background(), migrateTimeout)
+	// Standalone runs have no upstream services to create the shared tables,
+	// and the migrations reference some of them
+	if seedDemo {
+		if err := db.EnsureDevSchema(migrateCtx); err != nil {
+			log.Fatalf("❌ Dev schema setup failed: %v", err)
+		}
+	}
+	if err := db.Migrate(migrateCtx); err != nil {
+		log.Fatalf("❌ Database migration failed: %v", err)
+	}
+	migrateCancel()
+
+	if seedDemo {
+		seedCtx, seedCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		err := db.SeedDemoData(seedCtx, seedForce)
+		seedCancel()
+		if err != nil {
+			log.Fatalf("❌ Demo data seeding failed: %v", err)
+		}
+		if seedMode {
+			return
+		}
+	}
+
+	// Object storage for exports, reports and model artifacts
+	store, err := storage.New(storage.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("❌ Storage configuration invalid: %v", err)
+	}
+	log.Printf("✅ Object storage: %s", store.Name())
+
+	// Expire old exports and reports; export jobs are flagged once their files are gone
+	lifecycle := storage.NewLifecycle(store, storage.LifecycleRulesFromEnv())
+	lifecycle.OnExpire(storage.PrefixExports, func(ctx context.Context, before time.Time) error {
+		_, err := db.ExpireExportJobs(ctx, before)
+		return err
+	})
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go lifecycle.Run(workerCtx, time.Hour)
+
+	// Service-managed 1m -> 5m -> daily bar roll-ups
+	maintainer := aggregates.NewMaintainer(db)
+	go maintainer.Run(workerCtx)
+
+	// Daily retention pass; dry run unless RETENTION_ENFORCE=true
+	retentionManager := retention.NewManager(db, store, retention.ConfigFromEnv())
+	go retentionManager.Run(workerCtx)
+
 	// Create WebSocket hub
 	hub := websocket.NewHub()
+	if err := hub.SetHello(map[string]interface{}{
+		"type":        "hello",
+		"service":     "core-api-go",
+		"environment": env.Info(),
+		"topics":      websocket.Topics(),
+	}); err != nil {
+		log.Fatalf("❌ WebSocket hello invalid: %v", err)
+	}
+
+	// Fault injection for resilience drills: always available in dev,
+	// elsewhere only with CHAOS_ENABLED=true (and prod-admin in prod)
+	chaosController := chaos.NewController(env.TestHelpersEnabled() || os.Getenv("CHAOS_ENABLED") == "true")
+	if chaosController.Enabled() {
+		log.Println("⚠️  Fault injection enabled: drills can be started via /api/system/chaos")
+	}
+	hub.SetDropFilter(chaosController.DropWebSocket)
+
+	// API calls, WebSocket messages and exports are metered per account
+	// against soft daily quotas
+	usageMeter := usage.NewMeter(db)
+	hub.SetMeter(usageMeter.CountWSMessages)
+	meterDone := make(chan struct{})
+	go func() {
+		usageMeter.Run(workerCtx)
+		close(meterDone)
+	}()
 	go hub.Run()
 	log.Println("✅ WebSocket hub started")
 
@@ -40,34 +232,407 @@ func main() {
 		natsURL = "nats://localhost:4222"
 	}
 
+	// Top gainers/losers are kept in memory from market.tick and reconciled
+	// with md.realtime_prices every minute
+	moversCache := movers.NewCache(db)
+	go moversCache.Run(workerCtx)
+
+	// Latest prices from market.tick overlay md.realtime_prices when the
+	// bridge's writes lag (PRICE_SNAPSHOTS=off|memory|persist)
+	priceStore := prices.NewStore(db, prices.ConfigFromEnv())
+	go priceStore.Run(workerCtx)
+
+	// Reports and notifications are delivered by email (SMTP_*) and Telegram
+	// (TELEGRAM_BOT_TOKEN) when those channels are configured
+	senders := reports.SendersFromEnv()
+
+	// Alert and signal notifications honour each user's quiet hours and
+	// digest settings; the mobile app gets them as pushes through FCM
+	// (FCM_CREDENTIALS_FILE) and APNs (APNS_*) when configured
+	notifier := notifications.NewNotifier(db, db, senders, push.SendersFromEnv())
+	go notifier.Run(workerCtx)
+
+	// Active signals are checked against every tick and clients are told
+	// when one comes within PROXIMITY_BAND_PCT of its target or stop
+	proximityBand := proximity.BandFromEnv()
+	proximityWatcher := proximity.NewWatcher(db, hub, notifier, proximityBand)
+	go proximityWatcher.Run(workerCtx)
+
+	// Opening-range and gap scans are cached and kept warm during the session
+	scanService := scans.NewService(db)
+	go scanService.Run(workerCtx)
+
+	// Basket alerts fire over WebSocket and notifications once their
+	// condition holds
+	basketAlerts := baskets.NewAlertMonitor(db, hub, notifier)
+	go basketAlerts.Run(workerCtx)
+
+	// Report subscriptions go out on their schedules
+	reportScheduler := reports.NewScheduler(db, senders)
+	go reportScheduler.Run(workerCtx)
+
+	// Optional in-process RSS ingestion (NEWS_INGEST_ENABLED); feeds can be
+	// managed and polled on demand either way
+	newsIngester := newsfeeds.NewIngester(db)
+	go newsIngester.Run(workerCtx)
+
+	// Groups near-duplicate articles so GetNews returns one per story
+	go newscluster.NewClusterer(db).Run(workerCtx)
+
+	// New signals get the high-impact news that preceded them linked into
+	// their metadata
+	signalNewsLinker := signalnews.NewLinker(db)
+	go signalNewsLinker.Run(workerCtx)
+
+	// Daily snapshot of the stock config, which smart selection rewrites
+	// every morning (STOCK_CONFIG_SNAPSHOT_TIME)
+	go stockhistory.NewSnapshotter(db).Run(workerCtx)
+
+	// Nightly check of realtime closes against the bhavcopy
+	// (RECONCILE_TIME, RECONCILE_TOLERANCE_PCT)
+	go reconciliation.NewJob(db, hub, notifier).Run(workerCtx)
+
+	// Sentiment scoring for articles without llm_sentiment (SENTIMENT_BACKEND)
+	sentimentPipeline := sentiment.NewPipeline(db, sentiment.ConfigFromEnv())
+	go sentimentPipeline.Run(workerCtx)
+
+	// Signals sized above APPROVAL_THRESHOLD are held for review; outcomes
+	// go out on signal.approval for the execution layer
+	approvalReviewer := approvals.NewReviewer(db, approvals.ConfigFromEnv())
+
+	// Every signal.* event is kept as an ordered timeline per signal
+	timelineRecorder := timeline.NewRecorder(db)
+	go timelineRecorder.Run(workerCtx)
+
+	// Ticks and collector prices failing sanity checks (DQ_*) are
+	// quarantined instead of reaching clients, prices and signal monitors
+	qualityEngine := dataquality.NewEngine(dataquality.ConfigFromEnv())
+
+	// Dev-only synthetic event generator (LOADTEST_ENABLED) and on-demand
+	// engine/bridge commands use the same connection
+	var generator *loadtest.Generator
+	var natsRequester handlers.Requester
+	var decodeStats handlers.DecodeStatsProvider
+	var reprocessor handlers.Reprocessor
+	var eventStats handlers.EventStatsProvider
+	var tickQuality handlers.TickQualityProvider
+	var eventPublisher handlers.EventPublisher
 	subscriber, err := events.NewSubscriber(natsURL, hub)
 	if err != nil {
 		log.Printf("⚠️  NATS connection failed, events disabled: %v", err)
+		generator = loadtest.NewGenerator(nil)
 	} else {
 		defer subscriber.Close()
+		subscriber.OnTick(moversCache.HandleTick)
+		subscriber.OnTick(proximityWatcher.HandleTick)
+		subscriber.OnSignal(notifier.HandleSignal)
+		subscriber.OnSignal(approvalReviewer.HandleSignal)
+		subscriber.OnSignal(timelineRecorder.HandleSignal)
+		approvalReviewer.SetPublisher(subscriber)
+		if priceStore.Enabled() {
+			subscriber.OnTick(priceStore.HandleTick)
+		}
+		subscriber.SetDeadLetterSink(db)
+		subscriber.SetOutageSimulator(chaosController)
+		subscriber.SetTickGate(qualityEngine)
 		if err := subscriber.Subscribe(); err != nil {
 			log.Printf("⚠️  NATS subscription failed, continuing without events: %v", err)
 		}
+		generator = loadtest.NewGenerator(subscriber)
+		natsRequester = subscriber
+		decodeStats = subscriber
+		reprocessor = subscriber
+		eventStats = subscriber
+		tickQuality = subscriber
+		eventPublisher = subscriber
+	}
+
+	// Market regime is reclassified every minute; changes go out as
+	// regime_changed over WebSocket and regime.changed on NATS
+	regimeTracker := regime.NewTracker(db, hub, eventPublisher, regime.IndexFromEnv())
+	go regimeTracker.Run(workerCtx)
+
+	// Intraday risk snapshots (RISK_SNAPSHOT_MINUTES, RISK_DAILY_LOSS_LIMIT)
+	// are stored and go out as risk_snapshot over WebSocket and
+	// risk.snapshot on NATS
+	riskMonitor := risk.NewMonitor(db, hub, eventPublisher, risk.ConfigFromEnv())
+	go riskMonitor.Run(workerCtx)
+
+	go approvalReviewer.Run(workerCtx)
+
+	// Service health is checked in the background; the monitor endpoints
+	// serve the latest results
+	healthChecker := health.NewChecker(db.Ping)
+	go healthChecker.Run(workerCtx)
+
+	// Public status page: components are checked every minute and only the
+	// roll-up is exposed
+	realtimeCheck := status.Static(status.Outage)
+	if subscriber != nil {
+		realtimeCheck = func(context.Context) string {
+			if subscriber.Connected() {
+				return status.Operational
+			}
+			return status.Outage
+		}
+	}
+	statusMonitor := status.NewMonitor(db, []status.Component{
+		{ID: "api", Name: "API", Check: status.Static(status.Operational)},
+		{ID: "database", Name: "Database", Check: status.PingCheck(db.Ping)},
+		{ID: "market-data", Name: "Market data", Check: status.HTTPCheck(health.ServiceURL("market-bridge"))},
+		{ID: "signals", Name: "Signals", Check: status.HTTPCheck(health.ServiceURL("intraday-engine"))},
+		{ID: "news", Name: "News", Check: status.HTTPCheck(health.ServiceURL("news-nlp"))},
+		{ID: "realtime", Name: "Real-time updates", Check: realtimeCheck},
+	})
+	go statusMonitor.Run(workerCtx)
+
+	if generator.Enabled() && env.TestHelpersEnabled() {
+		log.Println("⚠️  Load test mode enabled: synthetic events can be started via /api/system/loadtest")
+	}
+
+	// Dev-only replay of recorded market days over WebSocket (REPLAY_ENABLED)
+	replayPlayer := replay.NewPlayer(db, hub)
+	if replayPlayer.Enabled() && env.TestHelpersEnabled() {
+		log.Println("⚠️  Replay mode enabled: recorded days can be replayed via /api/system/replay")
+	}
+
+	// Get port from environment
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "6001"
 	}
 
 	// Create HTTP handlers
-	handler := handlers.NewHandler(db, hub)
-	monitoringHandler := handlers.NewMonitoringHandler(db.GetConn())
-	quantHandler := handlers.NewQuantAnalyticsHandler(db.GetConn())
-	systemHandler := handlers.NewSystemHandler(db.GetConn())
+	handler := handlers.NewHandler(db, hub, moversCache, priceStore, env, healthChecker)
+	monitoringHandler := handlers.NewMonitoringHandler(db.GetConn(), healthChecker)
+	quantHandler := handlers.NewQuantAnalyticsHandler(db.AnalyticsConn())
+	systemHandler := handlers.NewSystemHandler(db.GetConn(), store)
+	graphqlHandler := handlers.NewGraphQLHandler(db)
+	exportManager := exports.NewManager(db, store)
+	exportManager.OnComplete(usageMeter.CountExport)
+	exportCtx, exportCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := exportManager.FailInterrupted(exportCtx); err != nil {
+		log.Printf("⚠️  Failed to clean up interrupted export jobs: %v", err)
+	}
+	exportCancel()
+	exportsHandler := handlers.NewExportsHandler(db, exportManager)
+	storageHandler := handlers.NewStorageHandler(store, lifecycle, env, serviceAuth)
+	aggregatesHandler := handlers.NewAggregatesHandler(db, maintainer)
+	retentionHandler := handlers.NewRetentionHandler(db, retentionManager)
+	loadTestHandler := handlers.NewLoadTestHandler(generator, hub)
+	replayHandler := handlers.NewReplayHandler(replayPlayer)
+	dbStatsTracker := dbstats.NewTracker()
+	dbStatsHandler := handlers.NewDBStatsHandler(dbStatsTracker)
+	databaseHandler := handlers.NewDatabaseHandler(db)
+	engineHandler := handlers.NewEngineHandler(natsRequester)
+	schemasHandler := handlers.NewSchemasHandler(decodeStats)
+	deadLetterHandler := handlers.NewDeadLetterHandler(db, reprocessor)
+	eventsHandler := handlers.NewEventsHandler(eventStats, hub)
+	tickQualityHandler := handlers.NewTickQualityHandler(tickQuality)
+	dataQualityHandler := handlers.NewDataQualityHandler(qualityEngine)
+	proximityHandler := handlers.NewProximityHandler(db, priceStore, proximityBand)
+	signalExecutionHandler := handlers.NewSignalExecutionHandler(db, eventPublisher)
+	signalIngestHandler := handlers.NewSignalIngestHandler(db, eventPublisher)
+	internalPricesHandler := handlers.NewInternalPricesHandler(db, qualityEngine)
+	tradingViewHandler := handlers.NewTradingViewHandler(db, db, notifier, eventPublisher, env)
+	inboundWebhookHandler := handlers.NewInboundWebhookHandler(db, db, notifier, eventPublisher)
+	assistantHandler := handlers.NewAssistantHandler(db, regimeTracker)
+	signalCalendarHandler := handlers.NewSignalCalendarHandler(db)
+	signalFillHandler := handlers.NewSignalFillHandler(db, db)
+	signalTimelineHandler := handlers.NewSignalTimelineHandler(db, db)
+
+	// Runtime settings in md.system_config are typed and validated, and
+	// changes go out on config.setting.changed
+	settingsRegistry := settings.NewRegistry(db, eventPublisher)
+	handlers.SmartSelectionHooks(settingsRegistry, db)
+	// Live settings are re-read every CONFIG_POLL_INTERVAL, on SIGHUP and
+	// after a write, and applied without a restart
+	configWatcher := settings.NewWatcher(settingsRegistry, settings.PollIntervalFromEnv())
+	settingsHandler := handlers.NewSettingsHandler(settingsRegistry, configWatcher)
+	signalApprovalHandler := handlers.NewSignalApprovalHandler(db, approvalReviewer)
+	// Deleted ingested and manual signals can be restored for
+	// SIGNAL_RESTORE_DAYS (default 7)
+	restoreDays, err := strconv.Atoi(os.Getenv("SIGNAL_RESTORE_DAYS"))
+	if err != nil || restoreDays < 1 {
+		restoreDays = 7
+	}
+	stockConfigHistoryHandler := handlers.NewStockConfigHistoryHandler(db)
+	reconciliationHandler := handlers.NewReconciliationHandler(db)
+	coverageHandler := handlers.NewCoverageHandler(db)
+	signalTombstoneHandler := handlers.NewSignalTombstoneHandler(db, eventPublisher, time.Duration(restoreDays)*24*time.Hour)
+	// Destructive admin actions in TWO_PERSON_ACTIONS need a second admin
+	confirmations := handlers.NewAdminConfirmationHandler(db, twoperson.FromEnv())
+	exposureHandler := handlers.NewExposureHandler(db)
+	positionSizeHandler := handlers.NewPositionSizeHandler(db, db)
+	stressHandler := handlers.NewStressHandler(db)
+	riskHandler := handlers.NewRiskHandler(db, riskMonitor)
+	scansHandler := handlers.NewScansHandler(scanService)
+	basketsHandler := handlers.NewBasketsHandler(db)
+	presetsHandler := handlers.NewPresetsHandler(db)
+	// Portfolios, baskets, presets, journal and watchlists belong to the
+	// account a request acts in (X-Account-ID, else the user's own)
+	accountScope := handlers.NewAccountScope(db)
+	accountsHandler := handlers.NewAccountsHandler(db, accountScope)
+	usageHandler := handlers.NewUsageHandler(db, usageMeter)
+	guestIssuer := guest.IssuerFromEnv()
+	// Failed and suspicious auth attempts are recorded and operators
+	// notified; repeat offenders are locked out
+	authGuard := authguard.NewGuard(db, authguard.ConfigFromEnv())
+	authGuard.OnEvent(notifier.HandleAuthEvent)
+	go authGuard.Run(workerCtx)
+	authSecurityHandler := handlers.NewAuthSecurityHandler(db, authGuard)
+	guestHandler := handlers.NewGuestHandler(guestIssuer)
+	regimeHandler := handlers.NewRegimeHandler(regimeTracker)
+	journalHandler := handlers.NewJournalHandler(db)
+	reportsHandler := handlers.NewReportsHandler(db, reportScheduler)
+	notificationsHandler := handlers.NewNotificationsHandler(db, notifier)
+	pushDevicesHandler := handlers.NewPushDevicesHandler(db, notifier)
+	shareHandler := handlers.NewShareHandler(db, db, db, share.SignerFromEnv())
+	widgetsHandler := handlers.NewWidgetsHandler(db, moversCache, regimeTracker)
+	calendarHandler := handlers.NewCalendarHandler(db)
+	statusPageHandler := handlers.NewStatusPageHandler(statusMonitor)
+	var natsConnected func() bool
+	if subscriber != nil {
+		natsConnected = subscriber.Connected
+	}
+	corePort, _ := strconv.Atoi(port)
+	topologyHandler := handlers.NewTopologyHandler(healthChecker, corePort, natsConnected)
+	chaosHandler := handlers.NewChaosHandler(chaosController)
+	versionHandler := handlers.NewVersionHandler(db, map[string]bool{
+		"timescaledb":     db.TimescaleEnabled(),
+		"news_ingest":     newsIngester.Enabled(),
+		"sentiment":       sentimentPipeline.Enabled(),
+		"realtime_prices": priceStore.Enabled(),
+		"nats_events":     subscriber != nil,
+		"loadtest":        generator.Enabled() && env.TestHelpersEnabled(),
+		"replay":          replayPlayer.Enabled() && env.TestHelpersEnabled(),
+		"test_helpers":    env.TestHelpersEnabled(),
+		"demo_seed":       seedDemo,
+	})
+	maintenanceMode := maintenance.NewMode(hub)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceMode)
+	configBundleHandler := handlers.NewConfigBundleHandler(db)
+	newsBriefHandler := handlers.NewNewsBriefHandler(newsbrief.NewSummarizer(db))
+	newsFeedsHandler := handlers.NewNewsFeedsHandler(db, newsIngester)
+	sentimentHandler := handlers.NewSentimentHandler(sentimentPipeline)
+	signalNewsHandler := handlers.NewSignalNewsHandler(signalNewsLinker)
+
+	corsPolicy := handlers.NewCORSPolicy()
+	configWatcher.ApplyStrings(settings.KeyCORSOrigins, corsPolicy.SetOrigins)
+	configWatcher.ApplyInt(settings.KeyWidgetCacheTTL, func(v int64) {
+		widgetsHandler.SetTTL(time.Duration(v) * time.Second)
+	})
+	configWatcher.ApplyInt(settings.KeyCalendarCacheTTL, func(v int64) {
+		signalCalendarHandler.SetCurrentMonthTTL(time.Duration(v) * time.Second)
+	})
+	configWatcher.ApplyInt(settings.KeyTickInterval, func(v int64) {
+		hub.SetTickInterval(time.Duration(v) * time.Millisecond)
+	})
+	configWatcher.ApplyStrings(settings.KeyMutedNotifications, notifier.SetMutedKinds)
+	configWatcher.ApplyInt(settings.KeyQuotaAPICalls, func(v int64) {
+		usageMeter.SetDefault(usage.MetricAPICalls, v)
+	})
+	configWatcher.ApplyInt(settings.KeyQuotaWSMessages, func(v int64) {
+		usageMeter.SetDefault(usage.MetricWSMessages, v)
+	})
+	configWatcher.ApplyInt(settings.KeyQuotaExportMB, func(v int64) {
+		usageMeter.SetDefault(usage.MetricExportBytes, v<<20)
+	})
+	if _, err := configWatcher.Reload(workerCtx, settings.TriggerStartup); err != nil {
+		log.Printf("⚠️  Failed to load live settings, using defaults: %v", err)
+	}
+	go configWatcher.Run(workerCtx)
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// ClientIP, which auth lockouts are keyed on, only believes forwarding
+	// headers from TRUSTED_PROXIES (comma-separated IPs or CIDRs); unset
+	// trusts none and uses the connection's address
+	var trustedProxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
-	router.Use(handlers.CORSMiddleware())
+	router.Use(handlers.CORSMiddleware(corsPolicy))
+	// Auth attempts from locked-out IPs are refused before any credentials
+	// are checked
+	router.Use(handlers.AuthGuardMiddleware(authGuard))
+	// Guest tokens may only read market data and signal stats
+	router.Use(handlers.GuestMiddleware(guestIssuer))
+	router.Use(handlers.MaintenanceMiddleware(maintenanceMode))
+	router.Use(handlers.ChaosLatency(chaosController))
+	// In dev every response reports its database work in X-DB-Stats
+	if env.TestHelpersEnabled() {
+		router.Use(handlers.DBStatsMiddleware(dbStatsTracker))
+	}
+	// ?preset=<id> fills in saved filters before anything reads the query
+	router.Use(handlers.PresetMiddleware(db, accountScope))
+
+	// Response-shape parity checks run requests through this router in-process
+	compatHandler := handlers.NewCompatHandler(compat.NewChecker(router, compat.ConfigFromEnv()))
 
-	// API routes
-	api := router.Group("/api")
+	// API routes; any GET can be asked for a compact payload with ?compact=true.
+	// Every call is metered against the caller's account.
+	api := router.Group("/api", handlers.CompactMiddleware(), handlers.UsageMiddleware(usageMeter, accountScope))
 	{
+		api.GET("/compact/keys", handlers.GetCompactKeys)
+
 		// Portfolio endpoints
 		api.GET("/portfolio/stats", handler.GetPortfolioStats)
+		api.GET("/portfolio/exposure", accountScope.Require(), exposureHandler.GetExposure)
+
+		// Accounts the caller belongs to and their members
+		accountsGroup := api.Group("/accounts")
+		{
+			accountsGroup.GET("", accountsHandler.ListAccounts)
+			accountsGroup.POST("", accountsHandler.CreateAccount)
+			accountsGroup.GET("/:id", accountsHandler.GetAccount)
+			accountsGroup.PUT("/:id", accountsHandler.UpdateAccount)
+			accountsGroup.PUT("/:id/members/:user", accountsHandler.PutAccountMember)
+			accountsGroup.DELETE("/:id/members/:user", accountsHandler.RemoveAccountMember)
+		}
+		adminAccountsGroup := api.Group("/admin/accounts", handlers.ProdAdminOnly(env))
+		{
+			adminAccountsGroup.GET("", accountsHandler.AdminListAccounts)
+			adminAccountsGroup.PUT("/:id/status", accountsHandler.AdminSetAccountStatus)
+			adminAccountsGroup.GET("/quotas", usageHandler.ListQuotas)
+			adminAccountsGroup.PUT("/:id/quota", usageHandler.PutQuota)
+			adminAccountsGroup.DELETE("/:id/quota", usageHandler.DeleteQuota)
+		}
+		// Time-limited read-only tokens for demos
+		api.POST("/admin/guest-tokens", handlers.ProdAdminOnly(env), guestHandler.CreateGuestToken)
+
+		// Usage per account against soft quotas
+		adminUsageGroup := api.Group("/admin/usage", handlers.ProdAdminOnly(env))
+		{
+			adminUsageGroup.GET("", usageHandler.GetUsage)
+			adminUsageGroup.GET("/:account", usageHandler.GetAccountUsage)
+		}
+
+		// User portfolio ledger endpoints
+		portfoliosGroup := api.Group("/portfolios", accountScope.Require())
+		{
+			portfoliosGroup.GET("", handler.ListPortfolios)
+			portfoliosGroup.POST("", handler.CreatePortfolio)
+			portfoliosGroup.GET("/:id", handler.GetPortfolio)
+			portfoliosGroup.PUT("/:id", handler.UpdatePortfolio)
+			portfoliosGroup.DELETE("/:id", handler.DeletePortfolio)
+			portfoliosGroup.GET("/:id/holdings", handler.GetPortfolioHoldings)
+			portfoliosGroup.GET("/:id/transactions", handler.ListPortfolioTransactions)
+			portfoliosGroup.POST("/:id/transactions", handler.AddPortfolioTransaction)
+			portfoliosGroup.POST("/:id/import", handler.ImportPortfolioTrades)
+			portfoliosGroup.GET("/:id/capital-gains", handler.GetCapitalGains)
+			portfoliosGroup.GET("/:id/performance", handler.GetPortfolioPerformance)
+			portfoliosGroup.GET("/:id/dividends", handler.GetPortfolioDividends)
+			portfoliosGroup.POST("/:id/rebalance", handler.RebalancePortfolio)
+		}
 
 		// Stock endpoints
 		stocksGroup := api.Group("/stocks")
@@ -77,21 +642,70 @@ func main() {
 			stocksGroup.GET("/realtime/all", handler.GetRealtimePrices)
 			stocksGroup.GET("/search", handler.SearchStocks)
 			stocksGroup.GET("/:symbol/realtime", handler.GetRealtimePrice)
+			stocksGroup.GET("/:symbol/candles", handler.GetCandles)
+			stocksGroup.GET("/:symbol/volatility", handler.GetVolatility)
 			stocksGroup.GET("/:symbol", handler.GetStockData)
 		}
 
 		// News endpoints
 		api.GET("/news", handler.GetNews)
+		api.GET("/news/clusters/:id", handler.GetNewsCluster)
+		api.POST("/news/summarize", newsBriefHandler.Summarize)
+
+		// RSS feeds polled into news.articles
+		newsFeedsGroup := api.Group("/news/feeds")
+		{
+			newsFeedsGroup.GET("", newsFeedsHandler.ListNewsFeeds)
+			newsFeedsGroup.POST("", newsFeedsHandler.CreateNewsFeed)
+			newsFeedsGroup.GET("/:id", newsFeedsHandler.GetNewsFeed)
+			newsFeedsGroup.PUT("/:id", newsFeedsHandler.UpdateNewsFeed)
+			newsFeedsGroup.DELETE("/:id", newsFeedsHandler.DeleteNewsFeed)
+			newsFeedsGroup.POST("/:id/poll", newsFeedsHandler.PollNewsFeed)
+		}
 
 		// Signals endpoints
 		signalsGroup := api.Group("/signals")
 		{
 			signalsGroup.GET("", handler.GetSignals)
 			signalsGroup.GET("/active", handler.GetActiveSignals)
+			signalsGroup.GET("/active/proximity", proximityHandler.GetProximity)
 			signalsGroup.GET("/alerts", handler.GetSignalAlerts)
 			signalsGroup.GET("/investment-signals", handler.GetInvestmentSignals)
 			signalsGroup.GET("/dashboard", handler.GetDashboardData)
+			signalsGroup.GET("/calendar", signalCalendarHandler.GetSignalCalendar)
+			// Large signals wait for approval before the execution layer acts
+			signalsGroup.GET("/approvals", signalApprovalHandler.ListApprovals)
+			signalsGroup.GET("/deleted", signalTombstoneHandler.ListDeletedSignals)
+			// Signals from vendors and other models, attributed to their provider
+			signalsGroup.POST("/ingest", handlers.ProdAdminOnly(env), signalIngestHandler.IngestSignals)
 			signalsGroup.GET("/:id", handler.GetSignalByID)
+			signalsGroup.DELETE("/:id", handlers.ProdAdminOnly(env), signalTombstoneHandler.DeleteSignal)
+			signalsGroup.POST("/:id/restore", handlers.ProdAdminOnly(env), signalTombstoneHandler.RestoreSignal)
+			signalsGroup.GET("/:id/news", signalNewsHandler.GetSignalNews)
+			signalsGroup.PUT("/:id/execution", signalExecutionHandler.UpdateExecution)
+			signalsGroup.GET("/:id/fills", signalFillHandler.ListFills)
+			signalsGroup.POST("/:id/fills", signalFillHandler.RecordFill)
+			signalsGroup.GET("/:id/timeline", signalTimelineHandler.GetTimeline)
+			signalsGroup.GET("/:id/approval", signalApprovalHandler.GetApproval)
+			signalsGroup.POST("/:id/approve", handlers.ProdAdminOnly(env), signalApprovalHandler.Approve)
+			signalsGroup.POST("/:id/reject", handlers.ProdAdminOnly(env), signalApprovalHandler.Reject)
+		}
+
+		// Symbols and strategies behind ?mine=true and the "mine" WebSocket topic
+		followsGroup := api.Group("/follows", accountScope.Require())
+		{
+			followsGroup.GET("", handler.ListFollows)
+			followsGroup.POST("", handler.AddFollow)
+			followsGroup.DELETE("/:kind/:value", handler.RemoveFollow)
+		}
+
+		// Intraday scans
+		scansGroup := api.Group("/scans")
+		{
+			scansGroup.GET("/orb", scansHandler.GetOpeningRangeBreakouts)
+			scansGroup.GET("/gaps", scansHandler.GetGaps)
+			scansGroup.GET("/unusual-volume", scansHandler.GetUnusualVolume)
+			scansGroup.GET("/circuits", scansHandler.GetCircuits)
 		}
 
 		// Predictions endpoints
@@ -105,13 +719,135 @@ func main() {
 		marketGroup := api.Group("/market")
 		{
 			marketGroup.GET("/indices", handler.GetMarketIndices)
+			marketGroup.GET("/heatmap", handler.GetMarketHeatmap)
+			marketGroup.GET("/regime", regimeHandler.GetMarketRegime)
+		}
+
+		// Public status page roll-up
+		api.GET("/status", statusPageHandler.GetStatus)
+
+		// Subscribable iCal feed of holidays, results dates and job schedules
+		api.GET("/calendar.ics", calendarHandler.GetCalendarICS)
+
+		// Custom basket endpoints
+		basketsGroup := api.Group("/baskets", accountScope.Require())
+		{
+			basketsGroup.GET("", basketsHandler.ListBaskets)
+			basketsGroup.POST("", basketsHandler.CreateBasket)
+			basketsGroup.GET("/:id", basketsHandler.GetBasket)
+			basketsGroup.PUT("/:id", basketsHandler.UpdateBasket)
+			basketsGroup.DELETE("/:id", basketsHandler.DeleteBasket)
+			basketsGroup.GET("/:id/nav", basketsHandler.GetBasketNAV)
+			basketsGroup.GET("/:id/history", basketsHandler.GetBasketHistory)
+			basketsGroup.GET("/:id/alerts", basketsHandler.ListBasketAlerts)
+			basketsGroup.POST("/:id/alerts", basketsHandler.CreateBasketAlert)
+			basketsGroup.DELETE("/:id/alerts/:alertId", basketsHandler.DeleteBasketAlert)
+		}
+
+		// Saved filter presets for signals, news and scans
+		presetsGroup := api.Group("/presets", accountScope.Require())
+		{
+			presetsGroup.GET("", presetsHandler.ListPresets)
+			presetsGroup.POST("", presetsHandler.CreatePreset)
+			presetsGroup.GET("/:id", presetsHandler.GetPreset)
+			presetsGroup.PUT("/:id", presetsHandler.UpdatePreset)
+			presetsGroup.DELETE("/:id", presetsHandler.DeletePreset)
+		}
+
+		// Trading journal endpoints
+		journalGroup := api.Group("/journal", accountScope.Require())
+		{
+			journalGroup.GET("", journalHandler.ListJournalEntries)
+			journalGroup.POST("", journalHandler.CreateJournalEntry)
+			journalGroup.GET("/summary/weekly", journalHandler.GetJournalWeeklySummary)
+			journalGroup.GET("/:id", journalHandler.GetJournalEntry)
+			journalGroup.PUT("/:id", journalHandler.UpdateJournalEntry)
+			journalGroup.DELETE("/:id", journalHandler.DeleteJournalEntry)
+		}
+
+		// Scheduled report subscriptions
+		reportsGroup := api.Group("/reports/subscriptions")
+		{
+			reportsGroup.GET("", reportsHandler.ListReportSubscriptions)
+			reportsGroup.POST("", reportsHandler.CreateReportSubscription)
+			reportsGroup.GET("/:id", reportsHandler.GetReportSubscription)
+			reportsGroup.PUT("/:id", reportsHandler.UpdateReportSubscription)
+			reportsGroup.DELETE("/:id", reportsHandler.DeleteReportSubscription)
+			reportsGroup.POST("/:id/send", reportsHandler.SendReportNow)
+		}
+
+		// Realtime closes reconciled against the bhavcopy, by trading day
+		api.GET("/reports/reconciliation/:date", reconciliationHandler.GetReconciliation)
+
+		// Alert and signal notification channels, quiet hours and digests
+		notificationsGroup := api.Group("/notifications/settings", accountScope.Require())
+		{
+			notificationsGroup.GET("", notificationsHandler.ListNotificationSettings)
+			notificationsGroup.PUT("/:channel", notificationsHandler.PutNotificationSetting)
+			notificationsGroup.DELETE("/:channel", notificationsHandler.DeleteNotificationSetting)
+		}
+
+		// Minimal, long-cached JSON for embedding in external sites
+		widgetsGroup := api.Group("/widgets")
+		{
+			widgetsGroup.GET("/top-movers", widgetsHandler.GetTopMovers)
+			widgetsGroup.GET("/market-summary", widgetsHandler.GetMarketSummary)
+		}
+
+		// Public read-only links; served unauthenticated at /share/:token
+		shareGroup := api.Group("/share", accountScope.Require())
+		{
+			shareGroup.GET("", shareHandler.ListShareLinks)
+			shareGroup.POST("", shareHandler.CreateShareLink)
+			shareGroup.DELETE("/:id", shareHandler.DeleteShareLink)
+		}
+
+		// TradingView alert webhooks; the receiver authenticates by webhook secret
+		tradingViewGroup := api.Group("/integrations/tradingview")
+		{
+			tradingViewGroup.POST("", tradingViewHandler.ReceiveAlert)
+			tradingViewGroup.GET("/webhooks", tradingViewHandler.ListWebhooks)
+			tradingViewGroup.POST("/webhooks", tradingViewHandler.CreateWebhook)
+			tradingViewGroup.PUT("/webhooks/:id", tradingViewHandler.UpdateWebhook)
+			tradingViewGroup.DELETE("/webhooks/:id", tradingViewHandler.DeleteWebhook)
+			tradingViewGroup.GET("/webhooks/:id/deliveries", tradingViewHandler.ListDeliveries)
+		}
+
+		// Configurable inbound webhooks mapped onto signals, alerts or news;
+		// the receiver authenticates by webhook secret
+		api.POST("/integrations/inbound/:slug", inboundWebhookHandler.ReceiveInboundWebhook)
+		inboundWebhooksGroup := api.Group("/integrations/webhooks")
+		{
+			inboundWebhooksGroup.GET("", inboundWebhookHandler.ListInboundWebhooks)
+			inboundWebhooksGroup.POST("", handlers.ProdAdminOnly(env), inboundWebhookHandler.CreateInboundWebhook)
+			inboundWebhooksGroup.POST("/preview", inboundWebhookHandler.PreviewInboundWebhook)
+			inboundWebhooksGroup.GET("/:id", inboundWebhookHandler.GetInboundWebhook)
+			inboundWebhooksGroup.PUT("/:id", handlers.ProdAdminOnly(env), inboundWebhookHandler.UpdateInboundWebhook)
+			inboundWebhooksGroup.DELETE("/:id", handlers.ProdAdminOnly(env), confirmations.Require(twoperson.InboundWebhookDelete), inboundWebhookHandler.DeleteInboundWebhook)
+		}
+
+		// Parameterized query intents for LLM assistants; never raw SQL
+		assistantGroup := api.Group("/assistant")
+		{
+			assistantGroup.GET("/intents", assistantHandler.ListIntents)
+			assistantGroup.POST("/query", assistantHandler.Query)
+		}
+
+		// Mobile devices receiving push notifications
+		pushDevicesGroup := api.Group("/notifications/devices", accountScope.Require())
+		{
+			pushDevicesGroup.GET("", pushDevicesHandler.ListPushDevices)
+			pushDevicesGroup.POST("", pushDevicesHandler.RegisterPushDevice)
+			pushDevicesGroup.PUT("/:id", pushDevicesHandler.UpdatePushDevice)
+			pushDevicesGroup.DELETE("/:id", pushDevicesHandler.DeletePushDevice)
 		}
 
 		// Watchlist endpoints
-		watchlistGroup := api.Group("/watchlist")
+		watchlistGroup := api.Group("/watchlist", accountScope.Require())
 		{
 			watchlistGroup.GET("", handler.GetWatchlist)
 			watchlistGroup.POST("", handler.AddToWatchlist)
+			watchlistGroup.POST("/import-from-broker", handler.ImportWatchlistFromBroker)
 			watchlistGroup.DELETE("/:symbol", handler.RemoveFromWatchlist)
 		}
 
@@ -121,18 +857,24 @@ func main() {
 			stockConfigGroup.GET("/stocks", handler.GetStockConfigs)
 			stockConfigGroup.PUT("/stocks/:symbol/:exchange", handler.UpdateStockConfig)
 			stockConfigGroup.GET("/stats", handler.GetStockConfigStats)
+			stockConfigGroup.GET("/as-of", stockConfigHistoryHandler.GetStockConfigAsOf)
 			stockConfigGroup.GET("/export-csv", handler.ExportStockConfigsCSV)
-			stockConfigGroup.POST("/import-csv", handler.ImportStockConfigsCSV)
+			stockConfigGroup.POST("/import-csv", handlers.ProdAdminOnly(env), confirmations.Require(twoperson.StockConfigImport), handler.ImportStockConfigsCSV)
 			stockConfigGroup.GET("/import-jobs/:jobId", handler.GetImportJobStatus)
 		}
 
 		// System configuration endpoints
 		configGroup := api.Group("/config")
 		{
-			configGroup.GET("/smart-selection", handler.GetSmartSelection)
-			configGroup.PUT("/smart-selection", handler.UpdateSmartSelection)
+			// Typed runtime settings; the smart-selection routes are kept
+			// for existing clients and read and write the same settings
+			configGroup.GET("/settings", settingsHandler.ListSettings)
+			configGroup.GET("/settings/:key", settingsHandler.GetSetting)
+			configGroup.PUT("/settings/:key", handlers.ProdAdminOnly(env), settingsHandler.UpdateSetting)
+			configGroup.GET("/smart-selection", settingsHandler.GetSmartSelection)
+			configGroup.PUT("/smart-selection", handlers.ProdAdminOnly(env), settingsHandler.UpdateSmartSelection)
 			configGroup.GET("/stock-counts", handler.GetStockCounts)
-			configGroup.PUT("/smart-selection/stock-count", handler.UpdateSmartSelectionStockCount)
+			configGroup.PUT("/smart-selection/stock-count", handlers.ProdAdminOnly(env), settingsHandler.UpdateSmartSelectionStockCount)
 		}
 
 		// Monitor endpoints (dashboard compatibility)
@@ -154,26 +896,130 @@ func main() {
 			monitoringGroup.GET("/logs/recent", monitoringHandler.GetRecentLogs)
 			monitoringGroup.GET("/logs/errors", monitoringHandler.GetErrorLogs)
 			monitoringGroup.GET("/broker-status", monitoringHandler.GetBrokerStatus)
+			monitoringGroup.GET("/coverage", coverageHandler.GetCoverage)
+			monitoringGroup.GET("/circuit-breakers", monitoringHandler.GetCircuitBreakers)
+			monitoringGroup.GET("/sentiment", sentimentHandler.GetSentimentStats)
+			monitoringGroup.GET("/events", eventsHandler.GetEvents)
+			monitoringGroup.GET("/tick-quality", tickQualityHandler.GetTickQuality)
+			monitoringGroup.GET("/quarantine", dataQualityHandler.ListQuarantine)
+			monitoringGroup.POST("/quarantine/:id/release", handlers.ProdAdminOnly(env), dataQualityHandler.ReleaseQuarantined)
+			monitoringGroup.GET("/events/dead-letter", deadLetterHandler.ListDeadLetters)
+			monitoringGroup.POST("/events/dead-letter/:id/reprocess", deadLetterHandler.ReprocessDeadLetter)
 		}
 
 		// Quantitative Analytics endpoints
 		quantGroup := api.Group("/quant")
 		{
-			quantGroup.GET("/analytics", quantHandler.GetQuantAnalytics)
+			quantGroup.GET("/analytics", accountScope.Require(), quantHandler.GetQuantAnalytics)
+			quantGroup.GET("/pairs", quantHandler.GetPairAnalytics)
+			quantGroup.GET("/seasonality", quantHandler.GetSeasonality)
+			quantGroup.GET("/leaderboard", quantHandler.GetLeaderboard)
+			quantGroup.GET("/slippage", signalFillHandler.GetSlippage)
+			quantGroup.POST("/position-size", positionSizeHandler.GetPositionSize)
+			quantGroup.POST("/stress", accountScope.Require(), stressHandler.RunStressTest)
+			quantGroup.GET("/risk/snapshots", riskHandler.GetRiskSnapshots)
 		}
 
 		// System monitoring endpoints
 		systemGroup := api.Group("/system")
 		{
 			systemGroup.GET("/services", systemHandler.GetServices)
+			systemGroup.GET("/topology", topologyHandler.GetTopology)
+			systemGroup.GET("/version", versionHandler.GetVersion)
 			systemGroup.GET("/jobs", systemHandler.GetJobs)
-			systemGroup.POST("/jobs/:jobName/run", systemHandler.RunJobManually)
+			systemGroup.GET("/maintenance", maintenanceHandler.GetMaintenance)
+			systemGroup.POST("/maintenance", handlers.ProdAdminOnly(env), maintenanceHandler.SetMaintenance)
+			systemGroup.GET("/config", settingsHandler.GetRuntimeConfig)
+			systemGroup.GET("/config/export", configBundleHandler.ExportConfig)
+			systemGroup.POST("/config/import", handlers.ProdAdminOnly(env), confirmations.Require(twoperson.ConfigImport), configBundleHandler.ImportConfig)
+			systemGroup.POST("/jobs/:jobName/run", handlers.ProdAdminOrService(env, serviceAuth, serviceauth.JobsRun), systemHandler.RunJobManually)
 			systemGroup.GET("/ml-models", systemHandler.GetMLModels)
+			systemGroup.GET("/aggregates", aggregatesHandler.GetAggregates)
+			systemGroup.POST("/aggregates/refresh", handlers.ProdAdminOnly(env), aggregatesHandler.RefreshAggregates)
+			systemGroup.GET("/retention", retentionHandler.GetRetention)
+			systemGroup.POST("/retention/run", handlers.ProdAdminOnly(env), confirmations.Require(twoperson.RetentionRun), retentionHandler.RunRetention)
+			systemGroup.GET("/database", databaseHandler.GetDatabase)
+			systemGroup.GET("/database/indexes", handlers.ProdAdminOnly(env), databaseHandler.GetIndexAdvice)
+			// Two-person requests for destructive actions and their audit trail
+			systemGroup.GET("/confirmations", handlers.ProdAdminOnly(env), confirmations.ListConfirmations)
+			systemGroup.DELETE("/confirmations/:id", handlers.ProdAdminOnly(env), confirmations.CancelConfirmation)
+
+			// On-demand actions relayed to downstream services over NATS
+			commandGuard := handlers.ProdAdminOrService(env, serviceAuth, serviceauth.CommandsSend)
+			systemGroup.GET("/commands", engineHandler.ListCommands)
+			systemGroup.POST("/engine/rescan-symbol/:symbol", commandGuard, engineHandler.Command("engine", "rescan-symbol"))
+			systemGroup.POST("/engine/reload-config", commandGuard, engineHandler.Command("engine", "reload-config"))
+			systemGroup.GET("/engine/status", engineHandler.Command("engine", "status"))
+			systemGroup.POST("/bridge/resubscribe", commandGuard, engineHandler.Command("bridge", "resubscribe"))
+			systemGroup.GET("/bridge/status", engineHandler.Command("bridge", "status"))
+
+			// Test helpers
+			chaosGroup := systemGroup.Group("/chaos", chaosHandler.RequireEnabled, handlers.ProdAdminOnly(env))
+			{
+				chaosGroup.GET("", chaosHandler.GetChaos)
+				chaosGroup.DELETE("", chaosHandler.ClearChaos)
+				chaosGroup.PUT("/latency", chaosHandler.SetLatency)
+				chaosGroup.PUT("/websocket-drop", chaosHandler.SetWebSocketDrop)
+				chaosGroup.PUT("/nats-disconnect", chaosHandler.SetNATSDisconnect)
+			}
+			systemGroup.GET("/loadtest", handlers.DevOnly(env), loadTestHandler.GetLoadTest)
+			systemGroup.POST("/loadtest", handlers.DevOnly(env), loadTestHandler.StartLoadTest)
+			systemGroup.DELETE("/loadtest", handlers.DevOnly(env), loadTestHandler.StopLoadTest)
+			systemGroup.GET("/replay", handlers.DevOnly(env), replayHandler.GetReplay)
+			systemGroup.POST("/replay", handlers.DevOnly(env), replayHandler.StartReplay)
+			systemGroup.DELETE("/replay", handlers.DevOnly(env), replayHandler.StopReplay)
+			systemGroup.GET("/replay/snapshot", handlers.DevOnly(env), replayHandler.GetReplaySnapshot)
+			systemGroup.GET("/db-stats", handlers.DevOnly(env), dbStatsHandler.GetDBStats)
+			systemGroup.DELETE("/db-stats", handlers.DevOnly(env), dbStatsHandler.ResetDBStats)
+		}
+
+		// Bulk export endpoints
+		exportsGroup := api.Group("/exports")
+		{
+			exportsGroup.POST("", exportsHandler.CreateExport)
+			exportsGroup.GET("", exportsHandler.ListExports)
+			exportsGroup.GET("/:id", exportsHandler.GetExport)
+		}
+
+		// Object storage: listing, pre-signed URLs, lifecycle and local signed transfers
+		storageGroup := api.Group("/storage")
+		{
+			storageGroup.GET("/list", storageHandler.ListObjects)
+			storageGroup.POST("/presign", storageHandler.Presign)
+			storageGroup.GET("/lifecycle", storageHandler.GetLifecycle)
+			storageGroup.POST("/lifecycle/run", handlers.ProdAdminOnly(env), confirmations.Require(twoperson.LifecycleRun), storageHandler.RunLifecycle)
+			storageGroup.GET("/objects/*key", storageHandler.GetObject)
+			storageGroup.PUT("/objects/*key", storageHandler.PutObject)
+		}
+
+		// Versioned NATS event schemas
+		eventsGroup := api.Group("/events")
+		{
+			eventsGroup.GET("/schemas", schemasHandler.ListSchemas)
+			eventsGroup.GET("/schemas/:name", schemasHandler.GetSchema)
+		}
+
+		// Service-to-service endpoints authenticated by service token;
+		// lightweight collectors push prices here instead of to the database
+		internalGroup := api.Group("/internal")
+		{
+			internalGroup.POST("/prices/batch", handlers.ServiceOnly(serviceAuth, serviceauth.PricesWrite), internalPricesHandler.UpsertPrices)
+		}
+
+		// Response-shape parity with the Python core-api
+		compatGroup := api.Group("/compat")
+		{
+			compatGroup.GET("/diff", compatHandler.Diff)
+			compatGroup.POST("/record", handlers.ProdAdminOnly(env), compatHandler.Record)
+			compatGroup.GET("/fixtures", compatHandler.ListFixtures)
 		}
 
 		// Authentication endpoints
 		authGroup := api.Group("/auth")
 		{
+			authGroup.GET("/security-events", handlers.ProdAdminOnly(env), authSecurityHandler.ListSecurityEvents)
+			authGroup.DELETE("/security-events/lockouts/:ip", handlers.ProdAdminOnly(env), authSecurityHandler.Unlock)
+
 			zerodhaGroup := authGroup.Group("/zerodha")
 			{
 				zerodhaGroup.GET("/login-url", handler.GetZerodhaLoginUrl)
@@ -195,30 +1041,36 @@ func main() {
 	}
 
 	// WebSocket endpoint
-	router.GET("/ws", handler.ServeWebSocket)
+	router.GET("/ws", accountScope.Require(), handler.ServeWebSocket)
+
+	// Shared signals, dashboard snapshots and reports
+	router.GET("/share/:token", shareHandler.GetShared)
+
+	// GraphQL endpoint (signals, stocks, news and portfolios in one round trip)
+	router.GET("/graphql", accountScope.Require(), graphqlHandler.Query)
+	router.POST("/graphql", accountScope.Require(), graphqlHandler.Query)
 
 	// Health endpoint
 	router.GET("/health", handler.Health)
 
-	// Root endpoint
+	// Root endpoint; the route count is filled in once every route is registered
+	var endpoints int
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"name":        "Trading-Chitti Core API (Go)",
-			"version":     "2.0.0",
+			"version":     buildinfo.Version,
+			"commit":      build.Commit,
 			"description": "Full-featured API with real-time WebSocket streaming",
-			"endpoints":   59,
+			"environment": env.Info(),
+			"endpoints":   endpoints,
 			"health":      "/health",
 			"websocket":   "/ws",
+			"graphql":     "/graphql",
 		})
 	})
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "6001"
-	}
-
-	log.Printf("✅ Core API Go listening on port %s (59 endpoints)", port)
+	endpoints = len(router.Routes())
+	log.Printf("✅ Core API Go %s (%s) listening on port %s (%d endpoints)", buildinfo.Version, buildinfo.ShortCommit(), port, endpoints)
 
 	// Start server in goroutine
 	go func() {
@@ -233,4 +1085,9 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down Core API Go...")
+
+	// Stop the workers and let the meter flush usage counted since its last
+	// tick before the database is closed
+	stopWorkers()
+	<-meterDone
 }
@@ -0,0 +1,165 @@
+// Package aggregates keeps the 1-minute, 5-minute and daily bar roll-ups up
+// to date from the tick stream, replacing the external backtest-data-collector
+// cron job.
+package aggregates
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// defaultBackfill is how far back a roll-up starts when it has no watermark
+const defaultBackfill = 7 * 24 * time.Hour
+
+// refreshTimeout caps one pass over the whole chain
+const refreshTimeout = 10 * time.Minute
+
+// Status describes one roll-up for /api/system/aggregates
+type Status struct {
+	database.AggregateState
+	Source     string  `json:"source"`
+	Bucket     string  `json:"bucket"`
+	LagSeconds float64 `json:"lag_seconds"`
+	Running    bool    `json:"running"`
+}
+
+// Maintainer refreshes the roll-up chain on a schedule
+type Maintainer struct {
+	db       *database.DB
+	interval time.Duration
+	backfill time.Duration
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewMaintainer reads AGGREGATES_REFRESH_SECONDS (default 60) and
+// AGGREGATES_BACKFILL_DAYS (default 7)
+func NewMaintainer(db *database.DB) *Maintainer {
+	m := &Maintainer{db: db, interval: time.Minute, backfill: defaultBackfill}
+	if v, err := strconv.Atoi(os.Getenv("AGGREGATES_REFRESH_SECONDS")); err == nil && v > 0 {
+		m.interval = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("AGGREGATES_BACKFILL_DAYS")); err == nil && v > 0 {
+		m.backfill = time.Duration(v) * 24 * time.Hour
+	}
+	return m
+}
+
+// Interval returns the refresh interval
+func (m *Maintainer) Interval() time.Duration {
+	return m.interval
+}
+
+// Run refreshes immediately and then every interval until ctx is cancelled
+func (m *Maintainer) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh runs one pass over the chain. It returns false if a pass is
+// already in progress.
+func (m *Maintainer) Refresh(ctx context.Context) bool {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return false
+	}
+	m.running = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	states, err := m.db.GetAggregateStates(ctx)
+	if err != nil {
+		log.Printf("❌ Aggregates: %v", err)
+		return true
+	}
+
+	now := time.Now()
+	for _, agg := range database.BarAggregates() {
+		from := agg.AlignBucket(now.Add(-m.backfill))
+		if s, ok := states[agg.Name]; ok && s.Watermark != nil {
+			from = agg.AlignBucket(s.Watermark.Add(-agg.Overlap))
+		}
+		// Include the bucket in progress; the overlap recomputes it next time
+		to := agg.AlignBucket(now).Add(agg.Width)
+
+		start := time.Now()
+		rows, err := m.db.RefreshBarAggregate(ctx, agg, from, to)
+		runAt := time.Now()
+		state := database.AggregateState{
+			Name:           agg.Name,
+			LastRunAt:      &runAt,
+			LastDurationMs: time.Since(start).Milliseconds(),
+			LastRows:       rows,
+		}
+		if err != nil {
+			msg := err.Error()
+			state.LastError = &msg
+			log.Printf("❌ Aggregates: %v", err)
+		} else {
+			watermark := agg.AlignBucket(now)
+			state.Watermark = &watermark
+		}
+		if serr := m.db.SaveAggregateState(ctx, state); serr != nil {
+			log.Printf("❌ Aggregates: %v", serr)
+		}
+		// Later levels read this one, so stop the chain on failure
+		if err != nil {
+			break
+		}
+	}
+	return true
+}
+
+// Status returns the refresh state of every roll-up
+func (m *Maintainer) Status(ctx context.Context) ([]Status, error) {
+	states, err := m.db.GetAggregateStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	running := m.running
+	m.mu.Unlock()
+
+	now := time.Now()
+	result := []Status{}
+	for _, agg := range database.BarAggregates() {
+		s := Status{
+			AggregateState: database.AggregateState{Name: agg.Name},
+			Source:         agg.Source,
+			Bucket:         agg.Width.String(),
+			Running:        running,
+		}
+		if state, ok := states[agg.Name]; ok {
+			s.AggregateState = state
+		}
+		if s.Watermark != nil {
+			s.LagSeconds = now.Sub(*s.Watermark).Seconds()
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
@@ -0,0 +1,214 @@
+// Package approvals holds large signals for review before the paper and
+// real execution layer acts on them. Active signals are sized with
+// half-Kelly on APPROVAL_CAPITAL; those whose notional exceeds
+// APPROVAL_THRESHOLD get a pending review that an operator approves or
+// rejects within APPROVAL_WINDOW_MINUTES, after which it expires. Every
+// outcome is published on signal.approval for the execution layer.
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// Subject is the NATS subject review outcomes are published on
+const Subject = "signal.approval"
+
+// Defaults for ConfigFromEnv
+const (
+	defaultCapital      = 1000000
+	defaultRiskFraction = 0.01
+	defaultWindow       = 30 * time.Minute
+	sweepInterval       = 30 * time.Second
+)
+
+// Config controls which signals need approval and for how long a review
+// stays open. A zero Threshold disables reviews.
+type Config struct {
+	Threshold    float64       `json:"threshold"`
+	Capital      float64       `json:"capital"`
+	RiskFraction float64       `json:"risk_fraction"`
+	Window       time.Duration `json:"-"`
+}
+
+// ConfigFromEnv reads APPROVAL_THRESHOLD (INR notional, unset disables
+// reviews), APPROVAL_CAPITAL (INR, default 1000000) and
+// APPROVAL_WINDOW_MINUTES (default 30)
+func ConfigFromEnv() Config {
+	cfg := Config{Capital: defaultCapital, RiskFraction: defaultRiskFraction, Window: defaultWindow}
+	if v, err := strconv.ParseFloat(os.Getenv("APPROVAL_THRESHOLD"), 64); err == nil && v > 0 {
+		cfg.Threshold = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("APPROVAL_CAPITAL"), 64); err == nil && v > 0 {
+		cfg.Capital = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("APPROVAL_WINDOW_MINUTES")); err == nil && v > 0 {
+		cfg.Window = time.Duration(v) * time.Minute
+	}
+	return cfg
+}
+
+// Publisher publishes a raw event on a NATS subject
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Reviewer opens reviews for large signals and expires stale ones
+type Reviewer struct {
+	db        database.SignalApprovalRepository
+	publisher Publisher
+	cfg       Config
+
+	mu       sync.Mutex
+	reviewed map[string]bool
+	wake     chan struct{}
+}
+
+// NewReviewer creates a reviewer. Outcomes are only stored until
+// SetPublisher is called.
+func NewReviewer(db database.SignalApprovalRepository, cfg Config) *Reviewer {
+	return &Reviewer{db: db, cfg: cfg, reviewed: map[string]bool{}, wake: make(chan struct{}, 1)}
+}
+
+// SetPublisher sends outcomes on NATS. Call before Run.
+func (r *Reviewer) SetPublisher(p Publisher) {
+	r.publisher = p
+}
+
+// Enabled reports whether signals are held for approval
+func (r *Reviewer) Enabled() bool {
+	return r.cfg.Threshold > 0
+}
+
+// Config returns the reviewer's configuration
+func (r *Reviewer) Config() Config {
+	return r.cfg
+}
+
+// HandleSignal sweeps as soon as a new signal is announced rather than at
+// the next interval
+func (r *Reviewer) HandleSignal(subject string, _ schemas.Signal) {
+	if subject != "signal.new" || !r.Enabled() {
+		return
+	}
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sweeps every 30 seconds and on new signals until ctx is cancelled
+func (r *Reviewer) Run(ctx context.Context) {
+	if !r.Enabled() {
+		return
+	}
+	log.Printf("✅ Signals over ₹%.0f notional need approval within %s", r.cfg.Threshold, r.cfg.Window)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		r.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// sweep expires overdue reviews and opens reviews for active signals not
+// seen yet
+func (r *Reviewer) sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	expired, err := r.db.ExpireSignalApprovals(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to expire signal approvals: %v", err)
+	}
+	for i := range expired {
+		log.Printf("⌛ Approval for signal %s expired", expired[i].SignalID)
+		r.Publish(&expired[i])
+	}
+
+	signals, err := r.db.GetActiveSignals(ctx)
+	if err != nil {
+		log.Printf("❌ Approval review failed to load active signals: %v", err)
+		return
+	}
+	active := make(map[string]bool, len(signals))
+	for i := range signals {
+		s := &signals[i]
+		active[s.SignalID] = true
+		r.mu.Lock()
+		seen := r.reviewed[s.SignalID]
+		r.mu.Unlock()
+		if seen {
+			continue
+		}
+		if err := r.review(ctx, s); err != nil {
+			log.Printf("❌ Failed to review signal %s for approval: %v", s.SignalID, err)
+			continue
+		}
+		r.mu.Lock()
+		r.reviewed[s.SignalID] = true
+		r.mu.Unlock()
+	}
+
+	// Forget closed signals so the set stays the size of the active book
+	r.mu.Lock()
+	for id := range r.reviewed {
+		if !active[id] {
+			delete(r.reviewed, id)
+		}
+	}
+	r.mu.Unlock()
+}
+
+// review sizes a signal and opens a review if it's over the threshold
+func (r *Reviewer) review(ctx context.Context, s *database.Signal) error {
+	size, err := r.db.GetSignalPositionSize(ctx, s, r.cfg.Capital, r.cfg.RiskFraction)
+	if err != nil {
+		return err
+	}
+	notional := size.HalfKelly.Notional
+	if notional <= r.cfg.Threshold {
+		return nil
+	}
+	created, err := r.db.RequestSignalApproval(ctx, s.SignalID, notional, r.cfg.Threshold, time.Now().Add(r.cfg.Window))
+	if err != nil || !created {
+		return err
+	}
+	log.Printf("✋ Signal %s (%s %s, ₹%.0f) is awaiting approval", s.SignalID, s.SignalType, s.Symbol, notional)
+	approval, err := r.db.GetSignalApproval(ctx, s.SignalID)
+	if err != nil {
+		return err
+	}
+	if approval != nil {
+		r.Publish(approval)
+	}
+	return nil
+}
+
+// Publish announces a review's current status on Subject
+func (r *Reviewer) Publish(a *database.SignalApproval) {
+	if r.publisher == nil {
+		return
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"event_type": Subject,
+		"approval":   a,
+		"timestamp":  time.Now().Format(time.RFC3339Nano),
+	})
+	if err := r.publisher.Publish(Subject, data); err != nil {
+		log.Printf("❌ Failed to publish approval for signal %s: %v", a.SignalID, err)
+	}
+}
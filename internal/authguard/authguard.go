@@ -0,0 +1,392 @@
+// Package authguard watches the auth endpoints for brute force and other
+// suspicious patterns. Every failed token exchange or rejected service or
+// guest token is recorded as a security event; an IP failing
+// AUTH_MAX_FAILURES times within AUTH_FAILURE_WINDOW_MINUTES is locked out
+// of auth attempts for AUTH_LOCKOUT_MINUTES. Failures against one subject
+// from many IPs, and a broker token being replaced again and again, are
+// flagged as anomalies. Hooks registered with OnEvent are told of every
+// event, e.g. to notify operators.
+//
+// Lockouts are kept in memory, so each instance enforces its own.
+package authguard
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Event kinds
+const (
+	// KindTokenExchangeFailed is a broker rejecting a request or access token
+	KindTokenExchangeFailed = "token_exchange_failed"
+	// KindServiceTokenInvalid is a forged, malformed or expired service token
+	KindServiceTokenInvalid = "service_token_invalid"
+	// KindServiceDenied is a valid service token used beyond its permissions
+	KindServiceDenied = "service_permission_denied"
+	// KindGuestTokenInvalid is a forged, malformed or expired guest token
+	KindGuestTokenInvalid = "guest_token_invalid"
+	// KindWebhookSecretInvalid is a webhook delivery with a missing or
+	// unknown secret
+	KindWebhookSecretInvalid = "webhook_secret_invalid"
+	// KindLockout is an IP being locked out after repeated failures
+	KindLockout = "ip_locked_out"
+	// KindDistributedFailures is one subject failing from many IPs, which
+	// per-IP lockouts alone don't stop
+	KindDistributedFailures = "distributed_failures"
+	// KindTokenChurn is a broker token being replaced unusually often
+	KindTokenChurn = "token_churn"
+)
+
+// severities of the failure kinds; anything unlisted is informational
+var severities = map[string]string{
+	KindServiceDenied:       database.AuthSeverityWarning,
+	KindLockout:             database.AuthSeverityCritical,
+	KindDistributedFailures: database.AuthSeverityCritical,
+	KindTokenChurn:          database.AuthSeverityWarning,
+}
+
+// Defaults for ConfigFromEnv
+const (
+	defaultMaxFailures   = 5
+	defaultWindow        = 15 * time.Minute
+	defaultLockout       = 15 * time.Minute
+	defaultSpreadIPs     = 5
+	defaultChurnLimit    = 3
+	defaultRetentionDays = 90
+	sweepInterval        = time.Minute
+	pruneInterval        = time.Hour
+)
+
+// Config controls when IPs are locked out and anomalies flagged
+type Config struct {
+	// MaxFailures within Window lock an IP out for Lockout
+	MaxFailures int           `json:"max_failures"`
+	Window      time.Duration `json:"-"`
+	Lockout     time.Duration `json:"-"`
+	// SpreadIPs distinct IPs failing against one subject within Window are
+	// flagged as a distributed attack
+	SpreadIPs int `json:"spread_ips"`
+	// ChurnLimit token saves for one broker within Window are flagged
+	ChurnLimit int `json:"churn_limit"`
+	// RetentionDays is how long events are kept
+	RetentionDays int `json:"retention_days"`
+}
+
+// ConfigFromEnv reads AUTH_MAX_FAILURES (default 5),
+// AUTH_FAILURE_WINDOW_MINUTES (default 15), AUTH_LOCKOUT_MINUTES (default
+// 15) and AUTH_EVENT_RETENTION_DAYS (default 90)
+func ConfigFromEnv() Config {
+	cfg := Config{
+		MaxFailures:   defaultMaxFailures,
+		Window:        defaultWindow,
+		Lockout:       defaultLockout,
+		SpreadIPs:     defaultSpreadIPs,
+		ChurnLimit:    defaultChurnLimit,
+		RetentionDays: defaultRetentionDays,
+	}
+	if v, err := strconv.Atoi(os.Getenv("AUTH_MAX_FAILURES")); err == nil && v > 0 {
+		cfg.MaxFailures = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("AUTH_FAILURE_WINDOW_MINUTES")); err == nil && v > 0 {
+		cfg.Window = time.Duration(v) * time.Minute
+	}
+	if v, err := strconv.Atoi(os.Getenv("AUTH_LOCKOUT_MINUTES")); err == nil && v > 0 {
+		cfg.Lockout = time.Duration(v) * time.Minute
+	}
+	if v, err := strconv.Atoi(os.Getenv("AUTH_EVENT_RETENTION_DAYS")); err == nil && v > 0 {
+		cfg.RetentionDays = v
+	}
+	return cfg
+}
+
+// Attempt is one auth attempt on an endpoint
+type Attempt struct {
+	Kind     string
+	IP       string
+	Endpoint string
+	// Subject is the broker, service or guest involved, when known
+	Subject string
+	Detail  string
+}
+
+// Lockout is an IP currently refused auth attempts
+type Lockout struct {
+	IP       string    `json:"ip"`
+	Until    time.Time `json:"until"`
+	Failures int       `json:"failures"`
+}
+
+type ipState struct {
+	failures    []time.Time
+	lockedUntil time.Time
+}
+
+// Guard tracks auth failures per IP and subject
+type Guard struct {
+	db  database.AuthSecurityRepository
+	cfg Config
+
+	mu sync.Mutex
+	// ips holds recent failures and lockouts by IP
+	ips map[string]*ipState
+	// failingIPs holds, by subject, when each IP last failed against it
+	failingIPs map[string]map[string]time.Time
+	// saves holds recent token saves by subject
+	saves map[string][]time.Time
+	// flagged holds when an anomaly was last raised, by kind and subject,
+	// so one is raised at most once per window
+	flagged map[[2]string]time.Time
+
+	hooks []func(database.AuthSecurityEvent)
+}
+
+// NewGuard creates an auth guard
+func NewGuard(db database.AuthSecurityRepository, cfg Config) *Guard {
+	return &Guard{
+		db:         db,
+		cfg:        cfg,
+		ips:        map[string]*ipState{},
+		failingIPs: map[string]map[string]time.Time{},
+		saves:      map[string][]time.Time{},
+		flagged:    map[[2]string]time.Time{},
+	}
+}
+
+// Config returns the guard's configuration
+func (g *Guard) Config() Config {
+	return g.cfg
+}
+
+// OnEvent registers fn to receive every recorded event. Call before the
+// guard sees traffic; fn runs on the request's goroutine and must not block.
+func (g *Guard) OnEvent(fn func(database.AuthSecurityEvent)) {
+	g.hooks = append(g.hooks, fn)
+}
+
+// Locked reports until when ip is locked out, if it is
+func (g *Guard) Locked(ip string, now time.Time) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if st := g.ips[ip]; st != nil && now.Before(st.lockedUntil) {
+		return st.lockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// Failure records a failed attempt, locking its IP out once it has failed
+// MaxFailures times within Window
+func (g *Guard) Failure(a Attempt) {
+	now := time.Now()
+	events := []database.AuthSecurityEvent{g.event(a.Kind, a, a.Detail, now)}
+
+	g.mu.Lock()
+	st := g.ips[a.IP]
+	if st == nil {
+		st = &ipState{}
+		g.ips[a.IP] = st
+	}
+	st.failures = append(recent(st.failures, now.Add(-g.cfg.Window)), now)
+	if len(st.failures) >= g.cfg.MaxFailures && !now.Before(st.lockedUntil) {
+		st.lockedUntil = now.Add(g.cfg.Lockout)
+		events = append(events, g.event(KindLockout, a,
+			fmt.Sprintf("%d failures within %s, locked out until %s",
+				len(st.failures), g.cfg.Window, st.lockedUntil.Format(time.RFC3339)), now))
+	}
+	if a.Subject != "" {
+		ips := g.failingIPs[a.Subject]
+		if ips == nil {
+			ips = map[string]time.Time{}
+			g.failingIPs[a.Subject] = ips
+		}
+		ips[a.IP] = now
+		for ip, at := range ips {
+			if now.Sub(at) > g.cfg.Window {
+				delete(ips, ip)
+			}
+		}
+		if len(ips) >= g.cfg.SpreadIPs && g.flag(KindDistributedFailures, a.Subject, now) {
+			events = append(events, g.event(KindDistributedFailures, a,
+				fmt.Sprintf("failures from %d IPs within %s", len(ips), g.cfg.Window), now))
+		}
+	}
+	g.mu.Unlock()
+
+	for _, e := range events {
+		g.record(e)
+	}
+}
+
+// TokenSaved records a successful token exchange, clearing the IP's
+// failures and flagging a subject whose token is replaced ChurnLimit times
+// within Window
+func (g *Guard) TokenSaved(a Attempt) {
+	now := time.Now()
+	var churn *database.AuthSecurityEvent
+
+	g.mu.Lock()
+	if st := g.ips[a.IP]; st != nil {
+		st.failures = nil
+	}
+	if a.Subject != "" {
+		saves := append(recent(g.saves[a.Subject], now.Add(-g.cfg.Window)), now)
+		g.saves[a.Subject] = saves
+		if len(saves) >= g.cfg.ChurnLimit && g.flag(KindTokenChurn, a.Subject, now) {
+			e := g.event(KindTokenChurn, a, fmt.Sprintf("token saved %d times within %s", len(saves), g.cfg.Window), now)
+			churn = &e
+		}
+	}
+	g.mu.Unlock()
+
+	if churn != nil {
+		g.record(*churn)
+	}
+}
+
+// Unlock lifts an IP's lockout and forgets its failures, returning false if
+// it wasn't locked out
+func (g *Guard) Unlock(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := g.ips[ip]
+	if st == nil || !time.Now().Before(st.lockedUntil) {
+		return false
+	}
+	delete(g.ips, ip)
+	return true
+}
+
+// Lockouts returns the IPs locked out now, soonest released first
+func (g *Guard) Lockouts() []Lockout {
+	now := time.Now()
+	g.mu.Lock()
+	lockouts := []Lockout{}
+	for ip, st := range g.ips {
+		if now.Before(st.lockedUntil) {
+			lockouts = append(lockouts, Lockout{IP: ip, Until: st.lockedUntil, Failures: len(st.failures)})
+		}
+	}
+	g.mu.Unlock()
+	sort.Slice(lockouts, func(i, j int) bool { return lockouts[i].Until.Before(lockouts[j].Until) })
+	return lockouts
+}
+
+// flag reports whether an anomaly may be raised now, marking it raised.
+// Callers hold mu.
+func (g *Guard) flag(kind, subject string, now time.Time) bool {
+	key := [2]string{kind, subject}
+	if last, ok := g.flagged[key]; ok && now.Sub(last) < g.cfg.Window {
+		return false
+	}
+	g.flagged[key] = now
+	return true
+}
+
+func (g *Guard) event(kind string, a Attempt, detail string, now time.Time) database.AuthSecurityEvent {
+	severity, ok := severities[kind]
+	if !ok {
+		severity = database.AuthSeverityInfo
+	}
+	return database.AuthSecurityEvent{
+		Kind:       kind,
+		Severity:   severity,
+		IP:         a.IP,
+		Endpoint:   a.Endpoint,
+		Subject:    a.Subject,
+		Detail:     detail,
+		OccurredAt: now,
+	}
+}
+
+// record stores an event and tells the hooks. A failed insert is logged and
+// the hooks are still told.
+func (g *Guard) record(e database.AuthSecurityEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if saved, err := g.db.InsertAuthSecurityEvent(ctx, e); err != nil {
+		log.Printf("❌ Failed to record auth security event %s from %s: %v", e.Kind, e.IP, err)
+	} else {
+		e = *saved
+	}
+	if e.Severity != database.AuthSeverityInfo {
+		log.Printf("🚨 Auth %s (%s) from %s on %s: %s", e.Kind, e.Severity, e.IP, e.Endpoint, e.Detail)
+	}
+	for _, fn := range g.hooks {
+		fn(e)
+	}
+}
+
+// recent returns the times in ts after since
+func recent(ts []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(ts) && !ts[i].After(since) {
+		i++
+	}
+	return ts[i:]
+}
+
+// sweep forgets failures, lockouts and anomalies that have run their course
+func (g *Guard) sweep(now time.Time) {
+	since := now.Add(-g.cfg.Window)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for ip, st := range g.ips {
+		st.failures = recent(st.failures, since)
+		if len(st.failures) == 0 && !now.Before(st.lockedUntil) {
+			delete(g.ips, ip)
+		}
+	}
+	for subject, ips := range g.failingIPs {
+		for ip, at := range ips {
+			if !at.After(since) {
+				delete(ips, ip)
+			}
+		}
+		if len(ips) == 0 {
+			delete(g.failingIPs, subject)
+		}
+	}
+	for subject, saves := range g.saves {
+		if saves = recent(saves, since); len(saves) == 0 {
+			delete(g.saves, subject)
+		} else {
+			g.saves[subject] = saves
+		}
+	}
+	for key, at := range g.flagged {
+		if !at.After(since) {
+			delete(g.flagged, key)
+		}
+	}
+}
+
+// Run sweeps expired state every minute and deletes events older than
+// RetentionDays every hour until ctx is cancelled
+func (g *Guard) Run(ctx context.Context) {
+	sweep := time.NewTicker(sweepInterval)
+	defer sweep.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-sweep.C:
+			g.sweep(now)
+		case now := <-prune.C:
+			n, err := g.db.DeleteAuthSecurityEventsBefore(ctx, now.AddDate(0, 0, -g.cfg.RetentionDays))
+			if err != nil {
+				log.Printf("❌ Failed to prune auth security events: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 Pruned %d auth security events older than %d days", n, g.cfg.RetentionDays)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package baskets evaluates alerts on user-defined baskets and announces the
+// ones that fire over WebSocket and to their owner's notification channels.
+package baskets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// evaluateInterval is how often active alerts are checked
+const evaluateInterval = time.Minute
+
+// AlertMonitor periodically values every basket with an active alert and
+// fires the alerts whose condition holds. Alerts fire once.
+type AlertMonitor struct {
+	db       database.BasketRepository
+	hub      *ws.Hub
+	notifier *notifications.Notifier
+}
+
+// NewAlertMonitor creates a monitor broadcasting on hub and notifying
+// alert owners through notifier
+func NewAlertMonitor(db database.BasketRepository, hub *ws.Hub, notifier *notifications.Notifier) *AlertMonitor {
+	return &AlertMonitor{db: db, hub: hub, notifier: notifier}
+}
+
+// Run evaluates alerts periodically until ctx is cancelled
+func (m *AlertMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(evaluateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.evaluate(ctx)
+		}
+	}
+}
+
+func (m *AlertMonitor) evaluate(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	alerts, err := m.db.ListActiveBasketAlerts(ctx)
+	if err != nil {
+		log.Printf("❌ Basket alert monitor failed to load alerts: %v", err)
+		return
+	}
+
+	valuations := map[int64]*database.BasketValuation{}
+	baskets := map[int64]*database.Basket{}
+	for _, a := range alerts {
+		v, ok := valuations[a.BasketID]
+		if !ok {
+			basket, err := m.db.GetBasket(ctx, a.AccountID, a.BasketID)
+			if err == nil && basket != nil {
+				v, err = m.db.ValueBasket(ctx, basket)
+			}
+			if err != nil {
+				log.Printf("❌ Basket alert monitor failed to value basket %d: %v", a.BasketID, err)
+			}
+			valuations[a.BasketID], baskets[a.BasketID] = v, basket
+		}
+		if v == nil {
+			continue
+		}
+
+		value, hit := a.Check(v)
+		if !hit {
+			continue
+		}
+		fired, err := m.db.TriggerBasketAlert(ctx, a.ID, value)
+		if err != nil {
+			log.Printf("❌ Failed to trigger basket alert %d: %v", a.ID, err)
+			continue
+		}
+		if !fired {
+			continue
+		}
+
+		m.hub.Broadcast(map[string]interface{}{
+			"type": "basket_alert",
+			"data": map[string]interface{}{
+				"alert_id":    a.ID,
+				"basket_id":   a.BasketID,
+				"basket_name": baskets[a.BasketID].Name,
+				"account_id":  a.AccountID,
+				"user_id":     a.UserID,
+				"condition":   a.Condition,
+				"threshold":   a.Threshold,
+				"value":       value,
+				"nav":         v.NAV,
+				"day_change":  v.DayChangePct,
+				"timestamp":   time.Now().Format(time.RFC3339),
+			},
+		})
+		m.notifier.Notify(a.UserID, notifications.Notification{
+			Kind:  notifications.KindBasketAlert,
+			Title: fmt.Sprintf("Basket alert: %s", baskets[a.BasketID].Name),
+			Body: fmt.Sprintf("%s %g hit at %.2f (NAV %.2f, day change %.2f%%)",
+				a.Condition, a.Threshold, value, v.NAV, v.DayChangePct),
+			Data: map[string]string{"basket_id": fmt.Sprint(a.BasketID)},
+		})
+	}
+}
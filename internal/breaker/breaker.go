@@ -0,0 +1,222 @@
+// Package breaker guards calls to downstream dependencies with a timeout and
+// a circuit breaker, so one slow or unreachable service fails fast instead of
+// holding up every request that touches it.
+//
+// A breaker starts closed. After failureThreshold consecutive failures it
+// opens and rejects calls with ErrOpen for cooldown; then it's half-open and
+// lets a single probe through, closing again if the probe succeeds and
+// reopening if it fails. A failure is a transport error or timeout; HTTP
+// error statuses mean the dependency answered, and are left to the caller.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// States
+const (
+	Closed   = "closed"
+	Open     = "open"
+	HalfOpen = "half_open"
+)
+
+const (
+	// failureThreshold is how many consecutive failures open a breaker
+	failureThreshold = 5
+	// cooldown is how long an open breaker rejects calls before probing
+	cooldown = 30 * time.Second
+)
+
+// ErrOpen is returned for calls rejected by an open breaker
+var ErrOpen = errors.New("circuit breaker open")
+
+// Breaker tracks the health of one dependency
+type Breaker struct {
+	name    string
+	timeout time.Duration
+
+	mu          sync.Mutex
+	state       string
+	failures    int
+	probing     bool
+	openedAt    time.Time
+	requests    int64
+	failed      int64
+	rejected    int64
+	lastError   string
+	lastFailure time.Time
+}
+
+// Status is a breaker's state as reported by the monitoring API
+type Status struct {
+	Name                string  `json:"name"`
+	State               string  `json:"state"`
+	TimeoutMs           int64   `json:"timeout_ms"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	Requests            int64   `json:"requests"`
+	Failures            int64   `json:"failures"`
+	Rejected            int64   `json:"rejected"`
+	LastError           string  `json:"last_error,omitempty"`
+	LastFailureAt       *string `json:"last_failure_at"`
+	OpenedAt            *string `json:"opened_at"`
+	RetryAt             *string `json:"retry_at"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Breaker{}
+)
+
+// New returns the breaker for a dependency, creating it with timeout as the
+// per-call deadline. Breakers are shared by name, so every caller of a
+// dependency sees the same state.
+func New(name string, timeout time.Duration) *Breaker {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if b, ok := registry[name]; ok {
+		return b
+	}
+	b := &Breaker{name: name, timeout: timeout, state: Closed}
+	registry[name] = b
+	return b
+}
+
+// Snapshot returns the status of every breaker, by name
+func Snapshot() []Status {
+	registryMu.Lock()
+	breakers := make([]*Breaker, 0, len(registry))
+	for _, b := range registry {
+		breakers = append(breakers, b)
+	}
+	registryMu.Unlock()
+
+	statuses := make([]Status, len(breakers))
+	for i, b := range breakers {
+		statuses[i] = b.Status()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Name returns the dependency name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Client returns an HTTP client that enforces the breaker's timeout and
+// fails fast with ErrOpen while it's open
+func (b *Breaker) Client() *http.Client {
+	return &http.Client{
+		Timeout:   b.timeout,
+		Transport: &transport{breaker: b, next: http.DefaultTransport},
+	}
+}
+
+// allow reports whether a call may proceed, moving an open breaker to
+// half-open once its cooldown has passed
+func (b *Breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && now.Sub(b.openedAt) >= cooldown {
+		b.state = HalfOpen
+		b.probing = false
+	}
+	switch b.state {
+	case Open:
+		b.rejected++
+		return false
+	case HalfOpen:
+		if b.probing {
+			b.rejected++
+			return false
+		}
+		b.probing = true
+	}
+	b.requests++
+	return true
+}
+
+// record updates the breaker with a call's outcome
+func (b *Breaker) record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.state = Closed
+		return
+	}
+	b.failed++
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailure = now
+	if b.state == HalfOpen || b.failures >= failureThreshold {
+		b.state = Open
+		b.openedAt = now
+	}
+}
+
+// release ends a call without recording an outcome
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Status returns the breaker's current state
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Status{
+		Name:                b.name,
+		State:               b.state,
+		TimeoutMs:           b.timeout.Milliseconds(),
+		ConsecutiveFailures: b.failures,
+		Requests:            b.requests,
+		Failures:            b.failed,
+		Rejected:            b.rejected,
+		LastError:           b.lastError,
+	}
+	if !b.lastFailure.IsZero() {
+		t := b.lastFailure.Format(time.RFC3339)
+		s.LastFailureAt = &t
+	}
+	if b.state != Closed {
+		opened := b.openedAt.Format(time.RFC3339)
+		s.OpenedAt = &opened
+	}
+	if b.state == Open {
+		retry := b.openedAt.Add(cooldown).Format(time.RFC3339)
+		s.RetryAt = &retry
+	}
+	return s
+}
+
+// transport wraps an HTTP transport with a breaker
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.allow(time.Now()) {
+		return nil, fmt.Errorf("%s: %w", t.breaker.name, ErrOpen)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil && errors.Is(req.Context().Err(), context.Canceled) {
+		// The caller gave up; that says nothing about the dependency
+		t.breaker.release()
+		return resp, err
+	}
+	t.breaker.record(err, time.Now())
+	return resp, err
+}
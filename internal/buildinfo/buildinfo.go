@@ -0,0 +1,68 @@
+// Package buildinfo identifies the running build. Commit and BuildTime are
+// set at build time:
+//
+//	go build -ldflags "-X github.com/trading-chitti/core-api-go/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/trading-chitti/core-api-go/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without ldflags they fall back to the VCS stamp the Go toolchain embeds
+// when building inside a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the API version
+const Version = "2.0.0"
+
+// Set via -ldflags -X
+var (
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	// Modified is true when built from a checkout with uncommitted changes
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info. Commit is "unknown" when neither ldflags nor
+// the VCS stamp provide it.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit
+func ShortCommit() string {
+	commit := Get().Commit
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
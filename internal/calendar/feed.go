@@ -0,0 +1,184 @@
+package calendar
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// jobEventMinutes is how long a job that runs at a fixed time is shown for
+const jobEventMinutes = 15
+
+// Job is a scheduled job as shown on the calendar
+type Job struct {
+	Name          string
+	Description   string
+	Schedule      string
+	ScheduleHuman string
+}
+
+var byDay = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Build assembles the feed. Jobs whose cron schedule can't be expressed as
+// a recurrence are left out.
+func Build(name string, holidays []database.MarketHoliday, results []database.ResultsDate, jobs []Job, now time.Time) *Calendar {
+	cal := &Calendar{Name: name, Events: []Event{}}
+
+	for _, h := range holidays {
+		day, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			continue
+		}
+		cal.Events = append(cal.Events, Event{
+			UID:        eventUID("holiday-"+h.Exchange, h.Date),
+			Summary:    h.Exchange + " holiday: " + h.Description,
+			Categories: "Holiday",
+			AllDay:     true,
+			Start:      day,
+		})
+	}
+
+	for _, r := range results {
+		day, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			continue
+		}
+		cal.Events = append(cal.Events, Event{
+			UID:         eventUID("results-"+r.Symbol, r.Date),
+			Summary:     r.Symbol + " results",
+			Description: r.Purpose,
+			Categories:  "Results",
+			AllDay:      true,
+			Start:       day,
+		})
+	}
+
+	open := database.SessionOpen(now)
+	today := time.Date(open.Year(), open.Month(), open.Day(), 0, 0, 0, 0, open.Location())
+	for _, j := range jobs {
+		if e, ok := jobEvent(j, today); ok {
+			cal.Events = append(cal.Events, e)
+		}
+	}
+	return cal
+}
+
+// jobEvent turns a five-field cron schedule into a recurring event starting
+// on the first matching day from today. Fixed-time jobs become short events;
+// jobs repeating within an hour range become one event spanning the range.
+// Day-of-month and month restrictions aren't supported.
+func jobEvent(j Job, today time.Time) (Event, bool) {
+	fields := strings.Fields(j.Schedule)
+	if len(fields) != 5 || fields[2] != "*" || fields[3] != "*" {
+		return Event{}, false
+	}
+	minute, hour, dow := fields[0], fields[1], fields[4]
+
+	days, ok := parseWeekdays(dow)
+	if !ok {
+		return Event{}, false
+	}
+	rrule := "FREQ=DAILY"
+	if len(days) < 7 {
+		codes := make([]string, len(days))
+		for i, d := range days {
+			codes[i] = byDay[d]
+		}
+		rrule = "FREQ=WEEKLY;BYDAY=" + strings.Join(codes, ",")
+	}
+
+	// First matching day, so DTSTART is itself an occurrence
+	start := today
+	for i := 0; i < 7; i++ {
+		if containsDay(days, int(start.Weekday())) {
+			break
+		}
+		start = start.AddDate(0, 0, 1)
+	}
+
+	description := j.Description + "\nSchedule: " + j.Schedule
+	if j.ScheduleHuman != "" {
+		description += " (" + j.ScheduleHuman + ")"
+	}
+	e := Event{
+		UID:         eventUID("job", j.Name),
+		Summary:     "Job: " + j.Name,
+		Description: description,
+		Categories:  "Job",
+		RRule:       rrule,
+	}
+
+	m, minuteFixed := parseInt(minute, 0, 59)
+	h, hourFixed := parseInt(hour, 0, 23)
+	repeating := minute == "*" || strings.HasPrefix(minute, "*/")
+	switch {
+	case minuteFixed && hourFixed:
+		e.Start = time.Date(start.Year(), start.Month(), start.Day(), h, m, 0, 0, start.Location())
+		e.End = e.Start.Add(jobEventMinutes * time.Minute)
+	case repeating && hour == "*":
+		e.AllDay = true
+		e.Start = start
+	case repeating:
+		from, to, ok := parseRange(hour, 0, 23)
+		if !ok {
+			return Event{}, false
+		}
+		e.Start = time.Date(start.Year(), start.Month(), start.Day(), from, 0, 0, 0, start.Location())
+		e.End = time.Date(start.Year(), start.Month(), start.Day(), to+1, 0, 0, 0, start.Location())
+	default:
+		return Event{}, false
+	}
+	return e, true
+}
+
+// parseWeekdays expands a day-of-week field of *, N, A-B or a comma list
+// of those. 7 is accepted as Sunday.
+func parseWeekdays(field string) ([]int, bool) {
+	if field == "*" {
+		return []int{0, 1, 2, 3, 4, 5, 6}, true
+	}
+	seen := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		from, to, ok := parseRange(part, 0, 7)
+		if !ok {
+			return nil, false
+		}
+		for d := from; d <= to; d++ {
+			seen[d%7] = true
+		}
+	}
+	days := []int{}
+	for d := 0; d < 7; d++ {
+		if seen[d] {
+			days = append(days, d)
+		}
+	}
+	return days, len(days) > 0
+}
+
+// parseRange parses N or A-B within [lo, hi]
+func parseRange(s string, lo, hi int) (int, int, bool) {
+	if a, b, found := strings.Cut(s, "-"); found {
+		from, ok1 := parseInt(a, lo, hi)
+		to, ok2 := parseInt(b, lo, hi)
+		return from, to, ok1 && ok2 && from <= to
+	}
+	n, ok := parseInt(s, lo, hi)
+	return n, n, ok
+}
+
+func parseInt(s string, lo, hi int) (int, bool) {
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n >= lo && n <= hi
+}
+
+func containsDay(days []int, d int) bool {
+	for _, v := range days {
+		if v == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// Package calendar renders market events and job schedules as an iCalendar
+// (RFC 5545) feed that calendar apps can subscribe to.
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// TZID is the timezone timed events are expressed in
+const TZID = "Asia/Kolkata"
+
+// vtimezone defines IST, which has no daylight saving
+const vtimezone = "BEGIN:VTIMEZONE\r\n" +
+	"TZID:" + TZID + "\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"TZOFFSETFROM:+0530\r\n" +
+	"TZOFFSETTO:+0530\r\n" +
+	"TZNAME:IST\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n"
+
+// Event is a single VEVENT. All-day events span Start's date; timed events
+// run from Start to End in IST and repeat per RRule when it's set.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  string
+	AllDay      bool
+	Start       time.Time
+	End         time.Time
+	RRule       string
+}
+
+// Calendar is a named collection of events
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Encode renders the calendar. stamp is used as every event's DTSTAMP.
+func (c *Calendar) Encode(stamp time.Time) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//trading-chitti//core-api//EN\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("METHOD:PUBLISH\r\n")
+	writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	writeLine(&b, "X-WR-TIMEZONE:"+TZID)
+	b.WriteString(vtimezone)
+
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+	for _, e := range c.Events {
+		b.WriteString("BEGIN:VEVENT\r\n")
+		writeLine(&b, "UID:"+escape(e.UID))
+		writeLine(&b, "DTSTAMP:"+dtstamp)
+		if e.AllDay {
+			writeLine(&b, "DTSTART;VALUE=DATE:"+e.Start.Format("20060102"))
+			writeLine(&b, "DTEND;VALUE=DATE:"+e.Start.AddDate(0, 0, 1).Format("20060102"))
+			b.WriteString("TRANSP:TRANSPARENT\r\n")
+		} else {
+			writeLine(&b, "DTSTART;TZID="+TZID+":"+e.Start.Format("20060102T150405"))
+			writeLine(&b, "DTEND;TZID="+TZID+":"+e.End.Format("20060102T150405"))
+		}
+		if e.RRule != "" {
+			writeLine(&b, "RRULE:"+e.RRule)
+		}
+		writeLine(&b, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escape(e.Description))
+		}
+		if e.Categories != "" {
+			writeLine(&b, "CATEGORIES:"+escape(e.Categories))
+		}
+		b.WriteString("END:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+// escape escapes TEXT values
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine folds content lines at 75 octets without splitting UTF-8
+// sequences, continuing each fold with a single space
+func writeLine(b *strings.Builder, line string) {
+	const limit = 75
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts toward the next line's length
+		width = limit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func utf8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// eventUID builds a UID unique to this feed
+func eventUID(kind, key string) string {
+	uid := strings.ToLower(kind + "-" + key)
+	return strings.ReplaceAll(uid, " ", "-") + "@trading-chitti"
+}
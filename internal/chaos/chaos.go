@@ -0,0 +1,239 @@
+// Package chaos injects faults for resilience drills: added latency on API
+// requests, a share of WebSocket messages dropped, and a simulated NATS
+// outage during which events are discarded and the connection reports down.
+//
+// Faults are held in memory, apply to this instance only and always expire,
+// so a forgotten drill can't outlive maxDuration. The controller is inert
+// unless it was created enabled (dev, or CHAOS_ENABLED=true).
+package chaos
+
+import (
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultDuration applies when a fault is injected without one
+	DefaultDuration = 5 * time.Minute
+	// maxDuration caps how long any fault may last
+	maxDuration = time.Hour
+)
+
+// latencyFault delays API requests by Delay plus up to Jitter. An empty
+// PathPrefix delays every request.
+type latencyFault struct {
+	Delay      time.Duration
+	Jitter     time.Duration
+	PathPrefix string
+	Until      time.Time
+}
+
+// dropFault discards Percent of WebSocket messages, per client
+type dropFault struct {
+	Percent float64
+	Until   time.Time
+}
+
+// outageFault simulates a NATS disconnect
+type outageFault struct {
+	Until time.Time
+}
+
+// State is the active faults and what they've done
+type State struct {
+	Enabled         bool          `json:"enabled"`
+	Latency         *LatencyState `json:"latency"`
+	WebSocketDrop   *DropState    `json:"websocket_drop"`
+	NATSDisconnect  *OutageState  `json:"nats_disconnect"`
+	DelayedRequests uint64        `json:"delayed_requests"`
+	DroppedMessages uint64        `json:"dropped_messages"`
+	DroppedEvents   uint64        `json:"dropped_events"`
+}
+
+// LatencyState is an active latency fault
+type LatencyState struct {
+	DelayMs    int64  `json:"delay_ms"`
+	JitterMs   int64  `json:"jitter_ms"`
+	PathPrefix string `json:"path_prefix,omitempty"`
+	Until      string `json:"until"`
+}
+
+// DropState is an active WebSocket drop fault
+type DropState struct {
+	Percent float64 `json:"percent"`
+	Until   string  `json:"until"`
+}
+
+// OutageState is an active NATS outage
+type OutageState struct {
+	Until string `json:"until"`
+}
+
+// Controller holds the active faults
+type Controller struct {
+	enabled bool
+
+	mu      sync.RWMutex
+	latency *latencyFault
+	drop    *dropFault
+	outage  *outageFault
+
+	delayed       atomic.Uint64
+	droppedWS     atomic.Uint64
+	droppedEvents atomic.Uint64
+}
+
+// NewController creates a controller; when disabled, faults can't be
+// injected
+func NewController(enabled bool) *Controller {
+	return &Controller{enabled: enabled}
+}
+
+// Enabled reports whether faults may be injected
+func (c *Controller) Enabled() bool {
+	return c.enabled
+}
+
+// ClampDuration applies the default to a zero duration and caps it at
+// maxDuration
+func ClampDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultDuration
+	}
+	if d > maxDuration {
+		return maxDuration
+	}
+	return d
+}
+
+// SetLatency starts delaying requests
+func (c *Controller) SetLatency(delay, jitter time.Duration, pathPrefix string, duration time.Duration) {
+	until := time.Now().Add(ClampDuration(duration))
+	c.mu.Lock()
+	c.latency = &latencyFault{Delay: delay, Jitter: jitter, PathPrefix: pathPrefix, Until: until}
+	c.mu.Unlock()
+	log.Printf("⚠️  Chaos: delaying %s requests by %s (+%s jitter) until %s",
+		orAll(pathPrefix), delay, jitter, until.Format(time.RFC3339))
+}
+
+// SetWebSocketDrop starts dropping percent of WebSocket messages
+func (c *Controller) SetWebSocketDrop(percent float64, duration time.Duration) {
+	until := time.Now().Add(ClampDuration(duration))
+	c.mu.Lock()
+	c.drop = &dropFault{Percent: percent, Until: until}
+	c.mu.Unlock()
+	log.Printf("⚠️  Chaos: dropping %.1f%% of WebSocket messages until %s", percent, until.Format(time.RFC3339))
+}
+
+// SetNATSDisconnect starts a simulated NATS outage
+func (c *Controller) SetNATSDisconnect(duration time.Duration) {
+	until := time.Now().Add(ClampDuration(duration))
+	c.mu.Lock()
+	c.outage = &outageFault{Until: until}
+	c.mu.Unlock()
+	log.Printf("⚠️  Chaos: simulating NATS disconnect until %s", until.Format(time.RFC3339))
+}
+
+// Clear removes every fault
+func (c *Controller) Clear() {
+	c.mu.Lock()
+	c.latency, c.drop, c.outage = nil, nil, nil
+	c.mu.Unlock()
+	log.Println("✅ Chaos: all faults cleared")
+}
+
+// Delay returns how long to hold a request for path, or 0
+func (c *Controller) Delay(path string) time.Duration {
+	if !c.enabled {
+		return 0
+	}
+	c.mu.RLock()
+	l := c.latency
+	c.mu.RUnlock()
+	if l == nil || time.Now().After(l.Until) || !strings.HasPrefix(path, l.PathPrefix) {
+		return 0
+	}
+
+	d := l.Delay
+	if l.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(l.Jitter)))
+	}
+	c.delayed.Add(1)
+	return d
+}
+
+// DropWebSocket reports whether to drop one WebSocket message
+func (c *Controller) DropWebSocket() bool {
+	if !c.enabled {
+		return false
+	}
+	c.mu.RLock()
+	d := c.drop
+	c.mu.RUnlock()
+	if d == nil || time.Now().After(d.Until) || rand.Float64()*100 >= d.Percent {
+		return false
+	}
+	c.droppedWS.Add(1)
+	return true
+}
+
+// NATSDown reports whether a NATS outage is being simulated
+func (c *Controller) NATSDown() bool {
+	if !c.enabled {
+		return false
+	}
+	c.mu.RLock()
+	o := c.outage
+	c.mu.RUnlock()
+	return o != nil && time.Now().Before(o.Until)
+}
+
+// DropEvent reports whether to discard an incoming NATS event, counting it
+// if so
+func (c *Controller) DropEvent() bool {
+	if !c.NATSDown() {
+		return false
+	}
+	c.droppedEvents.Add(1)
+	return true
+}
+
+// State returns the active faults; expired ones are omitted
+func (c *Controller) State() State {
+	now := time.Now()
+	s := State{
+		Enabled:         c.enabled,
+		DelayedRequests: c.delayed.Load(),
+		DroppedMessages: c.droppedWS.Load(),
+		DroppedEvents:   c.droppedEvents.Load(),
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if l := c.latency; l != nil && now.Before(l.Until) {
+		s.Latency = &LatencyState{
+			DelayMs:    l.Delay.Milliseconds(),
+			JitterMs:   l.Jitter.Milliseconds(),
+			PathPrefix: l.PathPrefix,
+			Until:      l.Until.Format(time.RFC3339),
+		}
+	}
+	if d := c.drop; d != nil && now.Before(d.Until) {
+		s.WebSocketDrop = &DropState{Percent: d.Percent, Until: d.Until.Format(time.RFC3339)}
+	}
+	if o := c.outage; o != nil && now.Before(o.Until) {
+		s.NATSDisconnect = &OutageState{Until: o.Until.Format(time.RFC3339)}
+	}
+	return s
+}
+
+func orAll(prefix string) string {
+	if prefix == "" {
+		return "all"
+	}
+	return prefix
+}
@@ -0,0 +1,92 @@
+// Package coalesce collapses concurrent identical calls into one, so a burst
+// of dashboards polling the same endpoint runs its query once.
+package coalesce
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// call is an in-flight or completed invocation
+type call struct {
+	wg   sync.WaitGroup
+	val  interface{}
+	err  error
+	dups int
+}
+
+// Group coalesces calls by key. The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+
+	executed atomic.Uint64
+	shared   atomic.Uint64
+}
+
+// Stats counts executed calls and callers that reused another's result
+type Stats struct {
+	Executed uint64 `json:"executed"`
+	Shared   uint64 `json:"shared"`
+	InFlight int    `json:"in_flight"`
+}
+
+// Do runs fn once for all concurrent callers with the same key. shared
+// reports whether the result was handed to more than one caller.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		g.shared.Add(1)
+		return c.val, true, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.executed.Add(1)
+	func() {
+		// A panicking fn must not leave waiters blocked forever
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = fmt.Errorf("coalesced call panicked: %v", r)
+			}
+		}()
+		c.val, c.err = fn()
+	}()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	dups := c.dups
+	g.mu.Unlock()
+	c.wg.Done()
+
+	return c.val, dups > 0, c.err
+}
+
+// Stats returns counters since the group was created
+func (g *Group) Stats() Stats {
+	g.mu.Lock()
+	inFlight := len(g.calls)
+	g.mu.Unlock()
+	return Stats{Executed: g.executed.Load(), Shared: g.shared.Load(), InFlight: inFlight}
+}
+
+// Do is a typed wrapper around Group.Do
+func Do[T any](g *Group, key string, fn func() (T, error)) (T, error) {
+	v, _, err := g.Do(key, func() (interface{}, error) {
+		return fn()
+	})
+	if v == nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), err
+}
@@ -0,0 +1,129 @@
+// Package compact shrinks JSON payloads for the mobile app: common field
+// names are replaced by short keys and null, zero, false and empty-string
+// values are left out. Clients expand responses with the key legend from
+// Keys and treat a missing field as its zero value.
+package compact
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// abbreviations maps full field names to their compact keys. Compact keys
+// must stay unique and stable: the mobile app caches the legend.
+var abbreviations = map[string]string{
+	"signal_id":           "id",
+	"signal_number":       "n",
+	"symbol":              "s",
+	"stock_name":          "sn",
+	"name":                "nm",
+	"sector":              "sec",
+	"signal_type":         "t",
+	"status":              "st",
+	"validation_status":   "vs",
+	"confidence_score":    "cs",
+	"confidence":          "cf",
+	"entry_price":         "ep",
+	"current_price":       "cp",
+	"exit_price":          "xp",
+	"target_price":        "tp",
+	"stop_loss":           "sl",
+	"expected_profit_pct": "epp",
+	"actual_profit_pct":   "app",
+	"success_rate_pct":    "srp",
+	"success_rate":        "sr",
+	"generated_at":        "ga",
+	"updated_at":          "ua",
+	"closed_at":           "ca",
+	"expires_at":          "xa",
+	"exit_reason":         "xr",
+	"metadata":            "md",
+	"news":                "nw",
+	"last_price":          "lp",
+	"price":               "p",
+	"open":                "o",
+	"high":                "h",
+	"low":                 "l",
+	"close":               "c",
+	"volume":              "v",
+	"change":              "ch",
+	"change_percent":      "chp",
+	"changePercent":       "chpc",
+	"marketCap":           "mc",
+	"timestamp":           "ts",
+	"quantity":            "q",
+	"signals":             "sg",
+	"active_signals":      "as",
+	"closed_signals":      "cls",
+	"statistics":          "stats",
+	"top_performers":      "tps",
+	"signal_distribution": "sd",
+	"avg_confidence":      "acf",
+	"signal_count":        "sc",
+	"count":               "cnt",
+}
+
+// Keys returns the legend mapping compact keys back to full field names
+func Keys() map[string]string {
+	legend := make(map[string]string, len(abbreviations))
+	for full, short := range abbreviations {
+		legend[short] = full
+	}
+	return legend
+}
+
+// Encode rewrites a JSON document in compact form
+func Encode(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Numbers stay as written so large IDs keep their precision
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(Transform(v))
+}
+
+// Transform abbreviates object keys and drops zero values throughout a
+// decoded JSON value. Array elements are kept even when zero so positions
+// still line up.
+func Transform(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if isZero(item) {
+				continue
+			}
+			if short, ok := abbreviations[k]; ok {
+				k = short
+			}
+			out[k] = Transform(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = Transform(item)
+		}
+		return out
+	}
+	return v
+}
+
+func isZero(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case string:
+		return val == ""
+	case json.Number:
+		f, err := val.Float64()
+		return err == nil && f == 0
+	case float64:
+		return val == 0
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+// Package compat compares this service's JSON responses with the Python
+// core-api it replaced, either live or against recorded golden fixtures, so
+// handler changes can be checked for response-shape parity.
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fetchTimeout caps one request to the legacy API
+const fetchTimeout = 15 * time.Second
+
+// ErrNoReference means a path has neither a legacy API nor a recorded fixture
+var ErrNoReference = errors.New("no legacy API or recorded fixture for path")
+
+// Config points at the legacy API and the fixture directory
+type Config struct {
+	LegacyURL   string
+	FixturesDir string
+}
+
+// ConfigFromEnv reads LEGACY_API_URL and COMPAT_FIXTURES_DIR
+// (default ./compat/fixtures)
+func ConfigFromEnv() Config {
+	cfg := Config{
+		LegacyURL:   strings.TrimRight(os.Getenv("LEGACY_API_URL"), "/"),
+		FixturesDir: os.Getenv("COMPAT_FIXTURES_DIR"),
+	}
+	if cfg.FixturesDir == "" {
+		cfg.FixturesDir = filepath.Join("compat", "fixtures")
+	}
+	return cfg
+}
+
+// Fixture is a recorded legacy response
+type Fixture struct {
+	Path       string          `json:"path"`
+	Status     int             `json:"status"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Source     string          `json:"source"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// Difference is one shape mismatch. Kind is "missing" (only in the
+// reference), "extra" (only in this service), "type" or "null".
+type Difference struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Report is the comparison of one path
+type Report struct {
+	Path           string       `json:"path"`
+	Reference      string       `json:"reference"` // "legacy" or "fixture"
+	ExpectedStatus int          `json:"expected_status"`
+	ActualStatus   int          `json:"actual_status"`
+	Compatible     bool         `json:"compatible"`
+	Differences    []Difference `json:"differences"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// Checker runs paths against this service in-process and against a reference
+type Checker struct {
+	handler http.Handler
+	cfg     Config
+	client  *http.Client
+}
+
+// NewChecker creates a checker that serves local requests through handler
+func NewChecker(handler http.Handler, cfg Config) *Checker {
+	return &Checker{handler: handler, cfg: cfg, client: &http.Client{Timeout: fetchTimeout}}
+}
+
+// LegacyConfigured reports whether live comparison is available
+func (c *Checker) LegacyConfigured() bool {
+	return c.cfg.LegacyURL != ""
+}
+
+// HasReference reports whether path can be compared, live or from a fixture
+func (c *Checker) HasReference(path string) bool {
+	if c.LegacyConfigured() {
+		return true
+	}
+	_, err := os.Stat(c.fixtureFile(path))
+	return err == nil
+}
+
+// ValidatePath accepts GET-able API paths, excluding the compat endpoints
+func ValidatePath(path string) error {
+	if !strings.HasPrefix(path, "/api/") && path != "/health" {
+		return fmt.Errorf("path must start with /api/")
+	}
+	if strings.HasPrefix(path, "/api/compat") {
+		return fmt.Errorf("compat endpoints cannot be compared")
+	}
+	return nil
+}
+
+// Diff compares path against the legacy API when configured, otherwise
+// against its recorded fixture. userID is forwarded as X-User-ID.
+func (c *Checker) Diff(ctx context.Context, path, userID string) Report {
+	report := Report{Path: path, Differences: []Difference{}}
+
+	var expected []byte
+	if c.LegacyConfigured() {
+		report.Reference = "legacy"
+		status, body, err := c.fetchLegacy(ctx, path, userID)
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		report.ExpectedStatus, expected = status, body
+	} else {
+		report.Reference = "fixture"
+		fixture, err := c.loadFixture(path)
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		report.ExpectedStatus, expected = fixture.Status, fixture.Body
+	}
+
+	status, actual := c.serveLocal(path, userID)
+	report.ActualStatus = status
+
+	var want, got interface{}
+	if err := json.Unmarshal(expected, &want); err != nil {
+		report.Error = fmt.Sprintf("reference response is not JSON: %v", err)
+		return report
+	}
+	if err := json.Unmarshal(actual, &got); err != nil {
+		report.Error = fmt.Sprintf("response is not JSON: %v", err)
+		return report
+	}
+
+	report.Differences = CompareShapes(want, got)
+	report.Compatible = report.ExpectedStatus == report.ActualStatus && len(report.Differences) == 0
+	return report
+}
+
+// DiffAll compares every recorded fixture path
+func (c *Checker) DiffAll(ctx context.Context, userID string) ([]Report, error) {
+	fixtures, err := c.Fixtures()
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]Report, 0, len(fixtures))
+	for _, f := range fixtures {
+		reports = append(reports, c.Diff(ctx, f.Path, userID))
+	}
+	return reports, nil
+}
+
+// Record fetches path from the legacy API and stores it as a golden fixture
+func (c *Checker) Record(ctx context.Context, path, userID string) (*Fixture, error) {
+	if !c.LegacyConfigured() {
+		return nil, fmt.Errorf("LEGACY_API_URL is not set")
+	}
+	status, body, err := c.fetchLegacy(ctx, path, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("legacy response for %s is not JSON", path)
+	}
+
+	fixture := &Fixture{Path: path, Status: status, RecordedAt: time.Now(), Source: c.cfg.LegacyURL, Body: body}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(c.cfg.FixturesDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures dir: %w", err)
+	}
+	if err := os.WriteFile(c.fixtureFile(path), data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return fixture, nil
+}
+
+// Fixtures lists recorded fixtures sorted by path, without their bodies
+func (c *Checker) Fixtures() ([]Fixture, error) {
+	files, err := filepath.Glob(filepath.Join(c.cfg.FixturesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := []Fixture{}
+	for _, file := range files {
+		f, err := readFixture(file)
+		if err != nil {
+			return nil, err
+		}
+		f.Body = nil
+		fixtures = append(fixtures, *f)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Path < fixtures[j].Path })
+	return fixtures, nil
+}
+
+func (c *Checker) fetchLegacy(ctx context.Context, path, userID string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.LegacyURL+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-User-ID", userID)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to call legacy API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read legacy response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// serveLocal runs path through this service's router without a network hop
+func (c *Checker) serveLocal(path, userID string) (int, []byte) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+func (c *Checker) loadFixture(path string) (*Fixture, error) {
+	f, err := readFixture(c.fixtureFile(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoReference
+	}
+	return f, err
+}
+
+// fixtureFile maps "/api/stocks/TCS?limit=5" to "api_stocks_TCS_limit_5.json"
+func (c *Checker) fixtureFile(path string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(path, "/"))
+	return filepath.Join(c.cfg.FixturesDir, name+".json")
+}
+
+func readFixture(file string) (*Fixture, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", filepath.Base(file), err)
+	}
+	return &f, nil
+}
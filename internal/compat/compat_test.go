@@ -0,0 +1,127 @@
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestCompareShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []Difference
+	}{
+		{"same shape, different values", `{"a": 1, "b": "x", "c": [{"d": true}]}`, `{"a": 2, "b": "y", "c": [{"d": false}, {"d": true}]}`, []Difference{}},
+		{"missing key", `{"a": 1, "b": 2}`, `{"a": 1}`, []Difference{{Path: "$.b", Kind: "missing", Expected: "number"}}},
+		{"extra key", `{"a": 1}`, `{"a": 1, "z": null}`, []Difference{{Path: "$.z", Kind: "extra", Actual: "null"}}},
+		{"type change", `{"a": 1}`, `{"a": "1"}`, []Difference{{Path: "$.a", Kind: "type", Expected: "number", Actual: "string"}}},
+		{"null", `{"a": {"b": 1}}`, `{"a": null}`, []Difference{{Path: "$.a", Kind: "null", Expected: "object", Actual: "null"}}},
+		{"array element", `[{"a": 1}]`, `[{"b": 1}]`, []Difference{
+			{Path: "$[].a", Kind: "missing", Expected: "number"},
+			{Path: "$[].b", Kind: "extra", Actual: "number"},
+		}},
+		{"empty array matches any", `[{"a": 1}]`, `[]`, []Difference{}},
+		{"top-level type", `{}`, `[]`, []Difference{{Path: "$", Kind: "type", Expected: "object", Actual: "array"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareShapes(decode(t, tt.expected), decode(t, tt.actual))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareShapes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	for path, ok := range map[string]bool{
+		"/api/signals":             true,
+		"/health":                  true,
+		"/api/compat/diff":         false,
+		"/metrics":                 false,
+		"http://example.com/api/x": false,
+	} {
+		if err := ValidatePath(path); (err == nil) != ok {
+			t.Errorf("ValidatePath(%q) = %v, want ok=%v", path, err, ok)
+		}
+	}
+}
+
+func TestFixtureFile(t *testing.T) {
+	c := NewChecker(nil, Config{FixturesDir: "fx"})
+	want := filepath.Join("fx", "api_stocks_TCS_limit_5.json")
+	if got := c.fixtureFile("/api/stocks/TCS?limit=5"); got != want {
+		t.Errorf("fixtureFile = %q, want %q", got, want)
+	}
+}
+
+// TestRecordAndReplay records a fixture from a stand-in legacy API and
+// replays it against compatible and drifted handlers
+func TestRecordAndReplay(t *testing.T) {
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-User-ID") != "tester" {
+			t.Errorf("legacy request user = %q, want tester", r.Header.Get("X-User-ID"))
+		}
+		w.Write([]byte(`{"signals": [{"symbol": "TCS", "confidence": 0.8}], "count": 1}`))
+	}))
+	defer legacy.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	recorder := NewChecker(nil, Config{LegacyURL: legacy.URL, FixturesDir: dir})
+	if _, err := recorder.Record(ctx, "/api/signals?limit=1", "tester"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	fixtures, err := NewChecker(nil, Config{FixturesDir: dir}).Fixtures()
+	if err != nil || len(fixtures) != 1 || fixtures[0].Path != "/api/signals?limit=1" || fixtures[0].Status != http.StatusOK {
+		t.Fatalf("Fixtures = %+v, %v", fixtures, err)
+	}
+
+	serve := func(status int, body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		})
+	}
+
+	compatible := NewChecker(serve(http.StatusOK, `{"signals": [], "count": 0}`), Config{FixturesDir: dir})
+	report := compatible.Diff(ctx, "/api/signals?limit=1", "tester")
+	if !report.Compatible || report.Reference != "fixture" {
+		t.Errorf("compatible replay = %+v", report)
+	}
+
+	drifted := NewChecker(serve(http.StatusOK, `{"signals": [{"symbol": "TCS", "confidence": "high"}]}`), Config{FixturesDir: dir})
+	report = drifted.Diff(ctx, "/api/signals?limit=1", "tester")
+	want := []Difference{
+		{Path: "$.count", Kind: "missing", Expected: "number"},
+		{Path: "$.signals[].confidence", Kind: "type", Expected: "number", Actual: "string"},
+	}
+	if report.Compatible || !reflect.DeepEqual(report.Differences, want) {
+		t.Errorf("drifted replay = %+v, want differences %+v", report, want)
+	}
+
+	failing := NewChecker(serve(http.StatusInternalServerError, `{"signals": [], "count": 0}`), Config{FixturesDir: dir})
+	if report := failing.Diff(ctx, "/api/signals?limit=1", "tester"); report.Compatible {
+		t.Errorf("replay with a different status reported compatible: %+v", report)
+	}
+
+	if report := compatible.Diff(ctx, "/api/unrecorded", "tester"); report.Error != ErrNoReference.Error() {
+		t.Errorf("unrecorded path error = %q, want %q", report.Error, ErrNoReference)
+	}
+}
@@ -0,0 +1,76 @@
+package compat
+
+import (
+	"context"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGoldenFixtures replays every recorded fixture against a running
+// instance of this service and fails on any status or shape difference:
+//
+//	COMPAT_TARGET_URL=http://localhost:8080 go test ./internal/compat/ -run GoldenFixtures
+//
+// Fixtures are read from COMPAT_FIXTURES_DIR (relative to this package),
+// default compat/fixtures at the repository root, where POST
+// /api/compat/record writes them.
+// COMPAT_USER_ID sets the X-User-ID the requests are made as.
+func TestGoldenFixtures(t *testing.T) {
+	dir := os.Getenv("COMPAT_FIXTURES_DIR")
+	if dir == "" {
+		dir = filepath.Join("..", "..", "compat", "fixtures")
+	}
+	checker := NewChecker(nil, Config{FixturesDir: dir})
+	fixtures, err := checker.Fixtures()
+	if err != nil {
+		t.Fatalf("failed to read fixtures from %s: %v", dir, err)
+	}
+	if len(fixtures) == 0 {
+		t.Skipf("no fixtures recorded in %s", dir)
+	}
+
+	target := os.Getenv("COMPAT_TARGET_URL")
+	if target == "" {
+		t.Skipf("COMPAT_TARGET_URL not set; %d fixtures not replayed", len(fixtures))
+	}
+	u, err := url.Parse(strings.TrimRight(target, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		t.Fatalf("COMPAT_TARGET_URL %q is not an absolute URL", target)
+	}
+	checker.handler = httputil.NewSingleHostReverseProxy(u)
+	userID := os.Getenv("COMPAT_USER_ID")
+
+	for _, f := range fixtures {
+		f := f
+		t.Run(f.Path, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+			defer cancel()
+			assertCompatible(t, checker.Diff(ctx, f.Path, userID))
+		})
+	}
+}
+
+// assertCompatible fails t with every difference in r
+func assertCompatible(t *testing.T, r Report) {
+	t.Helper()
+	if r.Error != "" {
+		t.Fatalf("%s: %s", r.Path, r.Error)
+	}
+	if r.ExpectedStatus != r.ActualStatus {
+		t.Errorf("%s: status %d, fixture recorded %d", r.Path, r.ActualStatus, r.ExpectedStatus)
+	}
+	for _, d := range r.Differences {
+		t.Errorf("%s: %s %s (fixture %s, got %s)", r.Path, d.Kind, d.Path, orDash(d.Expected), orDash(d.Actual))
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
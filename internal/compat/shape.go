@@ -0,0 +1,79 @@
+package compat
+
+import (
+	"fmt"
+	"sort"
+)
+
+// typeName names a decoded JSON value's type
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// CompareShapes reports structural differences between two decoded JSON
+// documents. Values are ignored; only keys, types and nullability matter.
+// Array elements are compared against the first element of the other side,
+// since list contents differ between environments.
+func CompareShapes(expected, actual interface{}) []Difference {
+	diffs := []Difference{}
+	compare("$", expected, actual, &diffs)
+	return diffs
+}
+
+func compare(path string, expected, actual interface{}, diffs *[]Difference) {
+	et, at := typeName(expected), typeName(actual)
+	if et != at {
+		kind := "type"
+		if et == "null" || at == "null" {
+			kind = "null"
+		}
+		*diffs = append(*diffs, Difference{Path: path, Kind: kind, Expected: et, Actual: at})
+		return
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a := actual.(map[string]interface{})
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ev, inExpected := e[k]
+			av, inActual := a[k]
+			switch {
+			case !inActual:
+				*diffs = append(*diffs, Difference{Path: path + "." + k, Kind: "missing", Expected: typeName(ev)})
+			case !inExpected:
+				*diffs = append(*diffs, Difference{Path: path + "." + k, Kind: "extra", Actual: typeName(av)})
+			default:
+				compare(path+"."+k, ev, av, diffs)
+			}
+		}
+	case []interface{}:
+		a := actual.([]interface{})
+		if len(e) > 0 && len(a) > 0 {
+			compare(path+"[]", e[0], a[0], diffs)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Account statuses
+const (
+	AccountActive    = "active"
+	AccountSuspended = "suspended"
+)
+
+// Account member roles. Owners manage an account's members.
+const (
+	AccountRoleOwner  = "owner"
+	AccountRoleMember = "member"
+)
+
+// ErrLastAccountOwner is returned when removing or demoting an account's
+// only owner
+var ErrLastAccountOwner = errors.New("account must keep an owner")
+
+// Account owns portfolios, baskets and their alerts, presets, journal
+// entries and watchlists. Each user has a personal account with their own
+// ID; shared accounts have generated IDs.
+type Account struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	// Role is the requesting user's role, when listing their accounts
+	Role    string          `json:"role,omitempty"`
+	Members []AccountMember `json:"members,omitempty"`
+}
+
+// AccountMember is a user's membership of an account
+type AccountMember struct {
+	UserID  string `json:"user_id"`
+	Role    string `json:"role"`
+	AddedAt string `json:"added_at"`
+}
+
+// AccountMembership is what a request needs to act within an account
+type AccountMembership struct {
+	AccountID string
+	Status    string
+	Role      string
+}
+
+// ValidAccountRole reports whether role is a known member role
+func ValidAccountRole(role string) bool {
+	return role == AccountRoleOwner || role == AccountRoleMember
+}
+
+const accountColumns = `a.id, a.name, a.status, a.created_by, a.created_at, a.updated_at`
+
+func scanAccount(row rowScanner, extra ...interface{}) (*Account, error) {
+	var a Account
+	var createdAt, updatedAt time.Time
+	dest := append([]interface{}{&a.ID, &a.Name, &a.Status, &a.CreatedBy, &createdAt, &updatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	a.CreatedAt = createdAt.Format(time.RFC3339)
+	a.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &a, nil
+}
+
+func (db *DB) queryAccounts(ctx context.Context, query string, args ...interface{}) ([]Account, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return accounts, nil
+}
+
+// ListAccounts returns every account, optionally only those with status
+func (db *DB) ListAccounts(ctx context.Context, status string) ([]Account, error) {
+	return db.queryAccounts(ctx, `
+		SELECT `+accountColumns+`
+		FROM core_api.accounts a
+		WHERE $1 = '' OR a.status = $1
+		ORDER BY a.created_at, a.id
+	`, status)
+}
+
+// ListUserAccounts returns the accounts a user is a member of, with their role
+func (db *DB) ListUserAccounts(ctx context.Context, userID string) ([]Account, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+accountColumns+`, m.role
+		FROM core_api.account_members m
+		JOIN core_api.accounts a ON a.id = m.account_id
+		WHERE m.user_id = $1
+		ORDER BY a.id <> $1, a.created_at, a.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		var role string
+		a, err := scanAccount(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		a.Role = role
+		accounts = append(accounts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetAccount returns an account with its members, or nil if not found
+func (db *DB) GetAccount(ctx context.Context, id string) (*Account, error) {
+	a, err := scanAccount(db.conn.QueryRowContext(ctx,
+		"SELECT "+accountColumns+" FROM core_api.accounts a WHERE a.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT user_id, role, added_at
+		FROM core_api.account_members
+		WHERE account_id = $1
+		ORDER BY role <> 'owner', added_at, user_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account members: %w", err)
+	}
+	defer rows.Close()
+
+	a.Members = []AccountMember{}
+	for rows.Next() {
+		var m AccountMember
+		var addedAt time.Time
+		if err := rows.Scan(&m.UserID, &m.Role, &addedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account member: %w", err)
+		}
+		m.AddedAt = addedAt.Format(time.RFC3339)
+		a.Members = append(a.Members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return a, nil
+}
+
+// CreateAccount creates a shared account with a generated ID, owned by ownerID
+func (db *DB) CreateAccount(ctx context.Context, name, ownerID string) (*Account, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate account ID: %w", err)
+	}
+	id := "acct_" + hex.EncodeToString(b)
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO core_api.accounts (id, name, created_by) VALUES ($1, $2, $3)", id, name, ownerID); err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO core_api.account_members (account_id, user_id, role) VALUES ($1, $2, 'owner')", id, ownerID); err != nil {
+		return nil, fmt.Errorf("failed to add account owner: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit account: %w", err)
+	}
+	return db.GetAccount(ctx, id)
+}
+
+// EnsurePersonalAccount creates a user's personal account, which has the
+// user's ID, if it doesn't exist yet
+func (db *DB) EnsurePersonalAccount(ctx context.Context, userID string) error {
+	_, err := db.conn.ExecContext(ctx, `
+		WITH created AS (
+			INSERT INTO core_api.accounts (id, name, created_by)
+			VALUES ($1, $1, $1)
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		)
+		INSERT INTO core_api.account_members (account_id, user_id, role)
+		SELECT id, id, 'owner' FROM created
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to create personal account: %w", err)
+	}
+	return nil
+}
+
+// GetAccountMembership returns a user's membership of an account, or nil if
+// the account doesn't exist or they aren't a member
+func (db *DB) GetAccountMembership(ctx context.Context, accountID, userID string) (*AccountMembership, error) {
+	m := AccountMembership{AccountID: accountID}
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT a.status, m.role
+		FROM core_api.accounts a
+		JOIN core_api.account_members m ON m.account_id = a.id
+		WHERE a.id = $1 AND m.user_id = $2
+	`, accountID, userID).Scan(&m.Status, &m.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account membership: %w", err)
+	}
+	return &m, nil
+}
+
+// UpdateAccount renames an account and/or changes its status, returning nil
+// if not found
+func (db *DB) UpdateAccount(ctx context.Context, id string, name, status *string) (*Account, error) {
+	res, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.accounts
+		SET name = COALESCE($2, name),
+		    status = COALESCE($3, status),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, name, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return db.GetAccount(ctx, id)
+}
+
+// PutAccountMember adds a user to an account or changes their role
+func (db *DB) PutAccountMember(ctx context.Context, accountID, userID, role string) error {
+	return db.changeOwners(ctx, accountID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.account_members (account_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (account_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		`, accountID, userID, role)
+		return err
+	})
+}
+
+// RemoveAccountMember removes a user from an account, returning false if
+// they weren't a member
+func (db *DB) RemoveAccountMember(ctx context.Context, accountID, userID string) (bool, error) {
+	var removed bool
+	err := db.changeOwners(ctx, accountID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"DELETE FROM core_api.account_members WHERE account_id = $1 AND user_id = $2", accountID, userID)
+		if err != nil {
+			return err
+		}
+		n, _ := res.RowsAffected()
+		removed = n > 0
+		return nil
+	})
+	return removed, err
+}
+
+// changeOwners runs a membership change, rolling it back with
+// ErrLastAccountOwner if it leaves the account without an owner. The
+// account row is locked so concurrent changes can't both pass the check.
+func (db *DB) changeOwners(ctx context.Context, accountID string, change func(*sql.Tx) error) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT 1 FROM core_api.accounts WHERE id = $1 FOR UPDATE", accountID); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	if err := change(tx); err != nil {
+		return fmt.Errorf("failed to change account members: %w", err)
+	}
+	var owners int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM core_api.account_members WHERE account_id = $1 AND role = 'owner'", accountID,
+	).Scan(&owners); err != nil {
+		return fmt.Errorf("failed to count account owners: %w", err)
+	}
+	if owners == 0 {
+		return ErrLastAccountOwner
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit account members: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Admin confirmation statuses. Pending requests past their deadline are
+// reported as expired.
+const (
+	ConfirmationPending   = "pending"
+	ConfirmationConfirmed = "confirmed"
+	ConfirmationCancelled = "cancelled"
+	ConfirmationExpired   = "expired"
+)
+
+// AdminConfirmation is a destructive admin action held for a second admin
+// under the two-person rule, and the audit record of how it was decided.
+// ResponseStatus is the HTTP status the action returned once confirmed.
+type AdminConfirmation struct {
+	ID             int64   `json:"id"`
+	Action         string  `json:"action"`
+	Method         string  `json:"method"`
+	Path           string  `json:"path"`
+	PayloadHash    string  `json:"payload_hash"`
+	Status         string  `json:"status"`
+	RequestedBy    string  `json:"requested_by"`
+	RequestedAt    string  `json:"requested_at"`
+	ExpiresAt      string  `json:"expires_at"`
+	DecidedBy      *string `json:"decided_by"`
+	DecidedAt      *string `json:"decided_at"`
+	ResponseStatus *int    `json:"response_status"`
+}
+
+const adminConfirmationColumns = `id, action, method, path, payload_hash,
+	CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END,
+	requested_by, requested_at, expires_at, decided_by, decided_at, response_status`
+
+func scanAdminConfirmation(row rowScanner) (*AdminConfirmation, error) {
+	var a AdminConfirmation
+	var requestedAt, expiresAt time.Time
+	var decidedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.Action, &a.Method, &a.Path, &a.PayloadHash, &a.Status,
+		&a.RequestedBy, &requestedAt, &expiresAt, &a.DecidedBy, &decidedAt, &a.ResponseStatus); err != nil {
+		return nil, err
+	}
+	a.RequestedAt = requestedAt.Format(time.RFC3339)
+	a.ExpiresAt = expiresAt.Format(time.RFC3339)
+	if decidedAt.Valid {
+		v := decidedAt.Time.Format(time.RFC3339)
+		a.DecidedAt = &v
+	}
+	return &a, nil
+}
+
+// RequestAdminConfirmation records a pending request for a second admin
+func (db *DB) RequestAdminConfirmation(ctx context.Context, action, method, path, payloadHash, requestedBy string, expiresAt time.Time) (*AdminConfirmation, error) {
+	a, err := scanAdminConfirmation(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.admin_confirmations (action, method, path, payload_hash, requested_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+adminConfirmationColumns,
+		action, method, path, payloadHash, requestedBy, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to request admin confirmation: %w", err)
+	}
+	return a, nil
+}
+
+// ListAdminConfirmations returns requests with status (all when empty),
+// newest first
+func (db *DB) ListAdminConfirmations(ctx context.Context, status string, limit int) ([]AdminConfirmation, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+adminConfirmationColumns+`
+		FROM core_api.admin_confirmations
+		WHERE $1 = ''
+			OR ($1 = 'expired' AND status = 'pending' AND expires_at <= NOW())
+			OR ($1 = 'pending' AND status = 'pending' AND expires_at > NOW())
+			OR ($1 NOT IN ('pending', 'expired') AND status = $1)
+		ORDER BY requested_at DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin confirmations: %w", err)
+	}
+	defer rows.Close()
+
+	confirmations := []AdminConfirmation{}
+	for rows.Next() {
+		a, err := scanAdminConfirmation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan admin confirmation: %w", err)
+		}
+		confirmations = append(confirmations, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return confirmations, nil
+}
+
+// GetAdminConfirmation returns a request, or nil if it doesn't exist
+func (db *DB) GetAdminConfirmation(ctx context.Context, id int64) (*AdminConfirmation, error) {
+	a, err := scanAdminConfirmation(db.conn.QueryRowContext(ctx, `
+		SELECT `+adminConfirmationColumns+`
+		FROM core_api.admin_confirmations
+		WHERE id = $1
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin confirmation: %w", err)
+	}
+	return a, nil
+}
+
+// ConfirmAdminAction marks a pending, unexpired request confirmed by
+// someone other than its requester, provided it is for the same action and
+// payload. It returns nil when no request qualifies.
+func (db *DB) ConfirmAdminAction(ctx context.Context, id int64, action, payloadHash, confirmedBy string) (*AdminConfirmation, error) {
+	a, err := scanAdminConfirmation(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.admin_confirmations
+		SET status = 'confirmed', decided_by = $4, decided_at = NOW()
+		WHERE id = $1 AND action = $2 AND payload_hash = $3 AND requested_by <> $4
+			AND status = 'pending' AND expires_at > NOW()
+		RETURNING `+adminConfirmationColumns,
+		id, action, payloadHash, confirmedBy))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm admin action: %w", err)
+	}
+	return a, nil
+}
+
+// CancelAdminConfirmation withdraws a pending request, returning nil when
+// it isn't pending
+func (db *DB) CancelAdminConfirmation(ctx context.Context, id int64, cancelledBy string) (*AdminConfirmation, error) {
+	a, err := scanAdminConfirmation(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.admin_confirmations
+		SET status = 'cancelled', decided_by = $2, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND expires_at > NOW()
+		RETURNING `+adminConfirmationColumns,
+		id, cancelledBy))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel admin confirmation: %w", err)
+	}
+	return a, nil
+}
+
+// RecordAdminActionResult stores the HTTP status a confirmed action
+// returned
+func (db *DB) RecordAdminActionResult(ctx context.Context, id int64, status int) error {
+	if _, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.admin_confirmations SET response_status = $2 WHERE id = $1
+	`, id, status); err != nil {
+		return fmt.Errorf("failed to record admin action result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BarAggregate is one level of the intraday bar roll-up chain
+type BarAggregate struct {
+	Name   string        `json:"name"`
+	Source string        `json:"source"`
+	Width  time.Duration `json:"-"`
+	// Overlap is re-aggregated on every refresh to pick up late ticks
+	Overlap time.Duration `json:"-"`
+	upsert  string
+}
+
+// rollupColumns is the upsert tail shared by the summary-table roll-ups
+const rollupColumns = `
+	ON CONFLICT (symbol, bucket) DO UPDATE SET
+		open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+		close = EXCLUDED.close, volume = EXCLUDED.volume, ticks = EXCLUDED.ticks
+`
+
+// barAggregates are refreshed in order; each level reads the previous one.
+// The upsert queries are used when TimescaleDB is disabled and take
+// ($1 from, $2 to) aligned to the level's bucket width.
+var barAggregates = []BarAggregate{
+	{
+		Name: "md.bars_1m", Source: "md.ticks", Width: time.Minute, Overlap: 5 * time.Minute,
+		upsert: `
+			INSERT INTO md.bars_1m (symbol, bucket, open, high, low, close, volume, ticks)
+			SELECT symbol, date_trunc('minute', ts) AS bucket,
+				(array_agg(last_price ORDER BY ts))[1], max(last_price), min(last_price),
+				(array_agg(last_price ORDER BY ts DESC))[1],
+				COALESCE(max(volume) - min(volume), 0), count(*)
+			FROM md.ticks
+			WHERE ts >= $1 AND ts < $2
+			GROUP BY symbol, bucket
+		` + rollupColumns,
+	},
+	{
+		Name: "md.bars_5m", Source: "md.bars_1m", Width: 5 * time.Minute, Overlap: 10 * time.Minute,
+		upsert: `
+			INSERT INTO md.bars_5m (symbol, bucket, open, high, low, close, volume, ticks)
+			SELECT symbol, to_timestamp(floor(extract(epoch FROM bucket) / 300) * 300) AS b,
+				(array_agg(open ORDER BY bucket))[1], max(high), min(low),
+				(array_agg(close ORDER BY bucket DESC))[1], sum(volume), sum(ticks)
+			FROM md.bars_1m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY symbol, b
+		` + rollupColumns,
+	},
+	{
+		Name: "md.bars_1d", Source: "md.bars_5m", Width: 24 * time.Hour, Overlap: 24 * time.Hour,
+		upsert: `
+			INSERT INTO md.bars_1d (symbol, bucket, open, high, low, close, volume, ticks)
+			SELECT symbol, date_trunc('day', bucket AT TIME ZONE 'Asia/Kolkata') AT TIME ZONE 'Asia/Kolkata' AS b,
+				(array_agg(open ORDER BY bucket))[1], max(high), min(low),
+				(array_agg(close ORDER BY bucket DESC))[1], sum(volume), sum(ticks)
+			FROM md.bars_5m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY symbol, b
+		` + rollupColumns,
+	},
+}
+
+// BarAggregates returns the roll-up chain in refresh order
+func BarAggregates() []BarAggregate {
+	return barAggregates
+}
+
+// AlignBucket truncates t to the start of its bucket. Daily buckets follow
+// the IST calendar day.
+func (a BarAggregate) AlignBucket(t time.Time) time.Time {
+	if a.Width >= 24*time.Hour {
+		ist := t.In(istLocation())
+		return time.Date(ist.Year(), ist.Month(), ist.Day(), 0, 0, 0, 0, istLocation())
+	}
+	return t.Truncate(a.Width)
+}
+
+// AggregateState is the persisted refresh progress of one roll-up
+type AggregateState struct {
+	Name           string     `json:"name"`
+	Watermark      *time.Time `json:"watermark"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastRows       int64      `json:"last_rows"`
+	LastError      *string    `json:"last_error"`
+}
+
+// RefreshBarAggregate recomputes buckets in [from, to) and returns how many
+// rows the window now holds
+func (db *DB) RefreshBarAggregate(ctx context.Context, agg BarAggregate, from, to time.Time) (int64, error) {
+	var rows int64
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		if !db.timescale {
+			result, err := conn.ExecContext(ctx, agg.upsert, from, to)
+			if err != nil {
+				return fmt.Errorf("failed to refresh %s: %w", agg.Name, err)
+			}
+			rows, err = result.RowsAffected()
+			return err
+		}
+
+		// refresh_continuous_aggregate cannot run inside a transaction, which
+		// database/sql does not open for plain Exec calls
+		if _, err := conn.ExecContext(ctx,
+			"CALL refresh_continuous_aggregate($1::regclass, $2::timestamptz, $3::timestamptz)",
+			agg.Name, from, to,
+		); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", agg.Name, err)
+		}
+
+		if err := conn.QueryRowContext(ctx,
+			fmt.Sprintf("SELECT count(*) FROM %s WHERE bucket >= $1 AND bucket < $2", agg.Name),
+			from, to,
+		).Scan(&rows); err != nil {
+			return fmt.Errorf("failed to count %s: %w", agg.Name, err)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// GetAggregateStates returns persisted roll-up progress keyed by name
+func (db *DB) GetAggregateStates(ctx context.Context) (map[string]AggregateState, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT name, watermark, last_run_at, last_duration_ms, last_rows, last_error
+		FROM core_api.aggregate_state
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aggregate state: %w", err)
+	}
+	defer rows.Close()
+
+	states := map[string]AggregateState{}
+	for rows.Next() {
+		var s AggregateState
+		var lastError sql.NullString
+		if err := rows.Scan(&s.Name, &s.Watermark, &s.LastRunAt, &s.LastDurationMs, &s.LastRows, &lastError); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate state: %w", err)
+		}
+		if lastError.Valid {
+			s.LastError = &lastError.String
+		}
+		states[s.Name] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return states, nil
+}
+
+// SaveAggregateState records the outcome of a refresh. A nil watermark keeps
+// the previous one so failed runs are retried from the same point.
+func (db *DB) SaveAggregateState(ctx context.Context, s AggregateState) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.aggregate_state (name, watermark, last_run_at, last_duration_ms, last_rows, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			watermark = COALESCE(EXCLUDED.watermark, core_api.aggregate_state.watermark),
+			last_run_at = EXCLUDED.last_run_at,
+			last_duration_ms = EXCLUDED.last_duration_ms,
+			last_rows = EXCLUDED.last_rows,
+			last_error = EXCLUDED.last_error
+	`, s.Name, s.Watermark, s.LastRunAt, s.LastDurationMs, s.LastRows, s.LastError)
+	if err != nil {
+		return fmt.Errorf("failed to save aggregate state: %w", err)
+	}
+	return nil
+}
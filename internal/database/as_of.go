@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// signalsAsOf is a table expression with intraday.signals' columns as they
+// stood at t. Signals generated after t are left out; those that closed
+// after t are shown ACTIVE, without their outcome and priced at their
+// symbol's last tick before t. t is embedded as a formatted literal so the
+// expression can stand in for the table in any query.
+func signalsAsOf(t time.Time) string {
+	at := fmt.Sprintf("'%s'::timestamptz", t.Format(time.RFC3339Nano))
+	return `(
+		SELECT s.signal_id, s.symbol, s.stock_name, s.sector, s.signal_type, s.confidence_score,
+			s.entry_price,
+			CASE WHEN c.closed THEN s.current_price ELSE COALESCE(tick.last_price, s.entry_price) END AS current_price,
+			s.stop_loss, s.target_price,
+			CASE WHEN c.closed THEN s.status ELSE 'ACTIVE' END AS status,
+			s.generated_at,
+			CASE WHEN c.closed THEN s.exit_price END AS exit_price,
+			CASE WHEN c.closed THEN s.closed_at END AS closed_at,
+			CASE WHEN c.closed THEN s.actual_profit_pct END AS actual_profit_pct,
+			s.prediction_features, s.recent_news_sentiment, s.metadata,
+			CASE WHEN c.closed THEN s.exit_reason END AS exit_reason,
+			s.time_in_force, s.expires_at, s.scaling_rules,
+			CASE WHEN c.closed THEN s.result END AS result
+		FROM intraday.signals s
+		CROSS JOIN LATERAL (
+			-- A signal closed without closed_at counts as closed once it expired
+			SELECT s.status <> 'ACTIVE' AND COALESCE(s.closed_at, s.expires_at, s.generated_at) <= ` + at + ` AS closed
+		) c
+		LEFT JOIN LATERAL (
+			SELECT last_price FROM md.ticks
+			WHERE symbol = s.symbol AND ts <= ` + at + ` AND ts > ` + at + ` - INTERVAL '1 day'
+			ORDER BY ts DESC
+			LIMIT 1
+		) tick ON NOT c.closed
+		WHERE s.generated_at <= ` + at + `
+	) signals`
+}
+
+// sessionDateOf is t's IST trading date as a SQL date literal, standing in
+// for CURRENT_DATE
+func sessionDateOf(t time.Time) string {
+	return "'" + t.In(istLocation()).Format("2006-01-02") + "'::date"
+}
+
+// GetActiveSignalsAsOf returns the signals that were active at asOf
+func (db *DB) GetActiveSignalsAsOf(ctx context.Context, asOf time.Time) ([]Signal, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalColumns+`
+		FROM `+signalsAsOf(asOf)+`
+		WHERE status = 'ACTIVE'
+		ORDER BY generated_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active signals as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	defer rows.Close()
+
+	signals := []Signal{}
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return signals, nil
+}
+
+// GetPortfolioStatsAsOf reconstructs the portfolio stats at asOf. The win
+// rate covers the 30 days before asOf's date, since that day's performance
+// row is only written after the close.
+func (db *DB) GetPortfolioStatsAsOf(ctx context.Context, asOf time.Time) (*PortfolioStats, error) {
+	stats := &PortfolioStats{}
+	today := sessionDateOf(asOf)
+	err := db.conn.QueryRowContext(ctx, `
+		WITH overall_stats AS (
+			SELECT
+				ROUND(COALESCE(SUM(successful_signals)::DECIMAL / NULLIF(SUM(successful_signals + failed_signals), 0)::DECIMAL * 100, 0)::NUMERIC, 2) as win_rate,
+				COALESCE(SUM(total_signals), 0) as total_trades,
+				COALESCE(SUM(successful_signals), 0) as winning_trades
+			FROM intraday.daily_signal_performance
+			WHERE trade_date >= `+today+` - INTERVAL '30 days' AND trade_date < `+today+`
+		),
+		active_stats AS (
+			SELECT
+				COUNT(*) as active_count,
+				ROUND(AVG(confidence_score)::NUMERIC * 100, 2) as avg_confidence
+			FROM `+signalsAsOf(asOf)+`
+			WHERE status IN ('ACTIVE', 'TRAILING_STOP')
+			AND generated_at >= `+today+`
+		),
+		best_signal AS (
+			SELECT symbol
+			FROM `+signalsAsOf(asOf)+`
+			WHERE status IN ('ACTIVE', 'TRAILING_STOP')
+			AND generated_at >= `+today+`
+			ORDER BY confidence_score DESC
+			LIMIT 1
+		)
+		SELECT
+			COALESCE(a.active_count, 0) as active_signals,
+			COALESCE(a.avg_confidence, 0) as avg_confidence,
+			COALESCE(o.win_rate, 0) as win_rate,
+			COALESCE(o.total_trades, 0) as total_trades,
+			COALESCE(o.winning_trades, 0) as winning_trades,
+			COALESCE(b.symbol, '') as best_signal
+		FROM overall_stats o
+		CROSS JOIN active_stats a
+		LEFT JOIN best_signal b ON true
+	`).Scan(&stats.ActiveSignals, &stats.AvgConfidence, &stats.WinRate, &stats.TotalTrades, &stats.WinningTrades, &stats.BestSignalToday)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio stats as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	return stats, nil
+}
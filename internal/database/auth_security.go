@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Auth security event severities
+const (
+	AuthSeverityInfo     = "info"
+	AuthSeverityWarning  = "warning"
+	AuthSeverityCritical = "critical"
+)
+
+// AuthSecurityEvent is a failed or suspicious use of the auth endpoints
+type AuthSecurityEvent struct {
+	ID       int64  `json:"id"`
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	IP       string `json:"ip"`
+	// Endpoint is the route the event was seen on
+	Endpoint string `json:"endpoint"`
+	// Subject is the broker, service or guest involved, when known
+	Subject    string    `json:"subject"`
+	Detail     string    `json:"detail"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// AuthSecurityFilter narrows ListAuthSecurityEvents; empty fields match all
+type AuthSecurityFilter struct {
+	Kind     string
+	Severity string
+	IP       string
+	Since    *time.Time
+	Limit    int
+}
+
+const authSecurityEventColumns = `id, kind, severity, ip, endpoint, subject, detail, occurred_at`
+
+func scanAuthSecurityEvent(row rowScanner) (*AuthSecurityEvent, error) {
+	var e AuthSecurityEvent
+	if err := row.Scan(&e.ID, &e.Kind, &e.Severity, &e.IP, &e.Endpoint, &e.Subject, &e.Detail, &e.OccurredAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// InsertAuthSecurityEvent stores an event and returns it with its ID
+func (db *DB) InsertAuthSecurityEvent(ctx context.Context, e AuthSecurityEvent) (*AuthSecurityEvent, error) {
+	saved, err := scanAuthSecurityEvent(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.auth_security_events (kind, severity, ip, endpoint, subject, detail, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+authSecurityEventColumns,
+		e.Kind, e.Severity, e.IP, e.Endpoint, e.Subject, e.Detail, e.OccurredAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert auth security event: %w", err)
+	}
+	return saved, nil
+}
+
+// ListAuthSecurityEvents returns events matching f, newest first
+func (db *DB) ListAuthSecurityEvents(ctx context.Context, f AuthSecurityFilter) ([]AuthSecurityEvent, error) {
+	var since interface{}
+	if f.Since != nil {
+		since = *f.Since
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+authSecurityEventColumns+`
+		FROM core_api.auth_security_events
+		WHERE ($1 = '' OR kind = $1)
+			AND ($2 = '' OR severity = $2)
+			AND ($3 = '' OR ip = $3)
+			AND ($4::timestamptz IS NULL OR occurred_at >= $4::timestamptz)
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $5
+	`, f.Kind, f.Severity, f.IP, since, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query auth security events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []AuthSecurityEvent{}
+	for rows.Next() {
+		e, err := scanAuthSecurityEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan auth security event: %w", err)
+		}
+		events = append(events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return events, nil
+}
+
+// DeleteAuthSecurityEventsBefore removes events older than before,
+// returning how many were removed
+func (db *DB) DeleteAuthSecurityEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM core_api.auth_security_events WHERE occurred_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete auth security events: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,702 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Basket alert conditions. NAV conditions compare the basket's NAV, CHANGE
+// conditions its day change in percent.
+const (
+	BasketAlertNAVAbove    = "NAV_ABOVE"
+	BasketAlertNAVBelow    = "NAV_BELOW"
+	BasketAlertChangeAbove = "CHANGE_ABOVE"
+	BasketAlertChangeBelow = "CHANGE_BELOW"
+)
+
+// ErrBasketNameTaken is returned when a user already has a basket by that name
+var ErrBasketNameTaken = errors.New("basket name already in use")
+
+// MaxBasketConstituents caps the size of a custom basket
+const MaxBasketConstituents = 50
+
+// MissingPricesError is returned when a basket can't be based because some
+// constituents have no price to anchor to
+type MissingPricesError struct {
+	Symbols []string
+}
+
+func (e *MissingPricesError) Error() string {
+	return "no price available for " + strings.Join(e.Symbols, ", ")
+}
+
+// Basket is a user-defined index of weighted symbols. NAV starts at
+// BaseValue on BaseDate and moves with each constituent's return since its
+// BasePrice.
+type Basket struct {
+	ID           int64               `json:"id"`
+	AccountID    string              `json:"account_id"`
+	UserID       string              `json:"user_id"`
+	Name         string              `json:"name"`
+	Description  *string             `json:"description"`
+	BaseValue    float64             `json:"base_value"`
+	BaseDate     string              `json:"base_date"`
+	Constituents []BasketConstituent `json:"constituents"`
+	CreatedAt    string              `json:"created_at"`
+	UpdatedAt    string              `json:"updated_at"`
+}
+
+// BasketConstituent is one symbol in a basket. Weights are relative and are
+// normalised when the basket is valued.
+type BasketConstituent struct {
+	Symbol    string  `json:"symbol"`
+	Weight    float64 `json:"weight"`
+	BasePrice float64 `json:"base_price"`
+}
+
+// BasketValuation is a basket's current NAV and day change
+type BasketValuation struct {
+	BasketID     int64                  `json:"basket_id"`
+	NAV          float64                `json:"nav"`
+	PrevNAV      float64                `json:"prev_nav"`
+	DayChange    float64                `json:"day_change"`
+	DayChangePct float64                `json:"day_change_pct"`
+	ReturnPct    float64                `json:"return_pct"`
+	Constituents []ConstituentValuation `json:"constituents"`
+	StaleSymbols []string               `json:"stale_symbols"`
+	Timestamp    string                 `json:"timestamp"`
+}
+
+// ConstituentValuation is one constituent's price and share of the NAV
+type ConstituentValuation struct {
+	Symbol        string  `json:"symbol"`
+	Weight        float64 `json:"weight"`
+	BasePrice     float64 `json:"base_price"`
+	LastPrice     float64 `json:"last_price"`
+	PrevClose     float64 `json:"prev_close"`
+	ChangePct     float64 `json:"change_pct"`
+	Contribution  float64 `json:"contribution"`
+	CurrentWeight float64 `json:"current_weight"`
+}
+
+// BasketNAVPoint is a basket's NAV at one day's close
+type BasketNAVPoint struct {
+	Date string  `json:"date"`
+	NAV  float64 `json:"nav"`
+}
+
+// BasketAlert fires once when a basket's NAV or day change crosses Threshold
+type BasketAlert struct {
+	ID             int64    `json:"id"`
+	BasketID       int64    `json:"basket_id"`
+	AccountID      string   `json:"account_id"`
+	UserID         string   `json:"user_id"`
+	Condition      string   `json:"condition"`
+	Threshold      float64  `json:"threshold"`
+	Active         bool     `json:"active"`
+	CreatedAt      string   `json:"created_at"`
+	TriggeredAt    *string  `json:"triggered_at"`
+	TriggeredValue *float64 `json:"triggered_value"`
+}
+
+// ValidBasketAlertCondition reports whether condition is a known alert condition
+func ValidBasketAlertCondition(condition string) bool {
+	switch condition {
+	case BasketAlertNAVAbove, BasketAlertNAVBelow, BasketAlertChangeAbove, BasketAlertChangeBelow:
+		return true
+	}
+	return false
+}
+
+// Check returns the value the alert compares and whether it has crossed
+func (a BasketAlert) Check(v *BasketValuation) (float64, bool) {
+	switch a.Condition {
+	case BasketAlertNAVAbove:
+		return v.NAV, v.NAV >= a.Threshold
+	case BasketAlertNAVBelow:
+		return v.NAV, v.NAV <= a.Threshold
+	case BasketAlertChangeAbove:
+		return v.DayChangePct, v.DayChangePct >= a.Threshold
+	case BasketAlertChangeBelow:
+		return v.DayChangePct, v.DayChangePct <= a.Threshold
+	}
+	return 0, false
+}
+
+const basketColumns = `id, account_id, user_id, name, description, base_value, base_date, created_at, updated_at`
+
+func scanBasket(row rowScanner) (*Basket, error) {
+	var b Basket
+	var baseDate, createdAt, updatedAt time.Time
+	err := row.Scan(&b.ID, &b.AccountID, &b.UserID, &b.Name, &b.Description, &b.BaseValue, &baseDate, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan basket: %w", err)
+	}
+	b.BaseDate = baseDate.Format("2006-01-02")
+	b.CreatedAt = createdAt.Format(time.RFC3339)
+	b.UpdatedAt = updatedAt.Format(time.RFC3339)
+	b.Constituents = []BasketConstituent{}
+	return &b, nil
+}
+
+// ListBaskets returns all baskets owned by an account with their constituents
+func (db *DB) ListBaskets(ctx context.Context, accountID string) ([]Basket, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+basketColumns+`
+		FROM core_api.baskets
+		WHERE account_id = $1
+		ORDER BY created_at
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query baskets: %w", err)
+	}
+	defer rows.Close()
+
+	baskets := []Basket{}
+	index := map[int64]int{}
+	for rows.Next() {
+		b, err := scanBasket(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[b.ID] = len(baskets)
+		baskets = append(baskets, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(baskets) == 0 {
+		return baskets, nil
+	}
+
+	crows, err := db.conn.QueryContext(ctx, `
+		SELECT c.basket_id, c.symbol, c.weight, c.base_price
+		FROM core_api.basket_constituents c
+		JOIN core_api.baskets b ON b.id = c.basket_id
+		WHERE b.account_id = $1
+		ORDER BY c.basket_id, c.weight DESC, c.symbol
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query basket constituents: %w", err)
+	}
+	defer crows.Close()
+
+	for crows.Next() {
+		var basketID int64
+		var bc BasketConstituent
+		if err := crows.Scan(&basketID, &bc.Symbol, &bc.Weight, &bc.BasePrice); err != nil {
+			return nil, fmt.Errorf("failed to scan basket constituent: %w", err)
+		}
+		if i, ok := index[basketID]; ok {
+			baskets[i].Constituents = append(baskets[i].Constituents, bc)
+		}
+	}
+	if err := crows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return baskets, nil
+}
+
+// GetBasket returns a single basket owned by an account, or nil if not found
+func (db *DB) GetBasket(ctx context.Context, accountID string, id int64) (*Basket, error) {
+	b, err := scanBasket(db.conn.QueryRowContext(ctx, `
+		SELECT `+basketColumns+`
+		FROM core_api.baskets
+		WHERE id = $1 AND account_id = $2
+	`, id, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.Constituents, err = db.getBasketConstituents(ctx, db.conn, id); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (db *DB) getBasketConstituents(ctx context.Context, q queryer, basketID int64) ([]BasketConstituent, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT symbol, weight, base_price
+		FROM core_api.basket_constituents
+		WHERE basket_id = $1
+		ORDER BY weight DESC, symbol
+	`, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query basket constituents: %w", err)
+	}
+	defer rows.Close()
+
+	constituents := []BasketConstituent{}
+	for rows.Next() {
+		var bc BasketConstituent
+		if err := rows.Scan(&bc.Symbol, &bc.Weight, &bc.BasePrice); err != nil {
+			return nil, fmt.Errorf("failed to scan basket constituent: %w", err)
+		}
+		constituents = append(constituents, bc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return constituents, nil
+}
+
+// basePrices anchors each symbol to its latest traded price, falling back to
+// the most recent daily close
+func (db *DB) basePrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	prices, err := db.GetLatestPrices(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, s := range symbols {
+		if prices[s] <= 0 {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) == 0 {
+		return prices, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT DISTINCT ON (symbol) symbol, close
+		FROM md.bhavcopy
+		WHERE symbol = ANY($1) AND series = 'EQ' AND close > 0
+		ORDER BY symbol, trade_date DESC
+	`, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last closes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var close float64
+		if err := rows.Scan(&symbol, &close); err != nil {
+			return nil, fmt.Errorf("failed to scan last close: %w", err)
+		}
+		prices[symbol] = close
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	missing = missing[:0]
+	for _, s := range symbols {
+		if prices[s] <= 0 {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingPricesError{Symbols: missing}
+	}
+	return prices, nil
+}
+
+// replaceBasketConstituents rewrites a basket's constituents at the given
+// base prices
+func replaceBasketConstituents(ctx context.Context, tx *sql.Tx, basketID int64, weights map[string]float64, prices map[string]float64) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM core_api.basket_constituents WHERE basket_id = $1", basketID); err != nil {
+		return fmt.Errorf("failed to clear basket constituents: %w", err)
+	}
+	for symbol, weight := range weights {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.basket_constituents (basket_id, symbol, weight, base_price)
+			VALUES ($1, $2, $3, $4)
+		`, basketID, symbol, weight, prices[symbol]); err != nil {
+			return fmt.Errorf("failed to insert basket constituent: %w", err)
+		}
+	}
+	return nil
+}
+
+// basketWriteError maps a unique violation on (account_id, name) to ErrBasketNameTaken
+func basketWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrBasketNameTaken
+	}
+	return err
+}
+
+func weightSymbols(weights map[string]float64) []string {
+	symbols := make([]string, 0, len(weights))
+	for s := range weights {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// CreateBasket inserts an account's basket based at baseValue on today's
+// prices. weights maps symbol to relative weight.
+func (db *DB) CreateBasket(ctx context.Context, accountID, userID, name string, description *string, baseValue float64, weights map[string]float64) (*Basket, error) {
+	prices, err := db.basePrices(ctx, weightSymbols(weights))
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := scanBasket(tx.QueryRowContext(ctx, `
+		INSERT INTO core_api.baskets (account_id, user_id, name, description, base_value, base_date)
+		VALUES ($1, $2, $3, $4, $5, $6::date)
+		RETURNING `+basketColumns,
+		accountID, userID, name, description, baseValue, time.Now().In(istLocation()).Format("2006-01-02")))
+	if err != nil {
+		return nil, basketWriteError(err)
+	}
+	if err := replaceBasketConstituents(ctx, tx, b.ID, weights, prices); err != nil {
+		return nil, err
+	}
+	if b.Constituents, err = db.getBasketConstituents(ctx, tx, b.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit basket: %w", err)
+	}
+	return b, nil
+}
+
+// UpdateBasket updates a basket's name and/or description and, when weights
+// is non-nil, replaces its constituents. Changing constituents rebases the
+// basket at its current NAV so the series stays continuous.
+func (db *DB) UpdateBasket(ctx context.Context, accountID string, id int64, name, description *string, weights map[string]float64) (*Basket, error) {
+	var rebaseValue *float64
+	var prices map[string]float64
+	if weights != nil {
+		current, err := db.GetBasket(ctx, accountID, id)
+		if err != nil || current == nil {
+			return nil, err
+		}
+		valuation, err := db.ValueBasket(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		nav := valuation.NAV
+		rebaseValue = &nav
+		if prices, err = db.basePrices(ctx, weightSymbols(weights)); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := scanBasket(tx.QueryRowContext(ctx, `
+		UPDATE core_api.baskets
+		SET name = COALESCE($3, name),
+		    description = COALESCE($4, description),
+		    base_value = COALESCE($5, base_value),
+		    base_date = CASE WHEN $5::double precision IS NULL THEN base_date ELSE $6::date END,
+		    updated_at = NOW()
+		WHERE id = $1 AND account_id = $2
+		RETURNING `+basketColumns,
+		id, accountID, name, description, rebaseValue, time.Now().In(istLocation()).Format("2006-01-02")))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, basketWriteError(err)
+	}
+	if weights != nil {
+		if err := replaceBasketConstituents(ctx, tx, id, weights, prices); err != nil {
+			return nil, err
+		}
+	}
+	if b.Constituents, err = db.getBasketConstituents(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit basket: %w", err)
+	}
+	return b, nil
+}
+
+// DeleteBasket removes a basket, its constituents and alerts, returning false if not found
+func (db *DB) DeleteBasket(ctx context.Context, accountID string, id int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.baskets WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete basket: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ValueBasket computes a basket's NAV from its constituents' latest prices
+// and its day change from their previous closes. Constituents without a
+// price today are held at their base price and listed in StaleSymbols.
+func (db *DB) ValueBasket(ctx context.Context, b *Basket) (*BasketValuation, error) {
+	symbols := make([]string, len(b.Constituents))
+	for i, bc := range b.Constituents {
+		symbols[i] = bc.Symbol
+	}
+
+	type quote struct{ last, prevClose float64 }
+	quotes := make(map[string]quote, len(symbols))
+	if len(symbols) > 0 {
+		rows, err := db.conn.QueryContext(ctx, `
+			SELECT DISTINCT ON (symbol) symbol, COALESCE(last_price, 0), COALESCE(close, 0)
+			FROM md.realtime_prices
+			WHERE symbol = ANY($1)
+			ORDER BY symbol, updated_at DESC
+		`, symbols)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query basket prices: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var symbol string
+			var q quote
+			if err := rows.Scan(&symbol, &q.last, &q.prevClose); err != nil {
+				return nil, fmt.Errorf("failed to scan basket price: %w", err)
+			}
+			quotes[symbol] = q
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows iteration error: %w", err)
+		}
+	}
+
+	var totalWeight float64
+	for _, bc := range b.Constituents {
+		totalWeight += bc.Weight
+	}
+
+	v := &BasketValuation{
+		BasketID:     b.ID,
+		Constituents: make([]ConstituentValuation, 0, len(b.Constituents)),
+		StaleSymbols: []string{},
+		Timestamp:    time.Now().Format(time.RFC3339),
+	}
+	if totalWeight <= 0 {
+		v.NAV, v.PrevNAV = b.BaseValue, b.BaseValue
+		return v, nil
+	}
+
+	values := make([]float64, 0, len(b.Constituents))
+	for _, bc := range b.Constituents {
+		q := quotes[bc.Symbol]
+		if q.last <= 0 {
+			q.last = bc.BasePrice
+			v.StaleSymbols = append(v.StaleSymbols, bc.Symbol)
+		}
+		if q.prevClose <= 0 {
+			q.prevClose = q.last
+		}
+
+		w := bc.Weight / totalWeight
+		value := b.BaseValue * w * q.last / bc.BasePrice
+		prevValue := b.BaseValue * w * q.prevClose / bc.BasePrice
+		v.NAV += value
+		v.PrevNAV += prevValue
+		values = append(values, value)
+		v.Constituents = append(v.Constituents, ConstituentValuation{
+			Symbol:       bc.Symbol,
+			Weight:       round2(w * 100),
+			BasePrice:    bc.BasePrice,
+			LastPrice:    q.last,
+			PrevClose:    q.prevClose,
+			ChangePct:    round2(pctChange(q.prevClose, q.last)),
+			Contribution: round2(value - prevValue),
+		})
+	}
+	for i, value := range values {
+		v.Constituents[i].CurrentWeight = round2(value / v.NAV * 100)
+	}
+
+	v.DayChange = round2(v.NAV - v.PrevNAV)
+	v.DayChangePct = round2(pctChange(v.PrevNAV, v.NAV))
+	v.ReturnPct = round2(pctChange(b.BaseValue, v.NAV))
+	v.NAV = round2(v.NAV)
+	v.PrevNAV = round2(v.PrevNAV)
+	return v, nil
+}
+
+// GetBasketHistory returns the basket's NAV at each daily close from its
+// base date (or from, if later) onwards. A constituent missing a day's close
+// carries its previous one forward.
+func (db *DB) GetBasketHistory(ctx context.Context, b *Basket, from time.Time) ([]BasketNAVPoint, error) {
+	baseDate, err := time.Parse("2006-01-02", b.BaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid basket base date %q: %w", b.BaseDate, err)
+	}
+
+	symbols := make([]string, len(b.Constituents))
+	var totalWeight float64
+	for i, bc := range b.Constituents {
+		symbols[i] = bc.Symbol
+		totalWeight += bc.Weight
+	}
+	points := []BasketNAVPoint{}
+	if totalWeight <= 0 {
+		return points, nil
+	}
+
+	closes, err := db.GetDailyCloses(ctx, symbols, baseDate, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	dateSet := map[string]bool{}
+	for _, byDate := range closes {
+		for d := range byDate {
+			dateSet[d] = true
+		}
+	}
+	dates := make([]string, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	last := make(map[string]float64, len(b.Constituents))
+	for _, bc := range b.Constituents {
+		last[bc.Symbol] = bc.BasePrice
+	}
+	fromDate := from.Format("2006-01-02")
+	for _, d := range dates {
+		var nav float64
+		for _, bc := range b.Constituents {
+			if c, ok := closes[bc.Symbol][d]; ok && c > 0 {
+				last[bc.Symbol] = c
+			}
+			nav += b.BaseValue * (bc.Weight / totalWeight) * last[bc.Symbol] / bc.BasePrice
+		}
+		if d >= fromDate {
+			points = append(points, BasketNAVPoint{Date: d, NAV: round2(nav)})
+		}
+	}
+	return points, nil
+}
+
+func scanBasketAlert(row rowScanner) (*BasketAlert, error) {
+	var a BasketAlert
+	var createdAt time.Time
+	var triggeredAt sql.NullTime
+	err := row.Scan(&a.ID, &a.BasketID, &a.AccountID, &a.UserID, &a.Condition, &a.Threshold, &a.Active,
+		&createdAt, &triggeredAt, &a.TriggeredValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan basket alert: %w", err)
+	}
+	a.CreatedAt = createdAt.Format(time.RFC3339)
+	if triggeredAt.Valid {
+		t := triggeredAt.Time.Format(time.RFC3339)
+		a.TriggeredAt = &t
+	}
+	return &a, nil
+}
+
+const basketAlertColumns = `a.id, a.basket_id, b.account_id, b.user_id, a.condition, a.threshold, a.active,
+		       a.created_at, a.triggered_at, a.triggered_value`
+
+func (db *DB) queryBasketAlerts(ctx context.Context, where string, args ...interface{}) ([]BasketAlert, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+basketAlertColumns+`
+		FROM core_api.basket_alerts a
+		JOIN core_api.baskets b ON b.id = a.basket_id
+		WHERE `+where+`
+		ORDER BY a.created_at, a.id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query basket alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []BasketAlert{}
+	for rows.Next() {
+		a, err := scanBasketAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return alerts, nil
+}
+
+// ListBasketAlerts returns every alert on a basket, triggered or not
+func (db *DB) ListBasketAlerts(ctx context.Context, basketID int64) ([]BasketAlert, error) {
+	return db.queryBasketAlerts(ctx, "a.basket_id = $1", basketID)
+}
+
+// ListActiveBasketAlerts returns alerts that have not yet fired, across all baskets
+func (db *DB) ListActiveBasketAlerts(ctx context.Context) ([]BasketAlert, error) {
+	return db.queryBasketAlerts(ctx, "a.active")
+}
+
+// CreateBasketAlert adds an alert to a basket
+func (db *DB) CreateBasketAlert(ctx context.Context, basketID int64, condition string, threshold float64) (*BasketAlert, error) {
+	return scanBasketAlert(db.conn.QueryRowContext(ctx, `
+		WITH a AS (
+			INSERT INTO core_api.basket_alerts (basket_id, condition, threshold)
+			VALUES ($1, $2, $3)
+			RETURNING *
+		)
+		SELECT `+basketAlertColumns+`
+		FROM a
+		JOIN core_api.baskets b ON b.id = a.basket_id
+	`, basketID, condition, threshold))
+}
+
+// DeleteBasketAlert removes an alert from a basket, returning false if not found
+func (db *DB) DeleteBasketAlert(ctx context.Context, basketID, alertID int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.basket_alerts WHERE id = $1 AND basket_id = $2", alertID, basketID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete basket alert: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// TriggerBasketAlert deactivates an alert at the value that fired it. It
+// returns false if the alert had already fired or was deleted, so each alert
+// is announced once even with several instances evaluating.
+func (db *DB) TriggerBasketAlert(ctx context.Context, alertID int64, value float64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.basket_alerts
+		SET active = false, triggered_at = NOW(), triggered_value = $2
+		WHERE id = $1 AND active
+	`, alertID, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to trigger basket alert: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func pctChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MarketHoliday is a day an exchange is closed
+type MarketHoliday struct {
+	Exchange    string `json:"exchange"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+// ResultsDate is a scheduled financial results announcement
+type ResultsDate struct {
+	Symbol  string `json:"symbol"`
+	Date    string `json:"date"`
+	Purpose string `json:"purpose"`
+}
+
+// GetMarketHolidays returns exchange holidays between from and to, inclusive
+func (db *DB) GetMarketHolidays(ctx context.Context, from, to time.Time) ([]MarketHoliday, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT exchange, holiday_date, description
+		FROM md.market_holidays
+		WHERE holiday_date BETWEEN $1::date AND $2::date
+		ORDER BY holiday_date, exchange
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query market holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := []MarketHoliday{}
+	for rows.Next() {
+		var h MarketHoliday
+		var date time.Time
+		if err := rows.Scan(&h.Exchange, &date, &h.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan market holiday: %w", err)
+		}
+		h.Date = date.Format("2006-01-02")
+		holidays = append(holidays, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return holidays, nil
+}
+
+// GetResultsDates returns results announcements from md.corporate_actions
+// between from and to, inclusive. Feeds record them either as action_type
+// RESULTS or as a board meeting whose purpose mentions results.
+func (db *DB) GetResultsDates(ctx context.Context, from, to time.Time) ([]ResultsDate, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, ex_date, COALESCE(purpose, 'Financial Results')
+		FROM md.corporate_actions
+		WHERE (action_type = 'RESULTS' OR purpose ILIKE '%result%')
+			AND ex_date BETWEEN $1::date AND $2::date
+		ORDER BY ex_date, symbol
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query results dates: %w", err)
+	}
+	defer rows.Close()
+
+	dates := []ResultsDate{}
+	for rows.Next() {
+		var r ResultsDate
+		var date time.Time
+		if err := rows.Scan(&r.Symbol, &date, &r.Purpose); err != nil {
+			return nil, fmt.Errorf("failed to scan results date: %w", err)
+		}
+		r.Date = date.Format("2006-01-02")
+		dates = append(dates, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return dates, nil
+}
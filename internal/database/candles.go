@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Candle is an OHLCV bar built from ticks
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int64     `json:"volume"`
+	Ticks  int64     `json:"ticks"`
+}
+
+// candleIntervals are the supported bucket widths
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// candleOrigin aligns buckets to the NSE open (09:15 IST) so hourly and daily
+// candles start with the session rather than on the clock hour
+var candleOrigin = time.Date(2000, 1, 3, 9, 15, 0, 0, istLocation())
+
+// CandleIntervalNames lists the supported candle intervals, shortest first
+func CandleIntervalNames() []string {
+	names := make([]string, 0, len(candleIntervals))
+	for name := range candleIntervals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return candleIntervals[names[i]] < candleIntervals[names[j]] })
+	return names
+}
+
+// CandleInterval returns the bucket width for an interval name
+func CandleInterval(name string) (time.Duration, bool) {
+	d, ok := candleIntervals[name]
+	return d, ok
+}
+
+// GetCandles aggregates md.ticks into OHLCV candles for [from, to). Tick volume
+// is the broker's cumulative day volume, so a candle's volume is the increase
+// across the bucket. With TimescaleDB enabled this uses time_bucket with the
+// first/last aggregates; otherwise buckets are computed from the epoch.
+func (db *DB) GetCandles(ctx context.Context, symbol, interval string, from, to time.Time) ([]Candle, error) {
+	width, ok := candleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %q", interval)
+	}
+
+	var query string
+	var args []interface{}
+	if db.timescale {
+		query = `
+			SELECT time_bucket($2::interval, ts, $5::timestamptz) AS bucket,
+				first(last_price, ts)::float8, max(last_price)::float8, min(last_price)::float8,
+				last(last_price, ts)::float8, COALESCE(max(volume) - min(volume), 0)::bigint, count(*)
+			FROM md.ticks
+			WHERE symbol = $1 AND ts >= $3 AND ts < $4
+			GROUP BY bucket
+			ORDER BY bucket
+		`
+		args = []interface{}{symbol, fmt.Sprintf("%d seconds", int64(width.Seconds())), from, to, candleOrigin}
+	} else {
+		query = `
+			SELECT to_timestamp(floor((extract(epoch FROM ts) - $5) / $2) * $2 + $5) AS bucket,
+				((array_agg(last_price ORDER BY ts))[1])::float8, max(last_price)::float8, min(last_price)::float8,
+				((array_agg(last_price ORDER BY ts DESC))[1])::float8,
+				COALESCE(max(volume) - min(volume), 0)::bigint, count(*)
+			FROM md.ticks
+			WHERE symbol = $1 AND ts >= $3 AND ts < $4
+			GROUP BY bucket
+			ORDER BY bucket
+		`
+		args = []interface{}{symbol, width.Seconds(), from, to, candleOrigin.Unix()}
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles: %w", err)
+	}
+	defer rows.Close()
+
+	candles := []Candle{}
+	for rows.Next() {
+		var c Candle
+		if err := rows.Scan(&c.Time, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume, &c.Ticks); err != nil {
+			return nil, fmt.Errorf("failed to scan candle: %w", err)
+		}
+		candles = append(candles, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return candles, nil
+}
@@ -0,0 +1,334 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Indian listed-equity capital gains rules
+var (
+	// Shares bought on or before this date are grandfathered to the 31-Jan-2018 FMV
+	grandfatheringCutoff = time.Date(2018, 1, 31, 23, 59, 59, 0, istLocation())
+	// Finance (No. 2) Act 2024 rate change for transfers on or after this date
+	rateChangeDate = time.Date(2024, 7, 23, 0, 0, 0, 0, istLocation())
+)
+
+// RealizedLot is a FIFO-matched buy lot closed (fully or partly) by a sell
+type RealizedLot struct {
+	Symbol            string   `json:"symbol"`
+	Exchange          string   `json:"exchange"`
+	Quantity          float64  `json:"quantity"`
+	BuyDate           string   `json:"buy_date"`
+	BuyPrice          float64  `json:"buy_price"`
+	SellDate          string   `json:"sell_date"`
+	SellPrice         float64  `json:"sell_price"`
+	HoldingDays       int      `json:"holding_days"`
+	Term              string   `json:"term"`
+	CostOfAcquisition float64  `json:"cost_of_acquisition"`
+	SaleValue         float64  `json:"sale_value"`
+	Gain              float64  `json:"gain"`
+	Grandfathered     bool     `json:"grandfathered"`
+	FMV2018           *float64 `json:"fmv_31jan2018,omitempty"`
+
+	buyTime  time.Time
+	sellTime time.Time
+}
+
+// UnmatchedSell is sell quantity with no open buy lot left to match it, usually
+// a holding bought before the ledger starts
+type UnmatchedSell struct {
+	Symbol   string  `json:"symbol"`
+	Exchange string  `json:"exchange"`
+	SellDate string  `json:"sell_date"`
+	Quantity float64 `json:"quantity"`
+
+	sellTime time.Time
+}
+
+// CapitalGainsReport summarises realized gains for a financial year
+type CapitalGainsReport struct {
+	PortfolioID   int64           `json:"portfolio_id"`
+	FinancialYear string          `json:"financial_year"`
+	PeriodStart   string          `json:"period_start"`
+	PeriodEnd     string          `json:"period_end"`
+	Lots          []RealizedLot   `json:"lots"`
+	STCG          float64         `json:"stcg"`
+	LTCG          float64         `json:"ltcg"`
+	LTCGExemption float64         `json:"ltcg_exemption"`
+	TaxableLTCG   float64         `json:"taxable_ltcg"`
+	EstimatedTax  float64         `json:"estimated_tax"`
+	MissingFMV    []string        `json:"missing_fmv_symbols"`
+	Unmatched     []UnmatchedSell `json:"unmatched_sells"`
+	Notes         []string        `json:"notes"`
+	Timestamp     string          `json:"timestamp"`
+}
+
+// ParseFinancialYear converts "2024-25" into its IST start/end bounds
+func ParseFinancialYear(fy string) (time.Time, time.Time, error) {
+	parts := strings.Split(fy, "-")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("financial year must look like 2024-25")
+	}
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 2000 || start > 2100 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid financial year start: %s", parts[0])
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil || end != (start+1)%100 {
+		return time.Time{}, time.Time{}, fmt.Errorf("financial year must span consecutive years")
+	}
+
+	loc := istLocation()
+	from := time.Date(start, 4, 1, 0, 0, 0, 0, loc)
+	to := time.Date(start+1, 4, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	return from, to, nil
+}
+
+// GetCapitalGainsReport matches sells to buys FIFO across the full ledger and
+// reports lots sold within the financial year
+func (db *DB) GetCapitalGainsReport(ctx context.Context, portfolioID int64, fy string) (*CapitalGainsReport, error) {
+	from, to, err := ParseFinancialYear(fy)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := db.ListPortfolioTransactions(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	allLots, allUnmatched, err := MatchFIFOLots(txns)
+	if err != nil {
+		return nil, err
+	}
+	unmatched := []UnmatchedSell{}
+	for _, u := range allUnmatched {
+		if !u.sellTime.Before(from) && !u.sellTime.After(to) {
+			unmatched = append(unmatched, u)
+		}
+	}
+
+	lots := []RealizedLot{}
+	needFMV := map[string]bool{}
+	for _, lot := range allLots {
+		if lot.sellTime.Before(from) || lot.sellTime.After(to) {
+			continue
+		}
+		if !lot.buyTime.After(grandfatheringCutoff) {
+			needFMV[lot.Symbol] = true
+		}
+		lots = append(lots, lot)
+	}
+
+	fmv := map[string]float64{}
+	if len(needFMV) > 0 {
+		symbols := make([]string, 0, len(needFMV))
+		for s := range needFMV {
+			symbols = append(symbols, s)
+		}
+		if fmv, err = db.getGrandfatheringFMV(ctx, symbols); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &CapitalGainsReport{
+		PortfolioID:   portfolioID,
+		FinancialYear: fy,
+		PeriodStart:   from.Format("2006-01-02"),
+		PeriodEnd:     to.Format("2006-01-02"),
+		Lots:          lots,
+		MissingFMV:    []string{},
+		Unmatched:     unmatched,
+		Notes:         []string{},
+		Timestamp:     time.Now().Format(time.RFC3339),
+	}
+
+	missing := map[string]bool{}
+	var stcgOld, stcgNew, ltcgOld, ltcgNew float64
+	for i := range report.Lots {
+		lot := &report.Lots[i]
+		lot.SaleValue = lot.SellPrice * lot.Quantity
+		lot.CostOfAcquisition = lot.BuyPrice * lot.Quantity
+
+		// Section 112A grandfathering: cost = max(actual, min(FMV, sale value))
+		if lot.Term == "LTCG" && !lot.buyTime.After(grandfatheringCutoff) {
+			if price, ok := fmv[lot.Symbol]; ok {
+				p := price
+				lot.FMV2018 = &p
+				grandfathered := math.Max(lot.CostOfAcquisition, math.Min(price*lot.Quantity, lot.SaleValue))
+				lot.Grandfathered = grandfathered != lot.CostOfAcquisition
+				lot.CostOfAcquisition = grandfathered
+			} else if !missing[lot.Symbol] {
+				missing[lot.Symbol] = true
+				report.MissingFMV = append(report.MissingFMV, lot.Symbol)
+			}
+		}
+
+		lot.Gain = lot.SaleValue - lot.CostOfAcquisition
+		newRegime := !lot.sellTime.Before(rateChangeDate)
+		switch {
+		case lot.Term == "STCG" && newRegime:
+			stcgNew += lot.Gain
+		case lot.Term == "STCG":
+			stcgOld += lot.Gain
+		case newRegime:
+			ltcgNew += lot.Gain
+		default:
+			ltcgOld += lot.Gain
+		}
+	}
+
+	report.STCG = stcgOld + stcgNew
+	report.LTCG = ltcgOld + ltcgNew
+
+	// Exemption and rates follow the regime in force; FY 2024-25 straddles both
+	report.LTCGExemption = 100000
+	if !to.Before(rateChangeDate) {
+		report.LTCGExemption = 125000
+	}
+	report.TaxableLTCG = math.Max(0, report.LTCG-report.LTCGExemption)
+
+	tax := math.Max(0, stcgOld)*0.15 + math.Max(0, stcgNew)*0.20
+	if report.TaxableLTCG > 0 && report.LTCG > 0 {
+		// Apportion the exemption across regimes by share of gains
+		taxableShare := report.TaxableLTCG / report.LTCG
+		tax += math.Max(0, ltcgOld)*taxableShare*0.10 + math.Max(0, ltcgNew)*taxableShare*0.125
+	}
+	report.EstimatedTax = math.Round(tax*100) / 100
+
+	if len(report.Unmatched) > 0 {
+		report.Notes = append(report.Notes, "Some sells exceed the buys recorded in the ledger; their gains are not included")
+	}
+	if len(report.MissingFMV) > 0 {
+		report.Notes = append(report.Notes, "31-Jan-2018 FMV unavailable for some pre-2018 lots; actual cost used")
+	}
+	report.Notes = append(report.Notes, "Estimated tax excludes surcharge, cess and STT; set-off of losses is not applied")
+
+	return report, nil
+}
+
+// MatchFIFOLots replays the ledger and pairs each sell with the oldest open buys.
+// Buy fees are capitalised into cost; sell fees reduce the sale price. Lots are
+// matched per symbol whatever the exchange, since NSE and BSE shares are the
+// same holding; sell quantity left without a buy is returned as unmatched.
+func MatchFIFOLots(txns []PortfolioTransaction) ([]RealizedLot, []UnmatchedSell, error) {
+	type openLot struct {
+		qty     float64
+		price   float64
+		buyTime time.Time
+	}
+
+	open := map[string][]*openLot{}
+	lots := []RealizedLot{}
+	unmatched := []UnmatchedSell{}
+
+	for _, t := range txns {
+		tradedAt, err := time.Parse(time.RFC3339, t.TradedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid traded_at on transaction %d: %w", t.ID, err)
+		}
+		key := t.Symbol
+
+		switch t.TxnType {
+		case "BUY":
+			if t.Quantity <= 0 {
+				continue
+			}
+			open[key] = append(open[key], &openLot{
+				qty:     t.Quantity,
+				price:   (t.Quantity*t.Price + t.Fees) / t.Quantity,
+				buyTime: tradedAt,
+			})
+		case "SELL":
+			if t.Quantity <= 0 {
+				continue
+			}
+			sellPrice := (t.Quantity*t.Price - t.Fees) / t.Quantity
+			remaining := t.Quantity
+			queue := open[key]
+			for remaining > 0 && len(queue) > 0 {
+				head := queue[0]
+				matched := math.Min(head.qty, remaining)
+
+				holdingDays := int(tradedAt.Sub(head.buyTime).Hours() / 24)
+				term := "STCG"
+				// Listed equity is long-term when held for more than 12 months
+				if tradedAt.After(head.buyTime.AddDate(1, 0, 0)) {
+					term = "LTCG"
+				}
+
+				lots = append(lots, RealizedLot{
+					Symbol:      t.Symbol,
+					Exchange:    t.Exchange,
+					Quantity:    matched,
+					BuyDate:     head.buyTime.Format("2006-01-02"),
+					BuyPrice:    head.price,
+					SellDate:    tradedAt.Format("2006-01-02"),
+					SellPrice:   sellPrice,
+					HoldingDays: holdingDays,
+					Term:        term,
+					buyTime:     head.buyTime,
+					sellTime:    tradedAt,
+				})
+
+				head.qty -= matched
+				remaining -= matched
+				if head.qty <= 1e-9 {
+					queue = queue[1:]
+				}
+			}
+			open[key] = queue
+			if remaining > 1e-9 {
+				unmatched = append(unmatched, UnmatchedSell{
+					Symbol:   t.Symbol,
+					Exchange: t.Exchange,
+					SellDate: tradedAt.Format("2006-01-02"),
+					Quantity: remaining,
+					sellTime: tradedAt,
+				})
+			}
+		}
+	}
+
+	return lots, unmatched, nil
+}
+
+// getGrandfatheringFMV returns the highest price quoted on 31-Jan-2018 per symbol
+func (db *DB) getGrandfatheringFMV(ctx context.Context, symbols []string) (map[string]float64, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, MAX(high)
+		FROM md.bhavcopy
+		WHERE trade_date = DATE '2018-01-31' AND symbol = ANY($1)
+		GROUP BY symbol
+	`, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query 2018 FMV: %w", err)
+	}
+	defer rows.Close()
+
+	fmv := map[string]float64{}
+	for rows.Next() {
+		var symbol string
+		var high float64
+		if err := rows.Scan(&symbol, &high); err != nil {
+			return nil, fmt.Errorf("failed to scan FMV: %w", err)
+		}
+		fmv[symbol] = high
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return fmv, nil
+}
+
+// istLocation returns Asia/Kolkata, falling back to a fixed +05:30 zone
+func istLocation() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+	return time.FixedZone("IST", 5*3600+1800)
+}
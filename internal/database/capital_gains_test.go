@@ -0,0 +1,40 @@
+package database
+
+import "testing"
+
+func TestMatchFIFOLotsAcrossExchanges(t *testing.T) {
+	txns := []PortfolioTransaction{
+		{ID: 1, Symbol: "RELIANCE", Exchange: "NSE", TxnType: "BUY", Quantity: 10, Price: 2400, TradedAt: "2024-01-10T10:00:00+05:30"},
+		{ID: 2, Symbol: "RELIANCE", Exchange: "BSE", TxnType: "BUY", Quantity: 5, Price: 2500, TradedAt: "2024-02-10T10:00:00+05:30"},
+		{ID: 3, Symbol: "RELIANCE", Exchange: "BSE", TxnType: "SELL", Quantity: 12, Price: 2600, TradedAt: "2024-03-10T10:00:00+05:30"},
+		{ID: 4, Symbol: "RELIANCE", Exchange: "NSE", TxnType: "SELL", Quantity: 5, Price: 2700, TradedAt: "2024-04-10T10:00:00+05:30"},
+	}
+	lots, unmatched, err := MatchFIFOLots(txns)
+	if err != nil {
+		t.Fatalf("MatchFIFOLots: %v", err)
+	}
+
+	// The BSE sell closes the NSE lot first, then 2 of the BSE lot; the
+	// NSE sell takes the remaining 3 and leaves 2 unmatched
+	want := []struct {
+		qty, buyPrice float64
+		sellDate      string
+	}{
+		{10, 2400, "2024-03-10"},
+		{2, 2500, "2024-03-10"},
+		{3, 2500, "2024-04-10"},
+	}
+	if len(lots) != len(want) {
+		t.Fatalf("got %d lots, want %d: %+v", len(lots), len(want), lots)
+	}
+	for i, w := range want {
+		if lots[i].Quantity != w.qty || lots[i].BuyPrice != w.buyPrice || lots[i].SellDate != w.sellDate {
+			t.Errorf("lot %d = %v @ %v sold %s, want %v @ %v sold %s",
+				i, lots[i].Quantity, lots[i].BuyPrice, lots[i].SellDate, w.qty, w.buyPrice, w.sellDate)
+		}
+	}
+
+	if len(unmatched) != 1 || unmatched[0].Quantity != 2 || unmatched[0].SellDate != "2024-04-10" {
+		t.Errorf("unmatched = %+v, want 2 RELIANCE sold 2024-04-10", unmatched)
+	}
+}
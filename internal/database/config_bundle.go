@@ -0,0 +1,320 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config bundle identification; Version changes when a section's shape does
+const (
+	ConfigBundleFormat  = "trading-chitti-config"
+	ConfigBundleVersion = 1
+)
+
+// ConfigBundle is the environment configuration exported for disaster
+// recovery. Alert rules are basket alerts and notification channels are
+// report subscriptions. Strategies aren't stored in core-api's database, so
+// they aren't part of the bundle.
+type ConfigBundle struct {
+	Format               string                      `json:"format"`
+	Version              int                         `json:"version"`
+	ExportedAt           string                      `json:"exported_at"`
+	SystemConfig         []SystemConfigEntry         `json:"system_config"`
+	StockConfig          []StockToggle               `json:"stock_config"`
+	AlertRules           []ConfigAlertRule           `json:"alert_rules"`
+	NotificationChannels []ConfigNotificationChannel `json:"notification_channels"`
+}
+
+// SystemConfigEntry is a md.system_config row
+type SystemConfigEntry struct {
+	Key         string  `json:"key"`
+	Value       *string `json:"value"`
+	Description *string `json:"description"`
+}
+
+// StockToggle is a md.stock_config row's switches
+type StockToggle struct {
+	Symbol            string  `json:"symbol"`
+	Exchange          string  `json:"exchange"`
+	Active            bool    `json:"active"`
+	IntradayEnabled   bool    `json:"intraday_enabled"`
+	InvestmentEnabled bool    `json:"investment_enabled"`
+	Fetcher           *string `json:"fetcher"`
+}
+
+// ConfigAlertRule is an untriggered basket alert, identified by its
+// basket's owner and name since IDs differ between environments
+type ConfigAlertRule struct {
+	// AccountID owns the basket; bundles exported before accounts existed
+	// only have UserID, whose personal account is used
+	AccountID string  `json:"account_id,omitempty"`
+	UserID    string  `json:"user_id"`
+	Basket    string  `json:"basket"`
+	Condition string  `json:"condition"`
+	Threshold float64 `json:"threshold"`
+}
+
+// ConfigNotificationChannel is a report subscription's delivery settings
+type ConfigNotificationChannel struct {
+	UserID      string `json:"user_id"`
+	ReportType  string `json:"report_type"`
+	Channel     string `json:"channel"`
+	Destination string `json:"destination"`
+	SendTime    string `json:"send_time"`
+	Weekday     *int   `json:"weekday"`
+	Active      bool   `json:"active"`
+}
+
+// ConfigImportCount tallies what an import did to one section
+type ConfigImportCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// ConfigImportResult reports an import, or what it would do on a dry run
+type ConfigImportResult struct {
+	DryRun   bool                          `json:"dry_run"`
+	Sections map[string]*ConfigImportCount `json:"sections"`
+	Warnings []string                      `json:"warnings"`
+}
+
+// ExportConfigBundle reads the current configuration
+func (db *DB) ExportConfigBundle(ctx context.Context) (*ConfigBundle, error) {
+	b := &ConfigBundle{
+		Format:               ConfigBundleFormat,
+		Version:              ConfigBundleVersion,
+		ExportedAt:           time.Now().Format(time.RFC3339),
+		SystemConfig:         []SystemConfigEntry{},
+		StockConfig:          []StockToggle{},
+		AlertRules:           []ConfigAlertRule{},
+		NotificationChannels: []ConfigNotificationChannel{},
+	}
+
+	if err := db.exportRows(ctx, `
+		SELECT config_key, config_value, description
+		FROM md.system_config
+		ORDER BY config_key
+	`, func(rows *sql.Rows) error {
+		var e SystemConfigEntry
+		if err := rows.Scan(&e.Key, &e.Value, &e.Description); err != nil {
+			return err
+		}
+		b.SystemConfig = append(b.SystemConfig, e)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to export system config: %w", err)
+	}
+
+	if err := db.exportRows(ctx, `
+		SELECT symbol, exchange, active, intraday_enabled, investment_enabled, fetcher
+		FROM md.stock_config
+		ORDER BY symbol, exchange
+	`, func(rows *sql.Rows) error {
+		var t StockToggle
+		if err := rows.Scan(&t.Symbol, &t.Exchange, &t.Active, &t.IntradayEnabled, &t.InvestmentEnabled, &t.Fetcher); err != nil {
+			return err
+		}
+		b.StockConfig = append(b.StockConfig, t)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to export stock config: %w", err)
+	}
+
+	if err := db.exportRows(ctx, `
+		SELECT b.account_id, b.user_id, b.name, a.condition, a.threshold
+		FROM core_api.basket_alerts a
+		JOIN core_api.baskets b ON b.id = a.basket_id
+		WHERE a.active AND a.triggered_at IS NULL
+		ORDER BY b.account_id, b.name, a.id
+	`, func(rows *sql.Rows) error {
+		var r ConfigAlertRule
+		if err := rows.Scan(&r.AccountID, &r.UserID, &r.Basket, &r.Condition, &r.Threshold); err != nil {
+			return err
+		}
+		b.AlertRules = append(b.AlertRules, r)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to export alert rules: %w", err)
+	}
+
+	if err := db.exportRows(ctx, `
+		SELECT user_id, report_type, channel, destination, send_time, weekday, active
+		FROM core_api.report_subscriptions
+		ORDER BY user_id, id
+	`, func(rows *sql.Rows) error {
+		var ch ConfigNotificationChannel
+		var weekday sql.NullInt16
+		if err := rows.Scan(&ch.UserID, &ch.ReportType, &ch.Channel, &ch.Destination, &ch.SendTime, &weekday, &ch.Active); err != nil {
+			return err
+		}
+		if weekday.Valid {
+			w := int(weekday.Int16)
+			ch.Weekday = &w
+		}
+		b.NotificationChannels = append(b.NotificationChannels, ch)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to export notification channels: %w", err)
+	}
+
+	return b, nil
+}
+
+func (db *DB) exportRows(ctx context.Context, query string, scan func(*sql.Rows) error) error {
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ImportConfigBundle applies a validated bundle in one transaction. Existing
+// settings are updated in place and nothing is deleted. nextRuns holds the
+// next send time of each notification channel, in bundle order. A dry run
+// rolls the transaction back and reports what would have changed.
+func (db *DB) ImportConfigBundle(ctx context.Context, b *ConfigBundle, nextRuns []time.Time, dryRun bool) (*ConfigImportResult, error) {
+	if len(nextRuns) != len(b.NotificationChannels) {
+		return nil, errors.New("a next run is needed for every notification channel")
+	}
+	result := &ConfigImportResult{
+		DryRun: dryRun,
+		Sections: map[string]*ConfigImportCount{
+			"system_config":         {},
+			"stock_config":          {},
+			"alert_rules":           {},
+			"notification_channels": {},
+		},
+		Warnings: []string{},
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := result.Sections["system_config"]
+	for _, e := range b.SystemConfig {
+		var inserted bool
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO md.system_config (config_key, config_value, description, updated_by)
+			VALUES ($1, $2, $3, 'config-import')
+			ON CONFLICT (config_key) DO UPDATE SET
+				config_value = EXCLUDED.config_value,
+				description = COALESCE(EXCLUDED.description, system_config.description),
+				updated_by = EXCLUDED.updated_by,
+				updated_at = NOW()
+			RETURNING xmax = 0
+		`, e.Key, e.Value, e.Description).Scan(&inserted); err != nil {
+			return nil, fmt.Errorf("failed to import config %s: %w", e.Key, err)
+		}
+		tally(count, inserted)
+	}
+
+	count = result.Sections["stock_config"]
+	for _, t := range b.StockConfig {
+		var inserted bool
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO md.stock_config (symbol, exchange, active, intraday_enabled, investment_enabled, fetcher)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (symbol, exchange) DO UPDATE SET
+				active = EXCLUDED.active,
+				intraday_enabled = EXCLUDED.intraday_enabled,
+				investment_enabled = EXCLUDED.investment_enabled,
+				fetcher = EXCLUDED.fetcher,
+				updated_at = NOW()
+			RETURNING xmax = 0
+		`, t.Symbol, t.Exchange, t.Active, t.IntradayEnabled, t.InvestmentEnabled, t.Fetcher).Scan(&inserted); err != nil {
+			return nil, fmt.Errorf("failed to import stock config %s:%s: %w", t.Symbol, t.Exchange, err)
+		}
+		tally(count, inserted)
+	}
+
+	count = result.Sections["alert_rules"]
+	for _, r := range b.AlertRules {
+		accountID := r.AccountID
+		if accountID == "" {
+			accountID = r.UserID
+		}
+		var basketID int64
+		err := tx.QueryRowContext(ctx,
+			"SELECT id FROM core_api.baskets WHERE account_id = $1 AND name = $2", accountID, r.Basket,
+		).Scan(&basketID)
+		if errors.Is(err, sql.ErrNoRows) {
+			count.Skipped++
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("alert rule skipped: basket %q of account %q does not exist", r.Basket, accountID))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up basket %q: %w", r.Basket, err)
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.basket_alerts (basket_id, condition, threshold)
+			SELECT $1::bigint, $2::text, $3::double precision
+			WHERE NOT EXISTS (
+				SELECT 1 FROM core_api.basket_alerts
+				WHERE basket_id = $1 AND condition = $2 AND threshold = $3
+					AND active AND triggered_at IS NULL
+			)
+		`, basketID, r.Condition, r.Threshold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import alert rule: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			count.Created++
+		} else {
+			count.Skipped++
+		}
+	}
+
+	count = result.Sections["notification_channels"]
+	for i, ch := range b.NotificationChannels {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE core_api.report_subscriptions
+			SET send_time = $5, weekday = $6, active = $7, next_run_at = $8, updated_at = NOW()
+			WHERE user_id = $1 AND report_type = $2 AND channel = $3 AND destination = $4
+		`, ch.UserID, ch.ReportType, ch.Channel, ch.Destination, ch.SendTime, ch.Weekday, ch.Active, nextRuns[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to import notification channel: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			count.Updated++
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.report_subscriptions
+				(user_id, report_type, channel, destination, send_time, weekday, active, next_run_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, ch.UserID, ch.ReportType, ch.Channel, ch.Destination, ch.SendTime, ch.Weekday, ch.Active, nextRuns[i]); err != nil {
+			return nil, fmt.Errorf("failed to import notification channel: %w", err)
+		}
+		count.Created++
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit config import: %w", err)
+	}
+	return result, nil
+}
+
+func tally(c *ConfigImportCount, inserted bool) {
+	if inserted {
+		c.Created++
+	} else {
+		c.Updated++
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SymbolCoverage is the market data an intraday-enabled symbol received in
+// a window
+type SymbolCoverage struct {
+	Symbol   string     `json:"symbol"`
+	Ticks    int64      `json:"ticks"`
+	LastTick *time.Time `json:"last_tick"`
+	Bars     int64      `json:"bars"`
+	LastBar  *time.Time `json:"last_bar"`
+}
+
+// IntradayCoverage compares the intraday universe with the data received
+type IntradayCoverage struct {
+	Symbols []SymbolCoverage
+	// Unexpected counts symbols that received ticks without being enabled
+	Unexpected int
+}
+
+// GetIntradayCoverage returns tick and 1-minute bar counts in [from, to)
+// for every active intraday-enabled symbol
+func (db *DB) GetIntradayCoverage(ctx context.Context, from, to time.Time) (*IntradayCoverage, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH enabled AS (
+			SELECT DISTINCT symbol FROM md.stock_config WHERE intraday_enabled AND active
+		),
+		t AS (
+			SELECT symbol, COUNT(*) AS ticks, MAX(ts) AS last_tick
+			FROM md.ticks
+			WHERE ts >= $1 AND ts < $2
+			GROUP BY symbol
+		),
+		b AS (
+			SELECT symbol, COUNT(*) AS bars, MAX(bucket) AS last_bar
+			FROM md.bars_1m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY symbol
+		)
+		SELECT e.symbol, COALESCE(t.ticks, 0), t.last_tick, COALESCE(b.bars, 0), b.last_bar,
+			(SELECT COUNT(*) FROM t WHERE t.symbol NOT IN (SELECT symbol FROM enabled))
+		FROM enabled e
+		LEFT JOIN t ON t.symbol = e.symbol
+		LEFT JOIN b ON b.symbol = e.symbol
+		ORDER BY e.symbol
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query intraday coverage: %w", err)
+	}
+	defer rows.Close()
+
+	coverage := &IntradayCoverage{Symbols: []SymbolCoverage{}}
+	for rows.Next() {
+		var s SymbolCoverage
+		var lastTick, lastBar sql.NullTime
+		if err := rows.Scan(&s.Symbol, &s.Ticks, &lastTick, &s.Bars, &lastBar, &coverage.Unexpected); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol coverage: %w", err)
+		}
+		if lastTick.Valid {
+			s.LastTick = &lastTick.Time
+		}
+		if lastBar.Valid {
+			s.LastBar = &lastBar.Time
+		}
+		coverage.Symbols = append(coverage.Symbols, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return coverage, nil
+}
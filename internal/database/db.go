@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -12,7 +13,11 @@ import (
 )
 
 type DB struct {
-	conn *sql.DB
+	conn      *sql.DB
+	analytics *sql.DB
+	policy    TimeoutPolicy
+	timescale bool
+	stmts     *stmtCache
 }
 
 // GetConn returns the underlying database connection
@@ -20,6 +25,22 @@ func (db *DB) GetConn() *sql.DB {
 	return db.conn
 }
 
+// Ping checks that the database is reachable
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+// SetTimescale enables TimescaleDB features: hypertable migrations,
+// time_bucket aggregation and compression policies. Call before Migrate.
+func (db *DB) SetTimescale(enabled bool) {
+	db.timescale = enabled
+}
+
+// TimescaleEnabled reports whether TimescaleDB features are enabled
+func (db *DB) TimescaleEnabled() bool {
+	return db.timescale
+}
+
 // NullRawMessage is a wrapper for json.RawMessage that handles NULL values
 type NullRawMessage struct {
 	RawMessage json.RawMessage
@@ -71,50 +92,90 @@ type Signal struct {
 	ExitReason          *string         `json:"exit_reason"`
 	Sector              string          `json:"sector"`
 	StockName           string          `json:"stock_name"`
+	TimeInForce         *string         `json:"time_in_force"`
+	ExpiresAt           *time.Time      `json:"expires_at"`
+	ScalingRules        NullRawMessage  `json:"scaling_rules"`
+}
+
+// signalColumns are selected wherever a Signal is scanned
+const signalColumns = `
+	signal_id, symbol, stock_name, sector, signal_type, confidence_score, entry_price, current_price,
+	stop_loss, target_price, status, generated_at, exit_price, closed_at, actual_profit_pct,
+	prediction_features, recent_news_sentiment, metadata, exit_reason,
+	time_in_force, expires_at, scaling_rules`
+
+// scanSignal reads one row selected with signalColumns
+func scanSignal(row rowScanner) (*Signal, error) {
+	var s Signal
+	err := row.Scan(
+		&s.SignalID, &s.Symbol, &s.StockName, &s.Sector, &s.SignalType, &s.ConfidenceScore, &s.EntryPrice,
+		&s.CurrentPrice, &s.StopLoss, &s.TargetPrice, &s.Status, &s.GeneratedAt,
+		&s.ExitPrice, &s.ClosedAt, &s.ActualProfitPct, &s.PredictionFeatures,
+		&s.RecentNewsSentiment, &s.Metadata, &s.ExitReason,
+		&s.TimeInForce, &s.ExpiresAt, &s.ScalingRules,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan signal: %w", err)
+	}
+	return &s, nil
 }
 
-// NewDB creates a new database connection
-func NewDB(dsn string) (*DB, error) {
-	conn, err := sql.Open("postgres", dsn)
+// NewDB creates a new database connection. Interactive and analytics
+// queries use separate pools with their own statement timeouts.
+func NewDB(dsn string, policy TimeoutPolicy) (*DB, error) {
+	prepares := newPrepareCounter()
+	connector, err := newSessionConnector(dsn, policy.Interactive, prepares)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	conn := sql.OpenDB(connector)
 
 	// Set connection pool settings
 	conn.SetMaxOpenConns(25)
 	conn.SetMaxIdleConns(5)
 	conn.SetConnMaxLifetime(5 * time.Minute)
 
+	analyticsConnector, err := newSessionConnector(dsn, policy.Analytics, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open analytics pool: %w", err)
+	}
+	analytics := sql.OpenDB(analyticsConnector)
+	analytics.SetMaxOpenConns(policy.AnalyticsMaxConns)
+	analytics.SetMaxIdleConns(1)
+	analytics.SetConnMaxLifetime(5 * time.Minute)
+
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		analytics.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("✅ Database connected")
-	return &DB{conn: conn}, nil
+	log.Printf("✅ Database connected (statement_timeout %s interactive, %s analytics)", policy.Interactive, policy.Analytics)
+	return &DB{conn: conn, analytics: analytics, policy: policy, stmts: newStmtCache(prepares)}, nil
 }
 
 // Close closes the database connection
 func (db *DB) Close() error {
+	db.closeStmts()
+	db.analytics.Close()
 	return db.conn.Close()
 }
 
 // GetActiveSignals retrieves active signals from the database
 func (db *DB) GetActiveSignals(ctx context.Context) ([]Signal, error) {
 	query := `
-		SELECT
-			signal_id, symbol, stock_name, sector, signal_type, confidence_score, entry_price, current_price,
-			stop_loss, target_price, status, generated_at, exit_price, closed_at, actual_profit_pct,
-			prediction_features, recent_news_sentiment, metadata, exit_reason
+		SELECT ` + signalColumns + `
 		FROM intraday.signals
 		WHERE status = 'ACTIVE'
 		ORDER BY generated_at DESC
 	`
 
-	rows, err := db.conn.QueryContext(ctx, query)
+	rows, err := db.queryHot(ctx, "active_signals", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active signals: %w", err)
 	}
@@ -122,17 +183,11 @@ func (db *DB) GetActiveSignals(ctx context.Context) ([]Signal, error) {
 
 	var signals []Signal
 	for rows.Next() {
-		var s Signal
-		err := rows.Scan(
-			&s.SignalID, &s.Symbol, &s.StockName, &s.Sector, &s.SignalType, &s.ConfidenceScore, &s.EntryPrice,
-			&s.CurrentPrice, &s.StopLoss, &s.TargetPrice, &s.Status, &s.GeneratedAt,
-			&s.ExitPrice, &s.ClosedAt, &s.ActualProfitPct, &s.PredictionFeatures,
-			&s.RecentNewsSentiment, &s.Metadata, &s.ExitReason,
-		)
+		s, err := scanSignal(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan signal: %w", err)
+			return nil, err
 		}
-		signals = append(signals, s)
+		signals = append(signals, *s)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
@@ -143,11 +198,22 @@ func (db *DB) GetActiveSignals(ctx context.Context) ([]Signal, error) {
 
 // GetAllSignals retrieves all signals with optional filters
 func (db *DB) GetAllSignals(ctx context.Context, limit int, status string) ([]Signal, error) {
+	var signals []Signal
+	err := db.StreamSignals(ctx, limit, status, func(s Signal) error {
+		signals = append(signals, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+// StreamSignals calls fn for each signal matching the filters, newest first,
+// without buffering the result set. An error from fn stops the scan.
+func (db *DB) StreamSignals(ctx context.Context, limit int, status string, fn func(Signal) error) error {
 	query := `
-		SELECT
-			signal_id, symbol, stock_name, sector, signal_type, confidence_score, entry_price, current_price,
-			stop_loss, target_price, status, generated_at, exit_price, closed_at, actual_profit_pct,
-			prediction_features, recent_news_sentiment, metadata, exit_reason
+		SELECT ` + signalColumns + `
 		FROM intraday.signals
 		WHERE 1=1
 	`
@@ -167,55 +233,40 @@ func (db *DB) GetAllSignals(ctx context.Context, limit int, status string) ([]Si
 
 	rows, err := db.conn.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query signals: %w", err)
+		return fmt.Errorf("failed to query signals: %w", err)
 	}
 	defer rows.Close()
 
-	var signals []Signal
 	for rows.Next() {
-		var s Signal
-		err := rows.Scan(
-			&s.SignalID, &s.Symbol, &s.StockName, &s.Sector, &s.SignalType, &s.ConfidenceScore, &s.EntryPrice,
-			&s.CurrentPrice, &s.StopLoss, &s.TargetPrice, &s.Status, &s.GeneratedAt,
-			&s.ExitPrice, &s.ClosedAt, &s.ActualProfitPct, &s.PredictionFeatures,
-			&s.RecentNewsSentiment, &s.Metadata, &s.ExitReason,
-		)
+		s, err := scanSignal(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan signal: %w", err)
+			return err
+		}
+		if err := fn(*s); err != nil {
+			return err
 		}
-		signals = append(signals, s)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return fmt.Errorf("rows iteration error: %w", err)
 	}
-
-	return signals, nil
+	return nil
 }
 
 // GetSignalByID retrieves a single signal by ID
 func (db *DB) GetSignalByID(ctx context.Context, signalID string) (*Signal, error) {
 	query := `
-		SELECT
-			signal_id, symbol, stock_name, sector, signal_type, confidence_score, entry_price, current_price,
-			stop_loss, target_price, status, generated_at, exit_price, closed_at, actual_profit_pct,
-			prediction_features, recent_news_sentiment, metadata, exit_reason
+		SELECT ` + signalColumns + `
 		FROM intraday.signals
 		WHERE signal_id = $1
 	`
 
-	var s Signal
-	err := db.conn.QueryRowContext(ctx, query, signalID).Scan(
-		&s.SignalID, &s.Symbol, &s.StockName, &s.Sector, &s.SignalType, &s.ConfidenceScore, &s.EntryPrice,
-		&s.CurrentPrice, &s.StopLoss, &s.TargetPrice, &s.Status, &s.GeneratedAt,
-		&s.ExitPrice, &s.ClosedAt, &s.ActualProfitPct, &s.PredictionFeatures,
-		&s.RecentNewsSentiment, &s.Metadata, &s.ExitReason,
-	)
-	if err == sql.ErrNoRows {
+	s, err := scanSignal(db.conn.QueryRowContext(ctx, query, signalID))
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get signal: %w", err)
+		return nil, err
 	}
 
-	return &s, nil
+	return s, nil
 }
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeadLetter is a NATS event that could not be decoded
+type DeadLetter struct {
+	ID            int64      `json:"id"`
+	Subject       string     `json:"subject"`
+	Payload       string     `json:"payload"`
+	Error         string     `json:"error"`
+	ReceivedAt    time.Time  `json:"received_at"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error"`
+	ReprocessedAt *time.Time `json:"reprocessed_at"`
+}
+
+// RecordDeadLetter stores the raw payload of an event that failed to decode
+func (db *DB) RecordDeadLetter(ctx context.Context, subject string, payload []byte, decodeErr string) error {
+	if _, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.event_dead_letters (subject, payload, error)
+		VALUES ($1, $2, $3)
+	`, subject, payload, decodeErr); err != nil {
+		return fmt.Errorf("failed to record dead letter: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns dead letters newest first, optionally filtered by
+// subject and restricted to those not yet reprocessed
+func (db *DB) ListDeadLetters(ctx context.Context, subject string, pending bool, limit int) ([]DeadLetter, error) {
+	query := `
+		SELECT id, subject, payload, error, received_at, attempts, last_error, reprocessed_at
+		FROM core_api.event_dead_letters
+		WHERE ($1 = '' OR subject = $1)
+	`
+	if pending {
+		query += " AND reprocessed_at IS NULL"
+	}
+	query += " ORDER BY received_at DESC LIMIT $2"
+
+	rows, err := db.conn.QueryContext(ctx, query, subject, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []DeadLetter{}
+	for rows.Next() {
+		var d DeadLetter
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.Subject, &payload, &d.Error, &d.ReceivedAt, &d.Attempts, &d.LastError, &d.ReprocessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		d.Payload = string(payload)
+		letters = append(letters, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return letters, nil
+}
+
+// GetDeadLetter returns a dead letter by ID, or nil if it does not exist
+func (db *DB) GetDeadLetter(ctx context.Context, id int64) (*DeadLetter, error) {
+	var d DeadLetter
+	var payload []byte
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, subject, payload, error, received_at, attempts, last_error, reprocessed_at
+		FROM core_api.event_dead_letters
+		WHERE id = $1
+	`, id).Scan(&d.ID, &d.Subject, &payload, &d.Error, &d.ReceivedAt, &d.Attempts, &d.LastError, &d.ReprocessedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	d.Payload = string(payload)
+	return &d, nil
+}
+
+// RecordDeadLetterAttempt counts a reprocess attempt. A nil reprocessErr
+// marks the dead letter as reprocessed.
+func (db *DB) RecordDeadLetterAttempt(ctx context.Context, id int64, reprocessErr error) error {
+	var err error
+	if reprocessErr == nil {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.event_dead_letters
+			SET attempts = attempts + 1, last_error = NULL, reprocessed_at = NOW()
+			WHERE id = $1
+		`, id)
+	} else {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.event_dead_letters
+			SET attempts = attempts + 1, last_error = $2
+			WHERE id = $1
+		`, id, reprocessErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return nil
+}
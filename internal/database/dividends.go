@@ -0,0 +1,240 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// dividendPaymentWindow is how long after the ex-date a ledger DIVIDEND entry
+// is considered to be the payout for that corporate action
+const dividendPaymentWindow = 60 * 24 * time.Hour
+
+// DividendEntitlement is a dividend the portfolio was (or will be) entitled to
+type DividendEntitlement struct {
+	Symbol         string  `json:"symbol"`
+	Purpose        *string `json:"purpose"`
+	ExDate         string  `json:"ex_date"`
+	RecordDate     *string `json:"record_date"`
+	PaymentDate    *string `json:"payment_date"`
+	PerShare       float64 `json:"per_share"`
+	Quantity       float64 `json:"quantity"`
+	Amount         float64 `json:"amount"`
+	RecordedAmount float64 `json:"recorded_amount"`
+	Recorded       bool    `json:"recorded"`
+}
+
+// HoldingDividends aggregates dividend income for one symbol
+type HoldingDividends struct {
+	Symbol      string   `json:"symbol"`
+	Received    float64  `json:"received"`
+	Upcoming    float64  `json:"upcoming"`
+	YieldOnCost *float64 `json:"yield_on_cost_pct"`
+}
+
+// DividendReport lists received and upcoming dividend income for a portfolio
+type DividendReport struct {
+	PortfolioID     int64                 `json:"portfolio_id"`
+	Received        []DividendEntitlement `json:"received"`
+	Upcoming        []DividendEntitlement `json:"upcoming"`
+	ByHolding       []HoldingDividends    `json:"by_holding"`
+	TotalReceived   float64               `json:"total_received"`
+	TotalRecorded   float64               `json:"total_recorded"`
+	ProjectedIncome float64               `json:"projected_income"`
+	Timestamp       string                `json:"timestamp"`
+}
+
+// corporateDividend is a dividend row from md.corporate_actions
+type corporateDividend struct {
+	symbol      string
+	purpose     *string
+	perShare    float64
+	exDate      time.Time
+	recordDate  *time.Time
+	paymentDate *time.Time
+}
+
+// GetPortfolioDividends matches announced dividends against the quantity held
+// on each ex-date. Past ex-dates are reported as received (flagged when a ledger
+// DIVIDEND entry exists); future ex-dates are projected on current holdings.
+func (db *DB) GetPortfolioDividends(ctx context.Context, portfolioID int64) (*DividendReport, error) {
+	txns, err := db.ListPortfolioTransactions(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DividendReport{
+		PortfolioID: portfolioID,
+		Received:    []DividendEntitlement{},
+		Upcoming:    []DividendEntitlement{},
+		ByHolding:   []HoldingDividends{},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	if len(txns) == 0 {
+		return report, nil
+	}
+
+	symbols := map[string]bool{}
+	for _, t := range txns {
+		symbols[t.Symbol] = true
+	}
+	symbolList := make([]string, 0, len(symbols))
+	for s := range symbols {
+		symbolList = append(symbolList, s)
+	}
+
+	firstTrade, err := time.Parse(time.RFC3339, txns[0].TradedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid traded_at on transaction %d: %w", txns[0].ID, err)
+	}
+
+	actions, err := db.getCorporateDividends(ctx, symbolList, firstTrade)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := istLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	byHolding := map[string]*HoldingDividends{}
+	for _, a := range actions {
+		exDate := time.Date(a.exDate.Year(), a.exDate.Month(), a.exDate.Day(), 0, 0, 0, 0, loc)
+		// Entitlement requires holding the shares before the ex-date opens
+		qty := quantityHeldBefore(txns, a.symbol, exDate)
+		if qty <= 0 {
+			continue
+		}
+
+		entry := DividendEntitlement{
+			Symbol:   a.symbol,
+			Purpose:  a.purpose,
+			ExDate:   exDate.Format("2006-01-02"),
+			PerShare: a.perShare,
+			Quantity: qty,
+			Amount:   round2(qty * a.perShare),
+		}
+		if a.recordDate != nil {
+			d := a.recordDate.Format("2006-01-02")
+			entry.RecordDate = &d
+		}
+		if a.paymentDate != nil {
+			d := a.paymentDate.Format("2006-01-02")
+			entry.PaymentDate = &d
+		}
+
+		h := byHolding[a.symbol]
+		if h == nil {
+			h = &HoldingDividends{Symbol: a.symbol}
+			byHolding[a.symbol] = h
+		}
+
+		if exDate.After(today) {
+			report.Upcoming = append(report.Upcoming, entry)
+			report.ProjectedIncome += entry.Amount
+			h.Upcoming += entry.Amount
+			continue
+		}
+
+		for _, t := range txns {
+			if t.TxnType != "DIVIDEND" || t.Symbol != a.symbol {
+				continue
+			}
+			paidAt, err := time.Parse(time.RFC3339, t.TradedAt)
+			if err != nil || paidAt.Before(exDate) || paidAt.After(exDate.Add(dividendPaymentWindow)) {
+				continue
+			}
+			entry.Recorded = true
+			entry.RecordedAmount += t.Amount
+		}
+
+		report.Received = append(report.Received, entry)
+		report.TotalReceived += entry.Amount
+		report.TotalRecorded += entry.RecordedAmount
+		h.Received += entry.Amount
+	}
+
+	// Yield on cost uses the trailing twelve months of received dividends
+	for _, holding := range ComputeHoldings(txns) {
+		h := byHolding[holding.Symbol]
+		if h == nil || holding.InvestedValue <= 0 {
+			continue
+		}
+		trailing := 0.0
+		for _, r := range report.Received {
+			exDate, _ := time.ParseInLocation("2006-01-02", r.ExDate, loc)
+			if r.Symbol == holding.Symbol && exDate.After(today.AddDate(-1, 0, 0)) {
+				trailing += r.PerShare * holding.Quantity
+			}
+		}
+		y := round2(trailing / holding.InvestedValue * 100)
+		h.YieldOnCost = &y
+	}
+
+	for _, h := range byHolding {
+		h.Received = round2(h.Received)
+		h.Upcoming = round2(h.Upcoming)
+		report.ByHolding = append(report.ByHolding, *h)
+	}
+	sort.Slice(report.ByHolding, func(i, j int) bool {
+		return report.ByHolding[i].Symbol < report.ByHolding[j].Symbol
+	})
+
+	report.TotalReceived = round2(report.TotalReceived)
+	report.TotalRecorded = round2(report.TotalRecorded)
+	report.ProjectedIncome = round2(report.ProjectedIncome)
+
+	return report, nil
+}
+
+// quantityHeldBefore replays the ledger up to (but excluding) the given time
+func quantityHeldBefore(txns []PortfolioTransaction, symbol string, before time.Time) float64 {
+	qty := 0.0
+	for _, t := range txns {
+		if t.Symbol != symbol {
+			continue
+		}
+		tradedAt, err := time.Parse(time.RFC3339, t.TradedAt)
+		if err != nil || !tradedAt.Before(before) {
+			continue
+		}
+		switch t.TxnType {
+		case "BUY":
+			qty += t.Quantity
+		case "SELL":
+			qty -= t.Quantity
+		}
+	}
+	return qty
+}
+
+// getCorporateDividends returns dividend actions for symbols with ex-date on or after since
+func (db *DB) getCorporateDividends(ctx context.Context, symbols []string, since time.Time) ([]corporateDividend, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, purpose, amount, ex_date, record_date, payment_date
+		FROM md.corporate_actions
+		WHERE action_type = 'DIVIDEND'
+			AND amount > 0
+			AND symbol = ANY($1)
+			AND ex_date >= $2::date
+		ORDER BY ex_date, symbol
+	`, symbols, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corporate actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []corporateDividend{}
+	for rows.Next() {
+		var a corporateDividend
+		if err := rows.Scan(&a.symbol, &a.purpose, &a.perShare, &a.exDate, &a.recordDate, &a.paymentDate); err != nil {
+			return nil, fmt.Errorf("failed to scan corporate action: %w", err)
+		}
+		actions = append(actions, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return actions, nil
+}
@@ -0,0 +1,370 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Export job statuses
+const (
+	ExportStatusPending   = "PENDING"
+	ExportStatusRunning   = "RUNNING"
+	ExportStatusCompleted = "COMPLETED"
+	ExportStatusFailed    = "FAILED"
+	ExportStatusExpired   = "EXPIRED"
+)
+
+// ExportRequest describes a bulk extract
+type ExportRequest struct {
+	Dataset string   `json:"dataset"`
+	Format  string   `json:"format"`
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// ExportJob tracks an asynchronous export
+type ExportJob struct {
+	ID           string        `json:"id"`
+	UserID       string        `json:"user_id"`
+	Dataset      string        `json:"dataset"`
+	Format       string        `json:"format"`
+	Params       ExportRequest `json:"params"`
+	Status       string        `json:"status"`
+	Storage      *string       `json:"storage"`
+	ObjectKey    *string       `json:"object_key"`
+	RowCount     int64         `json:"row_count"`
+	SizeBytes    int64         `json:"size_bytes"`
+	ErrorMessage *string       `json:"error_message"`
+	CreatedAt    string        `json:"created_at"`
+	StartedAt    *string       `json:"started_at"`
+	CompletedAt  *string       `json:"completed_at"`
+}
+
+// ExportColumn is a typed output column. Types are one of string, int64,
+// float64, bool or timestamp; queries cast so every row matches.
+type ExportColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// exportDataset defines how one dataset is queried
+type exportDataset struct {
+	columns []ExportColumn
+	query   string
+	// requireSymbols rejects user exports without a symbol filter; internal
+	// callers such as retention archival may still stream everything
+	requireSymbols bool
+}
+
+// exportDatasets are the extracts available through /api/exports.
+// Each query takes ($1 from, $2 to, $3 symbols) and must select the columns in order.
+var exportDatasets = map[string]exportDataset{
+	"signals": {
+		columns: []ExportColumn{
+			{"signal_id", "string"}, {"symbol", "string"}, {"signal_type", "string"},
+			{"confidence_score", "float64"}, {"entry_price", "float64"}, {"current_price", "float64"},
+			{"stop_loss", "float64"}, {"target_price", "float64"}, {"status", "string"},
+			{"generated_at", "timestamp"}, {"exit_price", "float64"}, {"closed_at", "timestamp"},
+			{"actual_profit_pct", "float64"}, {"exit_reason", "string"}, {"sector", "string"},
+		},
+		query: `
+			SELECT signal_id::text, symbol, signal_type, confidence_score::float8, entry_price::float8,
+				current_price::float8, stop_loss::float8, target_price::float8, status, generated_at,
+				exit_price::float8, closed_at, actual_profit_pct::float8, exit_reason, sector
+			FROM intraday.signals
+			WHERE generated_at >= $1 AND generated_at < $2
+				AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+			ORDER BY generated_at
+		`,
+	},
+	"ticks": {
+		columns: []ExportColumn{
+			{"symbol", "string"}, {"ts", "timestamp"}, {"last_price", "float64"}, {"volume", "int64"},
+		},
+		query: `
+			SELECT symbol, ts, last_price::float8, volume::bigint
+			FROM md.ticks
+			WHERE ts >= $1 AND ts < $2 AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+			ORDER BY symbol, ts
+		`,
+		requireSymbols: true,
+	},
+	"bars": {
+		columns: []ExportColumn{
+			{"symbol", "string"}, {"trade_date", "timestamp"}, {"open", "float64"}, {"high", "float64"},
+			{"low", "float64"}, {"close", "float64"}, {"volume", "int64"},
+		},
+		query: `
+			SELECT symbol, trade_date::timestamptz, open::float8, high::float8, low::float8,
+				close::float8, volume::bigint
+			FROM md.bhavcopy
+			WHERE series = 'EQ' AND trade_date >= $1::date AND trade_date < $2::date
+				AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+			ORDER BY symbol, trade_date
+		`,
+	},
+	"predictions": {
+		columns: []ExportColumn{
+			{"prediction_date", "timestamp"}, {"symbol", "string"}, {"current_price", "float64"},
+			{"predicted_price", "float64"}, {"predicted_change_pct", "float64"}, {"stop_loss", "float64"},
+			{"target", "float64"}, {"confidence", "float64"}, {"trend", "string"},
+		},
+		query: `
+			SELECT prediction_date::timestamptz, symbol, current_price::float8, predicted_price::float8,
+				predicted_change_pct::float8, stop_loss::float8, target::float8, confidence::float8, trend
+			FROM predictions.daily_predictions
+			WHERE prediction_date >= $1::date AND prediction_date < $2::date
+				AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+			ORDER BY prediction_date, symbol
+		`,
+	},
+	"news": {
+		columns: []ExportColumn{
+			{"id", "string"}, {"published_at", "timestamp"}, {"source", "string"}, {"title", "string"},
+			{"summary", "string"}, {"url", "string"}, {"sentiment_label", "string"},
+			{"sentiment_score", "float64"}, {"symbols", "string"},
+		},
+		query: `
+			SELECT a.id::text, a.published_at, a.source, a.title, a.summary, a.url, a.sentiment_label,
+				a.sentiment_score::float8,
+				(SELECT string_agg(ae.symbol, ',') FROM news.article_entities ae WHERE ae.article_id = a.id)
+			FROM news.articles a
+			WHERE a.published_at >= $1 AND a.published_at < $2
+				AND (cardinality($3::text[]) = 0 OR EXISTS (
+					SELECT 1 FROM news.article_entities ae WHERE ae.article_id = a.id AND ae.symbol = ANY($3)))
+			ORDER BY a.published_at
+		`,
+	},
+}
+
+// ExportDatasetNames lists the supported datasets
+func ExportDatasetNames() []string {
+	names := make([]string, 0, len(exportDatasets))
+	for name := range exportDatasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateExportRequest checks the dataset and its required parameters
+func ValidateExportRequest(req ExportRequest) error {
+	ds, ok := exportDatasets[req.Dataset]
+	if !ok {
+		return fmt.Errorf("unknown dataset %q (available: %v)", req.Dataset, ExportDatasetNames())
+	}
+	if ds.requireSymbols && len(req.Symbols) == 0 {
+		return fmt.Errorf("dataset %q requires symbols", req.Dataset)
+	}
+	return nil
+}
+
+// ExportColumns returns the output schema of a dataset
+func ExportColumns(dataset string) []ExportColumn {
+	return exportDatasets[dataset].columns
+}
+
+// StreamExport runs the dataset query and calls emit for each row. Values are
+// nil, string, int64, float64, bool or time.Time according to the column type.
+func (db *DB) StreamExport(ctx context.Context, req ExportRequest, from, to time.Time, emit func(values []interface{}) error) (int64, error) {
+	ds, ok := exportDatasets[req.Dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown dataset %q", req.Dataset)
+	}
+
+	symbols := req.Symbols
+	if symbols == nil {
+		symbols = []string{}
+	}
+
+	// Extracts are bounded by the job timeout rather than statement_timeout
+	var count int64
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, ds.query, from, to, symbols)
+		if err != nil {
+			return fmt.Errorf("failed to query %s export: %w", req.Dataset, err)
+		}
+		defer rows.Close()
+
+		values := make([]interface{}, len(ds.columns))
+		ptrs := make([]interface{}, len(ds.columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				return fmt.Errorf("failed to scan %s export row: %w", req.Dataset, err)
+			}
+			for i, v := range values {
+				if b, ok := v.([]byte); ok {
+					values[i] = string(b)
+				}
+			}
+			if err := emit(values); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
+		}
+		return nil
+	})
+	return count, err
+}
+
+// CreateExportJob records a pending export
+func (db *DB) CreateExportJob(ctx context.Context, id, userID string, req ExportRequest) (*ExportJob, error) {
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.export_jobs (id, user_id, dataset, format, params, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, id, userID, req.Dataset, req.Format, params, ExportStatusPending); err != nil {
+		return nil, fmt.Errorf("failed to create export job: %w", err)
+	}
+	return db.GetExportJob(ctx, userID, id)
+}
+
+// GetExportJob returns a user's export job, or nil if it does not exist
+func (db *DB) GetExportJob(ctx context.Context, userID, id string) (*ExportJob, error) {
+	row := db.conn.QueryRowContext(ctx, `
+		SELECT id, user_id, dataset, format, params, status, storage, object_key, row_count,
+			size_bytes, error_message, created_at, started_at, completed_at
+		FROM core_api.export_jobs
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+
+	job, err := scanExportJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ListExportJobs returns a user's most recent export jobs
+func (db *DB) ListExportJobs(ctx context.Context, userID string, limit int) ([]ExportJob, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, user_id, dataset, format, params, status, storage, object_key, row_count,
+			size_bytes, error_message, created_at, started_at, completed_at
+		FROM core_api.export_jobs
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []ExportJob{}
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return jobs, nil
+}
+
+// MarkExportJobRunning flags a job as started
+func (db *DB) MarkExportJobRunning(ctx context.Context, id string) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.export_jobs SET status = $2, started_at = NOW() WHERE id = $1
+	`, id, ExportStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	return nil
+}
+
+// CompleteExportJob records where the finished extract was stored
+func (db *DB) CompleteExportJob(ctx context.Context, id, storage, objectKey string, rowCount, sizeBytes int64) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.export_jobs
+		SET status = $2, storage = $3, object_key = $4, row_count = $5, size_bytes = $6, completed_at = NOW()
+		WHERE id = $1
+	`, id, ExportStatusCompleted, storage, objectKey, rowCount, sizeBytes)
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	return nil
+}
+
+// FailExportJob records why an export failed
+func (db *DB) FailExportJob(ctx context.Context, id, message string) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.export_jobs SET status = $2, error_message = $3, completed_at = NOW() WHERE id = $1
+	`, id, ExportStatusFailed, message)
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	return nil
+}
+
+// FailInterruptedExportJobs fails pending and running jobs created before
+// the cutoff; their goroutines died with the process that accepted them
+func (db *DB) FailInterruptedExportJobs(ctx context.Context, before time.Time, message string) (int64, error) {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.export_jobs SET status = $2, error_message = $3, completed_at = NOW()
+		WHERE status IN ($4, $5) AND created_at < $1
+	`, before, ExportStatusFailed, message, ExportStatusPending, ExportStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted export jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ExpireExportJobs marks completed jobs finished before the cutoff as expired
+// once storage lifecycle has removed their files
+func (db *DB) ExpireExportJobs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.export_jobs SET status = $2
+		WHERE status = $3 AND completed_at < $1
+	`, before, ExportStatusExpired, ExportStatusCompleted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire export jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func scanExportJob(row rowScanner) (*ExportJob, error) {
+	var job ExportJob
+	var params []byte
+	var createdAt time.Time
+	var startedAt, completedAt *time.Time
+	if err := row.Scan(
+		&job.ID, &job.UserID, &job.Dataset, &job.Format, &params, &job.Status, &job.Storage,
+		&job.ObjectKey, &job.RowCount, &job.SizeBytes, &job.ErrorMessage, &createdAt, &startedAt, &completedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan export job: %w", err)
+	}
+	if err := json.Unmarshal(params, &job.Params); err != nil {
+		return nil, fmt.Errorf("invalid export params: %w", err)
+	}
+	job.CreatedAt = createdAt.Format(time.RFC3339)
+	if startedAt != nil {
+		s := startedAt.Format(time.RFC3339)
+		job.StartedAt = &s
+	}
+	if completedAt != nil {
+		s := completedAt.Format(time.RFC3339)
+		job.CompletedAt = &s
+	}
+	return &job, nil
+}
@@ -0,0 +1,299 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Exposure directions
+const (
+	ExposureLong  = "LONG"
+	ExposureShort = "SHORT"
+)
+
+// Exposure position sources
+const (
+	ExposureHolding = "holding"
+	ExposureSignal  = "signal"
+)
+
+// exposureRiskWeights scale notional by market-cap tier, smaller and
+// unclassified names counting for more. Unlisted tiers use
+// exposureDefaultRiskWeight.
+var exposureRiskWeights = map[string]float64{
+	"LARGE_CAP": 1.0,
+	"MID_CAP":   1.5,
+	"SMALL_CAP": 2.0,
+}
+
+const exposureDefaultRiskWeight = 2.0
+
+// ExposurePosition is one holding or open signal
+type ExposurePosition struct {
+	Source    string `json:"source"`
+	ID        string `json:"id"`
+	Symbol    string `json:"symbol"`
+	Sector    string `json:"sector"`
+	MarketCap string `json:"market_cap"`
+	Direction string `json:"direction"`
+	// Quantity is nil for signals, which are sized at the requested notional
+	Quantity     *float64 `json:"quantity"`
+	Price        float64  `json:"price"`
+	Notional     float64  `json:"notional"`
+	RiskWeight   float64  `json:"risk_weight"`
+	RiskWeighted float64  `json:"risk_weighted"`
+	// StopRisk is the loss if a signal's stop is hit; nil for holdings
+	StopRisk *float64 `json:"stop_risk"`
+}
+
+// ExposureBucket totals positions sharing a sector, market-cap tier or
+// direction. Net is long minus short.
+type ExposureBucket struct {
+	Key          string  `json:"key"`
+	Positions    int     `json:"positions"`
+	Long         float64 `json:"long"`
+	Short        float64 `json:"short"`
+	Gross        float64 `json:"gross"`
+	Net          float64 `json:"net"`
+	RiskWeighted float64 `json:"risk_weighted"`
+	StopRisk     float64 `json:"stop_risk"`
+	// PctOfGross is this bucket's share of total gross exposure
+	PctOfGross float64 `json:"pct_of_gross"`
+}
+
+func (b *ExposureBucket) add(p ExposurePosition) {
+	b.Positions++
+	if p.Direction == ExposureShort {
+		b.Short += p.Notional
+	} else {
+		b.Long += p.Notional
+	}
+	b.RiskWeighted += p.RiskWeighted
+	if p.StopRisk != nil {
+		b.StopRisk += *p.StopRisk
+	}
+}
+
+func (b *ExposureBucket) finish(gross float64) {
+	b.Gross = round2(b.Long + b.Short)
+	b.Net = round2(b.Long - b.Short)
+	b.Long = round2(b.Long)
+	b.Short = round2(b.Short)
+	b.RiskWeighted = round2(b.RiskWeighted)
+	b.StopRisk = round2(b.StopRisk)
+	if gross > 0 {
+		b.PctOfGross = round2(b.Gross / gross * 100)
+	}
+}
+
+// Exposure is a user's combined exposure across portfolio holdings and
+// active signals
+type Exposure struct {
+	SignalNotional float64            `json:"signal_notional"`
+	Totals         ExposureBucket     `json:"totals"`
+	BySector       []ExposureBucket   `json:"by_sector"`
+	ByMarketCap    []ExposureBucket   `json:"by_market_cap"`
+	ByDirection    []ExposureBucket   `json:"by_direction"`
+	Positions      []ExposurePosition `json:"positions"`
+	Timestamp      string             `json:"timestamp"`
+}
+
+// GetExposure gathers an account's open holdings across all its portfolios,
+// valued at the latest price, and every active signal, each sized at
+// signalNotional since signals carry no quantity
+func (db *DB) GetExposure(ctx context.Context, accountID string, signalNotional float64) (*Exposure, error) {
+	portfolios, err := db.ListPortfolios(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []ExposurePosition
+	for _, p := range portfolios {
+		summary, err := db.GetPortfolioHoldings(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range summary.Holdings {
+			if h.Quantity <= 0 || h.LastPrice <= 0 {
+				continue
+			}
+			qty := h.Quantity
+			positions = append(positions, ExposurePosition{
+				Source:    ExposureHolding,
+				ID:        fmt.Sprintf("%d", p.ID),
+				Symbol:    h.Symbol,
+				Direction: ExposureLong,
+				Quantity:  &qty,
+				Price:     h.LastPrice,
+				Notional:  qty * h.LastPrice,
+			})
+		}
+	}
+
+	signals, err := db.GetActiveSignals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range signals {
+		if s.EntryPrice <= 0 {
+			continue
+		}
+		direction := ExposureLong
+		if t := strings.ToUpper(s.SignalType); t == "SELL" || t == "SHORT" {
+			direction = ExposureShort
+		}
+		price := s.CurrentPrice
+		if price <= 0 {
+			price = s.EntryPrice
+		}
+		p := ExposurePosition{
+			Source:    ExposureSignal,
+			ID:        s.SignalID,
+			Symbol:    s.Symbol,
+			Sector:    s.Sector,
+			Direction: direction,
+			Price:     price,
+			Notional:  signalNotional,
+		}
+		if s.StopLoss > 0 {
+			risk := round2(signalNotional * math.Abs(s.EntryPrice-s.StopLoss) / s.EntryPrice)
+			p.StopRisk = &risk
+		}
+		positions = append(positions, p)
+	}
+
+	symbols := make([]string, 0, len(positions))
+	for _, p := range positions {
+		symbols = append(symbols, p.Symbol)
+	}
+	meta, err := db.getExposureMeta(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	for i := range positions {
+		p := &positions[i]
+		m := meta[p.Symbol]
+		if p.Sector == "" {
+			p.Sector = m.sector
+		}
+		if p.Sector == "" {
+			p.Sector = "OTHER"
+		}
+		p.Sector = strings.ToUpper(p.Sector)
+		p.MarketCap = m.marketCap
+		if p.MarketCap == "" {
+			p.MarketCap = "UNCLASSIFIED"
+		}
+	}
+
+	exposure := computeExposure(positions)
+	exposure.SignalNotional = signalNotional
+	return exposure, nil
+}
+
+// computeExposure applies risk weights and totals positions by sector,
+// market-cap tier and direction, largest gross exposure first
+func computeExposure(positions []ExposurePosition) *Exposure {
+	exposure := &Exposure{
+		Totals:    ExposureBucket{Key: "total"},
+		Positions: []ExposurePosition{},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	sectors := map[string]*ExposureBucket{}
+	caps := map[string]*ExposureBucket{}
+	directions := map[string]*ExposureBucket{
+		ExposureLong:  {Key: ExposureLong},
+		ExposureShort: {Key: ExposureShort},
+	}
+	bucket := func(m map[string]*ExposureBucket, key string) *ExposureBucket {
+		if m[key] == nil {
+			m[key] = &ExposureBucket{Key: key}
+		}
+		return m[key]
+	}
+
+	for _, p := range positions {
+		p.RiskWeight = exposureDefaultRiskWeight
+		if w, ok := exposureRiskWeights[p.MarketCap]; ok {
+			p.RiskWeight = w
+		}
+		p.RiskWeighted = round2(p.Notional * p.RiskWeight)
+		p.Notional = round2(p.Notional)
+
+		exposure.Totals.add(p)
+		bucket(sectors, p.Sector).add(p)
+		bucket(caps, p.MarketCap).add(p)
+		directions[p.Direction].add(p)
+		exposure.Positions = append(exposure.Positions, p)
+	}
+
+	gross := exposure.Totals.Long + exposure.Totals.Short
+	exposure.Totals.finish(gross)
+	list := func(m map[string]*ExposureBucket) []ExposureBucket {
+		out := make([]ExposureBucket, 0, len(m))
+		for _, b := range m {
+			b.finish(gross)
+			out = append(out, *b)
+		}
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].Gross != out[j].Gross {
+				return out[i].Gross > out[j].Gross
+			}
+			return out[i].Key < out[j].Key
+		})
+		return out
+	}
+	exposure.BySector = list(sectors)
+	exposure.ByMarketCap = list(caps)
+	exposure.ByDirection = list(directions)
+	sort.Slice(exposure.Positions, func(i, j int) bool {
+		return exposure.Positions[i].Notional > exposure.Positions[j].Notional
+	})
+	return exposure
+}
+
+// exposureMeta is a symbol's sector and market-cap tier
+type exposureMeta struct {
+	sector    string
+	marketCap string
+}
+
+// getExposureMeta returns sector and market-cap tier from stock config
+func (db *DB) getExposureMeta(ctx context.Context, symbols []string) (map[string]exposureMeta, error) {
+	meta := map[string]exposureMeta{}
+	if len(symbols) == 0 {
+		return meta, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT DISTINCT ON (symbol) symbol,
+			COALESCE(UPPER(sector), ''),
+			COALESCE(UPPER(market_cap_category), '')
+		FROM md.stock_config
+		WHERE symbol = ANY($1) AND exchange = 'NSE'
+		ORDER BY symbol
+	`, pq.Array(symbols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exposure metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var m exposureMeta
+		if err := rows.Scan(&symbol, &m.sector, &m.marketCap); err != nil {
+			return nil, fmt.Errorf("failed to scan exposure metadata: %w", err)
+		}
+		meta[symbol] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Follow kinds
+const (
+	FollowSymbol   = "symbol"
+	FollowStrategy = "strategy"
+)
+
+// Follow is a symbol or strategy an account wants its "mine" views limited
+// to
+type Follow struct {
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	CreatedAt string `json:"created_at"`
+}
+
+// signalStrategyExpr is a signal's strategy: the engine's strategy name, or
+// the provider for ingested signals
+const signalStrategyExpr = `COALESCE(metadata->>'strategy', metadata->>'provider')`
+
+// SignalStrategy returns the strategy a signal belongs to, as matched by
+// strategy follows, or "" if it has none
+func SignalStrategy(s *Signal) string {
+	if !s.Metadata.Valid {
+		return ""
+	}
+	var meta struct {
+		Strategy string `json:"strategy"`
+		Provider string `json:"provider"`
+	}
+	if json.Unmarshal(s.Metadata.RawMessage, &meta) != nil {
+		return ""
+	}
+	if meta.Strategy != "" {
+		return meta.Strategy
+	}
+	return meta.Provider
+}
+
+// ListFollows returns an account's follows, symbols first
+func (db *DB) ListFollows(ctx context.Context, accountID string) ([]Follow, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT kind, value, created_at
+		FROM core_api.follows
+		WHERE account_id = $1
+		ORDER BY kind DESC, value
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follows: %w", err)
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var f Follow
+		var createdAt time.Time
+		if err := rows.Scan(&f.Kind, &f.Value, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan follow: %w", err)
+		}
+		f.CreatedAt = createdAt.Format(time.RFC3339)
+		follows = append(follows, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return follows, nil
+}
+
+// AddFollow follows a symbol or strategy for an account on behalf of
+// userID, reporting whether it wasn't already followed
+func (db *DB) AddFollow(ctx context.Context, accountID, userID, kind, value string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.follows (account_id, user_id, kind, value)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, accountID, userID, kind, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to add follow: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RemoveFollow unfollows a symbol or strategy for an account
+func (db *DB) RemoveFollow(ctx context.Context, accountID, kind, value string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.follows WHERE account_id = $1 AND kind = $2 AND value = $3", accountID, kind, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove follow: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// StreamFollowedSignals is StreamSignals limited to the symbols and
+// strategies accountID follows
+func (db *DB) StreamFollowedSignals(ctx context.Context, accountID string, limit int, status string, fn func(Signal) error) error {
+	query := `
+		SELECT ` + signalColumns + `
+		FROM intraday.signals
+		WHERE (symbol IN (SELECT value FROM core_api.follows WHERE account_id = $1 AND kind = 'symbol')
+			OR ` + signalStrategyExpr + ` IN (SELECT value FROM core_api.follows WHERE account_id = $1 AND kind = 'strategy'))
+	`
+	args := []interface{}{accountID}
+	if status != "" {
+		query += " AND status = $2"
+		args = append(args, status)
+	}
+	query += " ORDER BY generated_at DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query followed signals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*s); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+	return nil
+}
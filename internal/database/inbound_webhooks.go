@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// InboundWebhook is a configured endpoint that maps another system's JSON
+// webhooks onto signals, alerts or news. Config is an inbound.Config.
+type InboundWebhook struct {
+	ID     int64  `json:"id"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	// Slug names the endpoint in its URL and is its signal provider name
+	Slug            string          `json:"slug"`
+	Target          string          `json:"target"`
+	Config          json.RawMessage `json:"config"`
+	SecretHash      string          `json:"-"`
+	Enabled         bool            `json:"enabled"`
+	Deliveries      int64           `json:"deliveries"`
+	RecordsAccepted int64           `json:"records_accepted"`
+	LastDeliveryAt  *string         `json:"last_delivery_at"`
+	LastError       *string         `json:"last_error"`
+	CreatedAt       string          `json:"created_at"`
+	UpdatedAt       string          `json:"updated_at"`
+}
+
+// ErrInboundWebhookExists is returned when another endpoint has the slug
+var ErrInboundWebhookExists = errors.New("an inbound webhook with this slug already exists")
+
+const inboundWebhookColumns = `id, user_id, name, slug, target, config, secret_hash, enabled, deliveries,
+	records_accepted, last_delivery_at, last_error, created_at, updated_at`
+
+func scanInboundWebhook(row rowScanner) (*InboundWebhook, error) {
+	var w InboundWebhook
+	var config []byte
+	var lastDelivery sql.NullTime
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&w.ID, &w.UserID, &w.Name, &w.Slug, &w.Target, &config, &w.SecretHash, &w.Enabled,
+		&w.Deliveries, &w.RecordsAccepted, &lastDelivery, &w.LastError, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	w.Config = json.RawMessage(config)
+	if lastDelivery.Valid {
+		t := lastDelivery.Time.Format(time.RFC3339)
+		w.LastDeliveryAt = &t
+	}
+	w.CreatedAt = createdAt.Format(time.RFC3339)
+	w.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &w, nil
+}
+
+// getInboundWebhook returns the endpoint matching a query, or nil
+func (db *DB) getInboundWebhook(ctx context.Context, query string, args ...interface{}) (*InboundWebhook, error) {
+	w, err := scanInboundWebhook(db.conn.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound webhook: %w", err)
+	}
+	return w, nil
+}
+
+// inboundWebhookCreateError maps a unique violation on slug to
+// ErrInboundWebhookExists
+func inboundWebhookCreateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrInboundWebhookExists
+	}
+	return fmt.Errorf("failed to create inbound webhook: %w", err)
+}
+
+// ListInboundWebhooks returns all endpoints
+func (db *DB) ListInboundWebhooks(ctx context.Context) ([]InboundWebhook, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+inboundWebhookColumns+`
+		FROM core_api.inbound_webhooks
+		ORDER BY name, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inbound webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []InboundWebhook{}
+	for rows.Next() {
+		w, err := scanInboundWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbound webhook: %w", err)
+		}
+		webhooks = append(webhooks, *w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetInboundWebhook returns an endpoint by ID, or nil
+func (db *DB) GetInboundWebhook(ctx context.Context, id int64) (*InboundWebhook, error) {
+	return db.getInboundWebhook(ctx,
+		"SELECT "+inboundWebhookColumns+" FROM core_api.inbound_webhooks WHERE id = $1", id)
+}
+
+// GetInboundWebhookBySlug returns an endpoint by slug, or nil
+func (db *DB) GetInboundWebhookBySlug(ctx context.Context, slug string) (*InboundWebhook, error) {
+	return db.getInboundWebhook(ctx,
+		"SELECT "+inboundWebhookColumns+" FROM core_api.inbound_webhooks WHERE slug = $1", slug)
+}
+
+// CreateInboundWebhook stores an endpoint with w's user, name, slug, target,
+// config, secret hash and enabled flag
+func (db *DB) CreateInboundWebhook(ctx context.Context, w InboundWebhook) (*InboundWebhook, error) {
+	created, err := scanInboundWebhook(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.inbound_webhooks (user_id, name, slug, target, config, secret_hash, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+inboundWebhookColumns,
+		w.UserID, w.Name, w.Slug, w.Target, []byte(w.Config), w.SecretHash, w.Enabled))
+	if err != nil {
+		return nil, inboundWebhookCreateError(err)
+	}
+	return created, nil
+}
+
+// UpdateInboundWebhook replaces an endpoint's name, target, config and
+// enabled flag, returning nil if it doesn't exist. The slug and secret are
+// kept.
+func (db *DB) UpdateInboundWebhook(ctx context.Context, w InboundWebhook) (*InboundWebhook, error) {
+	return db.getInboundWebhook(ctx, `
+		UPDATE core_api.inbound_webhooks
+		SET name = $2, target = $3, config = $4, enabled = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+inboundWebhookColumns,
+		w.ID, w.Name, w.Target, []byte(w.Config), w.Enabled)
+}
+
+// DeleteInboundWebhook removes an endpoint. What it delivered is kept.
+func (db *DB) DeleteInboundWebhook(ctx context.Context, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM core_api.inbound_webhooks WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete inbound webhook: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordInboundDelivery counts a delivery and the records it produced. A
+// nil deliveryErr clears the endpoint's last error.
+func (db *DB) RecordInboundDelivery(ctx context.Context, id int64, accepted int, deliveryErr error) error {
+	var lastError *string
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		lastError = &msg
+	}
+	if _, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.inbound_webhooks
+		SET deliveries = deliveries + 1, records_accepted = records_accepted + $2,
+		    last_delivery_at = NOW(), last_error = $3
+		WHERE id = $1
+	`, id, accepted, lastError); err != nil {
+		return fmt.Errorf("failed to record inbound delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IndexSpec is an index a hot query relies on. An existing index whose
+// leading columns are Columns, in order, satisfies it.
+type IndexSpec struct {
+	Name    string
+	Table   string
+	Columns []string
+}
+
+// statement is the CREATE INDEX that adds the index
+func (s IndexSpec) statement() string {
+	return fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s)", s.Name, s.Table, strings.Join(s.Columns, ", "))
+}
+
+// hotQuery is one of the canonical queries behind the busiest endpoints,
+// with representative arguments
+type hotQuery struct {
+	name        string
+	description string
+	sql         string
+	args        []interface{}
+	indexes     []IndexSpec
+}
+
+// Indexes the hot queries rely on. Migration 29 creates them, except the
+// lookup by article, which news.article_entities' primary key serves.
+var (
+	signalsGeneratedIndex = IndexSpec{"idx_signals_generated_at", "intraday.signals", []string{"generated_at"}}
+	signalsStatusIndex    = IndexSpec{"idx_signals_status_generated_at", "intraday.signals", []string{"status", "generated_at"}}
+	signalsSymbolIndex    = IndexSpec{"idx_signals_symbol_generated_at", "intraday.signals", []string{"symbol", "generated_at"}}
+	articlesPublished     = IndexSpec{"idx_articles_published_at", "news.articles", []string{"published_at"}}
+	entitiesArticleIndex  = IndexSpec{"idx_article_entities_article", "news.article_entities", []string{"article_id"}}
+	entitiesSymbolIndex   = IndexSpec{"idx_article_entities_symbol", "news.article_entities", []string{"symbol"}}
+)
+
+func hotQueries(now time.Time) []hotQuery {
+	return []hotQuery{
+		{
+			name:        "dashboard_active",
+			description: "Today's active signals on the dashboard",
+			sql: `SELECT ` + signalColumns + ` FROM intraday.signals
+				WHERE status = 'ACTIVE' AND generated_at >= CURRENT_DATE
+				ORDER BY generated_at DESC LIMIT 100`,
+			indexes: []IndexSpec{signalsStatusIndex},
+		},
+		{
+			name:        "dashboard_closed",
+			description: "Today's closed signals on the dashboard",
+			sql: `SELECT ` + signalColumns + ` FROM intraday.signals
+				WHERE status IN ('HIT_TARGET', 'HIT_STOPLOSS', 'TRAILING_STOP', 'TIME_EXIT', 'EXPIRED')
+					AND generated_at >= CURRENT_DATE
+				ORDER BY closed_at DESC LIMIT 100`,
+			indexes: []IndexSpec{signalsStatusIndex},
+		},
+		{
+			name:        "signal_history",
+			description: "Signal history, newest first (GET /api/signals)",
+			sql: `SELECT ` + signalColumns + ` FROM intraday.signals
+				ORDER BY generated_at DESC LIMIT 100`,
+			indexes: []IndexSpec{signalsGeneratedIndex},
+		},
+		{
+			name:        "signal_stats",
+			description: "Outcome counts for signals generated in the last day",
+			sql: `SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'ACTIVE'), COALESCE(AVG(confidence_score), 0)
+				FROM intraday.signals WHERE generated_at >= $1`,
+			args:    []interface{}{now.Add(-24 * time.Hour)},
+			indexes: []IndexSpec{signalsGeneratedIndex},
+		},
+		{
+			name:        "symbol_history",
+			description: "Latest signals per symbol (GraphQL and watchlists)",
+			sql: `SELECT ` + signalColumns + ` FROM (
+					SELECT *, ROW_NUMBER() OVER (PARTITION BY symbol ORDER BY generated_at DESC) AS rn
+					FROM intraday.signals
+					WHERE symbol = ANY($1) AND ($2 = '' OR status = $2)
+				) s WHERE rn <= $3 ORDER BY symbol, generated_at DESC`,
+			args:    []interface{}{[]string{"RELIANCE", "TCS", "INFY"}, "", 5},
+			indexes: []IndexSpec{signalsSymbolIndex},
+		},
+		{
+			name:        "top_movers",
+			description: "Top gainers from realtime prices",
+			sql: `SELECT rp.symbol, COALESCE(sc.name, rp.symbol), rp.change_percent, rp.last_price
+				FROM md.realtime_prices rp
+				LEFT JOIN md.stock_config sc ON sc.symbol = rp.symbol AND sc.exchange = COALESCE(rp.exchange, 'NSE')
+				WHERE rp.change_percent > 0 AND rp.updated_at > NOW() - INTERVAL '1 day'
+				ORDER BY rp.change_percent DESC LIMIT 10`,
+		},
+		{
+			name:        "news_page",
+			description: "Latest news with each article's symbols (GET /api/news)",
+			sql: `SELECT a.id, a.title, e.symbols
+				FROM (SELECT * FROM news.articles ORDER BY published_at DESC LIMIT 20) a
+				LEFT JOIN LATERAL (
+					SELECT array_agg(ae.symbol) AS symbols FROM news.article_entities ae WHERE ae.article_id = a.id
+				) e ON true`,
+			indexes: []IndexSpec{articlesPublished, entitiesArticleIndex},
+		},
+		{
+			name:        "news_by_symbol",
+			description: "News mentioning a symbol (GET /api/news?symbol=)",
+			sql: `SELECT a.id, a.title FROM news.articles a
+				WHERE EXISTS (SELECT 1 FROM news.article_entities ae WHERE ae.article_id = a.id AND ae.symbol = $1)
+				ORDER BY a.published_at DESC LIMIT 20`,
+			args:    []interface{}{"RELIANCE"},
+			indexes: []IndexSpec{articlesPublished, entitiesSymbolIndex},
+		},
+	}
+}
+
+// SeqScan is a sequential scan in a query plan
+type SeqScan struct {
+	Table    string  `json:"table"`
+	Filter   *string `json:"filter"`
+	PlanRows float64 `json:"plan_rows"`
+}
+
+// IndexSuggestion is an index a hot query relies on that doesn't exist
+type IndexSuggestion struct {
+	Table     string   `json:"table"`
+	Columns   []string `json:"columns"`
+	Statement string   `json:"statement"`
+}
+
+// QueryAdvice is the estimated plan of one hot query and the indexes it is
+// missing
+type QueryAdvice struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	RootNode       string            `json:"root_node"`
+	TotalCost      float64           `json:"total_cost"`
+	PlanRows       float64           `json:"plan_rows"`
+	SeqScans       []SeqScan         `json:"seq_scans"`
+	MissingIndexes []IndexSuggestion `json:"missing_indexes"`
+	Plan           json.RawMessage   `json:"plan,omitempty"`
+	Error          *string           `json:"error"`
+}
+
+// planNode is the part of an EXPLAIN (FORMAT JSON) node the advisor reads
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Filter       *string    `json:"Filter"`
+	TotalCost    float64    `json:"Total Cost"`
+	PlanRows     float64    `json:"Plan Rows"`
+	Plans        []planNode `json:"Plans"`
+}
+
+func (n planNode) seqScans(out []SeqScan) []SeqScan {
+	if n.NodeType == "Seq Scan" {
+		out = append(out, SeqScan{Table: n.RelationName, Filter: n.Filter, PlanRows: n.PlanRows})
+	}
+	for _, child := range n.Plans {
+		out = child.seqScans(out)
+	}
+	return out
+}
+
+// AdviseIndexes explains each hot query without running it and lists the
+// indexes it relies on that are missing. A query that can't be explained,
+// say because its table doesn't exist here, reports the error and the rest
+// still run. withPlans includes each full plan.
+func (db *DB) AdviseIndexes(ctx context.Context, withPlans bool) ([]QueryAdvice, error) {
+	queries := hotQueries(time.Now())
+
+	tables := []string{}
+	for _, q := range queries {
+		for _, spec := range q.indexes {
+			tables = append(tables, spec.Table)
+		}
+	}
+	existing, err := db.indexedColumns(ctx, uniqueStrings(tables))
+	if err != nil {
+		return nil, err
+	}
+
+	advice := make([]QueryAdvice, 0, len(queries))
+	for _, q := range queries {
+		a := QueryAdvice{
+			Name:           q.name,
+			Description:    q.description,
+			SeqScans:       []SeqScan{},
+			MissingIndexes: []IndexSuggestion{},
+		}
+		for _, spec := range q.indexes {
+			if !indexCovers(existing[spec.Table], spec.Columns) {
+				a.MissingIndexes = append(a.MissingIndexes, IndexSuggestion{
+					Table: spec.Table, Columns: spec.Columns, Statement: spec.statement(),
+				})
+			}
+		}
+
+		var raw []byte
+		if err := db.conn.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+q.sql, q.args...).Scan(&raw); err != nil {
+			msg := err.Error()
+			a.Error = &msg
+			advice = append(advice, a)
+			continue
+		}
+		var plans []struct {
+			Plan planNode `json:"Plan"`
+		}
+		if err := json.Unmarshal(raw, &plans); err != nil || len(plans) == 0 {
+			return nil, fmt.Errorf("failed to decode plan for %s: %v", q.name, err)
+		}
+		root := plans[0].Plan
+		a.RootNode = root.NodeType
+		a.TotalCost = root.TotalCost
+		a.PlanRows = root.PlanRows
+		a.SeqScans = root.seqScans(a.SeqScans)
+		if withPlans {
+			a.Plan = raw
+		}
+		advice = append(advice, a)
+	}
+	return advice, nil
+}
+
+// indexedColumns returns, per table, the column lists of its btree indexes
+func (db *DB) indexedColumns(ctx context.Context, tables []string) (map[string][][]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT schemaname || '.' || tablename, indexdef
+		FROM pg_indexes
+		WHERE schemaname || '.' || tablename = ANY($1)
+	`, tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := map[string][][]string{}
+	for rows.Next() {
+		var table, def string
+		if err := rows.Scan(&table, &def); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		if cols := indexDefColumns(def); cols != nil {
+			indexes[table] = append(indexes[table], cols)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return indexes, nil
+}
+
+// indexDefColumns reads the column names from a btree pg_indexes.indexdef
+// such as "CREATE INDEX x ON s.t USING btree (a, b DESC)". Expression
+// columns are kept as written and won't match a plain column.
+func indexDefColumns(def string) []string {
+	i := strings.Index(def, "USING btree (")
+	if i < 0 {
+		return nil
+	}
+	rest := def[i+len("USING btree ("):]
+	depth, end := 0, -1
+	for j, r := range rest {
+		if r == '(' {
+			depth++
+		} else if r == ')' {
+			if depth == 0 {
+				end = j
+				break
+			}
+			depth--
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+	cols := []string{}
+	for _, part := range strings.Split(rest[:end], ",") {
+		fields := strings.Fields(part)
+		if len(fields) > 0 {
+			cols = append(cols, strings.Trim(fields[0], `"`))
+		}
+	}
+	return cols
+}
+
+// indexCovers reports whether any index leads with columns, in order
+func indexCovers(indexes [][]string, columns []string) bool {
+	for _, idx := range indexes {
+		if len(idx) < len(columns) {
+			continue
+		}
+		match := true
+		for i, col := range columns {
+			match = match && idx[i] == col
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
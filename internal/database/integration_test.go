@@ -0,0 +1,551 @@
+//go:build integration
+
+// Integration tests for the main query paths against a real Postgres.
+//
+//	go test -tags integration ./internal/database/
+//
+// TEST_DATABASE_URL points the suite at an existing, disposable database.
+// Without it a postgres container is started with docker and removed
+// afterwards; without docker the suite is skipped. The schema is built the
+// way a fresh deployment builds it: dev schema, migrations, demo seed.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testDB is shared by every integration test and benchmark
+var testDB *DB
+
+// postgresImage is the image started when TEST_DATABASE_URL is unset
+const postgresImage = "postgres:16-alpine"
+
+func TestMain(m *testing.M) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	stop := func() {}
+	if dsn == "" {
+		var err error
+		if dsn, stop, err = startPostgres(); err != nil {
+			log.Printf("⚠️  Skipping integration tests: %v", err)
+			os.Exit(0)
+		}
+	}
+
+	code, err := runSuite(m, dsn)
+	stop()
+	if err != nil {
+		log.Printf("❌ Integration setup failed: %v", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func runSuite(m *testing.M, dsn string) (int, error) {
+	policy := TimeoutPolicy{Interactive: 30 * time.Second, Analytics: time.Minute, AnalyticsMaxConns: 2}
+	var err error
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		if testDB, err = NewDB(dsn, policy); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("database never became ready: %w", err)
+		}
+		time.Sleep(time.Second)
+	}
+	defer testDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := testDB.EnsureDevSchema(ctx); err != nil {
+		return 0, err
+	}
+	if err := testDB.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	if err := testDB.SeedDemoData(ctx, true); err != nil {
+		return 0, err
+	}
+	return m.Run(), nil
+}
+
+// startPostgres runs a throwaway postgres container on a random local port
+func startPostgres() (string, func(), error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", nil, errors.New("TEST_DATABASE_URL not set and docker not found")
+	}
+	out, err := exec.Command("docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD=test", "-e", "POSTGRES_DB=trading",
+		"-p", "127.0.0.1::5432", postgresImage).Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start %s: %w", postgresImage, err)
+	}
+	id := strings.TrimSpace(string(out))
+	stop := func() { exec.Command("docker", "rm", "-f", id).Run() }
+
+	out, err = exec.Command("docker", "port", id, "5432/tcp").Output()
+	if err != nil {
+		stop()
+		return "", nil, fmt.Errorf("failed to read container port: %w", err)
+	}
+	addr := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	return fmt.Sprintf("postgres://postgres:test@%s/trading?sslmode=disable", addr), stop, nil
+}
+
+func testContext(t testing.TB) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// testAccount creates a personal account unique to the test
+func testAccount(t *testing.T) string {
+	id := fmt.Sprintf("it_%s_%d", strings.ToLower(strings.ReplaceAll(t.Name(), "/", "_")), time.Now().UnixNano())
+	if err := testDB.EnsurePersonalAccount(testContext(t), id); err != nil {
+		t.Fatalf("EnsurePersonalAccount: %v", err)
+	}
+	return id
+}
+
+func TestMigrationsAreIdempotent(t *testing.T) {
+	ctx := testContext(t)
+	if err := testDB.Migrate(ctx); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+	applied, err := testDB.AppliedSchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("AppliedSchemaVersion: %v", err)
+	}
+	if applied != SchemaVersion() {
+		t.Errorf("applied schema version = %d, want %d", applied, SchemaVersion())
+	}
+}
+
+func TestSignalQueries(t *testing.T) {
+	ctx := testContext(t)
+
+	signals, err := testDB.GetAllSignals(ctx, 10, "")
+	if err != nil {
+		t.Fatalf("GetAllSignals: %v", err)
+	}
+	if len(signals) == 0 || len(signals) > 10 {
+		t.Fatalf("GetAllSignals returned %d signals, want 1-10 from the seed", len(signals))
+	}
+
+	got, err := testDB.GetSignalByID(ctx, signals[0].SignalID)
+	if err != nil {
+		t.Fatalf("GetSignalByID: %v", err)
+	}
+	if got == nil || got.SignalID != signals[0].SignalID || got.Symbol != signals[0].Symbol {
+		t.Errorf("GetSignalByID(%s) = %+v", signals[0].SignalID, got)
+	}
+	if missing, err := testDB.GetSignalByID(ctx, "no-such-signal"); err != nil || missing != nil {
+		t.Errorf("GetSignalByID(missing) = %+v, %v; want nil, nil", missing, err)
+	}
+
+	if _, err := testDB.GetActiveSignals(ctx); err != nil {
+		t.Errorf("GetActiveSignals: %v", err)
+	}
+
+	bySymbol, err := testDB.GetSignalsBySymbols(ctx, []string{signals[0].Symbol}, 3, "")
+	if err != nil {
+		t.Fatalf("GetSignalsBySymbols: %v", err)
+	}
+	list := bySymbol[signals[0].Symbol]
+	if len(list) == 0 || len(list) > 3 {
+		t.Errorf("GetSignalsBySymbols returned %d signals for %s, want 1-3", len(list), signals[0].Symbol)
+	}
+}
+
+func TestNewsQueries(t *testing.T) {
+	ctx := testContext(t)
+
+	resp, err := testDB.GetNews(ctx, 10, 0, "", "", "", false)
+	if err != nil {
+		t.Fatalf("GetNews: %v", err)
+	}
+	if len(resp.Articles) == 0 || len(resp.Articles) > 10 {
+		t.Fatalf("GetNews returned %d articles, want 1-10 from the seed", len(resp.Articles))
+	}
+	if resp.Total < len(resp.Articles) {
+		t.Errorf("GetNews total = %d, below the %d articles returned", resp.Total, len(resp.Articles))
+	}
+
+	var symbol string
+	for _, a := range resp.Articles {
+		if len(a.AffectedStocks) > 0 {
+			symbol = a.AffectedStocks[0]
+			break
+		}
+	}
+	if symbol == "" {
+		t.Fatal("no seeded article mentions a stock")
+	}
+	filtered, err := testDB.GetNews(ctx, 10, 0, "", "", symbol, false)
+	if err != nil {
+		t.Fatalf("GetNews(symbol): %v", err)
+	}
+	for _, a := range filtered.Articles {
+		if !containsString(a.AffectedStocks, symbol) {
+			t.Errorf("article %s doesn't mention %s: %v", a.ID, symbol, a.AffectedStocks)
+		}
+	}
+
+	bySymbol, err := testDB.GetNewsBySymbols(ctx, []string{symbol}, 2)
+	if err != nil {
+		t.Fatalf("GetNewsBySymbols: %v", err)
+	}
+	if n := len(bySymbol[symbol]); n == 0 || n > 2 {
+		t.Errorf("GetNewsBySymbols returned %d articles for %s, want 1-2", n, symbol)
+	}
+}
+
+func TestMarketQueries(t *testing.T) {
+	ctx := testContext(t)
+	symbols := []string{"RELIANCE", "TCS", "NOSUCH"}
+
+	prices, err := testDB.GetRealtimePricesBySymbols(ctx, symbols)
+	if err != nil {
+		t.Fatalf("GetRealtimePricesBySymbols: %v", err)
+	}
+	if len(prices) != 2 || prices["RELIANCE"].LastPrice <= 0 {
+		t.Errorf("GetRealtimePricesBySymbols = %+v, want RELIANCE and TCS priced", prices)
+	}
+
+	configs, err := testDB.GetStockConfigsBySymbols(ctx, symbols)
+	if err != nil {
+		t.Fatalf("GetStockConfigsBySymbols: %v", err)
+	}
+	if cfg, ok := configs["TCS"]; !ok || cfg.Name == nil || *cfg.Name != "Tata Consultancy Services" {
+		t.Errorf("GetStockConfigsBySymbols[TCS] = %+v", configs["TCS"])
+	}
+
+	results, err := testDB.SearchStocks(ctx, "RELI")
+	if err != nil {
+		t.Fatalf("SearchStocks: %v", err)
+	}
+	found := false
+	for _, r := range results {
+		found = found || r.Symbol == "RELIANCE"
+	}
+	if !found {
+		t.Errorf("SearchStocks(RELI) = %+v, want RELIANCE", results)
+	}
+
+	if _, err := testDB.GetTopGainers(ctx, 5); err != nil {
+		t.Errorf("GetTopGainers: %v", err)
+	}
+}
+
+func TestPortfolioLedger(t *testing.T) {
+	ctx := testContext(t)
+	account := testAccount(t)
+
+	p, err := testDB.CreatePortfolio(ctx, account, account, "Integration", nil, "INR")
+	if err != nil {
+		t.Fatalf("CreatePortfolio: %v", err)
+	}
+	day := func(n int) time.Time { return time.Date(2025, 1, n, 10, 0, 0, 0, time.UTC) }
+	add := func(txnType string, qty float64, at time.Time) error {
+		_, err := testDB.AddPortfolioTransaction(ctx, PortfolioTransaction{
+			PortfolioID: p.ID, Symbol: "INFY", TxnType: txnType, Quantity: qty, Price: 1500,
+		}, at)
+		return err
+	}
+
+	if err := add("BUY", 10, day(1)); err != nil {
+		t.Fatalf("BUY: %v", err)
+	}
+	if err := add("SELL", 5, day(3)); err != nil {
+		t.Fatalf("SELL: %v", err)
+	}
+	// Backdated before the day-3 sell: 10 - 6 holds on day 2, but leaves
+	// -1 after day 3
+	if err := add("SELL", 6, day(2)); !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("backdated SELL 6 = %v, want ErrInsufficientQuantity", err)
+	}
+	if err := add("SELL", 5, day(2)); err != nil {
+		t.Errorf("backdated SELL 5: %v", err)
+	}
+	if err := add("SELL", 1, day(4)); !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("SELL from an empty holding = %v, want ErrInsufficientQuantity", err)
+	}
+
+	summary, err := testDB.GetPortfolioHoldings(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetPortfolioHoldings: %v", err)
+	}
+	for _, h := range summary.Holdings {
+		if h.Symbol == "INFY" && h.Quantity != 0 {
+			t.Errorf("INFY holding = %v, want 0", h.Quantity)
+		}
+	}
+
+	if other, err := testDB.GetPortfolio(ctx, testAccount(t), p.ID); err != nil || other != nil {
+		t.Errorf("GetPortfolio from another account = %+v, %v; want nil, nil", other, err)
+	}
+}
+
+func TestPortfolioConcurrentSells(t *testing.T) {
+	ctx := testContext(t)
+	account := testAccount(t)
+
+	p, err := testDB.CreatePortfolio(ctx, account, account, "Concurrent", nil, "INR")
+	if err != nil {
+		t.Fatalf("CreatePortfolio: %v", err)
+	}
+	txn := PortfolioTransaction{PortfolioID: p.ID, Symbol: "TCS", Quantity: 10, Price: 3900}
+	txn.TxnType = "BUY"
+	if _, err := testDB.AddPortfolioTransaction(ctx, txn, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("BUY: %v", err)
+	}
+
+	txn.TxnType = "SELL"
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = testDB.AddPortfolioTransaction(ctx, txn, time.Now())
+		}(i)
+	}
+	wg.Wait()
+
+	sold := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			sold++
+		case !errors.Is(err, ErrInsufficientQuantity):
+			t.Errorf("concurrent SELL: %v", err)
+		}
+	}
+	if sold != 1 {
+		t.Errorf("%d of 4 concurrent full sells succeeded, want 1", sold)
+	}
+}
+
+func TestPortfolioImportChecksHoldings(t *testing.T) {
+	ctx := testContext(t)
+	account := testAccount(t)
+
+	p, err := testDB.CreatePortfolio(ctx, account, account, "Import", nil, "INR")
+	if err != nil {
+		t.Fatalf("CreatePortfolio: %v", err)
+	}
+	day := func(n int) time.Time { return time.Date(2025, 2, n, 10, 0, 0, 0, time.UTC) }
+	if _, err := testDB.AddPortfolioTransaction(ctx, PortfolioTransaction{
+		PortfolioID: p.ID, Symbol: "HDFCBANK", Exchange: "NSE", TxnType: "BUY", Quantity: 10, Price: 1600,
+	}, day(1)); err != nil {
+		t.Fatalf("BUY: %v", err)
+	}
+
+	trade := func(id, txnType string, qty float64, at time.Time) ImportedTrade {
+		return ImportedTrade{ExternalID: id, Symbol: "HDFCBANK", Exchange: "NSE", TxnType: txnType, Quantity: qty, Price: 1650, TradedAt: at}
+	}
+	// Sells 12 against the manual 10: the whole import must be rolled back
+	_, err = testDB.ImportPortfolioTransactions(ctx, p.ID, "zerodha", []ImportedTrade{
+		trade("T1", "SELL", 8, day(2)),
+		trade("T2", "SELL", 4, day(3)),
+	})
+	if !errors.Is(err, ErrInsufficientQuantity) {
+		t.Fatalf("oversold import = %v, want ErrInsufficientQuantity", err)
+	}
+	txns, err := testDB.ListPortfolioTransactions(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListPortfolioTransactions: %v", err)
+	}
+	if len(txns) != 1 {
+		t.Errorf("ledger has %d rows after a rejected import, want 1", len(txns))
+	}
+
+	result, err := testDB.ImportPortfolioTransactions(ctx, p.ID, "zerodha", []ImportedTrade{
+		trade("T1", "SELL", 8, day(2)),
+		trade("T3", "BUY", 2, day(3)),
+		trade("T2", "SELL", 4, day(4)),
+	})
+	if err != nil {
+		t.Fatalf("covered import: %v", err)
+	}
+	if result.Imported != 3 {
+		t.Errorf("imported %d trades, want 3", result.Imported)
+	}
+}
+
+func TestFailInterruptedExportJobs(t *testing.T) {
+	ctx := testContext(t)
+	user := testAccount(t)
+
+	req := ExportRequest{Dataset: "bars", Format: "csv", From: "2025-01-01", To: "2025-01-31"}
+	ids := []string{user + "-pending", user + "-running", user + "-done"}
+	for _, id := range ids {
+		if _, err := testDB.CreateExportJob(ctx, id, user, req); err != nil {
+			t.Fatalf("CreateExportJob: %v", err)
+		}
+	}
+	if err := testDB.MarkExportJobRunning(ctx, ids[1]); err != nil {
+		t.Fatalf("MarkExportJobRunning: %v", err)
+	}
+	if err := testDB.CompleteExportJob(ctx, ids[2], "local", "exports/x.csv", 1, 10); err != nil {
+		t.Fatalf("CompleteExportJob: %v", err)
+	}
+
+	if _, err := testDB.FailInterruptedExportJobs(ctx, time.Now().Add(time.Second), "restarted"); err != nil {
+		t.Fatalf("FailInterruptedExportJobs: %v", err)
+	}
+	for i, want := range []string{ExportStatusFailed, ExportStatusFailed, ExportStatusCompleted} {
+		job, err := testDB.GetExportJob(ctx, user, ids[i])
+		if err != nil || job == nil {
+			t.Fatalf("GetExportJob %s = %v, %v", ids[i], job, err)
+		}
+		if job.Status != want {
+			t.Errorf("job %s status = %s, want %s", ids[i], job.Status, want)
+		}
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	ctx := testContext(t)
+	account := testAccount(t)
+
+	added, err := testDB.AddToWatchlist(ctx, account, "default", account, []string{"INFY", "TCS", "INFY"})
+	if err != nil {
+		t.Fatalf("AddToWatchlist: %v", err)
+	}
+	if len(added) != 2 {
+		t.Errorf("AddToWatchlist added %v, want INFY and TCS once", added)
+	}
+	if again, err := testDB.AddToWatchlist(ctx, account, "default", account, []string{"TCS"}); err != nil || len(again) != 0 {
+		t.Errorf("re-adding TCS = %v, %v; want nothing added", again, err)
+	}
+
+	removed, err := testDB.RemoveFromWatchlist(ctx, account, "default", "INFY")
+	if err != nil || !removed {
+		t.Errorf("RemoveFromWatchlist = %v, %v; want true", removed, err)
+	}
+	items, err := testDB.ListWatchlist(ctx, account, "default")
+	if err != nil {
+		t.Fatalf("ListWatchlist: %v", err)
+	}
+	if len(items) != 1 || items[0].Symbol != "TCS" {
+		t.Errorf("ListWatchlist = %+v, want only TCS", items)
+	}
+
+	if others, err := testDB.ListWatchlist(ctx, testAccount(t), "default"); err != nil || len(others) != 0 {
+		t.Errorf("another account's watchlist = %+v, %v; want empty", others, err)
+	}
+}
+
+func TestAccountScopedPreferences(t *testing.T) {
+	ctx := testContext(t)
+	account, other := testAccount(t), testAccount(t)
+	user := account
+
+	if added, err := testDB.AddFollow(ctx, account, user, FollowSymbol, "TCS"); err != nil || !added {
+		t.Fatalf("AddFollow = %v, %v; want true", added, err)
+	}
+	if follows, err := testDB.ListFollows(ctx, other); err != nil || len(follows) != 0 {
+		t.Errorf("another account's follows = %+v, %v; want empty", follows, err)
+	}
+	if removed, err := testDB.RemoveFollow(ctx, other, FollowSymbol, "TCS"); err != nil || removed {
+		t.Errorf("RemoveFollow from another account = %v, %v; want false", removed, err)
+	}
+
+	if _, err := testDB.UpsertNotificationSetting(ctx, NotificationSetting{
+		AccountID: account, UserID: user, Channel: "email", Destination: "it@example.com", Enabled: true,
+	}); err != nil {
+		t.Fatalf("UpsertNotificationSetting: %v", err)
+	}
+	if settings, err := testDB.ListNotificationSettings(ctx, other, user); err != nil || len(settings) != 0 {
+		t.Errorf("settings in another account = %+v, %v; want empty", settings, err)
+	}
+	if deleted, err := testDB.DeleteNotificationSetting(ctx, other, user, "email"); err != nil || deleted {
+		t.Errorf("DeleteNotificationSetting in another account = %v, %v; want false", deleted, err)
+	}
+
+	link, err := testDB.CreateShareLink(ctx, NewShareLink(account, user, "dashboard", "", []byte(`{}`), time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	if links, err := testDB.ListShareLinks(ctx, other); err != nil || len(links) != 0 {
+		t.Errorf("another account's share links = %+v, %v; want empty", links, err)
+	}
+	if deleted, err := testDB.DeleteShareLink(ctx, other, link.ID); err != nil || deleted {
+		t.Errorf("DeleteShareLink from another account = %v, %v; want false", deleted, err)
+	}
+	if links, err := testDB.ListShareLinks(ctx, account); err != nil || len(links) != 1 {
+		t.Errorf("ListShareLinks = %+v, %v; want the one link", links, err)
+	}
+}
+
+func TestAccountUsage(t *testing.T) {
+	ctx := testContext(t)
+	account := testAccount(t)
+	day := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, err := testDB.AddAccountUsage(ctx, day, map[string]UsageCounts{account: {APICalls: 3, WSMessages: 1}}); err != nil {
+			t.Fatalf("AddAccountUsage: %v", err)
+		}
+	}
+	rows, err := testDB.ListAccountUsage(ctx, account, day, day)
+	if err != nil {
+		t.Fatalf("ListAccountUsage: %v", err)
+	}
+	if len(rows) != 1 || rows[0].APICalls != 6 || rows[0].WSMessages != 2 {
+		t.Errorf("ListAccountUsage = %+v, want one day of 6 calls and 2 messages", rows)
+	}
+}
+
+func TestAuthSecurityEvents(t *testing.T) {
+	ctx := testContext(t)
+	ip := fmt.Sprintf("198.51.100.%d", time.Now().UnixNano()%250+1)
+	old := time.Now().Add(-100 * 24 * time.Hour)
+
+	for _, at := range []time.Time{old, time.Now()} {
+		if _, err := testDB.InsertAuthSecurityEvent(ctx, AuthSecurityEvent{
+			Kind: "service_token_invalid", Severity: AuthSeverityWarning, IP: ip,
+			Endpoint: "GET /api/signals", OccurredAt: at,
+		}); err != nil {
+			t.Fatalf("InsertAuthSecurityEvent: %v", err)
+		}
+	}
+
+	events, err := testDB.ListAuthSecurityEvents(ctx, AuthSecurityFilter{IP: ip, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuthSecurityEvents: %v", err)
+	}
+	if len(events) != 2 || !events[0].OccurredAt.After(events[1].OccurredAt) {
+		t.Errorf("ListAuthSecurityEvents = %+v, want both events newest first", events)
+	}
+
+	if _, err := testDB.DeleteAuthSecurityEventsBefore(ctx, time.Now().Add(-90*24*time.Hour)); err != nil {
+		t.Fatalf("DeleteAuthSecurityEventsBefore: %v", err)
+	}
+	events, err = testDB.ListAuthSecurityEvents(ctx, AuthSecurityFilter{IP: ip, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuthSecurityEvents: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("%d events left after pruning, want 1", len(events))
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,378 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// JournalMoods are the moods an entry can record
+var JournalMoods = []string{"CONFIDENT", "CALM", "NEUTRAL", "ANXIOUS", "FRUSTRATED", "EUPHORIC"}
+
+// ValidJournalMood reports whether mood is one of JournalMoods
+func ValidJournalMood(mood string) bool {
+	for _, m := range JournalMoods {
+		if m == mood {
+			return true
+		}
+	}
+	return false
+}
+
+// JournalEntry is a day's trading notes, kept in an account's journal by
+// UserID. Mistakes are free-form tags such as "moved-stop" or
+// "revenge-trade"; SignalIDs link the signals the notes are about.
+type JournalEntry struct {
+	ID          int64    `json:"id"`
+	AccountID   string   `json:"account_id"`
+	UserID      string   `json:"user_id"`
+	EntryDate   string   `json:"entry_date"`
+	Title       *string  `json:"title"`
+	Notes       string   `json:"notes"`
+	Mood        *string  `json:"mood"`
+	Mistakes    []string `json:"mistakes"`
+	SignalIDs   []string `json:"signal_ids"`
+	RealizedPnL *float64 `json:"realized_pnl"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// JournalUpdate holds the fields to change on an entry; nil fields are kept
+type JournalUpdate struct {
+	EntryDate   *time.Time
+	Title       *string
+	Notes       *string
+	Mood        *string
+	Mistakes    *[]string
+	SignalIDs   *[]string
+	RealizedPnL *float64
+}
+
+// JournalFilter narrows a journal listing
+type JournalFilter struct {
+	From    *time.Time
+	To      *time.Time
+	Mistake string
+	Limit   int
+}
+
+const journalColumns = `id, account_id, user_id, entry_date, title, notes, mood, mistakes, signal_ids, realized_pnl, created_at, updated_at`
+
+func scanJournalEntry(row rowScanner) (*JournalEntry, error) {
+	var e JournalEntry
+	var entryDate, createdAt, updatedAt time.Time
+	var mistakes, signalIDs pq.StringArray
+	err := row.Scan(&e.ID, &e.AccountID, &e.UserID, &entryDate, &e.Title, &e.Notes, &e.Mood, &mistakes, &signalIDs,
+		&e.RealizedPnL, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+	}
+	e.EntryDate = entryDate.Format("2006-01-02")
+	e.Mistakes = append([]string{}, mistakes...)
+	e.SignalIDs = append([]string{}, signalIDs...)
+	e.CreatedAt = createdAt.Format(time.RFC3339)
+	e.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &e, nil
+}
+
+// ListJournalEntries returns an account's entries, newest first
+func (db *DB) ListJournalEntries(ctx context.Context, accountID string, f JournalFilter) ([]JournalEntry, error) {
+	var from, to interface{}
+	if f.From != nil {
+		from = f.From.Format("2006-01-02")
+	}
+	if f.To != nil {
+		to = f.To.Format("2006-01-02")
+	}
+	var mistake interface{}
+	if f.Mistake != "" {
+		mistake = f.Mistake
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+journalColumns+`
+		FROM core_api.journal_entries
+		WHERE account_id = $1
+			AND ($2::date IS NULL OR entry_date >= $2::date)
+			AND ($3::date IS NULL OR entry_date <= $3::date)
+			AND ($4::text IS NULL OR $4::text = ANY(mistakes))
+		ORDER BY entry_date DESC, id DESC
+		LIMIT $5
+	`, accountID, from, to, mistake, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []JournalEntry{}
+	for rows.Next() {
+		e, err := scanJournalEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return entries, nil
+}
+
+// GetJournalEntry returns a single entry in an account's journal, or nil if not found
+func (db *DB) GetJournalEntry(ctx context.Context, accountID string, id int64) (*JournalEntry, error) {
+	e, err := scanJournalEntry(db.conn.QueryRowContext(ctx, `
+		SELECT `+journalColumns+`
+		FROM core_api.journal_entries
+		WHERE id = $1 AND account_id = $2
+	`, id, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CreateJournalEntry inserts an entry by e.UserID in e.AccountID's journal
+// on entryDate
+func (db *DB) CreateJournalEntry(ctx context.Context, e JournalEntry, entryDate time.Time) (*JournalEntry, error) {
+	if e.Mistakes == nil {
+		e.Mistakes = []string{}
+	}
+	if e.SignalIDs == nil {
+		e.SignalIDs = []string{}
+	}
+	return scanJournalEntry(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.journal_entries
+			(account_id, user_id, entry_date, title, notes, mood, mistakes, signal_ids, realized_pnl)
+		VALUES ($1, $2, $3::date, $4, $5, $6, $7, $8, $9)
+		RETURNING `+journalColumns,
+		e.AccountID, e.UserID, entryDate.Format("2006-01-02"), e.Title, e.Notes, e.Mood,
+		pq.Array(e.Mistakes), pq.Array(e.SignalIDs), e.RealizedPnL))
+}
+
+// UpdateJournalEntry applies u to an entry in an account's journal, returning nil if not found
+func (db *DB) UpdateJournalEntry(ctx context.Context, accountID string, id int64, u JournalUpdate) (*JournalEntry, error) {
+	var entryDate, mistakes, signalIDs interface{}
+	if u.EntryDate != nil {
+		entryDate = u.EntryDate.Format("2006-01-02")
+	}
+	if u.Mistakes != nil {
+		mistakes = pq.Array(*u.Mistakes)
+	}
+	if u.SignalIDs != nil {
+		signalIDs = pq.Array(*u.SignalIDs)
+	}
+
+	e, err := scanJournalEntry(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.journal_entries
+		SET entry_date = COALESCE($3::date, entry_date),
+		    title = COALESCE($4, title),
+		    notes = COALESCE($5, notes),
+		    mood = COALESCE($6, mood),
+		    mistakes = COALESCE($7::text[], mistakes),
+		    signal_ids = COALESCE($8::text[], signal_ids),
+		    realized_pnl = COALESCE($9, realized_pnl),
+		    updated_at = NOW()
+		WHERE id = $1 AND account_id = $2
+		RETURNING `+journalColumns,
+		id, accountID, entryDate, u.Title, u.Notes, u.Mood, mistakes, signalIDs, u.RealizedPnL))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// DeleteJournalEntry removes an entry, returning false if not found
+func (db *DB) DeleteJournalEntry(ctx context.Context, accountID string, id int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.journal_entries WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete journal entry: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// MistakeImpact is what one mistake tag cost over the week
+type MistakeImpact struct {
+	Tag           string  `json:"tag"`
+	Occurrences   int     `json:"occurrences"`
+	LosingEntries int     `json:"losing_entries"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	AvgPnL        float64 `json:"avg_pnl"`
+	// ShareOfLossesPct is the losses on entries with this tag as a share of
+	// all losses in the week. An entry with several tags counts for each.
+	ShareOfLossesPct float64 `json:"share_of_losses_pct"`
+	// AvgSignalReturnPct averages actual_profit_pct of the closed signals
+	// linked from entries with this tag
+	AvgSignalReturnPct *float64 `json:"avg_signal_return_pct"`
+}
+
+// JournalWeeklySummary aggregates a Monday-to-Sunday week of entries
+type JournalWeeklySummary struct {
+	WeekStart     string          `json:"week_start"`
+	WeekEnd       string          `json:"week_end"`
+	Entries       int             `json:"entries"`
+	TradingDays   int             `json:"trading_days"`
+	RealizedPnL   float64         `json:"realized_pnl"`
+	WinningDays   int             `json:"winning_days"`
+	LosingDays    int             `json:"losing_days"`
+	Moods         map[string]int  `json:"moods"`
+	Mistakes      []MistakeImpact `json:"mistakes"`
+	CleanEntries  int             `json:"clean_entries"`
+	CleanPnL      float64         `json:"clean_pnl"`
+	LinkedSignals int             `json:"linked_signals"`
+}
+
+// GetJournalWeeklySummary aggregates an account's entries for the week
+// starting weekStart (a Monday). Mistakes are ordered by the P&L lost to them.
+func (db *DB) GetJournalWeeklySummary(ctx context.Context, accountID string, weekStart time.Time) (*JournalWeeklySummary, error) {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	entries, err := db.ListJournalEntries(ctx, accountID, JournalFilter{From: &weekStart, To: &weekEnd, Limit: 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	var signalIDs []string
+	for _, e := range entries {
+		signalIDs = append(signalIDs, e.SignalIDs...)
+	}
+	signalReturns, err := db.signalReturns(ctx, signalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &JournalWeeklySummary{
+		WeekStart:     weekStart.Format("2006-01-02"),
+		WeekEnd:       weekEnd.Format("2006-01-02"),
+		Entries:       len(entries),
+		Moods:         map[string]int{},
+		Mistakes:      []MistakeImpact{},
+		LinkedSignals: len(signalReturns),
+	}
+
+	dayPnL := map[string]float64{}
+	days := map[string]bool{}
+	var totalLosses float64
+	type tagAcc struct {
+		impact  MistakeImpact
+		losses  float64
+		returns []float64
+	}
+	tags := map[string]*tagAcc{}
+	for _, e := range entries {
+		days[e.EntryDate] = true
+		if e.Mood != nil {
+			s.Moods[*e.Mood]++
+		}
+		var pnl float64
+		if e.RealizedPnL != nil {
+			pnl = *e.RealizedPnL
+			dayPnL[e.EntryDate] += pnl
+			s.RealizedPnL += pnl
+			if pnl < 0 {
+				totalLosses += -pnl
+			}
+		}
+		if len(e.Mistakes) == 0 {
+			s.CleanEntries++
+			s.CleanPnL += pnl
+			continue
+		}
+		for _, tag := range e.Mistakes {
+			acc := tags[tag]
+			if acc == nil {
+				acc = &tagAcc{impact: MistakeImpact{Tag: tag}}
+				tags[tag] = acc
+			}
+			acc.impact.Occurrences++
+			acc.impact.RealizedPnL += pnl
+			if pnl < 0 {
+				acc.impact.LosingEntries++
+				acc.losses += -pnl
+			}
+			for _, id := range e.SignalIDs {
+				if r, ok := signalReturns[id]; ok {
+					acc.returns = append(acc.returns, r)
+				}
+			}
+		}
+	}
+	s.TradingDays = len(days)
+	for _, pnl := range dayPnL {
+		if pnl > 0 {
+			s.WinningDays++
+		} else if pnl < 0 {
+			s.LosingDays++
+		}
+	}
+
+	for _, acc := range tags {
+		m := acc.impact
+		m.AvgPnL = round2(m.RealizedPnL / float64(m.Occurrences))
+		if totalLosses > 0 {
+			m.ShareOfLossesPct = round2(acc.losses / totalLosses * 100)
+		}
+		if len(acc.returns) > 0 {
+			var sum float64
+			for _, r := range acc.returns {
+				sum += r
+			}
+			avg := round2(sum / float64(len(acc.returns)))
+			m.AvgSignalReturnPct = &avg
+		}
+		m.RealizedPnL = round2(m.RealizedPnL)
+		s.Mistakes = append(s.Mistakes, m)
+	}
+	sort.Slice(s.Mistakes, func(i, j int) bool {
+		if s.Mistakes[i].RealizedPnL != s.Mistakes[j].RealizedPnL {
+			return s.Mistakes[i].RealizedPnL < s.Mistakes[j].RealizedPnL
+		}
+		return s.Mistakes[i].Tag < s.Mistakes[j].Tag
+	})
+	s.RealizedPnL = round2(s.RealizedPnL)
+	s.CleanPnL = round2(s.CleanPnL)
+	return s, nil
+}
+
+// signalReturns returns actual_profit_pct for the closed signals among ids
+func (db *DB) signalReturns(ctx context.Context, ids []string) (map[string]float64, error) {
+	returns := map[string]float64{}
+	if len(ids) == 0 {
+		return returns, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT signal_id, actual_profit_pct
+		FROM intraday.signals
+		WHERE signal_id = ANY($1) AND actual_profit_pct IS NOT NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal returns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var r float64
+		if err := rows.Scan(&id, &r); err != nil {
+			return nil, fmt.Errorf("failed to scan signal return: %w", err)
+		}
+		returns[id] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return returns, nil
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Batch loaders fetch a keyed set of rows in one query. They back the GraphQL
+// resolvers so nested fields cost one round trip per level, not per row.
+
+// GetRealtimePricesBySymbols returns the latest quote for each symbol
+func (db *DB) GetRealtimePricesBySymbols(ctx context.Context, symbols []string) (map[string]RealtimePrice, error) {
+	prices := map[string]RealtimePrice{}
+	if len(symbols) == 0 {
+		return prices, nil
+	}
+
+	rows, err := db.queryHot(ctx, "realtime_prices_by_symbols", `
+		SELECT DISTINCT ON (symbol)
+			symbol,
+			COALESCE(last_price, 0),
+			volume,
+			COALESCE(open, 0),
+			COALESCE(high, 0),
+			COALESCE(low, 0),
+			COALESCE(close, 0),
+			change_percent,
+			COALESCE(updated_at::text, '')
+		FROM md.realtime_prices
+		WHERE symbol = ANY($1)
+		ORDER BY symbol, updated_at DESC
+	`, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query realtime prices: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p RealtimePrice
+		if err := rows.Scan(&p.Symbol, &p.LastPrice, &p.Volume, &p.Open, &p.High, &p.Low, &p.Close, &p.ChangePercent, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan realtime price: %w", err)
+		}
+		prices[p.Symbol] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return prices, nil
+}
+
+// GetStockConfigsBySymbols returns the NSE stock config row for each symbol
+func (db *DB) GetStockConfigsBySymbols(ctx context.Context, symbols []string) (map[string]StockConfig, error) {
+	configs := map[string]StockConfig{}
+	if len(symbols) == 0 {
+		return configs, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT DISTINCT ON (symbol)
+			symbol, exchange, name, sector, market_cap_category,
+			intraday_enabled, investment_enabled, fetcher, active,
+			created_at, updated_at, intraday_ai_picked, selection_type
+		FROM md.stock_config
+		WHERE symbol = ANY($1)
+		ORDER BY symbol, (exchange = 'NSE') DESC
+	`, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock configs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s StockConfig
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(
+			&s.Symbol, &s.Exchange, &s.Name, &s.Sector, &s.MarketCapCat,
+			&s.IntradayEnabled, &s.InvestmentEnabled, &s.Fetcher, &s.Active,
+			&createdAt, &updatedAt, &s.IntradayAIPicked, &s.SelectionType,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stock config: %w", err)
+		}
+		s.CreatedAt = createdAt.Format(time.RFC3339)
+		s.UpdatedAt = updatedAt.Format(time.RFC3339)
+		configs[s.Symbol] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return configs, nil
+}
+
+// GetSignalsBySymbols returns up to perSymbol most recent signals per symbol
+func (db *DB) GetSignalsBySymbols(ctx context.Context, symbols []string, perSymbol int, status string) (map[string][]Signal, error) {
+	signals := map[string][]Signal{}
+	if len(symbols) == 0 {
+		return signals, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalColumns+`
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY symbol ORDER BY generated_at DESC) AS rn
+			FROM intraday.signals
+			WHERE symbol = ANY($1) AND ($2 = '' OR status = $2)
+		) s
+		WHERE rn <= $3
+		ORDER BY symbol, generated_at DESC
+	`, symbols, status, perSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals[s.Symbol] = append(signals[s.Symbol], *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return signals, nil
+}
+
+// GetNewsBySymbols returns up to perSymbol most recent articles tagged with each symbol
+func (db *DB) GetNewsBySymbols(ctx context.Context, symbols []string, perSymbol int) (map[string][]NewsArticle, error) {
+	news := map[string][]NewsArticle{}
+	if len(symbols) == 0 {
+		return news, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, id, title, source, published_at, url, summary, sentiment_score, sentiment_label
+		FROM (
+			SELECT
+				ae.symbol,
+				a.id,
+				COALESCE(a.title, '') AS title,
+				COALESCE(a.source, 'Unknown') AS source,
+				COALESCE(a.published_at, NOW()) AS published_at,
+				a.url,
+				a.summary,
+				COALESCE(a.sentiment_score, 0.5) AS sentiment_score,
+				a.sentiment_label,
+				ROW_NUMBER() OVER (PARTITION BY ae.symbol ORDER BY a.published_at DESC) AS rn
+			FROM news.article_entities ae
+			JOIN news.articles a ON a.id = ae.article_id
+			WHERE ae.symbol = ANY($1)
+		) n
+		WHERE rn <= $2
+		ORDER BY symbol, published_at DESC
+	`, symbols, perSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news by symbol: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var a NewsArticle
+		var publishedAt time.Time
+		var llmSentiment sql.NullString
+		if err := rows.Scan(&symbol, &a.ID, &a.Title, &a.Source, &publishedAt, &a.URL, &a.Summary, &a.Confidence, &llmSentiment); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		a.Time = publishedAt.Format(time.RFC3339)
+		applyArticleSentiment(&a, llmSentiment)
+		a.AffectedStocks = []string{symbol}
+		news[symbol] = append(news[symbol], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return news, nil
+}
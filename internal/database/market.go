@@ -64,3 +64,52 @@ func (db *DB) GetMarketIndices(ctx context.Context) ([]MarketIndex, error) {
 
 	return indices, nil
 }
+
+// HeatmapQuote is one active stock's latest price with its classification
+type HeatmapQuote struct {
+	Symbol            string
+	Name              string
+	Sector            string
+	MarketCapCategory string
+	LastPrice         float64
+	ChangePercent     float64
+	Volume            int64
+}
+
+// GetHeatmapQuotes returns today's quotes for active NSE stocks with their
+// sector and market-cap category in one query
+func (db *DB) GetHeatmapQuotes(ctx context.Context) ([]HeatmapQuote, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			sc.symbol,
+			COALESCE(sc.name, sc.symbol),
+			COALESCE(NULLIF(sc.sector, ''), 'Other'),
+			COALESCE(sc.market_cap_category, ''),
+			rp.last_price,
+			COALESCE(rp.change_percent, 0),
+			COALESCE(rp.volume, 0)
+		FROM md.stock_config sc
+		JOIN md.realtime_prices rp ON rp.symbol = sc.symbol
+		WHERE sc.exchange = 'NSE'
+			AND sc.active
+			AND rp.last_price > 0
+			AND rp.updated_at > NOW() - INTERVAL '1 day'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query heatmap quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var quotes []HeatmapQuote
+	for rows.Next() {
+		var q HeatmapQuote
+		if err := rows.Scan(&q.Symbol, &q.Name, &q.Sector, &q.MarketCapCategory, &q.LastPrice, &q.ChangePercent, &q.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan heatmap quote: %w", err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return quotes, nil
+}
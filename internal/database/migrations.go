@@ -0,0 +1,1160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migration is a versioned schema change owned by this service
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+	// Timescale migrations only run when TimescaleDB is enabled; they stay
+	// pending otherwise so turning the flag on later applies them
+	Timescale bool
+}
+
+// migrations lists schema changes in the order they must be applied.
+// Append new entries; never edit or reorder an applied migration.
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "portfolio_ledger",
+		SQL: `
+			CREATE SCHEMA IF NOT EXISTS portfolio;
+
+			CREATE TABLE IF NOT EXISTS portfolio.portfolios (
+				id            BIGSERIAL PRIMARY KEY,
+				user_id       TEXT NOT NULL,
+				name          TEXT NOT NULL,
+				description   TEXT,
+				base_currency TEXT NOT NULL DEFAULT 'INR',
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (user_id, name)
+			);
+
+			CREATE TABLE IF NOT EXISTS portfolio.transactions (
+				id           BIGSERIAL PRIMARY KEY,
+				portfolio_id BIGINT NOT NULL REFERENCES portfolio.portfolios(id) ON DELETE CASCADE,
+				symbol       TEXT NOT NULL,
+				exchange     TEXT NOT NULL DEFAULT 'NSE',
+				txn_type     TEXT NOT NULL CHECK (txn_type IN ('BUY', 'SELL', 'DIVIDEND')),
+				quantity     NUMERIC(18, 4) NOT NULL DEFAULT 0,
+				price        NUMERIC(18, 4) NOT NULL DEFAULT 0,
+				fees         NUMERIC(18, 4) NOT NULL DEFAULT 0,
+				amount       NUMERIC(18, 4) NOT NULL DEFAULT 0,
+				traded_at    TIMESTAMPTZ NOT NULL,
+				notes        TEXT,
+				created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_portfolio_transactions_portfolio
+				ON portfolio.transactions (portfolio_id, traded_at);
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "portfolio_import_dedupe",
+		SQL: `
+			ALTER TABLE portfolio.transactions
+				ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'manual',
+				ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolio_transactions_external
+				ON portfolio.transactions (portfolio_id, source, external_id)
+				WHERE external_id IS NOT NULL;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "index_history",
+		SQL: `
+			CREATE SCHEMA IF NOT EXISTS md;
+
+			CREATE TABLE IF NOT EXISTS md.index_history (
+				index_name TEXT NOT NULL,
+				trade_date DATE NOT NULL,
+				open       NUMERIC(18, 4),
+				high       NUMERIC(18, 4),
+				low        NUMERIC(18, 4),
+				close      NUMERIC(18, 4) NOT NULL,
+				PRIMARY KEY (index_name, trade_date)
+			);
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "corporate_actions",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS md.corporate_actions (
+				id           BIGSERIAL PRIMARY KEY,
+				symbol       TEXT NOT NULL,
+				action_type  TEXT NOT NULL,
+				purpose      TEXT,
+				amount       NUMERIC(18, 4),
+				ex_date      DATE NOT NULL,
+				record_date  DATE,
+				payment_date DATE,
+				announced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (symbol, action_type, ex_date)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_corporate_actions_ex_date
+				ON md.corporate_actions (ex_date);
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "export_jobs",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.export_jobs (
+				id            TEXT PRIMARY KEY,
+				user_id       TEXT NOT NULL,
+				dataset       TEXT NOT NULL,
+				format        TEXT NOT NULL,
+				params        JSONB NOT NULL DEFAULT '{}',
+				status        TEXT NOT NULL DEFAULT 'PENDING',
+				storage       TEXT,
+				object_key    TEXT,
+				row_count     BIGINT NOT NULL DEFAULT 0,
+				size_bytes    BIGINT NOT NULL DEFAULT 0,
+				error_message TEXT,
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				started_at    TIMESTAMPTZ,
+				completed_at  TIMESTAMPTZ
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_export_jobs_user
+				ON core_api.export_jobs (user_id, created_at DESC);
+		`,
+	},
+	{
+		Version:   6,
+		Name:      "timescale_hypertables",
+		Timescale: true,
+		SQL: `
+			CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+			SELECT create_hypertable('md.ticks', 'ts',
+				chunk_time_interval => INTERVAL '1 day',
+				migrate_data => true, if_not_exists => true);
+			SELECT create_hypertable('md.bhavcopy', 'trade_date',
+				chunk_time_interval => INTERVAL '1 year',
+				migrate_data => true, if_not_exists => true);
+
+			CREATE INDEX IF NOT EXISTS idx_ticks_symbol_ts ON md.ticks (symbol, ts DESC);
+
+			ALTER TABLE md.ticks SET (
+				timescaledb.compress,
+				timescaledb.compress_segmentby = 'symbol',
+				timescaledb.compress_orderby = 'ts DESC'
+			);
+			SELECT add_compression_policy('md.ticks', INTERVAL '7 days', if_not_exists => true);
+
+			ALTER TABLE md.bhavcopy SET (
+				timescaledb.compress,
+				timescaledb.compress_segmentby = 'symbol',
+				timescaledb.compress_orderby = 'trade_date DESC'
+			);
+			SELECT add_compression_policy('md.bhavcopy', INTERVAL '1 year', if_not_exists => true);
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "intraday_bar_rollups",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS md.bars_1m (
+				symbol TEXT NOT NULL,
+				bucket TIMESTAMPTZ NOT NULL,
+				open   DOUBLE PRECISION NOT NULL,
+				high   DOUBLE PRECISION NOT NULL,
+				low    DOUBLE PRECISION NOT NULL,
+				close  DOUBLE PRECISION NOT NULL,
+				volume BIGINT NOT NULL DEFAULT 0,
+				ticks  BIGINT NOT NULL DEFAULT 0,
+				PRIMARY KEY (symbol, bucket)
+			);
+			CREATE TABLE IF NOT EXISTS md.bars_5m (LIKE md.bars_1m INCLUDING ALL);
+			CREATE TABLE IF NOT EXISTS md.bars_1d (LIKE md.bars_1m INCLUDING ALL);
+
+			CREATE TABLE IF NOT EXISTS core_api.aggregate_state (
+				name             TEXT PRIMARY KEY,
+				watermark        TIMESTAMPTZ,
+				last_run_at      TIMESTAMPTZ,
+				last_duration_ms BIGINT NOT NULL DEFAULT 0,
+				last_rows        BIGINT NOT NULL DEFAULT 0,
+				last_error       TEXT
+			);
+		`,
+	},
+	{
+		// With TimescaleDB the roll-ups become hierarchical continuous
+		// aggregates. They are created empty and refreshed by the service
+		// from md.ticks, which is only kept for a while, so bars already
+		// rolled up into the summary tables are kept in md.bars_*_legacy.
+		Version:   8,
+		Name:      "intraday_bar_continuous_aggregates",
+		Timescale: true,
+		SQL: `
+			DO $$
+			DECLARE
+				t        TEXT;
+				has_rows BOOLEAN;
+			BEGIN
+				FOREACH t IN ARRAY ARRAY['bars_1m', 'bars_5m', 'bars_1d'] LOOP
+					IF EXISTS (SELECT 1 FROM pg_tables WHERE schemaname = 'md' AND tablename = t) THEN
+						EXECUTE format('SELECT EXISTS (SELECT 1 FROM md.%I)', t) INTO has_rows;
+						IF has_rows THEN
+							EXECUTE format('ALTER TABLE md.%I RENAME TO %I', t, t || '_legacy');
+						ELSE
+							EXECUTE format('DROP TABLE md.%I', t);
+						END IF;
+					END IF;
+				END LOOP;
+			END
+			$$;
+			UPDATE core_api.aggregate_state SET watermark = NULL;
+
+			CREATE MATERIALIZED VIEW IF NOT EXISTS md.bars_1m
+			WITH (timescaledb.continuous, timescaledb.materialized_only = true) AS
+			SELECT symbol, time_bucket(INTERVAL '1 minute', ts) AS bucket,
+				first(last_price, ts)::float8 AS open, max(last_price)::float8 AS high,
+				min(last_price)::float8 AS low, last(last_price, ts)::float8 AS close,
+				COALESCE(max(volume) - min(volume), 0)::bigint AS volume, count(*) AS ticks
+			FROM md.ticks
+			GROUP BY symbol, time_bucket(INTERVAL '1 minute', ts)
+			WITH NO DATA;
+
+			CREATE MATERIALIZED VIEW IF NOT EXISTS md.bars_5m
+			WITH (timescaledb.continuous, timescaledb.materialized_only = true) AS
+			SELECT symbol, time_bucket(INTERVAL '5 minutes', bucket) AS bucket,
+				first(open, bucket) AS open, max(high) AS high, min(low) AS low,
+				last(close, bucket) AS close, sum(volume)::bigint AS volume, sum(ticks)::bigint AS ticks
+			FROM md.bars_1m
+			GROUP BY symbol, time_bucket(INTERVAL '5 minutes', bucket)
+			WITH NO DATA;
+
+			CREATE MATERIALIZED VIEW IF NOT EXISTS md.bars_1d
+			WITH (timescaledb.continuous, timescaledb.materialized_only = true) AS
+			SELECT symbol, time_bucket(INTERVAL '1 day', bucket, 'Asia/Kolkata') AS bucket,
+				first(open, bucket) AS open, max(high) AS high, min(low) AS low,
+				last(close, bucket) AS close, sum(volume)::bigint AS volume, sum(ticks)::bigint AS ticks
+			FROM md.bars_5m
+			GROUP BY symbol, time_bucket(INTERVAL '1 day', bucket, 'Asia/Kolkata')
+			WITH NO DATA;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "retention_runs",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.retention_runs (
+				id          BIGSERIAL PRIMARY KEY,
+				dry_run     BOOLEAN NOT NULL,
+				started_at  TIMESTAMPTZ NOT NULL,
+				finished_at TIMESTAMPTZ NOT NULL,
+				results     JSONB NOT NULL DEFAULT '[]'
+			);
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "event_dead_letters",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.event_dead_letters (
+				id             BIGSERIAL PRIMARY KEY,
+				subject        TEXT NOT NULL,
+				payload        BYTEA NOT NULL,
+				error          TEXT NOT NULL,
+				received_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				attempts       INT NOT NULL DEFAULT 0,
+				last_error     TEXT,
+				reprocessed_at TIMESTAMPTZ
+			);
+			CREATE INDEX IF NOT EXISTS idx_event_dead_letters_pending
+				ON core_api.event_dead_letters (subject, received_at DESC)
+				WHERE reprocessed_at IS NULL;
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "price_snapshots",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.price_snapshots (
+				symbol         TEXT PRIMARY KEY,
+				last_price     DOUBLE PRECISION NOT NULL,
+				volume         BIGINT,
+				change_percent DOUBLE PRECISION,
+				updated_at     TIMESTAMPTZ NOT NULL
+			);
+		`,
+	},
+	{
+		// intraday.signals is created by the signal engine; deployments
+		// without it yet pick these columns up from the engine's own schema
+		Version: 12,
+		Name:    "signal_execution_rules",
+		SQL: `
+			ALTER TABLE IF EXISTS intraday.signals
+				ADD COLUMN IF NOT EXISTS time_in_force TEXT,
+				ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+				ADD COLUMN IF NOT EXISTS scaling_rules JSONB;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "baskets",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.baskets (
+				id          BIGSERIAL PRIMARY KEY,
+				user_id     TEXT NOT NULL,
+				name        TEXT NOT NULL,
+				description TEXT,
+				base_value  DOUBLE PRECISION NOT NULL DEFAULT 100,
+				base_date   DATE NOT NULL,
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (user_id, name)
+			);
+
+			CREATE TABLE IF NOT EXISTS core_api.basket_constituents (
+				basket_id  BIGINT NOT NULL REFERENCES core_api.baskets(id) ON DELETE CASCADE,
+				symbol     TEXT NOT NULL,
+				weight     DOUBLE PRECISION NOT NULL CHECK (weight > 0),
+				base_price DOUBLE PRECISION NOT NULL CHECK (base_price > 0),
+				PRIMARY KEY (basket_id, symbol)
+			);
+
+			CREATE TABLE IF NOT EXISTS core_api.basket_alerts (
+				id              BIGSERIAL PRIMARY KEY,
+				basket_id       BIGINT NOT NULL REFERENCES core_api.baskets(id) ON DELETE CASCADE,
+				condition       TEXT NOT NULL CHECK (condition IN ('NAV_ABOVE', 'NAV_BELOW', 'CHANGE_ABOVE', 'CHANGE_BELOW')),
+				threshold       DOUBLE PRECISION NOT NULL,
+				active          BOOLEAN NOT NULL DEFAULT true,
+				created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				triggered_at    TIMESTAMPTZ,
+				triggered_value DOUBLE PRECISION
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_basket_alerts_active
+				ON core_api.basket_alerts (basket_id) WHERE active;
+		`,
+	},
+	{
+		Version: 14,
+		Name:    "journal_entries",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.journal_entries (
+				id           BIGSERIAL PRIMARY KEY,
+				user_id      TEXT NOT NULL,
+				entry_date   DATE NOT NULL,
+				title        TEXT,
+				notes        TEXT NOT NULL DEFAULT '',
+				mood         TEXT,
+				mistakes     TEXT[] NOT NULL DEFAULT '{}',
+				signal_ids   TEXT[] NOT NULL DEFAULT '{}',
+				realized_pnl DOUBLE PRECISION,
+				created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_journal_entries_user_date
+				ON core_api.journal_entries (user_id, entry_date DESC);
+			CREATE INDEX IF NOT EXISTS idx_journal_entries_mistakes
+				ON core_api.journal_entries USING GIN (mistakes);
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "report_subscriptions",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.report_subscriptions (
+				id           BIGSERIAL PRIMARY KEY,
+				user_id      TEXT NOT NULL,
+				report_type  TEXT NOT NULL,
+				channel      TEXT NOT NULL CHECK (channel IN ('email', 'telegram')),
+				destination  TEXT NOT NULL,
+				send_time    TEXT NOT NULL,
+				weekday      SMALLINT CHECK (weekday BETWEEN 0 AND 6),
+				active       BOOLEAN NOT NULL DEFAULT true,
+				next_run_at  TIMESTAMPTZ NOT NULL,
+				last_sent_at TIMESTAMPTZ,
+				last_error   TEXT,
+				created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_report_subscriptions_due
+				ON core_api.report_subscriptions (next_run_at) WHERE active;
+			CREATE INDEX IF NOT EXISTS idx_report_subscriptions_user
+				ON core_api.report_subscriptions (user_id);
+		`,
+	},
+	{
+		Version: 16,
+		Name:    "market_holidays",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS md.market_holidays (
+				exchange     TEXT NOT NULL DEFAULT 'NSE',
+				holiday_date DATE NOT NULL,
+				description  TEXT NOT NULL,
+				PRIMARY KEY (exchange, holiday_date)
+			);
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "status_page",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.status_daily (
+				component       TEXT NOT NULL,
+				day             DATE NOT NULL,
+				checks          INT NOT NULL DEFAULT 0,
+				degraded_checks INT NOT NULL DEFAULT 0,
+				outage_checks   INT NOT NULL DEFAULT 0,
+				PRIMARY KEY (component, day)
+			);
+
+			CREATE TABLE IF NOT EXISTS core_api.status_incidents (
+				id          BIGSERIAL PRIMARY KEY,
+				component   TEXT NOT NULL,
+				title       TEXT NOT NULL,
+				impact      TEXT NOT NULL CHECK (impact IN ('degraded', 'outage')),
+				started_at  TIMESTAMPTZ NOT NULL,
+				resolved_at TIMESTAMPTZ
+			);
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_status_incidents_open
+				ON core_api.status_incidents (component) WHERE resolved_at IS NULL;
+			CREATE INDEX IF NOT EXISTS idx_status_incidents_started
+				ON core_api.status_incidents (started_at DESC);
+		`,
+	},
+	{
+		// news.articles belongs to the news service, so its url index is
+		// skipped where the table doesn't exist (as in migration 29)
+		Version: 18,
+		Name:    "news_feeds",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.news_feeds (
+				id                    BIGSERIAL PRIMARY KEY,
+				name                  TEXT NOT NULL,
+				url                   TEXT NOT NULL UNIQUE,
+				active                BOOLEAN NOT NULL DEFAULT true,
+				poll_interval_minutes INT NOT NULL DEFAULT 15 CHECK (poll_interval_minutes BETWEEN 1 AND 1440),
+				etag                  TEXT,
+				last_modified         TEXT,
+				last_polled_at        TIMESTAMPTZ,
+				last_success_at       TIMESTAMPTZ,
+				last_error            TEXT,
+				articles_ingested     BIGINT NOT NULL DEFAULT 0,
+				created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			DO $$
+			BEGIN
+				IF to_regclass('news.articles') IS NOT NULL THEN
+					CREATE INDEX IF NOT EXISTS idx_articles_url ON news.articles (url);
+				END IF;
+			END
+			$$;
+		`,
+	},
+	{
+		Version: 19,
+		Name:    "sentiment_queue",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.sentiment_queue (
+				article_id      TEXT PRIMARY KEY,
+				enqueued_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				attempts        INT NOT NULL DEFAULT 0,
+				next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				last_error      TEXT
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_sentiment_queue_next
+				ON core_api.sentiment_queue (next_attempt_at);
+		`,
+	},
+	{
+		Version: 20,
+		Name:    "article_clusters",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.article_clusters (
+				article_id   TEXT PRIMARY KEY,
+				cluster_id   TEXT NOT NULL,
+				simhash      BIGINT NOT NULL,
+				published_at TIMESTAMPTZ NOT NULL,
+				clustered_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_article_clusters_cluster
+				ON core_api.article_clusters (cluster_id);
+			CREATE INDEX IF NOT EXISTS idx_article_clusters_published
+				ON core_api.article_clusters (published_at);
+		`,
+	},
+	{
+		Version: 21,
+		Name:    "notification_settings",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.notification_settings (
+				user_id        TEXT NOT NULL,
+				channel        TEXT NOT NULL CHECK (channel IN ('email', 'telegram')),
+				destination    TEXT NOT NULL,
+				enabled        BOOLEAN NOT NULL DEFAULT true,
+				quiet_start    TEXT,
+				quiet_end      TEXT,
+				digest_enabled BOOLEAN NOT NULL DEFAULT false,
+				digest_time    TEXT,
+				last_digest_at TIMESTAMPTZ,
+				updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (user_id, channel)
+			);
+
+			CREATE TABLE IF NOT EXISTS core_api.pending_notifications (
+				id         BIGSERIAL PRIMARY KEY,
+				user_id    TEXT NOT NULL,
+				channel    TEXT NOT NULL,
+				kind       TEXT NOT NULL,
+				title      TEXT NOT NULL,
+				body       TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_pending_notifications_user
+				ON core_api.pending_notifications (user_id, channel, created_at);
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "signal_fills",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.signal_fills (
+				id          BIGSERIAL PRIMARY KEY,
+				signal_id   TEXT NOT NULL,
+				leg         TEXT NOT NULL CHECK (leg IN ('entry', 'exit')),
+				source      TEXT NOT NULL CHECK (source IN ('broker', 'paper', 'manual')),
+				price       NUMERIC(18, 4) NOT NULL CHECK (price > 0),
+				quantity    NUMERIC(18, 4),
+				filled_at   TIMESTAMPTZ NOT NULL,
+				external_id TEXT,
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (signal_id, leg, source)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_signal_fills_filled
+				ON core_api.signal_fills (filled_at);
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "risk_snapshots",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.risk_snapshots (
+				id               BIGSERIAL PRIMARY KEY,
+				taken_at         TIMESTAMPTZ NOT NULL,
+				open_positions   INTEGER NOT NULL,
+				gross_exposure   NUMERIC(18, 2) NOT NULL,
+				net_exposure     NUMERIC(18, 2) NOT NULL,
+				risk_weighted    NUMERIC(18, 2) NOT NULL,
+				unrealized_pnl   NUMERIC(18, 2) NOT NULL,
+				realized_pnl     NUMERIC(18, 2) NOT NULL,
+				daily_loss_limit NUMERIC(18, 2) NOT NULL,
+				limit_used_pct   NUMERIC(9, 2) NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_risk_snapshots_taken
+				ON core_api.risk_snapshots (taken_at);
+		`,
+	},
+	{
+		Version: 24,
+		Name:    "push_devices",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.push_devices (
+				id            BIGSERIAL PRIMARY KEY,
+				user_id       TEXT NOT NULL,
+				platform      TEXT NOT NULL,
+				token         TEXT NOT NULL UNIQUE,
+				name          TEXT NOT NULL DEFAULT '',
+				enabled       BOOLEAN NOT NULL DEFAULT TRUE,
+				kinds         TEXT[] NOT NULL DEFAULT '{}',
+				critical_only BOOLEAN NOT NULL DEFAULT FALSE,
+				quiet_start   TEXT,
+				quiet_end     TEXT,
+				last_error    TEXT,
+				last_sent_at  TIMESTAMPTZ,
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_push_devices_user
+				ON core_api.push_devices (user_id);
+		`,
+	},
+	{
+		Version: 25,
+		Name:    "share_links",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.share_links (
+				id         BIGSERIAL PRIMARY KEY,
+				user_id    TEXT NOT NULL,
+				kind       TEXT NOT NULL,
+				target     TEXT NOT NULL DEFAULT '',
+				snapshot   JSONB,
+				views      INTEGER NOT NULL DEFAULT 0,
+				expires_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_share_links_user
+				ON core_api.share_links (user_id, expires_at);
+		`,
+	},
+	{
+		Version: 26,
+		Name:    "tradingview_webhooks",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.tradingview_webhooks (
+				id               BIGSERIAL PRIMARY KEY,
+				user_id          TEXT NOT NULL,
+				name             TEXT NOT NULL,
+				mode             TEXT NOT NULL,
+				secret_hash      TEXT NOT NULL UNIQUE,
+				enabled          BOOLEAN NOT NULL DEFAULT true,
+				last_delivery_at TIMESTAMPTZ,
+				created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_tradingview_webhooks_user
+				ON core_api.tradingview_webhooks (user_id);
+
+			CREATE TABLE IF NOT EXISTS core_api.tradingview_deliveries (
+				id          BIGSERIAL PRIMARY KEY,
+				webhook_id  BIGINT NOT NULL REFERENCES core_api.tradingview_webhooks (id) ON DELETE CASCADE,
+				status      TEXT NOT NULL,
+				symbol      TEXT,
+				action      TEXT,
+				signal_id   TEXT,
+				error       TEXT,
+				payload     TEXT NOT NULL,
+				received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_tradingview_deliveries_webhook
+				ON core_api.tradingview_deliveries (webhook_id, received_at DESC);
+		`,
+	},
+	{
+		Version: 27,
+		Name:    "inbound_webhooks",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.inbound_webhooks (
+				id               BIGSERIAL PRIMARY KEY,
+				user_id          TEXT NOT NULL,
+				name             TEXT NOT NULL,
+				slug             TEXT NOT NULL UNIQUE,
+				target           TEXT NOT NULL CHECK (target IN ('signal', 'alert', 'news')),
+				config           JSONB NOT NULL,
+				secret_hash      TEXT NOT NULL,
+				enabled          BOOLEAN NOT NULL DEFAULT true,
+				deliveries       BIGINT NOT NULL DEFAULT 0,
+				records_accepted BIGINT NOT NULL DEFAULT 0,
+				last_delivery_at TIMESTAMPTZ,
+				last_error       TEXT,
+				created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+	},
+	{
+		Version: 28,
+		Name:    "presets",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.presets (
+				id         BIGSERIAL PRIMARY KEY,
+				user_id    TEXT NOT NULL,
+				name       TEXT NOT NULL,
+				scope      TEXT NOT NULL,
+				filters    JSONB NOT NULL DEFAULT '{}',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (user_id, scope, name)
+			);
+		`,
+	},
+	{
+		// Indexes behind the dashboard, signal history and news queries
+		// (see AdviseIndexes). The tables belong to the signal engine and
+		// news service, so each is skipped where its table doesn't exist.
+		// Building them locks writes to the table; on a large deployment,
+		// create them CONCURRENTLY by hand first and this becomes a no-op.
+		Version: 29,
+		Name:    "hot_query_indexes",
+		SQL: `
+			DO $$
+			BEGIN
+				IF to_regclass('intraday.signals') IS NOT NULL THEN
+					CREATE INDEX IF NOT EXISTS idx_signals_generated_at
+						ON intraday.signals (generated_at DESC);
+					CREATE INDEX IF NOT EXISTS idx_signals_status_generated_at
+						ON intraday.signals (status, generated_at DESC);
+					CREATE INDEX IF NOT EXISTS idx_signals_symbol_generated_at
+						ON intraday.signals (symbol, generated_at DESC);
+				END IF;
+				IF to_regclass('news.articles') IS NOT NULL THEN
+					CREATE INDEX IF NOT EXISTS idx_articles_published_at
+						ON news.articles (published_at DESC);
+				END IF;
+				-- (article_id, symbol) is the primary key, so lookups by article
+				-- are already indexed
+				IF to_regclass('news.article_entities') IS NOT NULL THEN
+					CREATE INDEX IF NOT EXISTS idx_article_entities_symbol
+						ON news.article_entities (symbol);
+				END IF;
+			END
+			$$;
+		`,
+	},
+	{
+		Version: 30,
+		Name:    "follows",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.follows (
+				user_id    TEXT NOT NULL,
+				kind       TEXT NOT NULL,
+				value      TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (user_id, kind, value)
+			);
+		`,
+	},
+	{
+		Version: 31,
+		Name:    "signal_approvals",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.signal_approvals (
+				signal_id    TEXT PRIMARY KEY,
+				notional     NUMERIC NOT NULL,
+				threshold    NUMERIC NOT NULL,
+				status       TEXT NOT NULL DEFAULT 'pending',
+				requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				expires_at   TIMESTAMPTZ NOT NULL,
+				decided_by   TEXT,
+				decided_at   TIMESTAMPTZ,
+				note         TEXT
+			);
+			CREATE INDEX IF NOT EXISTS idx_signal_approvals_pending
+				ON core_api.signal_approvals (expires_at) WHERE status = 'pending';
+		`,
+	},
+	{
+		Version: 32,
+		Name:    "admin_confirmations",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.admin_confirmations (
+				id              BIGSERIAL PRIMARY KEY,
+				action          TEXT NOT NULL,
+				method          TEXT NOT NULL,
+				path            TEXT NOT NULL,
+				payload_hash    TEXT NOT NULL,
+				status          TEXT NOT NULL DEFAULT 'pending',
+				requested_by    TEXT NOT NULL,
+				requested_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				expires_at      TIMESTAMPTZ NOT NULL,
+				decided_by      TEXT,
+				decided_at      TIMESTAMPTZ,
+				response_status INT
+			);
+			CREATE INDEX IF NOT EXISTS idx_admin_confirmations_requested
+				ON core_api.admin_confirmations (requested_at DESC);
+		`,
+	},
+	{
+		Version: 33,
+		Name:    "signal_tombstones",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.signal_tombstones (
+				signal_id     TEXT PRIMARY KEY,
+				symbol        TEXT NOT NULL,
+				signal_type   TEXT NOT NULL,
+				status        TEXT NOT NULL,
+				row           JSONB NOT NULL,
+				reason        TEXT NOT NULL,
+				deleted_by    TEXT NOT NULL,
+				deleted_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				restore_until TIMESTAMPTZ NOT NULL
+			);
+		`,
+	},
+	{
+		Version: 34,
+		Name:    "stock_config_snapshots",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.stock_config_snapshots (
+				snapshot_date       DATE NOT NULL,
+				symbol              TEXT NOT NULL,
+				exchange            TEXT NOT NULL,
+				name                TEXT,
+				sector              TEXT,
+				market_cap_category TEXT,
+				intraday_enabled    BOOLEAN NOT NULL,
+				investment_enabled  BOOLEAN NOT NULL,
+				active              BOOLEAN NOT NULL,
+				intraday_ai_picked  BOOLEAN,
+				selection_type      TEXT,
+				taken_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (snapshot_date, symbol, exchange)
+			);
+		`,
+	},
+	{
+		Version: 35,
+		Name:    "price_reconciliations",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.price_reconciliations (
+				trade_date       DATE PRIMARY KEY,
+				tolerance_pct    DOUBLE PRECISION NOT NULL,
+				compared         INTEGER NOT NULL,
+				matched          INTEGER NOT NULL,
+				discrepancies    INTEGER NOT NULL,
+				stale_realtime   INTEGER NOT NULL,
+				missing_bhavcopy INTEGER NOT NULL,
+				run_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE TABLE IF NOT EXISTS core_api.price_reconciliation_items (
+				trade_date     DATE NOT NULL REFERENCES core_api.price_reconciliations (trade_date) ON DELETE CASCADE,
+				symbol         TEXT NOT NULL,
+				kind           TEXT NOT NULL,
+				realtime_price DOUBLE PRECISION,
+				realtime_at    TIMESTAMPTZ,
+				bhavcopy_close DOUBLE PRECISION,
+				diff_pct       DOUBLE PRECISION,
+				PRIMARY KEY (trade_date, symbol)
+			);
+		`,
+	},
+	{
+		// Signals already closed get their generated and closed events
+		// backfilled; their intermediate history was never kept
+		Version: 36,
+		Name:    "signal_events",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.signal_events (
+				id           BIGSERIAL PRIMARY KEY,
+				signal_id    TEXT NOT NULL,
+				kind         TEXT NOT NULL,
+				status       TEXT,
+				price        DOUBLE PRECISION,
+				stop_loss    DOUBLE PRECISION,
+				target_price DOUBLE PRECISION,
+				progress_pct DOUBLE PRECISION,
+				detail       JSONB,
+				occurred_at  TIMESTAMPTZ NOT NULL,
+				recorded_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_signal_events_signal
+				ON core_api.signal_events (signal_id, occurred_at, id);
+			-- Redelivered signal.new and signal.closed events are not recorded twice
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_signal_events_once
+				ON core_api.signal_events (signal_id, kind) WHERE kind IN ('generated', 'closed');
+			DO $$
+			BEGIN
+				IF to_regclass('intraday.signals') IS NOT NULL THEN
+					INSERT INTO core_api.signal_events (signal_id, kind, status, price, stop_loss, target_price, occurred_at)
+					SELECT signal_id, 'generated', 'ACTIVE', entry_price, stop_loss, target_price, generated_at
+					FROM intraday.signals
+					ON CONFLICT DO NOTHING;
+					INSERT INTO core_api.signal_events (signal_id, kind, status, price, stop_loss, target_price, occurred_at)
+					SELECT signal_id, 'closed', status, exit_price, stop_loss, target_price, closed_at
+					FROM intraday.signals
+					WHERE status <> 'ACTIVE' AND closed_at IS NOT NULL
+					ON CONFLICT DO NOTHING;
+				END IF;
+			END
+			$$;
+		`,
+	},
+	{
+		// Accounts own portfolios, baskets and their alerts, presets, journal
+		// entries and watchlists; users act within the accounts they're
+		// members of. Every existing user gets a personal account with the
+		// same ID, so data already stored stays with its user.
+		Version: 37,
+		Name:    "accounts",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.accounts (
+				id         TEXT PRIMARY KEY,
+				name       TEXT NOT NULL,
+				status     TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended')),
+				created_by TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE TABLE IF NOT EXISTS core_api.account_members (
+				account_id TEXT NOT NULL REFERENCES core_api.accounts(id) ON DELETE CASCADE,
+				user_id    TEXT NOT NULL,
+				role       TEXT NOT NULL CHECK (role IN ('owner', 'member')),
+				added_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (account_id, user_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_account_members_user
+				ON core_api.account_members (user_id);
+
+			WITH users AS (
+				SELECT 'default' AS user_id
+				UNION SELECT user_id FROM portfolio.portfolios
+				UNION SELECT user_id FROM core_api.baskets
+				UNION SELECT user_id FROM core_api.journal_entries
+				UNION SELECT user_id FROM core_api.presets
+			), created AS (
+				INSERT INTO core_api.accounts (id, name, created_by)
+				SELECT user_id, user_id, 'migration' FROM users
+				ON CONFLICT DO NOTHING
+				RETURNING id
+			)
+			INSERT INTO core_api.account_members (account_id, user_id, role)
+			SELECT id, id, 'owner' FROM created
+			ON CONFLICT DO NOTHING;
+
+			ALTER TABLE portfolio.portfolios ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE portfolio.portfolios SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE portfolio.portfolios ALTER COLUMN account_id SET NOT NULL;
+			ALTER TABLE portfolio.portfolios DROP CONSTRAINT IF EXISTS portfolios_user_id_name_key;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_account_name
+				ON portfolio.portfolios (account_id, name);
+
+			ALTER TABLE core_api.baskets ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.baskets SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.baskets ALTER COLUMN account_id SET NOT NULL;
+			ALTER TABLE core_api.baskets DROP CONSTRAINT IF EXISTS baskets_user_id_name_key;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_baskets_account_name
+				ON core_api.baskets (account_id, name);
+
+			ALTER TABLE core_api.journal_entries ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.journal_entries SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.journal_entries ALTER COLUMN account_id SET NOT NULL;
+			CREATE INDEX IF NOT EXISTS idx_journal_entries_account_date
+				ON core_api.journal_entries (account_id, entry_date DESC);
+
+			ALTER TABLE core_api.presets ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.presets SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.presets ALTER COLUMN account_id SET NOT NULL;
+			ALTER TABLE core_api.presets DROP CONSTRAINT IF EXISTS presets_user_id_scope_name_key;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_presets_account_scope_name
+				ON core_api.presets (account_id, scope, name);
+
+			-- Watchlists were held in memory; they start empty here
+			CREATE TABLE IF NOT EXISTS core_api.watchlist_items (
+				account_id TEXT NOT NULL REFERENCES core_api.accounts(id),
+				watchlist  TEXT NOT NULL,
+				symbol     TEXT NOT NULL,
+				added_by   TEXT NOT NULL,
+				added_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (account_id, watchlist, symbol)
+			);
+		`,
+	},
+	{
+		// Daily usage per account, added to by every instance's meter, and
+		// per-account overrides of the default soft quotas
+		Version: 38,
+		Name:    "account_usage",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.account_usage (
+				account_id   TEXT NOT NULL,
+				day          DATE NOT NULL,
+				api_calls    BIGINT NOT NULL DEFAULT 0,
+				ws_messages  BIGINT NOT NULL DEFAULT 0,
+				export_rows  BIGINT NOT NULL DEFAULT 0,
+				export_bytes BIGINT NOT NULL DEFAULT 0,
+				updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (account_id, day)
+			);
+			CREATE INDEX IF NOT EXISTS idx_account_usage_day
+				ON core_api.account_usage (day);
+
+			CREATE TABLE IF NOT EXISTS core_api.account_quotas (
+				account_id   TEXT PRIMARY KEY REFERENCES core_api.accounts(id) ON DELETE CASCADE,
+				api_calls    BIGINT CHECK (api_calls >= 0),
+				ws_messages  BIGINT CHECK (ws_messages >= 0),
+				export_bytes BIGINT CHECK (export_bytes >= 0),
+				updated_by   TEXT NOT NULL,
+				updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+	},
+	{
+		// Failed token exchanges, lockouts and other suspicious patterns
+		// seen on the auth endpoints
+		Version: 39,
+		Name:    "auth_security_events",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS core_api.auth_security_events (
+				id          BIGSERIAL PRIMARY KEY,
+				kind        TEXT NOT NULL,
+				severity    TEXT NOT NULL CHECK (severity IN ('info', 'warning', 'critical')),
+				ip          TEXT NOT NULL,
+				endpoint    TEXT NOT NULL DEFAULT '',
+				subject     TEXT NOT NULL DEFAULT '',
+				detail      TEXT NOT NULL DEFAULT '',
+				occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_auth_security_events_occurred
+				ON core_api.auth_security_events (occurred_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_auth_security_events_ip
+				ON core_api.auth_security_events (ip, occurred_at DESC);
+		`,
+	},
+	{
+		// Follows and share links become account-owned like portfolios.
+		// Notification settings and push devices hold a member's own
+		// destinations, so they stay per user within each account. Existing
+		// rows move to their user's personal account.
+		Version: 40,
+		Name:    "account_scoped_preferences",
+		SQL: `
+			WITH users AS (
+				SELECT user_id FROM core_api.follows
+				UNION SELECT user_id FROM core_api.share_links
+				UNION SELECT user_id FROM core_api.notification_settings
+				UNION SELECT user_id FROM core_api.push_devices
+			), created AS (
+				INSERT INTO core_api.accounts (id, name, created_by)
+				SELECT user_id, user_id, 'migration' FROM users
+				ON CONFLICT DO NOTHING
+				RETURNING id
+			)
+			INSERT INTO core_api.account_members (account_id, user_id, role)
+			SELECT id, id, 'owner' FROM created
+			ON CONFLICT DO NOTHING;
+
+			ALTER TABLE core_api.follows ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.follows SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.follows ALTER COLUMN account_id SET NOT NULL;
+			ALTER TABLE core_api.follows DROP CONSTRAINT IF EXISTS follows_pkey;
+			ALTER TABLE core_api.follows ADD PRIMARY KEY (account_id, kind, value);
+
+			ALTER TABLE core_api.share_links ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.share_links SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.share_links ALTER COLUMN account_id SET NOT NULL;
+			DROP INDEX IF EXISTS core_api.idx_share_links_user;
+			CREATE INDEX IF NOT EXISTS idx_share_links_account
+				ON core_api.share_links (account_id, expires_at);
+
+			ALTER TABLE core_api.notification_settings ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.notification_settings SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.notification_settings ALTER COLUMN account_id SET NOT NULL;
+			ALTER TABLE core_api.notification_settings DROP CONSTRAINT IF EXISTS notification_settings_pkey;
+			ALTER TABLE core_api.notification_settings ADD PRIMARY KEY (account_id, user_id, channel);
+
+			ALTER TABLE core_api.pending_notifications ADD COLUMN IF NOT EXISTS account_id TEXT;
+			UPDATE core_api.pending_notifications SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.pending_notifications ALTER COLUMN account_id SET NOT NULL;
+			DROP INDEX IF EXISTS core_api.idx_pending_notifications_user;
+			CREATE INDEX IF NOT EXISTS idx_pending_notifications_account_user
+				ON core_api.pending_notifications (account_id, user_id, channel, created_at);
+
+			ALTER TABLE core_api.push_devices ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES core_api.accounts(id);
+			UPDATE core_api.push_devices SET account_id = user_id WHERE account_id IS NULL;
+			ALTER TABLE core_api.push_devices ALTER COLUMN account_id SET NOT NULL;
+			DROP INDEX IF EXISTS core_api.idx_push_devices_user;
+			CREATE INDEX IF NOT EXISTS idx_push_devices_account_user
+				ON core_api.push_devices (account_id, user_id);
+		`,
+	},
+}
+
+// Migrate applies any pending migrations inside a transaction per version.
+// Migrations are bounded by ctx rather than statement_timeout.
+func (db *DB) Migrate(ctx context.Context) error {
+	return db.maintenance(ctx, func(conn *sql.Conn) error {
+		return db.migrate(ctx, conn)
+	})
+}
+
+func (db *DB) migrate(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, `
+		CREATE SCHEMA IF NOT EXISTS core_api;
+		CREATE TABLE IF NOT EXISTS core_api.schema_migrations (
+			version    INT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	applied := map[int]bool{}
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM core_api.schema_migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		applied[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if m.Timescale && !db.timescale {
+			continue
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+		}
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO core_api.schema_migrations (version, name) VALUES ($1, $2)",
+			m.Version, m.Name,
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+		}
+
+		log.Printf("✅ Applied migration %d: %s", m.Version, m.Name)
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the latest migration this build knows about
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// AppliedSchemaVersion returns the latest migration applied to the
+// database, or 0 if none has been
+func (db *DB) AppliedSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM core_api.schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
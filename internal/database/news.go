@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // NewsArticle represents a news article from the database
@@ -24,6 +26,10 @@ type NewsArticle struct {
 	AffectedStocks []string `json:"affectedStocks"`
 	PriceMovement  float64  `json:"priceMovement"`
 	Confidence     float64  `json:"confidence"`
+	// ClusterID groups near-duplicate reports of the same story; an
+	// unclustered article is its own cluster
+	ClusterID   string `json:"clusterId,omitempty"`
+	ClusterSize int    `json:"clusterSize,omitempty"`
 }
 
 // NewsResponse represents the paginated news response
@@ -34,8 +40,10 @@ type NewsResponse struct {
 	HasMore  bool          `json:"hasMore"`
 }
 
-// GetNews retrieves paginated news articles with optional filters
-func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string) (*NewsResponse, error) {
+// GetNews retrieves paginated news articles with optional filters. With
+// collapse, near-duplicate articles count once and only the earliest
+// matching article of each cluster is returned.
+func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string, collapse bool) (*NewsResponse, error) {
 	// Build WHERE clause
 	conditions := []string{}
 	args := []interface{}{}
@@ -66,28 +74,58 @@ func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search,
 	}
 
 	// Count total
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM news.articles a %s", whereClause)
+	countExpr := "COUNT(*)"
+	if collapse {
+		countExpr = "COUNT(DISTINCT COALESCE(c.cluster_id, a.id))"
+	}
+	countQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM news.articles a
+		LEFT JOIN core_api.article_clusters c ON c.article_id = a.id
+		%s
+	`, countExpr, whereClause)
 	var total int
 	if err := db.conn.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count articles: %w", err)
 	}
 
-	// Fetch articles
+	// Fetch a page of articles, keeping one per cluster when collapsing,
+	// and aggregate each one's symbols
+	pick := "matched"
+	if collapse {
+		pick = "(SELECT DISTINCT ON (cluster_id) * FROM matched ORDER BY cluster_id, published_at ASC NULLS LAST, id) m"
+	}
 	query := fmt.Sprintf(`
-		SELECT
-			a.id,
-			COALESCE(a.title, ''),
-			COALESCE(a.source, 'Unknown'),
-			COALESCE(a.published_at, NOW()),
-			a.url,
-			a.summary,
-			COALESCE(a.sentiment_score, 0.5),
-			a.sentiment_label
-		FROM news.articles a
-		%s
-		ORDER BY a.published_at DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argIdx, argIdx+1)
+		WITH matched AS (
+			SELECT
+				a.id,
+				COALESCE(a.title, '') AS title,
+				COALESCE(a.source, 'Unknown') AS source,
+				a.published_at,
+				a.url,
+				a.summary,
+				COALESCE(a.sentiment_score, 0.5) AS sentiment_score,
+				a.sentiment_label,
+				COALESCE(c.cluster_id, a.id) AS cluster_id
+			FROM news.articles a
+			LEFT JOIN core_api.article_clusters c ON c.article_id = a.id
+			%s
+		), page AS (
+			SELECT id, title, source, published_at, url, summary, sentiment_score, sentiment_label, cluster_id
+			FROM %s
+			ORDER BY published_at DESC
+			LIMIT $%d OFFSET $%d
+		)
+		SELECT p.id, p.title, p.source, COALESCE(p.published_at, NOW()), p.url, p.summary,
+			p.sentiment_score, p.sentiment_label, p.cluster_id, e.symbols
+		FROM page p
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(array_agg(ae.symbol ORDER BY ae.symbol), '{}') AS symbols
+			FROM news.article_entities ae
+			WHERE ae.article_id = p.id
+		) e ON true
+		ORDER BY p.published_at DESC
+	`, whereClause, pick, argIdx, argIdx+1)
 
 	args = append(args, limit, offset)
 
@@ -102,38 +140,20 @@ func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search,
 		var a NewsArticle
 		var publishedAt time.Time
 		var llmSentiment sql.NullString
+		var symbols []string
 
 		if err := rows.Scan(
 			&a.ID, &a.Title, &a.Source, &publishedAt, &a.URL, &a.Summary,
-			&a.Confidence, &llmSentiment,
+			&a.Confidence, &llmSentiment, &a.ClusterID, pq.Array(&symbols),
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan article: %w", err)
 		}
 
 		a.Time = publishedAt.Format(time.RFC3339)
-
-		// Map sentiment
-		if llmSentiment.Valid {
-			label := llmSentiment.String
-			a.SentimentLabel = &label
-			switch strings.ToLower(label) {
-			case "positive":
-				a.Sentiment = a.Confidence
-				a.Impact = "high"
-			case "negative":
-				a.Sentiment = -a.Confidence
-				a.Impact = "high"
-			default:
-				a.Sentiment = 0
-				a.Impact = "low"
-			}
-		} else {
-			a.Sentiment = 0
-			a.Impact = "low"
+		applyArticleSentiment(&a, llmSentiment)
+		if len(symbols) > 0 {
+			a.AffectedStocks = symbols
 		}
-		a.Category = "market"
-		a.PriceMovement = 0
-		a.AffectedStocks = []string{}
 
 		articles = append(articles, a)
 	}
@@ -141,32 +161,8 @@ func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search,
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	// Fetch affected stocks for all articles
-	if len(articles) > 0 {
-		articleIDs := make([]string, len(articles))
-		articleMap := make(map[string]int)
-		for i, a := range articles {
-			articleIDs[i] = a.ID
-			articleMap[a.ID] = i
-		}
-
-		entityQuery := `
-			SELECT article_id, symbol
-			FROM news.article_entities
-			WHERE article_id = ANY($1)
-		`
-		entityRows, err := db.conn.QueryContext(ctx, entityQuery, articleIDs)
-		if err == nil {
-			defer entityRows.Close()
-			for entityRows.Next() {
-				var articleID, sym string
-				if err := entityRows.Scan(&articleID, &sym); err == nil {
-					if idx, ok := articleMap[articleID]; ok {
-						articles[idx].AffectedStocks = append(articles[idx].AffectedStocks, sym)
-					}
-				}
-			}
-		}
+	if err := db.applyClusterSizes(ctx, articles); err != nil {
+		return nil, err
 	}
 
 	// Ensure non-nil articles
@@ -182,3 +178,116 @@ func (db *DB) GetNews(ctx context.Context, limit, offset int, sentiment, search,
 		HasMore:  offset+limit < total,
 	}, nil
 }
+
+// applyArticleSentiment maps the stored sentiment label onto the signed
+// sentiment and impact fields the dashboard expects
+func applyArticleSentiment(a *NewsArticle, llmSentiment sql.NullString) {
+	if llmSentiment.Valid {
+		label := llmSentiment.String
+		a.SentimentLabel = &label
+		switch strings.ToLower(label) {
+		case "positive":
+			a.Sentiment = a.Confidence
+			a.Impact = "high"
+		case "negative":
+			a.Sentiment = -a.Confidence
+			a.Impact = "high"
+		default:
+			a.Sentiment = 0
+			a.Impact = "low"
+		}
+	} else {
+		a.Sentiment = 0
+		a.Impact = "low"
+	}
+	a.Category = "market"
+	a.PriceMovement = 0
+	a.AffectedStocks = []string{}
+}
+
+// newsContextWindow is how far back recent articles are counted
+const newsContextWindow = 24 * time.Hour
+
+// latestHeadlineWindow bounds how old a symbol's latest headline may be
+const latestHeadlineWindow = 7 * 24 * time.Hour
+
+// NewsContext summarises a symbol's recent news for inline display
+type NewsContext struct {
+	// ArticleCount counts articles mentioning the symbol in the last 24 hours
+	ArticleCount int `json:"article_count"`
+	// The latest headline from the last 7 days, if any
+	LatestHeadline       *string  `json:"latest_headline"`
+	LatestSentiment      *string  `json:"latest_sentiment"`
+	LatestSentimentScore *float64 `json:"latest_sentiment_score"`
+	LatestPublishedAt    *string  `json:"latest_published_at"`
+}
+
+// GetNewsContext returns the news context of each symbol in one query,
+// using lateral joins so each symbol only touches its own recent articles
+func (db *DB) GetNewsContext(ctx context.Context, symbols []string) (map[string]*NewsContext, error) {
+	return db.GetNewsContextAt(ctx, symbols, time.Now())
+}
+
+// GetNewsContextAt returns the news context of each symbol as it was at now,
+// leaving out articles published later
+func (db *DB) GetNewsContextAt(ctx context.Context, symbols []string, now time.Time) (map[string]*NewsContext, error) {
+	result := make(map[string]*NewsContext, len(symbols))
+	if len(symbols) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.queryHot(ctx, "news_context", `
+		SELECT s.symbol, COALESCE(recent.article_count, 0),
+			latest.title, latest.sentiment_label, latest.sentiment_score, latest.published_at
+		FROM unnest($1::text[]) AS s(symbol)
+		LEFT JOIN LATERAL (
+			SELECT COUNT(DISTINCT a.id) AS article_count
+			FROM news.article_entities ae
+			JOIN news.articles a ON a.id = ae.article_id
+			WHERE ae.symbol = s.symbol AND a.published_at >= $2 AND a.published_at <= $4
+		) recent ON true
+		LEFT JOIN LATERAL (
+			SELECT a.title, a.sentiment_label, a.sentiment_score, a.published_at
+			FROM news.article_entities ae
+			JOIN news.articles a ON a.id = ae.article_id
+			WHERE ae.symbol = s.symbol AND a.published_at >= $3 AND a.published_at <= $4
+			ORDER BY a.published_at DESC
+			LIMIT 1
+		) latest ON true
+	`, pq.Array(uniqueStrings(symbols)), now.Add(-newsContextWindow), now.Add(-latestHeadlineWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news context: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var nc NewsContext
+		var publishedAt *time.Time
+		if err := rows.Scan(&symbol, &nc.ArticleCount,
+			&nc.LatestHeadline, &nc.LatestSentiment, &nc.LatestSentimentScore, &publishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan news context: %w", err)
+		}
+		if publishedAt != nil {
+			published := publishedAt.Format(time.RFC3339)
+			nc.LatestPublishedAt = &published
+		}
+		result[symbol] = &nc
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return result, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
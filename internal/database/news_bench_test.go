@@ -0,0 +1,198 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// benchArticles is how many articles seedBenchNews adds on top of the demo
+// seed, enough for the 50-article pages the dashboard asks for
+const benchArticles = 120
+
+// seedBenchNews adds recent articles tagged with two symbols each and
+// removes them when b finishes
+func seedBenchNews(b *testing.B) {
+	ctx := testContext(b)
+	now := time.Now()
+	for n := 0; n < benchArticles; n++ {
+		id := fmt.Sprintf("bench-news-%03d", n)
+		if _, err := testDB.conn.ExecContext(ctx, `
+			INSERT INTO news.articles (id, title, source, published_at, url, summary, sentiment_score,
+				sentiment_label, llm_sentiment, llm_confidence)
+			VALUES ($1, $2, 'Bench', $3, $4, $2, 0.8, 'positive', 'positive', 0.9)
+			ON CONFLICT (id) DO NOTHING
+		`, id, "Benchmark article "+id, now.Add(-time.Duration(n)*time.Minute), "https://example.com/news/"+id); err != nil {
+			b.Fatalf("insert article: %v", err)
+		}
+		for _, sym := range []string{demoStocks[n%len(demoStocks)].symbol, demoStocks[(n+1)%len(demoStocks)].symbol} {
+			if _, err := testDB.conn.ExecContext(ctx, `
+				INSERT INTO news.article_entities (article_id, symbol) VALUES ($1, $2)
+				ON CONFLICT DO NOTHING
+			`, id, sym); err != nil {
+				b.Fatalf("insert entity: %v", err)
+			}
+		}
+	}
+	b.Cleanup(func() {
+		testDB.conn.Exec(`DELETE FROM news.articles WHERE id LIKE 'bench-news-%'`)
+	})
+}
+
+// BenchmarkGetNews measures a page of the news feed, symbols included
+func BenchmarkGetNews(b *testing.B) {
+	seedBenchNews(b)
+	ctx := testContext(b)
+	for _, limit := range []int{50, 100} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				resp, err := testDB.GetNews(ctx, limit, 0, "", "", "", false)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(resp.Articles) != limit {
+					b.Fatalf("got %d articles, want %d", len(resp.Articles), limit)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetSignalAlerts measures the news alerts list, which returns up
+// to 50 articles with their symbols
+func BenchmarkGetSignalAlerts(b *testing.B) {
+	seedBenchNews(b)
+	ctx := testContext(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		alerts, err := testDB.GetSignalAlerts(ctx, "", 0.5)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(alerts) != 50 {
+			b.Fatalf("got %d alerts, want 50", len(alerts))
+		}
+	}
+}
+
+// BenchmarkArticleSymbols compares the single query GetNews and
+// GetSignalAlerts now run against the page-then-entities pair of queries
+// they replaced, on the same page of articles
+func BenchmarkArticleSymbols(b *testing.B) {
+	seedBenchNews(b)
+	ctx := testContext(b)
+	for _, limit := range []int{50, 100} {
+		b.Run(fmt.Sprintf("single_query/limit=%d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := articleSymbolsJoined(ctx, limit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("two_queries/limit=%d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := articleSymbolsTwoQueries(ctx, limit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type benchArticle struct {
+	id, title string
+	symbols   []string
+}
+
+func articleSymbolsJoined(ctx context.Context, limit int) ([]benchArticle, error) {
+	rows, err := testDB.conn.QueryContext(ctx, `
+		SELECT p.id, COALESCE(p.title, ''), e.symbols
+		FROM (
+			SELECT id, title, published_at
+			FROM news.articles
+			ORDER BY published_at DESC
+			LIMIT $1
+		) p
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(array_agg(ae.symbol ORDER BY ae.symbol), '{}') AS symbols
+			FROM news.article_entities ae
+			WHERE ae.article_id = p.id
+		) e ON true
+		ORDER BY p.published_at DESC
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []benchArticle
+	for rows.Next() {
+		var a benchArticle
+		if err := rows.Scan(&a.id, &a.title, pq.Array(&a.symbols)); err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// articleSymbolsTwoQueries is the removed approach: fetch the page, then
+// fetch every entity of the page and attach them in Go
+func articleSymbolsTwoQueries(ctx context.Context, limit int) ([]benchArticle, error) {
+	rows, err := testDB.conn.QueryContext(ctx, `
+		SELECT id, COALESCE(title, '')
+		FROM news.articles
+		ORDER BY published_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []benchArticle
+	for rows.Next() {
+		a := benchArticle{symbols: []string{}}
+		if err := rows.Scan(&a.id, &a.title); err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(articles))
+	index := make(map[string]int, len(articles))
+	for i, a := range articles {
+		ids[i] = a.id
+		index[a.id] = i
+	}
+	entityRows, err := testDB.conn.QueryContext(ctx, `
+		SELECT article_id, symbol
+		FROM news.article_entities
+		WHERE article_id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer entityRows.Close()
+	for entityRows.Next() {
+		var articleID, sym string
+		if err := entityRows.Scan(&articleID, &sym); err != nil {
+			return nil, err
+		}
+		if i, ok := index[articleID]; ok {
+			articles[i].symbols = append(articles[i].symbols, sym)
+		}
+	}
+	return articles, entityRows.Err()
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ClusterCandidate is an article not yet assigned to a cluster
+type ClusterCandidate struct {
+	ID          string
+	Title       string
+	Summary     string
+	PublishedAt time.Time
+}
+
+// ArticleCluster assigns an article to a cluster of near-duplicates. The
+// cluster ID is the ID of the cluster's first article. Title is only read,
+// for comparing against new articles.
+type ArticleCluster struct {
+	ArticleID   string
+	ClusterID   string
+	Simhash     uint64
+	PublishedAt time.Time
+	Title       string
+}
+
+// ListUnclusteredArticles returns up to limit articles published since
+// that have no cluster yet, oldest first
+func (db *DB) ListUnclusteredArticles(ctx context.Context, since time.Time, limit int) ([]ClusterCandidate, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT a.id, COALESCE(a.title, ''), COALESCE(a.summary, ''), a.published_at
+		FROM news.articles a
+		WHERE a.published_at >= $1
+		  AND NOT EXISTS (SELECT 1 FROM core_api.article_clusters c WHERE c.article_id = a.id)
+		ORDER BY a.published_at, a.id
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unclustered articles: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []ClusterCandidate{}
+	for rows.Next() {
+		var a ClusterCandidate
+		if err := rows.Scan(&a.ID, &a.Title, &a.Summary, &a.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		candidates = append(candidates, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return candidates, nil
+}
+
+// ListArticleClusters returns cluster assignments of articles published
+// since, for matching new articles against
+func (db *DB) ListArticleClusters(ctx context.Context, since time.Time) ([]ArticleCluster, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT c.article_id, c.cluster_id, c.simhash, c.published_at, COALESCE(a.title, '')
+		FROM core_api.article_clusters c
+		JOIN news.articles a ON a.id = c.article_id
+		WHERE c.published_at >= $1
+		ORDER BY c.published_at
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query article clusters: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := []ArticleCluster{}
+	for rows.Next() {
+		var c ArticleCluster
+		var hash int64
+		if err := rows.Scan(&c.ArticleID, &c.ClusterID, &hash, &c.PublishedAt, &c.Title); err != nil {
+			return nil, fmt.Errorf("failed to scan article cluster: %w", err)
+		}
+		c.Simhash = uint64(hash)
+		clusters = append(clusters, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return clusters, nil
+}
+
+// SaveArticleClusters stores cluster assignments. Articles already
+// assigned keep their cluster.
+func (db *DB) SaveArticleClusters(ctx context.Context, clusters []ArticleCluster) error {
+	if len(clusters) == 0 {
+		return nil
+	}
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range clusters {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.article_clusters (article_id, cluster_id, simhash, published_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (article_id) DO NOTHING
+		`, c.ArticleID, c.ClusterID, int64(c.Simhash), c.PublishedAt); err != nil {
+			return fmt.Errorf("failed to save cluster for %s: %w", c.ArticleID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit article clusters: %w", err)
+	}
+	return nil
+}
+
+// GetNewsCluster returns every article in a cluster, earliest first, or
+// nil if there's no such cluster. An unclustered article ID is a cluster of
+// one.
+func (db *DB) GetNewsCluster(ctx context.Context, clusterID string) ([]NewsArticle, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			a.id,
+			COALESCE(a.title, ''),
+			COALESCE(a.source, 'Unknown'),
+			COALESCE(a.published_at, NOW()),
+			a.url,
+			a.summary,
+			COALESCE(a.sentiment_score, 0.5),
+			a.sentiment_label
+		FROM news.articles a
+		LEFT JOIN core_api.article_clusters c ON c.article_id = a.id
+		WHERE COALESCE(c.cluster_id, a.id) = $1
+		ORDER BY a.published_at ASC NULLS LAST, a.id
+	`, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news cluster: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []NewsArticle
+	for rows.Next() {
+		var a NewsArticle
+		var publishedAt time.Time
+		var llmSentiment sql.NullString
+		if err := rows.Scan(&a.ID, &a.Title, &a.Source, &publishedAt, &a.URL, &a.Summary, &a.Confidence, &llmSentiment); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		a.Time = publishedAt.Format(time.RFC3339)
+		applyArticleSentiment(&a, llmSentiment)
+		a.ClusterID = clusterID
+		articles = append(articles, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	for i := range articles {
+		articles[i].ClusterSize = len(articles)
+	}
+	return articles, nil
+}
+
+// applyClusterSizes fills in how many articles share each article's cluster
+func (db *DB) applyClusterSizes(ctx context.Context, articles []NewsArticle) error {
+	ids := make([]string, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ClusterID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT cluster_id, COUNT(*)
+		FROM core_api.article_clusters
+		WHERE cluster_id = ANY($1)
+		GROUP BY cluster_id
+	`, pq.Array(uniqueStrings(ids)))
+	if err != nil {
+		return fmt.Errorf("failed to query cluster sizes: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := map[string]int{}
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return fmt.Errorf("failed to scan cluster size: %w", err)
+		}
+		sizes[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	for i := range articles {
+		articles[i].ClusterSize = 1
+		if n := sizes[articles[i].ClusterID]; n > 1 {
+			articles[i].ClusterSize = n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NewsFeed is an RSS or Atom feed polled for news articles. ETag and
+// LastModified come from the previous poll and make the next one conditional.
+type NewsFeed struct {
+	ID                  int64   `json:"id"`
+	Name                string  `json:"name"`
+	URL                 string  `json:"url"`
+	Active              bool    `json:"active"`
+	PollIntervalMinutes int     `json:"poll_interval_minutes"`
+	ETag                *string `json:"-"`
+	LastModified        *string `json:"-"`
+	LastPolledAt        *string `json:"last_polled_at"`
+	LastSuccessAt       *string `json:"last_success_at"`
+	LastError           *string `json:"last_error"`
+	ArticlesIngested    int64   `json:"articles_ingested"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+}
+
+// FeedArticle is an article read from a feed. ID is derived from the URL.
+type FeedArticle struct {
+	ID          string
+	Title       string
+	URL         string
+	Summary     string
+	PublishedAt time.Time
+	Symbols     []string
+}
+
+// ErrNewsFeedExists is returned when another feed already has the URL
+var ErrNewsFeedExists = errors.New("a feed with this URL already exists")
+
+const newsFeedColumns = `id, name, url, active, poll_interval_minutes, etag, last_modified,
+	last_polled_at, last_success_at, last_error, articles_ingested, created_at, updated_at`
+
+func scanNewsFeed(row rowScanner) (*NewsFeed, error) {
+	var f NewsFeed
+	var lastPolled, lastSuccess sql.NullTime
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&f.ID, &f.Name, &f.URL, &f.Active, &f.PollIntervalMinutes, &f.ETag, &f.LastModified,
+		&lastPolled, &lastSuccess, &f.LastError, &f.ArticlesIngested, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan news feed: %w", err)
+	}
+	if lastPolled.Valid {
+		t := lastPolled.Time.Format(time.RFC3339)
+		f.LastPolledAt = &t
+	}
+	if lastSuccess.Valid {
+		t := lastSuccess.Time.Format(time.RFC3339)
+		f.LastSuccessAt = &t
+	}
+	f.CreatedAt = createdAt.Format(time.RFC3339)
+	f.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &f, nil
+}
+
+func (db *DB) queryNewsFeeds(ctx context.Context, query string, args ...interface{}) ([]NewsFeed, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news feeds: %w", err)
+	}
+	defer rows.Close()
+
+	feeds := []NewsFeed{}
+	for rows.Next() {
+		f, err := scanNewsFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return feeds, nil
+}
+
+// ListNewsFeeds returns all configured feeds
+func (db *DB) ListNewsFeeds(ctx context.Context) ([]NewsFeed, error) {
+	return db.queryNewsFeeds(ctx, `
+		SELECT `+newsFeedColumns+`
+		FROM core_api.news_feeds
+		ORDER BY name, id
+	`)
+}
+
+// ListDueNewsFeeds returns active feeds not polled within their interval
+func (db *DB) ListDueNewsFeeds(ctx context.Context, now time.Time) ([]NewsFeed, error) {
+	return db.queryNewsFeeds(ctx, `
+		SELECT `+newsFeedColumns+`
+		FROM core_api.news_feeds
+		WHERE active AND (last_polled_at IS NULL
+			OR last_polled_at + poll_interval_minutes * INTERVAL '1 minute' <= $1)
+		ORDER BY last_polled_at NULLS FIRST
+	`, now)
+}
+
+// GetNewsFeed returns a single feed, or nil if not found
+func (db *DB) GetNewsFeed(ctx context.Context, id int64) (*NewsFeed, error) {
+	f, err := scanNewsFeed(db.conn.QueryRowContext(ctx, `
+		SELECT `+newsFeedColumns+`
+		FROM core_api.news_feeds
+		WHERE id = $1
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CreateNewsFeed inserts a feed
+func (db *DB) CreateNewsFeed(ctx context.Context, f NewsFeed) (*NewsFeed, error) {
+	created, err := scanNewsFeed(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.news_feeds (name, url, active, poll_interval_minutes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+newsFeedColumns,
+		f.Name, f.URL, f.Active, f.PollIntervalMinutes))
+	if err != nil {
+		return nil, newsFeedWriteError(err)
+	}
+	return created, nil
+}
+
+// UpdateNewsFeed replaces a feed's settings, returning nil if not found. A
+// changed URL clears the conditional request state.
+func (db *DB) UpdateNewsFeed(ctx context.Context, f NewsFeed) (*NewsFeed, error) {
+	updated, err := scanNewsFeed(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.news_feeds
+		SET name = $2, active = $4, poll_interval_minutes = $5,
+		    etag = CASE WHEN url = $3 THEN etag END,
+		    last_modified = CASE WHEN url = $3 THEN last_modified END,
+		    url = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+newsFeedColumns,
+		f.ID, f.Name, f.URL, f.Active, f.PollIntervalMinutes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newsFeedWriteError(err)
+	}
+	return updated, nil
+}
+
+// newsFeedWriteError maps a unique violation on url to ErrNewsFeedExists
+func newsFeedWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrNewsFeedExists
+	}
+	return err
+}
+
+// DeleteNewsFeed removes a feed. Articles it ingested are kept.
+func (db *DB) DeleteNewsFeed(ctx context.Context, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM core_api.news_feeds WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete news feed: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordNewsFeedPoll stores a poll's outcome. etag and lastModified replace
+// the stored values only on success.
+func (db *DB) RecordNewsFeedPoll(ctx context.Context, id int64, etag, lastModified string, ingested int, pollErr error) error {
+	var err error
+	if pollErr != nil {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.news_feeds
+			SET last_polled_at = NOW(), last_error = $2
+			WHERE id = $1
+		`, id, pollErr.Error())
+	} else {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.news_feeds
+			SET last_polled_at = NOW(), last_success_at = NOW(), last_error = NULL,
+			    etag = $2, last_modified = $3, articles_ingested = articles_ingested + $4
+			WHERE id = $1
+		`, id, nullString(etag), nullString(lastModified), ingested)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record news feed poll: %w", err)
+	}
+	return nil
+}
+
+// InsertFeedArticles writes articles from a feed, skipping any whose ID or
+// URL is already stored, and tags them with their symbols. It returns how
+// many were new.
+func (db *DB) InsertFeedArticles(ctx context.Context, source string, articles []FeedArticle) (int, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, a := range articles {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO news.articles (id, title, source, published_at, url, summary)
+			SELECT $1::text, $2::text, $3::text, $4::timestamptz, $5::text, NULLIF($6::text, '')
+			WHERE NOT EXISTS (SELECT 1 FROM news.articles WHERE url = $5)
+			ON CONFLICT (id) DO NOTHING
+		`, a.ID, a.Title, source, a.PublishedAt, a.URL, a.Summary)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert article %s: %w", a.URL, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		inserted++
+		if len(a.Symbols) > 0 {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO news.article_entities (article_id, symbol)
+				SELECT $1, unnest($2::text[])
+				ON CONFLICT DO NOTHING
+			`, a.ID, pq.Array(a.Symbols)); err != nil {
+				return 0, fmt.Errorf("failed to tag article %s: %w", a.URL, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit feed articles: %w", err)
+	}
+	return inserted, nil
+}
+
+// ListActiveSymbols returns the symbols of active stocks, used to tag articles
+func (db *DB) ListActiveSymbols(ctx context.Context) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, "SELECT DISTINCT symbol FROM md.stock_config WHERE active ORDER BY symbol")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active symbols: %w", err)
+	}
+	defer rows.Close()
+
+	symbols := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
+		}
+		symbols = append(symbols, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return symbols, nil
+}
+
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotificationSetting controls how alert and signal notifications reach a
+// member of an account over one channel. Times are HH:MM in IST. Quiet hours hold
+// non-critical notifications until they end; digest mode holds them all
+// day and sends one summary at DigestTime.
+type NotificationSetting struct {
+	AccountID     string  `json:"account_id"`
+	UserID        string  `json:"user_id"`
+	Channel       string  `json:"channel"`
+	Destination   string  `json:"destination"`
+	Enabled       bool    `json:"enabled"`
+	QuietStart    *string `json:"quiet_start"`
+	QuietEnd      *string `json:"quiet_end"`
+	DigestEnabled bool    `json:"digest_enabled"`
+	DigestTime    *string `json:"digest_time"`
+	LastDigestAt  *string `json:"last_digest_at"`
+	UpdatedAt     string  `json:"updated_at"`
+	// Pending counts notifications held for later delivery
+	Pending int `json:"pending"`
+
+	lastDigest *time.Time
+}
+
+// LastDigest returns LastDigestAt as a time, or nil if no digest was sent
+func (s NotificationSetting) LastDigest() *time.Time {
+	return s.lastDigest
+}
+
+// PendingNotification is a notification held by quiet hours or digest mode
+type PendingNotification struct {
+	ID        int64
+	Kind      string
+	Title     string
+	Body      string
+	CreatedAt time.Time
+}
+
+const notificationSettingColumns = `s.account_id, s.user_id, s.channel, s.destination, s.enabled, s.quiet_start, s.quiet_end,
+	s.digest_enabled, s.digest_time, s.last_digest_at, s.updated_at,
+	(SELECT COUNT(*) FROM core_api.pending_notifications p
+		WHERE p.account_id = s.account_id AND p.user_id = s.user_id AND p.channel = s.channel)`
+
+func scanNotificationSetting(row rowScanner) (*NotificationSetting, error) {
+	var s NotificationSetting
+	var lastDigest sql.NullTime
+	var updatedAt time.Time
+	err := row.Scan(&s.AccountID, &s.UserID, &s.Channel, &s.Destination, &s.Enabled, &s.QuietStart, &s.QuietEnd,
+		&s.DigestEnabled, &s.DigestTime, &lastDigest, &updatedAt, &s.Pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan notification setting: %w", err)
+	}
+	if lastDigest.Valid {
+		t := lastDigest.Time
+		s.lastDigest = &t
+		formatted := t.Format(time.RFC3339)
+		s.LastDigestAt = &formatted
+	}
+	s.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &s, nil
+}
+
+func (db *DB) queryNotificationSettings(ctx context.Context, query string, args ...interface{}) ([]NotificationSetting, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := []NotificationSetting{}
+	for rows.Next() {
+		s, err := scanNotificationSetting(rows)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return settings, nil
+}
+
+// ListNotificationSettings returns a member's channel settings in an
+// account
+func (db *DB) ListNotificationSettings(ctx context.Context, accountID, userID string) ([]NotificationSetting, error) {
+	return db.queryNotificationSettings(ctx, `
+		SELECT `+notificationSettingColumns+`
+		FROM core_api.notification_settings s
+		WHERE s.account_id = $1 AND s.user_id = $2
+		ORDER BY s.channel
+	`, accountID, userID)
+}
+
+// ListEnabledNotificationSettings returns every enabled channel setting
+func (db *DB) ListEnabledNotificationSettings(ctx context.Context) ([]NotificationSetting, error) {
+	return db.queryNotificationSettings(ctx, `
+		SELECT `+notificationSettingColumns+`
+		FROM core_api.notification_settings s
+		WHERE s.enabled
+		ORDER BY s.user_id, s.account_id, s.channel
+	`)
+}
+
+// UpsertNotificationSetting creates or replaces a member's setting for a
+// channel
+func (db *DB) UpsertNotificationSetting(ctx context.Context, s NotificationSetting) (*NotificationSetting, error) {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.notification_settings
+			(account_id, user_id, channel, destination, enabled, quiet_start, quiet_end, digest_enabled, digest_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (account_id, user_id, channel) DO UPDATE SET
+			destination = EXCLUDED.destination, enabled = EXCLUDED.enabled,
+			quiet_start = EXCLUDED.quiet_start, quiet_end = EXCLUDED.quiet_end,
+			digest_enabled = EXCLUDED.digest_enabled, digest_time = EXCLUDED.digest_time,
+			updated_at = NOW()
+	`, s.AccountID, s.UserID, s.Channel, s.Destination, s.Enabled, s.QuietStart, s.QuietEnd, s.DigestEnabled, s.DigestTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification setting: %w", err)
+	}
+
+	return scanNotificationSetting(db.conn.QueryRowContext(ctx, `
+		SELECT `+notificationSettingColumns+`
+		FROM core_api.notification_settings s
+		WHERE s.account_id = $1 AND s.user_id = $2 AND s.channel = $3
+	`, s.AccountID, s.UserID, s.Channel))
+}
+
+// DeleteNotificationSetting removes a member's setting for a channel along
+// with anything held for it
+func (db *DB) DeleteNotificationSetting(ctx context.Context, accountID, userID, channel string) (bool, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"DELETE FROM core_api.notification_settings WHERE account_id = $1 AND user_id = $2 AND channel = $3",
+		accountID, userID, channel)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification setting: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM core_api.pending_notifications WHERE account_id = $1 AND user_id = $2 AND channel = $3",
+		accountID, userID, channel,
+	); err != nil {
+		return false, fmt.Errorf("failed to delete pending notifications: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// QueueNotification holds a notification for later delivery
+func (db *DB) QueueNotification(ctx context.Context, accountID, userID, channel string, n PendingNotification) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.pending_notifications (account_id, user_id, channel, kind, title, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, accountID, userID, channel, n.Kind, n.Title, n.Body)
+	if err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
+
+// ListPendingNotifications returns what's held for a member's channel,
+// oldest first
+func (db *DB) ListPendingNotifications(ctx context.Context, accountID, userID, channel string) ([]PendingNotification, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, kind, title, body, created_at
+		FROM core_api.pending_notifications
+		WHERE account_id = $1 AND user_id = $2 AND channel = $3
+		ORDER BY created_at, id
+	`, accountID, userID, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	pending := []PendingNotification{}
+	for rows.Next() {
+		var n PendingNotification
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Title, &n.Body, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending notification: %w", err)
+		}
+		pending = append(pending, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return pending, nil
+}
+
+// ClearPendingNotifications removes delivered notifications
+func (db *DB) ClearPendingNotifications(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.pending_notifications WHERE id = ANY($1)", pq.Array(ids),
+	); err != nil {
+		return fmt.Errorf("failed to clear pending notifications: %w", err)
+	}
+	return nil
+}
+
+// RecordNotificationDigest notes when a channel's digest went out
+func (db *DB) RecordNotificationDigest(ctx context.Context, accountID, userID, channel string, at time.Time) error {
+	if _, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.notification_settings SET last_digest_at = $4
+		WHERE account_id = $1 AND user_id = $2 AND channel = $3
+	`, accountID, userID, channel, at); err != nil {
+		return fmt.Errorf("failed to record notification digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// benchmarkIndex is the default comparison index; its TRI series is preferred
+const benchmarkIndex = "NIFTY 50"
+
+// CashFlow is a dated cash movement from the investor's perspective
+// (negative = money put in, positive = money taken out)
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// IndexPoint is a daily index close
+type IndexPoint struct {
+	Date  time.Time `json:"date"`
+	Close float64   `json:"close"`
+}
+
+// PerformancePoint is one day of the portfolio vs benchmark growth series
+type PerformancePoint struct {
+	Date           string  `json:"date"`
+	PortfolioValue float64 `json:"portfolio_value"`
+	PortfolioIndex float64 `json:"portfolio_index"`
+	BenchmarkIndex float64 `json:"benchmark_index"`
+}
+
+// PortfolioPerformance reports time-weighted and money-weighted returns vs benchmark
+type PortfolioPerformance struct {
+	PortfolioID         int64              `json:"portfolio_id"`
+	Period              string             `json:"period"`
+	From                string             `json:"from"`
+	To                  string             `json:"to"`
+	StartValue          float64            `json:"start_value"`
+	EndValue            float64            `json:"end_value"`
+	NetInflows          float64            `json:"net_inflows"`
+	TimeWeightedReturn  float64            `json:"time_weighted_return_pct"`
+	AnnualizedTWR       *float64           `json:"annualized_twr_pct"`
+	XIRR                *float64           `json:"xirr_pct"`
+	Benchmark           string             `json:"benchmark"`
+	BenchmarkReturn     *float64           `json:"benchmark_return_pct"`
+	AnnualizedBenchmark *float64           `json:"annualized_benchmark_pct"`
+	ExcessReturn        *float64           `json:"excess_return_pct"`
+	Series              []PerformancePoint `json:"series"`
+	Notes               []string           `json:"notes"`
+	Timestamp           string             `json:"timestamp"`
+}
+
+// PeriodStart resolves a period label (1M, 3M, 6M, YTD, 1Y, 3Y, ALL) to its start date
+func PeriodStart(period string, now time.Time, inception time.Time) (time.Time, error) {
+	switch period {
+	case "1M":
+		return now.AddDate(0, -1, 0), nil
+	case "3M":
+		return now.AddDate(0, -3, 0), nil
+	case "6M":
+		return now.AddDate(0, -6, 0), nil
+	case "YTD":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), nil
+	case "1Y":
+		return now.AddDate(-1, 0, 0), nil
+	case "3Y":
+		return now.AddDate(-3, 0, 0), nil
+	case "ALL", "":
+		return inception, nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported period %q (use 1M, 3M, 6M, YTD, 1Y, 3Y or ALL)", period)
+}
+
+// GetPortfolioPerformance computes TWR, XIRR and benchmark-relative return
+func (db *DB) GetPortfolioPerformance(ctx context.Context, portfolioID int64, period string) (*PortfolioPerformance, error) {
+	txns, err := db.ListPortfolioTransactions(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := istLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	perf := &PortfolioPerformance{
+		PortfolioID: portfolioID,
+		Period:      period,
+		Benchmark:   benchmarkIndex,
+		Series:      []PerformancePoint{},
+		Notes:       []string{},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	if len(txns) == 0 {
+		perf.Notes = append(perf.Notes, "Portfolio has no transactions")
+		return perf, nil
+	}
+
+	firstTrade, _ := time.Parse(time.RFC3339, txns[0].TradedAt)
+	firstTrade = firstTrade.In(loc)
+	inception := time.Date(firstTrade.Year(), firstTrade.Month(), firstTrade.Day(), 0, 0, 0, 0, loc)
+
+	from, err := PeriodStart(period, today, inception)
+	if err != nil {
+		return nil, err
+	}
+	if from.Before(inception) {
+		from = inception
+	}
+	perf.From = from.Format("2006-01-02")
+	perf.To = today.Format("2006-01-02")
+
+	symbols := map[string]bool{}
+	for _, t := range txns {
+		symbols[t.Symbol] = true
+	}
+	symbolList := make([]string, 0, len(symbols))
+	for s := range symbols {
+		symbolList = append(symbolList, s)
+	}
+
+	// Pull closes from a week before the window so the first day has a price
+	closes, err := db.GetDailyCloses(ctx, symbolList, from.AddDate(0, 0, -7), today)
+	if err != nil {
+		return nil, err
+	}
+	livePrices, err := db.GetLatestPrices(ctx, symbolList)
+	if err != nil {
+		return nil, err
+	}
+
+	benchmark, benchmarkName, err := db.GetIndexSeries(ctx, benchmarkIndex, from.AddDate(0, 0, -7), today)
+	if err != nil {
+		return nil, err
+	}
+	perf.Benchmark = benchmarkName
+
+	// Walk the calendar, carrying last known closes forward over holidays
+	qty := map[string]float64{}
+	lastClose := map[string]float64{}
+	lastTradePrice := map[string]float64{}
+	txnIdx := 0
+	twrGrowth := 1.0
+	prevValue := 0.0
+	netInflows := 0.0
+	flows := []CashFlow{}
+	var benchStart, benchLast float64
+
+	valueAt := func() float64 {
+		v := 0.0
+		for s, q := range qty {
+			if q <= 0 {
+				continue
+			}
+			price := lastClose[s]
+			if price == 0 {
+				price = lastTradePrice[s]
+			}
+			v += q * price
+		}
+		return v
+	}
+
+	benchIdx := 0
+	for day := from.AddDate(0, 0, -7); !day.After(today); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		inWindow := !day.Before(from)
+
+		// Buys are money put in, sells and dividends money taken out
+		dayIn, dayOut := 0.0, 0.0
+		for txnIdx < len(txns) {
+			t := txns[txnIdx]
+			tradedAt, _ := time.Parse(time.RFC3339, t.TradedAt)
+			if !tradedAt.Before(dayEnd) {
+				break
+			}
+			switch t.TxnType {
+			case "BUY":
+				qty[t.Symbol] += t.Quantity
+				lastTradePrice[t.Symbol] = t.Price
+				if inWindow {
+					dayIn += t.Quantity*t.Price + t.Fees
+				}
+			case "SELL":
+				qty[t.Symbol] -= t.Quantity
+				lastTradePrice[t.Symbol] = t.Price
+				if inWindow {
+					dayOut += t.Quantity*t.Price - t.Fees
+				}
+			case "DIVIDEND":
+				if inWindow {
+					dayOut += t.Amount
+				}
+			}
+			txnIdx++
+		}
+
+		key := day.Format("2006-01-02")
+		for s := range symbols {
+			if c, ok := closes[s][key]; ok {
+				lastClose[s] = c
+			}
+		}
+		if day.Equal(today) {
+			for s, p := range livePrices {
+				lastClose[s] = p
+			}
+		}
+		for benchIdx < len(benchmark) && benchmark[benchIdx].Date.Before(dayEnd) {
+			benchLast = benchmark[benchIdx].Close
+			benchIdx++
+		}
+
+		value := valueAt()
+		if !inWindow {
+			prevValue = value
+			continue
+		}
+
+		if day.Equal(from) {
+			// Opening value is treated as an initial contribution
+			perf.StartValue = prevValue
+			if prevValue > 0 {
+				flows = append(flows, CashFlow{Date: day, Amount: -prevValue})
+			}
+			benchStart = benchLast
+		}
+		if dayFlow := dayIn - dayOut; dayFlow != 0 {
+			flows = append(flows, CashFlow{Date: day, Amount: -dayFlow})
+			netInflows += dayFlow
+		}
+
+		twrGrowth *= dailyGrowth(prevValue, value, dayIn, dayOut)
+		prevValue = value
+
+		benchIndex := 0.0
+		if benchStart > 0 {
+			benchIndex = benchLast / benchStart * 100
+		}
+		perf.Series = append(perf.Series, PerformancePoint{
+			Date:           key,
+			PortfolioValue: round2(value),
+			PortfolioIndex: round2(twrGrowth * 100),
+			BenchmarkIndex: round2(benchIndex),
+		})
+	}
+
+	perf.EndValue = prevValue
+	perf.NetInflows = netInflows
+	perf.TimeWeightedReturn = round2((twrGrowth - 1) * 100)
+
+	years := today.Sub(from).Hours() / 24 / 365
+	if years >= 1 {
+		a := round2((math.Pow(twrGrowth, 1/years) - 1) * 100)
+		perf.AnnualizedTWR = &a
+	}
+
+	if prevValue > 0 {
+		flows = append(flows, CashFlow{Date: today, Amount: prevValue})
+	}
+	if rate, err := XIRR(flows); err == nil {
+		x := round2(rate * 100)
+		perf.XIRR = &x
+	} else {
+		perf.Notes = append(perf.Notes, "XIRR unavailable: "+err.Error())
+	}
+
+	if benchStart > 0 && benchLast > 0 {
+		b := round2((benchLast/benchStart - 1) * 100)
+		perf.BenchmarkReturn = &b
+		excess := round2(perf.TimeWeightedReturn - b)
+		perf.ExcessReturn = &excess
+		if years >= 1 {
+			ab := round2((math.Pow(benchLast/benchStart, 1/years) - 1) * 100)
+			perf.AnnualizedBenchmark = &ab
+		}
+	} else {
+		perf.Notes = append(perf.Notes, "Benchmark series unavailable for the selected period")
+	}
+	if benchmarkName != benchmarkIndex+" TRI" {
+		perf.Notes = append(perf.Notes, "Total return index unavailable; benchmark uses price index")
+	}
+
+	return perf, nil
+}
+
+// dailyGrowth is one day's TWR growth factor. Money put in is assumed to
+// arrive at the start of the day and money taken out to leave at the end, so
+// a full exit books the day's price move instead of a -100% return
+func dailyGrowth(prevValue, value, in, out float64) float64 {
+	base := prevValue + in
+	if base <= 0 {
+		return 1
+	}
+	return (value + out) / base
+}
+
+// XIRR solves for the annualised rate that zeroes the NPV of dated cash flows,
+// using Newton-Raphson with a bisection fallback
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, errors.New("need at least two cash flows")
+	}
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].Date.Before(flows[j].Date) })
+
+	hasNeg, hasPos := false, false
+	for _, f := range flows {
+		if f.Amount < 0 {
+			hasNeg = true
+		}
+		if f.Amount > 0 {
+			hasPos = true
+		}
+	}
+	if !hasNeg || !hasPos {
+		return 0, errors.New("cash flows must include both contributions and withdrawals")
+	}
+
+	t0 := flows[0].Date
+	npv := func(rate float64) (float64, float64) {
+		var value, deriv float64
+		for _, f := range flows {
+			years := f.Date.Sub(t0).Hours() / 24 / 365
+			denom := math.Pow(1+rate, years)
+			value += f.Amount / denom
+			deriv -= years * f.Amount / (denom * (1 + rate))
+		}
+		return value, deriv
+	}
+
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value, deriv := npv(rate)
+		if math.Abs(value) < 1e-7 {
+			return rate, nil
+		}
+		if deriv == 0 {
+			break
+		}
+		next := rate - value/deriv
+		if next <= -0.999999 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, nil
+		}
+		rate = next
+	}
+
+	// Bisection over a wide bracket when Newton fails to converge
+	lo, hi := -0.9999, 100.0
+	fLo, _ := npv(lo)
+	fHi, _ := npv(hi)
+	if fLo*fHi > 0 {
+		return 0, errors.New("no solution in range")
+	}
+	for i := 0; i < 300; i++ {
+		mid := (lo + hi) / 2
+		fMid, _ := npv(mid)
+		if math.Abs(fMid) < 1e-7 || (hi-lo) < 1e-10 {
+			return mid, nil
+		}
+		if fLo*fMid < 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// GetDailyCloses returns EOD closes from the bhavcopy keyed by symbol then date
+func (db *DB) GetDailyCloses(ctx context.Context, symbols []string, from, to time.Time) (map[string]map[string]float64, error) {
+	closes := map[string]map[string]float64{}
+	if len(symbols) == 0 {
+		return closes, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, trade_date, close
+		FROM md.bhavcopy
+		WHERE symbol = ANY($1)
+			AND series = 'EQ'
+			AND trade_date BETWEEN $2::date AND $3::date
+		ORDER BY trade_date
+	`, symbols, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily closes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var date time.Time
+		var close float64
+		if err := rows.Scan(&symbol, &date, &close); err != nil {
+			return nil, fmt.Errorf("failed to scan daily close: %w", err)
+		}
+		if closes[symbol] == nil {
+			closes[symbol] = map[string]float64{}
+		}
+		closes[symbol][date.Format("2006-01-02")] = close
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return closes, nil
+}
+
+// GetIndexSeries returns daily closes for an index, preferring its total return
+// variant ("<index> TRI") when available. The series name used is returned.
+func (db *DB) GetIndexSeries(ctx context.Context, index string, from, to time.Time) ([]IndexPoint, string, error) {
+	for _, name := range []string{index + " TRI", index} {
+		rows, err := db.conn.QueryContext(ctx, `
+			SELECT trade_date, close
+			FROM md.index_history
+			WHERE index_name = $1 AND trade_date BETWEEN $2::date AND $3::date
+			ORDER BY trade_date
+		`, name, from, to)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query index series: %w", err)
+		}
+
+		points := []IndexPoint{}
+		for rows.Next() {
+			var p IndexPoint
+			if err := rows.Scan(&p.Date, &p.Close); err != nil {
+				rows.Close()
+				return nil, "", fmt.Errorf("failed to scan index point: %w", err)
+			}
+			points = append(points, p)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("rows iteration error: %w", err)
+		}
+		if len(points) > 0 {
+			return points, name, nil
+		}
+	}
+	return []IndexPoint{}, index, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDailyGrowthFullLiquidation(t *testing.T) {
+	// Buy 100 @ 10, close at 11, sell everything @ 11 the next day,
+	// then sit in cash; a dividend of 50 arrives after the exit
+	days := []struct {
+		value, in, out float64
+	}{
+		{1000, 1000, 0},
+		{1100, 0, 0},
+		{0, 0, 1100},
+		{0, 0, 0},
+		{0, 0, 50},
+	}
+	growth, prev := 1.0, 0.0
+	for _, d := range days {
+		growth *= dailyGrowth(prev, d.value, d.in, d.out)
+		prev = d.value
+	}
+	if twr := (growth - 1) * 100; math.Abs(twr-10) > 1e-9 {
+		t.Errorf("TWR after full exit = %.4f%%, want 10%%", twr)
+	}
+}
+
+func TestDailyGrowthPartialSell(t *testing.T) {
+	// Holding worth 1000 rises 5% and half is sold at the close
+	if g := dailyGrowth(1000, 525, 0, 525); math.Abs(g-1.05) > 1e-9 {
+		t.Errorf("growth = %v, want 1.05", g)
+	}
+}
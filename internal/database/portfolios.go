@@ -0,0 +1,484 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrInsufficientQuantity is returned when a sell exceeds the quantity held
+var ErrInsufficientQuantity = errors.New("sell quantity exceeds holding")
+
+// Portfolio represents a portfolio owned by an account. UserID is who
+// created it.
+type Portfolio struct {
+	ID           int64   `json:"id"`
+	AccountID    string  `json:"account_id"`
+	UserID       string  `json:"user_id"`
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	BaseCurrency string  `json:"base_currency"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+// PortfolioTransaction represents a single ledger entry (buy, sell or dividend)
+type PortfolioTransaction struct {
+	ID          int64   `json:"id"`
+	PortfolioID int64   `json:"portfolio_id"`
+	Symbol      string  `json:"symbol"`
+	Exchange    string  `json:"exchange"`
+	TxnType     string  `json:"txn_type"`
+	Quantity    float64 `json:"quantity"`
+	Price       float64 `json:"price"`
+	Fees        float64 `json:"fees"`
+	Amount      float64 `json:"amount"`
+	TradedAt    string  `json:"traded_at"`
+	Notes       *string `json:"notes"`
+	Source      string  `json:"source"`
+	ExternalID  *string `json:"external_id"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// Holding represents a computed position derived from the ledger
+type Holding struct {
+	Symbol           string  `json:"symbol"`
+	Exchange         string  `json:"exchange"`
+	Quantity         float64 `json:"quantity"`
+	AvgCost          float64 `json:"avg_cost"`
+	InvestedValue    float64 `json:"invested_value"`
+	LastPrice        float64 `json:"last_price"`
+	MarketValue      float64 `json:"market_value"`
+	UnrealizedPnL    float64 `json:"unrealized_pnl"`
+	UnrealizedPnLPct float64 `json:"unrealized_pnl_pct"`
+	RealizedPnL      float64 `json:"realized_pnl"`
+	DividendIncome   float64 `json:"dividend_income"`
+}
+
+// PortfolioSummary represents holdings plus portfolio-level P&L totals
+type PortfolioSummary struct {
+	PortfolioID    int64     `json:"portfolio_id"`
+	Holdings       []Holding `json:"holdings"`
+	InvestedValue  float64   `json:"invested_value"`
+	MarketValue    float64   `json:"market_value"`
+	UnrealizedPnL  float64   `json:"unrealized_pnl"`
+	RealizedPnL    float64   `json:"realized_pnl"`
+	DividendIncome float64   `json:"dividend_income"`
+	TotalPnL       float64   `json:"total_pnl"`
+	Timestamp      string    `json:"timestamp"`
+}
+
+// ListPortfolios returns all portfolios owned by an account
+func (db *DB) ListPortfolios(ctx context.Context, accountID string) ([]Portfolio, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+portfolioColumns+`
+		FROM portfolio.portfolios
+		WHERE account_id = $1
+		ORDER BY created_at
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	portfolios := []Portfolio{}
+	for rows.Next() {
+		p, err := scanPortfolio(rows)
+		if err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return portfolios, nil
+}
+
+// GetPortfolio returns a single portfolio owned by an account, or nil if not found
+func (db *DB) GetPortfolio(ctx context.Context, accountID string, id int64) (*Portfolio, error) {
+	row := db.conn.QueryRowContext(ctx, `
+		SELECT `+portfolioColumns+`
+		FROM portfolio.portfolios
+		WHERE id = $1 AND account_id = $2
+	`, id, accountID)
+
+	p, err := scanPortfolio(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreatePortfolio inserts a new portfolio for an account
+func (db *DB) CreatePortfolio(ctx context.Context, accountID, userID, name string, description *string, baseCurrency string) (*Portfolio, error) {
+	if baseCurrency == "" {
+		baseCurrency = "INR"
+	}
+
+	row := db.conn.QueryRowContext(ctx, `
+		INSERT INTO portfolio.portfolios (account_id, user_id, name, description, base_currency)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+portfolioColumns+`
+	`, accountID, userID, name, description, baseCurrency)
+
+	p, err := scanPortfolio(row)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdatePortfolio updates a portfolio's name and/or description
+func (db *DB) UpdatePortfolio(ctx context.Context, accountID string, id int64, name, description *string) (*Portfolio, error) {
+	row := db.conn.QueryRowContext(ctx, `
+		UPDATE portfolio.portfolios
+		SET name = COALESCE($3, name),
+		    description = COALESCE($4, description),
+		    updated_at = NOW()
+		WHERE id = $1 AND account_id = $2
+		RETURNING `+portfolioColumns+`
+	`, id, accountID, name, description)
+
+	p, err := scanPortfolio(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeletePortfolio removes a portfolio and its ledger, returning false if not found
+func (db *DB) DeletePortfolio(ctx context.Context, accountID string, id int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"DELETE FROM portfolio.portfolios WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete portfolio: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ListPortfolioTransactions returns the ledger for a portfolio in trade order
+func (db *DB) ListPortfolioTransactions(ctx context.Context, portfolioID int64) ([]PortfolioTransaction, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, portfolio_id, symbol, exchange, txn_type, quantity, price, fees, amount,
+		       traded_at, notes, source, external_id, created_at
+		FROM portfolio.transactions
+		WHERE portfolio_id = $1
+		ORDER BY traded_at, id
+	`, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	txns := []PortfolioTransaction{}
+	for rows.Next() {
+		var t PortfolioTransaction
+		var tradedAt, createdAt time.Time
+		if err := rows.Scan(
+			&t.ID, &t.PortfolioID, &t.Symbol, &t.Exchange, &t.TxnType, &t.Quantity, &t.Price,
+			&t.Fees, &t.Amount, &tradedAt, &t.Notes, &t.Source, &t.ExternalID, &createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		t.TradedAt = tradedAt.Format(time.RFC3339)
+		t.CreatedAt = createdAt.Format(time.RFC3339)
+		txns = append(txns, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return txns, nil
+}
+
+// AddPortfolioTransaction records a ledger entry, rejecting sells that would
+// leave the running holding negative at any point from the trade date on.
+// The portfolio row is locked so concurrent sells are checked one at a time.
+func (db *DB) AddPortfolioTransaction(ctx context.Context, t PortfolioTransaction, tradedAt time.Time) (*PortfolioTransaction, error) {
+	if t.Exchange == "" {
+		t.Exchange = "NSE"
+	}
+	if t.TxnType == "DIVIDEND" && t.Amount == 0 {
+		t.Amount = t.Quantity * t.Price
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"SELECT id FROM portfolio.portfolios WHERE id = $1 FOR UPDATE", t.PortfolioID); err != nil {
+		return nil, fmt.Errorf("failed to lock portfolio: %w", err)
+	}
+
+	var created, traded time.Time
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO portfolio.transactions
+			(portfolio_id, symbol, exchange, txn_type, quantity, price, fees, amount, traded_at, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, source, traded_at, created_at
+	`, t.PortfolioID, t.Symbol, t.Exchange, t.TxnType, t.Quantity, t.Price, t.Fees, t.Amount, tradedAt, t.Notes,
+	).Scan(&t.ID, &t.Source, &traded, &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	if t.TxnType == "SELL" {
+		var lowest float64
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(MIN(held), 0)
+			FROM (
+				SELECT traded_at,
+					SUM(CASE txn_type WHEN 'BUY' THEN quantity WHEN 'SELL' THEN -quantity ELSE 0 END)
+						OVER (ORDER BY traded_at, id) AS held
+				FROM portfolio.transactions
+				WHERE portfolio_id = $1 AND symbol = $2 AND exchange = $3
+			) running
+			WHERE traded_at >= $4
+		`, t.PortfolioID, t.Symbol, t.Exchange, tradedAt).Scan(&lowest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check holding: %w", err)
+		}
+		if lowest < 0 {
+			return nil, ErrInsufficientQuantity
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	t.TradedAt = traded.Format(time.RFC3339)
+	t.CreatedAt = created.Format(time.RFC3339)
+	return &t, nil
+}
+
+// ImportedTrade is a broker-reported trade to be merged into a portfolio ledger
+type ImportedTrade struct {
+	ExternalID string
+	Symbol     string
+	Exchange   string
+	TxnType    string
+	Quantity   float64
+	Price      float64
+	TradedAt   time.Time
+}
+
+// ImportResult summarises a ledger import
+type ImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+}
+
+// ImportPortfolioTransactions inserts broker trades in one transaction, skipping
+// trades already imported from the same source (deduped on the broker trade ID).
+// Like AddPortfolioTransaction it locks the portfolio and rejects the whole
+// import if the merged ledger would leave any imported symbol's running
+// holding negative.
+func (db *DB) ImportPortfolioTransactions(ctx context.Context, portfolioID int64, source string, trades []ImportedTrade) (*ImportResult, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"SELECT id FROM portfolio.portfolios WHERE id = $1 FOR UPDATE", portfolioID); err != nil {
+		return nil, fmt.Errorf("failed to lock portfolio: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO portfolio.transactions
+			(portfolio_id, symbol, exchange, txn_type, quantity, price, traded_at, source, external_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (portfolio_id, source, external_id) WHERE external_id IS NOT NULL DO NOTHING
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare import: %w", err)
+	}
+	defer stmt.Close()
+
+	result := &ImportResult{}
+	symbols := map[string]bool{}
+	for _, t := range trades {
+		res, err := stmt.ExecContext(ctx, portfolioID, t.Symbol, t.Exchange, t.TxnType, t.Quantity, t.Price, t.TradedAt, source, t.ExternalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import trade %s: %w", t.ExternalID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			result.Imported++
+			symbols[t.Symbol] = true
+		} else {
+			result.Duplicates++
+		}
+	}
+
+	if len(symbols) > 0 {
+		symbolList := make([]string, 0, len(symbols))
+		for s := range symbols {
+			symbolList = append(symbolList, s)
+		}
+		var short string
+		err := tx.QueryRowContext(ctx, `
+			SELECT symbol
+			FROM (
+				SELECT symbol,
+					SUM(CASE txn_type WHEN 'BUY' THEN quantity WHEN 'SELL' THEN -quantity ELSE 0 END)
+						OVER (PARTITION BY symbol, exchange ORDER BY traded_at, id) AS held
+				FROM portfolio.transactions
+				WHERE portfolio_id = $1 AND symbol = ANY($2)
+			) running
+			WHERE held < 0
+			ORDER BY symbol
+			LIMIT 1
+		`, portfolioID, pq.Array(symbolList)).Scan(&short)
+		if err == nil {
+			return nil, fmt.Errorf("%w for %s", ErrInsufficientQuantity, short)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check holdings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return result, nil
+}
+
+// GetPortfolioHoldings computes holdings and P&L from the ledger using live prices
+func (db *DB) GetPortfolioHoldings(ctx context.Context, portfolioID int64) (*PortfolioSummary, error) {
+	txns, err := db.ListPortfolioTransactions(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := ComputeHoldings(txns)
+
+	symbols := make([]string, 0, len(holdings))
+	for _, hld := range holdings {
+		symbols = append(symbols, hld.Symbol)
+	}
+	prices, err := db.GetLatestPrices(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PortfolioSummary{
+		PortfolioID: portfolioID,
+		Holdings:    []Holding{},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	for _, hld := range holdings {
+		if price, ok := prices[hld.Symbol]; ok {
+			hld.LastPrice = price
+		} else {
+			// No live quote: value the position at cost so it doesn't show a fake loss
+			hld.LastPrice = hld.AvgCost
+		}
+		hld.MarketValue = hld.Quantity * hld.LastPrice
+		hld.UnrealizedPnL = hld.MarketValue - hld.InvestedValue
+		if hld.InvestedValue > 0 {
+			hld.UnrealizedPnLPct = hld.UnrealizedPnL / hld.InvestedValue * 100
+		}
+
+		summary.InvestedValue += hld.InvestedValue
+		summary.MarketValue += hld.MarketValue
+		summary.UnrealizedPnL += hld.UnrealizedPnL
+		summary.RealizedPnL += hld.RealizedPnL
+		summary.DividendIncome += hld.DividendIncome
+		summary.Holdings = append(summary.Holdings, hld)
+	}
+	summary.TotalPnL = summary.UnrealizedPnL + summary.RealizedPnL + summary.DividendIncome
+
+	return summary, nil
+}
+
+// ComputeHoldings folds a trade-ordered ledger into per-symbol holdings using
+// the average cost method. Fully exited positions are kept so their realized
+// P&L and dividends still count towards portfolio totals.
+func ComputeHoldings(txns []PortfolioTransaction) []Holding {
+	bySymbol := map[string]*Holding{}
+	order := []string{}
+
+	for _, t := range txns {
+		key := t.Symbol + ":" + t.Exchange
+		hld, ok := bySymbol[key]
+		if !ok {
+			hld = &Holding{Symbol: t.Symbol, Exchange: t.Exchange}
+			bySymbol[key] = hld
+			order = append(order, key)
+		}
+
+		switch t.TxnType {
+		case "BUY":
+			hld.InvestedValue += t.Quantity*t.Price + t.Fees
+			hld.Quantity += t.Quantity
+		case "SELL":
+			qty := t.Quantity
+			if qty > hld.Quantity {
+				qty = hld.Quantity
+			}
+			avgCost := 0.0
+			if hld.Quantity > 0 {
+				avgCost = hld.InvestedValue / hld.Quantity
+			}
+			hld.RealizedPnL += qty*(t.Price-avgCost) - t.Fees
+			hld.InvestedValue -= qty * avgCost
+			hld.Quantity -= qty
+		case "DIVIDEND":
+			hld.DividendIncome += t.Amount
+		}
+
+		if hld.Quantity > 0 {
+			hld.AvgCost = hld.InvestedValue / hld.Quantity
+		} else {
+			hld.Quantity = 0
+			hld.InvestedValue = 0
+			hld.AvgCost = 0
+		}
+	}
+
+	holdings := make([]Holding, 0, len(order))
+	for _, key := range order {
+		holdings = append(holdings, *bySymbol[key])
+	}
+	sort.SliceStable(holdings, func(i, j int) bool {
+		return holdings[i].Symbol < holdings[j].Symbol
+	})
+	return holdings
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const portfolioColumns = `id, account_id, user_id, name, description, base_currency, created_at, updated_at`
+
+func scanPortfolio(row rowScanner) (*Portfolio, error) {
+	var p Portfolio
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&p.ID, &p.AccountID, &p.UserID, &p.Name, &p.Description, &p.BaseCurrency, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan portfolio: %w", err)
+	}
+	p.CreatedAt = createdAt.Format(time.RFC3339)
+	p.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &p, nil
+}
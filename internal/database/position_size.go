@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SizingLookback is how much signal history position sizes are based on
+const SizingLookback = 180 * 24 * time.Hour
+
+// MinSizingTrades is the closed-signal count below which a symbol's own
+// history is considered too thin and the all-symbol history is used
+const MinSizingTrades = 10
+
+// SymbolTradeStats is the closed-signal record behind a position size,
+// with the dashboard's hit and miss rules. AvgWinPct and AvgLossPct are
+// mean absolute actual_profit_pct of hits and misses.
+type SymbolTradeStats struct {
+	// Symbol is empty when the stats cover every symbol
+	Symbol      string   `json:"symbol"`
+	Since       string   `json:"since"`
+	Trades      int      `json:"trades"`
+	Wins        int      `json:"wins"`
+	Losses      int      `json:"losses"`
+	WinRate     *float64 `json:"win_rate"`
+	AvgWinPct   *float64 `json:"avg_win_pct"`
+	AvgLossPct  *float64 `json:"avg_loss_pct"`
+	PayoffRatio *float64 `json:"payoff_ratio"`
+}
+
+// GetSymbolTradeStats summarises the outcomes of signals generated since
+// since for symbol, or for every symbol when symbol is empty
+func (db *DB) GetSymbolTradeStats(ctx context.Context, symbol string, since time.Time) (*SymbolTradeStats, error) {
+	stats := &SymbolTradeStats{Symbol: symbol, Since: since.Format(time.RFC3339)}
+	var avgWin, avgLoss sql.NullFloat64
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE result = 'HIT'),
+			COUNT(*) FILTER (WHERE result = 'MISS'),
+			(AVG(ABS(actual_profit_pct)) FILTER (WHERE result = 'HIT'))::float8,
+			(AVG(ABS(actual_profit_pct)) FILTER (WHERE result = 'MISS'))::float8
+		FROM intraday.signals
+		WHERE generated_at >= $1 AND ($2 = '' OR symbol = $2)
+	`, since, symbol).Scan(&stats.Wins, &stats.Losses, &avgWin, &avgLoss)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol trade stats: %w", err)
+	}
+
+	stats.Trades = stats.Wins + stats.Losses
+	if stats.Trades > 0 {
+		rate := round4(float64(stats.Wins) / float64(stats.Trades))
+		stats.WinRate = &rate
+	}
+	if avgWin.Valid {
+		v := round4(avgWin.Float64)
+		stats.AvgWinPct = &v
+	}
+	if avgLoss.Valid {
+		v := round4(avgLoss.Float64)
+		stats.AvgLossPct = &v
+	}
+	if avgWin.Valid && avgLoss.Valid && avgLoss.Float64 > 0 {
+		payoff := round4(avgWin.Float64 / avgLoss.Float64)
+		stats.PayoffRatio = &payoff
+	}
+	return stats, nil
+}
+
+// PositionSizeInput is what a position size is computed from. WinRate is
+// a fraction (0-1) and RiskFraction the share of capital risked by the
+// fixed-fractional method. StopDistancePct is how far the stop sits from
+// entry; EntryPrice is optional and only used to turn notional into shares.
+type PositionSizeInput struct {
+	WinRate         float64  `json:"win_rate"`
+	PayoffRatio     float64  `json:"payoff_ratio"`
+	Capital         float64  `json:"capital"`
+	RiskFraction    float64  `json:"risk_fraction"`
+	StopDistancePct float64  `json:"stop_distance_pct"`
+	EntryPrice      *float64 `json:"entry_price"`
+}
+
+// SizeSuggestion is one sizing method's result. Fraction is the share of
+// capital put at risk; Notional is the position that loses exactly that
+// much at the stop, capped at Capital since positions are unlevered.
+type SizeSuggestion struct {
+	Fraction      float64 `json:"fraction"`
+	CapitalAtRisk float64 `json:"capital_at_risk"`
+	Notional      float64 `json:"notional"`
+	Quantity      *int64  `json:"quantity"`
+	Capped        bool    `json:"capped"`
+}
+
+// PositionSize holds the Kelly, half-Kelly and fixed-fractional sizes for
+// one set of inputs
+type PositionSize struct {
+	Inputs PositionSizeInput `json:"inputs"`
+	// Stats is the history the win rate and payoff came from, if any
+	Stats           *SymbolTradeStats `json:"stats,omitempty"`
+	Kelly           SizeSuggestion    `json:"kelly"`
+	HalfKelly       SizeSuggestion    `json:"half_kelly"`
+	FixedFractional SizeSuggestion    `json:"fixed_fractional"`
+	// Edge is false when the Kelly fraction is not positive, i.e. the
+	// history says not to take the trade
+	Edge  bool     `json:"edge"`
+	Notes []string `json:"notes"`
+}
+
+// ComputePositionSize applies the Kelly criterion f = p - (1-p)/b, clamped
+// to [0, 1], alongside half-Kelly and a fixed risk fraction
+func ComputePositionSize(in PositionSizeInput) *PositionSize {
+	kelly := 0.0
+	if in.PayoffRatio > 0 {
+		kelly = in.WinRate - (1-in.WinRate)/in.PayoffRatio
+	}
+	kelly = math.Max(0, math.Min(1, kelly))
+
+	size := &PositionSize{
+		Inputs:          in,
+		Kelly:           sizeFor(kelly, in),
+		HalfKelly:       sizeFor(kelly/2, in),
+		FixedFractional: sizeFor(in.RiskFraction, in),
+		Edge:            kelly > 0,
+		Notes:           []string{},
+	}
+	if !size.Edge {
+		size.Notes = append(size.Notes, "No positive edge: Kelly recommends not taking the trade")
+	}
+	if size.Kelly.Capped {
+		size.Notes = append(size.Notes, "Kelly size exceeds capital at this stop distance and was capped")
+	}
+	return size
+}
+
+func sizeFor(fraction float64, in PositionSizeInput) SizeSuggestion {
+	s := SizeSuggestion{
+		Fraction:      round4(fraction),
+		CapitalAtRisk: round2(fraction * in.Capital),
+	}
+	if in.StopDistancePct > 0 {
+		s.Notional = s.CapitalAtRisk / (in.StopDistancePct / 100)
+	}
+	if s.Notional > in.Capital {
+		s.Notional = in.Capital
+		s.Capped = true
+	}
+	s.Notional = round2(s.Notional)
+	if in.EntryPrice != nil && *in.EntryPrice > 0 {
+		qty := int64(math.Floor(s.Notional / *in.EntryPrice))
+		s.Quantity = &qty
+	}
+	return s
+}
+
+// GetSignalPositionSize sizes a signal from its symbol's closed signals
+// over SizingLookback, falling back to every symbol's when the symbol has
+// fewer than MinSizingTrades. The stop distance is the signal's own.
+func (db *DB) GetSignalPositionSize(ctx context.Context, s *Signal, capital, riskFraction float64) (*PositionSize, error) {
+	since := time.Now().Add(-SizingLookback)
+	stats, err := db.GetSymbolTradeStats(ctx, s.Symbol, since)
+	if err != nil {
+		return nil, err
+	}
+	var notes []string
+	if stats.Trades < MinSizingTrades || stats.PayoffRatio == nil {
+		notes = append(notes, fmt.Sprintf("%s has %d closed signals; using all symbols' history", s.Symbol, stats.Trades))
+		if stats, err = db.GetSymbolTradeStats(ctx, "", since); err != nil {
+			return nil, err
+		}
+	}
+
+	in := PositionSizeInput{Capital: capital, RiskFraction: riskFraction}
+	if stats.WinRate != nil {
+		in.WinRate = *stats.WinRate
+	}
+	if stats.PayoffRatio != nil {
+		in.PayoffRatio = *stats.PayoffRatio
+	}
+	if s.EntryPrice > 0 {
+		entry := s.EntryPrice
+		in.EntryPrice = &entry
+		if s.StopLoss > 0 {
+			in.StopDistancePct = round4(math.Abs(s.EntryPrice-s.StopLoss) / s.EntryPrice * 100)
+		}
+	}
+
+	size := ComputePositionSize(in)
+	size.Stats = stats
+	if in.StopDistancePct == 0 {
+		notes = append(notes, "Signal has no stop loss; notional cannot be sized")
+	}
+	size.Notes = append(notes, size.Notes...)
+	return size, nil
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrPresetNameTaken is returned when an account already has a preset by
+// that name for the same scope
+var ErrPresetNameTaken = errors.New("preset name already in use")
+
+// Preset is a named set of query filters for one list endpoint. Scope is
+// the endpoint's path under /api ("signals", "news", "scans/gaps", ...) and
+// Filters its query parameters. Presets are shared within an account;
+// UserID is who saved it.
+type Preset struct {
+	ID        int64             `json:"id"`
+	AccountID string            `json:"account_id"`
+	UserID    string            `json:"user_id"`
+	Name      string            `json:"name"`
+	Scope     string            `json:"scope"`
+	Filters   map[string]string `json:"filters"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+const presetColumns = `id, account_id, user_id, name, scope, filters, created_at, updated_at`
+
+func scanPreset(row rowScanner) (*Preset, error) {
+	var p Preset
+	var filters []byte
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&p.ID, &p.AccountID, &p.UserID, &p.Name, &p.Scope, &filters, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &p.Filters); err != nil {
+		return nil, fmt.Errorf("failed to decode preset filters: %w", err)
+	}
+	if p.Filters == nil {
+		p.Filters = map[string]string{}
+	}
+	p.CreatedAt = createdAt.Format(time.RFC3339)
+	p.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &p, nil
+}
+
+// presetWriteError maps a unique violation on (account_id, scope, name) to
+// ErrPresetNameTaken
+func presetWriteError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrPresetNameTaken
+	}
+	return fmt.Errorf("failed to %s preset: %w", action, err)
+}
+
+// ListPresets returns an account's presets, optionally for one scope
+func (db *DB) ListPresets(ctx context.Context, accountID, scope string) ([]Preset, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+presetColumns+`
+		FROM core_api.presets
+		WHERE account_id = $1 AND ($2 = '' OR scope = $2)
+		ORDER BY scope, name
+	`, accountID, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []Preset{}
+	for rows.Next() {
+		p, err := scanPreset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan preset: %w", err)
+		}
+		presets = append(presets, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return presets, nil
+}
+
+// GetPreset returns an account's preset by ID, or nil
+func (db *DB) GetPreset(ctx context.Context, accountID string, id int64) (*Preset, error) {
+	p, err := scanPreset(db.conn.QueryRowContext(ctx, `
+		SELECT `+presetColumns+`
+		FROM core_api.presets
+		WHERE id = $1 AND account_id = $2
+	`, id, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset: %w", err)
+	}
+	return p, nil
+}
+
+// CreatePreset stores a preset with p's account, user, name, scope and filters
+func (db *DB) CreatePreset(ctx context.Context, p Preset) (*Preset, error) {
+	filters, err := json.Marshal(p.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preset filters: %w", err)
+	}
+	created, err := scanPreset(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.presets (account_id, user_id, name, scope, filters)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+presetColumns,
+		p.AccountID, p.UserID, p.Name, p.Scope, filters))
+	if err != nil {
+		return nil, presetWriteError("create", err)
+	}
+	return created, nil
+}
+
+// UpdatePreset replaces the name and filters of p.AccountID's preset,
+// returning nil if it doesn't exist. The scope and author are kept.
+func (db *DB) UpdatePreset(ctx context.Context, p Preset) (*Preset, error) {
+	filters, err := json.Marshal(p.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preset filters: %w", err)
+	}
+	updated, err := scanPreset(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.presets
+		SET name = $3, filters = $4, updated_at = NOW()
+		WHERE id = $1 AND account_id = $2
+		RETURNING `+presetColumns,
+		p.ID, p.AccountID, p.Name, filters))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, presetWriteError("update", err)
+	}
+	return updated, nil
+}
+
+// DeletePreset removes an account's preset
+func (db *DB) DeletePreset(ctx context.Context, accountID string, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM core_api.presets WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete preset: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrNoBhavcopy is returned when a day's bhavcopy hasn't been loaded
+var ErrNoBhavcopy = errors.New("no bhavcopy for date")
+
+// Price reconciliation item kinds
+const (
+	ReconMismatch        = "mismatch"         // closes differ by more than the tolerance
+	ReconStaleRealtime   = "stale_realtime"   // realtime price not updated on the day
+	ReconMissingBhavcopy = "missing_bhavcopy" // realtime price with no bhavcopy close
+)
+
+// PriceDiscrepancy is a symbol whose realtime close didn't reconcile with
+// the bhavcopy
+type PriceDiscrepancy struct {
+	Symbol        string     `json:"symbol"`
+	Kind          string     `json:"kind"`
+	RealtimePrice *float64   `json:"realtime_price"`
+	RealtimeAt    *time.Time `json:"realtime_at"`
+	BhavcopyClose *float64   `json:"bhavcopy_close"`
+	DiffPct       *float64   `json:"diff_pct"`
+}
+
+// PriceReconciliation compares md.realtime_prices with the official
+// bhavcopy closes for a trading day
+type PriceReconciliation struct {
+	TradeDate       string             `json:"trade_date"`
+	TolerancePct    float64            `json:"tolerance_pct"`
+	Compared        int                `json:"compared"`
+	Matched         int                `json:"matched"`
+	Discrepancies   int                `json:"discrepancies"`
+	StaleRealtime   int                `json:"stale_realtime"`
+	MissingBhavcopy int                `json:"missing_bhavcopy"`
+	RunAt           time.Time          `json:"run_at"`
+	Items           []PriceDiscrepancy `json:"items"`
+}
+
+// ReconcilePrices compares every symbol in md.realtime_prices with date's
+// EQ bhavcopy close and stores the result, replacing an earlier run for the
+// date. Realtime prices only hold the latest value, so this must run after
+// the close and before the next session opens.
+func (db *DB) ReconcilePrices(ctx context.Context, date time.Time, tolerancePct float64) (*PriceReconciliation, error) {
+	day := date.Format("2006-01-02")
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var loaded bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM md.bhavcopy WHERE trade_date = $1::date AND series = 'EQ')
+	`, day).Scan(&loaded); err != nil {
+		return nil, fmt.Errorf("failed to check bhavcopy: %w", err)
+	}
+	if !loaded {
+		return nil, ErrNoBhavcopy
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT r.symbol, r.last_price, r.updated_at,
+			(r.updated_at AT TIME ZONE 'Asia/Kolkata')::date = $1::date, b.close
+		FROM md.realtime_prices r
+		LEFT JOIN md.bhavcopy b ON b.symbol = r.symbol AND b.trade_date = $1::date AND b.series = 'EQ'
+		ORDER BY r.symbol
+	`, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prices to reconcile: %w", err)
+	}
+	defer rows.Close()
+
+	recon := &PriceReconciliation{TradeDate: day, TolerancePct: tolerancePct, Items: []PriceDiscrepancy{}}
+	for rows.Next() {
+		var d PriceDiscrepancy
+		var lastPrice, bhavClose sql.NullFloat64
+		var updatedAt time.Time
+		var fresh bool
+		if err := rows.Scan(&d.Symbol, &lastPrice, &updatedAt, &fresh, &bhavClose); err != nil {
+			return nil, fmt.Errorf("failed to scan price to reconcile: %w", err)
+		}
+		d.RealtimeAt = &updatedAt
+		if lastPrice.Valid {
+			d.RealtimePrice = &lastPrice.Float64
+		}
+		if bhavClose.Valid {
+			d.BhavcopyClose = &bhavClose.Float64
+		}
+
+		switch {
+		case !fresh || !lastPrice.Valid:
+			d.Kind = ReconStaleRealtime
+			recon.StaleRealtime++
+		case !bhavClose.Valid:
+			d.Kind = ReconMissingBhavcopy
+			recon.MissingBhavcopy++
+		default:
+			recon.Compared++
+			diff := math.Round((lastPrice.Float64-bhavClose.Float64)/bhavClose.Float64*10000) / 100
+			if math.Abs(diff) <= tolerancePct {
+				recon.Matched++
+				continue
+			}
+			d.Kind = ReconMismatch
+			d.DiffPct = &diff
+			recon.Discrepancies++
+		}
+		recon.Items = append(recon.Items, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM core_api.price_reconciliations WHERE trade_date = $1::date`, day); err != nil {
+		return nil, fmt.Errorf("failed to clear price reconciliation: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO core_api.price_reconciliations (trade_date, tolerance_pct, compared, matched,
+			discrepancies, stale_realtime, missing_bhavcopy)
+		VALUES ($1::date, $2, $3, $4, $5, $6, $7)
+		RETURNING run_at
+	`, day, tolerancePct, recon.Compared, recon.Matched, recon.Discrepancies, recon.StaleRealtime,
+		recon.MissingBhavcopy).Scan(&recon.RunAt); err != nil {
+		return nil, fmt.Errorf("failed to insert price reconciliation: %w", err)
+	}
+	for _, d := range recon.Items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.price_reconciliation_items (trade_date, symbol, kind, realtime_price,
+				realtime_at, bhavcopy_close, diff_pct)
+			VALUES ($1::date, $2, $3, $4, $5, $6, $7)
+		`, day, d.Symbol, d.Kind, d.RealtimePrice, d.RealtimeAt, d.BhavcopyClose, d.DiffPct); err != nil {
+			return nil, fmt.Errorf("failed to insert price discrepancy: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit price reconciliation: %w", err)
+	}
+	return recon, nil
+}
+
+// HasPriceReconciliation reports whether date has been reconciled
+func (db *DB) HasPriceReconciliation(ctx context.Context, date time.Time) (bool, error) {
+	var exists bool
+	if err := db.conn.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM core_api.price_reconciliations WHERE trade_date = $1::date)
+	`, date.Format("2006-01-02")).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check price reconciliation: %w", err)
+	}
+	return exists, nil
+}
+
+// GetPriceReconciliation returns date's reconciliation, optionally only
+// items of one kind, or nil when it hasn't run. Mismatches come first,
+// largest difference first.
+func (db *DB) GetPriceReconciliation(ctx context.Context, date time.Time, kind string) (*PriceReconciliation, error) {
+	recon := &PriceReconciliation{Items: []PriceDiscrepancy{}}
+	var tradeDate time.Time
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT trade_date, tolerance_pct, compared, matched, discrepancies, stale_realtime,
+			missing_bhavcopy, run_at
+		FROM core_api.price_reconciliations
+		WHERE trade_date = $1::date
+	`, date.Format("2006-01-02")).Scan(&tradeDate, &recon.TolerancePct, &recon.Compared, &recon.Matched,
+		&recon.Discrepancies, &recon.StaleRealtime, &recon.MissingBhavcopy, &recon.RunAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price reconciliation: %w", err)
+	}
+	recon.TradeDate = tradeDate.Format("2006-01-02")
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, kind, realtime_price, realtime_at, bhavcopy_close, diff_pct
+		FROM core_api.price_reconciliation_items
+		WHERE trade_date = $1 AND ($2 = '' OR kind = $2)
+		ORDER BY kind = 'mismatch' DESC, ABS(diff_pct) DESC NULLS LAST, symbol
+	`, tradeDate, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price discrepancies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d PriceDiscrepancy
+		var realtimePrice, bhavcopyClose, diffPct sql.NullFloat64
+		var realtimeAt sql.NullTime
+		if err := rows.Scan(&d.Symbol, &d.Kind, &realtimePrice, &realtimeAt, &bhavcopyClose, &diffPct); err != nil {
+			return nil, fmt.Errorf("failed to scan price discrepancy: %w", err)
+		}
+		if realtimePrice.Valid {
+			d.RealtimePrice = &realtimePrice.Float64
+		}
+		if realtimeAt.Valid {
+			d.RealtimeAt = &realtimeAt.Time
+		}
+		if bhavcopyClose.Valid {
+			d.BhavcopyClose = &bhavcopyClose.Float64
+		}
+		if diffPct.Valid {
+			d.DiffPct = &diffPct.Float64
+		}
+		recon.Items = append(recon.Items, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return recon, nil
+}
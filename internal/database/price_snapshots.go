@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PriceSnapshot is the latest tick-derived price of a symbol
+type PriceSnapshot struct {
+	Symbol        string
+	LastPrice     float64
+	Volume        int64
+	ChangePercent float64
+	UpdatedAt     time.Time
+}
+
+// UpsertPriceSnapshots writes snapshots in one statement, keeping the newer
+// row when one already exists
+func (db *DB) UpsertPriceSnapshots(ctx context.Context, snapshots []PriceSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	symbols := make([]string, len(snapshots))
+	prices := make([]float64, len(snapshots))
+	volumes := make([]int64, len(snapshots))
+	changes := make([]float64, len(snapshots))
+	times := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		symbols[i], prices[i], volumes[i], changes[i], times[i] = s.Symbol, s.LastPrice, s.Volume, s.ChangePercent, s.UpdatedAt.Format(time.RFC3339Nano)
+	}
+
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.price_snapshots (symbol, last_price, volume, change_percent, updated_at)
+		SELECT * FROM unnest($1::text[], $2::float8[], $3::bigint[], $4::float8[], $5::timestamptz[])
+		ON CONFLICT (symbol) DO UPDATE SET
+			last_price = EXCLUDED.last_price,
+			volume = EXCLUDED.volume,
+			change_percent = EXCLUDED.change_percent,
+			updated_at = EXCLUDED.updated_at
+		WHERE core_api.price_snapshots.updated_at < EXCLUDED.updated_at
+	`, pq.Array(symbols), pq.Array(prices), pq.Array(volumes), pq.Array(changes), pq.Array(times))
+	if err != nil {
+		return fmt.Errorf("failed to upsert price snapshots: %w", err)
+	}
+	return nil
+}
+
+// GetPriceSnapshots returns snapshots updated within maxAge
+func (db *DB) GetPriceSnapshots(ctx context.Context, maxAge time.Duration) ([]PriceSnapshot, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, last_price, COALESCE(volume, 0), COALESCE(change_percent, 0), updated_at
+		FROM core_api.price_snapshots
+		WHERE updated_at > $1
+	`, time.Now().Add(-maxAge))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []PriceSnapshot
+	for rows.Next() {
+		var s PriceSnapshot
+		if err := rows.Scan(&s.Symbol, &s.LastPrice, &s.Volume, &s.ChangePercent, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price snapshot: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MaxPriceBatch caps the updates in one UpsertRealtimePrices call
+const MaxPriceBatch = 5000
+
+// maxPriceClockSkew is how far in the future an update's time may be
+const maxPriceClockSkew = time.Minute
+
+// PriceUpdate is one quote pushed by a collector. Optional fields left nil
+// keep the stored value; ChangePercent is derived from Close when missing.
+type PriceUpdate struct {
+	Symbol          string
+	Exchange        string
+	InstrumentToken *int64
+	LastPrice       float64
+	Volume          *int64
+	Open            *float64
+	High            *float64
+	Low             *float64
+	Close           *float64
+	ChangePercent   *float64
+	UpdatedAt       time.Time
+}
+
+// Validate normalises the update. A zero UpdatedAt becomes now and a
+// missing exchange NSE.
+func (u *PriceUpdate) Validate(now time.Time) error {
+	u.Symbol = strings.ToUpper(strings.TrimSpace(u.Symbol))
+	u.Exchange = strings.ToUpper(strings.TrimSpace(u.Exchange))
+	if u.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if u.Exchange == "" {
+		u.Exchange = "NSE"
+	}
+	if u.LastPrice <= 0 {
+		return errors.New("last_price must be positive")
+	}
+	for _, v := range []*float64{u.Open, u.High, u.Low, u.Close} {
+		if v != nil && *v <= 0 {
+			return errors.New("open, high, low and close must be positive")
+		}
+	}
+	if u.Volume != nil && *u.Volume < 0 {
+		return errors.New("volume cannot be negative")
+	}
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = now
+	} else if u.UpdatedAt.After(now.Add(maxPriceClockSkew)) {
+		return errors.New("timestamp is in the future")
+	}
+	return nil
+}
+
+// PriceUpsertResult counts what a batch did. Stale updates were older than
+// the stored price, and Duplicates were superseded by a later update to the
+// same symbol in the batch.
+type PriceUpsertResult struct {
+	Written    int `json:"written"`
+	Stale      int `json:"stale"`
+	Duplicates int `json:"duplicates"`
+}
+
+func nullInt(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+// UpsertRealtimePrices writes validated updates to md.realtime_prices in a
+// single statement. Only the latest update per symbol is kept, and none
+// overwrites a newer stored price.
+func (db *DB) UpsertRealtimePrices(ctx context.Context, updates []PriceUpdate) (*PriceUpsertResult, error) {
+	result := &PriceUpsertResult{}
+	latest := map[string]int{}
+	order := []string{}
+	for i, u := range updates {
+		j, seen := latest[u.Symbol]
+		if !seen {
+			order = append(order, u.Symbol)
+		} else {
+			result.Duplicates++
+			if u.UpdatedAt.Before(updates[j].UpdatedAt) {
+				continue
+			}
+		}
+		latest[u.Symbol] = i
+	}
+	if len(order) == 0 {
+		return result, nil
+	}
+
+	n := len(order)
+	symbols, exchanges := make([]string, n), make([]string, n)
+	tokens, volumes := make([]sql.NullInt64, n), make([]sql.NullInt64, n)
+	last := make([]float64, n)
+	opens, highs, lows, closes, changes := make([]sql.NullFloat64, n), make([]sql.NullFloat64, n),
+		make([]sql.NullFloat64, n), make([]sql.NullFloat64, n), make([]sql.NullFloat64, n)
+	times := make([]time.Time, n)
+	for k, symbol := range order {
+		u := updates[latest[symbol]]
+		symbols[k], exchanges[k], last[k], times[k] = u.Symbol, u.Exchange, u.LastPrice, u.UpdatedAt
+		tokens[k], volumes[k] = nullInt(u.InstrumentToken), nullInt(u.Volume)
+		opens[k], highs[k], lows[k], closes[k] = nullFloat(u.Open), nullFloat(u.High), nullFloat(u.Low), nullFloat(u.Close)
+		changes[k] = nullFloat(u.ChangePercent)
+	}
+
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO md.realtime_prices AS rp (symbol, exchange, instrument_token, last_price, volume,
+			open, high, low, close, change_percent, updated_at)
+		SELECT u.symbol, u.exchange, u.instrument_token, u.last_price, u.volume, u.open, u.high, u.low, u.close,
+			COALESCE(u.change_percent, (u.last_price - u.close) / NULLIF(u.close, 0) * 100), u.updated_at
+		FROM unnest($1::text[], $2::text[], $3::bigint[], $4::float8[], $5::bigint[],
+			$6::float8[], $7::float8[], $8::float8[], $9::float8[], $10::float8[], $11::timestamptz[])
+			AS u(symbol, exchange, instrument_token, last_price, volume, open, high, low, close, change_percent, updated_at)
+		ON CONFLICT (symbol) DO UPDATE SET
+			exchange = EXCLUDED.exchange,
+			instrument_token = COALESCE(EXCLUDED.instrument_token, rp.instrument_token),
+			last_price = EXCLUDED.last_price,
+			volume = COALESCE(EXCLUDED.volume, rp.volume),
+			open = COALESCE(EXCLUDED.open, rp.open),
+			high = COALESCE(EXCLUDED.high, rp.high),
+			low = COALESCE(EXCLUDED.low, rp.low),
+			close = COALESCE(EXCLUDED.close, rp.close),
+			change_percent = COALESCE(EXCLUDED.change_percent,
+				(EXCLUDED.last_price - COALESCE(EXCLUDED.close, rp.close)) / NULLIF(COALESCE(EXCLUDED.close, rp.close), 0) * 100,
+				rp.change_percent),
+			updated_at = EXCLUDED.updated_at
+		WHERE rp.updated_at <= EXCLUDED.updated_at
+	`, pq.Array(symbols), pq.Array(exchanges), pq.Array(tokens), pq.Array(last), pq.Array(volumes),
+		pq.Array(opens), pq.Array(highs), pq.Array(lows), pq.Array(closes), pq.Array(changes), pq.Array(times))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert realtime prices: %w", err)
+	}
+	written, _ := res.RowsAffected()
+	result.Written = int(written)
+	result.Stale = n - result.Written
+	return result, nil
+}
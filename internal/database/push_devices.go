@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PushDevice is a mobile app install registered for push notifications.
+// Kinds limits which notification kinds it receives, all when empty;
+// CriticalOnly narrows that to critical ones. Quiet hours are HH:MM IST and
+// drop non-critical pushes rather than holding them, since the app shows
+// everything missed when it's next opened.
+type PushDevice struct {
+	ID        int64  `json:"id"`
+	AccountID string `json:"account_id"`
+	UserID    string `json:"user_id"`
+	Platform  string `json:"platform"`
+	// Token is the FCM registration token or APNs device token. It's never
+	// returned in full.
+	Token        string   `json:"-"`
+	TokenSuffix  string   `json:"token_suffix"`
+	Name         string   `json:"name"`
+	Enabled      bool     `json:"enabled"`
+	Kinds        []string `json:"kinds"`
+	CriticalOnly bool     `json:"critical_only"`
+	QuietStart   *string  `json:"quiet_start"`
+	QuietEnd     *string  `json:"quiet_end"`
+	LastError    *string  `json:"last_error"`
+	LastSentAt   *string  `json:"last_sent_at"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+// pushTokenSuffixLen is how much of a token is shown to identify a device
+const pushTokenSuffixLen = 8
+
+const pushDeviceColumns = `id, account_id, user_id, platform, token, name, enabled, kinds, critical_only,
+	quiet_start, quiet_end, last_error, last_sent_at, created_at, updated_at`
+
+func scanPushDevice(row rowScanner) (*PushDevice, error) {
+	var d PushDevice
+	var lastSent sql.NullTime
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&d.ID, &d.AccountID, &d.UserID, &d.Platform, &d.Token, &d.Name, &d.Enabled, pq.Array(&d.Kinds),
+		&d.CriticalOnly, &d.QuietStart, &d.QuietEnd, &d.LastError, &lastSent, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if d.Kinds == nil {
+		d.Kinds = []string{}
+	}
+	d.TokenSuffix = d.Token
+	if len(d.Token) > pushTokenSuffixLen {
+		d.TokenSuffix = d.Token[len(d.Token)-pushTokenSuffixLen:]
+	}
+	if lastSent.Valid {
+		formatted := lastSent.Time.Format(time.RFC3339)
+		d.LastSentAt = &formatted
+	}
+	d.CreatedAt = createdAt.Format(time.RFC3339)
+	d.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &d, nil
+}
+
+func (db *DB) queryPushDevices(ctx context.Context, query string, args ...interface{}) ([]PushDevice, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query push devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []PushDevice{}
+	for rows.Next() {
+		d, err := scanPushDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan push device: %w", err)
+		}
+		devices = append(devices, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return devices, nil
+}
+
+// ListPushDevices returns the devices a member registered in an account
+func (db *DB) ListPushDevices(ctx context.Context, accountID, userID string) ([]PushDevice, error) {
+	return db.queryPushDevices(ctx, `
+		SELECT `+pushDeviceColumns+`
+		FROM core_api.push_devices
+		WHERE account_id = $1 AND user_id = $2
+		ORDER BY created_at, id
+	`, accountID, userID)
+}
+
+// ListEnabledPushDevices returns every device with push enabled
+func (db *DB) ListEnabledPushDevices(ctx context.Context) ([]PushDevice, error) {
+	return db.queryPushDevices(ctx, `
+		SELECT `+pushDeviceColumns+`
+		FROM core_api.push_devices
+		WHERE enabled
+		ORDER BY user_id, id
+	`)
+}
+
+// RegisterPushDevice stores a device, or takes over an existing
+// registration of the same token, e.g. after the app signs in as another
+// user or switches account. Re-registering enables the device again.
+func (db *DB) RegisterPushDevice(ctx context.Context, d PushDevice) (*PushDevice, error) {
+	saved, err := scanPushDevice(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.push_devices
+			(account_id, user_id, platform, token, name, kinds, critical_only, quiet_start, quiet_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (token) DO UPDATE SET
+			account_id = EXCLUDED.account_id, user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, name = EXCLUDED.name,
+			enabled = TRUE, kinds = EXCLUDED.kinds, critical_only = EXCLUDED.critical_only,
+			quiet_start = EXCLUDED.quiet_start, quiet_end = EXCLUDED.quiet_end,
+			last_error = NULL, updated_at = NOW()
+		RETURNING `+pushDeviceColumns,
+		d.AccountID, d.UserID, d.Platform, d.Token, d.Name, pq.Array(d.Kinds), d.CriticalOnly, d.QuietStart, d.QuietEnd))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register push device: %w", err)
+	}
+	return saved, nil
+}
+
+// UpdatePushDevice replaces a device's preferences, returning nil if the
+// member has no such device in the account
+func (db *DB) UpdatePushDevice(ctx context.Context, d PushDevice) (*PushDevice, error) {
+	saved, err := scanPushDevice(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.push_devices
+		SET name = $4, enabled = $5, kinds = $6, critical_only = $7,
+		    quiet_start = $8, quiet_end = $9, updated_at = NOW()
+		WHERE id = $1 AND account_id = $2 AND user_id = $3
+		RETURNING `+pushDeviceColumns,
+		d.ID, d.AccountID, d.UserID, d.Name, d.Enabled, pq.Array(d.Kinds), d.CriticalOnly, d.QuietStart, d.QuietEnd))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update push device: %w", err)
+	}
+	return saved, nil
+}
+
+// DeletePushDevice removes one of the devices a member registered in an
+// account
+func (db *DB) DeletePushDevice(ctx context.Context, accountID, userID string, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.push_devices WHERE id = $1 AND account_id = $2 AND user_id = $3", id, accountID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete push device: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordPushResult notes the outcome of a push to a device. disable turns
+// push off for devices whose token the provider no longer accepts.
+func (db *DB) RecordPushResult(ctx context.Context, id int64, sendErr error, disable bool) error {
+	var err error
+	if sendErr == nil {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.push_devices SET last_sent_at = NOW(), last_error = NULL WHERE id = $1
+		`, id)
+	} else {
+		_, err = db.conn.ExecContext(ctx, `
+			UPDATE core_api.push_devices SET last_error = $2, enabled = enabled AND NOT $3 WHERE id = $1
+		`, id, sendErr.Error(), disable)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record push result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/dbstats"
+)
+
+// statsConn records queries into the dbstats.Stats their context carries.
+// Without one it passes straight through, so outside dev it costs a context
+// lookup per query. It implements exactly the optional interfaces lib/pq's
+// connection does, so database/sql treats it the same. Statements prepared
+// explicitly are counted once, when prepared, and also in prepares.
+type statsConn struct {
+	conn     driver.Conn
+	prepares *prepareCounter
+}
+
+// pqConn is the set of interfaces lib/pq's connection implements
+type pqConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.Pinger
+	driver.NamedValueChecker
+	driver.SessionResetter
+	driver.Validator
+}
+
+// pqRows is the set of interfaces lib/pq's rows implement
+type pqRows interface {
+	driver.RowsNextResultSet
+	driver.RowsColumnTypeScanType
+	driver.RowsColumnTypeDatabaseTypeName
+	driver.RowsColumnTypeLength
+	driver.RowsColumnTypePrecisionScale
+}
+
+// withQueryStats wraps conn in a statsConn, or returns it as is if it isn't
+// shaped like a lib/pq connection
+func withQueryStats(conn driver.Conn, prepares *prepareCounter) driver.Conn {
+	if _, ok := conn.(pqConn); !ok {
+		return conn
+	}
+	return &statsConn{conn: conn, prepares: prepares}
+}
+
+func (c *statsConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *statsConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *statsConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *statsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *statsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stats := dbstats.FromContext(ctx)
+	start := time.Now()
+	stmt, err := c.conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	if err == nil && c.prepares != nil {
+		c.prepares.add(query)
+	}
+	if stats != nil {
+		stats.Query(query, time.Since(start))
+	}
+	return stmt, err
+}
+
+func (c *statsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	stats := dbstats.FromContext(ctx)
+	start := time.Now()
+	rows, err := c.conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	if stats == nil {
+		return rows, err
+	}
+	stats.Query(query, time.Since(start))
+	if _, ok := rows.(pqRows); err != nil || !ok {
+		return rows, err
+	}
+	return &statsRows{Rows: rows, stats: stats}, nil
+}
+
+func (c *statsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	stats := dbstats.FromContext(ctx)
+	start := time.Now()
+	res, err := c.conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	if stats != nil {
+		stats.Query(query, time.Since(start))
+	}
+	return res, err
+}
+
+func (c *statsConn) Ping(ctx context.Context) error {
+	return c.conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *statsConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return c.conn.(driver.NamedValueChecker).CheckNamedValue(nv)
+}
+
+func (c *statsConn) ResetSession(ctx context.Context) error {
+	return c.conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *statsConn) IsValid() bool {
+	return c.conn.(driver.Validator).IsValid()
+}
+
+// statsRows counts the rows read from a result and records them on Close
+type statsRows struct {
+	driver.Rows
+	stats   *dbstats.Stats
+	rows    int64
+	elapsed time.Duration
+	closed  bool
+}
+
+func (r *statsRows) Next(dest []driver.Value) error {
+	start := time.Now()
+	err := r.Rows.Next(dest)
+	r.elapsed += time.Since(start)
+	if err == nil {
+		r.rows++
+	}
+	return err
+}
+
+func (r *statsRows) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.stats.Rows(r.rows, len(r.Rows.Columns()), r.elapsed)
+	}
+	return r.Rows.Close()
+}
+
+func (r *statsRows) HasNextResultSet() bool {
+	return r.Rows.(driver.RowsNextResultSet).HasNextResultSet()
+}
+
+func (r *statsRows) NextResultSet() error {
+	return r.Rows.(driver.RowsNextResultSet).NextResultSet()
+}
+
+func (r *statsRows) ColumnTypeScanType(index int) reflect.Type {
+	return r.Rows.(driver.RowsColumnTypeScanType).ColumnTypeScanType(index)
+}
+
+func (r *statsRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.Rows.(driver.RowsColumnTypeDatabaseTypeName).ColumnTypeDatabaseTypeName(index)
+}
+
+func (r *statsRows) ColumnTypeLength(index int) (int64, bool) {
+	return r.Rows.(driver.RowsColumnTypeLength).ColumnTypeLength(index)
+}
+
+func (r *statsRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	return r.Rows.(driver.RowsColumnTypePrecisionScale).ColumnTypePrecisionScale(index)
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rebalance target modes
+const (
+	RebalanceBySymbol = "symbol"
+	RebalanceBySector = "sector"
+)
+
+// RebalanceRequest describes the desired allocation for a portfolio.
+// Targets are percentages of total value keyed by symbol or sector; any
+// unallocated remainder is left in cash.
+type RebalanceRequest struct {
+	Mode           string             `json:"mode"`
+	Targets        map[string]float64 `json:"targets"`
+	AdditionalCash float64            `json:"additional_cash"`
+	MinTradeValue  float64            `json:"min_trade_value"`
+}
+
+// RebalanceTrade is a suggested order for one symbol
+type RebalanceTrade struct {
+	Symbol          string  `json:"symbol"`
+	Sector          string  `json:"sector"`
+	Action          string  `json:"action"`
+	Quantity        float64 `json:"quantity"`
+	LotSize         float64 `json:"lot_size"`
+	Price           float64 `json:"price"`
+	TradeValue      float64 `json:"trade_value"`
+	CurrentQuantity float64 `json:"current_quantity"`
+	TargetQuantity  float64 `json:"target_quantity"`
+	CurrentWeight   float64 `json:"current_weight_pct"`
+	TargetWeight    float64 `json:"target_weight_pct"`
+	PostTradeWeight float64 `json:"post_trade_weight_pct"`
+}
+
+// RebalancePlan is the full set of suggested trades and their cash impact
+type RebalancePlan struct {
+	PortfolioID  int64            `json:"portfolio_id"`
+	Mode         string           `json:"mode"`
+	TotalValue   float64          `json:"total_value"`
+	Trades       []RebalanceTrade `json:"trades"`
+	BuyValue     float64          `json:"buy_value"`
+	SellValue    float64          `json:"sell_value"`
+	ResidualCash float64          `json:"residual_cash"`
+	Unallocated  []string         `json:"unallocated_targets"`
+	Notes        []string         `json:"notes"`
+	Timestamp    string           `json:"timestamp"`
+}
+
+// rebalanceInstrument carries the pricing inputs for one symbol
+type rebalanceInstrument struct {
+	symbol   string
+	sector   string
+	quantity float64
+	price    float64
+	lotSize  float64
+}
+
+// GetRebalancePlan loads holdings, prices, sectors and lot sizes and computes
+// the trades needed to move the portfolio towards the requested targets
+func (db *DB) GetRebalancePlan(ctx context.Context, portfolioID int64, req RebalanceRequest) (*RebalancePlan, error) {
+	summary, err := db.GetPortfolioHoldings(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	instruments := map[string]*rebalanceInstrument{}
+	for _, h := range summary.Holdings {
+		if h.Quantity <= 0 {
+			continue
+		}
+		instruments[h.Symbol] = &rebalanceInstrument{symbol: h.Symbol, quantity: h.Quantity, price: h.LastPrice}
+	}
+	if req.Mode == RebalanceBySymbol {
+		for symbol := range req.Targets {
+			if _, ok := instruments[symbol]; !ok {
+				instruments[symbol] = &rebalanceInstrument{symbol: symbol}
+			}
+		}
+	}
+
+	symbols := make([]string, 0, len(instruments))
+	for s := range instruments {
+		symbols = append(symbols, s)
+	}
+
+	prices, err := db.GetLatestPrices(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := db.getInstrumentMeta(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	for s, inst := range instruments {
+		if p, ok := prices[s]; ok {
+			inst.price = p
+		}
+		inst.lotSize = 1
+		if m, ok := meta[s]; ok {
+			inst.sector = m.sector
+			if m.lotSize > 0 {
+				inst.lotSize = m.lotSize
+			}
+		}
+		if inst.sector == "" {
+			inst.sector = "OTHER"
+		}
+	}
+
+	list := make([]rebalanceInstrument, 0, len(instruments))
+	for _, inst := range instruments {
+		list = append(list, *inst)
+	}
+
+	plan := computeRebalance(list, req)
+	plan.PortfolioID = portfolioID
+	return plan, nil
+}
+
+// computeRebalance sizes orders in whole lots. Sector targets are spread over
+// existing holdings in that sector pro rata to current value; sectors with no
+// holdings cannot be allocated and are reported instead.
+func computeRebalance(instruments []rebalanceInstrument, req RebalanceRequest) *RebalancePlan {
+	plan := &RebalancePlan{
+		Mode:        req.Mode,
+		Trades:      []RebalanceTrade{},
+		Unallocated: []string{},
+		Notes:       []string{},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	sort.Slice(instruments, func(i, j int) bool { return instruments[i].symbol < instruments[j].symbol })
+
+	holdingsValue := 0.0
+	sectorValue := map[string]float64{}
+	sectorCount := map[string]int{}
+	for _, inst := range instruments {
+		v := inst.quantity * inst.price
+		holdingsValue += v
+		sectorValue[inst.sector] += v
+		if inst.quantity > 0 {
+			sectorCount[inst.sector]++
+		}
+	}
+	total := holdingsValue + req.AdditionalCash
+	plan.TotalValue = round2(total)
+	if total <= 0 {
+		plan.Notes = append(plan.Notes, "Portfolio has no value to rebalance")
+		return plan
+	}
+
+	// Resolve each instrument's target weight (percent of total)
+	targetWeight := map[string]float64{}
+	switch req.Mode {
+	case RebalanceBySector:
+		targets := map[string]float64{}
+		for sector, w := range req.Targets {
+			targets[strings.ToUpper(sector)] = w
+		}
+		for sector, w := range targets {
+			if sectorCount[sector] == 0 && w > 0 {
+				plan.Unallocated = append(plan.Unallocated, sector)
+			}
+		}
+		for _, inst := range instruments {
+			w := targets[strings.ToUpper(inst.sector)]
+			if w == 0 || inst.quantity <= 0 {
+				continue
+			}
+			if sv := sectorValue[inst.sector]; sv > 0 {
+				targetWeight[inst.symbol] = w * inst.quantity * inst.price / sv
+			} else {
+				targetWeight[inst.symbol] = w / float64(sectorCount[inst.sector])
+			}
+		}
+	default:
+		for symbol, w := range req.Targets {
+			targetWeight[strings.ToUpper(symbol)] = w
+		}
+	}
+	sort.Strings(plan.Unallocated)
+
+	cash := req.AdditionalCash
+	postValue := map[string]float64{}
+	for _, inst := range instruments {
+		w := targetWeight[strings.ToUpper(inst.symbol)]
+		if inst.price <= 0 {
+			if w > 0 {
+				plan.Unallocated = append(plan.Unallocated, inst.symbol)
+				plan.Notes = append(plan.Notes, fmt.Sprintf("No price available for %s", inst.symbol))
+			}
+			continue
+		}
+
+		lotValue := inst.price * inst.lotSize
+		// Round to the nearest whole lot
+		targetQty := math.Round(total*w/100/lotValue) * inst.lotSize
+		delta := targetQty - inst.quantity
+
+		trade := RebalanceTrade{
+			Symbol:          inst.symbol,
+			Sector:          inst.sector,
+			Action:          "HOLD",
+			LotSize:         inst.lotSize,
+			Price:           inst.price,
+			CurrentQuantity: inst.quantity,
+			TargetQuantity:  targetQty,
+			CurrentWeight:   round2(inst.quantity * inst.price / total * 100),
+			TargetWeight:    round2(w),
+		}
+		if math.Abs(delta)*inst.price >= req.MinTradeValue && delta != 0 {
+			trade.Quantity = math.Abs(delta)
+			trade.TradeValue = round2(trade.Quantity * inst.price)
+			if delta > 0 {
+				trade.Action = "BUY"
+				plan.BuyValue += trade.TradeValue
+				cash -= trade.TradeValue
+			} else {
+				trade.Action = "SELL"
+				plan.SellValue += trade.TradeValue
+				cash += trade.TradeValue
+			}
+		}
+
+		post := inst.quantity
+		switch trade.Action {
+		case "BUY":
+			post += trade.Quantity
+		case "SELL":
+			post -= trade.Quantity
+		}
+		postValue[inst.symbol] = post * inst.price
+		plan.Trades = append(plan.Trades, trade)
+	}
+
+	for i := range plan.Trades {
+		plan.Trades[i].PostTradeWeight = round2(postValue[plan.Trades[i].Symbol] / total * 100)
+	}
+
+	plan.BuyValue = round2(plan.BuyValue)
+	plan.SellValue = round2(plan.SellValue)
+	plan.ResidualCash = round2(cash)
+	if cash < 0 {
+		plan.Notes = append(plan.Notes, "Suggested buys exceed available cash; lot rounding overshoots the targets")
+	}
+	return plan
+}
+
+// instrumentMeta holds reference data used when sizing orders
+type instrumentMeta struct {
+	sector  string
+	lotSize float64
+}
+
+// getInstrumentMeta returns sector (from stock config) and lot size (from the
+// instrument master) for each symbol
+func (db *DB) getInstrumentMeta(ctx context.Context, symbols []string) (map[string]instrumentMeta, error) {
+	meta := map[string]instrumentMeta{}
+	if len(symbols) == 0 {
+		return meta, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT s.symbol,
+			COALESCE(UPPER(sc.sector), ''),
+			COALESCE(it.lot_size, 1)
+		FROM UNNEST($1::text[]) AS s(symbol)
+		LEFT JOIN md.stock_config sc ON sc.symbol = s.symbol AND sc.exchange = 'NSE'
+		LEFT JOIN LATERAL (
+			SELECT lot_size FROM md.instrument_tokens
+			WHERE tradingsymbol = s.symbol AND exchange = 'NSE'
+			LIMIT 1
+		) it ON true
+	`, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instrument metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var m instrumentMeta
+		if err := rows.Scan(&symbol, &m.sector, &m.lotSize); err != nil {
+			return nil, fmt.Errorf("failed to scan instrument metadata: %w", err)
+		}
+		meta[symbol] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return meta, nil
+}
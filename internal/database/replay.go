@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ReplayTick is one recorded tick from md.ticks
+type ReplayTick struct {
+	Symbol    string
+	Timestamp time.Time
+	Price     float64
+	Volume    int64
+}
+
+// ReplayArticle is a news article as it was published
+type ReplayArticle struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Source         string    `json:"source"`
+	PublishedAt    time.Time `json:"published_at"`
+	URL            *string   `json:"url"`
+	Summary        *string   `json:"summary"`
+	Sentiment      float64   `json:"sentiment"`
+	SentimentLabel *string   `json:"sentiment_label"`
+	Symbols        []string  `json:"symbols"`
+}
+
+// GetReplayTicks returns recorded ticks in [from, to) in time order,
+// optionally only for symbols
+func (db *DB) GetReplayTicks(ctx context.Context, from, to time.Time, symbols []string) ([]ReplayTick, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, ts, last_price, volume
+		FROM md.ticks
+		WHERE ts >= $1 AND ts < $2 AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+		ORDER BY ts, symbol
+	`, from, to, pq.Array(symbols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replay ticks: %w", err)
+	}
+	defer rows.Close()
+
+	ticks := []ReplayTick{}
+	for rows.Next() {
+		var t ReplayTick
+		if err := rows.Scan(&t.Symbol, &t.Timestamp, &t.Price, &t.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan replay tick: %w", err)
+		}
+		ticks = append(ticks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return ticks, nil
+}
+
+// GetReplaySignals returns signals generated in [from, to), earliest first,
+// optionally only for symbols
+func (db *DB) GetReplaySignals(ctx context.Context, from, to time.Time, symbols []string) ([]Signal, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalColumns+`
+		FROM intraday.signals
+		WHERE generated_at >= $1 AND generated_at < $2 AND (cardinality($3::text[]) = 0 OR symbol = ANY($3))
+		ORDER BY generated_at, signal_id
+	`, from, to, pq.Array(symbols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replay signals: %w", err)
+	}
+	defer rows.Close()
+
+	signals := []Signal{}
+	for rows.Next() {
+		s, err := scanSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return signals, nil
+}
+
+// GetReplayNews returns articles published in [from, to), earliest first
+func (db *DB) GetReplayNews(ctx context.Context, from, to time.Time) ([]ReplayArticle, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT a.id, COALESCE(a.title, ''), COALESCE(a.source, 'Unknown'), a.published_at, a.url, a.summary,
+			COALESCE(a.sentiment_score, 0.5), a.sentiment_label,
+			COALESCE((SELECT array_agg(ae.symbol ORDER BY ae.symbol) FROM news.article_entities ae
+				WHERE ae.article_id = a.id), '{}')
+		FROM news.articles a
+		WHERE a.published_at >= $1 AND a.published_at < $2
+		ORDER BY a.published_at, a.id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replay news: %w", err)
+	}
+	defer rows.Close()
+
+	articles := []ReplayArticle{}
+	for rows.Next() {
+		var a ReplayArticle
+		if err := rows.Scan(&a.ID, &a.Title, &a.Source, &a.PublishedAt, &a.URL, &a.Summary, &a.Sentiment,
+			&a.SentimentLabel, pq.Array(&a.Symbols)); err != nil {
+			return nil, fmt.Errorf("failed to scan replay article: %w", err)
+		}
+		articles = append(articles, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return articles, nil
+}
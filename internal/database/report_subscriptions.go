@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReportSubscription delivers a periodic report to a user over a channel.
+// SendTime is HH:MM in IST; Weekday (0 = Sunday) is set for weekly reports.
+type ReportSubscription struct {
+	ID          int64   `json:"id"`
+	UserID      string  `json:"user_id"`
+	ReportType  string  `json:"report_type"`
+	Channel     string  `json:"channel"`
+	Destination string  `json:"destination"`
+	SendTime    string  `json:"send_time"`
+	Weekday     *int    `json:"weekday"`
+	Active      bool    `json:"active"`
+	NextRunAt   string  `json:"next_run_at"`
+	LastSentAt  *string `json:"last_sent_at"`
+	LastError   *string `json:"last_error"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+
+	nextRun time.Time
+}
+
+// NextRun returns NextRunAt as a time
+func (s ReportSubscription) NextRun() time.Time {
+	return s.nextRun
+}
+
+const reportSubscriptionColumns = `id, user_id, report_type, channel, destination, send_time, weekday, active,
+	next_run_at, last_sent_at, last_error, created_at, updated_at`
+
+func scanReportSubscription(row rowScanner) (*ReportSubscription, error) {
+	var s ReportSubscription
+	var weekday sql.NullInt16
+	var lastSent sql.NullTime
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&s.ID, &s.UserID, &s.ReportType, &s.Channel, &s.Destination, &s.SendTime, &weekday,
+		&s.Active, &s.nextRun, &lastSent, &s.LastError, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan report subscription: %w", err)
+	}
+	if weekday.Valid {
+		w := int(weekday.Int16)
+		s.Weekday = &w
+	}
+	if lastSent.Valid {
+		t := lastSent.Time.Format(time.RFC3339)
+		s.LastSentAt = &t
+	}
+	s.NextRunAt = s.nextRun.Format(time.RFC3339)
+	s.CreatedAt = createdAt.Format(time.RFC3339)
+	s.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &s, nil
+}
+
+func (db *DB) queryReportSubscriptions(ctx context.Context, query string, args ...interface{}) ([]ReportSubscription, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []ReportSubscription{}
+	for rows.Next() {
+		s, err := scanReportSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return subs, nil
+}
+
+// ListReportSubscriptions returns a user's subscriptions
+func (db *DB) ListReportSubscriptions(ctx context.Context, userID string) ([]ReportSubscription, error) {
+	return db.queryReportSubscriptions(ctx, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM core_api.report_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+}
+
+// ListDueReportSubscriptions returns active subscriptions whose next run is
+// at or before now
+func (db *DB) ListDueReportSubscriptions(ctx context.Context, now time.Time) ([]ReportSubscription, error) {
+	return db.queryReportSubscriptions(ctx, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM core_api.report_subscriptions
+		WHERE active AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT 500
+	`, now)
+}
+
+// GetReportSubscription returns a single subscription owned by a user, or nil if not found
+func (db *DB) GetReportSubscription(ctx context.Context, userID string, id int64) (*ReportSubscription, error) {
+	s, err := scanReportSubscription(db.conn.QueryRowContext(ctx, `
+		SELECT `+reportSubscriptionColumns+`
+		FROM core_api.report_subscriptions
+		WHERE id = $1 AND user_id = $2
+	`, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CreateReportSubscription inserts a subscription first due at nextRun
+func (db *DB) CreateReportSubscription(ctx context.Context, s ReportSubscription, nextRun time.Time) (*ReportSubscription, error) {
+	return scanReportSubscription(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.report_subscriptions
+			(user_id, report_type, channel, destination, send_time, weekday, active, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+reportSubscriptionColumns,
+		s.UserID, s.ReportType, s.Channel, s.Destination, s.SendTime, s.Weekday, s.Active, nextRun))
+}
+
+// UpdateReportSubscription replaces a subscription's settings, returning nil if not found
+func (db *DB) UpdateReportSubscription(ctx context.Context, s ReportSubscription, nextRun time.Time) (*ReportSubscription, error) {
+	updated, err := scanReportSubscription(db.conn.QueryRowContext(ctx, `
+		UPDATE core_api.report_subscriptions
+		SET report_type = $3, channel = $4, destination = $5, send_time = $6, weekday = $7,
+		    active = $8, next_run_at = $9, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+reportSubscriptionColumns,
+		s.ID, s.UserID, s.ReportType, s.Channel, s.Destination, s.SendTime, s.Weekday, s.Active, nextRun))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteReportSubscription removes a subscription, returning false if not found
+func (db *DB) DeleteReportSubscription(ctx context.Context, userID string, id int64) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.report_subscriptions WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete report subscription: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ClaimReportSubscription moves a due subscription's next run from due to
+// next. It returns false if another instance claimed the run first.
+func (db *DB) ClaimReportSubscription(ctx context.Context, id int64, due, next time.Time) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.report_subscriptions
+		SET next_run_at = $3
+		WHERE id = $1 AND active AND next_run_at = $2
+	`, id, due, next)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim report subscription: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// RecordReportDelivery stores the outcome of a delivery. A nil sendErr marks
+// the report as sent and clears the last error.
+func (db *DB) RecordReportDelivery(ctx context.Context, id int64, sendErr error) error {
+	var lastError *string
+	if sendErr != nil {
+		msg := sendErr.Error()
+		lastError = &msg
+	}
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.report_subscriptions
+		SET last_sent_at = CASE WHEN $2::text IS NULL THEN NOW() ELSE last_sent_at END,
+		    last_error = $2
+		WHERE id = $1
+	`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to record report delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,516 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// The interfaces below describe what the HTTP handlers need from the
+// database, grouped by domain. *DB implements all of them; handlers depend on
+// the interfaces so they can be exercised against fakes without Postgres.
+
+// SignalRepository reads intraday and investment signals
+type SignalRepository interface {
+	GetActiveSignals(ctx context.Context) ([]Signal, error)
+	GetAllSignals(ctx context.Context, limit int, status string) ([]Signal, error)
+	StreamSignals(ctx context.Context, limit int, status string, fn func(Signal) error) error
+	GetSignalByID(ctx context.Context, signalID string) (*Signal, error)
+	GetDashboardData(ctx context.Context, limit int, includeClosed bool) (*DashboardData, error)
+	GetInvestmentSignals(ctx context.Context, minConfidence, minSuccessRate float64, requireSentiment bool) (*InvestmentSignalsResponse, error)
+	GetPredictedGainers(ctx context.Context, limit int) ([]PredictedMover, error)
+	GetPredictedLosers(ctx context.Context, limit int) ([]PredictedMover, error)
+	GetSignalAlerts(ctx context.Context, strategy string, minConfidence float64) ([]NewsAlert, error)
+	UpdateSignalExecution(ctx context.Context, signalID string, e SignalExecution) (*Signal, error)
+}
+
+// TimeTravelRepository reconstructs what the dashboards showed at a past
+// instant
+type TimeTravelRepository interface {
+	GetActiveSignalsAsOf(ctx context.Context, asOf time.Time) ([]Signal, error)
+	GetDashboardDataAsOf(ctx context.Context, asOf time.Time, limit int, includeClosed bool) (*DashboardData, error)
+	GetPortfolioStatsAsOf(ctx context.Context, asOf time.Time) (*PortfolioStats, error)
+}
+
+// FollowRepository manages the symbols and strategies accounts follow
+type FollowRepository interface {
+	ListFollows(ctx context.Context, accountID string) ([]Follow, error)
+	AddFollow(ctx context.Context, accountID, userID, kind, value string) (bool, error)
+	RemoveFollow(ctx context.Context, accountID, kind, value string) (bool, error)
+	StreamFollowedSignals(ctx context.Context, accountID string, limit int, status string, fn func(Signal) error) error
+}
+
+// MarketRepository reads prices, candles, movers and indices
+type MarketRepository interface {
+	GetRealtimePrice(ctx context.Context, symbol string) (*RealtimePrice, error)
+	GetRealtimePrices(ctx context.Context, limit int) ([]RealtimePrice, error)
+	StreamRealtimePrices(ctx context.Context, limit int, fn func(RealtimePrice) error) error
+	GetStockData(ctx context.Context, symbol string) (*StockData, error)
+	GetTopGainers(ctx context.Context, limit int) ([]TopMover, error)
+	GetTopLosers(ctx context.Context, limit int) ([]TopMover, error)
+	SearchStocks(ctx context.Context, query string) ([]StockSearchResult, error)
+	GetCandles(ctx context.Context, symbol, interval string, from, to time.Time) ([]Candle, error)
+	GetMarketIndices(ctx context.Context) ([]MarketIndex, error)
+	GetHeatmapQuotes(ctx context.Context) ([]HeatmapQuote, error)
+	GetVolatility(ctx context.Context, symbol string) (*Volatility, error)
+}
+
+// NewsRepository reads news articles
+type NewsRepository interface {
+	GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string, collapse bool) (*NewsResponse, error)
+	GetNewsCluster(ctx context.Context, clusterID string) ([]NewsArticle, error)
+}
+
+// PortfolioRepository manages portfolios, their ledgers and reports
+type PortfolioRepository interface {
+	GetPortfolioStats(ctx context.Context) (*PortfolioStats, error)
+	ListPortfolios(ctx context.Context, accountID string) ([]Portfolio, error)
+	GetPortfolio(ctx context.Context, accountID string, id int64) (*Portfolio, error)
+	CreatePortfolio(ctx context.Context, accountID, userID, name string, description *string, baseCurrency string) (*Portfolio, error)
+	UpdatePortfolio(ctx context.Context, accountID string, id int64, name, description *string) (*Portfolio, error)
+	DeletePortfolio(ctx context.Context, accountID string, id int64) (bool, error)
+	ListPortfolioTransactions(ctx context.Context, portfolioID int64) ([]PortfolioTransaction, error)
+	AddPortfolioTransaction(ctx context.Context, t PortfolioTransaction, tradedAt time.Time) (*PortfolioTransaction, error)
+	ImportPortfolioTransactions(ctx context.Context, portfolioID int64, source string, trades []ImportedTrade) (*ImportResult, error)
+	GetPortfolioHoldings(ctx context.Context, portfolioID int64) (*PortfolioSummary, error)
+	GetPortfolioPerformance(ctx context.Context, portfolioID int64, period string) (*PortfolioPerformance, error)
+	GetPortfolioDividends(ctx context.Context, portfolioID int64) (*DividendReport, error)
+	GetCapitalGainsReport(ctx context.Context, portfolioID int64, fy string) (*CapitalGainsReport, error)
+	GetRebalancePlan(ctx context.Context, portfolioID int64, req RebalanceRequest) (*RebalancePlan, error)
+}
+
+// StockConfigRepository manages the tracked stock universe and system config
+type StockConfigRepository interface {
+	GetStockConfigs(ctx context.Context, f StockConfigFilters) (*StockConfigResponse, error)
+	GetStockConfigStats(ctx context.Context) (*StockConfigStats, error)
+	UpdateStockConfig(ctx context.Context, symbol, exchange string, updates map[string]interface{}) error
+	ExportStockConfigsCSV(ctx context.Context) (string, error)
+	GetImportJobStatus(ctx context.Context, jobID string) (map[string]interface{}, error)
+	GetSystemConfig(ctx context.Context, key string) (string, bool, error)
+	GetStockSelectionCounts(ctx context.Context) (*StockSelectionCounts, error)
+	ClearMLSelections(ctx context.Context) error
+}
+
+// SettingsRepository reads and writes md.system_config for the typed
+// settings registry
+type SettingsRepository interface {
+	GetSystemConfigValues(ctx context.Context, keys []string) (map[string]SystemConfigValue, error)
+	PutSystemConfig(ctx context.Context, key, value, description, updatedBy string) (*string, time.Time, error)
+}
+
+// BrokerRepository manages broker credentials and tokens
+type BrokerRepository interface {
+	GetBrokerConfig(ctx context.Context, brokerName string) (*BrokerConfig, error)
+	UpdateBrokerToken(ctx context.Context, brokerName, accessToken, userID string, expiresAt time.Time) error
+	ClearBrokerToken(ctx context.Context, brokerName string) error
+}
+
+// ExportRepository reads a user's export jobs
+type ExportRepository interface {
+	ListExportJobs(ctx context.Context, userID string, limit int) ([]ExportJob, error)
+	GetExportJob(ctx context.Context, userID, id string) (*ExportJob, error)
+}
+
+// RetentionRepository reads retention history
+type RetentionRepository interface {
+	GetLastRetentionRun(ctx context.Context) (*RetentionRun, error)
+}
+
+// DeadLetterRepository stores NATS events that failed to decode
+type DeadLetterRepository interface {
+	ListDeadLetters(ctx context.Context, subject string, pending bool, limit int) ([]DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id int64) (*DeadLetter, error)
+	RecordDeadLetterAttempt(ctx context.Context, id int64, reprocessErr error) error
+}
+
+// BasketRepository manages custom baskets, their valuation and alerts
+type BasketRepository interface {
+	ListBaskets(ctx context.Context, accountID string) ([]Basket, error)
+	GetBasket(ctx context.Context, accountID string, id int64) (*Basket, error)
+	CreateBasket(ctx context.Context, accountID, userID, name string, description *string, baseValue float64, weights map[string]float64) (*Basket, error)
+	UpdateBasket(ctx context.Context, accountID string, id int64, name, description *string, weights map[string]float64) (*Basket, error)
+	DeleteBasket(ctx context.Context, accountID string, id int64) (bool, error)
+	ValueBasket(ctx context.Context, b *Basket) (*BasketValuation, error)
+	GetBasketHistory(ctx context.Context, b *Basket, from time.Time) ([]BasketNAVPoint, error)
+	ListBasketAlerts(ctx context.Context, basketID int64) ([]BasketAlert, error)
+	ListActiveBasketAlerts(ctx context.Context) ([]BasketAlert, error)
+	CreateBasketAlert(ctx context.Context, basketID int64, condition string, threshold float64) (*BasketAlert, error)
+	DeleteBasketAlert(ctx context.Context, basketID, alertID int64) (bool, error)
+	TriggerBasketAlert(ctx context.Context, alertID int64, value float64) (bool, error)
+}
+
+// JournalRepository manages users' trading journals
+type JournalRepository interface {
+	ListJournalEntries(ctx context.Context, accountID string, f JournalFilter) ([]JournalEntry, error)
+	GetJournalEntry(ctx context.Context, accountID string, id int64) (*JournalEntry, error)
+	CreateJournalEntry(ctx context.Context, e JournalEntry, entryDate time.Time) (*JournalEntry, error)
+	UpdateJournalEntry(ctx context.Context, accountID string, id int64, u JournalUpdate) (*JournalEntry, error)
+	DeleteJournalEntry(ctx context.Context, accountID string, id int64) (bool, error)
+	GetJournalWeeklySummary(ctx context.Context, accountID string, weekStart time.Time) (*JournalWeeklySummary, error)
+}
+
+// ReportSubscriptionRepository manages scheduled report subscriptions
+type ReportSubscriptionRepository interface {
+	ListReportSubscriptions(ctx context.Context, userID string) ([]ReportSubscription, error)
+	GetReportSubscription(ctx context.Context, userID string, id int64) (*ReportSubscription, error)
+	CreateReportSubscription(ctx context.Context, s ReportSubscription, nextRun time.Time) (*ReportSubscription, error)
+	UpdateReportSubscription(ctx context.Context, s ReportSubscription, nextRun time.Time) (*ReportSubscription, error)
+	DeleteReportSubscription(ctx context.Context, userID string, id int64) (bool, error)
+	ListDueReportSubscriptions(ctx context.Context, now time.Time) ([]ReportSubscription, error)
+	ClaimReportSubscription(ctx context.Context, id int64, due, next time.Time) (bool, error)
+	RecordReportDelivery(ctx context.Context, id int64, sendErr error) error
+}
+
+// CalendarRepository reads dated market events
+type CalendarRepository interface {
+	GetMarketHolidays(ctx context.Context, from, to time.Time) ([]MarketHoliday, error)
+	GetResultsDates(ctx context.Context, from, to time.Time) ([]ResultsDate, error)
+}
+
+// StatusPageRepository stores health check tallies and incidents for the
+// public status page
+type StatusPageRepository interface {
+	RecordStatusCheck(ctx context.Context, component string, day time.Time, level string) error
+	GetStatusUptime(ctx context.Context, since time.Time) (map[string]StatusUptime, error)
+	OpenStatusIncident(ctx context.Context, component, title, impact string, at time.Time) error
+	ResolveStatusIncident(ctx context.Context, component string, at time.Time) error
+	ListStatusIncidents(ctx context.Context, since time.Time, limit int) ([]StatusIncident, error)
+}
+
+// ConfigBundleRepository exports and imports environment configuration
+type ConfigBundleRepository interface {
+	ExportConfigBundle(ctx context.Context) (*ConfigBundle, error)
+	ImportConfigBundle(ctx context.Context, b *ConfigBundle, nextRuns []time.Time, dryRun bool) (*ConfigImportResult, error)
+}
+
+// NewsFeedRepository manages RSS feeds and the articles ingested from them
+type NewsFeedRepository interface {
+	ListNewsFeeds(ctx context.Context) ([]NewsFeed, error)
+	ListDueNewsFeeds(ctx context.Context, now time.Time) ([]NewsFeed, error)
+	GetNewsFeed(ctx context.Context, id int64) (*NewsFeed, error)
+	CreateNewsFeed(ctx context.Context, f NewsFeed) (*NewsFeed, error)
+	UpdateNewsFeed(ctx context.Context, f NewsFeed) (*NewsFeed, error)
+	DeleteNewsFeed(ctx context.Context, id int64) (bool, error)
+	RecordNewsFeedPoll(ctx context.Context, id int64, etag, lastModified string, ingested int, pollErr error) error
+	InsertFeedArticles(ctx context.Context, source string, articles []FeedArticle) (int, error)
+	ListActiveSymbols(ctx context.Context) ([]string, error)
+}
+
+// SentimentQueueRepository queues articles for sentiment scoring
+type SentimentQueueRepository interface {
+	EnqueueUnscoredArticles(ctx context.Context, since time.Time) (int, error)
+	ClaimSentimentTasks(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]SentimentTask, error)
+	SaveSentimentScores(ctx context.Context, scores []SentimentScore) error
+	FailSentimentTasks(ctx context.Context, articleIDs []string, backoff time.Duration, cause error) error
+	GetSentimentQueueStats(ctx context.Context, maxAttempts int) (*SentimentQueueStats, error)
+}
+
+// NewsClusterRepository groups near-duplicate articles
+type NewsClusterRepository interface {
+	ListUnclusteredArticles(ctx context.Context, since time.Time, limit int) ([]ClusterCandidate, error)
+	ListArticleClusters(ctx context.Context, since time.Time) ([]ArticleCluster, error)
+	SaveArticleClusters(ctx context.Context, clusters []ArticleCluster) error
+}
+
+// SignalNewsRepository links signals to the news that preceded them
+type SignalNewsRepository interface {
+	ListUnlinkedSignals(ctx context.Context, since time.Time, limit int) ([]SignalNews, error)
+	GetSignalNews(ctx context.Context, signalID string) (*SignalNews, error)
+	FindSignalNews(ctx context.Context, symbol string, at time.Time, lookback time.Duration, limit int) ([]SignalNewsLink, error)
+	SaveSignalNews(ctx context.Context, signalID string, links []SignalNewsLink) error
+}
+
+// NotificationRepository stores notification settings and held
+// notifications
+type NotificationRepository interface {
+	ListNotificationSettings(ctx context.Context, accountID, userID string) ([]NotificationSetting, error)
+	ListEnabledNotificationSettings(ctx context.Context) ([]NotificationSetting, error)
+	UpsertNotificationSetting(ctx context.Context, s NotificationSetting) (*NotificationSetting, error)
+	DeleteNotificationSetting(ctx context.Context, accountID, userID, channel string) (bool, error)
+	QueueNotification(ctx context.Context, accountID, userID, channel string, n PendingNotification) error
+	ListPendingNotifications(ctx context.Context, accountID, userID, channel string) ([]PendingNotification, error)
+	ClearPendingNotifications(ctx context.Context, ids []int64) error
+	RecordNotificationDigest(ctx context.Context, accountID, userID, channel string, at time.Time) error
+}
+
+// PushDeviceRepository stores mobile devices registered for push
+// notifications and their preferences
+type PushDeviceRepository interface {
+	ListPushDevices(ctx context.Context, accountID, userID string) ([]PushDevice, error)
+	ListEnabledPushDevices(ctx context.Context) ([]PushDevice, error)
+	RegisterPushDevice(ctx context.Context, d PushDevice) (*PushDevice, error)
+	UpdatePushDevice(ctx context.Context, d PushDevice) (*PushDevice, error)
+	DeletePushDevice(ctx context.Context, accountID, userID string, id int64) (bool, error)
+	RecordPushResult(ctx context.Context, id int64, sendErr error, disable bool) error
+}
+
+// ShareLinkRepository stores public read-only share links
+type ShareLinkRepository interface {
+	CreateShareLink(ctx context.Context, l ShareLink) (*ShareLink, error)
+	GetShareLink(ctx context.Context, id int64) (*ShareLink, error)
+	ListShareLinks(ctx context.Context, accountID string) ([]ShareLink, error)
+	DeleteShareLink(ctx context.Context, accountID string, id int64) (bool, error)
+	RecordShareView(ctx context.Context, id int64) error
+}
+
+// SignalCalendarRepository summarises signal outcomes by day
+type SignalCalendarRepository interface {
+	GetSignalCalendar(ctx context.Context, month time.Time) (*SignalCalendar, error)
+}
+
+// SignalIngestRepository stores signals from external providers
+type SignalIngestRepository interface {
+	IngestSignals(ctx context.Context, provider string, signals []IngestedSignal) (*SignalIngestResult, error)
+}
+
+// PriceUpsertRepository writes realtime prices pushed by collectors
+type PriceUpsertRepository interface {
+	UpsertRealtimePrices(ctx context.Context, updates []PriceUpdate) (*PriceUpsertResult, error)
+}
+
+// SignalApprovalRepository sizes active signals and stores the reviews of
+// those too large to execute without approval
+type SignalApprovalRepository interface {
+	GetActiveSignals(ctx context.Context) ([]Signal, error)
+	GetSignalPositionSize(ctx context.Context, s *Signal, capital, riskFraction float64) (*PositionSize, error)
+	RequestSignalApproval(ctx context.Context, signalID string, notional, threshold float64, expiresAt time.Time) (bool, error)
+	ListSignalApprovals(ctx context.Context, status string, limit int) ([]SignalApproval, error)
+	GetSignalApproval(ctx context.Context, signalID string) (*SignalApproval, error)
+	DecideSignalApproval(ctx context.Context, signalID, status, decidedBy, note string) (*SignalApproval, error)
+	ExpireSignalApprovals(ctx context.Context) ([]SignalApproval, error)
+}
+
+// CoverageRepository compares the intraday universe with the market data
+// received
+type CoverageRepository interface {
+	GetIntradayCoverage(ctx context.Context, from, to time.Time) (*IntradayCoverage, error)
+}
+
+// StockConfigSnapshotRepository keeps a daily copy of the stock config so
+// past universes can be looked up
+type StockConfigSnapshotRepository interface {
+	SnapshotStockConfig(ctx context.Context, date time.Time) (int64, error)
+	HasStockConfigSnapshot(ctx context.Context, date time.Time) (bool, error)
+	GetStockConfigAsOf(ctx context.Context, date time.Time, intradayOnly bool) (*StockConfigAsOf, error)
+}
+
+// PriceReconciliationRepository checks realtime closes against the
+// bhavcopy
+type PriceReconciliationRepository interface {
+	ReconcilePrices(ctx context.Context, date time.Time, tolerancePct float64) (*PriceReconciliation, error)
+	HasPriceReconciliation(ctx context.Context, date time.Time) (bool, error)
+	GetPriceReconciliation(ctx context.Context, date time.Time, kind string) (*PriceReconciliation, error)
+}
+
+// ReplayRepository reads back a recorded market day
+type ReplayRepository interface {
+	GetReplayTicks(ctx context.Context, from, to time.Time, symbols []string) ([]ReplayTick, error)
+	GetReplaySignals(ctx context.Context, from, to time.Time, symbols []string) ([]Signal, error)
+	GetReplayNews(ctx context.Context, from, to time.Time) ([]ReplayArticle, error)
+}
+
+// SignalTimelineRepository stores the lifecycle events of signals
+type SignalTimelineRepository interface {
+	RecordSignalEvent(ctx context.Context, e SignalTimelineEvent) (bool, error)
+	LastSignalEvent(ctx context.Context, signalID string) (*SignalTimelineEvent, error)
+	GetSignalTimeline(ctx context.Context, signalID string) ([]SignalTimelineEvent, error)
+	FindSignalID(ctx context.Context, symbol string, generatedAt time.Time) (string, error)
+}
+
+// SignalTombstoneRepository soft-deletes ingested and manual signals and
+// restores them within their window
+type SignalTombstoneRepository interface {
+	GetSignalByID(ctx context.Context, signalID string) (*Signal, error)
+	SoftDeleteSignal(ctx context.Context, signalID, reason, deletedBy string, restoreUntil time.Time) (*SignalTombstone, error)
+	ListSignalTombstones(ctx context.Context, limit int) ([]SignalTombstone, error)
+	GetSignalTombstone(ctx context.Context, signalID string) (*SignalTombstone, error)
+	RestoreSignal(ctx context.Context, signalID string) (*Signal, error)
+}
+
+// AdminConfirmationRepository stores two-person requests for destructive
+// admin actions and how they were decided
+type AdminConfirmationRepository interface {
+	RequestAdminConfirmation(ctx context.Context, action, method, path, payloadHash, requestedBy string, expiresAt time.Time) (*AdminConfirmation, error)
+	ListAdminConfirmations(ctx context.Context, status string, limit int) ([]AdminConfirmation, error)
+	GetAdminConfirmation(ctx context.Context, id int64) (*AdminConfirmation, error)
+	ConfirmAdminAction(ctx context.Context, id int64, action, payloadHash, confirmedBy string) (*AdminConfirmation, error)
+	CancelAdminConfirmation(ctx context.Context, id int64, cancelledBy string) (*AdminConfirmation, error)
+	RecordAdminActionResult(ctx context.Context, id int64, status int) error
+}
+
+// TradingViewRepository manages TradingView webhooks and their delivery log
+type TradingViewRepository interface {
+	CreateTradingViewWebhook(ctx context.Context, userID, name, mode, secretHash string) (*TradingViewWebhook, error)
+	ListTradingViewWebhooks(ctx context.Context, userID string) ([]TradingViewWebhook, error)
+	GetTradingViewWebhook(ctx context.Context, userID string, id int64) (*TradingViewWebhook, error)
+	GetTradingViewWebhookBySecret(ctx context.Context, secretHash string) (*TradingViewWebhook, error)
+	UpdateTradingViewWebhook(ctx context.Context, userID string, id int64, name string, enabled bool) (*TradingViewWebhook, error)
+	DeleteTradingViewWebhook(ctx context.Context, userID string, id int64) (bool, error)
+	RecordTradingViewDelivery(ctx context.Context, d TradingViewDelivery) error
+	ListTradingViewDeliveries(ctx context.Context, webhookID int64, limit int) ([]TradingViewDelivery, error)
+	ResolveSymbol(ctx context.Context, exchange string, candidates []string) (string, error)
+}
+
+// InboundWebhookRepository stores configured inbound webhook endpoints
+type InboundWebhookRepository interface {
+	ListInboundWebhooks(ctx context.Context) ([]InboundWebhook, error)
+	GetInboundWebhook(ctx context.Context, id int64) (*InboundWebhook, error)
+	GetInboundWebhookBySlug(ctx context.Context, slug string) (*InboundWebhook, error)
+	CreateInboundWebhook(ctx context.Context, w InboundWebhook) (*InboundWebhook, error)
+	UpdateInboundWebhook(ctx context.Context, w InboundWebhook) (*InboundWebhook, error)
+	DeleteInboundWebhook(ctx context.Context, id int64) (bool, error)
+	RecordInboundDelivery(ctx context.Context, id int64, accepted int, deliveryErr error) error
+	InsertFeedArticles(ctx context.Context, source string, articles []FeedArticle) (int, error)
+}
+
+// AssistantRepository is what the assistant's query intents read
+type AssistantRepository interface {
+	StreamSignals(ctx context.Context, limit int, status string, fn func(Signal) error) error
+	GetSignalByID(ctx context.Context, signalID string) (*Signal, error)
+	GetSignalStats(ctx context.Context, since time.Time) (*DashboardStats, error)
+	GetTopGainers(ctx context.Context, limit int) ([]TopMover, error)
+	GetTopLosers(ctx context.Context, limit int) ([]TopMover, error)
+	GetPredictedGainers(ctx context.Context, limit int) ([]PredictedMover, error)
+	GetPredictedLosers(ctx context.Context, limit int) ([]PredictedMover, error)
+	GetRealtimePrice(ctx context.Context, symbol string) (*RealtimePrice, error)
+	SearchStocks(ctx context.Context, query string) ([]StockSearchResult, error)
+	GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string, collapse bool) (*NewsResponse, error)
+	GetMarketIndices(ctx context.Context) ([]MarketIndex, error)
+}
+
+// PresetRepository stores accounts' saved filter presets
+type PresetRepository interface {
+	ListPresets(ctx context.Context, accountID, scope string) ([]Preset, error)
+	GetPreset(ctx context.Context, accountID string, id int64) (*Preset, error)
+	CreatePreset(ctx context.Context, p Preset) (*Preset, error)
+	UpdatePreset(ctx context.Context, p Preset) (*Preset, error)
+	DeletePreset(ctx context.Context, accountID string, id int64) (bool, error)
+}
+
+// AccountRepository manages accounts and their members
+type AccountRepository interface {
+	ListAccounts(ctx context.Context, status string) ([]Account, error)
+	ListUserAccounts(ctx context.Context, userID string) ([]Account, error)
+	GetAccount(ctx context.Context, id string) (*Account, error)
+	CreateAccount(ctx context.Context, name, ownerID string) (*Account, error)
+	EnsurePersonalAccount(ctx context.Context, userID string) error
+	GetAccountMembership(ctx context.Context, accountID, userID string) (*AccountMembership, error)
+	UpdateAccount(ctx context.Context, id string, name, status *string) (*Account, error)
+	PutAccountMember(ctx context.Context, accountID, userID, role string) error
+	RemoveAccountMember(ctx context.Context, accountID, userID string) (bool, error)
+}
+
+// UsageRepository meters accounts' daily usage and stores their quotas
+type UsageRepository interface {
+	AddAccountUsage(ctx context.Context, day time.Time, deltas map[string]UsageCounts) (map[string]UsageCounts, error)
+	GetAccountUsageDay(ctx context.Context, day time.Time) (map[string]UsageCounts, error)
+	ListAccountUsage(ctx context.Context, accountID string, from, to time.Time) ([]AccountUsage, error)
+	ListAccountQuotas(ctx context.Context) ([]AccountQuota, error)
+	PutAccountQuota(ctx context.Context, q AccountQuota) (*AccountQuota, error)
+	DeleteAccountQuota(ctx context.Context, accountID string) (bool, error)
+}
+
+// AuthSecurityRepository stores security events seen on the auth endpoints
+type AuthSecurityRepository interface {
+	InsertAuthSecurityEvent(ctx context.Context, e AuthSecurityEvent) (*AuthSecurityEvent, error)
+	ListAuthSecurityEvents(ctx context.Context, f AuthSecurityFilter) ([]AuthSecurityEvent, error)
+	DeleteAuthSecurityEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WatchlistRepository stores accounts' named watchlists
+type WatchlistRepository interface {
+	ListWatchlist(ctx context.Context, accountID, watchlist string) ([]WatchlistItem, error)
+	AddToWatchlist(ctx context.Context, accountID, watchlist, userID string, symbols []string) ([]string, error)
+	RemoveFromWatchlist(ctx context.Context, accountID, watchlist, symbol string) (bool, error)
+}
+
+// SignalFillRepository records signal executions and reports slippage
+type SignalFillRepository interface {
+	UpsertSignalFill(ctx context.Context, signalID, leg, source string, price float64, quantity *float64, filledAt time.Time, externalID *string) (*SignalFill, error)
+	ListSignalFills(ctx context.Context, signalID string) ([]SignalFill, error)
+	MatchBrokerFills(ctx context.Context, trades []ImportedTrade) (int, error)
+	GetSlippageReport(ctx context.Context, since time.Time, source string) (*SlippageReport, error)
+}
+
+// ExposureRepository aggregates open positions for risk views
+type ExposureRepository interface {
+	GetExposure(ctx context.Context, accountID string, signalNotional float64) (*Exposure, error)
+}
+
+// PositionSizeRepository sizes positions from closed-signal history
+type PositionSizeRepository interface {
+	GetSymbolTradeStats(ctx context.Context, symbol string, since time.Time) (*SymbolTradeStats, error)
+	GetSignalPositionSize(ctx context.Context, s *Signal, capital, riskFraction float64) (*PositionSize, error)
+}
+
+// StressRepository projects scenario P&L on open positions
+type StressRepository interface {
+	RunStressTest(ctx context.Context, accountID string, signalNotional float64, shock StressShock) (*StressResult, error)
+}
+
+// RiskSnapshotRepository computes and stores intraday risk snapshots
+type RiskSnapshotRepository interface {
+	GetExposure(ctx context.Context, accountID string, signalNotional float64) (*Exposure, error)
+	GetSignalBookPnL(ctx context.Context, notional float64, since time.Time) (float64, float64, error)
+	InsertRiskSnapshot(ctx context.Context, s RiskSnapshot) (*RiskSnapshot, error)
+	ListRiskSnapshots(ctx context.Context, from, to time.Time) ([]RiskSnapshot, error)
+}
+
+// Repository is everything the main API handler uses
+type Repository interface {
+	SignalRepository
+	TimeTravelRepository
+	FollowRepository
+	MarketRepository
+	NewsRepository
+	PortfolioRepository
+	WatchlistRepository
+	StockConfigRepository
+	BrokerRepository
+	SignalFillRepository
+	PositionSizeRepository
+	Ping(ctx context.Context) error
+}
+
+var (
+	_ Repository                    = (*DB)(nil)
+	_ ExportRepository              = (*DB)(nil)
+	_ RetentionRepository           = (*DB)(nil)
+	_ DeadLetterRepository          = (*DB)(nil)
+	_ BasketRepository              = (*DB)(nil)
+	_ JournalRepository             = (*DB)(nil)
+	_ ReportSubscriptionRepository  = (*DB)(nil)
+	_ CalendarRepository            = (*DB)(nil)
+	_ StatusPageRepository          = (*DB)(nil)
+	_ ConfigBundleRepository        = (*DB)(nil)
+	_ NewsFeedRepository            = (*DB)(nil)
+	_ SentimentQueueRepository      = (*DB)(nil)
+	_ NewsClusterRepository         = (*DB)(nil)
+	_ SignalNewsRepository          = (*DB)(nil)
+	_ NotificationRepository        = (*DB)(nil)
+	_ PushDeviceRepository          = (*DB)(nil)
+	_ ShareLinkRepository           = (*DB)(nil)
+	_ SignalCalendarRepository      = (*DB)(nil)
+	_ SignalFillRepository          = (*DB)(nil)
+	_ ExposureRepository            = (*DB)(nil)
+	_ PositionSizeRepository        = (*DB)(nil)
+	_ StressRepository              = (*DB)(nil)
+	_ RiskSnapshotRepository        = (*DB)(nil)
+	_ SignalIngestRepository        = (*DB)(nil)
+	_ PriceUpsertRepository         = (*DB)(nil)
+	_ SignalApprovalRepository      = (*DB)(nil)
+	_ SignalTombstoneRepository     = (*DB)(nil)
+	_ StockConfigSnapshotRepository = (*DB)(nil)
+	_ CoverageRepository            = (*DB)(nil)
+	_ PriceReconciliationRepository = (*DB)(nil)
+	_ ReplayRepository              = (*DB)(nil)
+	_ SignalTimelineRepository      = (*DB)(nil)
+	_ SettingsRepository            = (*DB)(nil)
+	_ AdminConfirmationRepository   = (*DB)(nil)
+	_ TradingViewRepository         = (*DB)(nil)
+	_ InboundWebhookRepository      = (*DB)(nil)
+	_ AssistantRepository           = (*DB)(nil)
+	_ PresetRepository              = (*DB)(nil)
+	_ AccountRepository             = (*DB)(nil)
+	_ UsageRepository               = (*DB)(nil)
+	_ AuthSecurityRepository        = (*DB)(nil)
+)
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// retentionTarget describes how expired rows of a dataset are found and removed
+type retentionTarget struct {
+	table      string
+	timeColumn string
+	// keep excludes rows that must survive regardless of age
+	keep string
+	// dependents are deleted first; each takes ($1 from, $2 to)
+	dependents []string
+	// hypertable targets drop whole chunks when TimescaleDB is enabled
+	hypertable bool
+}
+
+// retentionTargets are keyed by export dataset name so archives reuse the
+// export queries and schemas
+var retentionTargets = map[string]retentionTarget{
+	"ticks": {table: "md.ticks", timeColumn: "ts", hypertable: true},
+	"news": {
+		table:      "news.articles",
+		timeColumn: "published_at",
+		dependents: []string{`
+			DELETE FROM news.article_entities WHERE article_id IN (
+				SELECT id FROM news.articles WHERE published_at >= $1 AND published_at < $2)
+		`, `
+			DELETE FROM core_api.article_clusters WHERE article_id IN (
+				SELECT id FROM news.articles WHERE published_at >= $1 AND published_at < $2)
+		`},
+	},
+	"signals": {table: "intraday.signals", timeColumn: "generated_at", keep: "status = 'ACTIVE'"},
+}
+
+func (t retentionTarget) where() string {
+	w := fmt.Sprintf("%s >= $1 AND %s < $2", t.timeColumn, t.timeColumn)
+	if t.keep != "" {
+		w += " AND NOT (" + t.keep + ")"
+	}
+	return w
+}
+
+// OldestRetained returns the earliest timestamp of a dataset that retention
+// could remove, or nil if the table is empty
+func (db *DB) OldestRetained(ctx context.Context, dataset string) (*time.Time, error) {
+	t, ok := retentionTargets[dataset]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention dataset %q", dataset)
+	}
+	query := fmt.Sprintf("SELECT min(%s) FROM %s", t.timeColumn, t.table)
+	if t.keep != "" {
+		query += " WHERE NOT (" + t.keep + ")"
+	}
+
+	var oldest sql.NullTime
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		return conn.QueryRowContext(ctx, query).Scan(&oldest)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oldest %s: %w", dataset, err)
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	return &oldest.Time, nil
+}
+
+// CountRetained counts removable rows of a dataset in [from, to)
+func (db *DB) CountRetained(ctx context.Context, dataset string, from, to time.Time) (int64, error) {
+	t, ok := retentionTargets[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention dataset %q", dataset)
+	}
+
+	var count int64
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		return conn.QueryRowContext(ctx,
+			fmt.Sprintf("SELECT count(*) FROM %s WHERE %s", t.table, t.where()), from, to,
+		).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", dataset, err)
+	}
+	return count, nil
+}
+
+// PurgeRetained deletes removable rows of a dataset in [from, to) along with
+// their dependents, in one transaction
+func (db *DB) PurgeRetained(ctx context.Context, dataset string, from, to time.Time) (int64, error) {
+	t, ok := retentionTargets[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention dataset %q", dataset)
+	}
+
+	var deleted int64
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin purge: %w", err)
+		}
+		defer tx.Rollback()
+
+		for _, dep := range t.dependents {
+			if _, err := tx.ExecContext(ctx, dep, from, to); err != nil {
+				return fmt.Errorf("failed to purge %s dependents: %w", dataset, err)
+			}
+		}
+		result, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", t.table, t.where()), from, to)
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", dataset, err)
+		}
+		deleted, _ = result.RowsAffected()
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit purge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// DropExpiredChunks removes hypertable chunks entirely older than before.
+// It reports false when the dataset is not a hypertable in this deployment.
+func (db *DB) DropExpiredChunks(ctx context.Context, dataset string, before time.Time) (bool, error) {
+	t, ok := retentionTargets[dataset]
+	if !ok || !t.hypertable || !db.timescale {
+		return false, nil
+	}
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx,
+			"SELECT drop_chunks($1::regclass, older_than => $2::timestamptz)", t.table, before,
+		)
+		return err
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to drop %s chunks: %w", dataset, err)
+	}
+	return true, nil
+}
+
+// RetentionRun is a recorded retention pass
+type RetentionRun struct {
+	ID         int64           `json:"id"`
+	DryRun     bool            `json:"dry_run"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Results    json.RawMessage `json:"results"`
+}
+
+// RecordRetentionRun stores the outcome of a retention pass
+func (db *DB) RecordRetentionRun(ctx context.Context, dryRun bool, startedAt, finishedAt time.Time, results interface{}) error {
+	payload, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	if _, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.retention_runs (dry_run, started_at, finished_at, results)
+		VALUES ($1, $2, $3, $4)
+	`, dryRun, startedAt, finishedAt, payload); err != nil {
+		return fmt.Errorf("failed to record retention run: %w", err)
+	}
+	return nil
+}
+
+// GetLastRetentionRun returns the most recent pass, or nil if none has run
+func (db *DB) GetLastRetentionRun(ctx context.Context) (*RetentionRun, error) {
+	var run RetentionRun
+	var results []byte
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT id, dry_run, started_at, finished_at, results
+		FROM core_api.retention_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&run.ID, &run.DryRun, &run.StartedAt, &run.FinishedAt, &results)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last retention run: %w", err)
+	}
+	run.Results = results
+	return &run, nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RiskSnapshot is the intraday risk picture at one moment. Exposure covers
+// holdings and active signals as in GetExposure; P&L covers the signal book,
+// each signal sized at the same notional. LimitUsedPct is today's loss as a
+// share of DailyLossLimit, 0 while the day is in profit.
+type RiskSnapshot struct {
+	ID             int64   `json:"id"`
+	TakenAt        string  `json:"taken_at"`
+	OpenPositions  int     `json:"open_positions"`
+	GrossExposure  float64 `json:"gross_exposure"`
+	NetExposure    float64 `json:"net_exposure"`
+	RiskWeighted   float64 `json:"risk_weighted"`
+	UnrealizedPnL  float64 `json:"unrealized_pnl"`
+	RealizedPnL    float64 `json:"realized_pnl"`
+	DayPnL         float64 `json:"day_pnl"`
+	DailyLossLimit float64 `json:"daily_loss_limit"`
+	// DistanceToLimit is how much more can be lost today before the limit
+	DistanceToLimit float64 `json:"distance_to_limit"`
+	LimitUsedPct    float64 `json:"limit_used_pct"`
+	LimitBreached   bool    `json:"limit_breached"`
+}
+
+// fillDerived sets the fields computed from the stored ones
+func (s *RiskSnapshot) fillDerived() {
+	s.DayPnL = round2(s.UnrealizedPnL + s.RealizedPnL)
+	s.DistanceToLimit = round2(s.DailyLossLimit + s.DayPnL)
+	s.LimitBreached = s.DistanceToLimit <= 0
+}
+
+// NewRiskSnapshot builds a snapshot from exposure and the signal book's P&L
+func NewRiskSnapshot(takenAt time.Time, exposure *Exposure, unrealized, realized, dailyLossLimit float64) RiskSnapshot {
+	s := RiskSnapshot{
+		TakenAt:        takenAt.Format(time.RFC3339),
+		OpenPositions:  exposure.Totals.Positions,
+		GrossExposure:  exposure.Totals.Gross,
+		NetExposure:    exposure.Totals.Net,
+		RiskWeighted:   exposure.Totals.RiskWeighted,
+		UnrealizedPnL:  round2(unrealized),
+		RealizedPnL:    round2(realized),
+		DailyLossLimit: dailyLossLimit,
+	}
+	s.fillDerived()
+	if s.DayPnL < 0 && dailyLossLimit > 0 {
+		s.LimitUsedPct = round2(-s.DayPnL / dailyLossLimit * 100)
+	}
+	return s
+}
+
+// GetSignalBookPnL returns the unrealized P&L of active signals at their
+// current price and the realized P&L of signals closed since since, each
+// signal sized at notional
+func (db *DB) GetSignalBookPnL(ctx context.Context, notional float64, since time.Time) (float64, float64, error) {
+	var unrealizedPct, realizedPct float64
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(
+				(current_price - entry_price) / entry_price * 100 *
+				CASE WHEN UPPER(signal_type) IN ('SELL', 'SHORT') THEN -1 ELSE 1 END
+			) FILTER (WHERE status = 'ACTIVE' AND entry_price > 0 AND current_price > 0), 0)::float8,
+			COALESCE(SUM(actual_profit_pct) FILTER (WHERE status <> 'ACTIVE' AND closed_at >= $1), 0)::float8
+		FROM intraday.signals
+		WHERE status = 'ACTIVE' OR closed_at >= $1
+	`, since).Scan(&unrealizedPct, &realizedPct)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get signal book P&L: %w", err)
+	}
+	return unrealizedPct / 100 * notional, realizedPct / 100 * notional, nil
+}
+
+// InsertRiskSnapshot stores a snapshot, returning it with its ID
+func (db *DB) InsertRiskSnapshot(ctx context.Context, s RiskSnapshot) (*RiskSnapshot, error) {
+	err := db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.risk_snapshots (
+			taken_at, open_positions, gross_exposure, net_exposure, risk_weighted,
+			unrealized_pnl, realized_pnl, daily_loss_limit, limit_used_pct
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, s.TakenAt, s.OpenPositions, s.GrossExposure, s.NetExposure, s.RiskWeighted,
+		s.UnrealizedPnL, s.RealizedPnL, s.DailyLossLimit, s.LimitUsedPct,
+	).Scan(&s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert risk snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// ListRiskSnapshots returns snapshots taken in [from, to), oldest first
+func (db *DB) ListRiskSnapshots(ctx context.Context, from, to time.Time) ([]RiskSnapshot, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, taken_at, open_positions, gross_exposure::float8, net_exposure::float8,
+			risk_weighted::float8, unrealized_pnl::float8, realized_pnl::float8,
+			daily_loss_limit::float8, limit_used_pct::float8
+		FROM core_api.risk_snapshots
+		WHERE taken_at >= $1 AND taken_at < $2
+		ORDER BY taken_at
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risk snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []RiskSnapshot{}
+	for rows.Next() {
+		var s RiskSnapshot
+		var takenAt time.Time
+		if err := rows.Scan(&s.ID, &takenAt, &s.OpenPositions, &s.GrossExposure, &s.NetExposure,
+			&s.RiskWeighted, &s.UnrealizedPnL, &s.RealizedPnL, &s.DailyLossLimit, &s.LimitUsedPct); err != nil {
+			return nil, fmt.Errorf("failed to scan risk snapshot: %w", err)
+		}
+		s.TakenAt = takenAt.Format(time.RFC3339)
+		s.fillDerived()
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,343 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SessionMinutes is the length of the NSE cash session, 09:15 to 15:30 IST
+const SessionMinutes = 375
+
+// SessionOpen returns 09:15 IST on the given day
+func SessionOpen(day time.Time) time.Time {
+	loc := istLocation()
+	d := day.In(loc)
+	return time.Date(d.Year(), d.Month(), d.Day(), 9, 15, 0, 0, loc)
+}
+
+// OpeningRange is a symbol's opening range and any breakout from it
+type OpeningRange struct {
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name"`
+	Open      float64 `json:"open"`
+	RangeHigh float64 `json:"range_high"`
+	RangeLow  float64 `json:"range_low"`
+	// RangePct is the range width relative to its low
+	RangePct  float64 `json:"range_pct"`
+	LastPrice float64 `json:"last_price"`
+	// Breakout is UP or DOWN for the first 1m close outside the range
+	Breakout      *string    `json:"breakout"`
+	BreakoutAt    *time.Time `json:"breakout_at"`
+	BreakoutPrice *float64   `json:"breakout_price"`
+	// ExtensionPct is how far the last price is beyond the broken level
+	ExtensionPct *float64 `json:"extension_pct"`
+	RangeVolume  int64    `json:"range_volume"`
+}
+
+// GetOpeningRanges computes each symbol's high and low over the first
+// rangeMinutes of the session on day from md.bars_1m, and the first bar
+// that closed outside that range afterwards
+func (db *DB) GetOpeningRanges(ctx context.Context, day time.Time, rangeMinutes int) ([]OpeningRange, error) {
+	open := SessionOpen(day)
+	rangeEnd := open.Add(time.Duration(rangeMinutes) * time.Minute)
+	sessionEnd := open.Add(SessionMinutes * time.Minute)
+
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH session AS (
+			SELECT symbol, bucket, open, high, low, close, volume
+			FROM md.bars_1m
+			WHERE bucket >= $1 AND bucket < $3
+		),
+		opening AS (
+			SELECT symbol, max(high) AS range_high, min(low) AS range_low,
+				(array_agg(open ORDER BY bucket))[1] AS day_open, sum(volume)::bigint AS range_volume
+			FROM session
+			WHERE bucket < $2
+			GROUP BY symbol
+		),
+		breakout AS (
+			SELECT DISTINCT ON (s.symbol) s.symbol, s.bucket, s.close,
+				CASE WHEN s.close > o.range_high THEN 'UP' ELSE 'DOWN' END AS direction
+			FROM session s
+			JOIN opening o ON o.symbol = s.symbol
+			WHERE s.bucket >= $2 AND (s.close > o.range_high OR s.close < o.range_low)
+			ORDER BY s.symbol, s.bucket
+		),
+		latest AS (
+			SELECT DISTINCT ON (symbol) symbol, close
+			FROM session
+			ORDER BY symbol, bucket DESC
+		)
+		SELECT o.symbol, COALESCE(sc.name, o.symbol), o.day_open, o.range_high, o.range_low,
+			l.close, b.direction, b.bucket, b.close, o.range_volume
+		FROM opening o
+		JOIN latest l ON l.symbol = o.symbol
+		LEFT JOIN breakout b ON b.symbol = o.symbol
+		LEFT JOIN md.stock_config sc ON sc.symbol = o.symbol AND sc.exchange = 'NSE'
+		ORDER BY o.symbol
+	`, open, rangeEnd, sessionEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query opening ranges: %w", err)
+	}
+	defer rows.Close()
+
+	ranges := []OpeningRange{}
+	for rows.Next() {
+		var r OpeningRange
+		if err := rows.Scan(&r.Symbol, &r.Name, &r.Open, &r.RangeHigh, &r.RangeLow, &r.LastPrice,
+			&r.Breakout, &r.BreakoutAt, &r.BreakoutPrice, &r.RangeVolume); err != nil {
+			return nil, fmt.Errorf("failed to scan opening range: %w", err)
+		}
+		if r.RangeLow > 0 {
+			r.RangePct = (r.RangeHigh - r.RangeLow) / r.RangeLow * 100
+		}
+		if r.Breakout != nil {
+			level := r.RangeHigh
+			if *r.Breakout == "DOWN" {
+				level = r.RangeLow
+			}
+			ext := (r.LastPrice - level) / level * 100
+			if *r.Breakout == "DOWN" {
+				ext = -ext
+			}
+			r.ExtensionPct = &ext
+		}
+		ranges = append(ranges, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return ranges, nil
+}
+
+// Gap is a symbol's overnight gap between the previous close and today's open
+type Gap struct {
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name"`
+	PrevClose float64   `json:"prev_close"`
+	PrevDate  time.Time `json:"prev_date"`
+	Open      float64   `json:"open"`
+	GapPct    float64   `json:"gap_pct"`
+	LastPrice float64   `json:"last_price"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	// Filled is true once price has traded back to the previous close
+	Filled bool `json:"filled"`
+}
+
+// GetGaps compares the first 1m bar of the session on day with the latest
+// bhavcopy close before day
+func (db *DB) GetGaps(ctx context.Context, day time.Time) ([]Gap, error) {
+	open := SessionOpen(day)
+	sessionEnd := open.Add(SessionMinutes * time.Minute)
+
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH session AS (
+			SELECT symbol,
+				(array_agg(open ORDER BY bucket))[1] AS open,
+				(array_agg(close ORDER BY bucket DESC))[1] AS last,
+				max(high) AS high, min(low) AS low
+			FROM md.bars_1m
+			WHERE bucket >= $1 AND bucket < $2
+			GROUP BY symbol
+		),
+		previous AS (
+			SELECT DISTINCT ON (symbol) symbol, trade_date, close
+			FROM md.bhavcopy
+			WHERE series = 'EQ' AND trade_date < $3::date
+				AND trade_date >= $3::date - 10
+				AND symbol IN (SELECT symbol FROM session)
+			ORDER BY symbol, trade_date DESC
+		)
+		SELECT s.symbol, COALESCE(sc.name, s.symbol), p.close, p.trade_date, s.open, s.last, s.high, s.low
+		FROM session s
+		JOIN previous p ON p.symbol = s.symbol
+		LEFT JOIN md.stock_config sc ON sc.symbol = s.symbol AND sc.exchange = 'NSE'
+		WHERE p.close > 0
+	`, open, sessionEnd, open.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gaps: %w", err)
+	}
+	defer rows.Close()
+
+	gaps := []Gap{}
+	for rows.Next() {
+		var g Gap
+		if err := rows.Scan(&g.Symbol, &g.Name, &g.PrevClose, &g.PrevDate, &g.Open, &g.LastPrice, &g.High, &g.Low); err != nil {
+			return nil, fmt.Errorf("failed to scan gap: %w", err)
+		}
+		g.GapPct = (g.Open - g.PrevClose) / g.PrevClose * 100
+		g.Filled = (g.GapPct > 0 && g.Low <= g.PrevClose) || (g.GapPct < 0 && g.High >= g.PrevClose)
+		gaps = append(gaps, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return gaps, nil
+}
+
+// ScanPage selects a page of scan results restricted to sectors
+type ScanPage struct {
+	// Sectors matches md.stock_config.sector case-insensitively; empty
+	// means all sectors
+	Sectors []string
+	Limit   int
+	Offset  int
+}
+
+// UnusualVolume is a symbol trading well above its recent average volume
+type UnusualVolume struct {
+	Symbol        string   `json:"symbol"`
+	Name          string   `json:"name"`
+	Sector        string   `json:"sector"`
+	LastPrice     float64  `json:"last_price"`
+	ChangePercent *float64 `json:"change_percent"`
+	Volume        int64    `json:"volume"`
+	AvgVolume     float64  `json:"avg_volume_20d"`
+	// Ratio is today's volume over the 20-day average
+	Ratio float64 `json:"volume_ratio"`
+}
+
+// GetUnusualVolume lists symbols whose volume today in md.realtime_prices is
+// at least minRatio times their average daily bhavcopy volume over the 20
+// sessions before day. It also returns the total matching count.
+func (db *DB) GetUnusualVolume(ctx context.Context, day time.Time, minRatio float64, page ScanPage) ([]UnusualVolume, int, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH average AS (
+			SELECT symbol, avg(volume)::float8 AS avg_volume
+			FROM (
+				SELECT symbol, volume,
+					ROW_NUMBER() OVER (PARTITION BY symbol ORDER BY trade_date DESC) AS rn
+				FROM md.bhavcopy
+				WHERE series = 'EQ' AND trade_date < $1::date AND trade_date >= $1::date - 45
+			) b
+			WHERE rn <= 20
+			GROUP BY symbol
+			HAVING count(*) >= 10
+		)
+		SELECT rp.symbol, COALESCE(sc.name, rp.symbol), COALESCE(sc.sector, ''),
+			COALESCE(rp.last_price, 0), rp.change_percent, rp.volume, a.avg_volume,
+			rp.volume / a.avg_volume AS ratio,
+			count(*) OVER () AS total
+		FROM md.realtime_prices rp
+		JOIN average a ON a.symbol = rp.symbol
+		LEFT JOIN md.stock_config sc ON sc.symbol = rp.symbol AND sc.exchange = COALESCE(rp.exchange, 'NSE')
+		WHERE rp.updated_at > NOW() - INTERVAL '1 day'
+			AND rp.volume IS NOT NULL
+			AND a.avg_volume > 0
+			AND rp.volume / a.avg_volume >= $2
+			AND (cardinality($3::text[]) = 0 OR upper(sc.sector) = ANY($3))
+		ORDER BY ratio DESC, rp.symbol
+		LIMIT $4 OFFSET $5
+	`, SessionOpen(day).Format("2006-01-02"), minRatio, pq.Array(upperAll(page.Sectors)), page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query unusual volume: %w", err)
+	}
+	defer rows.Close()
+
+	results := []UnusualVolume{}
+	total := 0
+	for rows.Next() {
+		var u UnusualVolume
+		if err := rows.Scan(&u.Symbol, &u.Name, &u.Sector, &u.LastPrice, &u.ChangePercent,
+			&u.Volume, &u.AvgVolume, &u.Ratio, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan unusual volume: %w", err)
+		}
+		results = append(results, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return results, total, nil
+}
+
+// circuitBands are the NSE price bands, in percent. Bands are inferred from
+// the day's change because no band master is loaded.
+var circuitBands = []float64{2, 5, 10, 20}
+
+// circuitTolerance is how close to a band the change must be, in percent
+// points, to count as locked at it
+const circuitTolerance = 0.1
+
+// Circuit is a symbol trading at its upper or lower price band
+type Circuit struct {
+	Symbol        string  `json:"symbol"`
+	Name          string  `json:"name"`
+	Sector        string  `json:"sector"`
+	Side          string  `json:"side"`
+	BandPct       float64 `json:"band_pct"`
+	LastPrice     float64 `json:"last_price"`
+	PrevClose     float64 `json:"prev_close"`
+	LimitPrice    float64 `json:"limit_price"`
+	ChangePercent float64 `json:"change_percent"`
+	Volume        *int64  `json:"volume"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// GetCircuits lists symbols in md.realtime_prices locked at a price band:
+// the change matches a band and the last price is the day's extreme. side
+// is UPPER, LOWER or empty for both. It also returns the total matching
+// count.
+func (db *DB) GetCircuits(ctx context.Context, side string, page ScanPage) ([]Circuit, int, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT rp.symbol, COALESCE(sc.name, rp.symbol), COALESCE(sc.sector, ''),
+			CASE WHEN rp.change_percent > 0 THEN 'UPPER' ELSE 'LOWER' END AS side,
+			b.band, rp.last_price, rp.close, rp.change_percent, rp.volume,
+			rp.updated_at::text,
+			count(*) OVER () AS total
+		FROM md.realtime_prices rp
+		CROSS JOIN LATERAL (
+			SELECT band FROM unnest($1::float8[]) AS band
+			WHERE abs(abs(rp.change_percent) - band) <= $2
+			ORDER BY band
+			LIMIT 1
+		) b
+		LEFT JOIN md.stock_config sc ON sc.symbol = rp.symbol AND sc.exchange = COALESCE(rp.exchange, 'NSE')
+		WHERE rp.updated_at > NOW() - INTERVAL '1 day'
+			AND rp.close > 0 AND rp.last_price > 0
+			AND ((rp.change_percent > 0 AND rp.last_price >= rp.high)
+				OR (rp.change_percent < 0 AND rp.last_price <= rp.low))
+			AND ($3 = '' OR (CASE WHEN rp.change_percent > 0 THEN 'UPPER' ELSE 'LOWER' END) = $3)
+			AND (cardinality($4::text[]) = 0 OR upper(sc.sector) = ANY($4))
+		ORDER BY b.band DESC, abs(rp.change_percent) DESC, rp.symbol
+		LIMIT $5 OFFSET $6
+	`, pq.Array(circuitBands), circuitTolerance, side, pq.Array(upperAll(page.Sectors)), page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query circuits: %w", err)
+	}
+	defer rows.Close()
+
+	results := []Circuit{}
+	total := 0
+	for rows.Next() {
+		var c Circuit
+		if err := rows.Scan(&c.Symbol, &c.Name, &c.Sector, &c.Side, &c.BandPct, &c.LastPrice, &c.PrevClose,
+			&c.ChangePercent, &c.Volume, &c.UpdatedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan circuit: %w", err)
+		}
+		dir := 1.0
+		if c.Side == "LOWER" {
+			dir = -1
+		}
+		c.LimitPrice = c.PrevClose * (1 + dir*c.BandPct/100)
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return results, total, nil
+}
+
+func upperAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.ToUpper(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,705 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// devSchema creates the tables this service reads but other services own, so
+// a contributor or CI job can run the API against an empty database. Columns
+// mirror what the queries in this package use, not the full upstream schema.
+const devSchema = `
+	CREATE SCHEMA IF NOT EXISTS md;
+	CREATE SCHEMA IF NOT EXISTS intraday;
+	CREATE SCHEMA IF NOT EXISTS news;
+	CREATE SCHEMA IF NOT EXISTS predictions;
+	CREATE SCHEMA IF NOT EXISTS brokers;
+	CREATE SCHEMA IF NOT EXISTS ml;
+
+	CREATE TABLE IF NOT EXISTS md.stock_config (
+		symbol              TEXT NOT NULL,
+		exchange            TEXT NOT NULL DEFAULT 'NSE',
+		name                TEXT,
+		sector              TEXT,
+		market_cap_category TEXT,
+		intraday_enabled    BOOLEAN NOT NULL DEFAULT false,
+		investment_enabled  BOOLEAN NOT NULL DEFAULT false,
+		fetcher             TEXT,
+		active              BOOLEAN NOT NULL DEFAULT true,
+		intraday_ai_picked  BOOLEAN,
+		selection_type      TEXT,
+		created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (symbol, exchange)
+	);
+
+	CREATE TABLE IF NOT EXISTS md.instrument_tokens (
+		instrument_token BIGINT PRIMARY KEY,
+		tradingsymbol    TEXT NOT NULL,
+		exchange         TEXT NOT NULL,
+		lot_size         INT NOT NULL DEFAULT 1
+	);
+
+	CREATE TABLE IF NOT EXISTS md.realtime_prices (
+		symbol           TEXT PRIMARY KEY,
+		exchange         TEXT,
+		instrument_token BIGINT,
+		last_price       DOUBLE PRECISION,
+		volume           BIGINT,
+		open             DOUBLE PRECISION,
+		high             DOUBLE PRECISION,
+		low              DOUBLE PRECISION,
+		close            DOUBLE PRECISION,
+		change_percent   DOUBLE PRECISION,
+		updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS md.ticks (
+		symbol     TEXT NOT NULL,
+		ts         TIMESTAMPTZ NOT NULL,
+		last_price DOUBLE PRECISION NOT NULL,
+		volume     BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (symbol, ts)
+	);
+
+	CREATE TABLE IF NOT EXISTS md.bhavcopy (
+		symbol     TEXT NOT NULL,
+		series     TEXT NOT NULL DEFAULT 'EQ',
+		trade_date DATE NOT NULL,
+		open       DOUBLE PRECISION NOT NULL,
+		high       DOUBLE PRECISION NOT NULL,
+		low        DOUBLE PRECISION NOT NULL,
+		close      DOUBLE PRECISION NOT NULL,
+		volume     BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (symbol, series, trade_date)
+	);
+
+	CREATE TABLE IF NOT EXISTS md.option_iv (
+		symbol     TEXT NOT NULL,
+		trade_date DATE NOT NULL,
+		atm_iv     DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (symbol, trade_date)
+	);
+
+	CREATE TABLE IF NOT EXISTS md.system_config (
+		config_key   TEXT PRIMARY KEY,
+		config_value TEXT,
+		description  TEXT,
+		updated_by   TEXT,
+		updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS md.csv_import_jobs (
+		job_id                  TEXT PRIMARY KEY,
+		filename                TEXT NOT NULL,
+		total_rows              INT NOT NULL DEFAULT 0,
+		processed_rows          INT NOT NULL DEFAULT 0,
+		successful_rows         INT NOT NULL DEFAULT 0,
+		failed_rows             INT NOT NULL DEFAULT 0,
+		status                  TEXT NOT NULL,
+		progress_percentage     DOUBLE PRECISION NOT NULL DEFAULT 0,
+		error_message           TEXT,
+		started_at              TIMESTAMPTZ,
+		completed_at            TIMESTAMPTZ,
+		estimated_completion_at TIMESTAMPTZ
+	);
+
+	CREATE TABLE IF NOT EXISTS intraday.signals (
+		signal_id             TEXT PRIMARY KEY,
+		symbol                TEXT NOT NULL,
+		stock_name            TEXT,
+		sector                TEXT,
+		signal_type           TEXT NOT NULL,
+		confidence_score      DOUBLE PRECISION NOT NULL,
+		entry_price           DOUBLE PRECISION NOT NULL,
+		current_price         DOUBLE PRECISION NOT NULL,
+		stop_loss             DOUBLE PRECISION NOT NULL,
+		target_price          DOUBLE PRECISION NOT NULL,
+		status                TEXT NOT NULL,
+		result                TEXT,
+		generated_at          TIMESTAMPTZ NOT NULL,
+		exit_price            DOUBLE PRECISION,
+		closed_at             TIMESTAMPTZ,
+		actual_profit_pct     DOUBLE PRECISION,
+		prediction_features   JSONB,
+		recent_news_sentiment DOUBLE PRECISION,
+		metadata              JSONB,
+		exit_reason           TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_signals_generated_at ON intraday.signals (generated_at DESC);
+
+	CREATE TABLE IF NOT EXISTS intraday.daily_signal_performance (
+		trade_date         DATE PRIMARY KEY,
+		total_signals      INT NOT NULL DEFAULT 0,
+		successful_signals INT NOT NULL DEFAULT 0,
+		failed_signals     INT NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS news.articles (
+		id              TEXT PRIMARY KEY,
+		title           TEXT,
+		source          TEXT,
+		published_at    TIMESTAMPTZ,
+		url             TEXT,
+		summary         TEXT,
+		sentiment_score DOUBLE PRECISION,
+		sentiment_label TEXT,
+		llm_sentiment   TEXT,
+		llm_confidence  DOUBLE PRECISION
+	);
+	CREATE INDEX IF NOT EXISTS idx_articles_published_at ON news.articles (published_at DESC);
+
+	CREATE TABLE IF NOT EXISTS news.article_entities (
+		article_id TEXT NOT NULL REFERENCES news.articles (id) ON DELETE CASCADE,
+		symbol     TEXT NOT NULL,
+		PRIMARY KEY (article_id, symbol)
+	);
+
+	CREATE TABLE IF NOT EXISTS predictions.daily_predictions (
+		prediction_date      DATE NOT NULL,
+		symbol               TEXT NOT NULL,
+		current_price        DOUBLE PRECISION NOT NULL,
+		predicted_price      DOUBLE PRECISION NOT NULL,
+		predicted_change_pct DOUBLE PRECISION NOT NULL,
+		stop_loss            DOUBLE PRECISION NOT NULL,
+		target               DOUBLE PRECISION NOT NULL,
+		confidence           DOUBLE PRECISION NOT NULL,
+		trend                TEXT NOT NULL,
+		reasoning            TEXT,
+		technical_summary    TEXT,
+		PRIMARY KEY (prediction_date, symbol)
+	);
+
+	CREATE TABLE IF NOT EXISTS brokers.config (
+		id                    SERIAL PRIMARY KEY,
+		broker_name           TEXT NOT NULL UNIQUE,
+		enabled               BOOLEAN NOT NULL DEFAULT false,
+		api_key               TEXT,
+		api_secret            TEXT,
+		access_token          TEXT,
+		user_id               TEXT,
+		token_expires_at      TIMESTAMPTZ,
+		last_authenticated_at TIMESTAMPTZ,
+		created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS ml.model_performance (
+		model_name   TEXT NOT NULL,
+		accuracy     DOUBLE PRECISION NOT NULL,
+		evaluated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+`
+
+// EnsureDevSchema creates the externally owned tables if they are missing.
+// It is only used by the seed command and SEED_DEMO_DATA, never in production.
+func (db *DB) EnsureDevSchema(ctx context.Context) error {
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, devSchema)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dev schema: %w", err)
+	}
+	return nil
+}
+
+// demoStock is one instrument in the demo universe
+type demoStock struct {
+	symbol, name, sector, capCategory string
+	price                             float64
+	token                             int64
+}
+
+var demoStocks = []demoStock{
+	{"RELIANCE", "Reliance Industries", "Energy", "LARGE_CAP", 2900, 738561},
+	{"TCS", "Tata Consultancy Services", "IT", "LARGE_CAP", 3900, 2953217},
+	{"INFY", "Infosys", "IT", "LARGE_CAP", 1550, 408065},
+	{"WIPRO", "Wipro", "IT", "LARGE_CAP", 480, 969473},
+	{"HDFCBANK", "HDFC Bank", "Banking", "LARGE_CAP", 1650, 341249},
+	{"ICICIBANK", "ICICI Bank", "Banking", "LARGE_CAP", 1150, 1270529},
+	{"SBIN", "State Bank of India", "Banking", "LARGE_CAP", 780, 779521},
+	{"KOTAKBANK", "Kotak Mahindra Bank", "Banking", "LARGE_CAP", 1800, 492033},
+	{"AXISBANK", "Axis Bank", "Banking", "LARGE_CAP", 1120, 1510401},
+	{"BAJFINANCE", "Bajaj Finance", "Financial Services", "LARGE_CAP", 7100, 81153},
+	{"ITC", "ITC", "FMCG", "LARGE_CAP", 440, 424961},
+	{"HINDUNILVR", "Hindustan Unilever", "FMCG", "LARGE_CAP", 2450, 356865},
+	{"BHARTIARTL", "Bharti Airtel", "Telecom", "LARGE_CAP", 1350, 2714625},
+	{"LT", "Larsen & Toubro", "Infrastructure", "LARGE_CAP", 3500, 2939649},
+	{"MARUTI", "Maruti Suzuki", "Auto", "LARGE_CAP", 12000, 2815745},
+	{"TATAMOTORS", "Tata Motors", "Auto", "LARGE_CAP", 950, 884737},
+	{"SUNPHARMA", "Sun Pharmaceutical", "Pharma", "LARGE_CAP", 1600, 857857},
+	{"DRREDDY", "Dr. Reddy's Laboratories", "Pharma", "LARGE_CAP", 6200, 225537},
+	{"TATASTEEL", "Tata Steel", "Metals", "LARGE_CAP", 150, 895745},
+	{"IRCTC", "Indian Railway Catering", "Consumer Services", "MID_CAP", 900, 3484417},
+}
+
+// demoIndices are seeded into realtime prices, instrument tokens and history
+var demoIndices = []demoStock{
+	{"NIFTY 50", "NIFTY 50", "", "", 24500, 256265},
+	{"NIFTY BANK", "NIFTY BANK", "", "", 52000, 260105},
+}
+
+// demoHistoryDays is how many trading days of daily bars are generated
+const demoHistoryDays = 400
+
+// SeedDemoData inserts a deterministic, realistic demo data set: stock
+// configs, instruments, daily bars, one session of ticks, live prices,
+// signals, news, predictions and configs. Without force it does nothing if
+// stock configs already exist.
+func (db *DB) SeedDemoData(ctx context.Context, force bool) error {
+	if !force {
+		var existing int
+		if err := db.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM md.stock_config").Scan(&existing); err != nil {
+			return fmt.Errorf("failed to check existing data: %w", err)
+		}
+		if existing > 0 {
+			log.Printf("⚠️  Skipping demo seed: md.stock_config already has %d rows", existing)
+			return nil
+		}
+	}
+
+	s := &seeder{rng: rand.New(rand.NewSource(42)), now: time.Now().In(istLocation())}
+	err := db.maintenance(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin seed: %w", err)
+		}
+		defer tx.Rollback()
+
+		s.tx = tx
+		steps := []struct {
+			name string
+			fn   func(context.Context) error
+		}{
+			{"stock configs", s.stockConfigs},
+			{"daily bars", s.dailyBars},
+			{"ticks and prices", s.session},
+			{"signals", s.signals},
+			{"news", s.news},
+			{"predictions", s.predictions},
+			{"configs", s.configs},
+		}
+		for _, step := range steps {
+			if err := step.fn(ctx); err != nil {
+				return fmt.Errorf("failed to seed %s: %w", step.name, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit seed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Seeded demo data: %d stocks, %d trading days, session %s",
+		len(demoStocks), demoHistoryDays, s.sessionDate().Format("2006-01-02"))
+	return nil
+}
+
+// seeder holds state shared between seeding steps
+type seeder struct {
+	tx  *sql.Tx
+	rng *rand.Rand
+	now time.Time
+	// closes holds the generated daily closes per symbol, oldest first
+	closes map[string][]float64
+	days   []time.Time
+	// last holds the latest session price per symbol
+	last map[string]float64
+}
+
+// tradingDays returns n weekdays ending on the most recent weekday, oldest first
+func tradingDays(end time.Time, n int) []time.Time {
+	day := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	days := make([]time.Time, 0, n)
+	for len(days) < n {
+		if wd := day.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			days = append(days, day)
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	for i, j := 0, len(days)-1; i < j; i, j = i+1, j-1 {
+		days[i], days[j] = days[j], days[i]
+	}
+	return days
+}
+
+// sessionDate is the trading day the tick session and live prices belong to
+func (s *seeder) sessionDate() time.Time {
+	return s.days[len(s.days)-1]
+}
+
+func (s *seeder) stockConfigs(ctx context.Context) error {
+	fetchers := []string{"ZERODHA", "INDMONEY"}
+	for i, st := range demoStocks {
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO md.stock_config (symbol, exchange, name, sector, market_cap_category,
+				intraday_enabled, investment_enabled, fetcher, active, selection_type)
+			VALUES ($1, 'NSE', $2, $3, $4, true, $5, $6, true, $7)
+			ON CONFLICT (symbol, exchange) DO NOTHING
+		`, st.symbol, st.name, st.sector, st.capCategory, i%3 != 2, fetchers[i%2], nullIf(i%5 == 0, "MORNING_ML")); err != nil {
+			return err
+		}
+	}
+	for _, st := range append(append([]demoStock{}, demoStocks...), demoIndices...) {
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO md.instrument_tokens (instrument_token, tradingsymbol, exchange, lot_size)
+			VALUES ($1, $2, 'NSE', 1)
+			ON CONFLICT (instrument_token) DO NOTHING
+		`, st.token, st.symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dailyBars writes a geometric random walk per symbol into md.bhavcopy and
+// index history for NIFTY 50 and its TRI
+func (s *seeder) dailyBars(ctx context.Context) error {
+	s.days = tradingDays(s.now, demoHistoryDays)
+	s.closes = map[string][]float64{}
+
+	stmt, err := s.tx.PrepareContext(ctx, `
+		INSERT INTO md.bhavcopy (symbol, series, trade_date, open, high, low, close, volume)
+		VALUES ($1, 'EQ', $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (symbol, series, trade_date) DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, st := range demoStocks {
+		// Walk backwards from today's reference price so recent prices look familiar
+		closes := make([]float64, len(s.days))
+		price := st.price
+		for i := len(s.days) - 1; i >= 0; i-- {
+			closes[i] = round2(price)
+			price /= math.Exp(0.0004 + 0.016*s.rng.NormFloat64())
+		}
+		s.closes[st.symbol] = closes
+
+		prev := closes[0]
+		for i, day := range s.days {
+			close := closes[i]
+			open := round2(prev * (1 + 0.004*s.rng.NormFloat64()))
+			high := round2(math.Max(open, close) * (1 + 0.006*s.rng.Float64()))
+			low := round2(math.Min(open, close) * (1 - 0.006*s.rng.Float64()))
+			volume := int64(2e5 + s.rng.Float64()*3e6)
+			if _, err := stmt.ExecContext(ctx, st.symbol, day, open, high, low, close, volume); err != nil {
+				return err
+			}
+			prev = close
+		}
+	}
+
+	// Index history is the equal-weighted average of the demo universe
+	idx, err := s.tx.PrepareContext(ctx, `
+		INSERT INTO md.index_history (index_name, trade_date, close)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	for i, day := range s.days {
+		var ratio float64
+		for _, st := range demoStocks {
+			ratio += s.closes[st.symbol][i] / st.price
+		}
+		level := round2(demoIndices[0].price * ratio / float64(len(demoStocks)))
+		// TRI compounds an assumed 1.3% dividend yield
+		tri := round2(level * math.Pow(1.013, float64(i-len(s.days)+1)/250) * 1.38)
+		if _, err := idx.ExecContext(ctx, "NIFTY 50", day, level); err != nil {
+			return err
+		}
+		if _, err := idx.ExecContext(ctx, "NIFTY 50 TRI", day, tri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// session writes one trading session of per-minute ticks for the latest
+// trading day and derives live prices from it
+func (s *seeder) session(ctx context.Context) error {
+	day := s.sessionDate()
+	open := day.Add(9*time.Hour + 15*time.Minute)
+	minutes := 375
+	// A session still in progress stops at the current time
+	if elapsed := int(s.now.Sub(open).Minutes()); elapsed < minutes {
+		minutes = elapsed
+	}
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	stmt, err := s.tx.PrepareContext(ctx, `
+		INSERT INTO md.ticks (symbol, ts, last_price, volume)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (symbol, ts) DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	s.last = map[string]float64{}
+	for _, st := range demoStocks {
+		closes := s.closes[st.symbol]
+		prevClose := closes[len(closes)-2]
+		price := prevClose * (1 + 0.004*s.rng.NormFloat64())
+		dayOpen, high, low := round2(price), price, price
+		var volume int64
+		for m := 0; m < minutes; m++ {
+			price *= math.Exp(0.0012 * s.rng.NormFloat64())
+			high, low = math.Max(high, price), math.Min(low, price)
+			volume += int64(500 + s.rng.Intn(8000))
+			if _, err := stmt.ExecContext(ctx, st.symbol, open.Add(time.Duration(m)*time.Minute), round2(price), volume); err != nil {
+				return err
+			}
+		}
+		s.last[st.symbol] = round2(price)
+
+		change := (price - prevClose) / prevClose * 100
+		if err := s.upsertPrice(ctx, st, price, volume, dayOpen, high, low, prevClose, change); err != nil {
+			return err
+		}
+	}
+
+	for _, ix := range demoIndices {
+		change := 0.6 * s.rng.NormFloat64()
+		price := ix.price * (1 + change/100)
+		if err := s.upsertPrice(ctx, ix, price, 0, ix.price, math.Max(price, ix.price), math.Min(price, ix.price), ix.price, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) upsertPrice(ctx context.Context, st demoStock, price float64, volume int64, open, high, low, prevClose, change float64) error {
+	_, err := s.tx.ExecContext(ctx, `
+		INSERT INTO md.realtime_prices (symbol, exchange, instrument_token, last_price, volume,
+			open, high, low, close, change_percent, updated_at)
+		VALUES ($1, 'NSE', $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (symbol) DO UPDATE SET
+			last_price = EXCLUDED.last_price, volume = EXCLUDED.volume, open = EXCLUDED.open,
+			high = EXCLUDED.high, low = EXCLUDED.low, close = EXCLUDED.close,
+			change_percent = EXCLUDED.change_percent, updated_at = NOW()
+	`, st.symbol, st.token, round2(price), volume, round2(open), round2(high), round2(low), round2(prevClose), round2(change))
+	return err
+}
+
+// signals writes a week of closed signals plus today's active ones, and the
+// matching daily performance rows
+func (s *seeder) signals(ctx context.Context) error {
+	closedStatuses := []string{"HIT_TARGET", "HIT_TARGET", "HIT_STOPLOSS", "TIME_EXIT", "TRAILING_STOP"}
+	days := s.days[len(s.days)-7:]
+	for d, day := range days {
+		isToday := d == len(days)-1
+		count := 6 + s.rng.Intn(5)
+		wins, losses := 0, 0
+		for n := 0; n < count; n++ {
+			st := demoStocks[s.rng.Intn(len(demoStocks))]
+			entry := s.closes[st.symbol][len(s.days)-len(days)+d]
+			signalType := "BUY"
+			dir := 1.0
+			if s.rng.Float64() < 0.3 {
+				signalType, dir = "SELL", -1.0
+			}
+			target := round2(entry * (1 + dir*0.015))
+			stop := round2(entry * (1 - dir*0.008))
+			confidence := round2(0.55 + 0.4*s.rng.Float64())
+			generatedAt := day.Add(9*time.Hour + 20*time.Minute + time.Duration(s.rng.Intn(300))*time.Minute)
+			if generatedAt.After(s.now) {
+				generatedAt = s.now.Add(-time.Duration(1+s.rng.Intn(60)) * time.Minute)
+			}
+
+			status, result := "ACTIVE", sql.NullString{}
+			current := entry
+			var exitPrice, profit sql.NullFloat64
+			var closedAt sql.NullTime
+			var exitReason sql.NullString
+			if !isToday || n >= 4 {
+				status = closedStatuses[s.rng.Intn(len(closedStatuses))]
+				exit := entry * (1 + dir*(s.rng.Float64()*0.02-0.006))
+				switch status {
+				case "HIT_TARGET":
+					exit = target
+				case "HIT_STOPLOSS":
+					exit = stop
+				}
+				pct := dir * (exit - entry) / entry * 100
+				exitPrice = sql.NullFloat64{Float64: round2(exit), Valid: true}
+				profit = sql.NullFloat64{Float64: round2(pct), Valid: true}
+				closedAt = sql.NullTime{Time: generatedAt.Add(time.Duration(20+s.rng.Intn(180)) * time.Minute), Valid: true}
+				exitReason = sql.NullString{String: status, Valid: true}
+				current = round2(exit)
+				if pct > 0 {
+					result = sql.NullString{String: "HIT", Valid: true}
+					wins++
+				} else {
+					result = sql.NullString{String: "MISS", Valid: true}
+					losses++
+				}
+			} else if last, ok := s.last[st.symbol]; ok {
+				current = last
+			}
+
+			features, _ := json.Marshal(map[string]float64{
+				"rsi_14": round2(30 + 40*s.rng.Float64()), "volume_ratio": round2(0.8 + 1.5*s.rng.Float64()),
+			})
+			metadata, _ := json.Marshal(map[string]string{"strategy": "demo_momentum", "source": "seed"})
+			if _, err := s.tx.ExecContext(ctx, `
+				INSERT INTO intraday.signals (signal_id, symbol, stock_name, sector, signal_type, confidence_score,
+					entry_price, current_price, stop_loss, target_price, status, result, generated_at, exit_price,
+					closed_at, actual_profit_pct, prediction_features, recent_news_sentiment, metadata, exit_reason)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+				ON CONFLICT (signal_id) DO NOTHING
+			`, fmt.Sprintf("demo-%s-%02d", day.Format("20060102"), n), st.symbol, st.name, st.sector, signalType,
+				confidence, entry, current, stop, target, status, result, generatedAt, exitPrice, closedAt, profit,
+				features, round2(0.3+0.5*s.rng.Float64()), metadata, exitReason); err != nil {
+				return err
+			}
+		}
+
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO intraday.daily_signal_performance (trade_date, total_signals, successful_signals, failed_signals)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (trade_date) DO NOTHING
+		`, day, count, wins, losses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) news(ctx context.Context) error {
+	headlines := []struct {
+		title, label string
+	}{
+		{"%s beats quarterly estimates on strong margins", "positive"},
+		{"%s announces capacity expansion plan", "positive"},
+		{"Brokerages raise target price on %s", "positive"},
+		{"%s shares slip as input costs rise", "negative"},
+		{"Regulator seeks clarification from %s", "negative"},
+		{"%s to consider dividend at board meeting", "neutral"},
+		{"%s management reiterates full-year guidance", "neutral"},
+	}
+	sources := []string{"Economic Times", "Moneycontrol", "Business Standard", "Mint", "Reuters"}
+
+	for n := 0; n < 40; n++ {
+		st := demoStocks[s.rng.Intn(len(demoStocks))]
+		h := headlines[s.rng.Intn(len(headlines))]
+		score := 0.5
+		switch h.label {
+		case "positive":
+			score = 0.65 + 0.3*s.rng.Float64()
+		case "negative":
+			score = 0.05 + 0.3*s.rng.Float64()
+		}
+		id := fmt.Sprintf("demo-news-%03d", n)
+		title := fmt.Sprintf(h.title, st.name)
+		publishedAt := s.now.Add(-time.Duration(n*90+s.rng.Intn(60)) * time.Minute)
+
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO news.articles (id, title, source, published_at, url, summary, sentiment_score,
+				sentiment_label, llm_sentiment, llm_confidence)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9)
+			ON CONFLICT (id) DO NOTHING
+		`, id, title, sources[s.rng.Intn(len(sources))], publishedAt,
+			fmt.Sprintf("https://example.com/news/%s", id),
+			fmt.Sprintf("%s. Demo article generated for local development.", title),
+			round2(score), h.label, round2(0.6+0.35*s.rng.Float64())); err != nil {
+			return err
+		}
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO news.article_entities (article_id, symbol) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, id, st.symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) predictions(ctx context.Context) error {
+	today := time.Date(s.now.Year(), s.now.Month(), s.now.Day(), 0, 0, 0, 0, s.now.Location())
+	for _, st := range demoStocks {
+		closes := s.closes[st.symbol]
+		current := closes[len(closes)-1]
+		change := round2(2.5 * s.rng.NormFloat64())
+		trend := "bullish"
+		if change < 0 {
+			trend = "bearish"
+		}
+		predicted := round2(current * (1 + change/100))
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO predictions.daily_predictions (prediction_date, symbol, current_price, predicted_price,
+				predicted_change_pct, stop_loss, target, confidence, trend, reasoning, technical_summary)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (prediction_date, symbol) DO NOTHING
+		`, today, st.symbol, current, predicted, change, round2(current*(1-math.Copysign(0.02, change))),
+			predicted, round2(0.5+0.4*s.rng.Float64()), trend,
+			"Demo prediction generated by the seed command", "RSI neutral, price above 20 DMA"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) configs(ctx context.Context) error {
+	configs := [][3]string{
+		{"smart_stock_selection_enabled", "true", "Enable ML-based stock selection"},
+		{"smart_selection_stock_count", "200", "Number of stocks to select in Smart Mode (split equally between fetchers)"},
+	}
+	for _, c := range configs {
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO md.system_config (config_key, config_value, description, updated_by)
+			VALUES ($1, $2, $3, 'seed')
+			ON CONFLICT (config_key) DO NOTHING
+		`, c[0], c[1], c[2]); err != nil {
+			return err
+		}
+	}
+	for _, broker := range []string{"zerodha", "indmoney"} {
+		if _, err := s.tx.ExecContext(ctx, `
+			INSERT INTO brokers.config (broker_name, enabled, api_key, api_secret)
+			VALUES ($1, false, 'demo_api_key', 'demo_api_secret')
+			ON CONFLICT (broker_name) DO NOTHING
+		`, broker); err != nil {
+			return err
+		}
+	}
+	if _, err := s.tx.ExecContext(ctx, `
+		INSERT INTO md.corporate_actions (symbol, action_type, amount, ex_date, record_date)
+		VALUES ('ITC', 'DIVIDEND', 6.25, $1, $1), ('INFY', 'DIVIDEND', 18, $2, $2)
+		ON CONFLICT DO NOTHING
+	`, s.days[len(s.days)-60], s.now.AddDate(0, 0, 10)); err != nil {
+		return err
+	}
+	if _, err := s.tx.ExecContext(ctx, `
+		INSERT INTO md.corporate_actions (symbol, action_type, purpose, ex_date)
+		VALUES ('TCS', 'RESULTS', 'Financial Results', $1), ('HDFCBANK', 'RESULTS', 'Financial Results', $2)
+		ON CONFLICT DO NOTHING
+	`, s.now.AddDate(0, 0, 3), s.now.AddDate(0, 0, 8)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// nullIf returns value when ok, otherwise NULL
+func nullIf(ok bool, value string) sql.NullString {
+	return sql.NullString{String: value, Valid: ok}
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SentimentTask is a queued article awaiting a sentiment score
+type SentimentTask struct {
+	ArticleID string
+	Title     string
+	Summary   string
+	Attempts  int
+}
+
+// SentimentScore is a scoring backend's verdict on one article. Label is
+// positive, negative or neutral.
+type SentimentScore struct {
+	ArticleID  string
+	Label      string
+	Confidence float64
+}
+
+// SentimentQueueStats describes the scoring queue
+type SentimentQueueStats struct {
+	Depth int `json:"depth"`
+	// Ready can be claimed now; the rest are backing off after a failure
+	Ready int `json:"ready"`
+	// Exhausted ran out of attempts and are no longer retried
+	Exhausted  int     `json:"exhausted"`
+	OldestItem *string `json:"oldest_enqueued_at"`
+}
+
+// EnqueueUnscoredArticles queues articles published since without an LLM
+// sentiment, returning how many were added. Tasks whose article has since
+// been deleted are dropped.
+func (db *DB) EnqueueUnscoredArticles(ctx context.Context, since time.Time) (int, error) {
+	if _, err := db.conn.ExecContext(ctx, `
+		DELETE FROM core_api.sentiment_queue q
+		WHERE NOT EXISTS (SELECT 1 FROM news.articles a WHERE a.id = q.article_id)
+	`); err != nil {
+		return 0, fmt.Errorf("failed to prune sentiment queue: %w", err)
+	}
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.sentiment_queue (article_id)
+		SELECT a.id
+		FROM news.articles a
+		WHERE a.llm_sentiment IS NULL AND a.published_at >= $1
+		ON CONFLICT (article_id) DO NOTHING
+	`, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue articles: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ClaimSentimentTasks takes up to limit ready tasks with fewer than
+// maxAttempts attempts. Claimed tasks are pushed back by lease so another
+// worker doesn't take them while they're being scored.
+func (db *DB) ClaimSentimentTasks(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]SentimentTask, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH claimed AS (
+			SELECT article_id
+			FROM core_api.sentiment_queue
+			WHERE next_attempt_at <= NOW() AND attempts < $2
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE core_api.sentiment_queue q
+		SET attempts = q.attempts + 1, next_attempt_at = NOW() + $3::int * INTERVAL '1 second'
+		FROM claimed
+		JOIN news.articles a ON a.id = claimed.article_id
+		WHERE q.article_id = claimed.article_id
+		RETURNING q.article_id, COALESCE(a.title, ''), COALESCE(a.summary, ''), q.attempts
+	`, limit, maxAttempts, int(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sentiment tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []SentimentTask{}
+	for rows.Next() {
+		var t SentimentTask
+		if err := rows.Scan(&t.ArticleID, &t.Title, &t.Summary, &t.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan sentiment task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return tasks, nil
+}
+
+// SaveSentimentScores writes scores back to news.articles and removes their
+// tasks. The display sentiment is only filled in where nothing set it.
+func (db *DB) SaveSentimentScores(ctx context.Context, scores []SentimentScore) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range scores {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE news.articles
+			SET llm_sentiment = $2, llm_confidence = $3,
+			    sentiment_label = COALESCE(sentiment_label, $2),
+			    sentiment_score = COALESCE(sentiment_score, $3)
+			WHERE id = $1
+		`, s.ArticleID, s.Label, s.Confidence); err != nil {
+			return fmt.Errorf("failed to save sentiment for %s: %w", s.ArticleID, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM core_api.sentiment_queue WHERE article_id = $1", s.ArticleID,
+		); err != nil {
+			return fmt.Errorf("failed to dequeue %s: %w", s.ArticleID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sentiment scores: %w", err)
+	}
+	return nil
+}
+
+// FailSentimentTasks records a failed attempt, retrying after backoff
+func (db *DB) FailSentimentTasks(ctx context.Context, articleIDs []string, backoff time.Duration, cause error) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.sentiment_queue
+		SET next_attempt_at = NOW() + $2::int * INTERVAL '1 second', last_error = $3
+		WHERE article_id = ANY($1)
+	`, pq.Array(articleIDs), int(backoff.Seconds()), cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to record sentiment failure: %w", err)
+	}
+	return nil
+}
+
+// GetSentimentQueueStats summarises the queue
+func (db *DB) GetSentimentQueueStats(ctx context.Context, maxAttempts int) (*SentimentQueueStats, error) {
+	var s SentimentQueueStats
+	var oldest sql.NullTime
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE attempts < $1),
+			COUNT(*) FILTER (WHERE attempts < $1 AND next_attempt_at <= NOW()),
+			COUNT(*) FILTER (WHERE attempts >= $1),
+			MIN(enqueued_at) FILTER (WHERE attempts < $1)
+		FROM core_api.sentiment_queue
+	`, maxAttempts).Scan(&s.Depth, &s.Ready, &s.Exhausted, &oldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sentiment queue: %w", err)
+	}
+	if oldest.Valid {
+		t := oldest.Time.Format(time.RFC3339)
+		s.OldestItem = &t
+	}
+	return &s, nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ShareLink is a public read-only link to a signal, a dashboard snapshot or
+// a daily report. Signals are served live from Target; the other kinds are
+// frozen in Snapshot when the link is created. Links belong to an account;
+// UserID is the member who created one.
+type ShareLink struct {
+	ID        int64           `json:"id"`
+	AccountID string          `json:"account_id"`
+	UserID    string          `json:"user_id"`
+	Kind      string          `json:"kind"`
+	Target    string          `json:"target,omitempty"`
+	Snapshot  json.RawMessage `json:"-"`
+	Views     int             `json:"views"`
+	ExpiresAt string          `json:"expires_at"`
+	CreatedAt string          `json:"created_at"`
+
+	expires time.Time
+}
+
+// Expires returns ExpiresAt as a time
+func (l ShareLink) Expires() time.Time {
+	return l.expires
+}
+
+// NewShareLink builds an unsaved link expiring at expires
+func NewShareLink(accountID, userID, kind, target string, snapshot json.RawMessage, expires time.Time) ShareLink {
+	return ShareLink{AccountID: accountID, UserID: userID, Kind: kind, Target: target, Snapshot: snapshot, expires: expires}
+}
+
+const shareLinkColumns = `id, account_id, user_id, kind, target, snapshot, views, expires_at, created_at`
+
+func scanShareLink(row rowScanner) (*ShareLink, error) {
+	var l ShareLink
+	var snapshot []byte
+	var createdAt time.Time
+	if err := row.Scan(&l.ID, &l.AccountID, &l.UserID, &l.Kind, &l.Target, &snapshot, &l.Views, &l.expires, &createdAt); err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		l.Snapshot = json.RawMessage(snapshot)
+	}
+	l.ExpiresAt = l.expires.Format(time.RFC3339)
+	l.CreatedAt = createdAt.Format(time.RFC3339)
+	return &l, nil
+}
+
+// CreateShareLink stores a link, returning it with its ID
+func (db *DB) CreateShareLink(ctx context.Context, l ShareLink) (*ShareLink, error) {
+	var snapshot interface{}
+	if l.Snapshot != nil {
+		snapshot = []byte(l.Snapshot)
+	}
+	saved, err := scanShareLink(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.share_links (account_id, user_id, kind, target, snapshot, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+shareLinkColumns,
+		l.AccountID, l.UserID, l.Kind, l.Target, snapshot, l.expires))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return saved, nil
+}
+
+// GetShareLink returns a link by ID, or nil if it doesn't exist
+func (db *DB) GetShareLink(ctx context.Context, id int64) (*ShareLink, error) {
+	l, err := scanShareLink(db.conn.QueryRowContext(ctx,
+		"SELECT "+shareLinkColumns+" FROM core_api.share_links WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return l, nil
+}
+
+// ListShareLinks returns an account's unexpired links, newest first
+func (db *DB) ListShareLinks(ctx context.Context, accountID string) ([]ShareLink, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+shareLinkColumns+`
+		FROM core_api.share_links
+		WHERE account_id = $1 AND expires_at > NOW()
+		ORDER BY created_at DESC, id DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []ShareLink{}
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, *l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return links, nil
+}
+
+// DeleteShareLink revokes one of an account's links
+func (db *DB) DeleteShareLink(ctx context.Context, accountID string, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.share_links WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete share link: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordShareView counts a view of a link
+func (db *DB) RecordShareView(ctx context.Context, id int64) error {
+	if _, err := db.conn.ExecContext(ctx,
+		"UPDATE core_api.share_links SET views = views + 1 WHERE id = $1", id,
+	); err != nil {
+		return fmt.Errorf("failed to record share view: %w", err)
+	}
+	return nil
+}
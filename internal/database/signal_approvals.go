@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Approval statuses
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// SignalApproval is the review of a signal whose suggested size crossed
+// the approval threshold. The execution layer only acts on it once
+// approved; pending reviews expire at ExpiresAt.
+type SignalApproval struct {
+	SignalID    string  `json:"signal_id"`
+	Symbol      string  `json:"symbol"`
+	SignalType  string  `json:"signal_type"`
+	EntryPrice  float64 `json:"entry_price"`
+	Notional    float64 `json:"notional"`
+	Threshold   float64 `json:"threshold"`
+	Status      string  `json:"status"`
+	RequestedAt string  `json:"requested_at"`
+	ExpiresAt   string  `json:"expires_at"`
+	DecidedBy   *string `json:"decided_by"`
+	DecidedAt   *string `json:"decided_at"`
+	Note        *string `json:"note"`
+}
+
+// signalApprovalColumns are selected from core_api.signal_approvals a
+// joined with intraday.signals s
+const signalApprovalColumns = `a.signal_id, COALESCE(s.symbol, ''), COALESCE(s.signal_type, ''),
+	COALESCE(s.entry_price, 0)::float8, a.notional::float8, a.threshold::float8, a.status,
+	a.requested_at, a.expires_at, a.decided_by, a.decided_at, a.note`
+
+func scanSignalApproval(row rowScanner) (*SignalApproval, error) {
+	var a SignalApproval
+	var requestedAt, expiresAt time.Time
+	var decidedAt sql.NullTime
+	if err := row.Scan(&a.SignalID, &a.Symbol, &a.SignalType, &a.EntryPrice, &a.Notional, &a.Threshold,
+		&a.Status, &requestedAt, &expiresAt, &a.DecidedBy, &decidedAt, &a.Note); err != nil {
+		return nil, err
+	}
+	a.RequestedAt = requestedAt.Format(time.RFC3339)
+	a.ExpiresAt = expiresAt.Format(time.RFC3339)
+	if decidedAt.Valid {
+		v := decidedAt.Time.Format(time.RFC3339)
+		a.DecidedAt = &v
+	}
+	return &a, nil
+}
+
+// RequestSignalApproval records a pending review for a signal, reporting
+// whether one didn't already exist
+func (db *DB) RequestSignalApproval(ctx context.Context, signalID string, notional, threshold float64, expiresAt time.Time) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.signal_approvals (signal_id, notional, threshold, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (signal_id) DO NOTHING
+	`, signalID, notional, threshold, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to request signal approval: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListSignalApprovals returns reviews with status (all when empty), newest
+// first
+func (db *DB) ListSignalApprovals(ctx context.Context, status string, limit int) ([]SignalApproval, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalApprovalColumns+`
+		FROM core_api.signal_approvals a
+		LEFT JOIN intraday.signals s ON s.signal_id = a.signal_id
+		WHERE $1 = '' OR a.status = $1
+		ORDER BY a.requested_at DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []SignalApproval{}
+	for rows.Next() {
+		a, err := scanSignalApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal approval: %w", err)
+		}
+		approvals = append(approvals, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return approvals, nil
+}
+
+// GetSignalApproval returns a signal's review, or nil if it didn't need one
+func (db *DB) GetSignalApproval(ctx context.Context, signalID string) (*SignalApproval, error) {
+	a, err := scanSignalApproval(db.conn.QueryRowContext(ctx, `
+		SELECT `+signalApprovalColumns+`
+		FROM core_api.signal_approvals a
+		LEFT JOIN intraday.signals s ON s.signal_id = a.signal_id
+		WHERE a.signal_id = $1
+	`, signalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal approval: %w", err)
+	}
+	return a, nil
+}
+
+// DecideSignalApproval approves or rejects a pending review that hasn't
+// expired, returning nil when there is no such review
+func (db *DB) DecideSignalApproval(ctx context.Context, signalID, status, decidedBy, note string) (*SignalApproval, error) {
+	a, err := scanSignalApproval(db.conn.QueryRowContext(ctx, `
+		WITH a AS (
+			UPDATE core_api.signal_approvals
+			SET status = $2, decided_by = $3, decided_at = NOW(), note = NULLIF($4, '')
+			WHERE signal_id = $1 AND status = 'pending' AND expires_at > NOW()
+			RETURNING *
+		)
+		SELECT `+signalApprovalColumns+`
+		FROM a
+		LEFT JOIN intraday.signals s ON s.signal_id = a.signal_id
+	`, signalID, status, decidedBy, note))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide signal approval: %w", err)
+	}
+	return a, nil
+}
+
+// ExpireSignalApprovals marks pending reviews past their deadline as
+// expired and returns them
+func (db *DB) ExpireSignalApprovals(ctx context.Context) ([]SignalApproval, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		WITH a AS (
+			UPDATE core_api.signal_approvals
+			SET status = 'expired', decided_at = NOW()
+			WHERE status = 'pending' AND expires_at <= NOW()
+			RETURNING *
+		)
+		SELECT `+signalApprovalColumns+`
+		FROM a
+		LEFT JOIN intraday.signals s ON s.signal_id = a.signal_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire signal approvals: %w", err)
+	}
+	defer rows.Close()
+
+	expired := []SignalApproval{}
+	for rows.Next() {
+		a, err := scanSignalApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal approval: %w", err)
+		}
+		expired = append(expired, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return expired, nil
+}
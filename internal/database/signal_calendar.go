@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SignalCalendarDay summarises the signals generated on one IST calendar
+// day. Outcomes use the dashboard's hit and miss rules; NetPnLPct sums
+// actual_profit_pct over the day's closed signals, i.e. the return of
+// trading each signal with the same capital.
+type SignalCalendarDay struct {
+	Date       string `json:"date"`
+	TradingDay bool   `json:"trading_day"`
+	// Holiday names the NSE holiday, if the market was shut
+	Holiday   *string  `json:"holiday,omitempty"`
+	Signals   int      `json:"signals"`
+	Closed    int      `json:"closed"`
+	Hits      int      `json:"hits"`
+	Misses    int      `json:"misses"`
+	WinRate   *float64 `json:"win_rate"`
+	NetPnLPct float64  `json:"net_pnl_pct"`
+}
+
+// SignalCalendar is a month of SignalCalendarDay, every calendar day
+// included so clients can lay out a grid, with the month's totals
+type SignalCalendar struct {
+	Month       string              `json:"month"`
+	Days        []SignalCalendarDay `json:"days"`
+	TradingDays int                 `json:"trading_days"`
+	Signals     int                 `json:"signals"`
+	Hits        int                 `json:"hits"`
+	Misses      int                 `json:"misses"`
+	WinRate     *float64            `json:"win_rate"`
+	NetPnLPct   float64             `json:"net_pnl_pct"`
+	// BestDay and WorstDay are the dates with the highest and lowest net
+	// P&L among days with closed signals
+	BestDay  *string `json:"best_day"`
+	WorstDay *string `json:"worst_day"`
+}
+
+// GetSignalCalendar returns per-day signal counts, win rate and net P&L for
+// the month containing month, in IST
+func (db *DB) GetSignalCalendar(ctx context.Context, month time.Time) (*SignalCalendar, error) {
+	loc := istLocation()
+	m := month.In(loc)
+	start := time.Date(m.Year(), m.Month(), 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 1, 0)
+
+	days := map[string]*SignalCalendarDay{}
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			(generated_at AT TIME ZONE 'Asia/Kolkata')::date AS day,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE result IS NOT NULL),
+			COUNT(*) FILTER (WHERE result = 'HIT'),
+			COUNT(*) FILTER (WHERE result = 'MISS'),
+			COALESCE(SUM(actual_profit_pct) FILTER (WHERE result IS NOT NULL), 0)::float8
+		FROM intraday.signals
+		WHERE generated_at >= $1 AND generated_at < $2
+		GROUP BY day
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal calendar: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day time.Time
+		var d SignalCalendarDay
+		if err := rows.Scan(&day, &d.Signals, &d.Closed, &d.Hits, &d.Misses, &d.NetPnLPct); err != nil {
+			return nil, fmt.Errorf("failed to scan signal calendar day: %w", err)
+		}
+		d.Date = day.Format("2006-01-02")
+		days[d.Date] = &d
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	holidays, err := db.GetMarketHolidays(ctx, start, end.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	holidayNames := map[string]string{}
+	for _, h := range holidays {
+		if h.Exchange == "NSE" {
+			holidayNames[h.Date] = h.Description
+		}
+	}
+
+	cal := &SignalCalendar{Month: start.Format("2006-01"), Days: []SignalCalendarDay{}}
+	var best, worst *SignalCalendarDay
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		d := days[date]
+		if d == nil {
+			d = &SignalCalendarDay{Date: date}
+		}
+		if name, ok := holidayNames[date]; ok {
+			d.Holiday = &name
+		}
+		d.TradingDay = d.Holiday == nil && day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+		d.WinRate = winRate(d.Hits, d.Closed)
+		d.NetPnLPct = round2(d.NetPnLPct)
+
+		if d.TradingDay {
+			cal.TradingDays++
+		}
+		cal.Signals += d.Signals
+		cal.Hits += d.Hits
+		cal.Misses += d.Misses
+		cal.NetPnLPct += d.NetPnLPct
+		if d.Closed > 0 {
+			if best == nil || d.NetPnLPct > best.NetPnLPct {
+				best = d
+			}
+			if worst == nil || d.NetPnLPct < worst.NetPnLPct {
+				worst = d
+			}
+		}
+		cal.Days = append(cal.Days, *d)
+	}
+
+	closed := 0
+	for _, d := range cal.Days {
+		closed += d.Closed
+	}
+	cal.WinRate = winRate(cal.Hits, closed)
+	cal.NetPnLPct = round2(cal.NetPnLPct)
+	if best != nil {
+		cal.BestDay = &best.Date
+		cal.WorstDay = &worst.Date
+	}
+	return cal, nil
+}
+
+// winRate is hits as a percentage of closed signals, or nil with none closed
+func winRate(hits, closed int) *float64 {
+	if closed == 0 {
+		return nil
+	}
+	r := round2(float64(hits) / float64(closed) * 100)
+	return &r
+}
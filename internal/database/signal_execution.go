@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// Time-in-force values for a signal
+const (
+	// TimeInForceDay squares off at the end of the session
+	TimeInForceDay = "DAY"
+	// TimeInForceGTD stays active until ExpiresAt
+	TimeInForceGTD = "GTD"
+	// TimeInForceGTC stays active until a target or stop is hit
+	TimeInForceGTC = "GTC"
+)
+
+// Scaling rules are shared with the signal events so every service reads
+// the same shape
+type (
+	ScalingStep  = schemas.ScalingStep
+	TrailRule    = schemas.TrailRule
+	ScalingRules = schemas.ScalingRules
+)
+
+// SignalExecution is the optional execution policy attached to a signal
+type SignalExecution struct {
+	TimeInForce  string        `json:"time_in_force"`
+	ExpiresAt    *time.Time    `json:"expires_at"`
+	ScalingRules *ScalingRules `json:"scaling_rules"`
+}
+
+// Validate checks the policy against the signal's direction and levels.
+// Step prices must lie beyond entry in the signal's direction, in order, and
+// not past the final target; exits may not add up to more than 100%.
+func (e *SignalExecution) Validate(s *Signal) error {
+	e.TimeInForce = strings.ToUpper(strings.TrimSpace(e.TimeInForce))
+	switch e.TimeInForce {
+	case "", TimeInForceDay, TimeInForceGTC:
+		if e.ExpiresAt != nil {
+			return errors.New("expires_at is only allowed with time_in_force GTD")
+		}
+	case TimeInForceGTD:
+		if e.ExpiresAt == nil {
+			return errors.New("time_in_force GTD requires expires_at")
+		}
+		if !e.ExpiresAt.After(time.Now()) {
+			return errors.New("expires_at must be in the future")
+		}
+	default:
+		return fmt.Errorf("time_in_force must be %s, %s or %s", TimeInForceDay, TimeInForceGTD, TimeInForceGTC)
+	}
+
+	r := e.ScalingRules
+	if r == nil {
+		return nil
+	}
+	if len(r.Steps) == 0 && r.Trail == nil {
+		return errors.New("scaling_rules needs at least one step or a trail")
+	}
+
+	// dir is +1 when profit means a rising price
+	dir := 1.0
+	if t := strings.ToUpper(s.SignalType); t == "SELL" || t == "SHORT" {
+		dir = -1
+	}
+	total := 0.0
+	last := s.EntryPrice
+	levels := map[string]bool{}
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		step.Level = strings.TrimSpace(step.Level)
+		if step.Level == "" {
+			step.Level = fmt.Sprintf("T%d", i+1)
+		}
+		if levels[step.Level] {
+			return fmt.Errorf("duplicate scaling level %s", step.Level)
+		}
+		levels[step.Level] = true
+
+		if step.ExitPct <= 0 || step.ExitPct > 100 {
+			return fmt.Errorf("%s: exit_pct must be between 0 and 100", step.Level)
+		}
+		total += step.ExitPct
+		if (step.Price-last)*dir <= 0 {
+			return fmt.Errorf("%s: price must be beyond entry and previous steps in the signal's direction", step.Level)
+		}
+		if s.TargetPrice > 0 && (step.Price-s.TargetPrice)*dir > 0 {
+			return fmt.Errorf("%s: price is past the target", step.Level)
+		}
+		last = step.Price
+
+		switch step.MoveStopTo {
+		case "", "entry":
+		default:
+			var stop float64
+			if _, err := fmt.Sscanf(step.MoveStopTo, "%g", &stop); err != nil || (step.Price-stop)*dir <= 0 {
+				return fmt.Errorf("%s: move_stop_to must be \"entry\" or a price behind the step", step.Level)
+			}
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("scaling exits add up to %.4g%%, more than 100%%", total)
+	}
+
+	if t := r.Trail; t != nil {
+		if t.TrailPct <= 0 || t.TrailPct > 20 {
+			return errors.New("trail_pct must be between 0 and 20")
+		}
+		if t.ActivateAt != "" && !levels[t.ActivateAt] {
+			return fmt.Errorf("trail activate_at %s is not a scaling level", t.ActivateAt)
+		}
+		if total >= 100 {
+			return errors.New("nothing is left to trail after the scaling steps")
+		}
+	}
+	return nil
+}
+
+// UpdateSignalExecution sets the execution policy of an active signal and
+// returns the updated signal, or nil if no active signal has that ID
+func (db *DB) UpdateSignalExecution(ctx context.Context, signalID string, e SignalExecution) (*Signal, error) {
+	var tif *string
+	if e.TimeInForce != "" {
+		tif = &e.TimeInForce
+	}
+	var rules []byte
+	if e.ScalingRules != nil {
+		var err error
+		if rules, err = json.Marshal(e.ScalingRules); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := scanSignal(db.conn.QueryRowContext(ctx, `
+		UPDATE intraday.signals
+		SET time_in_force = $2, expires_at = $3, scaling_rules = $4
+		WHERE signal_id = $1 AND status = 'ACTIVE'
+		RETURNING `+signalColumns, signalID, tif, e.ExpiresAt, rules))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update signal execution: %w", err)
+	}
+	return s, nil
+}
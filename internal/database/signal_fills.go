@@ -0,0 +1,308 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Fill legs
+const (
+	FillEntry = "entry"
+	FillExit  = "exit"
+)
+
+// Fill sources. Broker fills are matched from imported trades; paper and
+// manual fills are posted by the paper-trading engine or a user.
+const (
+	FillSourceBroker = "broker"
+	FillSourcePaper  = "paper"
+	FillSourceManual = "manual"
+)
+
+// fillMatchWindow is how long after a signal is generated, or closed, a
+// broker trade may still be taken as its entry, or exit
+const fillMatchWindow = 30 * time.Minute
+
+// SignalFill is an actual execution of a signal's entry or exit
+type SignalFill struct {
+	ID         int64    `json:"id"`
+	SignalID   string   `json:"signal_id"`
+	Leg        string   `json:"leg"`
+	Source     string   `json:"source"`
+	Price      float64  `json:"price"`
+	Quantity   *float64 `json:"quantity"`
+	FilledAt   string   `json:"filled_at"`
+	ExternalID *string  `json:"external_id"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+const signalFillColumns = `id, signal_id, leg, source, price::float8, quantity::float8, filled_at, external_id, created_at`
+
+func scanSignalFill(row rowScanner) (*SignalFill, error) {
+	var f SignalFill
+	var filledAt, createdAt time.Time
+	if err := row.Scan(&f.ID, &f.SignalID, &f.Leg, &f.Source, &f.Price, &f.Quantity, &filledAt, &f.ExternalID, &createdAt); err != nil {
+		return nil, fmt.Errorf("failed to scan signal fill: %w", err)
+	}
+	f.FilledAt = filledAt.Format(time.RFC3339)
+	f.CreatedAt = createdAt.Format(time.RFC3339)
+	return &f, nil
+}
+
+// UpsertSignalFill records a fill, replacing any earlier one for the same
+// signal, leg and source
+func (db *DB) UpsertSignalFill(ctx context.Context, signalID, leg, source string, price float64, quantity *float64, filledAt time.Time, externalID *string) (*SignalFill, error) {
+	return scanSignalFill(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.signal_fills (signal_id, leg, source, price, quantity, filled_at, external_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (signal_id, leg, source) DO UPDATE SET
+			price = EXCLUDED.price, quantity = EXCLUDED.quantity,
+			filled_at = EXCLUDED.filled_at, external_id = EXCLUDED.external_id
+		RETURNING `+signalFillColumns,
+		signalID, leg, source, price, quantity, filledAt, externalID))
+}
+
+// ListSignalFills returns a signal's fills, entries first
+func (db *DB) ListSignalFills(ctx context.Context, signalID string) ([]SignalFill, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalFillColumns+`
+		FROM core_api.signal_fills
+		WHERE signal_id = $1
+		ORDER BY leg, source
+	`, signalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal fills: %w", err)
+	}
+	defer rows.Close()
+
+	fills := []SignalFill{}
+	for rows.Next() {
+		f, err := scanSignalFill(rows)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return fills, nil
+}
+
+// MatchBrokerFills links imported broker trades to the signals they
+// executed. A trade in a signal's direction within fillMatchWindow of it
+// being generated is its entry; a later opposite trade, up to
+// fillMatchWindow after the signal closed, is its exit. Each signal takes
+// its first matching trades and trades are considered in time order. It
+// returns how many fills were recorded.
+func (db *DB) MatchBrokerFills(ctx context.Context, trades []ImportedTrade) (int, error) {
+	sorted := append([]ImportedTrade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TradedAt.Before(sorted[j].TradedAt) })
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin fill matching: %w", err)
+	}
+	defer tx.Rollback()
+
+	window := int(fillMatchWindow.Seconds())
+	matched := 0
+	for _, t := range sorted {
+		if t.TxnType != "BUY" && t.TxnType != "SELL" {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO core_api.signal_fills (signal_id, leg, source, price, quantity, filled_at, external_id)
+			SELECT s.signal_id::text, 'entry', 'broker', $3::numeric, $4::numeric, $5::timestamptz, $6::text
+			FROM intraday.signals s
+			WHERE s.symbol = $1
+				AND (CASE WHEN UPPER(s.signal_type) IN ('SELL', 'SHORT') THEN 'SELL' ELSE 'BUY' END) = $2
+				AND $5::timestamptz BETWEEN s.generated_at AND s.generated_at + $7::int * INTERVAL '1 second'
+				AND NOT EXISTS (
+					SELECT 1 FROM core_api.signal_fills f
+					WHERE f.signal_id = s.signal_id::text AND f.leg = 'entry' AND f.source = 'broker'
+				)
+			ORDER BY s.generated_at DESC
+			LIMIT 1
+			ON CONFLICT (signal_id, leg, source) DO NOTHING
+		`, t.Symbol, t.TxnType, t.Price, t.Quantity, t.TradedAt, t.ExternalID, window)
+		if err != nil {
+			return 0, fmt.Errorf("failed to match entry fill %s: %w", t.ExternalID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			matched++
+			continue
+		}
+
+		res, err = tx.ExecContext(ctx, `
+			INSERT INTO core_api.signal_fills (signal_id, leg, source, price, quantity, filled_at, external_id)
+			SELECT s.signal_id::text, 'exit', 'broker', $3::numeric, $4::numeric, $5::timestamptz, $6::text
+			FROM intraday.signals s
+			JOIN core_api.signal_fills e
+				ON e.signal_id = s.signal_id::text AND e.leg = 'entry' AND e.source = 'broker'
+			WHERE s.symbol = $1
+				AND (CASE WHEN UPPER(s.signal_type) IN ('SELL', 'SHORT') THEN 'BUY' ELSE 'SELL' END) = $2
+				AND $5::timestamptz > e.filled_at
+				AND s.closed_at IS NOT NULL
+				AND $5::timestamptz <= s.closed_at + $7::int * INTERVAL '1 second'
+				AND NOT EXISTS (
+					SELECT 1 FROM core_api.signal_fills f
+					WHERE f.signal_id = s.signal_id::text AND f.leg = 'exit' AND f.source = 'broker'
+				)
+			ORDER BY e.filled_at
+			LIMIT 1
+			ON CONFLICT (signal_id, leg, source) DO NOTHING
+		`, t.Symbol, t.TxnType, t.Price, t.Quantity, t.TradedAt, t.ExternalID, window)
+		if err != nil {
+			return 0, fmt.Errorf("failed to match exit fill %s: %w", t.ExternalID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			matched++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit fill matching: %w", err)
+	}
+	return matched, nil
+}
+
+// SlippageStat is the average slippage of a group of fills, in percent of
+// the intended price. Positive slippage is adverse: paying more than the
+// entry on a long, or exiting below the target or stop. Exits are measured
+// against the target or stop the signal closed on, and otherwise against
+// the signal's own exit price.
+type SlippageStat struct {
+	Key                 string   `json:"key"`
+	Fills               int      `json:"fills"`
+	EntryFills          int      `json:"entry_fills"`
+	ExitFills           int      `json:"exit_fills"`
+	AvgSlippagePct      *float64 `json:"avg_slippage_pct"`
+	AvgEntrySlippagePct *float64 `json:"avg_entry_slippage_pct"`
+	AvgExitSlippagePct  *float64 `json:"avg_exit_slippage_pct"`
+	WorstSlippagePct    *float64 `json:"worst_slippage_pct"`
+
+	entrySum, exitSum float64
+}
+
+func (s *SlippageStat) add(leg string, slippage float64) {
+	s.Fills++
+	if leg == FillEntry {
+		s.EntryFills++
+		s.entrySum += slippage
+	} else {
+		s.ExitFills++
+		s.exitSum += slippage
+	}
+	if s.WorstSlippagePct == nil || slippage > *s.WorstSlippagePct {
+		worst := slippage
+		s.WorstSlippagePct = &worst
+	}
+}
+
+func (s *SlippageStat) finish() {
+	avg := func(sum float64, n int) *float64 {
+		if n == 0 {
+			return nil
+		}
+		v := round4(sum / float64(n))
+		return &v
+	}
+	s.AvgSlippagePct = avg(s.entrySum+s.exitSum, s.Fills)
+	s.AvgEntrySlippagePct = avg(s.entrySum, s.EntryFills)
+	s.AvgExitSlippagePct = avg(s.exitSum, s.ExitFills)
+	if s.WorstSlippagePct != nil {
+		worst := round4(*s.WorstSlippagePct)
+		s.WorstSlippagePct = &worst
+	}
+}
+
+// SlippageReport breaks slippage down by symbol and by IST hour of the fill
+type SlippageReport struct {
+	Overall  SlippageStat   `json:"overall"`
+	BySymbol []SlippageStat `json:"by_symbol"`
+	ByHour   []SlippageStat `json:"by_hour"`
+	// Unmeasured counts exit fills whose signal has no exit reference yet
+	Unmeasured int `json:"unmeasured"`
+}
+
+// GetSlippageReport measures fills since since, optionally from one source
+func (db *DB) GetSlippageReport(ctx context.Context, since time.Time, source string) (*SlippageReport, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			f.leg,
+			s.symbol,
+			EXTRACT(HOUR FROM f.filled_at AT TIME ZONE 'Asia/Kolkata')::int,
+			CASE WHEN UPPER(s.signal_type) IN ('SELL', 'SHORT') THEN -1 ELSE 1 END,
+			f.price::float8,
+			(CASE
+				WHEN f.leg = 'entry' THEN s.entry_price
+				WHEN s.status = 'HIT_TARGET' THEN s.target_price
+				WHEN s.status = 'HIT_STOPLOSS' THEN s.stop_loss
+				ELSE s.exit_price
+			END)::float8
+		FROM core_api.signal_fills f
+		JOIN intraday.signals s ON s.signal_id::text = f.signal_id
+		WHERE f.filled_at >= $1 AND ($2 = '' OR f.source = $2)
+	`, since, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal fills: %w", err)
+	}
+	defer rows.Close()
+
+	report := &SlippageReport{Overall: SlippageStat{Key: "all"}}
+	bySymbol := map[string]*SlippageStat{}
+	byHour := map[int]*SlippageStat{}
+	for rows.Next() {
+		var leg, symbol string
+		var hour int
+		var dir, price float64
+		var reference sql.NullFloat64
+		if err := rows.Scan(&leg, &symbol, &hour, &dir, &price, &reference); err != nil {
+			return nil, fmt.Errorf("failed to scan signal fill: %w", err)
+		}
+		if !reference.Valid || reference.Float64 <= 0 {
+			report.Unmeasured++
+			continue
+		}
+
+		slippage := dir * (price - reference.Float64) / reference.Float64 * 100
+		if leg == FillExit {
+			slippage = -slippage
+		}
+
+		report.Overall.add(leg, slippage)
+		if bySymbol[symbol] == nil {
+			bySymbol[symbol] = &SlippageStat{Key: symbol}
+		}
+		bySymbol[symbol].add(leg, slippage)
+		if byHour[hour] == nil {
+			byHour[hour] = &SlippageStat{Key: fmt.Sprintf("%02d:00", hour)}
+		}
+		byHour[hour].add(leg, slippage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	report.Overall.finish()
+	report.BySymbol = []SlippageStat{}
+	for _, s := range bySymbol {
+		s.finish()
+		report.BySymbol = append(report.BySymbol, *s)
+	}
+	// Worst symbols first
+	sort.Slice(report.BySymbol, func(i, j int) bool {
+		return *report.BySymbol[i].AvgSlippagePct > *report.BySymbol[j].AvgSlippagePct
+	})
+	report.ByHour = []SlippageStat{}
+	for _, s := range byHour {
+		s.finish()
+		report.ByHour = append(report.ByHour, *s)
+	}
+	sort.Slice(report.ByHour, func(i, j int) bool { return report.ByHour[i].Key < report.ByHour[j].Key })
+	return report, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// externalIDPattern limits external IDs to characters that are safe inside
+// a signal ID
+var externalIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.:\-]{1,64}$`)
+
+// maxSignalClockSkew is how far in the future a provider's generated_at may
+// be before it's rejected
+const maxSignalClockSkew = 5 * time.Minute
+
+// IngestedSignal is a signal generated outside the engine, by a vendor or
+// another model
+type IngestedSignal struct {
+	// ExternalID is the provider's ID for the signal. Without one, an ID is
+	// derived from the signal's symbol, direction, time and entry.
+	ExternalID  string
+	Symbol      string
+	SignalType  string
+	Confidence  float64
+	EntryPrice  float64
+	StopLoss    float64
+	TargetPrice float64
+	GeneratedAt time.Time
+	TimeInForce string
+	ExpiresAt   *time.Time
+}
+
+// Validate normalises the signal and checks its levels against its
+// direction. A zero GeneratedAt becomes now when there is an ExternalID.
+func (s *IngestedSignal) Validate(now time.Time) error {
+	s.Symbol = strings.ToUpper(strings.TrimSpace(s.Symbol))
+	s.SignalType = strings.ToUpper(strings.TrimSpace(s.SignalType))
+	s.ExternalID = strings.TrimSpace(s.ExternalID)
+	if s.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if s.SignalType != "BUY" && s.SignalType != "SELL" {
+		return errors.New("signal_type must be BUY or SELL")
+	}
+	if s.Confidence < 0 || s.Confidence > 1 {
+		return errors.New("confidence must be between 0 and 1")
+	}
+	if s.EntryPrice <= 0 || s.StopLoss <= 0 || s.TargetPrice <= 0 {
+		return errors.New("entry_price, stop_loss and target_price must be positive")
+	}
+	if s.SignalType == "BUY" && !(s.StopLoss < s.EntryPrice && s.EntryPrice < s.TargetPrice) {
+		return errors.New("a BUY signal needs stop_loss < entry_price < target_price")
+	}
+	if s.SignalType == "SELL" && !(s.TargetPrice < s.EntryPrice && s.EntryPrice < s.StopLoss) {
+		return errors.New("a SELL signal needs target_price < entry_price < stop_loss")
+	}
+	if s.GeneratedAt.IsZero() {
+		// A derived ID needs the provider's time to stay stable on resend
+		if s.ExternalID == "" {
+			return errors.New("external_id or generated_at is required")
+		}
+		s.GeneratedAt = now
+	}
+	if s.GeneratedAt.After(now.Add(maxSignalClockSkew)) {
+		return errors.New("generated_at is in the future")
+	}
+
+	e := SignalExecution{TimeInForce: s.TimeInForce, ExpiresAt: s.ExpiresAt}
+	if err := e.Validate(&Signal{SignalType: s.SignalType, EntryPrice: s.EntryPrice, TargetPrice: s.TargetPrice}); err != nil {
+		return err
+	}
+	s.TimeInForce = e.TimeInForce
+
+	if s.ExternalID == "" {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%g", s.Symbol, s.SignalType, s.GeneratedAt.Unix(), s.EntryPrice)))
+		s.ExternalID = hex.EncodeToString(sum[:8])
+	}
+	if !externalIDPattern.MatchString(s.ExternalID) {
+		return errors.New("external_id must be at most 64 letters, digits or _.:-")
+	}
+	return nil
+}
+
+// ExternalSignalID is the signal ID an ingested signal is stored under, so
+// the same provider signal is only stored once
+func ExternalSignalID(provider, externalID string) string {
+	return "ext-" + provider + "-" + externalID
+}
+
+// SignalIngestResult summarises an ingested batch
+type SignalIngestResult struct {
+	// Signals are the newly stored signals
+	Signals    []Signal
+	Duplicates int
+	// UnknownSymbols are symbols missing from the stock config; their
+	// signals were not stored
+	UnknownSymbols []string
+}
+
+// IngestSignals stores validated signals from provider as active signals
+// in one transaction. Signals already ingested from the provider are
+// skipped, as are signals for symbols not in the stock config. The provider
+// and external ID are recorded in the signal's metadata.
+func (db *DB) IngestSignals(ctx context.Context, provider string, signals []IngestedSignal) (*SignalIngestResult, error) {
+	result := &SignalIngestResult{Signals: []Signal{}, UnknownSymbols: []string{}}
+	if len(signals) == 0 {
+		return result, nil
+	}
+
+	symbols := make([]string, 0, len(signals))
+	for _, s := range signals {
+		symbols = append(symbols, s.Symbol)
+	}
+	type stock struct{ name, sector string }
+	known := map[string]stock{}
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT DISTINCT ON (symbol) symbol, COALESCE(name, symbol), COALESCE(sector, '')
+		FROM md.stock_config
+		WHERE symbol = ANY($1)
+		ORDER BY symbol, exchange = 'NSE' DESC
+	`, pq.Array(symbols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up signal symbols: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var symbol string
+		var st stock
+		if err := rows.Scan(&symbol, &st.name, &st.sector); err != nil {
+			return nil, fmt.Errorf("failed to scan stock: %w", err)
+		}
+		known[symbol] = st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin ingest: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO intraday.signals (signal_id, symbol, stock_name, sector, signal_type, confidence_score,
+			entry_price, current_price, stop_loss, target_price, status, generated_at, metadata,
+			time_in_force, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8, $9, 'ACTIVE', $10, $11, $12, $13)
+		ON CONFLICT (signal_id) DO NOTHING
+		RETURNING `+signalColumns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare ingest: %w", err)
+	}
+	defer stmt.Close()
+
+	unknown := map[string]bool{}
+	for _, s := range signals {
+		st, ok := known[s.Symbol]
+		if !ok {
+			if !unknown[s.Symbol] {
+				unknown[s.Symbol] = true
+				result.UnknownSymbols = append(result.UnknownSymbols, s.Symbol)
+			}
+			continue
+		}
+		metadata, err := json.Marshal(map[string]string{
+			"source":      "external",
+			"provider":    provider,
+			"external_id": s.ExternalID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var tif *string
+		if s.TimeInForce != "" {
+			tif = &s.TimeInForce
+		}
+
+		saved, err := scanSignal(stmt.QueryRowContext(ctx,
+			ExternalSignalID(provider, s.ExternalID), s.Symbol, st.name, st.sector, s.SignalType, s.Confidence,
+			s.EntryPrice, s.StopLoss, s.TargetPrice, s.GeneratedAt, metadata, tif, s.ExpiresAt))
+		if errors.Is(err, sql.ErrNoRows) {
+			result.Duplicates++
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to ingest signal %s: %w", s.ExternalID, err)
+		}
+		result.Signals = append(result.Signals, *saved)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit ingest: %w", err)
+	}
+	return result, nil
+}
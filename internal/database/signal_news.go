@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SignalNewsLink is a high-impact article published shortly before a
+// signal for the same symbol
+type SignalNewsLink struct {
+	ArticleID   string    `json:"article_id"`
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`
+	URL         *string   `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	Sentiment   string    `json:"sentiment"`
+	Confidence  float64   `json:"confidence"`
+	// LeadMinutes is how long before the signal the article was published
+	LeadMinutes int `json:"lead_minutes"`
+}
+
+// SignalNews is a signal and the articles linked into its metadata.
+// Linked is false until the signal has been checked for news.
+type SignalNews struct {
+	SignalID    string           `json:"signal_id"`
+	Symbol      string           `json:"symbol"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Linked      bool             `json:"linked"`
+	Articles    []SignalNewsLink `json:"articles"`
+}
+
+// ListUnlinkedSignals returns up to limit signals generated since that
+// haven't been checked for news, oldest first
+func (db *DB) ListUnlinkedSignals(ctx context.Context, since time.Time, limit int) ([]SignalNews, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT signal_id, symbol, generated_at
+		FROM intraday.signals
+		WHERE generated_at >= $1
+		  AND (metadata IS NULL OR NOT (metadata ? 'news_links'))
+		ORDER BY generated_at
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unlinked signals: %w", err)
+	}
+	defer rows.Close()
+
+	signals := []SignalNews{}
+	for rows.Next() {
+		s := SignalNews{Articles: []SignalNewsLink{}}
+		if err := rows.Scan(&s.SignalID, &s.Symbol, &s.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signal: %w", err)
+		}
+		signals = append(signals, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return signals, nil
+}
+
+// GetSignalNews returns a signal's linked articles, or nil if there's no
+// such signal
+func (db *DB) GetSignalNews(ctx context.Context, signalID string) (*SignalNews, error) {
+	s := SignalNews{Articles: []SignalNewsLink{}}
+	var links []byte
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT signal_id, symbol, generated_at, metadata->'news_links'
+		FROM intraday.signals
+		WHERE signal_id = $1
+	`, signalID).Scan(&s.SignalID, &s.Symbol, &s.GeneratedAt, &links)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal news: %w", err)
+	}
+	if links != nil {
+		s.Linked = true
+		if err := json.Unmarshal(links, &s.Articles); err != nil {
+			return nil, fmt.Errorf("failed to decode news links of %s: %w", signalID, err)
+		}
+	}
+	return &s, nil
+}
+
+// FindSignalNews returns up to limit high-impact articles tagged with
+// symbol and published in the lookback before at, nearest first. An
+// article counts as high impact when its LLM sentiment is positive or
+// negative; near-duplicates count once.
+func (db *DB) FindSignalNews(ctx context.Context, symbol string, at time.Time, lookback time.Duration, limit int) ([]SignalNewsLink, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, title, source, url, published_at, sentiment, confidence
+		FROM (
+			SELECT DISTINCT ON (COALESCE(c.cluster_id, a.id))
+				a.id,
+				COALESCE(a.title, '') AS title,
+				COALESCE(a.source, 'Unknown') AS source,
+				a.url,
+				a.published_at,
+				lower(a.llm_sentiment) AS sentiment,
+				COALESCE(a.llm_confidence, a.sentiment_score, 0.5) AS confidence
+			FROM news.article_entities ae
+			JOIN news.articles a ON a.id = ae.article_id
+			LEFT JOIN core_api.article_clusters c ON c.article_id = a.id
+			WHERE ae.symbol = $1
+			  AND a.published_at <= $2
+			  AND a.published_at >= $2 - $3::int * INTERVAL '1 second'
+			  AND lower(a.llm_sentiment) IN ('positive', 'negative')
+			ORDER BY COALESCE(c.cluster_id, a.id), a.published_at DESC
+		) n
+		ORDER BY published_at DESC
+		LIMIT $4
+	`, symbol, at, int(lookback.Seconds()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal news: %w", err)
+	}
+	defer rows.Close()
+
+	links := []SignalNewsLink{}
+	for rows.Next() {
+		var l SignalNewsLink
+		if err := rows.Scan(&l.ArticleID, &l.Title, &l.Source, &l.URL, &l.PublishedAt, &l.Sentiment, &l.Confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		l.LeadMinutes = int(at.Sub(l.PublishedAt).Minutes())
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return links, nil
+}
+
+// SaveSignalNews writes links into the signal's metadata as news_links,
+// keeping its other metadata. An empty list marks the signal as checked.
+func (db *DB) SaveSignalNews(ctx context.Context, signalID string, links []SignalNewsLink) error {
+	if links == nil {
+		links = []SignalNewsLink{}
+	}
+	data, err := json.Marshal(links)
+	if err != nil {
+		return fmt.Errorf("failed to encode news links: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `
+		UPDATE intraday.signals
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('news_links', $2::jsonb)
+		WHERE signal_id = $1
+	`, signalID, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save news links of %s: %w", signalID, err)
+	}
+	return nil
+}
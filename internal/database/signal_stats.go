@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetSignalStats counts signals generated since a time and their outcomes,
+// with the same hit and miss rules as the dashboard statistics
+func (db *DB) GetSignalStats(ctx context.Context, since time.Time) (*DashboardStats, error) {
+	var s DashboardStats
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'ACTIVE'),
+			COUNT(*) FILTER (WHERE result = 'HIT'),
+			COUNT(*) FILTER (WHERE result = 'MISS'),
+			COUNT(*) FILTER (WHERE status IN ('EXPIRED', 'TIME_EXIT')),
+			COALESCE(AVG(confidence_score), 0),
+			COALESCE(AVG(actual_profit_pct) FILTER (WHERE result = 'HIT'), 0),
+			COALESCE(AVG(actual_profit_pct) FILTER (WHERE result = 'MISS'), 0),
+			ROUND(
+				COUNT(*) FILTER (WHERE result = 'HIT')::numeric /
+				NULLIF(COUNT(*) FILTER (WHERE result IS NOT NULL), 0) * 100,
+				2
+			)
+		FROM intraday.signals
+		WHERE generated_at >= $1
+	`, since).Scan(
+		&s.TotalSignals, &s.ActiveCount, &s.Hits, &s.Misses, &s.Expired,
+		&s.AvgConfidence, &s.AvgProfitHit, &s.AvgLossMiss, &s.SuccessRate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal stats: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Signal timeline event kinds
+const (
+	// SignalEventGenerated is the signal being announced
+	SignalEventGenerated = "generated"
+	// SignalEventThreshold is price moving into another band of progress
+	// toward the target or stop
+	SignalEventThreshold = "threshold_crossed"
+	// SignalEventStopMoved is the stop being trailed or moved by a scaling step
+	SignalEventStopMoved = "stop_moved"
+	// SignalEventStatus is a status change short of closing, e.g. to
+	// TRAILING_STOP
+	SignalEventStatus = "status_changed"
+	// SignalEventClosed is the signal's outcome
+	SignalEventClosed = "closed"
+)
+
+// SignalTimelineEvent is one lifecycle transition of a signal, with the
+// signal's levels as they were at that moment
+type SignalTimelineEvent struct {
+	ID          int64    `json:"id"`
+	SignalID    string   `json:"signal_id"`
+	Kind        string   `json:"kind"`
+	Status      *string  `json:"status"`
+	Price       *float64 `json:"price"`
+	StopLoss    *float64 `json:"stop_loss"`
+	TargetPrice *float64 `json:"target_price"`
+	// ProgressPct is the band price had reached, in percent of the way from
+	// entry to target (positive) or stop (negative)
+	ProgressPct *float64        `json:"progress_pct"`
+	Detail      json.RawMessage `json:"detail,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	RecordedAt  time.Time       `json:"recorded_at"`
+}
+
+const signalEventColumns = `id, signal_id, kind, status, price, stop_loss, target_price, progress_pct, detail, occurred_at, recorded_at`
+
+func scanSignalTimelineEvent(row rowScanner) (*SignalTimelineEvent, error) {
+	var e SignalTimelineEvent
+	var detail []byte
+	if err := row.Scan(&e.ID, &e.SignalID, &e.Kind, &e.Status, &e.Price, &e.StopLoss, &e.TargetPrice,
+		&e.ProgressPct, &detail, &e.OccurredAt, &e.RecordedAt); err != nil {
+		return nil, err
+	}
+	if len(detail) > 0 {
+		e.Detail = detail
+	}
+	return &e, nil
+}
+
+// RecordSignalEvent appends e to its signal's timeline. It reports false
+// when e is a generated or closed event the signal already has.
+func (db *DB) RecordSignalEvent(ctx context.Context, e SignalTimelineEvent) (bool, error) {
+	var detail []byte
+	if len(e.Detail) > 0 {
+		detail = e.Detail
+	}
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.signal_events (signal_id, kind, status, price, stop_loss, target_price, progress_pct, detail, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (signal_id, kind) WHERE kind IN ('generated', 'closed') DO NOTHING
+	`, e.SignalID, e.Kind, e.Status, e.Price, e.StopLoss, e.TargetPrice, e.ProgressPct, detail, e.OccurredAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record signal event: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record signal event: %w", err)
+	}
+	return n > 0, nil
+}
+
+// LastSignalEvent returns the latest event on a signal's timeline, or nil
+// if it has none
+func (db *DB) LastSignalEvent(ctx context.Context, signalID string) (*SignalTimelineEvent, error) {
+	e, err := scanSignalTimelineEvent(db.conn.QueryRowContext(ctx, `
+		SELECT `+signalEventColumns+`
+		FROM core_api.signal_events
+		WHERE signal_id = $1
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT 1
+	`, signalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last signal event: %w", err)
+	}
+	return e, nil
+}
+
+// GetSignalTimeline returns a signal's events in the order they happened
+func (db *DB) GetSignalTimeline(ctx context.Context, signalID string) ([]SignalTimelineEvent, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalEventColumns+`
+		FROM core_api.signal_events
+		WHERE signal_id = $1
+		ORDER BY occurred_at, id
+	`, signalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal timeline: %w", err)
+	}
+	defer rows.Close()
+
+	events := []SignalTimelineEvent{}
+	for rows.Next() {
+		e, err := scanSignalTimelineEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal event: %w", err)
+		}
+		events = append(events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return events, nil
+}
+
+// FindSignalID returns the ID of the signal on symbol generated at
+// generatedAt, to the second, or "" if there is none. Events for signals
+// with non-numeric IDs carry only their symbol and generation time.
+func (db *DB) FindSignalID(ctx context.Context, symbol string, generatedAt time.Time) (string, error) {
+	var id string
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT signal_id
+		FROM intraday.signals
+		WHERE symbol = $1 AND generated_at >= $2 AND generated_at < $2 + INTERVAL '1 second'
+		ORDER BY generated_at DESC
+		LIMIT 1
+	`, symbol, generatedAt.Truncate(time.Second)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find signal: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSignalExists is returned when a deleted signal can't be restored
+// because a signal with its ID has been stored since
+var ErrSignalExists = errors.New("a signal with this ID already exists")
+
+// deletableSignalExpr limits soft deletes to signals entered here, by
+// ingest or by hand; engine signals are corrected at the engine
+const deletableSignalExpr = `metadata->>'source' IN ('external', 'manual')`
+
+// SignalDeletable reports whether a signal was entered here and so may be
+// soft-deleted
+func SignalDeletable(s *Signal) bool {
+	if !s.Metadata.Valid {
+		return false
+	}
+	var meta struct {
+		Source string `json:"source"`
+	}
+	if json.Unmarshal(s.Metadata.RawMessage, &meta) != nil {
+		return false
+	}
+	return meta.Source == "external" || meta.Source == "manual"
+}
+
+// SignalTombstone records a soft-deleted signal. The signal is moved out
+// of intraday.signals, so it drops out of every list and analytic, and can
+// be restored until RestoreUntil.
+type SignalTombstone struct {
+	SignalID     string `json:"signal_id"`
+	Symbol       string `json:"symbol"`
+	SignalType   string `json:"signal_type"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason"`
+	DeletedBy    string `json:"deleted_by"`
+	DeletedAt    string `json:"deleted_at"`
+	RestoreUntil string `json:"restore_until"`
+	Restorable   bool   `json:"restorable"`
+}
+
+const signalTombstoneColumns = `signal_id, symbol, signal_type, status, reason, deleted_by,
+	deleted_at, restore_until, restore_until > NOW()`
+
+func scanSignalTombstone(row rowScanner) (*SignalTombstone, error) {
+	var t SignalTombstone
+	var deletedAt, restoreUntil time.Time
+	if err := row.Scan(&t.SignalID, &t.Symbol, &t.SignalType, &t.Status, &t.Reason, &t.DeletedBy,
+		&deletedAt, &restoreUntil, &t.Restorable); err != nil {
+		return nil, err
+	}
+	t.DeletedAt = deletedAt.Format(time.RFC3339)
+	t.RestoreUntil = restoreUntil.Format(time.RFC3339)
+	return &t, nil
+}
+
+// SoftDeleteSignal moves an ingested or manual signal into a tombstone,
+// returning nil when there is no such signal
+func (db *DB) SoftDeleteSignal(ctx context.Context, signalID, reason, deletedBy string, restoreUntil time.Time) (*SignalTombstone, error) {
+	t, err := scanSignalTombstone(db.conn.QueryRowContext(ctx, `
+		WITH d AS (
+			DELETE FROM intraday.signals
+			WHERE signal_id = $1 AND `+deletableSignalExpr+`
+			RETURNING *
+		)
+		INSERT INTO core_api.signal_tombstones
+			(signal_id, symbol, signal_type, status, row, reason, deleted_by, restore_until)
+		SELECT d.signal_id, d.symbol, d.signal_type, d.status, to_jsonb(d), $2, $3, $4
+		FROM d
+		ON CONFLICT (signal_id) DO UPDATE SET
+			symbol = EXCLUDED.symbol, signal_type = EXCLUDED.signal_type, status = EXCLUDED.status,
+			row = EXCLUDED.row, reason = EXCLUDED.reason, deleted_by = EXCLUDED.deleted_by,
+			deleted_at = NOW(), restore_until = EXCLUDED.restore_until
+		RETURNING `+signalTombstoneColumns,
+		signalID, reason, deletedBy, restoreUntil))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete signal: %w", err)
+	}
+	return t, nil
+}
+
+// ListSignalTombstones returns soft-deleted signals, most recent first
+func (db *DB) ListSignalTombstones(ctx context.Context, limit int) ([]SignalTombstone, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+signalTombstoneColumns+`
+		FROM core_api.signal_tombstones
+		ORDER BY deleted_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal tombstones: %w", err)
+	}
+	defer rows.Close()
+
+	tombstones := []SignalTombstone{}
+	for rows.Next() {
+		t, err := scanSignalTombstone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal tombstone: %w", err)
+		}
+		tombstones = append(tombstones, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return tombstones, nil
+}
+
+// GetSignalTombstone returns a deleted signal's tombstone, or nil
+func (db *DB) GetSignalTombstone(ctx context.Context, signalID string) (*SignalTombstone, error) {
+	t, err := scanSignalTombstone(db.conn.QueryRowContext(ctx, `
+		SELECT `+signalTombstoneColumns+`
+		FROM core_api.signal_tombstones
+		WHERE signal_id = $1
+	`, signalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal tombstone: %w", err)
+	}
+	return t, nil
+}
+
+// RestoreSignal moves a deleted signal back into intraday.signals if its
+// restore window is open, returning nil when it isn't. ErrSignalExists
+// means the ID has been reused since.
+func (db *DB) RestoreSignal(ctx context.Context, signalID string) (*Signal, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	var row []byte
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM core_api.signal_tombstones
+		WHERE signal_id = $1 AND restore_until > NOW()
+		RETURNING row
+	`, signalID).Scan(&row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signal tombstone: %w", err)
+	}
+
+	s, err := scanSignal(tx.QueryRowContext(ctx, `
+		INSERT INTO intraday.signals
+		SELECT (jsonb_populate_record(NULL::intraday.signals, $1::jsonb)).*
+		ON CONFLICT (signal_id) DO NOTHING
+		RETURNING `+signalColumns, row))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSignalExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore signal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return s, nil
+}
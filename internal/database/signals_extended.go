@@ -2,9 +2,13 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // DashboardSignal represents a signal for the dashboard view
@@ -31,6 +35,7 @@ type DashboardSignal struct {
 	ClosedAt        *string         `json:"closed_at,omitempty"`
 	ExpiresAt       string          `json:"expires_at"`
 	Metadata        json.RawMessage `json:"metadata"`
+	News            *NewsContext    `json:"news"`
 }
 
 // DashboardStats represents signal statistics
@@ -75,6 +80,29 @@ type DashboardData struct {
 
 // GetDashboardData retrieves aggregated dashboard data
 func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed bool) (*DashboardData, error) {
+	return db.getDashboardData(ctx, limit, includeClosed, nil)
+}
+
+// GetDashboardDataAsOf reconstructs the dashboard as it stood at asOf: the
+// signals generated by then, as they were then, for asOf's IST day
+func (db *DB) GetDashboardDataAsOf(ctx context.Context, asOf time.Time, limit int, includeClosed bool) (*DashboardData, error) {
+	return db.getDashboardData(ctx, limit, includeClosed, &asOf)
+}
+
+// getDashboardData reads the live signals, or when asOf is set the signals
+// as they stood at that instant
+func (db *DB) getDashboardData(ctx context.Context, limit int, includeClosed bool, asOf *time.Time) (*DashboardData, error) {
+	signals, today, since := "intraday.signals", "CURRENT_DATE", time.Now()
+	query := func(name, q string, args ...interface{}) (*sql.Rows, error) {
+		return db.queryHot(ctx, name, q, args...)
+	}
+	if asOf != nil {
+		signals, today, since = signalsAsOf(*asOf), sessionDateOf(*asOf), *asOf
+		query = func(_, q string, args ...interface{}) (*sql.Rows, error) {
+			return db.conn.QueryContext(ctx, q, args...)
+		}
+	}
+
 	data := &DashboardData{
 		ActiveSignals:      []DashboardSignal{},
 		ClosedSignals:      []DashboardSignal{},
@@ -97,12 +125,12 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 			COALESCE(generated_at::text, ''), COALESCE(generated_at::text, ''),
 			COALESCE((generated_at + INTERVAL '6 hours')::text, ''),
 			COALESCE(metadata::text, '{}')
-		FROM intraday.signals
-		WHERE status = 'ACTIVE' AND generated_at >= CURRENT_DATE
+		FROM ` + signals + `
+		WHERE status = 'ACTIVE' AND generated_at >= ` + today + `
 		ORDER BY generated_at DESC
 		LIMIT $1
 	`
-	activeRows, err := db.conn.QueryContext(ctx, activeQuery, limit)
+	activeRows, err := query("dashboard_active", activeQuery, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active signals: %w", err)
 	}
@@ -139,13 +167,13 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 				COALESCE(generated_at::text, ''), COALESCE(generated_at::text, ''),
 				COALESCE(closed_at::text, ''),
 				COALESCE(metadata::text, '{}')
-			FROM intraday.signals
+			FROM ` + signals + `
 			WHERE status IN ('HIT_TARGET', 'HIT_STOPLOSS', 'TRAILING_STOP', 'TIME_EXIT', 'EXPIRED')
-				AND generated_at >= CURRENT_DATE
+				AND generated_at >= ` + today + `
 			ORDER BY closed_at DESC
 			LIMIT $1
 		`
-		closedRows, err := db.conn.QueryContext(ctx, closedQuery, limit)
+		closedRows, err := query("dashboard_closed", closedQuery, limit)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query closed signals: %w", err)
 		}
@@ -173,6 +201,25 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 		}
 	}
 
+	// Recent article count and latest headline per signal symbol
+	symbols := make([]string, 0, len(data.ActiveSignals)+len(data.ClosedSignals))
+	for _, s := range data.ActiveSignals {
+		symbols = append(symbols, s.Symbol)
+	}
+	for _, s := range data.ClosedSignals {
+		symbols = append(symbols, s.Symbol)
+	}
+	if news, err := db.GetNewsContextAt(ctx, symbols, since); err != nil {
+		log.Printf("⚠️  News context unavailable for dashboard: %v", err)
+	} else {
+		for i := range data.ActiveSignals {
+			data.ActiveSignals[i].News = news[data.ActiveSignals[i].Symbol]
+		}
+		for i := range data.ClosedSignals {
+			data.ClosedSignals[i].News = news[data.ClosedSignals[i].Symbol]
+		}
+	}
+
 	// Statistics - using result column to count hits/misses
 	// HIT includes: HIT_TARGET + profitable TIME_EXIT/TRAILING_STOP
 	// MISS includes: HIT_STOPLOSS + unprofitable TIME_EXIT/TRAILING_STOP
@@ -191,8 +238,8 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 				NULLIF(COUNT(*) FILTER (WHERE result IS NOT NULL), 0) * 100,
 				2
 			) as success_rate
-		FROM intraday.signals
-		WHERE generated_at >= CURRENT_DATE
+		FROM ` + signals + `
+		WHERE generated_at >= ` + today + `
 	`).Scan(
 		&data.Statistics.TotalSignals, &data.Statistics.ActiveCount,
 		&data.Statistics.Hits, &data.Statistics.Misses, &data.Statistics.Expired,
@@ -210,8 +257,8 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 			COUNT(*) as signal_count,
 			COUNT(*) FILTER (WHERE status = 'HIT_TARGET') as wins,
 			COALESCE(AVG(actual_profit_pct) FILTER (WHERE actual_profit_pct IS NOT NULL), 0) as avg_profit
-		FROM intraday.signals
-		WHERE generated_at >= CURRENT_DATE - INTERVAL '7 days'
+		FROM ` + signals + `
+		WHERE generated_at >= ` + today + ` - INTERVAL '7 days'
 		GROUP BY symbol, stock_name
 		HAVING COUNT(*) >= 2
 		ORDER BY wins DESC, avg_profit DESC
@@ -235,8 +282,8 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 			COUNT(*) as count,
 			COALESCE(AVG(confidence_score), 0) as avg_confidence,
 			COUNT(*) FILTER (WHERE status = 'HIT_TARGET') as hits
-		FROM intraday.signals
-		WHERE generated_at >= CURRENT_DATE
+		FROM ` + signals + `
+		WHERE generated_at >= ` + today + `
 		GROUP BY signal_type
 		ORDER BY count DESC
 	`
@@ -256,6 +303,9 @@ func (db *DB) GetDashboardData(ctx context.Context, limit int, includeClosed boo
 		"active_count": len(data.ActiveSignals),
 		"closed_count": len(data.ClosedSignals),
 	}
+	if asOf != nil {
+		data.Metadata["as_of"] = asOf.Format(time.RFC3339)
+	}
 
 	return data, nil
 }
@@ -476,13 +526,23 @@ func (db *DB) GetSignalAlerts(ctx context.Context, strategy string, minConfidenc
 			COALESCE(a.url, ''),
 			COALESCE(a.source, 'Unknown'),
 			COALESCE(a.llm_sentiment, 'neutral'),
-			COALESCE(a.llm_confidence, 0.5)
-		FROM news.articles a
-		WHERE a.published_at >= CURRENT_DATE - INTERVAL '2 days'
-			AND a.llm_sentiment IS NOT NULL
-			AND COALESCE(a.llm_confidence, 0) >= $1
+			COALESCE(a.llm_confidence, 0.5),
+			e.symbols
+		FROM (
+			SELECT *
+			FROM news.articles
+			WHERE published_at >= CURRENT_DATE - INTERVAL '2 days'
+				AND llm_sentiment IS NOT NULL
+				AND COALESCE(llm_confidence, 0) >= $1
+			ORDER BY published_at DESC
+			LIMIT 50
+		) a
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(array_agg(ae.symbol ORDER BY ae.symbol), '{}') AS symbols
+			FROM news.article_entities ae
+			WHERE ae.article_id = a.id
+		) e ON true
 		ORDER BY a.published_at DESC
-		LIMIT 50
 	`
 
 	rows, err := db.conn.QueryContext(ctx, query, minConfidence)
@@ -495,7 +555,8 @@ func (db *DB) GetSignalAlerts(ctx context.Context, strategy string, minConfidenc
 	for rows.Next() {
 		var id, createdAt, title, link, source, sentiment string
 		var confidence float64
-		if err := rows.Scan(&id, &createdAt, &title, &link, &source, &sentiment, &confidence); err != nil {
+		symbols := []string{}
+		if err := rows.Scan(&id, &createdAt, &title, &link, &source, &sentiment, &confidence, pq.Array(&symbols)); err != nil {
 			continue
 		}
 
@@ -531,7 +592,7 @@ func (db *DB) GetSignalAlerts(ctx context.Context, strategy string, minConfidenc
 			Confidence: confidence,
 			Duration:   "1-3 days",
 			Sectors:    []string{},
-			Symbols:    []string{},
+			Symbols:    symbols,
 			Rationale:  fmt.Sprintf("News sentiment: %s (%.0f%% confidence)", sentiment, confidence*100),
 			Meta:       nil,
 		}
@@ -542,34 +603,6 @@ func (db *DB) GetSignalAlerts(ctx context.Context, strategy string, minConfidenc
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	// Fetch entities for all alerts
-	if len(alerts) > 0 {
-		alertIDs := make([]string, len(alerts))
-		alertMap := make(map[string]int)
-		for i, a := range alerts {
-			alertIDs[i] = a.ID
-			alertMap[a.ID] = i
-		}
-
-		entityQuery := `
-			SELECT article_id, symbol
-			FROM news.article_entities
-			WHERE article_id = ANY($1)
-		`
-		entityRows, err := db.conn.QueryContext(ctx, entityQuery, alertIDs)
-		if err == nil {
-			defer entityRows.Close()
-			for entityRows.Next() {
-				var articleID, sym string
-				if err := entityRows.Scan(&articleID, &sym); err == nil {
-					if idx, ok := alertMap[articleID]; ok {
-						alerts[idx].Symbols = append(alerts[idx].Symbols, sym)
-					}
-				}
-			}
-		}
-	}
-
 	if alerts == nil {
 		alerts = []NewsAlert{}
 	}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// stmtCache prepares the hot statements behind the dashboard, active
+// signals and price lookups once per pool and reuses them, so Postgres
+// parses and plans them once per connection instead of once per request.
+// database/sql prepares a *sql.Stmt on each connection the first time it
+// runs there. Like statement_timeout, this needs PgBouncer in session
+// pooling mode; DB_PREPARED_STATEMENTS=false turns it off.
+type stmtCache struct {
+	enabled bool
+	mu      sync.Mutex
+	stmts   map[string]*cachedStmt
+	// prepares counts statements prepared on a connection, by SQL, as seen
+	// by the driver
+	prepares *prepareCounter
+}
+
+type cachedStmt struct {
+	name  string
+	query string
+	stmt  *sql.Stmt
+	uses  atomic.Int64
+}
+
+// prepareCounter counts driver-level prepares by SQL text
+type prepareCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newPrepareCounter() *prepareCounter {
+	return &prepareCounter{counts: map[string]int64{}}
+}
+
+func (p *prepareCounter) add(query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts[query]++
+}
+
+func (p *prepareCounter) get(query string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.counts[query]
+}
+
+func newStmtCache(prepares *prepareCounter) *stmtCache {
+	enabled := !strings.EqualFold(os.Getenv("DB_PREPARED_STATEMENTS"), "false")
+	return &stmtCache{enabled: enabled, stmts: map[string]*cachedStmt{}, prepares: prepares}
+}
+
+// stmt returns the pool-wide statement for query, preparing it on first
+// use. It returns nil when caching is off or the statement can't be
+// prepared, and the caller runs the query unprepared.
+func (db *DB) stmt(ctx context.Context, name, query string) *cachedStmt {
+	c := db.stmts
+	if !c.enabled {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cs, ok := c.stmts[name]; ok {
+		return cs
+	}
+	stmt, err := db.conn.PrepareContext(ctx, query)
+	if err != nil {
+		// Unless the request gave up, don't retry on every request; the
+		// query still runs, just unprepared
+		log.Printf("⚠️  Failed to prepare %s, running it unprepared: %v", name, err)
+		if ctx.Err() == nil {
+			c.stmts[name] = nil
+		}
+		return nil
+	}
+	cs := &cachedStmt{name: name, query: query, stmt: stmt}
+	c.stmts[name] = cs
+	return cs
+}
+
+// queryHot runs a hot query through its prepared statement
+func (db *DB) queryHot(ctx context.Context, name, query string, args ...interface{}) (*sql.Rows, error) {
+	if cs := db.stmt(ctx, name, query); cs != nil {
+		cs.uses.Add(1)
+		return cs.stmt.QueryContext(ctx, args...)
+	}
+	return db.conn.QueryContext(ctx, query, args...)
+}
+
+// queryRowHot runs a hot single-row query through its prepared statement
+func (db *DB) queryRowHot(ctx context.Context, name, query string, args ...interface{}) *sql.Row {
+	if cs := db.stmt(ctx, name, query); cs != nil {
+		cs.uses.Add(1)
+		return cs.stmt.QueryRowContext(ctx, args...)
+	}
+	return db.conn.QueryRowContext(ctx, query, args...)
+}
+
+// closeStmts releases the prepared statements
+func (db *DB) closeStmts() {
+	db.stmts.mu.Lock()
+	defer db.stmts.mu.Unlock()
+	for name, cs := range db.stmts.stmts {
+		if cs != nil {
+			cs.stmt.Close()
+		}
+		delete(db.stmts.stmts, name)
+	}
+}
+
+// PreparedStatement reports how often a hot statement ran and how often it
+// had to be prepared on a connection first. HitRate is the share of runs
+// that found it already prepared.
+type PreparedStatement struct {
+	Name     string  `json:"name"`
+	Uses     int64   `json:"uses"`
+	Prepares int64   `json:"prepares"`
+	HitRate  float64 `json:"hit_rate"`
+}
+
+// PreparedStatementStats summarises the statement cache
+type PreparedStatementStats struct {
+	Enabled    bool                `json:"enabled"`
+	Uses       int64               `json:"uses"`
+	Prepares   int64               `json:"prepares"`
+	HitRate    float64             `json:"hit_rate"`
+	Statements []PreparedStatement `json:"statements"`
+}
+
+// hitRate is the share of uses that didn't need a prepare
+func hitRate(uses, prepares int64) float64 {
+	if uses == 0 {
+		return 0
+	}
+	return max(0, float64(uses-prepares)/float64(uses))
+}
+
+// PreparedStatementStats returns use and prepare counts per hot statement
+func (db *DB) PreparedStatementStats() PreparedStatementStats {
+	c := db.stmts
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := PreparedStatementStats{Enabled: c.enabled, Statements: []PreparedStatement{}}
+	for _, cs := range c.stmts {
+		if cs == nil {
+			continue
+		}
+		ps := PreparedStatement{Name: cs.name, Uses: cs.uses.Load(), Prepares: c.prepares.get(cs.query)}
+		ps.HitRate = hitRate(ps.Uses, ps.Prepares)
+		stats.Uses += ps.Uses
+		stats.Prepares += ps.Prepares
+		stats.Statements = append(stats.Statements, ps)
+	}
+	stats.HitRate = hitRate(stats.Uses, stats.Prepares)
+	sort.Slice(stats.Statements, func(i, j int) bool { return stats.Statements[i].Name < stats.Statements[j].Name })
+	return stats
+}
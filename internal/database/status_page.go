@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StatusUptime counts a component's health checks over a period
+type StatusUptime struct {
+	Checks         int
+	DegradedChecks int
+	OutageChecks   int
+}
+
+// StatusIncident is a period a component was degraded or down
+type StatusIncident struct {
+	ID         int64      `json:"id"`
+	Component  string     `json:"component"`
+	Title      string     `json:"title"`
+	Impact     string     `json:"impact"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// RecordStatusCheck adds a health check result to the component's daily
+// tally. level is "operational", "degraded" or "outage".
+func (db *DB) RecordStatusCheck(ctx context.Context, component string, day time.Time, level string) error {
+	var degraded, outage int
+	switch level {
+	case "degraded":
+		degraded = 1
+	case "outage":
+		outage = 1
+	}
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.status_daily (component, day, checks, degraded_checks, outage_checks)
+		VALUES ($1, $2::date, 1, $3, $4)
+		ON CONFLICT (component, day) DO UPDATE SET
+			checks = status_daily.checks + 1,
+			degraded_checks = status_daily.degraded_checks + EXCLUDED.degraded_checks,
+			outage_checks = status_daily.outage_checks + EXCLUDED.outage_checks
+	`, component, day.Format("2006-01-02"), degraded, outage)
+	if err != nil {
+		return fmt.Errorf("failed to record status check: %w", err)
+	}
+	return nil
+}
+
+// GetStatusUptime tallies each component's checks from since onwards
+func (db *DB) GetStatusUptime(ctx context.Context, since time.Time) (map[string]StatusUptime, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT component, SUM(checks), SUM(degraded_checks), SUM(outage_checks)
+		FROM core_api.status_daily
+		WHERE day >= $1::date
+		GROUP BY component
+	`, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status uptime: %w", err)
+	}
+	defer rows.Close()
+
+	uptime := map[string]StatusUptime{}
+	for rows.Next() {
+		var component string
+		var u StatusUptime
+		if err := rows.Scan(&component, &u.Checks, &u.DegradedChecks, &u.OutageChecks); err != nil {
+			return nil, fmt.Errorf("failed to scan status uptime: %w", err)
+		}
+		uptime[component] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return uptime, nil
+}
+
+// OpenStatusIncident opens an incident for a component, or escalates the
+// open one to an outage. At most one incident per component is open.
+func (db *DB) OpenStatusIncident(ctx context.Context, component, title, impact string, at time.Time) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.status_incidents (component, title, impact, started_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (component) WHERE resolved_at IS NULL DO UPDATE SET
+			title = CASE WHEN EXCLUDED.impact = 'outage' THEN EXCLUDED.title ELSE status_incidents.title END,
+			impact = CASE WHEN EXCLUDED.impact = 'outage' THEN 'outage' ELSE status_incidents.impact END
+	`, component, title, impact, at)
+	if err != nil {
+		return fmt.Errorf("failed to open status incident: %w", err)
+	}
+	return nil
+}
+
+// ResolveStatusIncident closes a component's open incident, if any
+func (db *DB) ResolveStatusIncident(ctx context.Context, component string, at time.Time) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE core_api.status_incidents
+		SET resolved_at = $2
+		WHERE component = $1 AND resolved_at IS NULL
+	`, component, at)
+	if err != nil {
+		return fmt.Errorf("failed to resolve status incident: %w", err)
+	}
+	return nil
+}
+
+// ListStatusIncidents returns open incidents and those started since, newest first
+func (db *DB) ListStatusIncidents(ctx context.Context, since time.Time, limit int) ([]StatusIncident, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, component, title, impact, started_at, resolved_at
+		FROM core_api.status_incidents
+		WHERE resolved_at IS NULL OR started_at >= $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []StatusIncident{}
+	for rows.Next() {
+		var i StatusIncident
+		if err := rows.Scan(&i.ID, &i.Component, &i.Title, &i.Impact, &i.StartedAt, &i.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status incident: %w", err)
+		}
+		incidents = append(incidents, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return incidents, nil
+}
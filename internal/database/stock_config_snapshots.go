@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StockConfigSnapshot is a symbol's stock config as it stood on a trading
+// day
+type StockConfigSnapshot struct {
+	Symbol            string  `json:"symbol"`
+	Exchange          string  `json:"exchange"`
+	Name              *string `json:"name"`
+	Sector            *string `json:"sector"`
+	MarketCapCategory *string `json:"market_cap_category"`
+	IntradayEnabled   bool    `json:"intraday_enabled"`
+	InvestmentEnabled bool    `json:"investment_enabled"`
+	Active            bool    `json:"active"`
+	IntradayAIPicked  *bool   `json:"intraday_ai_picked"`
+	SelectionType     *string `json:"selection_type"`
+}
+
+// StockConfigAsOf is the latest snapshot taken on or before a date
+type StockConfigAsOf struct {
+	SnapshotDate string                `json:"snapshot_date"`
+	TakenAt      string                `json:"taken_at"`
+	Stocks       []StockConfigSnapshot `json:"stocks"`
+}
+
+// SnapshotStockConfig copies md.stock_config into the snapshot for date
+// unless one was already taken, returning the rows written
+func (db *DB) SnapshotStockConfig(ctx context.Context, date time.Time) (int64, error) {
+	res, err := db.conn.ExecContext(ctx, `
+		INSERT INTO core_api.stock_config_snapshots (snapshot_date, symbol, exchange, name, sector,
+			market_cap_category, intraday_enabled, investment_enabled, active, intraday_ai_picked, selection_type)
+		SELECT $1::date, symbol, exchange, name, sector, market_cap_category, intraday_enabled,
+			investment_enabled, active, intraday_ai_picked, selection_type
+		FROM md.stock_config
+		WHERE NOT EXISTS (SELECT 1 FROM core_api.stock_config_snapshots WHERE snapshot_date = $1::date)
+		ON CONFLICT DO NOTHING
+	`, date.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot stock config: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// HasStockConfigSnapshot reports whether date's snapshot has been taken
+func (db *DB) HasStockConfigSnapshot(ctx context.Context, date time.Time) (bool, error) {
+	var exists bool
+	if err := db.conn.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM core_api.stock_config_snapshots WHERE snapshot_date = $1::date)
+	`, date.Format("2006-01-02")).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check stock config snapshot: %w", err)
+	}
+	return exists, nil
+}
+
+// GetStockConfigAsOf returns the latest snapshot taken on or before date,
+// optionally only its intraday-enabled symbols, or nil when none was
+func (db *DB) GetStockConfigAsOf(ctx context.Context, date time.Time, intradayOnly bool) (*StockConfigAsOf, error) {
+	var snapshotDate, takenAt time.Time
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT snapshot_date, MIN(taken_at)
+		FROM core_api.stock_config_snapshots
+		WHERE snapshot_date = (
+			SELECT MAX(snapshot_date) FROM core_api.stock_config_snapshots WHERE snapshot_date <= $1::date
+		)
+		GROUP BY snapshot_date
+	`, date.Format("2006-01-02")).Scan(&snapshotDate, &takenAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock config snapshot: %w", err)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, exchange, name, sector, market_cap_category, intraday_enabled, investment_enabled,
+			active, intraday_ai_picked, selection_type
+		FROM core_api.stock_config_snapshots
+		WHERE snapshot_date = $1 AND (NOT $2 OR intraday_enabled)
+		ORDER BY symbol, exchange
+	`, snapshotDate, intradayOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock config snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	asOf := &StockConfigAsOf{
+		SnapshotDate: snapshotDate.Format("2006-01-02"),
+		TakenAt:      takenAt.Format(time.RFC3339),
+		Stocks:       []StockConfigSnapshot{},
+	}
+	for rows.Next() {
+		var s StockConfigSnapshot
+		if err := rows.Scan(&s.Symbol, &s.Exchange, &s.Name, &s.Sector, &s.MarketCapCategory, &s.IntradayEnabled,
+			&s.InvestmentEnabled, &s.Active, &s.IntradayAIPicked, &s.SelectionType); err != nil {
+			return nil, fmt.Errorf("failed to scan stock config snapshot: %w", err)
+		}
+		asOf.Stocks = append(asOf.Stocks, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return asOf, nil
+}
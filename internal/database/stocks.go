@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"fmt"
+	"log"
+	"time"
 )
 
 // TopMover represents a top gainer or loser stock
@@ -25,6 +27,8 @@ type RealtimePrice struct {
 	Close         float64  `json:"close"`
 	ChangePercent *float64 `json:"change_percent"`
 	UpdatedAt     string   `json:"updated_at"`
+	// News is recent news context, only set for single-symbol lookups
+	News *NewsContext `json:"news,omitempty"`
 }
 
 // StockData represents detailed stock information
@@ -119,8 +123,67 @@ func (db *DB) GetTopLosers(ctx context.Context, limit int) ([]TopMover, error) {
 	return results, nil
 }
 
+// MoverQuote is one symbol's latest price and day change, used to seed and
+// reconcile the in-memory top-movers cache
+type MoverQuote struct {
+	Symbol        string
+	Name          string
+	Price         float64
+	ChangePercent float64
+	UpdatedAt     time.Time
+}
+
+// GetMoverQuotes returns every symbol priced within the last day, with the
+// same filters as GetTopGainers and GetTopLosers
+func (db *DB) GetMoverQuotes(ctx context.Context) ([]MoverQuote, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT
+			rp.symbol,
+			COALESCE(sc.name, rp.symbol) as name,
+			COALESCE(rp.last_price, 0) as price,
+			rp.change_percent,
+			rp.updated_at
+		FROM md.realtime_prices rp
+		LEFT JOIN md.stock_config sc ON sc.symbol = rp.symbol AND sc.exchange = COALESCE(rp.exchange, 'NSE')
+		WHERE rp.change_percent IS NOT NULL
+			AND rp.updated_at > NOW() - INTERVAL '1 day'
+			AND rp.symbol IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mover quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var results []MoverQuote
+	for rows.Next() {
+		var q MoverQuote
+		if err := rows.Scan(&q.Symbol, &q.Name, &q.Price, &q.ChangePercent, &q.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mover quote: %w", err)
+		}
+		results = append(results, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return results, nil
+}
+
 // GetRealtimePrices returns latest prices for multiple stocks
 func (db *DB) GetRealtimePrices(ctx context.Context, limit int) ([]RealtimePrice, error) {
+	var results []RealtimePrice
+	err := db.StreamRealtimePrices(ctx, limit, func(p RealtimePrice) error {
+		results = append(results, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StreamRealtimePrices calls fn for each of the latest prices without
+// buffering the result set. An error from fn stops the scan and is returned.
+func (db *DB) StreamRealtimePrices(ctx context.Context, limit int, fn func(RealtimePrice) error) error {
 	query := `
 		SELECT
 			symbol,
@@ -140,22 +203,23 @@ func (db *DB) GetRealtimePrices(ctx context.Context, limit int) ([]RealtimePrice
 	`
 	rows, err := db.conn.QueryContext(ctx, query, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query realtime prices: %w", err)
+		return fmt.Errorf("failed to query realtime prices: %w", err)
 	}
 	defer rows.Close()
 
-	var results []RealtimePrice
 	for rows.Next() {
 		var p RealtimePrice
 		if err := rows.Scan(&p.Symbol, &p.LastPrice, &p.Volume, &p.Open, &p.High, &p.Low, &p.Close, &p.ChangePercent, &p.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan realtime price: %w", err)
+			return fmt.Errorf("failed to scan realtime price: %w", err)
+		}
+		if err := fn(p); err != nil {
+			return err
 		}
-		results = append(results, p)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return fmt.Errorf("rows iteration error: %w", err)
 	}
-	return results, nil
+	return nil
 }
 
 // GetRealtimePrice returns the latest price for a single stock
@@ -176,12 +240,18 @@ func (db *DB) GetRealtimePrice(ctx context.Context, symbol string) (*RealtimePri
 		LIMIT 1
 	`
 	var p RealtimePrice
-	err := db.conn.QueryRowContext(ctx, query, symbol).Scan(
+	err := db.queryRowHot(ctx, "realtime_price", query, symbol).Scan(
 		&p.Symbol, &p.LastPrice, &p.Volume, &p.Open, &p.High, &p.Low, &p.Close, &p.ChangePercent, &p.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get realtime price for %s: %w", symbol, err)
 	}
+	// News context is a nice-to-have; the price is still served without it
+	if news, err := db.GetNewsContext(ctx, []string{p.Symbol}); err != nil {
+		log.Printf("⚠️  News context unavailable for %s: %v", p.Symbol, err)
+	} else {
+		p.News = news[p.Symbol]
+	}
 	return &p, nil
 }
 
@@ -244,3 +314,35 @@ func (db *DB) SearchStocks(ctx context.Context, query string) ([]StockSearchResu
 	}
 	return results, nil
 }
+
+// GetLatestPrices returns the latest traded price keyed by symbol
+func (db *DB) GetLatestPrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(symbols))
+	if len(symbols) == 0 {
+		return prices, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT DISTINCT ON (symbol) symbol, last_price
+		FROM md.realtime_prices
+		WHERE symbol = ANY($1) AND last_price IS NOT NULL
+		ORDER BY symbol, updated_at DESC
+	`, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest prices: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var price float64
+		if err := rows.Scan(&symbol, &price); err != nil {
+			return nil, fmt.Errorf("failed to scan latest price: %w", err)
+		}
+		prices[symbol] = price
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return prices, nil
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BetaLookbackDays is how many calendar days of daily bars betas use
+const BetaLookbackDays = 180
+
+// minBetaReturns is the fewest paired daily returns a beta is estimated
+// from; symbols with fewer are assumed to move with the index
+const minBetaReturns = 30
+
+// StressShock is a scenario in percent price moves. IndexPct moves every
+// position by its beta to NIFTY 50; sector and stock shocks are added on
+// top to positions in that sector or symbol, e.g. a -5% BANKING sector
+// shock or a -15% gap in one name.
+type StressShock struct {
+	IndexPct float64            `json:"index_pct"`
+	Sectors  map[string]float64 `json:"sectors"`
+	Stocks   map[string]float64 `json:"stocks"`
+}
+
+// StressPosition is one position's projected move under a scenario
+type StressPosition struct {
+	Source    string  `json:"source"`
+	ID        string  `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Sector    string  `json:"sector"`
+	Direction string  `json:"direction"`
+	Notional  float64 `json:"notional"`
+	Beta      float64 `json:"beta"`
+	// BetaAssumed is set when history was too short and a beta of 1 is used
+	BetaAssumed bool    `json:"beta_assumed"`
+	MovePct     float64 `json:"move_pct"`
+	PnL         float64 `json:"pnl"`
+}
+
+// StressSector totals projected P&L for one sector
+type StressSector struct {
+	Sector   string  `json:"sector"`
+	Notional float64 `json:"notional"`
+	PnL      float64 `json:"pnl"`
+}
+
+// StressResult is a scenario's projected P&L per position and in total.
+// PnLPct is relative to gross exposure.
+type StressResult struct {
+	Shock          StressShock      `json:"shock"`
+	SignalNotional float64          `json:"signal_notional"`
+	Gross          float64          `json:"gross"`
+	PnL            float64          `json:"pnl"`
+	PnLPct         float64          `json:"pnl_pct"`
+	Positions      []StressPosition `json:"positions"`
+	BySector       []StressSector   `json:"by_sector"`
+	Timestamp      string           `json:"timestamp"`
+}
+
+// RunStressTest applies shock to an account's exposure, i.e. holdings and
+// active signals sized at signalNotional
+func (db *DB) RunStressTest(ctx context.Context, accountID string, signalNotional float64, shock StressShock) (*StressResult, error) {
+	exposure, err := db.GetExposure(ctx, accountID, signalNotional)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(exposure.Positions))
+	for _, p := range exposure.Positions {
+		symbols = append(symbols, p.Symbol)
+	}
+	betas, err := db.GetSymbolBetas(ctx, symbols, time.Now().AddDate(0, 0, -BetaLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	result := applyStress(exposure.Positions, betas, shock)
+	result.SignalNotional = signalNotional
+	return result, nil
+}
+
+// applyStress projects each position's move as beta × index shock plus its
+// sector and stock shocks, with shorts gaining when prices fall
+func applyStress(positions []ExposurePosition, betas map[string]float64, shock StressShock) *StressResult {
+	sectorShocks := map[string]float64{}
+	for k, v := range shock.Sectors {
+		sectorShocks[strings.ToUpper(k)] = v
+	}
+	stockShocks := map[string]float64{}
+	for k, v := range shock.Stocks {
+		stockShocks[strings.ToUpper(k)] = v
+	}
+
+	result := &StressResult{
+		Shock:     shock,
+		Positions: []StressPosition{},
+		BySector:  []StressSector{},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	sectors := map[string]*StressSector{}
+	for _, p := range positions {
+		beta, ok := betas[p.Symbol]
+		if !ok {
+			beta = 1
+		}
+		move := beta*shock.IndexPct + sectorShocks[p.Sector] + stockShocks[p.Symbol]
+		pnl := p.Notional * move / 100
+		if p.Direction == ExposureShort {
+			pnl = -pnl
+		}
+
+		result.Positions = append(result.Positions, StressPosition{
+			Source:      p.Source,
+			ID:          p.ID,
+			Symbol:      p.Symbol,
+			Sector:      p.Sector,
+			Direction:   p.Direction,
+			Notional:    p.Notional,
+			Beta:        round4(beta),
+			BetaAssumed: !ok,
+			MovePct:     round4(move),
+			PnL:         round2(pnl),
+		})
+		result.Gross += p.Notional
+		result.PnL += pnl
+
+		if sectors[p.Sector] == nil {
+			sectors[p.Sector] = &StressSector{Sector: p.Sector}
+		}
+		sectors[p.Sector].Notional += p.Notional
+		sectors[p.Sector].PnL += pnl
+	}
+
+	if result.Gross > 0 {
+		result.PnLPct = round4(result.PnL / result.Gross * 100)
+	}
+	result.Gross = round2(result.Gross)
+	result.PnL = round2(result.PnL)
+	for _, s := range sectors {
+		s.Notional = round2(s.Notional)
+		s.PnL = round2(s.PnL)
+		result.BySector = append(result.BySector, *s)
+	}
+	// Hardest hit first
+	sort.Slice(result.BySector, func(i, j int) bool { return result.BySector[i].PnL < result.BySector[j].PnL })
+	sort.Slice(result.Positions, func(i, j int) bool { return result.Positions[i].PnL < result.Positions[j].PnL })
+	return result
+}
+
+// GetSymbolBetas estimates each symbol's beta to NIFTY 50 from daily
+// returns since since. Symbols with fewer than minBetaReturns paired
+// returns are left out.
+func (db *DB) GetSymbolBetas(ctx context.Context, symbols []string, since time.Time) (map[string]float64, error) {
+	betas := map[string]float64{}
+	if len(symbols) == 0 {
+		return betas, nil
+	}
+
+	index, _, err := db.GetIndexSeries(ctx, benchmarkIndex, since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	indexClose := make(map[string]float64, len(index))
+	for _, p := range index {
+		indexClose[p.Date.Format("2006-01-02")] = p.Close
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, trade_date, close::float8
+		FROM md.bhavcopy
+		WHERE symbol = ANY($1) AND series = 'EQ' AND trade_date >= $2::date AND close > 0
+		ORDER BY symbol, trade_date
+	`, pq.Array(symbols), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily closes: %w", err)
+	}
+	defer rows.Close()
+
+	// Returns are paired on consecutive dates both series traded
+	type pair struct{ stock, index []float64 }
+	pairs := map[string]*pair{}
+	var symbol string
+	var prevStock, prevIndex float64
+	for rows.Next() {
+		var s string
+		var date time.Time
+		var px float64
+		if err := rows.Scan(&s, &date, &px); err != nil {
+			return nil, fmt.Errorf("failed to scan daily close: %w", err)
+		}
+		if s != symbol {
+			symbol, prevStock, prevIndex = s, 0, 0
+			pairs[s] = &pair{}
+		}
+		ic, ok := indexClose[date.Format("2006-01-02")]
+		if !ok || ic <= 0 {
+			continue
+		}
+		if prevStock > 0 {
+			p := pairs[s]
+			p.stock = append(p.stock, px/prevStock-1)
+			p.index = append(p.index, ic/prevIndex-1)
+		}
+		prevStock, prevIndex = px, ic
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	for s, p := range pairs {
+		if len(p.stock) < minBetaReturns {
+			continue
+		}
+		if beta, ok := regressionBeta(p.stock, p.index); ok {
+			betas[s] = beta
+		}
+	}
+	return betas, nil
+}
+
+// regressionBeta is cov(stock, index) / var(index)
+func regressionBeta(stock, index []float64) (float64, bool) {
+	n := float64(len(stock))
+	var ms, mi float64
+	for i := range stock {
+		ms += stock[i]
+		mi += index[i]
+	}
+	ms /= n
+	mi /= n
+	var cov, variance float64
+	for i := range stock {
+		cov += (stock[i] - ms) * (index[i] - mi)
+		variance += (index[i] - mi) * (index[i] - mi)
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return cov / variance, true
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// StockSelectionCounts breaks down active stocks by fetcher and selection type
+type StockSelectionCounts struct {
+	TotalEnabled  int `json:"total_enabled"`
+	ZerodhaCount  int `json:"zerodha_count"`
+	IndmoneyCount int `json:"indmoney_count"`
+	MLSelected    int `json:"ml_selected"`
+	WildcardCount int `json:"wildcard_count"`
+	ManualCount   int `json:"manual_count"`
+}
+
+// GetSystemConfig returns a md.system_config value. ok is false when the key
+// is missing or NULL.
+func (db *DB) GetSystemConfig(ctx context.Context, key string) (string, bool, error) {
+	var value sql.NullString
+	err := db.conn.QueryRowContext(ctx,
+		"SELECT config_value FROM md.system_config WHERE config_key = $1", key,
+	).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to query config %s: %w", key, err)
+	}
+	return value.String, value.Valid, nil
+}
+
+// SystemConfigValue is a md.system_config value with who last set it
+type SystemConfigValue struct {
+	Key       string
+	Value     *string
+	UpdatedBy *string
+	UpdatedAt time.Time
+}
+
+// GetSystemConfigValues returns the md.system_config rows for keys, by key.
+// Missing keys are left out.
+func (db *DB) GetSystemConfigValues(ctx context.Context, keys []string) (map[string]SystemConfigValue, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT config_key, config_value, updated_by, updated_at
+		FROM md.system_config
+		WHERE config_key = ANY($1)
+	`, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config: %w", err)
+	}
+	defer rows.Close()
+
+	values := map[string]SystemConfigValue{}
+	for rows.Next() {
+		var v SystemConfigValue
+		if err := rows.Scan(&v.Key, &v.Value, &v.UpdatedBy, &v.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan config: %w", err)
+		}
+		values[v.Key] = v
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return values, nil
+}
+
+// PutSystemConfig creates or updates a md.system_config value as updatedBy.
+// It returns the value it replaced, nil if there was none, and when the new
+// value was written.
+func (db *DB) PutSystemConfig(ctx context.Context, key, value, description, updatedBy string) (*string, time.Time, error) {
+	var previous *string
+	var updatedAt time.Time
+	err := db.conn.QueryRowContext(ctx, `
+		WITH prev AS (
+			SELECT config_value FROM md.system_config WHERE config_key = $1
+		)
+		INSERT INTO md.system_config (config_key, config_value, description, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (config_key) DO UPDATE SET
+			config_value = EXCLUDED.config_value,
+			description = EXCLUDED.description,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING (SELECT config_value FROM prev), updated_at
+	`, key, value, description, updatedBy).Scan(&previous, &updatedAt)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to update config %s: %w", key, err)
+	}
+	return previous, updatedAt, nil
+}
+
+// GetStockSelectionCounts counts active stocks by fetcher and selection type
+func (db *DB) GetStockSelectionCounts(ctx context.Context) (*StockSelectionCounts, error) {
+	var c StockSelectionCounts
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) as total_enabled,
+			COUNT(*) FILTER (WHERE fetcher = 'ZERODHA') as zerodha_count,
+			COUNT(*) FILTER (WHERE fetcher = 'INDMONEY') as indmoney_count,
+			COUNT(*) FILTER (WHERE selection_type = 'MORNING_ML') as ml_selected,
+			COUNT(*) FILTER (WHERE selection_type = 'WILDCARD_NEWS') as wildcard_count,
+			COUNT(*) FILTER (WHERE selection_type IS NULL OR selection_type = '') as manual_count
+		FROM md.stock_config
+		WHERE active = true
+	`).Scan(&c.TotalEnabled, &c.ZerodhaCount, &c.IndmoneyCount, &c.MLSelected, &c.WildcardCount, &c.ManualCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock counts: %w", err)
+	}
+	return &c, nil
+}
+
+// ClearMLSelections removes all AI stock picks
+func (db *DB) ClearMLSelections(ctx context.Context) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE md.stock_config
+		SET intraday_ai_picked = FALSE,
+			selection_type = NULL
+		WHERE intraday_ai_picked = TRUE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to clear ML selections: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TimeoutPolicy sets server-side statement_timeout budgets per query class.
+// Client-side context deadlines still apply; lib/pq sends a cancel request
+// to the server when a context ends, and statement_timeout is the backstop
+// for queries whose caller never gives up.
+type TimeoutPolicy struct {
+	// Interactive is the session default for every pooled connection
+	Interactive time.Duration `json:"interactive"`
+	// Analytics applies to the separate analytics pool
+	Analytics time.Duration `json:"analytics"`
+	// AnalyticsMaxConns caps the analytics pool so heavy reports cannot
+	// exhaust connections needed by interactive endpoints
+	AnalyticsMaxConns int `json:"analytics_max_conns"`
+}
+
+// TimeoutPolicyFromEnv reads DB_STATEMENT_TIMEOUT_SECONDS (default 15),
+// DB_ANALYTICS_STATEMENT_TIMEOUT_SECONDS (default 120) and
+// DB_ANALYTICS_MAX_CONNS (default 5). A timeout of 0 disables it.
+func TimeoutPolicyFromEnv() TimeoutPolicy {
+	p := TimeoutPolicy{Interactive: 15 * time.Second, Analytics: 2 * time.Minute, AnalyticsMaxConns: 5}
+	if v, err := strconv.Atoi(os.Getenv("DB_STATEMENT_TIMEOUT_SECONDS")); err == nil && v >= 0 {
+		p.Interactive = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("DB_ANALYTICS_STATEMENT_TIMEOUT_SECONDS")); err == nil && v >= 0 {
+		p.Analytics = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("DB_ANALYTICS_MAX_CONNS")); err == nil && v > 0 {
+		p.AnalyticsMaxConns = v
+	}
+	return p
+}
+
+// setStatementTimeout is the statement applying d to a session
+func setStatementTimeout(d time.Duration) string {
+	return fmt.Sprintf("SET statement_timeout = %d", d.Milliseconds())
+}
+
+// sessionConnector applies a statement_timeout to every new connection.
+// Behind PgBouncer this requires session pooling; in transaction pooling
+// mode the setting may land on a server connection used by other clients.
+type sessionConnector struct {
+	driver.Connector
+	timeout time.Duration
+	// prepares, if set, counts statements prepared on its connections
+	prepares *prepareCounter
+}
+
+func newSessionConnector(dsn string, timeout time.Duration, prepares *prepareCounter) (*sessionConnector, error) {
+	base, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionConnector{Connector: base, timeout: timeout, prepares: prepares}, nil
+}
+
+// Connect opens a connection and applies the session timeout. Connections
+// record into any dbstats.Stats their queries' contexts carry.
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return withQueryStats(conn, c.prepares), nil
+	}
+	if _, err := execer.ExecContext(ctx, setStatementTimeout(c.timeout), nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+	return withQueryStats(conn, c.prepares), nil
+}
+
+// AnalyticsConn returns the pool for long-running analytical reads, with its
+// own statement_timeout and connection cap
+func (db *DB) AnalyticsConn() *sql.DB {
+	return db.analytics
+}
+
+// TimeoutPolicy returns the configured statement timeouts
+func (db *DB) TimeoutPolicy() TimeoutPolicy {
+	return db.policy
+}
+
+// PoolStats returns connection pool statistics for both pools
+func (db *DB) PoolStats() map[string]sql.DBStats {
+	return map[string]sql.DBStats{
+		"interactive": db.conn.Stats(),
+		"analytics":   db.analytics.Stats(),
+	}
+}
+
+// maintenance runs fn on a dedicated connection with statement_timeout
+// disabled, for migrations, exports, roll-ups and retention that are bounded
+// by their own context deadlines instead. The session default is restored
+// before the connection returns to the pool.
+func (db *DB) maintenance(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, setStatementTimeout(0)); err != nil {
+		return fmt.Errorf("failed to lift statement_timeout: %w", err)
+	}
+
+	fnErr := fn(conn)
+
+	// Restore even if ctx has ended; a connection that cannot be restored is
+	// discarded rather than returned with no timeout
+	restoreCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.ExecContext(restoreCtx, setStatementTimeout(db.policy.Interactive)); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	return fnErr
+}
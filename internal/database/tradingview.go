@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TradingView webhook modes: signal webhooks create active signals, alert
+// webhooks notify the webhook's owner
+const (
+	TradingViewModeSignal = "signal"
+	TradingViewModeAlert  = "alert"
+)
+
+// TradingView delivery statuses
+const (
+	DeliveryAccepted  = "accepted"
+	DeliveryDuplicate = "duplicate"
+	DeliveryRejected  = "rejected"
+	DeliveryFailed    = "failed"
+)
+
+// maxTradingViewDeliveries is how many deliveries are kept per webhook
+const maxTradingViewDeliveries = 500
+
+// TradingViewWebhook receives TradingView alerts. It's identified by its
+// secret, of which only a hash is stored.
+type TradingViewWebhook struct {
+	ID             int64   `json:"id"`
+	UserID         string  `json:"user_id"`
+	Name           string  `json:"name"`
+	Mode           string  `json:"mode"`
+	Enabled        bool    `json:"enabled"`
+	LastDeliveryAt *string `json:"last_delivery_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// TradingViewDelivery is one received alert and what became of it. The
+// payload is stored with its secret removed.
+type TradingViewDelivery struct {
+	ID         int64   `json:"id"`
+	WebhookID  int64   `json:"webhook_id"`
+	Status     string  `json:"status"`
+	Symbol     *string `json:"symbol"`
+	Action     *string `json:"action"`
+	SignalID   *string `json:"signal_id"`
+	Error      *string `json:"error"`
+	Payload    string  `json:"payload"`
+	ReceivedAt string  `json:"received_at"`
+}
+
+const tradingViewWebhookColumns = `id, user_id, name, mode, enabled, last_delivery_at, created_at`
+
+func scanTradingViewWebhook(row rowScanner) (*TradingViewWebhook, error) {
+	var w TradingViewWebhook
+	var lastDelivery sql.NullTime
+	var createdAt time.Time
+	if err := row.Scan(&w.ID, &w.UserID, &w.Name, &w.Mode, &w.Enabled, &lastDelivery, &createdAt); err != nil {
+		return nil, err
+	}
+	if lastDelivery.Valid {
+		formatted := lastDelivery.Time.Format(time.RFC3339)
+		w.LastDeliveryAt = &formatted
+	}
+	w.CreatedAt = createdAt.Format(time.RFC3339)
+	return &w, nil
+}
+
+// getTradingViewWebhook returns the webhook matching a query, or nil
+func (db *DB) getTradingViewWebhook(ctx context.Context, query string, args ...interface{}) (*TradingViewWebhook, error) {
+	w, err := scanTradingViewWebhook(db.conn.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradingview webhook: %w", err)
+	}
+	return w, nil
+}
+
+// CreateTradingViewWebhook stores a webhook for the secret with secretHash
+func (db *DB) CreateTradingViewWebhook(ctx context.Context, userID, name, mode, secretHash string) (*TradingViewWebhook, error) {
+	w, err := scanTradingViewWebhook(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.tradingview_webhooks (user_id, name, mode, secret_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+tradingViewWebhookColumns,
+		userID, name, mode, secretHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tradingview webhook: %w", err)
+	}
+	return w, nil
+}
+
+// ListTradingViewWebhooks returns a user's webhooks, oldest first
+func (db *DB) ListTradingViewWebhooks(ctx context.Context, userID string) ([]TradingViewWebhook, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT `+tradingViewWebhookColumns+`
+		FROM core_api.tradingview_webhooks
+		WHERE user_id = $1
+		ORDER BY id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tradingview webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []TradingViewWebhook{}
+	for rows.Next() {
+		w, err := scanTradingViewWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tradingview webhook: %w", err)
+		}
+		webhooks = append(webhooks, *w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetTradingViewWebhook returns one of a user's webhooks, or nil
+func (db *DB) GetTradingViewWebhook(ctx context.Context, userID string, id int64) (*TradingViewWebhook, error) {
+	return db.getTradingViewWebhook(ctx,
+		"SELECT "+tradingViewWebhookColumns+" FROM core_api.tradingview_webhooks WHERE id = $1 AND user_id = $2",
+		id, userID)
+}
+
+// GetTradingViewWebhookBySecret returns the webhook whose secret hashes to
+// secretHash, or nil
+func (db *DB) GetTradingViewWebhookBySecret(ctx context.Context, secretHash string) (*TradingViewWebhook, error) {
+	return db.getTradingViewWebhook(ctx,
+		"SELECT "+tradingViewWebhookColumns+" FROM core_api.tradingview_webhooks WHERE secret_hash = $1",
+		secretHash)
+}
+
+// UpdateTradingViewWebhook renames and enables or disables one of a user's
+// webhooks, returning nil if it doesn't exist
+func (db *DB) UpdateTradingViewWebhook(ctx context.Context, userID string, id int64, name string, enabled bool) (*TradingViewWebhook, error) {
+	return db.getTradingViewWebhook(ctx, `
+		UPDATE core_api.tradingview_webhooks
+		SET name = $3, enabled = $4
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+tradingViewWebhookColumns,
+		id, userID, name, enabled)
+}
+
+// DeleteTradingViewWebhook deletes one of a user's webhooks with its
+// delivery log
+func (db *DB) DeleteTradingViewWebhook(ctx context.Context, userID string, id int64) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.tradingview_webhooks WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tradingview webhook: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordTradingViewDelivery logs a delivery, keeping the latest
+// maxTradingViewDeliveries per webhook
+func (db *DB) RecordTradingViewDelivery(ctx context.Context, d TradingViewDelivery) error {
+	if _, err := db.conn.ExecContext(ctx, `
+		WITH logged AS (
+			INSERT INTO core_api.tradingview_deliveries (webhook_id, status, symbol, action, signal_id, error, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		)
+		UPDATE core_api.tradingview_webhooks SET last_delivery_at = NOW() WHERE id = $1
+	`, d.WebhookID, d.Status, d.Symbol, d.Action, d.SignalID, d.Error, d.Payload); err != nil {
+		return fmt.Errorf("failed to record tradingview delivery: %w", err)
+	}
+	if _, err := db.conn.ExecContext(ctx, `
+		DELETE FROM core_api.tradingview_deliveries
+		WHERE webhook_id = $1 AND id < (
+			SELECT id FROM core_api.tradingview_deliveries
+			WHERE webhook_id = $1
+			ORDER BY id DESC
+			OFFSET $2 LIMIT 1
+		)
+	`, d.WebhookID, maxTradingViewDeliveries-1); err != nil {
+		return fmt.Errorf("failed to trim tradingview deliveries: %w", err)
+	}
+	return nil
+}
+
+// ListTradingViewDeliveries returns a webhook's latest deliveries, newest
+// first
+func (db *DB) ListTradingViewDeliveries(ctx context.Context, webhookID int64, limit int) ([]TradingViewDelivery, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, webhook_id, status, symbol, action, signal_id, error, payload, received_at
+		FROM core_api.tradingview_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tradingview deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []TradingViewDelivery{}
+	for rows.Next() {
+		var d TradingViewDelivery
+		var receivedAt time.Time
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Status, &d.Symbol, &d.Action, &d.SignalID,
+			&d.Error, &d.Payload, &receivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tradingview delivery: %w", err)
+		}
+		d.ReceivedAt = receivedAt.Format(time.RFC3339)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ResolveSymbol returns the first candidate in the stock config, preferring
+// exchange when it's set, or "" if none is known
+func (db *DB) ResolveSymbol(ctx context.Context, exchange string, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", nil
+	}
+	var symbol string
+	err := db.conn.QueryRowContext(ctx, `
+		SELECT sc.symbol
+		FROM md.stock_config sc
+		JOIN unnest($1::text[]) WITH ORDINALITY AS c(symbol, n) ON c.symbol = sc.symbol
+		ORDER BY c.n, (sc.exchange = $2) DESC
+		LIMIT 1
+	`, pq.Array(candidates), exchange).Scan(&symbol)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symbol: %w", err)
+	}
+	return symbol, nil
+}
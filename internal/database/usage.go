@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UsageCounts is what an account used over some period
+type UsageCounts struct {
+	APICalls    int64 `json:"api_calls"`
+	WSMessages  int64 `json:"ws_messages"`
+	ExportRows  int64 `json:"export_rows"`
+	ExportBytes int64 `json:"export_bytes"`
+}
+
+// Add returns the sum of u and o
+func (u UsageCounts) Add(o UsageCounts) UsageCounts {
+	return UsageCounts{
+		APICalls:    u.APICalls + o.APICalls,
+		WSMessages:  u.WSMessages + o.WSMessages,
+		ExportRows:  u.ExportRows + o.ExportRows,
+		ExportBytes: u.ExportBytes + o.ExportBytes,
+	}
+}
+
+// AccountUsage is an account's usage on one day, or summed over a range
+type AccountUsage struct {
+	AccountID string `json:"account_id"`
+	// Day is set on daily rows
+	Day string `json:"day,omitempty"`
+	UsageCounts
+}
+
+// AccountQuota overrides the default daily soft quotas for an account. A
+// nil limit keeps the default; 0 means no quota.
+type AccountQuota struct {
+	AccountID   string `json:"account_id"`
+	APICalls    *int64 `json:"api_calls"`
+	WSMessages  *int64 `json:"ws_messages"`
+	ExportBytes *int64 `json:"export_bytes"`
+	UpdatedBy   string `json:"updated_by"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// AddAccountUsage adds each account's deltas to its usage on day and
+// returns the resulting totals, which include what other instances added
+func (db *DB) AddAccountUsage(ctx context.Context, day time.Time, deltas map[string]UsageCounts) (map[string]UsageCounts, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A fixed order keeps concurrent flushes from deadlocking
+	accounts := make([]string, 0, len(deltas))
+	for accountID := range deltas {
+		accounts = append(accounts, accountID)
+	}
+	sort.Strings(accounts)
+
+	totals := make(map[string]UsageCounts, len(deltas))
+	for _, accountID := range accounts {
+		d := deltas[accountID]
+		var t UsageCounts
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO core_api.account_usage (account_id, day, api_calls, ws_messages, export_rows, export_bytes)
+			VALUES ($1, $2::date, $3, $4, $5, $6)
+			ON CONFLICT (account_id, day) DO UPDATE SET
+				api_calls = account_usage.api_calls + EXCLUDED.api_calls,
+				ws_messages = account_usage.ws_messages + EXCLUDED.ws_messages,
+				export_rows = account_usage.export_rows + EXCLUDED.export_rows,
+				export_bytes = account_usage.export_bytes + EXCLUDED.export_bytes,
+				updated_at = NOW()
+			RETURNING api_calls, ws_messages, export_rows, export_bytes
+		`, accountID, day.Format("2006-01-02"), d.APICalls, d.WSMessages, d.ExportRows, d.ExportBytes,
+		).Scan(&t.APICalls, &t.WSMessages, &t.ExportRows, &t.ExportBytes); err != nil {
+			return nil, fmt.Errorf("failed to record usage of %s: %w", accountID, err)
+		}
+		totals[accountID] = t
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit usage: %w", err)
+	}
+	return totals, nil
+}
+
+// GetAccountUsageDay returns every account's usage on day, by account
+func (db *DB) GetAccountUsageDay(ctx context.Context, day time.Time) (map[string]UsageCounts, error) {
+	rows, err := db.ListAccountUsage(ctx, "", day, day)
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]UsageCounts, len(rows))
+	for _, u := range rows {
+		usage[u.AccountID] = u.UsageCounts
+	}
+	return usage, nil
+}
+
+// ListAccountUsage returns daily usage from from to to inclusive, for one
+// account or, with accountID empty, all of them. Rows are ordered by day,
+// then account.
+func (db *DB) ListAccountUsage(ctx context.Context, accountID string, from, to time.Time) ([]AccountUsage, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT account_id, day, api_calls, ws_messages, export_rows, export_bytes
+		FROM core_api.account_usage
+		WHERE ($1 = '' OR account_id = $1) AND day BETWEEN $2::date AND $3::date
+		ORDER BY day, account_id
+	`, accountID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []AccountUsage{}
+	for rows.Next() {
+		var u AccountUsage
+		var day time.Time
+		if err := rows.Scan(&u.AccountID, &day, &u.APICalls, &u.WSMessages, &u.ExportRows, &u.ExportBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan account usage: %w", err)
+		}
+		u.Day = day.Format("2006-01-02")
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return usage, nil
+}
+
+const accountQuotaColumns = `account_id, api_calls, ws_messages, export_bytes, updated_by, updated_at`
+
+func scanAccountQuota(row rowScanner) (*AccountQuota, error) {
+	var q AccountQuota
+	var updatedAt time.Time
+	if err := row.Scan(&q.AccountID, &q.APICalls, &q.WSMessages, &q.ExportBytes, &q.UpdatedBy, &updatedAt); err != nil {
+		return nil, err
+	}
+	q.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &q, nil
+}
+
+// ListAccountQuotas returns every account's quota overrides
+func (db *DB) ListAccountQuotas(ctx context.Context) ([]AccountQuota, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		"SELECT "+accountQuotaColumns+" FROM core_api.account_quotas ORDER BY account_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account quotas: %w", err)
+	}
+	defer rows.Close()
+
+	quotas := []AccountQuota{}
+	for rows.Next() {
+		q, err := scanAccountQuota(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account quota: %w", err)
+		}
+		quotas = append(quotas, *q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return quotas, nil
+}
+
+// PutAccountQuota replaces an account's quota overrides, returning nil if
+// the account doesn't exist
+func (db *DB) PutAccountQuota(ctx context.Context, q AccountQuota) (*AccountQuota, error) {
+	saved, err := scanAccountQuota(db.conn.QueryRowContext(ctx, `
+		INSERT INTO core_api.account_quotas (account_id, api_calls, ws_messages, export_bytes, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id) DO UPDATE SET
+			api_calls = EXCLUDED.api_calls,
+			ws_messages = EXCLUDED.ws_messages,
+			export_bytes = EXCLUDED.export_bytes,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING `+accountQuotaColumns,
+		q.AccountID, q.APICalls, q.WSMessages, q.ExportBytes, q.UpdatedBy))
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save account quota: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteAccountQuota removes an account's overrides, returning false if it
+// had none
+func (db *DB) DeleteAccountQuota(ctx context.Context, accountID string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx, "DELETE FROM core_api.account_quotas WHERE account_id = $1", accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete account quota: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
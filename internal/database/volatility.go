@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// volatilityLookback is the number of sessions IV rank and percentile span
+const volatilityLookback = 252
+
+// realizedWindows are the realized volatility windows reported, in sessions
+var realizedWindows = []int{10, 20, 30}
+
+// Volatility is a symbol's realized and implied volatility with its rank
+// over the past year. All volatilities are annualised percentages.
+type Volatility struct {
+	Symbol       string             `json:"symbol"`
+	AsOf         string             `json:"as_of"`
+	RealizedVol  map[string]float64 `json:"realized_vol"`
+	IV           *float64           `json:"iv"`
+	IVAsOf       *string            `json:"iv_as_of"`
+	IVRank       *float64           `json:"iv_rank"`
+	IVPercentile *float64           `json:"iv_percentile"`
+	// RankSource is "implied" when rank and percentile come from option IV
+	// and "realized" when no IV history exists and 20-day realized
+	// volatility stands in for it
+	RankSource   string `json:"rank_source"`
+	Observations int    `json:"observations"`
+}
+
+// GetVolatility returns realized volatility from daily closes and, when the
+// options pipeline has published it to md.option_iv, at-the-money IV. Returns
+// nil if the symbol has too few closes to compute any realized volatility.
+func (db *DB) GetVolatility(ctx context.Context, symbol string) (*Volatility, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT trade_date, close FROM (
+			SELECT trade_date, close
+			FROM md.bhavcopy
+			WHERE symbol = $1 AND series = 'EQ' AND close > 0
+			ORDER BY trade_date DESC
+			LIMIT $2
+		) c
+		ORDER BY trade_date
+	`, symbol, volatilityLookback+realizedWindows[len(realizedWindows)-1]+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closes: %w", err)
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	var closes []float64
+	for rows.Next() {
+		var date time.Time
+		var close float64
+		if err := rows.Scan(&date, &close); err != nil {
+			return nil, fmt.Errorf("failed to scan close: %w", err)
+		}
+		dates = append(dates, date)
+		closes = append(closes, close)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(closes) <= realizedWindows[0] {
+		return nil, nil
+	}
+
+	returns := make([]float64, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		returns[i-1] = math.Log(closes[i] / closes[i-1])
+	}
+
+	v := &Volatility{
+		Symbol:       symbol,
+		AsOf:         dates[len(dates)-1].Format("2006-01-02"),
+		RealizedVol:  map[string]float64{},
+		Observations: len(closes),
+	}
+	for _, w := range realizedWindows {
+		if len(returns) >= w {
+			v.RealizedVol[fmt.Sprintf("%dd", w)] = round2(annualisedVol(returns[len(returns)-w:]))
+		}
+	}
+
+	ivs, err := db.impliedVolHistory(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(ivs) > 0 {
+		latest := ivs[len(ivs)-1]
+		iv := round2(latest.iv)
+		asOf := latest.date.Format("2006-01-02")
+		v.IV, v.IVAsOf = &iv, &asOf
+		series := make([]float64, len(ivs))
+		for i, p := range ivs {
+			series[i] = p.iv
+		}
+		v.IVRank, v.IVPercentile = volRank(series)
+		v.RankSource = "implied"
+		return v, nil
+	}
+
+	// Without option IV, rank today's 20-day realized vol against its own year
+	window := realizedWindows[1]
+	var series []float64
+	for end := window; end <= len(returns); end++ {
+		series = append(series, annualisedVol(returns[end-window:end]))
+	}
+	if len(series) > volatilityLookback {
+		series = series[len(series)-volatilityLookback:]
+	}
+	v.IVRank, v.IVPercentile = volRank(series)
+	v.RankSource = "realized"
+	return v, nil
+}
+
+type ivPoint struct {
+	date time.Time
+	iv   float64
+}
+
+// impliedVolHistory returns the past year of daily ATM IV, oldest first, or
+// nothing if md.option_iv isn't present in this deployment
+func (db *DB) impliedVolHistory(ctx context.Context, symbol string) ([]ivPoint, error) {
+	var exists bool
+	if err := db.conn.QueryRowContext(ctx,
+		"SELECT to_regclass('md.option_iv') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check for option IV table: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT trade_date, atm_iv
+		FROM md.option_iv
+		WHERE symbol = $1
+			AND atm_iv > 0
+			AND trade_date > CURRENT_DATE - INTERVAL '1 year'
+		ORDER BY trade_date
+	`, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query option IV: %w", err)
+	}
+	defer rows.Close()
+
+	var points []ivPoint
+	for rows.Next() {
+		var p ivPoint
+		if err := rows.Scan(&p.date, &p.iv); err != nil {
+			return nil, fmt.Errorf("failed to scan option IV: %w", err)
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return points, nil
+}
+
+// annualisedVol is the sample standard deviation of daily log returns,
+// annualised over 252 sessions, in percent
+func annualisedVol(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+	var ss float64
+	for _, r := range returns {
+		ss += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(ss/float64(len(returns)-1)) * math.Sqrt(252) * 100
+}
+
+// volRank returns where the last value of series sits within its range
+// (rank) and the share of earlier values below it (percentile), both 0-100
+func volRank(series []float64) (*float64, *float64) {
+	if len(series) < 2 {
+		return nil, nil
+	}
+	current := series[len(series)-1]
+	history := append([]float64(nil), series...)
+	sort.Float64s(history)
+	lo, hi := history[0], history[len(history)-1]
+
+	var rank float64
+	if hi > lo {
+		rank = round2((current - lo) / (hi - lo) * 100)
+	}
+	below := sort.SearchFloat64s(history, current)
+	percentile := round2(float64(below) / float64(len(series)-1) * 100)
+	return &rank, &percentile
+}
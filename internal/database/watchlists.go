@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WatchlistItem is a symbol on one of an account's named watchlists
+type WatchlistItem struct {
+	Symbol  string `json:"symbol"`
+	AddedBy string `json:"added_by"`
+	AddedAt string `json:"added_at"`
+}
+
+// ListWatchlist returns the symbols on an account's watchlist in the order
+// they were added
+func (db *DB) ListWatchlist(ctx context.Context, accountID, watchlist string) ([]WatchlistItem, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT symbol, added_by, added_at
+		FROM core_api.watchlist_items
+		WHERE account_id = $1 AND watchlist = $2
+		ORDER BY added_at, symbol
+	`, accountID, watchlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []WatchlistItem{}
+	for rows.Next() {
+		var item WatchlistItem
+		var addedAt time.Time
+		if err := rows.Scan(&item.Symbol, &item.AddedBy, &addedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist item: %w", err)
+		}
+		item.AddedAt = addedAt.Format(time.RFC3339)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return items, nil
+}
+
+// AddToWatchlist adds symbols to an account's watchlist, returning those
+// that weren't already on it
+func (db *DB) AddToWatchlist(ctx context.Context, accountID, watchlist, userID string, symbols []string) ([]string, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		INSERT INTO core_api.watchlist_items (account_id, watchlist, symbol, added_by)
+		SELECT $1, $2, s, $3 FROM UNNEST($4::text[]) AS s
+		ON CONFLICT DO NOTHING
+		RETURNING symbol
+	`, accountID, watchlist, userID, pq.Array(symbols))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	added := []string{}
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist symbol: %w", err)
+		}
+		added = append(added, symbol)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return added, nil
+}
+
+// RemoveFromWatchlist removes a symbol from an account's watchlist,
+// returning false if it wasn't on it
+func (db *DB) RemoveFromWatchlist(ctx context.Context, accountID, watchlist, symbol string) (bool, error) {
+	res, err := db.conn.ExecContext(ctx,
+		"DELETE FROM core_api.watchlist_items WHERE account_id = $1 AND watchlist = $2 AND symbol = $3",
+		accountID, watchlist, symbol)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove from watchlist: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,293 @@
+// Package dataquality holds back market data that fails sanity checks
+// before it reaches WebSocket clients, the price overlay and signal
+// monitors. Ticks from market.tick and prices posted by collectors are
+// checked per symbol against the last accepted value:
+//
+//   - a zero or negative price
+//   - a move of more than DQ_MAX_JUMP_PCT (default 20) from the last
+//     accepted price; DQ_JUMP_CONFIRM_TICKS (default 3) consecutive ticks
+//     at the new level accept it, so a genuine gap isn't held back forever
+//   - cumulative volume going down within the same IST day
+//     (DQ_VOLUME_REGRESSION, default true)
+//
+// Failing data is quarantined in memory for inspection instead of being
+// propagated.
+package dataquality
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// Rules
+const (
+	RuleNonPositivePrice = "non_positive_price"
+	RulePriceJump        = "price_jump"
+	RuleVolumeRegression = "volume_regression"
+)
+
+// Sources of checked data
+const (
+	SourceTick  = "market.tick"
+	SourcePrice = "prices.batch"
+)
+
+// quarantineSize caps the quarantined items kept for inspection
+const quarantineSize = 1000
+
+// Config holds the rule thresholds. A zero MaxJumpPct disables the jump
+// rule.
+type Config struct {
+	MaxJumpPct       float64 `json:"max_jump_pct"`
+	JumpConfirmTicks int     `json:"jump_confirm_ticks"`
+	VolumeRegression bool    `json:"volume_regression"`
+}
+
+// ConfigFromEnv reads DQ_MAX_JUMP_PCT, DQ_JUMP_CONFIRM_TICKS and
+// DQ_VOLUME_REGRESSION
+func ConfigFromEnv() Config {
+	cfg := Config{MaxJumpPct: 20, JumpConfirmTicks: 3, VolumeRegression: true}
+	if v, err := strconv.ParseFloat(os.Getenv("DQ_MAX_JUMP_PCT"), 64); err == nil && v >= 0 {
+		cfg.MaxJumpPct = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("DQ_JUMP_CONFIRM_TICKS")); err == nil && v >= 1 {
+		cfg.JumpConfirmTicks = v
+	}
+	if v := os.Getenv("DQ_VOLUME_REGRESSION"); v != "" {
+		cfg.VolumeRegression = v == "true"
+	}
+	return cfg
+}
+
+// Observation is one price to check. Volume is cumulative for the day, or
+// negative when unknown.
+type Observation struct {
+	Source    string
+	Symbol    string
+	Price     float64
+	Volume    int64
+	Timestamp time.Time
+	Payload   json.RawMessage
+}
+
+// Quarantined is an observation held back by a rule
+type Quarantined struct {
+	ID         int64           `json:"id"`
+	Source     string          `json:"source"`
+	Symbol     string          `json:"symbol"`
+	Rule       string          `json:"rule"`
+	Detail     string          `json:"detail"`
+	Price      float64         `json:"price"`
+	Volume     *int64          `json:"volume"`
+	Timestamp  time.Time       `json:"timestamp"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Released   bool            `json:"released"`
+}
+
+// symbolState is the last accepted price and volume for a symbol, and a
+// new price level waiting for confirmation
+type symbolState struct {
+	price     float64
+	volume    int64
+	day       string
+	candidate float64
+	confirms  int
+}
+
+// Stats counts checked, accepted and quarantined data since startup
+type Stats struct {
+	Config      Config            `json:"config"`
+	Since       time.Time         `json:"since"`
+	Checked     uint64            `json:"checked"`
+	Quarantined uint64            `json:"quarantined"`
+	ByRule      map[string]uint64 `json:"by_rule"`
+	Rebased     uint64            `json:"rebased"`
+	Held        int               `json:"held"`
+}
+
+// Engine applies the rules and keeps the quarantine
+type Engine struct {
+	cfg Config
+	loc *time.Location
+
+	mu         sync.Mutex
+	symbols    map[string]*symbolState
+	quarantine []Quarantined
+	nextID     int64
+	checked    uint64
+	rebased    uint64
+	byRule     map[string]uint64
+	started    time.Time
+}
+
+// NewEngine creates a rules engine
+func NewEngine(cfg Config) *Engine {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		loc = time.FixedZone("IST", 5*3600+1800)
+	}
+	return &Engine{cfg: cfg, loc: loc, symbols: map[string]*symbolState{}, byRule: map[string]uint64{}, started: time.Now()}
+}
+
+// AllowTick checks a market.tick, quarantining it if it fails a rule
+func (e *Engine) AllowTick(t schemas.Tick, data []byte) bool {
+	ts, err := time.Parse(time.RFC3339Nano, t.Timestamp)
+	if err != nil {
+		ts = time.Now()
+	}
+	volume := int64(t.Volume)
+	if volume == 0 {
+		volume = -1
+	}
+	return e.Check(Observation{Source: SourceTick, Symbol: t.Symbol, Price: t.Price, Volume: volume, Timestamp: ts, Payload: data})
+}
+
+// AllowPrice checks a collector's price update, quarantining it if it
+// fails a rule
+func (e *Engine) AllowPrice(u database.PriceUpdate, payload []byte) bool {
+	volume := int64(-1)
+	if u.Volume != nil {
+		volume = *u.Volume
+	}
+	return e.Check(Observation{Source: SourcePrice, Symbol: u.Symbol, Price: u.LastPrice, Volume: volume, Timestamp: u.UpdatedAt, Payload: payload})
+}
+
+// Check applies the rules to o, returning whether it may be propagated
+func (e *Engine) Check(o Observation) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.checked++
+
+	if o.Price <= 0 || math.IsNaN(o.Price) || math.IsInf(o.Price, 0) {
+		e.hold(o, RuleNonPositivePrice, fmt.Sprintf("price %g", o.Price))
+		return false
+	}
+
+	day := o.Timestamp.In(e.loc).Format("2006-01-02")
+	st, seen := e.symbols[o.Symbol]
+	if !seen {
+		e.symbols[o.Symbol] = &symbolState{price: o.Price, volume: o.Volume, day: day}
+		return true
+	}
+
+	if e.cfg.MaxJumpPct > 0 {
+		if jump := pctMove(st.price, o.Price); jump > e.cfg.MaxJumpPct {
+			if st.candidate > 0 && pctMove(st.candidate, o.Price) <= e.cfg.MaxJumpPct {
+				st.confirms++
+			} else {
+				st.candidate, st.confirms = o.Price, 1
+			}
+			if st.confirms < e.cfg.JumpConfirmTicks {
+				e.hold(o, RulePriceJump, fmt.Sprintf("%.2f%% from %g", jump, st.price))
+				return false
+			}
+			log.Printf("⚠️  %s accepted at %g after %d ticks away from %g", o.Symbol, o.Price, st.confirms, st.price)
+			e.rebased++
+		}
+	}
+
+	if e.cfg.VolumeRegression && o.Volume >= 0 && st.volume >= 0 && day == st.day && o.Volume < st.volume {
+		e.hold(o, RuleVolumeRegression, fmt.Sprintf("volume %d after %d", o.Volume, st.volume))
+		return false
+	}
+
+	st.price, st.day, st.candidate, st.confirms = o.Price, day, 0, 0
+	if o.Volume >= 0 {
+		st.volume = o.Volume
+	}
+	return true
+}
+
+// pctMove is the absolute move from from to to, in percent
+func pctMove(from, to float64) float64 {
+	return math.Abs(to-from) / from * 100
+}
+
+// hold quarantines o, evicting the oldest item when full
+func (e *Engine) hold(o Observation, rule, detail string) {
+	e.nextID++
+	e.byRule[rule]++
+	q := Quarantined{
+		ID: e.nextID, Source: o.Source, Symbol: o.Symbol, Rule: rule, Detail: detail,
+		Price: o.Price, Timestamp: o.Timestamp, ReceivedAt: time.Now(), Payload: o.Payload,
+	}
+	if o.Volume >= 0 {
+		v := o.Volume
+		q.Volume = &v
+	}
+	if len(e.quarantine) >= quarantineSize {
+		e.quarantine = e.quarantine[1:]
+	}
+	e.quarantine = append(e.quarantine, q)
+}
+
+// List returns quarantined items, newest first, optionally for one symbol
+// or rule
+func (e *Engine) List(symbol, rule string, limit int) []Quarantined {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	items := []Quarantined{}
+	for i := len(e.quarantine) - 1; i >= 0 && len(items) < limit; i-- {
+		q := e.quarantine[i]
+		if (symbol == "" || strings.EqualFold(q.Symbol, symbol)) && (rule == "" || q.Rule == rule) {
+			items = append(items, q)
+		}
+	}
+	return items
+}
+
+// Release marks a quarantined item as a false positive and accepts its
+// price and volume as the symbol's new baseline, so the ticks that follow
+// it pass. The item itself is not re-sent; later ticks supersede it.
+func (e *Engine) Release(id int64) (*Quarantined, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range e.quarantine {
+		q := &e.quarantine[i]
+		if q.ID != id {
+			continue
+		}
+		if !q.Released && q.Price > 0 {
+			st := e.symbols[q.Symbol]
+			if st == nil {
+				st = &symbolState{}
+				e.symbols[q.Symbol] = st
+			}
+			st.price, st.day, st.candidate, st.confirms = q.Price, q.Timestamp.In(e.loc).Format("2006-01-02"), 0, 0
+			st.volume = -1
+			if q.Volume != nil {
+				st.volume = *q.Volume
+			}
+		}
+		q.Released = true
+		released := *q
+		return &released, true
+	}
+	return nil, false
+}
+
+// Stats returns counts since startup
+func (e *Engine) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := Stats{Config: e.cfg, Since: e.started, Checked: e.checked, ByRule: map[string]uint64{}, Rebased: e.rebased, Held: len(e.quarantine)}
+	for rule, n := range e.byRule {
+		s.ByRule[rule] = n
+		s.Quarantined += n
+	}
+	return s
+}
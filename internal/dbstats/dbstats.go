@@ -0,0 +1,221 @@
+// Package dbstats counts the database work each API request does: queries,
+// rows and values read, and time spent in the database. In dev every
+// request carries a Stats in its context; the database driver records into
+// it, and a query shape repeated RepeatThreshold times or more within one
+// request is flagged as a likely N+1 (a follow-up query per row of an
+// earlier result).
+//
+// A Tracker keeps per-route totals in memory for this instance.
+package dbstats
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RepeatThreshold is how many runs of one query shape in a request flag it
+const RepeatThreshold = 5
+
+// maxShapeLength truncates query shapes in reports
+const maxShapeLength = 200
+
+type contextKey struct{}
+
+// Stats is one request's database work. It is safe for concurrent use, as
+// handlers may query from several goroutines.
+type Stats struct {
+	mu       sync.Mutex
+	queries  int
+	rows     int64
+	values   int64
+	duration time.Duration
+	shapes   map[string]int
+}
+
+// WithStats returns ctx carrying a new Stats
+func WithStats(ctx context.Context) (context.Context, *Stats) {
+	s := &Stats{shapes: map[string]int{}}
+	return context.WithValue(ctx, contextKey{}, s), s
+}
+
+// FromContext returns the Stats ctx carries, or nil
+func FromContext(ctx context.Context) *Stats {
+	s, _ := ctx.Value(contextKey{}).(*Stats)
+	return s
+}
+
+var (
+	literalPattern    = regexp.MustCompile(`'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Shape normalizes a query so runs differing only in literals compare equal
+func Shape(query string) string {
+	shape := literalPattern.ReplaceAllString(query, "?")
+	shape = strings.TrimSpace(whitespacePattern.ReplaceAllString(shape, " "))
+	if len(shape) > maxShapeLength {
+		shape = shape[:maxShapeLength] + "…"
+	}
+	return shape
+}
+
+// Query records one query or statement and the time the driver took to run
+// it, not counting reading its rows
+func (s *Stats) Query(query string, d time.Duration) {
+	shape := Shape(query)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries++
+	s.duration += d
+	s.shapes[shape]++
+}
+
+// Rows records rows read from a result, each of columns values, and the
+// time spent reading them
+func (s *Stats) Rows(rows int64, columns int, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows += rows
+	s.values += rows * int64(columns)
+	s.duration += d
+}
+
+// Repeat is a query shape run RepeatThreshold or more times in a request
+type Repeat struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+// Summary is a snapshot of a request's database work
+type Summary struct {
+	Queries  int      `json:"queries"`
+	Rows     int64    `json:"rows"`
+	Values   int64    `json:"values"`
+	TimeMs   float64  `json:"time_ms"`
+	Repeated []Repeat `json:"repeated"`
+}
+
+// Summary snapshots the work so far, most repeated shapes first
+func (s *Stats) Summary() Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := Summary{
+		Queries:  s.queries,
+		Rows:     s.rows,
+		Values:   s.values,
+		TimeMs:   float64(s.duration.Microseconds()) / 1000,
+		Repeated: []Repeat{},
+	}
+	for shape, n := range s.shapes {
+		if n >= RepeatThreshold {
+			sum.Repeated = append(sum.Repeated, Repeat{Query: shape, Count: n})
+		}
+	}
+	sort.Slice(sum.Repeated, func(i, j int) bool {
+		if sum.Repeated[i].Count != sum.Repeated[j].Count {
+			return sum.Repeated[i].Count > sum.Repeated[j].Count
+		}
+		return sum.Repeated[i].Query < sum.Repeated[j].Query
+	})
+	return sum
+}
+
+// Header formats the summary for the X-DB-Stats response header
+func (sum Summary) Header() string {
+	h := fmt.Sprintf("queries=%d; rows=%d; values=%d; time_ms=%.1f", sum.Queries, sum.Rows, sum.Values, sum.TimeMs)
+	if len(sum.Repeated) > 0 {
+		h += fmt.Sprintf("; repeated=%d", sum.Repeated[0].Count)
+	}
+	return h
+}
+
+// RouteStats are the totals for one route
+type RouteStats struct {
+	Route    string  `json:"route"`
+	Requests int64   `json:"requests"`
+	Queries  int64   `json:"queries"`
+	Rows     int64   `json:"rows"`
+	Values   int64   `json:"values"`
+	TimeMs   float64 `json:"time_ms"`
+	// MaxQueries is the most queries a single request made
+	MaxQueries int `json:"max_queries"`
+	// NPlusOne counts requests that repeated a query shape; Example is the
+	// most repeated shape seen
+	NPlusOne    int64   `json:"n_plus_one"`
+	Example     *Repeat `json:"example"`
+	AvgQueries  float64 `json:"avg_queries"`
+	AvgTimeMs   float64 `json:"avg_time_ms"`
+	LastRequest string  `json:"last_request"`
+}
+
+// Tracker accumulates summaries per route
+type Tracker struct {
+	mu     sync.Mutex
+	routes map[string]*RouteStats
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{routes: map[string]*RouteStats{}}
+}
+
+// Add counts one request to route
+func (t *Tracker) Add(route string, sum Summary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r, ok := t.routes[route]
+	if !ok {
+		r = &RouteStats{Route: route}
+		t.routes[route] = r
+	}
+	r.Requests++
+	r.Queries += int64(sum.Queries)
+	r.Rows += sum.Rows
+	r.Values += sum.Values
+	r.TimeMs += sum.TimeMs
+	r.MaxQueries = max(r.MaxQueries, sum.Queries)
+	if len(sum.Repeated) > 0 {
+		r.NPlusOne++
+		if r.Example == nil || sum.Repeated[0].Count > r.Example.Count {
+			worst := sum.Repeated[0]
+			r.Example = &worst
+		}
+	}
+	r.LastRequest = time.Now().Format(time.RFC3339)
+}
+
+// Routes returns per-route totals, routes flagged for N+1 first, then by
+// average queries per request
+func (t *Tracker) Routes() []RouteStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	routes := make([]RouteStats, 0, len(t.routes))
+	for _, r := range t.routes {
+		rs := *r
+		rs.AvgQueries = float64(rs.Queries) / float64(rs.Requests)
+		rs.AvgTimeMs = rs.TimeMs / float64(rs.Requests)
+		routes = append(routes, rs)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if (routes[i].NPlusOne > 0) != (routes[j].NPlusOne > 0) {
+			return routes[i].NPlusOne > 0
+		}
+		if routes[i].AvgQueries != routes[j].AvgQueries {
+			return routes[i].AvgQueries > routes[j].AvgQueries
+		}
+		return routes[i].Route < routes[j].Route
+	})
+	return routes
+}
+
+// Reset clears all totals
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = map[string]*RouteStats{}
+}
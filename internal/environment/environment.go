@@ -0,0 +1,81 @@
+// Package environment identifies the deployment core-api is running in and
+// the guardrails that follow from it.
+//
+// ENVIRONMENT is dev, staging or prod, and prod when unset. In prod, endpoints
+// that run jobs or control other services need the prod-admin role; test
+// helpers such as the load generator and demo seeding only run in dev.
+package environment
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// Environments
+const (
+	Dev     = "dev"
+	Staging = "staging"
+	Prod    = "prod"
+)
+
+// ProdAdminRole may use dangerous endpoints in prod
+const ProdAdminRole = "prod-admin"
+
+// Environment is the deployment core-api is running in
+type Environment struct {
+	Name string
+}
+
+// Info is the environment as reported by /health, / and WebSocket hellos
+type Info struct {
+	Name       string `json:"name"`
+	Production bool   `json:"production"`
+	// Banner is shown by clients outside prod so environments aren't confused
+	Banner *string `json:"banner"`
+}
+
+// FromEnv reads ENVIRONMENT. An unset or unrecognised value is treated as
+// prod so a missing variable or a typo never loosens the guardrails.
+func FromEnv() Environment {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("ENVIRONMENT")))
+	switch name {
+	case "":
+		log.Printf("⚠️  ENVIRONMENT not set, using %s", Prod)
+		return Environment{Name: Prod}
+	case Dev, Staging, Prod:
+		return Environment{Name: name}
+	case "development":
+		return Environment{Name: Dev}
+	case "production":
+		return Environment{Name: Prod}
+	default:
+		log.Printf("⚠️  Unknown ENVIRONMENT=%q, using %s", name, Prod)
+		return Environment{Name: Prod}
+	}
+}
+
+// IsProd reports whether this is production
+func (e Environment) IsProd() bool {
+	return e.Name == Prod
+}
+
+// TestHelpersEnabled reports whether test helpers may run, which is only in dev
+func (e Environment) TestHelpersEnabled() bool {
+	return e.Name == Dev
+}
+
+// Allowed reports whether a caller with role may use dangerous endpoints
+func (e Environment) Allowed(role string) bool {
+	return !e.IsProd() || role == ProdAdminRole
+}
+
+// Info describes the environment for clients
+func (e Environment) Info() Info {
+	info := Info{Name: e.Name, Production: e.IsProd()}
+	if !info.Production {
+		banner := strings.ToUpper(e.Name) + " environment"
+		info.Banner = &banner
+	}
+	return info
+}
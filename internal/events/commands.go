@@ -0,0 +1,49 @@
+package events
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Command is an on-demand action served by a downstream service over NATS
+// request-reply
+type Command struct {
+	Name    string        `json:"name"`
+	Service string        `json:"service"`
+	Subject string        `json:"subject"`
+	Timeout time.Duration `json:"-"`
+	// Param names the route parameter sent in the request payload, if any
+	Param string `json:"param,omitempty"`
+}
+
+// Commands are the actions exposed under /api/system, keyed by service and name
+var Commands = []Command{
+	{Name: "rescan-symbol", Service: "engine", Subject: "engine.cmd.rescan_symbol", Timeout: 10 * time.Second, Param: "symbol"},
+	{Name: "reload-config", Service: "engine", Subject: "engine.cmd.reload_config", Timeout: 5 * time.Second},
+	{Name: "status", Service: "engine", Subject: "engine.cmd.status", Timeout: 3 * time.Second},
+	{Name: "resubscribe", Service: "bridge", Subject: "bridge.cmd.resubscribe", Timeout: 15 * time.Second},
+	{Name: "status", Service: "bridge", Subject: "bridge.cmd.status", Timeout: 3 * time.Second},
+}
+
+// FindCommand looks up a command by service and name
+func FindCommand(service, name string) (Command, bool) {
+	for _, cmd := range Commands {
+		if cmd.Service == service && cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// IsNoResponders reports whether a request failed because no service is
+// subscribed to the subject
+func IsNoResponders(err error) bool {
+	return errors.Is(err, nats.ErrNoResponders)
+}
+
+// IsTimeout reports whether a request got no reply in time
+func IsTimeout(err error) bool {
+	return errors.Is(err, nats.ErrTimeout)
+}
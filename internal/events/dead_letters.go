@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// deadLetterQueueSize bounds undecodable events waiting to be stored. A
+// producer emitting garbage at tick rate must not stall NATS delivery, so
+// events beyond this are logged and dropped.
+const deadLetterQueueSize = 1000
+
+// DeadLetterSink stores events that failed to decode
+type DeadLetterSink interface {
+	RecordDeadLetter(ctx context.Context, subject string, payload []byte, decodeErr string) error
+}
+
+type deadLetter struct {
+	subject string
+	payload []byte
+	err     string
+}
+
+// SetDeadLetterSink stores undecodable events in sink instead of dropping
+// them. Call before Subscribe.
+func (s *Subscriber) SetDeadLetterSink(sink DeadLetterSink) {
+	s.deadLetterSink = sink
+	s.deadLetters = make(chan deadLetter, deadLetterQueueSize)
+}
+
+func (s *Subscriber) deadLetter(subject string, data []byte, err error) {
+	if s.deadLetters == nil {
+		return
+	}
+	payload := append([]byte(nil), data...)
+	select {
+	case s.deadLetters <- deadLetter{subject: subject, payload: payload, err: err.Error()}:
+	default:
+		log.Printf("⚠️  Dead-letter queue full, dropping %s event", subject)
+	}
+}
+
+func (s *Subscriber) writeDeadLetters() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case d := <-s.deadLetters:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.deadLetterSink.RecordDeadLetter(ctx, d.subject, d.payload, d.err); err != nil {
+				log.Printf("❌ Failed to store dead letter for %s: %v", d.subject, err)
+			}
+			cancel()
+		}
+	}
+}
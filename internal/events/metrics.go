@@ -0,0 +1,201 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// metricsWindow is the longest rolling window reported, in one-second buckets
+const metricsWindow = 15 * 60
+
+// silentAfter marks a subject as silent when nothing has arrived for this
+// long, which usually means its producer is down
+const silentAfter = time.Minute
+
+// bucket aggregates one second of events on a subject
+type bucket struct {
+	second    int64
+	messages  uint64
+	errors    uint64
+	fanout    uint64
+	latencyNs int64
+	maxNs     int64
+	lagMs     int64
+	lagged    uint64
+}
+
+// subjectMetrics is a ring of per-second buckets plus lifetime totals
+type subjectMetrics struct {
+	buckets  [metricsWindow]bucket
+	total    uint64
+	errors   uint64
+	lastSeen time.Time
+}
+
+// Metrics tracks per-subject throughput, fan-out, processing latency and
+// producer lag over rolling windows
+type Metrics struct {
+	mu       sync.Mutex
+	subjects map[string]*subjectMetrics
+	started  time.Time
+}
+
+// sample is what one handled event contributes to the metrics
+type sample struct {
+	latency time.Duration
+	// lag is receive time minus the producer's timestamp; negative if unknown
+	lag    time.Duration
+	fanout int
+	failed bool
+}
+
+// NewMetrics creates an empty metrics tracker
+func NewMetrics() *Metrics {
+	return &Metrics{subjects: make(map[string]*subjectMetrics), started: time.Now()}
+}
+
+func (m *Metrics) record(subject string, now time.Time, s sample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sm, ok := m.subjects[subject]
+	if !ok {
+		sm = &subjectMetrics{}
+		m.subjects[subject] = sm
+	}
+	sec := now.Unix()
+	b := &sm.buckets[sec%metricsWindow]
+	if b.second != sec {
+		*b = bucket{second: sec}
+	}
+
+	b.messages++
+	sm.total++
+	if now.After(sm.lastSeen) {
+		sm.lastSeen = now
+	}
+	if s.failed {
+		b.errors++
+		sm.errors++
+		return
+	}
+	b.fanout += uint64(s.fanout)
+	ns := s.latency.Nanoseconds()
+	b.latencyNs += ns
+	if ns > b.maxNs {
+		b.maxNs = ns
+	}
+	if s.lag >= 0 {
+		b.lagMs += s.lag.Milliseconds()
+		b.lagged++
+	}
+}
+
+// WindowStats summarises one rolling window
+type WindowStats struct {
+	Messages     uint64  `json:"messages"`
+	Errors       uint64  `json:"errors"`
+	RatePerSec   float64 `json:"rate_per_sec"`
+	Fanout       uint64  `json:"fanout"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+	AvgLagMs     float64 `json:"avg_lag_ms"`
+}
+
+// SubjectStats describes one subject
+type SubjectStats struct {
+	Subject        string     `json:"subject"`
+	Status         string     `json:"status"`
+	Total          uint64     `json:"total"`
+	TotalErrors    uint64     `json:"total_errors"`
+	LastReceivedAt *time.Time `json:"last_received_at"`
+	IdleSeconds    *float64   `json:"idle_seconds"`
+	// Windows are keyed 1m, 5m and 15m
+	Windows map[string]WindowStats `json:"windows"`
+}
+
+var windows = []struct {
+	name    string
+	seconds int64
+}{{"1m", 60}, {"5m", 300}, {"15m", 900}}
+
+// Snapshot returns stats for the given subjects, plus any others seen.
+// Subjects that never received a message are reported as "never".
+func (m *Metrics) Snapshot(expected []string) []SubjectStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	names := map[string]bool{}
+	for _, s := range expected {
+		names[s] = true
+	}
+	for s := range m.subjects {
+		names[s] = true
+	}
+
+	out := make([]SubjectStats, 0, len(names))
+	for name := range names {
+		st := SubjectStats{Subject: name, Status: "never", Windows: map[string]WindowStats{}}
+		sm, ok := m.subjects[name]
+		if ok {
+			st.Total, st.TotalErrors = sm.total, sm.errors
+			last := sm.lastSeen
+			idle := now.Sub(last).Seconds()
+			st.LastReceivedAt, st.IdleSeconds = &last, &idle
+			st.Status = "active"
+			if now.Sub(last) > silentAfter {
+				st.Status = "silent"
+			}
+		}
+		for _, w := range windows {
+			var ws WindowStats
+			if ok {
+				// Rates right after startup are averaged over uptime
+				span := w.seconds
+				if up := int64(now.Sub(m.started).Seconds()) + 1; up < span {
+					span = up
+				}
+				ws = sm.window(now.Unix(), span)
+			}
+			st.Windows[w.name] = ws
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Subject < out[j].Subject })
+	return out
+}
+
+// window sums the buckets of the last n seconds, including the current one
+func (sm *subjectMetrics) window(now, n int64) WindowStats {
+	var ws WindowStats
+	var latencyNs, maxNs, lagMs int64
+	var handled, lagged uint64
+	for i := range sm.buckets {
+		b := &sm.buckets[i]
+		if b.second <= now-n || b.second > now {
+			continue
+		}
+		ws.Messages += b.messages
+		ws.Errors += b.errors
+		ws.Fanout += b.fanout
+		handled += b.messages - b.errors
+		latencyNs += b.latencyNs
+		if b.maxNs > maxNs {
+			maxNs = b.maxNs
+		}
+		lagMs += b.lagMs
+		lagged += b.lagged
+	}
+
+	ws.RatePerSec = float64(ws.Messages) / float64(n)
+	if handled > 0 {
+		ws.AvgLatencyMs = float64(latencyNs) / float64(handled) / 1e6
+	}
+	ws.MaxLatencyMs = float64(maxNs) / 1e6
+	if lagged > 0 {
+		ws.AvgLagMs = float64(lagMs) / float64(lagged)
+	}
+	return ws
+}
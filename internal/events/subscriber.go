@@ -1,48 +1,60 @@
 package events
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
 	"github.com/trading-chitti/core-api-go/internal/websocket"
 )
 
 // Subscriber subscribes to NATS events and broadcasts to WebSocket clients
 type Subscriber struct {
-	nc  *nats.Conn
-	hub *websocket.Hub
+	nc      *nats.Conn
+	hub     *websocket.Hub
+	decoder *schemas.Decoder
+	metrics *Metrics
+	quality *TickQuality
+
+	// tickHandlers are called for every market.tick before it is broadcast
+	tickHandlers []func(TickEvent)
+	// signalHandlers are called for every signal.* event with its subject
+	signalHandlers []func(string, SignalEvent)
+
+	// outage, if set, can simulate the connection being down
+	outage OutageSimulator
+	// gate, if set, can hold back ticks that fail data quality rules
+	gate TickGate
+
+	// Undecodable events are queued on deadLetters and stored by one writer
+	deadLetterSink DeadLetterSink
+	deadLetters    chan deadLetter
+	done           chan struct{}
 }
 
-// SignalEvent represents a signal event from NATS
-type SignalEvent struct {
-	EventType   string    `json:"event_type"`
-	SignalID    int       `json:"signal_id"`
-	Symbol      string    `json:"symbol"`
-	SignalType  string    `json:"signal_type"`
-	EntryPrice  float64   `json:"entry_price"`
-	StopLoss    float64   `json:"stop_loss"`
-	TargetPrice float64   `json:"target_price"`
-	Confidence  float64   `json:"confidence"`
-	Status      string    `json:"status"`
-	CurrentPrice float64  `json:"current_price"`
-	ExitPrice   float64   `json:"exit_price"`
-	PNL         float64   `json:"pnl"`
-	GeneratedAt string    `json:"generated_at"`
-	Timestamp   string    `json:"timestamp"`
+// OutageSimulator fakes a NATS outage for resilience drills. While NATSDown,
+// the subscriber reports itself disconnected; DropEvent is asked for each
+// incoming event whether to discard it.
+type OutageSimulator interface {
+	NATSDown() bool
+	DropEvent() bool
 }
 
-// TickEvent represents a market tick event from NATS
-type TickEvent struct {
-	EventType string    `json:"event_type"`
-	Symbol    string    `json:"symbol"`
-	Price     float64   `json:"price"`
-	Volume    uint32    `json:"volume"`
-	ChangePct float64   `json:"change_pct"`
-	Timestamp string    `json:"timestamp"`
+// TickGate decides whether a market.tick may reach tick handlers and
+// WebSocket clients. data is the raw payload, kept for inspection.
+type TickGate interface {
+	AllowTick(event TickEvent, data []byte) bool
 }
 
+// SignalEvent represents a signal event from NATS
+type SignalEvent = schemas.Signal
+
+// TickEvent represents a market tick event from NATS
+type TickEvent = schemas.Tick
+
 // NewSubscriber creates a new NATS event subscriber
 func NewSubscriber(natsURL string, hub *websocket.Hub) (*Subscriber, error) {
 	nc, err := nats.Connect(natsURL,
@@ -64,101 +76,201 @@ func NewSubscriber(natsURL string, hub *websocket.Hub) (*Subscriber, error) {
 	}
 
 	log.Printf("✅ NATS subscriber connected: %s", natsURL)
-	return &Subscriber{nc: nc, hub: hub}, nil
+	return &Subscriber{nc: nc, hub: hub, decoder: schemas.NewDecoder(), metrics: NewMetrics(), quality: NewTickQuality(), done: make(chan struct{})}, nil
 }
 
 // Close closes the NATS connection
 func (s *Subscriber) Close() {
 	if s.nc != nil {
 		s.nc.Close()
+		close(s.done)
 		log.Println("👋 NATS subscriber disconnected")
 	}
 }
 
-// Subscribe subscribes to all relevant NATS subjects
-func (s *Subscriber) Subscribe() error {
-	// Subscribe to new signals
-	_, err := s.nc.Subscribe("signal.new", func(m *nats.Msg) {
-		var event SignalEvent
-		if err := json.Unmarshal(m.Data, &event); err != nil {
-			log.Printf("❌ Failed to unmarshal signal.new event: %v", err)
-			return
-		}
+// Connected reports whether the NATS connection is currently up
+func (s *Subscriber) Connected() bool {
+	if s.outage != nil && s.outage.NATSDown() {
+		return false
+	}
+	return s.nc != nil && s.nc.IsConnected()
+}
 
-		log.Printf("📥 Received signal.new: %s %s (%.2f confidence)", event.Symbol, event.SignalType, event.Confidence)
+// SetOutageSimulator installs a simulated outage. Call before Subscribe.
+func (s *Subscriber) SetOutageSimulator(o OutageSimulator) {
+	s.outage = o
+}
 
-		// Broadcast to WebSocket clients
-		s.hub.Broadcast(map[string]interface{}{
-			"type": "signal_new",
-			"data": event,
-		})
-	})
+// SetTickGate holds back ticks g rejects. Call before Subscribe.
+func (s *Subscriber) SetTickGate(g TickGate) {
+	s.gate = g
+}
+
+// DecodeStats returns per-subject decode counters, including payloads from
+// unknown schema versions
+func (s *Subscriber) DecodeStats() []schemas.SubjectStats {
+	return s.decoder.Stats()
+}
+
+// TickQuality returns duplicate, out-of-order and stale tick counts, with
+// the limit symbols that have the most issues
+func (s *Subscriber) TickQuality(limit int) TickQualityReport {
+	return s.quality.Report(limit)
+}
+
+// EventStats returns rolling throughput, fan-out, latency and lag per
+// consumed subject
+func (s *Subscriber) EventStats() []SubjectStats {
+	return s.metrics.Snapshot(subjects)
+}
+
+// OnTick registers fn to receive every market.tick event. Call before
+// Subscribe; fn runs on the NATS delivery goroutine and must not block.
+func (s *Subscriber) OnTick(fn func(TickEvent)) {
+	s.tickHandlers = append(s.tickHandlers, fn)
+}
+
+// OnSignal registers fn to receive every signal.new, signal.updated and
+// signal.closed event. Call before Subscribe; fn runs on the NATS delivery
+// goroutine and must not block.
+func (s *Subscriber) OnSignal(fn func(subject string, event SignalEvent)) {
+	s.signalHandlers = append(s.signalHandlers, fn)
+}
+
+// Publish sends a raw event on subject
+func (s *Subscriber) Publish(subject string, data []byte) error {
+	return s.nc.Publish(subject, data)
+}
+
+// Request sends a request on subject and waits up to timeout for one reply
+func (s *Subscriber) Request(subject string, data []byte, timeout time.Duration) ([]byte, error) {
+	msg, err := s.nc.Request(subject, data, timeout)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return msg.Data, nil
+}
+
+// subjects are the NATS subjects consumed by this service
+var subjects = []string{"signal.new", "signal.updated", "signal.closed", "market.tick"}
+
+// Subscribe subscribes to all relevant NATS subjects. Events that fail to
+// decode are written to the dead-letter sink, if one is set.
+func (s *Subscriber) Subscribe() error {
+	if s.deadLetters != nil {
+		go s.writeDeadLetters()
 	}
 
-	// Subscribe to signal updates
-	_, err = s.nc.Subscribe("signal.updated", func(m *nats.Msg) {
-		var event SignalEvent
-		if err := json.Unmarshal(m.Data, &event); err != nil {
-			log.Printf("❌ Failed to unmarshal signal.updated event: %v", err)
-			return
+	for _, subject := range subjects {
+		_, err := s.nc.Subscribe(subject, func(m *nats.Msg) {
+			if s.outage != nil && s.outage.DropEvent() {
+				return
+			}
+			received := time.Now()
+			out, err := s.handle(m.Subject, m.Data)
+			if err != nil {
+				log.Printf("❌ Failed to unmarshal %s event: %v", m.Subject, err)
+				s.deadLetter(m.Subject, m.Data, err)
+				s.metrics.record(m.Subject, received, sample{failed: true})
+				return
+			}
+			s.metrics.record(m.Subject, received, sample{
+				latency: time.Since(received),
+				lag:     producerLag(out.timestamp, received),
+				fanout:  out.fanout,
+			})
+		})
+		if err != nil {
+			return err
 		}
+	}
+
+	log.Println("✅ Subscribed to NATS subjects: signal.*, market.tick")
+	return nil
+}
 
-		log.Printf("📥 Received signal.updated: ID=%d Status=%s Price=%.2f", event.SignalID, event.Status, event.CurrentPrice)
+// Reprocess runs a stored payload through the handler for subject as if it
+// had just been received
+func (s *Subscriber) Reprocess(subject string, data []byte) error {
+	_, err := s.handle(subject, data)
+	return err
+}
 
-		// Broadcast to WebSocket clients
-		s.hub.Broadcast(map[string]interface{}{
-			"type": "signal_updated",
-			"data": event,
-		})
-	})
+// handled describes a successfully decoded event for metrics
+type handled struct {
+	// timestamp is the producer's event time, if it sent one
+	timestamp string
+	// fanout is how many WebSocket clients the event was broadcast to
+	fanout int
+}
+
+// producerLag is how long an event took from its producer to this service,
+// or -1 if the producer timestamp is missing or unparseable
+func producerLag(timestamp string, received time.Time) time.Duration {
+	ts, err := time.Parse(time.RFC3339Nano, timestamp)
 	if err != nil {
-		return err
+		return -1
 	}
+	if lag := received.Sub(ts); lag > 0 {
+		return lag
+	}
+	return 0
+}
 
-	// Subscribe to signal closed
-	_, err = s.nc.Subscribe("signal.closed", func(m *nats.Msg) {
+// handle decodes one event and fans it out. It returns an error only when
+// the payload cannot be decoded.
+func (s *Subscriber) handle(subject string, data []byte) (handled, error) {
+	switch subject {
+	case "signal.new", "signal.updated", "signal.closed":
 		var event SignalEvent
-		if err := json.Unmarshal(m.Data, &event); err != nil {
-			log.Printf("❌ Failed to unmarshal signal.closed event: %v", err)
-			return
+		if err := s.decoder.Decode(subject, data, &event); err != nil {
+			return handled{}, err
 		}
 
-		log.Printf("📥 Received signal.closed: ID=%d Status=%s PNL=%.2f", event.SignalID, event.Status, event.PNL)
+		switch subject {
+		case "signal.new":
+			log.Printf("📥 Received signal.new: %s %s (%.2f confidence)", event.Symbol, event.SignalType, event.Confidence)
+		case "signal.updated":
+			log.Printf("📥 Received signal.updated: ID=%d Status=%s Price=%.2f", event.SignalID, event.Status, event.CurrentPrice)
+		case "signal.closed":
+			log.Printf("📥 Received signal.closed: ID=%d Status=%s PNL=%.2f", event.SignalID, event.Status, event.PNL)
+		}
+
+		for _, fn := range s.signalHandlers {
+			fn(subject, event)
+		}
 
-		// Broadcast to WebSocket clients
-		s.hub.Broadcast(map[string]interface{}{
-			"type": "signal_closed",
+		// Broadcast to WebSocket clients; those on the "mine" topic only
+		// get the symbols their user follows
+		s.hub.BroadcastAbout(websocket.Subject{Symbol: event.Symbol}, map[string]interface{}{
+			"type": strings.Replace(subject, ".", "_", 1),
 			"data": event,
 		})
-	})
-	if err != nil {
-		return err
-	}
+		return handled{timestamp: event.Timestamp, fanout: s.hub.ClientCount()}, nil
 
-	// Subscribe to market ticks
-	_, err = s.nc.Subscribe("market.tick", func(m *nats.Msg) {
+	case "market.tick":
 		var event TickEvent
-		if err := json.Unmarshal(m.Data, &event); err != nil {
-			log.Printf("❌ Failed to unmarshal market.tick event: %v", err)
-			return
+		if err := s.decoder.Decode(subject, data, &event); err != nil {
+			return handled{}, err
+		}
+		s.quality.Observe(event, time.Now())
+		if s.gate != nil && !s.gate.AllowTick(event, data) {
+			return handled{timestamp: event.Timestamp}, nil
 		}
 
-		// Only broadcast every 5 seconds to avoid overwhelming clients
-		// (ticks are high frequency)
-		// In production, you'd add throttling logic here
+		for _, fn := range s.tickHandlers {
+			fn(event)
+		}
 
-		// Broadcast to WebSocket clients
-		s.hub.Broadcast(map[string]interface{}{
+		// Broadcast to WebSocket clients; those that asked for fewer ticks
+		// are throttled per symbol by the hub
+		s.hub.BroadcastKeyed(event.Symbol, map[string]interface{}{
 			"type": "market_tick",
 			"data": event,
 		})
-	})
-	if err != nil {
-		return err
-	}
+		return handled{timestamp: event.Timestamp, fanout: s.hub.ClientCount()}, nil
 
-	log.Println("✅ Subscribed to NATS subjects: signal.*, market.tick")
-	return nil
+	default:
+		return handled{}, fmt.Errorf("no handler for subject %q", subject)
+	}
 }
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultStaleAfter is how old a tick's timestamp may be on arrival before
+// it's counted stale
+const defaultStaleAfter = 30 * time.Second
+
+// Tick quality issues
+const (
+	TickDuplicate  = "duplicate"    // same timestamp, price and volume as the symbol's last tick
+	TickOutOfOrder = "out_of_order" // older than a tick already received for the symbol
+	TickStale      = "stale"        // timestamp more than StaleAfter behind arrival
+	TickUntimed    = "untimed"      // missing or unparseable timestamp
+)
+
+// qualityBucket counts one second of ticks and their issues
+type qualityBucket struct {
+	second   int64
+	ticks    uint64
+	affected uint64
+	issues   map[string]uint64
+}
+
+// symbolQuality is the last tick seen for a symbol and its issue counts
+type symbolQuality struct {
+	lastTS     time.Time
+	lastPrice  float64
+	lastVolume uint32
+	ticks      uint64
+	issues     map[string]uint64
+	lastIssue  time.Time
+}
+
+// TickQuality counts duplicate, out-of-order and stale market.tick events
+// per symbol, so regressions in the market bridge show up in monitoring
+// before they show up in signals
+type TickQuality struct {
+	mu         sync.Mutex
+	staleAfter time.Duration
+	symbols    map[string]*symbolQuality
+	buckets    [metricsWindow]qualityBucket
+	started    time.Time
+}
+
+// NewTickQuality creates a tracker, reading TICK_STALE_SECONDS (default 30)
+func NewTickQuality() *TickQuality {
+	staleAfter := defaultStaleAfter
+	if v, err := strconv.Atoi(os.Getenv("TICK_STALE_SECONDS")); err == nil && v > 0 {
+		staleAfter = time.Duration(v) * time.Second
+	}
+	return &TickQuality{staleAfter: staleAfter, symbols: map[string]*symbolQuality{}, started: time.Now()}
+}
+
+// Observe classifies a tick received at now, returning its issues
+func (q *TickQuality) Observe(t TickEvent, now time.Time) []string {
+	var issues []string
+	ts, err := time.Parse(time.RFC3339Nano, t.Timestamp)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sq, ok := q.symbols[t.Symbol]
+	if !ok {
+		sq = &symbolQuality{issues: map[string]uint64{}}
+		q.symbols[t.Symbol] = sq
+	}
+	sq.ticks++
+
+	switch {
+	case err != nil:
+		issues = append(issues, TickUntimed)
+	case ok && ts.Equal(sq.lastTS) && t.Price == sq.lastPrice && t.Volume == sq.lastVolume:
+		issues = append(issues, TickDuplicate)
+	case ok && ts.Before(sq.lastTS):
+		issues = append(issues, TickOutOfOrder)
+	default:
+		sq.lastTS, sq.lastPrice, sq.lastVolume = ts, t.Price, t.Volume
+	}
+	if err == nil && now.Sub(ts) > q.staleAfter {
+		issues = append(issues, TickStale)
+	}
+
+	sec := now.Unix()
+	b := &q.buckets[sec%metricsWindow]
+	if b.second != sec {
+		*b = qualityBucket{second: sec, issues: map[string]uint64{}}
+	}
+	b.ticks++
+	if len(issues) > 0 {
+		b.affected++
+	}
+	for _, issue := range issues {
+		b.issues[issue]++
+		sq.issues[issue]++
+		sq.lastIssue = now
+	}
+	return issues
+}
+
+// TickQualityWindow counts ticks and issues over a rolling window
+type TickQualityWindow struct {
+	Ticks  uint64            `json:"ticks"`
+	Issues map[string]uint64 `json:"issues"`
+	// IssueRate is the share of ticks with at least one issue, 0-1
+	IssueRate float64 `json:"issue_rate"`
+}
+
+// SymbolTickQuality is one symbol's issue counts since startup
+type SymbolTickQuality struct {
+	Symbol      string            `json:"symbol"`
+	Ticks       uint64            `json:"ticks"`
+	Issues      map[string]uint64 `json:"issues"`
+	IssueCount  uint64            `json:"issue_count"`
+	LastTickAt  *time.Time        `json:"last_tick_at"`
+	LastIssueAt *time.Time        `json:"last_issue_at"`
+}
+
+// TickQualityReport summarises tick quality
+type TickQualityReport struct {
+	Since             time.Time                    `json:"since"`
+	StaleAfterSeconds float64                      `json:"stale_after_seconds"`
+	Symbols           int                          `json:"symbols"`
+	Windows           map[string]TickQualityWindow `json:"windows"`
+	// Worst are the symbols with the most issues, most first
+	Worst []SymbolTickQuality `json:"worst"`
+}
+
+// Report returns rolling totals and the limit symbols with the most issues
+func (q *TickQuality) Report(limit int) TickQualityReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	report := TickQualityReport{
+		Since:             q.started,
+		StaleAfterSeconds: q.staleAfter.Seconds(),
+		Symbols:           len(q.symbols),
+		Windows:           map[string]TickQualityWindow{},
+		Worst:             []SymbolTickQuality{},
+	}
+	for _, w := range windows {
+		report.Windows[w.name] = q.window(now.Unix(), w.seconds)
+	}
+
+	for symbol, sq := range q.symbols {
+		var count uint64
+		for _, n := range sq.issues {
+			count += n
+		}
+		if count == 0 {
+			continue
+		}
+		s := SymbolTickQuality{Symbol: symbol, Ticks: sq.ticks, Issues: map[string]uint64{}, IssueCount: count}
+		for issue, n := range sq.issues {
+			s.Issues[issue] = n
+		}
+		if !sq.lastTS.IsZero() {
+			last := sq.lastTS
+			s.LastTickAt = &last
+		}
+		lastIssue := sq.lastIssue
+		s.LastIssueAt = &lastIssue
+		report.Worst = append(report.Worst, s)
+	}
+	sort.Slice(report.Worst, func(i, j int) bool {
+		if report.Worst[i].IssueCount != report.Worst[j].IssueCount {
+			return report.Worst[i].IssueCount > report.Worst[j].IssueCount
+		}
+		return report.Worst[i].Symbol < report.Worst[j].Symbol
+	})
+	if len(report.Worst) > limit {
+		report.Worst = report.Worst[:limit]
+	}
+	return report
+}
+
+// window sums the buckets of the last n seconds
+func (q *TickQuality) window(now, n int64) TickQualityWindow {
+	w := TickQualityWindow{Issues: map[string]uint64{}}
+	var affected uint64
+	for i := range q.buckets {
+		b := &q.buckets[i]
+		if b.second <= now-n || b.second > now {
+			continue
+		}
+		w.Ticks += b.ticks
+		affected += b.affected
+		for issue, count := range b.issues {
+			w.Issues[issue] += count
+		}
+	}
+	if w.Ticks > 0 {
+		w.IssueRate = float64(affected) / float64(w.Ticks)
+	}
+	return w
+}
@@ -0,0 +1,330 @@
+// Package exports runs bulk data extracts as background jobs and writes the
+// results to object storage.
+package exports
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/storage"
+)
+
+// maxConcurrentJobs bounds how many extracts hit the database at once
+const maxConcurrentJobs = 2
+
+// jobTimeout caps how long a single extract may run
+const jobTimeout = 30 * time.Minute
+
+// DownloadURLTTL is how long a download link stays valid
+const DownloadURLTTL = time.Hour
+
+// rowWriter encodes rows in one output format
+type rowWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// format describes an output encoding
+type format struct {
+	extension   string
+	contentType string
+	newWriter   func(w io.Writer, columns []database.ExportColumn, metadata map[string]string) (rowWriter, error)
+}
+
+// formats are the supported output encodings keyed by request name
+var formats = map[string]format{
+	"csv":     {extension: "csv", contentType: "text/csv", newWriter: newCSVWriter},
+	"parquet": {extension: "parquet", contentType: "application/vnd.apache.parquet", newWriter: newParquetWriter},
+}
+
+// FormatNames lists the supported output formats
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Manager accepts export requests and runs them in the background
+type Manager struct {
+	db    *database.DB
+	store storage.Store
+	slots chan struct{}
+
+	// completeHandlers are told each finished export's size
+	completeHandlers []func(accountID string, rows, sizeBytes int64)
+}
+
+// NewManager creates an export manager writing to store
+func NewManager(db *database.DB, store storage.Store) *Manager {
+	return &Manager{db: db, store: store, slots: make(chan struct{}, maxConcurrentJobs)}
+}
+
+// OnComplete registers fn to receive the row count and file size of every
+// completed export, with the account it's metered against. Call before
+// Submit; fn runs on the export's goroutine.
+func (m *Manager) OnComplete(fn func(accountID string, rows, sizeBytes int64)) {
+	m.completeHandlers = append(m.completeHandlers, fn)
+}
+
+// Submit validates the request, records a job for userID and starts it in
+// the background, metered against accountID
+func (m *Manager) Submit(ctx context.Context, userID, accountID string, req database.ExportRequest) (*database.ExportJob, error) {
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+	if _, ok := formats[req.Format]; !ok {
+		return nil, fmt.Errorf("unsupported format %q (available: %v)", req.Format, FormatNames())
+	}
+	if err := database.ValidateExportRequest(req); err != nil {
+		return nil, err
+	}
+	if _, _, err := ParseRange(req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	job, err := m.db.CreateExportJob(ctx, newJobID(), userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	go m.run(job.ID, userID, accountID, req)
+	return job, nil
+}
+
+// FailInterrupted marks jobs left pending or running by a previous process
+// as failed so their owners can resubmit them. Call once at startup, before
+// Submit.
+func (m *Manager) FailInterrupted(ctx context.Context) error {
+	n, err := m.db.FailInterruptedExportJobs(ctx, time.Now(), "interrupted by a server restart; please resubmit")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("⚠️  Failed %d export jobs interrupted by a restart", n)
+	}
+	return nil
+}
+
+// DownloadURL returns a signed link for a completed job
+func (m *Manager) DownloadURL(job *database.ExportJob) (string, error) {
+	if job.Status != database.ExportStatusCompleted || job.ObjectKey == nil {
+		return "", fmt.Errorf("export is not complete")
+	}
+	return m.store.PresignGet(*job.ObjectKey, DownloadURLTTL)
+}
+
+// run executes one export; failures are recorded on the job
+func (m *Manager) run(jobID, userID, accountID string, req database.ExportRequest) {
+	m.slots <- struct{}{}
+	defer func() { <-m.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	if err := m.db.MarkExportJobRunning(ctx, jobID); err != nil {
+		log.Printf("❌ Export %s: %v", jobID, err)
+	}
+
+	key, rows, size, err := m.export(ctx, jobID, userID, req)
+	if err != nil {
+		log.Printf("❌ Export %s (%s) failed: %v", jobID, req.Dataset, err)
+		if ferr := m.db.FailExportJob(context.Background(), jobID, err.Error()); ferr != nil {
+			log.Printf("❌ Export %s: %v", jobID, ferr)
+		}
+		return
+	}
+
+	if err := m.db.CompleteExportJob(ctx, jobID, m.store.Name(), key, rows, size); err != nil {
+		log.Printf("❌ Export %s: %v", jobID, err)
+		return
+	}
+	log.Printf("✅ Export %s (%s) completed: %d rows, %d bytes", jobID, req.Dataset, rows, size)
+	for _, fn := range m.completeHandlers {
+		fn(accountID, rows, size)
+	}
+}
+
+// export writes the extract for a job and returns its object key
+func (m *Manager) export(ctx context.Context, jobID, userID string, req database.ExportRequest) (string, int64, int64, error) {
+	from, to, _ := ParseRange(req.From, req.To)
+	key := fmt.Sprintf("%s%s/%s/%s_%s_%s.%s", storage.PrefixExports, userID, jobID, req.Dataset,
+		from.Format("20060102"), to.Format("20060102"), formats[req.Format].extension)
+
+	rows, size, err := Extract(ctx, m.db, m.store, key, req, from, to, Metadata(jobID, req, from, to))
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return key, rows, size, nil
+}
+
+// Extract writes a dataset to a temp file in req.Format, then uploads it to
+// store under key. It returns the row count and file size.
+func Extract(ctx context.Context, db *database.DB, store storage.Store, key string, req database.ExportRequest, from, to time.Time, metadata map[string]string) (int64, int64, error) {
+	f, ok := formats[req.Format]
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported format %q", req.Format)
+	}
+
+	tmp, err := os.CreateTemp("", "export-*."+f.extension)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w, err := f.newWriter(tmp, database.ExportColumns(req.Dataset), metadata)
+	if err != nil {
+		return 0, 0, err
+	}
+	rows, err := db.StreamExport(ctx, req, from, to, w.WriteRow)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, 0, fmt.Errorf("failed to finalise %s: %w", req.Format, err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	if err := store.Put(ctx, key, tmp, size, f.contentType); err != nil {
+		return 0, 0, err
+	}
+	return rows, size, nil
+}
+
+// ParseRange parses from/to as YYYY-MM-DD (IST) or RFC3339. Dates are
+// inclusive; the default range is the last 7 days.
+func ParseRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	parse := func(s string, endOfDay bool) (time.Time, error) {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", s, ist)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC3339)", s)
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	to := time.Now()
+	if toStr != "" {
+		t, err := parse(toStr, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -7)
+	if fromStr != "" {
+		f, err := parse(fromStr, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = f
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+// Metadata describes an extract so notebooks can recover its schema and
+// parameters from the file alone (stored in the Parquet key-value metadata)
+func Metadata(jobID string, req database.ExportRequest, from, to time.Time) map[string]string {
+	schema, _ := json.Marshal(database.ExportColumns(req.Dataset))
+	symbols, _ := json.Marshal(req.Symbols)
+	return map[string]string{
+		"trading_chitti.export_id":    jobID,
+		"trading_chitti.dataset":      req.Dataset,
+		"trading_chitti.schema":       string(schema),
+		"trading_chitti.from":         from.Format(time.RFC3339),
+		"trading_chitti.to":           to.Format(time.RFC3339),
+		"trading_chitti.symbols":      string(symbols),
+		"trading_chitti.generated_at": time.Now().Format(time.RFC3339),
+	}
+}
+
+// sortedKeys returns map keys in a stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "exp_" + hex.EncodeToString(b)
+}
+
+// csvWriter writes a header row followed by one record per row
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []database.ExportColumn, _ map[string]string) (rowWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch val := v.(type) {
+		case nil:
+			record[i] = ""
+		case string:
+			record[i] = val
+		case float64:
+			record[i] = strconv.FormatFloat(val, 'f', -1, 64)
+		case int64:
+			record[i] = strconv.FormatInt(val, 10)
+		case bool:
+			record[i] = strconv.FormatBool(val)
+		case time.Time:
+			record[i] = val.Format(time.RFC3339)
+		default:
+			record[i] = fmt.Sprint(val)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
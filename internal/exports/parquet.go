@@ -0,0 +1,418 @@
+package exports
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Parquet output is written without external dependencies: flat schema, one
+// uncompressed PLAIN-encoded data page per column per row group, all columns
+// OPTIONAL. That is enough for pandas, pyarrow, polars and duckdb to read it.
+
+// parquetRowGroupSize is the number of rows buffered per row group
+const parquetRowGroupSize = 100000
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet converted types
+const (
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+)
+
+// Parquet encodings
+const (
+	encodingPlain = 0
+	encodingRLE   = 3
+)
+
+// parquetColumn buffers one column of the current row group
+type parquetColumn struct {
+	name     string
+	physical int32
+	logical  string
+	defs     []bool
+	values   []byte
+	boolBits []bool
+}
+
+// parquetWriter streams row groups to w and writes the footer on Close
+type parquetWriter struct {
+	w         *countingWriter
+	columns   []*parquetColumn
+	metadata  map[string]string
+	rowGroups []parquetRowGroup
+	buffered  int
+	totalRows int64
+}
+
+type parquetRowGroup struct {
+	numRows   int64
+	totalSize int64
+	chunks    []parquetChunk
+}
+
+type parquetChunk struct {
+	column    *parquetColumn
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newParquetWriter(w io.Writer, columns []database.ExportColumn, metadata map[string]string) (rowWriter, error) {
+	pw := &parquetWriter{w: &countingWriter{w: w}, metadata: metadata}
+	for _, c := range columns {
+		col := &parquetColumn{name: c.Name, logical: c.Type}
+		switch c.Type {
+		case "string":
+			col.physical = parquetByteArray
+		case "int64", "timestamp":
+			col.physical = parquetInt64
+		case "float64":
+			col.physical = parquetDouble
+		case "bool":
+			col.physical = parquetBoolean
+		default:
+			return nil, fmt.Errorf("unsupported column type %q for %s", c.Type, c.Name)
+		}
+		pw.columns = append(pw.columns, col)
+	}
+	if _, err := io.WriteString(pw.w, parquetMagic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (p *parquetWriter) WriteRow(values []interface{}) error {
+	for i, col := range p.columns {
+		v := values[i]
+		if v == nil {
+			col.defs = append(col.defs, false)
+			continue
+		}
+		col.defs = append(col.defs, true)
+
+		switch col.physical {
+		case parquetByteArray:
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprint(v)
+			}
+			col.values = binary.LittleEndian.AppendUint32(col.values, uint32(len(s)))
+			col.values = append(col.values, s...)
+		case parquetInt64:
+			var n int64
+			switch val := v.(type) {
+			case int64:
+				n = val
+			case float64:
+				n = int64(val)
+			case time.Time:
+				n = val.UnixMicro()
+			default:
+				return fmt.Errorf("column %s: cannot encode %T as int64", col.name, v)
+			}
+			col.values = binary.LittleEndian.AppendUint64(col.values, uint64(n))
+		case parquetDouble:
+			var f float64
+			switch val := v.(type) {
+			case float64:
+				f = val
+			case int64:
+				f = float64(val)
+			default:
+				return fmt.Errorf("column %s: cannot encode %T as double", col.name, v)
+			}
+			col.values = binary.LittleEndian.AppendUint64(col.values, math.Float64bits(f))
+		case parquetBoolean:
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("column %s: cannot encode %T as boolean", col.name, v)
+			}
+			col.boolBits = append(col.boolBits, b)
+		}
+	}
+
+	p.buffered++
+	if p.buffered >= parquetRowGroupSize {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+// flushRowGroup writes one data page per column and resets the buffers
+func (p *parquetWriter) flushRowGroup() error {
+	if p.buffered == 0 {
+		return nil
+	}
+
+	rg := parquetRowGroup{numRows: int64(p.buffered)}
+	for _, col := range p.columns {
+		values := col.values
+		if col.physical == parquetBoolean {
+			values = packBits(col.boolBits)
+		}
+		levels := encodeDefinitionLevels(col.defs)
+
+		page := make([]byte, 0, 4+len(levels)+len(values))
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+		page = append(page, values...)
+
+		header := newThriftEncoder()
+		header.i32Field(1, 0) // DATA_PAGE
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.structField(5)
+		header.i32Field(1, int32(len(col.defs)))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		offset := p.w.n
+		if _, err := p.w.Write(header.bytes()); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(page); err != nil {
+			return err
+		}
+		size := p.w.n - offset
+
+		rg.chunks = append(rg.chunks, parquetChunk{column: col, offset: offset, size: size, numValues: int64(len(col.defs))})
+		rg.totalSize += size
+
+		col.defs = col.defs[:0]
+		col.values = col.values[:0]
+		col.boolBits = col.boolBits[:0]
+	}
+
+	p.rowGroups = append(p.rowGroups, rg)
+	p.totalRows += int64(p.buffered)
+	p.buffered = 0
+	return nil
+}
+
+// Close flushes the final row group and writes the file footer
+func (p *parquetWriter) Close() error {
+	if err := p.flushRowGroup(); err != nil {
+		return err
+	}
+
+	meta := newThriftEncoder()
+	meta.i32Field(1, 1) // version
+
+	// Schema: root group followed by one leaf per column
+	meta.listField(2, thriftStruct, len(p.columns)+1)
+	meta.beginListStruct()
+	meta.binaryField(4, "schema")
+	meta.i32Field(5, int32(len(p.columns)))
+	meta.endStruct()
+	for _, col := range p.columns {
+		meta.beginListStruct()
+		meta.i32Field(1, col.physical)
+		meta.i32Field(3, 1) // OPTIONAL
+		meta.binaryField(4, col.name)
+		switch col.logical {
+		case "string":
+			meta.i32Field(6, convertedUTF8)
+		case "timestamp":
+			meta.i32Field(6, convertedTimestampMicros)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64Field(3, p.totalRows)
+
+	meta.listField(4, thriftStruct, len(p.rowGroups))
+	for _, rg := range p.rowGroups {
+		meta.beginListStruct()
+		meta.listField(1, thriftStruct, len(rg.chunks))
+		for _, chunk := range rg.chunks {
+			meta.beginListStruct()
+			meta.i64Field(2, chunk.offset)
+			meta.structField(3)
+			meta.i32Field(1, chunk.column.physical)
+			meta.listField(2, thriftI32, 2)
+			meta.i32Elem(encodingPlain)
+			meta.i32Elem(encodingRLE)
+			meta.listField(3, thriftBinary, 1)
+			meta.binaryElem(chunk.column.name)
+			meta.i32Field(4, 0) // UNCOMPRESSED
+			meta.i64Field(5, chunk.numValues)
+			meta.i64Field(6, chunk.size)
+			meta.i64Field(7, chunk.size)
+			meta.i64Field(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64Field(2, rg.totalSize)
+		meta.i64Field(3, rg.numRows)
+		meta.endStruct()
+	}
+
+	if len(p.metadata) > 0 {
+		keys := sortedKeys(p.metadata)
+		meta.listField(5, thriftStruct, len(keys))
+		for _, k := range keys {
+			meta.beginListStruct()
+			meta.binaryField(1, k)
+			meta.binaryField(2, p.metadata[k])
+			meta.endStruct()
+		}
+	}
+	meta.binaryField(6, "trading-chitti core-api")
+	meta.endStruct()
+
+	footer := meta.bytes()
+	if _, err := p.w.Write(footer); err != nil {
+		return err
+	}
+	if _, err := p.w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(p.w, parquetMagic)
+	return err
+}
+
+// encodeDefinitionLevels writes bit-width-1 levels as bit-packed runs of the
+// RLE/bit-packing hybrid encoding
+func encodeDefinitionLevels(defs []bool) []byte {
+	if len(defs) == 0 {
+		return nil
+	}
+	groups := (len(defs) + 7) / 8
+	out := appendUvarint(nil, uint64(groups)<<1|1)
+	return append(out, packBits(defs)...)
+}
+
+// packBits packs booleans LSB-first, padding the last byte with zeros
+func packBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+// Thrift compact protocol, limited to what the Parquet footer needs
+
+const (
+	thriftBinary = 8
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+type thriftEncoder struct {
+	buf     []byte
+	lastIDs []int16
+	lastID  int16
+}
+
+func newThriftEncoder() *thriftEncoder {
+	return &thriftEncoder{}
+}
+
+func (e *thriftEncoder) bytes() []byte { return e.buf }
+
+func (e *thriftEncoder) fieldHeader(id int16, typ byte) {
+	if delta := id - e.lastID; delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta)<<4|typ)
+	} else {
+		e.buf = append(e.buf, typ)
+		e.buf = appendUvarint(e.buf, zigzag(int64(id)))
+	}
+	e.lastID = id
+}
+
+func (e *thriftEncoder) i32Field(id int16, v int32) {
+	e.fieldHeader(id, thriftI32)
+	e.i32Elem(v)
+}
+
+func (e *thriftEncoder) i64Field(id int16, v int64) {
+	e.fieldHeader(id, thriftI64)
+	e.buf = appendUvarint(e.buf, zigzag(v))
+}
+
+func (e *thriftEncoder) binaryField(id int16, v string) {
+	e.fieldHeader(id, thriftBinary)
+	e.binaryElem(v)
+}
+
+func (e *thriftEncoder) i32Elem(v int32) {
+	e.buf = appendUvarint(e.buf, zigzag(int64(v)))
+}
+
+func (e *thriftEncoder) binaryElem(v string) {
+	e.buf = appendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// structField opens a nested struct field; close it with endStruct
+func (e *thriftEncoder) structField(id int16) {
+	e.fieldHeader(id, thriftStruct)
+	e.lastIDs = append(e.lastIDs, e.lastID)
+	e.lastID = 0
+}
+
+// beginListStruct opens a struct element inside a list
+func (e *thriftEncoder) beginListStruct() {
+	e.lastIDs = append(e.lastIDs, e.lastID)
+	e.lastID = 0
+}
+
+func (e *thriftEncoder) endStruct() {
+	e.buf = append(e.buf, 0)
+	if n := len(e.lastIDs); n > 0 {
+		e.lastID = e.lastIDs[n-1]
+		e.lastIDs = e.lastIDs[:n-1]
+	}
+}
+
+func (e *thriftEncoder) listField(id int16, elemType byte, size int) {
+	e.fieldHeader(id, thriftList)
+	if size < 15 {
+		e.buf = append(e.buf, byte(size)<<4|elemType)
+	} else {
+		e.buf = append(e.buf, 0xF0|elemType)
+		e.buf = appendUvarint(e.buf, uint64(size))
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	return binary.AppendUvarint(buf, v)
+}
@@ -0,0 +1,212 @@
+package exports
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+func TestParquetWriter(t *testing.T) {
+	columns := []database.ExportColumn{
+		{Name: "symbol", Type: "string"},
+		{Name: "qty", Type: "int64"},
+		{Name: "price", Type: "float64"},
+		{Name: "active", Type: "bool"},
+		{Name: "ts", Type: "timestamp"},
+	}
+	t0 := time.Date(2025, 1, 2, 9, 15, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"INFY", int64(10), 1500.5, true, t0},
+		{nil, nil, nil, nil, nil},
+		{"TCS", int64(-3), 3900.0, false, t0.Add(time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	w, err := newParquetWriter(&buf, columns, map[string]string{"dataset": "bars"})
+	if err != nil {
+		t.Fatalf("newParquetWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatalf("file does not start and end with %s", parquetMagic)
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart <= len(parquetMagic) {
+		t.Fatalf("footer length %d overruns the %d-byte file", footerLen, len(data))
+	}
+
+	d := &thriftDecoder{buf: data[footerStart : len(data)-8]}
+	meta := d.readStruct()
+	if d.err != nil {
+		t.Fatalf("decode footer: %v", d.err)
+	}
+	if d.pos != footerLen {
+		t.Errorf("footer decoded %d of %d bytes", d.pos, footerLen)
+	}
+	if meta[3] != int64(len(rows)) {
+		t.Errorf("num_rows = %v, want %d", meta[3], len(rows))
+	}
+	if meta[6] != "trading-chitti core-api" {
+		t.Errorf("created_by = %v", meta[6])
+	}
+
+	schema := meta[2].([]interface{})
+	if len(schema) != len(columns)+1 || schema[0].(map[int16]interface{})[5] != int64(len(columns)) {
+		t.Fatalf("schema = %v, want a root with %d children", schema, len(columns))
+	}
+	for i, col := range columns {
+		if name := schema[i+1].(map[int16]interface{})[4]; name != col.Name {
+			t.Errorf("schema[%d] name = %v, want %s", i+1, name, col.Name)
+		}
+	}
+
+	kv := meta[5].([]interface{})
+	if len(kv) != 1 || kv[0].(map[int16]interface{})[1] != "dataset" || kv[0].(map[int16]interface{})[2] != "bars" {
+		t.Errorf("key_value_metadata = %v, want dataset=bars", kv)
+	}
+
+	rowGroups := meta[4].([]interface{})
+	if len(rowGroups) != 1 {
+		t.Fatalf("got %d row groups, want 1", len(rowGroups))
+	}
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	if len(chunks) != len(columns) {
+		t.Fatalf("got %d column chunks, want %d", len(chunks), len(columns))
+	}
+
+	// Every column has the middle row null: levels are one bit-packed group
+	// of 1,0,1
+	wantLevels := []byte{3, 0b101}
+	wantValues := map[string][]byte{
+		"symbol": append(append(binary.LittleEndian.AppendUint32(nil, 4), "INFY"...), append(binary.LittleEndian.AppendUint32(nil, 3), "TCS"...)...),
+		"qty":    binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint64(nil, 10), uint64(math.MaxUint64-2)),
+		"price":  binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint64(nil, math.Float64bits(1500.5)), math.Float64bits(3900)),
+		"active": {0b01},
+		"ts":     binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint64(nil, uint64(t0.UnixMicro())), uint64(t0.Add(time.Minute).UnixMicro())),
+	}
+	for i, col := range columns {
+		chunkMeta := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		if chunkMeta[5] != int64(len(rows)) {
+			t.Errorf("%s: num_values = %v, want %d", col.Name, chunkMeta[5], len(rows))
+		}
+
+		offset := int(chunkMeta[9].(int64))
+		pd := &thriftDecoder{buf: data[offset:]}
+		header := pd.readStruct()
+		if pd.err != nil {
+			t.Fatalf("%s: decode page header: %v", col.Name, pd.err)
+		}
+		page := data[offset+pd.pos : offset+pd.pos+int(header[2].(int64))]
+		if int64(pd.pos+len(page)) != chunkMeta[6] {
+			t.Errorf("%s: page is %d bytes, chunk says %v", col.Name, pd.pos+len(page), chunkMeta[6])
+		}
+
+		levelsLen := int(binary.LittleEndian.Uint32(page))
+		if levels := page[4 : 4+levelsLen]; !bytes.Equal(levels, wantLevels) {
+			t.Errorf("%s: definition levels = %v, want %v", col.Name, levels, wantLevels)
+		}
+		if values := page[4+levelsLen:]; !bytes.Equal(values, wantValues[col.Name]) {
+			t.Errorf("%s: values = %v, want %v", col.Name, values, wantValues[col.Name])
+		}
+	}
+}
+
+// thriftDecoder reads the Thrift compact protocol subset parquetWriter
+// emits, returning structs as field ID -> value maps
+type thriftDecoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (d *thriftDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf[d.pos:])
+	if n <= 0 {
+		d.fail("bad varint at %d", d.pos)
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *thriftDecoder) readByte() byte {
+	if d.pos >= len(d.buf) {
+		d.fail("unexpected end of data")
+		return 0
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b
+}
+
+func (d *thriftDecoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (d *thriftDecoder) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var lastID int16
+	for d.err == nil {
+		h := d.readByte()
+		if h == 0 {
+			break
+		}
+		id := lastID + int16(h>>4)
+		if h>>4 == 0 {
+			u := d.uvarint()
+			id = int16(int64(u>>1) ^ -int64(u&1))
+		}
+		lastID = id
+		fields[id] = d.readValue(h & 0x0F)
+	}
+	return fields
+}
+
+func (d *thriftDecoder) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		u := d.uvarint()
+		return int64(u>>1) ^ -int64(u&1)
+	case thriftBinary:
+		n := int(d.uvarint())
+		if d.pos+n > len(d.buf) {
+			d.fail("binary of %d bytes overruns data", n)
+			return ""
+		}
+		s := string(d.buf[d.pos : d.pos+n])
+		d.pos += n
+		return s
+	case thriftList:
+		h := d.readByte()
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(d.uvarint())
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size && d.err == nil; i++ {
+			list = append(list, d.readValue(h&0x0F))
+		}
+		return list
+	case thriftStruct:
+		return d.readStruct()
+	}
+	d.fail("unsupported thrift type %d", typ)
+	return nil
+}
@@ -0,0 +1,565 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Object describes a GraphQL object type and its fields
+type Object struct {
+	Name   string
+	Fields map[string]*FieldDef
+}
+
+// FieldDef describes how to resolve one field of an Object.
+//
+// Fields resolve level by level: all parents at the same depth are passed to
+// Batch together, so a nested field under a list costs one query, not one per
+// row. Resolve is the per-parent form for fields that don't need batching.
+// Fields with neither are read from the parent by JSON tag name.
+type FieldDef struct {
+	// Type is the object type of the field; nil for scalars
+	Type *Object
+	// Batch returns one result per parent, in parent order
+	Batch func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error)
+	// Resolve returns the result for a single parent
+	Resolve func(ctx context.Context, parent interface{}, args map[string]interface{}) (interface{}, error)
+	// Size estimates how many items a list field returns per parent, for
+	// query complexity; nil for fields returning a single value
+	Size func(args map[string]interface{}) int
+}
+
+// Schema is the root of an executable schema
+type Schema struct {
+	Query *Object
+	// MaxDepth bounds how deeply selections may nest; 0 for no limit
+	MaxDepth int
+	// MaxComplexity bounds a query's estimated cost, the number of values
+	// it could resolve with every list at its Size; 0 for no limit
+	MaxComplexity int
+}
+
+// Request is a standard GraphQL-over-HTTP request body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Error is a GraphQL response error
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Response is a standard GraphQL response body
+type Response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []Error                `json:"errors,omitempty"`
+}
+
+// execution carries per-request state through the resolver tree
+type execution struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	errors    []Error
+}
+
+// Execute parses and runs a query against the schema. Field errors are
+// collected in the response and the failing field is set to null.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	if op.Type != "query" {
+		return &Response{Errors: []Error{{Message: fmt.Sprintf("%s operations are not supported", op.Type)}}}
+	}
+
+	vars := map[string]interface{}{}
+	for _, def := range op.Variables {
+		if v, ok := req.Variables[def.Name]; ok {
+			vars[def.Name] = v
+		} else if def.Default != nil {
+			vars[def.Name] = def.Default
+		}
+	}
+
+	ex := &execution{schema: s, doc: doc, variables: vars}
+	if err := ex.checkLimits(op); err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	ctx = context.WithValue(ctx, memoKey, &memo{entries: map[string]memoEntry{}})
+	results := ex.executeSelections(ctx, s.Query, []interface{}{struct{}{}}, op.SelectionSet, nil)
+
+	resp := &Response{Errors: ex.errors}
+	if len(results) == 1 {
+		resp.Data = results[0]
+	}
+	return resp
+}
+
+type memoKeyType struct{}
+
+var memoKey memoKeyType
+
+type memoEntry struct {
+	value interface{}
+	err   error
+}
+
+// memo caches loader results for the lifetime of one request
+type memo struct {
+	mu      sync.Mutex
+	entries map[string]memoEntry
+}
+
+// Memo returns the cached result for (name, keys) within the current request,
+// calling load on first use. Sibling fields that need the same rows (e.g.
+// Stock.name and Stock.sector) therefore share a single query.
+func Memo(ctx context.Context, name string, keys []string, load func() (interface{}, error)) (interface{}, error) {
+	m, ok := ctx.Value(memoKey).(*memo)
+	if !ok {
+		return load()
+	}
+
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+	cacheKey := name + "|" + strings.Join(sorted, ",")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[cacheKey]; ok {
+		return e.value, e.err
+	}
+	v, err := load()
+	m.entries[cacheKey] = memoEntry{value: v, err: err}
+	return v, err
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// collectFields flattens fragments into an ordered list of fields
+func (ex *execution) collectFields(obj *Object, selections []Selection, out []*Field, visited map[string]bool) ([]*Field, error) {
+	for _, sel := range selections {
+		switch {
+		case sel.Field != nil:
+			out = append(out, sel.Field)
+		case sel.InlineFragment != nil:
+			if tc := sel.InlineFragment.TypeCondition; tc != "" && tc != obj.Name {
+				continue
+			}
+			var err error
+			if out, err = ex.collectFields(obj, sel.InlineFragment.SelectionSet, out, visited); err != nil {
+				return nil, err
+			}
+		default:
+			frag, ok := ex.doc.Fragments[sel.FragmentSpread]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", sel.FragmentSpread)
+			}
+			if visited[frag.Name] {
+				continue
+			}
+			visited[frag.Name] = true
+			if frag.TypeCondition != obj.Name {
+				continue
+			}
+			var err error
+			if out, err = ex.collectFields(obj, frag.SelectionSet, out, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// checkLimits refuses an operation nesting deeper than MaxDepth or costing
+// more than MaxComplexity, before anything is resolved
+func (ex *execution) checkLimits(op *Operation) error {
+	if ex.schema.MaxDepth <= 0 && ex.schema.MaxComplexity <= 0 {
+		return nil
+	}
+	cost, depth, err := ex.measure(ex.schema.Query, op.SelectionSet, 1)
+	if err != nil {
+		return err
+	}
+	if ex.schema.MaxDepth > 0 && depth > ex.schema.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, ex.schema.MaxDepth)
+	}
+	if ex.schema.MaxComplexity > 0 && cost > ex.schema.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", cost, ex.schema.MaxComplexity)
+	}
+	return nil
+}
+
+// measure returns the cost and depth of a selection set at the given
+// depth. A field costs one plus its sub-selection's cost, times its Size
+// for lists. Measuring stops once either limit is passed, so a hostile
+// query can't make it expensive.
+func (ex *execution) measure(obj *Object, selections []Selection, depth int) (int, int, error) {
+	if ex.schema.MaxDepth > 0 && depth > ex.schema.MaxDepth {
+		return 0, depth, nil
+	}
+	fields, err := ex.collectFields(obj, selections, nil, map[string]bool{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cost, deepest := 0, depth
+	for _, field := range fields {
+		def, ok := obj.Fields[field.Name]
+		if !ok || def.Type == nil {
+			cost++
+			continue
+		}
+		childCost, childDepth, err := ex.measure(def.Type, field.SelectionSet, depth+1)
+		if err != nil {
+			return 0, 0, err
+		}
+		if childDepth > deepest {
+			deepest = childDepth
+		}
+		size := 1
+		if def.Size != nil {
+			args, err := ex.resolveArguments(field.Arguments)
+			if err != nil {
+				return 0, 0, err
+			}
+			if size = def.Size(args); size < 1 {
+				size = 1
+			}
+		}
+		cost += size * (1 + childCost)
+		if ex.schema.MaxComplexity > 0 && cost > ex.schema.MaxComplexity {
+			return cost, deepest, nil
+		}
+	}
+	return cost, deepest, nil
+}
+
+// executeSelections resolves a selection set for every parent at once and
+// returns one result map per parent
+func (ex *execution) executeSelections(ctx context.Context, obj *Object, parents []interface{}, selections []Selection, path []interface{}) []map[string]interface{} {
+	results := make([]map[string]interface{}, len(parents))
+	for i := range results {
+		results[i] = map[string]interface{}{}
+	}
+
+	fields, err := ex.collectFields(obj, selections, nil, map[string]bool{})
+	if err != nil {
+		ex.errors = append(ex.errors, Error{Message: err.Error(), Path: path})
+		return results
+	}
+
+	for _, field := range fields {
+		key := field.ResponseKey()
+		fieldPath := append(append([]interface{}{}, path...), key)
+
+		if field.Name == "__typename" {
+			for i := range results {
+				results[i][key] = obj.Name
+			}
+			continue
+		}
+
+		def, ok := obj.Fields[field.Name]
+		if !ok {
+			ex.errors = append(ex.errors, Error{Message: fmt.Sprintf("cannot query field %q on type %q", field.Name, obj.Name), Path: fieldPath})
+			continue
+		}
+		if def.Type != nil && len(field.SelectionSet) == 0 {
+			ex.errors = append(ex.errors, Error{Message: fmt.Sprintf("field %q of type %q must have a selection of subfields", field.Name, def.Type.Name), Path: fieldPath})
+			continue
+		}
+
+		args, err := ex.resolveArguments(field.Arguments)
+		if err != nil {
+			ex.errors = append(ex.errors, Error{Message: err.Error(), Path: fieldPath})
+			continue
+		}
+
+		values, err := ex.resolveField(ctx, def, field.Name, parents, args)
+		if err != nil {
+			ex.errors = append(ex.errors, Error{Message: err.Error(), Path: fieldPath})
+			for i := range results {
+				results[i][key] = nil
+			}
+			continue
+		}
+
+		if def.Type == nil {
+			for i := range results {
+				results[i][key] = values[i]
+			}
+			continue
+		}
+
+		// Flatten every child object across all parents so the next level is
+		// resolved in a single batch
+		var children []interface{}
+		type span struct {
+			start, end int
+			list, null bool
+		}
+		spans := make([]span, len(values))
+		for i, v := range values {
+			spans[i].start = len(children)
+			if isNil(v) {
+				spans[i].null = true
+			} else if items, isList := asList(v); isList {
+				spans[i].list = true
+				children = append(children, items...)
+			} else {
+				children = append(children, v)
+			}
+			spans[i].end = len(children)
+		}
+
+		childResults := ex.executeSelections(ctx, def.Type, children, field.SelectionSet, fieldPath)
+		for i, sp := range spans {
+			switch {
+			case sp.null:
+				results[i][key] = nil
+			case sp.list:
+				list := make([]interface{}, 0, sp.end-sp.start)
+				for _, r := range childResults[sp.start:sp.end] {
+					list = append(list, r)
+				}
+				results[i][key] = list
+			default:
+				results[i][key] = childResults[sp.start]
+			}
+		}
+	}
+
+	return results
+}
+
+// resolveField returns one value per parent using Batch, Resolve or the default resolver
+func (ex *execution) resolveField(ctx context.Context, def *FieldDef, name string, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	if len(parents) == 0 {
+		return nil, nil
+	}
+	if def.Batch != nil {
+		values, err := def.Batch(ctx, parents, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(parents) {
+			return nil, fmt.Errorf("batch resolver for %q returned %d results for %d parents", name, len(values), len(parents))
+		}
+		return values, nil
+	}
+
+	values := make([]interface{}, len(parents))
+	for i, parent := range parents {
+		if def.Resolve != nil {
+			v, err := def.Resolve(ctx, parent, args)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+			continue
+		}
+		values[i] = defaultResolve(parent, name)
+	}
+	return values, nil
+}
+
+// resolveArguments substitutes variables into argument values
+func (ex *execution) resolveArguments(args map[string]Value) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(args))
+	for name, v := range args {
+		resolved, err := ex.resolveValue(v)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = resolved
+	}
+	return out, nil
+}
+
+func (ex *execution) resolveValue(v Value) (interface{}, error) {
+	switch val := v.(type) {
+	case Variable:
+		resolved, ok := ex.variables[string(val)]
+		if !ok {
+			return nil, nil
+		}
+		return resolved, nil
+	case EnumValue:
+		return string(val), nil
+	case []Value:
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			r, err := ex.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = r
+		}
+		return list, nil
+	case map[string]Value:
+		obj := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			r, err := ex.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = r
+		}
+		return obj, nil
+	}
+	return v, nil
+}
+
+// defaultResolve reads a field from a map or from a struct by its JSON tag
+func defaultResolve(parent interface{}, name string) interface{} {
+	if m, ok := parent.(map[string]interface{}); ok {
+		return m[name]
+	}
+
+	rv := reflect.ValueOf(parent)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == name || (tag == "" && strings.EqualFold(f.Name, name)) {
+			v := rv.Field(i).Interface()
+			// Keep custom JSON encodings (e.g. NullRawMessage) intact
+			if m, ok := v.(json.Marshaler); ok {
+				return m
+			}
+			return v
+		}
+	}
+	return nil
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// asList expands any slice into []interface{}
+func asList(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// Args helpers convert decoded argument values to Go types
+
+// StringArg returns a string argument or the fallback
+func StringArg(args map[string]interface{}, name, fallback string) string {
+	if s, ok := args[name].(string); ok {
+		return s
+	}
+	return fallback
+}
+
+// IntArg returns an integer argument (literal or JSON number) or the fallback
+func IntArg(args map[string]interface{}, name string, fallback int) int {
+	switch v := args[name].(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return fallback
+}
+
+// FloatArg returns a float argument or the fallback
+func FloatArg(args map[string]interface{}, name string, fallback float64) float64 {
+	switch v := args[name].(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return fallback
+}
+
+// BoolArg returns a boolean argument or the fallback
+func BoolArg(args map[string]interface{}, name string, fallback bool) bool {
+	if b, ok := args[name].(bool); ok {
+		return b
+	}
+	return fallback
+}
+
+// StringListArg returns a list-of-strings argument, accepting a single string too
+func StringListArg(args map[string]interface{}, name string) []string {
+	switch v := args[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// testSchema is items(limit) → Item { id label owner { name items(limit) } },
+// counting the owner batch calls
+func testSchema(ownerBatches *int) *Schema {
+	item := &Object{Name: "Item", Fields: map[string]*FieldDef{}}
+	owner := &Object{Name: "Owner", Fields: map[string]*FieldDef{}}
+
+	items := &FieldDef{
+		Type: item,
+		Size: limitSize(3),
+		Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+			n := IntArg(args, "limit", 3)
+			out := make([]testItem, n)
+			for i := range out {
+				out[i] = testItem{ID: string(rune('a' + i)), Label: StringArg(args, "prefix", "item") + "-" + string(rune('a'+i))}
+			}
+			return out, nil
+		},
+	}
+	scalars(item.Fields, "id", "label")
+	item.Fields["owner"] = &FieldDef{
+		Type: owner,
+		Batch: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			*ownerBatches++
+			out := make([]interface{}, len(parents))
+			for i, p := range parents {
+				out[i] = map[string]interface{}{"name": "owner of " + p.(testItem).ID}
+			}
+			return out, nil
+		},
+	}
+	item.Fields["broken"] = &FieldDef{
+		Resolve: func(ctx context.Context, parent interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	owner.Fields["name"] = &FieldDef{}
+	owner.Fields["items"] = items
+
+	query := &Object{Name: "Query", Fields: map[string]*FieldDef{"items": items}}
+	return &Schema{Query: query}
+}
+
+func TestExecuteBatchesNestedFields(t *testing.T) {
+	batches := 0
+	resp := testSchema(&batches).Execute(context.Background(), Request{
+		Query: `{ items(limit: 3) { id owner { name } } }`,
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	if batches != 1 {
+		t.Errorf("owner resolved in %d batches, want 1", batches)
+	}
+	items := resp.Data["items"].([]interface{})
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+	want := map[string]interface{}{"id": "b", "owner": map[string]interface{}{"name": "owner of b"}}
+	if !reflect.DeepEqual(items[1], want) {
+		t.Errorf("items[1] = %#v, want %#v", items[1], want)
+	}
+}
+
+func TestExecuteAliasesVariablesAndFragments(t *testing.T) {
+	batches := 0
+	resp := testSchema(&batches).Execute(context.Background(), Request{
+		Query: `
+			query Q($n: Int, $p: String = "dflt") {
+				first: items(limit: $n) { ...F }
+				second: items(limit: 1, prefix: $p) { __typename ... on Item { label } }
+			}
+			fragment F on Item { id }`,
+		Variables: map[string]interface{}{"n": 2.0},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	first := resp.Data["first"].([]interface{})
+	if len(first) != 2 || !reflect.DeepEqual(first[0], map[string]interface{}{"id": "a"}) {
+		t.Errorf("first = %#v", first)
+	}
+	second := resp.Data["second"].([]interface{})
+	want := map[string]interface{}{"__typename": "Item", "label": "dflt-a"}
+	if !reflect.DeepEqual(second[0], want) {
+		t.Errorf("second[0] = %#v, want %#v", second[0], want)
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	batches := 0
+	resp := testSchema(&batches).Execute(context.Background(), Request{
+		Query: `{ items(limit: 1) { id broken missing owner } }`,
+	})
+	if resp.Data == nil {
+		t.Fatal("data is nil; field errors should leave the rest of the result")
+	}
+	item := resp.Data["items"].([]interface{})[0].(map[string]interface{})
+	if item["id"] != "a" || item["broken"] != nil {
+		t.Errorf("item = %#v", item)
+	}
+
+	var messages []string
+	for _, e := range resp.Errors {
+		messages = append(messages, e.Message)
+	}
+	got := strings.Join(messages, "; ")
+	for _, want := range []string{"boom", `cannot query field "missing"`, `must have a selection of subfields`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("errors %q missing %q", got, want)
+		}
+	}
+}
+
+func TestExecuteRequestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   Request
+		error string
+	}{
+		{"syntax", Request{Query: `{ items(`}, "syntax error"},
+		{"mutation", Request{Query: `mutation { items { id } }`}, "mutation operations are not supported"},
+		{"ambiguous operation", Request{Query: `query A { items { id } } query B { items { id } }`}, "operationName is required"},
+		{"unknown operation", Request{Query: `query A { items { id } }`, OperationName: "B"}, `unknown operation "B"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := 0
+			resp := testSchema(&batches).Execute(context.Background(), tt.req)
+			if resp.Data != nil {
+				t.Errorf("data = %#v, want nil", resp.Data)
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.error) {
+				t.Errorf("errors = %+v, want one containing %q", resp.Errors, tt.error)
+			}
+		})
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		error string
+	}{
+		{"within limits", `{ items(limit: 2) { id owner { name } } }`, ""},
+		{"too deep", `{ items { owner { items { owner { items { id } } } } } }`, "query depth 5 exceeds the limit of 4"},
+		{"too complex", `{ items(limit: 500) { owner { items(limit: 500) { id } } } }`, "exceeds the limit of 1000"},
+		{"complexity through variables", `query($n: Int) { items(limit: $n) { id label } }`, "query complexity 1500 exceeds the limit of 1000"},
+		{
+			"cyclic fragments stop at the depth limit",
+			`{ items { ...F } } fragment F on Item { owner { items { ...F } } }`,
+			"exceeds the limit of 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := 0
+			schema := testSchema(&batches)
+			schema.MaxDepth = 4
+			schema.MaxComplexity = 1000
+			resp := schema.Execute(context.Background(), Request{
+				Query:     tt.query,
+				Variables: map[string]interface{}{"n": 500.0},
+			})
+			if tt.error == "" {
+				if len(resp.Errors) > 0 {
+					t.Fatalf("errors: %+v", resp.Errors)
+				}
+				return
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.error) {
+				t.Errorf("errors = %+v, want one containing %q", resp.Errors, tt.error)
+			}
+			if batches != 0 {
+				t.Errorf("resolved %d batches of a refused query", batches)
+			}
+		})
+	}
+}
+
+func TestMemoSharesLoads(t *testing.T) {
+	ctx := context.WithValue(context.Background(), memoKey, &memo{entries: map[string]memoEntry{}})
+
+	loads := 0
+	load := func() (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+	a, _ := Memo(ctx, "rows", []string{"B", "A"}, load)
+	b, _ := Memo(ctx, "rows", []string{"A", "B"}, load)
+	c, _ := Memo(ctx, "other", []string{"A", "B"}, load)
+	if a != 1 || b != 1 || c != 2 {
+		t.Errorf("Memo results = %v, %v, %v; want 1, 1, 2", a, b, c)
+	}
+}
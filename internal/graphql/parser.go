@@ -0,0 +1,545 @@
+// Package graphql implements the subset of GraphQL used by the dashboard:
+// queries with arguments, variables, aliases and fragments. Mutations,
+// subscriptions and introspection are not supported.
+//
+// It is a small engine rather than gqlgen on purpose. The graph is a
+// read-only view over a handful of existing database methods; gqlgen would
+// add a code generation step, generated resolver files to keep in sync with
+// the database package and a dataloader dependency, for features the
+// dashboard doesn't use. Here batching is built into execution instead:
+// each level of the query is resolved for all parents at once.
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed GraphQL request document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a single query operation
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []VariableDefinition
+	SelectionSet []Selection
+}
+
+// VariableDefinition declares an operation variable and its default
+type VariableDefinition struct {
+	Name    string
+	Default Value
+}
+
+// Fragment is a named, reusable selection set
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Selection is a field, fragment spread or inline fragment
+type Selection struct {
+	Field          *Field
+	FragmentSpread string
+	InlineFragment *Fragment
+}
+
+// Field is a selected field with its arguments and sub-selections
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]Value
+	SelectionSet []Selection
+}
+
+// ResponseKey is the alias if present, otherwise the field name
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Value is an unresolved argument value literal
+type Value interface{}
+
+// Variable references an operation variable inside a Value
+type Variable string
+
+// EnumValue is a bare enum literal
+type EnumValue string
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// lex tokenises a GraphQL document, discarding whitespace, commas and comments
+func lex(src string) ([]token, error) {
+	src = strings.TrimPrefix(src, "\ufeff")
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("{}()[]:!$@=|&", c) >= 0:
+			tokens = append(tokens, token{tokPunct, string(c), i})
+			i++
+		case c == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return nil, fmt.Errorf("unexpected '.' at %d", i)
+			}
+			tokens = append(tokens, token{tokPunct, "...", i})
+			i += 3
+		case c == '"':
+			if strings.HasPrefix(src[i:], `"""`) {
+				end := strings.Index(src[i+3:], `"""`)
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated block string at %d", i)
+				}
+				tokens = append(tokens, token{tokString, src[i+3 : i+3+end], i})
+				i += end + 6
+				continue
+			}
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %v", i, err)
+			}
+			tokens = append(tokens, token{tokString, s, i})
+			i = j + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			kind := tokInt
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.' || src[j] == 'e' || src[j] == 'E' || src[j] == '+' || src[j] == '-') {
+				if src[j] == '.' || src[j] == 'e' || src[j] == 'E' {
+					kind = tokFloat
+				}
+				j++
+			}
+			tokens = append(tokens, token{kind, src[i:j], i})
+			i = j
+		case isNameStart(c):
+			j := i + 1
+			for j < len(src) && (isNameStart(src[j]) || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{tokName, src[i:j], i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// isNameStart reports whether c can begin a GraphQL name (ASCII only per spec)
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a GraphQL document
+func Parse(src string) (*Document, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	doc := &Document{Fragments: map[string]*Fragment{}}
+
+	for p.peek().kind != tokEOF {
+		switch {
+		case p.peekPunct("{"):
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: sel})
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekName("fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			return nil, p.errorf("expected operation or fragment")
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("document contains no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) peekPunct(v string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.value == v
+}
+
+func (p *parser) peekName(v string) bool {
+	t := p.peek()
+	return t.kind == tokName && t.value == v
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expectPunct(v string) error {
+	if !p.peekPunct(v) {
+		return p.errorf("expected %q", v)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokName {
+		return "", p.errorf("expected name")
+	}
+	p.next()
+	return t.value, nil
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: p.next().value}
+	if p.peek().kind == tokName {
+		op.Name = p.next().value
+	}
+
+	if p.peekPunct("(") {
+		p.next()
+		for !p.peekPunct(")") {
+			if err := p.expectPunct("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			if err := p.skipType(); err != nil {
+				return nil, err
+			}
+			def := VariableDefinition{Name: name}
+			if p.peekPunct("=") {
+				p.next()
+				if def.Default, err = p.value(true); err != nil {
+					return nil, err
+				}
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		p.next()
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.SelectionSet = sel
+	return op, nil
+}
+
+// skipType consumes a type reference; variable types are not validated
+func (p *parser) skipType() error {
+	if p.peekPunct("[") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peekPunct("!") {
+		p.next()
+	}
+	return nil
+}
+
+// skipDirectives consumes directives, which are accepted but ignored
+func (p *parser) skipDirectives() error {
+	for p.peekPunct("@") {
+		p.next()
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.peekPunct("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	p.next()
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekName("on") {
+		return nil, p.errorf("expected 'on'")
+	}
+	p.next()
+	typeCond, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &Fragment{Name: name, TypeCondition: typeCond, SelectionSet: sel}, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peekPunct("}") {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf("unterminated selection set")
+		}
+
+		if p.peekPunct("...") {
+			p.next()
+			if p.peekName("on") || p.peekPunct("{") || p.peekPunct("@") {
+				frag := &Fragment{}
+				if p.peekName("on") {
+					p.next()
+					typeCond, err := p.name()
+					if err != nil {
+						return nil, err
+					}
+					frag.TypeCondition = typeCond
+				}
+				if err := p.skipDirectives(); err != nil {
+					return nil, err
+				}
+				sel, err := p.selectionSet()
+				if err != nil {
+					return nil, err
+				}
+				frag.SelectionSet = sel
+				selections = append(selections, Selection{InlineFragment: frag})
+				continue
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.skipDirectives(); err != nil {
+				return nil, err
+			}
+			selections = append(selections, Selection{FragmentSpread: name})
+			continue
+		}
+
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, Selection{Field: field})
+	}
+	p.next()
+	return selections, nil
+}
+
+func (p *parser) field() (*Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	f := &Field{Name: name, Arguments: map[string]Value{}}
+	if p.peekPunct(":") {
+		p.next()
+		f.Alias = name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekPunct("(") {
+		if f.Arguments, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peekPunct("{") {
+		if f.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) arguments() (map[string]Value, error) {
+	p.next()
+	args := map[string]Value{}
+	for !p.peekPunct(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		args[name] = v
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *parser) value(constant bool) (Value, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokInt:
+		p.next()
+		n, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid int %s", t.value)
+		}
+		return n, nil
+	case tokFloat:
+		p.next()
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %s", t.value)
+		}
+		return f, nil
+	case tokString:
+		p.next()
+		return t.value, nil
+	case tokName:
+		p.next()
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return EnumValue(t.value), nil
+	case tokPunct:
+		switch t.value {
+		case "$":
+			if constant {
+				return nil, p.errorf("variables are not allowed here")
+			}
+			p.next()
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			return Variable(name), nil
+		case "[":
+			p.next()
+			list := []Value{}
+			for !p.peekPunct("]") {
+				v, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			p.next()
+			obj := map[string]Value{}
+			for !p.peekPunct("}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				obj[name] = v
+			}
+			p.next()
+			return obj, nil
+		}
+	}
+	return nil, p.errorf("expected value")
+}
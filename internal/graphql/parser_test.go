@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShorthandQuery(t *testing.T) {
+	doc, err := Parse(`{ signals(limit: 5, status: "ACTIVE") { symbol } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "" {
+		t.Errorf("operation = %s %q, want anonymous query", op.Type, op.Name)
+	}
+	f := op.SelectionSet[0].Field
+	if f.Name != "signals" {
+		t.Fatalf("field = %q, want signals", f.Name)
+	}
+	want := map[string]Value{"limit": int64(5), "status": "ACTIVE"}
+	if !reflect.DeepEqual(f.Arguments, want) {
+		t.Errorf("arguments = %#v, want %#v", f.Arguments, want)
+	}
+	if got := f.SelectionSet[0].Field.Name; got != "symbol" {
+		t.Errorf("sub-field = %q, want symbol", got)
+	}
+}
+
+func TestParseNamedOperationWithVariables(t *testing.T) {
+	doc, err := Parse(`
+		# dashboard page
+		query Page($limit: Int = 10, $symbols: [String!]!) @cached {
+			top: signals(limit: $limit) { symbol }
+			stocks(symbols: $symbols) { symbol }
+		}`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	op := doc.Operations[0]
+	if op.Name != "Page" {
+		t.Errorf("name = %q, want Page", op.Name)
+	}
+	wantVars := []VariableDefinition{{Name: "limit", Default: int64(10)}, {Name: "symbols"}}
+	if !reflect.DeepEqual(op.Variables, wantVars) {
+		t.Errorf("variables = %#v, want %#v", op.Variables, wantVars)
+	}
+	top := op.SelectionSet[0].Field
+	if top.Alias != "top" || top.Name != "signals" || top.ResponseKey() != "top" {
+		t.Errorf("aliased field = %+v", top)
+	}
+	if v := top.Arguments["limit"]; v != Variable("limit") {
+		t.Errorf("limit argument = %#v, want variable", v)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	doc, err := Parse(`{ f(i: -3, fl: 1.5e2, s: "a\"b", b: true, n: null, e: ACTIVE, l: [1, "x"], o: {k: false}, bs: """raw "q" text""") }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]Value{
+		"i":  int64(-3),
+		"fl": 150.0,
+		"s":  `a"b`,
+		"b":  true,
+		"n":  nil,
+		"e":  EnumValue("ACTIVE"),
+		"l":  []Value{int64(1), "x"},
+		"o":  map[string]Value{"k": false},
+		"bs": `raw "q" text`,
+	}
+	if got := doc.Operations[0].SelectionSet[0].Field.Arguments; !reflect.DeepEqual(got, want) {
+		t.Errorf("arguments = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseFragments(t *testing.T) {
+	doc, err := Parse(`
+		{ signals { ...SignalFields ... on Signal { status } ... @skip(if: false) { symbol } } }
+		fragment SignalFields on Signal { signal_id }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	frag, ok := doc.Fragments["SignalFields"]
+	if !ok || frag.TypeCondition != "Signal" {
+		t.Fatalf("fragment = %+v", frag)
+	}
+	sels := doc.Operations[0].SelectionSet[0].Field.SelectionSet
+	if len(sels) != 3 {
+		t.Fatalf("got %d selections, want 3", len(sels))
+	}
+	if sels[0].FragmentSpread != "SignalFields" {
+		t.Errorf("spread = %q", sels[0].FragmentSpread)
+	}
+	if sels[1].InlineFragment == nil || sels[1].InlineFragment.TypeCondition != "Signal" {
+		t.Errorf("typed inline fragment = %+v", sels[1].InlineFragment)
+	}
+	if sels[2].InlineFragment == nil || sels[2].InlineFragment.TypeCondition != "" {
+		t.Errorf("untyped inline fragment = %+v", sels[2].InlineFragment)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		error string
+	}{
+		{"empty document", ``, "document contains no operations"},
+		{"only a fragment", `fragment F on Signal { symbol }`, "document contains no operations"},
+		{"stray token", `signals { symbol }`, "expected operation or fragment"},
+		{"unterminated selection", `{ signals { symbol }`, "unterminated selection set"},
+		{"single dot", `{ signals { .symbol } }`, "unexpected '.'"},
+		{"unterminated string", `{ f(s: "abc) }`, "unterminated string"},
+		{"unterminated block string", `{ f(s: """abc) }`, "unterminated block string"},
+		{"invalid escape", `{ f(s: "\q") }`, "invalid string"},
+		{"unexpected character", `{ f ^ }`, "unexpected character"},
+		{"invalid int", `{ f(i: 1-2) }`, "invalid int"},
+		{"invalid float", `{ f(x: 1.2.3) }`, "invalid float"},
+		{"missing argument colon", `{ f(limit 5) }`, `expected ":"`},
+		{"missing argument value", `{ f(limit: ) }`, "expected value"},
+		{"unterminated arguments", `{ f(limit: 5`, "expected name"},
+		{"unterminated list", `{ f(l: [1, 2`, "expected value"},
+		{"missing alias target", `{ a: { x } }`, "expected name"},
+		{"variable without $", `query Q(limit: Int) { f }`, `expected "$"`},
+		{"variable without type", `query Q($limit) { f }`, `expected ":"`},
+		{"unterminated list type", `query Q($s: [String) { f }`, `expected "]"`},
+		{"variable in default", `query Q($a: Int = $b) { f }`, "variables are not allowed here"},
+		{"fragment without on", `{ f } fragment F Signal { symbol }`, "expected 'on'"},
+		{"fragment without name", `{ f } fragment on Signal { symbol }`, "expected 'on'"},
+		{"spread without name", `{ f { ... } }`, "expected name"},
+		{"operation without selection", `query Q`, `expected "{"`},
+		{"directive without name", `{ f @ }`, "expected name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.src)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, want error containing %q", tt.src, doc, tt.error)
+			}
+			if !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.src, err, tt.error)
+			}
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := Parse(`{ signals(limit: ) }`)
+	if err == nil || !strings.HasPrefix(err.Error(), "syntax error at 17:") {
+		t.Errorf("error = %v, want it located at offset 17", err)
+	}
+}
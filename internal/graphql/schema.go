@@ -0,0 +1,335 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Query limits. A dashboard page costs a few thousand at most; nesting lists
+// under lists (signals → stock → news → stocks → …) is what they stop.
+const (
+	maxDepth      = 6
+	maxComplexity = 20000
+)
+
+// Typical lengths of list fields without a limit argument
+const (
+	articleStocksSize = 5
+	portfoliosSize    = 10
+	holdingsSize      = 50
+)
+
+type contextKey int
+
+const accountIDKey contextKey = iota
+
+// WithAccountID scopes portfolio queries to the requesting account
+func WithAccountID(ctx context.Context, accountID string) context.Context {
+	return context.WithValue(ctx, accountIDKey, accountID)
+}
+
+func accountIDFrom(ctx context.Context) string {
+	if id, ok := ctx.Value(accountIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// stockRef is the parent value for Stock; everything else is batch-loaded by symbol
+type stockRef struct {
+	Symbol string `json:"symbol"`
+}
+
+// scalars declares fields resolved directly from the parent value
+func scalars(fields map[string]*FieldDef, names ...string) map[string]*FieldDef {
+	for _, name := range names {
+		fields[name] = &FieldDef{}
+	}
+	return fields
+}
+
+// parentSymbols extracts the distinct symbols of a batch of stock parents
+func parentSymbols(parents []interface{}) []string {
+	seen := map[string]bool{}
+	symbols := []string{}
+	for _, p := range parents {
+		s := p.(stockRef).Symbol
+		if !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	return symbols
+}
+
+// NewSchema builds the query schema over signals, stocks, news and portfolios
+func NewSchema(db *database.DB) *Schema {
+	price := &Object{Name: "Price", Fields: scalars(map[string]*FieldDef{},
+		"symbol", "last_price", "volume", "open", "high", "low", "close", "change_percent", "updated_at")}
+
+	stock := &Object{Name: "Stock", Fields: map[string]*FieldDef{}}
+	signal := &Object{Name: "Signal", Fields: map[string]*FieldDef{}}
+	article := &Object{Name: "Article", Fields: map[string]*FieldDef{}}
+	holding := &Object{Name: "Holding", Fields: map[string]*FieldDef{}}
+	portfolio := &Object{Name: "Portfolio", Fields: map[string]*FieldDef{}}
+
+	toStock := &FieldDef{
+		Type: stock,
+		Resolve: func(ctx context.Context, parent interface{}, args map[string]interface{}) (interface{}, error) {
+			return stockRef{Symbol: defaultResolve(parent, "symbol").(string)}, nil
+		},
+	}
+
+	// Stock config fields share one lookup per batch
+	configField := func(get func(database.StockConfig) interface{}) *FieldDef {
+		return &FieldDef{
+			Batch: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+				symbols := parentSymbols(parents)
+				loaded, err := Memo(ctx, "stock_config", symbols, func() (interface{}, error) {
+					return db.GetStockConfigsBySymbols(ctx, symbols)
+				})
+				if err != nil {
+					return nil, err
+				}
+				configs := loaded.(map[string]database.StockConfig)
+				out := make([]interface{}, len(parents))
+				for i, p := range parents {
+					if cfg, ok := configs[p.(stockRef).Symbol]; ok {
+						out[i] = get(cfg)
+					}
+				}
+				return out, nil
+			},
+		}
+	}
+
+	stock.Fields["symbol"] = &FieldDef{}
+	stock.Fields["name"] = configField(func(c database.StockConfig) interface{} { return c.Name })
+	stock.Fields["sector"] = configField(func(c database.StockConfig) interface{} { return c.Sector })
+	stock.Fields["exchange"] = configField(func(c database.StockConfig) interface{} { return c.Exchange })
+	stock.Fields["market_cap_category"] = configField(func(c database.StockConfig) interface{} { return c.MarketCapCat })
+	stock.Fields["price"] = &FieldDef{
+		Type: price,
+		Batch: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			prices, err := db.GetRealtimePricesBySymbols(ctx, parentSymbols(parents))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]interface{}, len(parents))
+			for i, p := range parents {
+				if q, ok := prices[p.(stockRef).Symbol]; ok {
+					out[i] = q
+				}
+			}
+			return out, nil
+		},
+	}
+	stock.Fields["news"] = &FieldDef{
+		Type: article,
+		Size: limitSize(5),
+		Batch: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			news, err := db.GetNewsBySymbols(ctx, parentSymbols(parents), clampLimit(IntArg(args, "limit", 5)))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]interface{}, len(parents))
+			for i, p := range parents {
+				articles := news[p.(stockRef).Symbol]
+				if articles == nil {
+					articles = []database.NewsArticle{}
+				}
+				out[i] = articles
+			}
+			return out, nil
+		},
+	}
+	stock.Fields["signals"] = &FieldDef{
+		Type: signal,
+		Size: limitSize(5),
+		Batch: func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			signals, err := db.GetSignalsBySymbols(ctx, parentSymbols(parents), clampLimit(IntArg(args, "limit", 5)), strings.ToUpper(StringArg(args, "status", "")))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]interface{}, len(parents))
+			for i, p := range parents {
+				list := signals[p.(stockRef).Symbol]
+				if list == nil {
+					list = []database.Signal{}
+				}
+				out[i] = list
+			}
+			return out, nil
+		},
+	}
+
+	scalars(signal.Fields,
+		"signal_id", "symbol", "signal_type", "confidence_score", "entry_price", "current_price",
+		"stop_loss", "target_price", "status", "generated_at", "exit_price", "closed_at",
+		"actual_profit_pct", "prediction_features", "recent_news_sentiment", "metadata",
+		"exit_reason", "sector", "stock_name", "time_in_force", "expires_at", "scaling_rules")
+	signal.Fields["stock"] = toStock
+
+	scalars(article.Fields,
+		"id", "title", "source", "time", "url", "summary", "sentiment", "sentimentLabel",
+		"impact", "impactScore", "category", "affectedStocks", "priceMovement", "confidence",
+		"clusterId", "clusterSize")
+	article.Fields["stocks"] = &FieldDef{
+		Type: stock,
+		Size: fixedSize(articleStocksSize),
+		Resolve: func(ctx context.Context, parent interface{}, args map[string]interface{}) (interface{}, error) {
+			a := parent.(database.NewsArticle)
+			refs := make([]interface{}, len(a.AffectedStocks))
+			for i, s := range a.AffectedStocks {
+				refs[i] = stockRef{Symbol: s}
+			}
+			return refs, nil
+		},
+	}
+
+	scalars(holding.Fields,
+		"symbol", "exchange", "quantity", "avg_cost", "invested_value", "last_price", "market_value",
+		"unrealized_pnl", "unrealized_pnl_pct", "realized_pnl", "dividend_income")
+	holding.Fields["stock"] = toStock
+
+	scalars(portfolio.Fields, "id", "name", "description", "base_currency", "created_at", "updated_at")
+	portfolio.Fields["holdings"] = &FieldDef{
+		Type: holding,
+		Size: fixedSize(holdingsSize),
+		Resolve: func(ctx context.Context, parent interface{}, args map[string]interface{}) (interface{}, error) {
+			summary, err := db.GetPortfolioHoldings(ctx, parent.(database.Portfolio).ID)
+			if err != nil {
+				return nil, err
+			}
+			open := []database.Holding{}
+			for _, h := range summary.Holdings {
+				if h.Quantity > 0 {
+					open = append(open, h)
+				}
+			}
+			return open, nil
+		},
+	}
+
+	query := &Object{Name: "Query", Fields: map[string]*FieldDef{
+		"signals": {
+			Type: signal,
+			Size: limitSize(50),
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				signals, err := db.GetAllSignals(ctx, clampLimit(IntArg(args, "limit", 50)), strings.ToUpper(StringArg(args, "status", "")))
+				if signals == nil && err == nil {
+					signals = []database.Signal{}
+				}
+				return signals, err
+			},
+		},
+		"signal": {
+			Type: signal,
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				id := StringArg(args, "id", "")
+				if id == "" {
+					return nil, fmt.Errorf("argument id is required")
+				}
+				s, err := db.GetSignalByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				if s == nil {
+					return nil, nil
+				}
+				return *s, nil
+			},
+		},
+		"stock": {
+			Type: stock,
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				symbol := strings.ToUpper(StringArg(args, "symbol", ""))
+				if symbol == "" {
+					return nil, fmt.Errorf("argument symbol is required")
+				}
+				return stockRef{Symbol: symbol}, nil
+			},
+		},
+		"stocks": {
+			Type: stock,
+			Size: func(args map[string]interface{}) int { return len(StringListArg(args, "symbols")) },
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				symbols := StringListArg(args, "symbols")
+				refs := make([]interface{}, len(symbols))
+				for i, s := range symbols {
+					refs[i] = stockRef{Symbol: strings.ToUpper(s)}
+				}
+				return refs, nil
+			},
+		},
+		"news": {
+			Type: article,
+			Size: limitSize(20),
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				resp, err := db.GetNews(ctx, clampLimit(IntArg(args, "limit", 20)), IntArg(args, "offset", 0),
+					StringArg(args, "sentiment", ""), StringArg(args, "search", ""), strings.ToUpper(StringArg(args, "symbol", "")),
+					BoolArg(args, "collapse", true))
+				if err != nil {
+					return nil, err
+				}
+				return resp.Articles, nil
+			},
+		},
+		"portfolios": {
+			Type: portfolio,
+			Size: fixedSize(portfoliosSize),
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				return db.ListPortfolios(ctx, accountIDFrom(ctx))
+			},
+		},
+		"portfolio": {
+			Type: portfolio,
+			Resolve: func(ctx context.Context, _ interface{}, args map[string]interface{}) (interface{}, error) {
+				var id int64
+				switch v := args["id"].(type) {
+				case string:
+					id, _ = strconv.ParseInt(v, 10, 64)
+				default:
+					id = int64(IntArg(args, "id", 0))
+				}
+				if id <= 0 {
+					return nil, fmt.Errorf("argument id is required")
+				}
+				p, err := db.GetPortfolio(ctx, accountIDFrom(ctx), id)
+				if err != nil || p == nil {
+					return nil, err
+				}
+				return *p, nil
+			},
+		},
+	}}
+
+	return &Schema{Query: query, MaxDepth: maxDepth, MaxComplexity: maxComplexity}
+}
+
+// limitSize sizes a list field by its limit argument, as the resolver clamps it
+func limitSize(fallback int) func(map[string]interface{}) int {
+	return func(args map[string]interface{}) int {
+		return clampLimit(IntArg(args, "limit", fallback))
+	}
+}
+
+// fixedSize sizes a list field that takes no limit by a typical length
+func fixedSize(n int) func(map[string]interface{}) int {
+	return func(map[string]interface{}) int { return n }
+}
+
+// clampLimit bounds list sizes requested through the graph
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > 500 {
+		return 500
+	}
+	return limit
+}
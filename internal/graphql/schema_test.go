@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaLimits(t *testing.T) {
+	schema := NewSchema(nil)
+	tests := []struct {
+		name  string
+		query string
+		error string
+	}{
+		{
+			"dashboard page",
+			`{
+				signals(limit: 50) { signal_id symbol status stock { name sector price { last_price change_percent } } }
+				news(limit: 20) { title sentiment stocks { symbol price { last_price } } }
+				portfolios { name holdings { symbol quantity market_value } }
+			}`,
+			"",
+		},
+		{
+			"lists nested under lists",
+			`{ signals(limit: 500) { stock { news(limit: 500) { stocks { signals(limit: 500) { symbol } } } } } }`,
+			"query complexity",
+		},
+		{
+			"recursive nesting",
+			`{ signals { stock { signals { stock { signals { stock { symbol } } } } } } }`,
+			"query depth 7 exceeds the limit of 6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			ex := &execution{schema: schema, doc: doc, variables: map[string]interface{}{}}
+			err = ex.checkLimits(doc.Operations[0])
+			switch {
+			case tt.error == "" && err != nil:
+				t.Errorf("checkLimits = %v, want nil", err)
+			case tt.error != "" && (err == nil || !strings.Contains(err.Error(), tt.error)):
+				t.Errorf("checkLimits = %v, want error containing %q", err, tt.error)
+			}
+		})
+	}
+}
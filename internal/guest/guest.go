@@ -0,0 +1,99 @@
+// Package guest issues time-limited read-only tokens for demoing the
+// dashboard without a login. A token carries a random guest ID and its
+// expiry signed with GUEST_TOKEN_SECRET, so it can't be forged or extended.
+// What a guest may read is decided by the API, not the token: market data
+// and signal stats, never anything belonging to an account.
+package guest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries a guest token; the token may also be passed as
+// ?guest_token=, for WebSocket connections and demo links
+const (
+	Header     = "X-Guest-Token"
+	QueryParam = "guest_token"
+)
+
+// idPattern matches the guest IDs NewID generates
+var idPattern = regexp.MustCompile(`^guest_[0-9a-f]{16}$`)
+
+// Issuer signs and verifies guest tokens
+type Issuer struct {
+	secret []byte
+}
+
+// IssuerFromEnv reads GUEST_TOKEN_SECRET. Without one a random secret is
+// generated, so tokens don't survive a restart.
+func IssuerFromEnv() *Issuer {
+	secret := os.Getenv("GUEST_TOKEN_SECRET")
+	if secret == "" {
+		log.Printf("⚠️  GUEST_TOKEN_SECRET not set, guest tokens expire on restart")
+		buf := make([]byte, 32)
+		rand.Read(buf)
+		secret = string(buf)
+	}
+	return &Issuer{secret: []byte(secret)}
+}
+
+// NewID returns a random guest ID
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "guest_" + hex.EncodeToString(b)
+}
+
+// Token returns a token for guest id until expires
+func (i *Issuer) Token(id string, expires time.Time) (string, error) {
+	if !idPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid guest ID %q", id)
+	}
+	payload := fmt.Sprintf("%s.%d", id, expires.Unix())
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(i.sign(payload)), nil
+}
+
+// Verify checks a token, returning the guest ID and expiry if it's
+// authentic and not expired
+func (i *Issuer) Verify(token string, now time.Time) (string, time.Time, bool) {
+	enc := base64.RawURLEncoding
+	encPayload, encSig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, i.sign(string(payload))) {
+		return "", time.Time{}, false
+	}
+	id, expPart, ok := strings.Cut(string(payload), ".")
+	if !ok || !idPattern.MatchString(id) {
+		return "", time.Time{}, false
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", time.Time{}, false
+	}
+	return id, time.Unix(exp, 0), true
+}
+
+func (i *Issuer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte("guest:" + payload))
+	return mac.Sum(nil)
+}
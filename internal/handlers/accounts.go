@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// AccountHeader picks which of the caller's accounts a request acts in;
+// without it the caller's personal account is used
+const AccountHeader = "X-Account-ID"
+
+// accountKey holds the request's account once its membership is checked
+const accountKey = "account"
+
+// membershipTTL is how long a resolved membership is trusted before it's
+// read again. Changes made through this instance apply immediately.
+const membershipTTL = 30 * time.Second
+
+// maxCachedMemberships is how many memberships are cached before expired
+// ones are swept
+const maxCachedMemberships = 10000
+
+// requestAccountID returns the account the request acts in. Outside
+// AccountScope it's the caller's personal account.
+func requestAccountID(c *gin.Context) string {
+	if accountID := c.GetString(accountKey); accountID != "" {
+		return accountID
+	}
+	return requestUserID(c)
+}
+
+type cachedMembership struct {
+	membership *database.AccountMembership
+	expires    time.Time
+}
+
+// AccountScope resolves the account a request acts in and checks the
+// caller is an active member of it. Personal accounts are created on a
+// user's first request.
+type AccountScope struct {
+	db database.AccountRepository
+
+	mu    sync.Mutex
+	cache map[[2]string]cachedMembership
+}
+
+// NewAccountScope creates an account scope
+func NewAccountScope(db database.AccountRepository) *AccountScope {
+	return &AccountScope{db: db, cache: map[[2]string]cachedMembership{}}
+}
+
+// Require guards endpoints serving account-owned data
+func (s *AccountScope) Require() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.resolve(c) {
+			c.Next()
+		}
+	}
+}
+
+// resolve sets the request's account, writing the error response itself
+// and returning false when the caller can't act in it
+func (s *AccountScope) resolve(c *gin.Context) bool {
+	if c.GetString(accountKey) != "" {
+		return true
+	}
+	// A guest acts in an account of its own that owns nothing, so it's
+	// never looked up or created
+	if id := c.GetString(guestKey); id != "" {
+		c.Set(accountKey, id)
+		return true
+	}
+	userID := requestUserID(c)
+	accountID := strings.TrimSpace(c.GetHeader(AccountHeader))
+	if accountID == "" {
+		accountID = userID
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	m, err := s.membership(ctx, accountID, userID)
+	if err != nil {
+		log.Printf("❌ Failed to resolve account %s for %s: %v", accountID, userID, err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to resolve account"})
+		return false
+	}
+	if m == nil {
+		log.Printf("⚠️  %s %s refused: %s is not a member of account %s", c.Request.Method, c.Request.URL.Path, userID, accountID)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not a member of account " + accountID})
+		return false
+	}
+	if m.Status != database.AccountActive {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account " + accountID + " is " + m.Status})
+		return false
+	}
+	c.Set(accountKey, accountID)
+	return true
+}
+
+// membership returns userID's membership of accountID from the cache or the
+// database, creating the user's personal account if that's the one asked for
+func (s *AccountScope) membership(ctx context.Context, accountID, userID string) (*database.AccountMembership, error) {
+	key := [2]string{accountID, userID}
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.membership, nil
+	}
+
+	m, err := s.db.GetAccountMembership(ctx, accountID, userID)
+	if err == nil && m == nil && accountID == userID {
+		if err = s.db.EnsurePersonalAccount(ctx, userID); err == nil {
+			m, err = s.db.GetAccountMembership(ctx, accountID, userID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	if len(s.cache) >= maxCachedMemberships {
+		for k, cached := range s.cache {
+			if now.After(cached.expires) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.cache[key] = cachedMembership{membership: m, expires: now.Add(membershipTTL)}
+	s.mu.Unlock()
+	return m, nil
+}
+
+// forget drops cached memberships of an account after it changes
+func (s *AccountScope) forget(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.cache {
+		if key[0] == accountID {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// AccountsHandler manages accounts and their members
+type AccountsHandler struct {
+	db    database.AccountRepository
+	scope *AccountScope
+}
+
+// NewAccountsHandler creates a new accounts handler
+func NewAccountsHandler(db database.AccountRepository, scope *AccountScope) *AccountsHandler {
+	return &AccountsHandler{db: db, scope: scope}
+}
+
+// ListAccounts handles GET /api/accounts: the caller's accounts with their
+// role in each, personal account first
+func (h *AccountsHandler) ListAccounts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	userID := requestUserID(c)
+	if _, err := h.scope.membership(ctx, userID, userID); err != nil {
+		log.Printf("❌ Failed to create personal account for %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+	accounts, err := h.db.ListUserAccounts(ctx, userID)
+	if err != nil {
+		log.Printf("❌ Failed to list accounts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts, "count": len(accounts)})
+}
+
+// CreateAccount handles POST /api/accounts. Body: name. The caller becomes
+// its owner.
+func (h *AccountsHandler) CreateAccount(c *gin.Context) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	account, err := h.db.CreateAccount(ctx, strings.TrimSpace(body.Name), requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to create account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+	log.Printf("👥 Account %s created by %s", account.ID, requestActor(c))
+	c.JSON(http.StatusCreated, account)
+}
+
+// loadAccount resolves the :id path param to an account the caller is a
+// member of, writing the error response itself and returning nil when it
+// can't. With owner set only owners get it.
+func (h *AccountsHandler) loadAccount(ctx context.Context, c *gin.Context, owner bool) *database.Account {
+	id := c.Param("id")
+	m, err := h.scope.membership(ctx, id, requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to get account %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve account"})
+		return nil
+	}
+	if m == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return nil
+	}
+	if owner && m.Role != database.AccountRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can manage account " + id})
+		return nil
+	}
+
+	account, err := h.db.GetAccount(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get account %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve account"})
+		return nil
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return nil
+	}
+	return account
+}
+
+// GetAccount handles GET /api/accounts/:id, with its members
+func (h *AccountsHandler) GetAccount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if account := h.loadAccount(ctx, c, false); account != nil {
+		c.JSON(http.StatusOK, account)
+	}
+}
+
+// UpdateAccount handles PUT /api/accounts/:id. Body: name. Owners only.
+func (h *AccountsHandler) UpdateAccount(c *gin.Context) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if h.loadAccount(ctx, c, true) == nil {
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	account, err := h.db.UpdateAccount(ctx, c.Param("id"), &name, nil)
+	if err != nil {
+		log.Printf("❌ Failed to update account %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// PutAccountMember handles PUT /api/accounts/:id/members/:user. Body: role
+// (owner or member, default member). Owners only.
+func (h *AccountsHandler) PutAccountMember(c *gin.Context) {
+	var body struct {
+		Role string `json:"role"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if body.Role == "" {
+		body.Role = database.AccountRoleMember
+	}
+	if !database.ValidAccountRole(body.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner or member"})
+		return
+	}
+	userID := strings.TrimSpace(c.Param("user"))
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if h.loadAccount(ctx, c, true) == nil {
+		return
+	}
+	id := c.Param("id")
+	err := h.db.PutAccountMember(ctx, id, userID, body.Role)
+	if errors.Is(err, database.ErrLastAccountOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account " + id + " must keep an owner"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to add %s to account %s: %v", userID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account members"})
+		return
+	}
+	h.scope.forget(id)
+	log.Printf("👥 %s is now %s of account %s (by %s)", userID, body.Role, id, requestActor(c))
+	c.JSON(http.StatusOK, gin.H{"account_id": id, "user_id": userID, "role": body.Role})
+}
+
+// RemoveAccountMember handles DELETE /api/accounts/:id/members/:user.
+// Owners can remove anyone; members can remove themselves.
+func (h *AccountsHandler) RemoveAccountMember(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, userID := c.Param("id"), c.Param("user")
+	if h.loadAccount(ctx, c, userID != requestUserID(c)) == nil {
+		return
+	}
+	removed, err := h.db.RemoveAccountMember(ctx, id, userID)
+	if errors.Is(err, database.ErrLastAccountOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account " + id + " must keep an owner"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to remove %s from account %s: %v", userID, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account members"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	h.scope.forget(id)
+	log.Printf("👥 %s removed from account %s (by %s)", userID, id, requestActor(c))
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed", "account_id": id, "user_id": userID})
+}
+
+// AdminListAccounts handles GET /api/admin/accounts. ?status= filters by
+// active or suspended.
+func (h *AccountsHandler) AdminListAccounts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	accounts, err := h.db.ListAccounts(ctx, c.Query("status"))
+	if err != nil {
+		log.Printf("❌ Failed to list accounts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts, "count": len(accounts)})
+}
+
+// AdminSetAccountStatus handles PUT /api/admin/accounts/:id/status. Body:
+// status (active or suspended). Members of a suspended account can't reach
+// its data.
+func (h *AccountsHandler) AdminSetAccountStatus(c *gin.Context) {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil ||
+		(body.Status != database.AccountActive && body.Status != database.AccountSuspended) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active or suspended"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	account, err := h.db.UpdateAccount(ctx, id, nil, &body.Status)
+	if err != nil {
+		log.Printf("❌ Failed to update account %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	h.scope.forget(id)
+	log.Printf("👥 Account %s %s by %s", id, body.Status, requestActor(c))
+	c.JSON(http.StatusOK, account)
+}
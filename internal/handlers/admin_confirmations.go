@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/twoperson"
+)
+
+// AdminConfirmationHandler enforces and audits the two-person rule
+type AdminConfirmationHandler struct {
+	db   database.AdminConfirmationRepository
+	rule *twoperson.Rule
+}
+
+// NewAdminConfirmationHandler creates a new admin confirmation handler
+func NewAdminConfirmationHandler(db database.AdminConfirmationRepository, rule *twoperson.Rule) *AdminConfirmationHandler {
+	return &AdminConfirmationHandler{db: db, rule: rule}
+}
+
+// Require guards a destructive action with the two-person rule when the
+// action is configured for it. A call without X-Confirmation-ID is
+// recorded and answered with 202 and the request to confirm; a different
+// admin repeating the identical call with that ID runs it. Place after the
+// role guard so only admins can request or confirm.
+func (h *AdminConfirmationHandler) Require(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.rule.Requires(action) {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxConfigBundleSize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := twoperson.PayloadHash(c.Request.Method, c.Request.URL.RequestURI(), body)
+		actor := requestActor(c)
+
+		header := strings.TrimSpace(c.GetHeader(twoperson.Header))
+		if header == "" {
+			request, err := h.db.RequestAdminConfirmation(ctx, action, c.Request.Method, c.Request.URL.RequestURI(),
+				hash, actor, time.Now().Add(h.rule.Window()))
+			if err != nil {
+				log.Printf("❌ Failed to request confirmation of %s: %v", action, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to request confirmation"})
+				return
+			}
+			log.Printf("👥 %s requested by %s, awaiting a second admin (confirmation %d)", action, actor, request.ID)
+			c.AbortWithStatusJSON(http.StatusAccepted, gin.H{
+				"message":      "A second admin must repeat this request with " + twoperson.Header + " before " + request.ExpiresAt,
+				"confirmation": request,
+			})
+			return
+		}
+
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": twoperson.Header + " must be a confirmation ID"})
+			return
+		}
+		confirmed, err := h.db.ConfirmAdminAction(ctx, id, action, hash, actor)
+		if err != nil {
+			log.Printf("❌ Failed to confirm %s: %v", action, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm action"})
+			return
+		}
+		if confirmed == nil {
+			h.refuse(ctx, c, id, action, hash, actor)
+			return
+		}
+
+		log.Printf("👥 %s requested by %s confirmed by %s (confirmation %d)", action, confirmed.RequestedBy, actor, id)
+		c.Next()
+
+		// The action's own context may be done; record with a fresh one
+		resultCtx, resultCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer resultCancel()
+		if err := h.db.RecordAdminActionResult(resultCtx, id, c.Writer.Status()); err != nil {
+			log.Printf("⚠️  Failed to record result of confirmation %d: %v", id, err)
+		}
+	}
+}
+
+// refuse explains why a confirmation didn't qualify
+func (h *AdminConfirmationHandler) refuse(ctx context.Context, c *gin.Context, id int64, action, hash, actor string) {
+	request, err := h.db.GetAdminConfirmation(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get confirmation %d: %v", id, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm action"})
+		return
+	}
+	switch {
+	case request == nil:
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Confirmation not found"})
+	case request.Action != action || request.PayloadHash != hash:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Request does not match the one awaiting confirmation", "confirmation": request})
+	case request.Status != database.ConfirmationPending:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Confirmation is " + request.Status, "confirmation": request})
+	default:
+		log.Printf("⚠️  %s tried to confirm their own %s request (confirmation %d)", actor, action, id)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "A different admin must confirm this request", "confirmation": request})
+	}
+}
+
+// ListConfirmations handles GET /api/system/confirmations.
+// Query: status (pending, confirmed, cancelled, expired or all; default
+// all) and limit (default 100, max 500).
+func (h *AdminConfirmationHandler) ListConfirmations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status := strings.ToLower(c.DefaultQuery("status", "all"))
+	switch status {
+	case "all":
+		status = ""
+	case database.ConfirmationPending, database.ConfirmationConfirmed, database.ConfirmationCancelled, database.ConfirmationExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, confirmed, cancelled, expired or all"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	confirmations, err := h.db.ListAdminConfirmations(ctx, status, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list admin confirmations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve confirmations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"confirmations":  confirmations,
+		"count":          len(confirmations),
+		"actions":        h.rule.Covered(),
+		"window_minutes": int(h.rule.Window().Minutes()),
+	})
+}
+
+// CancelConfirmation handles DELETE /api/system/confirmations/:id
+func (h *AdminConfirmationHandler) CancelConfirmation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid confirmation ID"})
+		return
+	}
+	actor := requestActor(c)
+	cancelled, err := h.db.CancelAdminConfirmation(ctx, id, actor)
+	if err != nil {
+		log.Printf("❌ Failed to cancel confirmation %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel confirmation"})
+		return
+	}
+	if cancelled == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending confirmation with that ID"})
+		return
+	}
+	log.Printf("👥 %s cancelled %s (confirmation %d)", actor, cancelled.Action, id)
+	c.JSON(http.StatusOK, cancelled)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/aggregates"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// AggregatesHandler exposes the intraday bar roll-up maintenance
+type AggregatesHandler struct {
+	db         *database.DB
+	maintainer *aggregates.Maintainer
+}
+
+// NewAggregatesHandler creates a new aggregates handler
+func NewAggregatesHandler(db *database.DB, maintainer *aggregates.Maintainer) *AggregatesHandler {
+	return &AggregatesHandler{db: db, maintainer: maintainer}
+}
+
+// GetAggregates handles GET /api/system/aggregates
+func (h *AggregatesHandler) GetAggregates(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.maintainer.Status(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to get aggregate status: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get aggregate status"})
+		return
+	}
+
+	mode := "summary_tables"
+	if h.db.TimescaleEnabled() {
+		mode = "continuous_aggregates"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"mode":       mode,
+		"interval":   h.maintainer.Interval().String(),
+		"aggregates": status,
+	})
+}
+
+// RefreshAggregates handles POST /api/system/aggregates/refresh
+func (h *AggregatesHandler) RefreshAggregates(c *gin.Context) {
+	started := make(chan bool, 1)
+	go func() {
+		started <- h.maintainer.Refresh(context.Background())
+	}()
+
+	// Refresh returns false immediately when a pass is already running
+	select {
+	case ok := <-started:
+		if !ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "A refresh is already running"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Refresh completed"})
+	case <-time.After(100 * time.Millisecond):
+		c.JSON(http.StatusAccepted, gin.H{"message": "Refresh started"})
+	}
+}
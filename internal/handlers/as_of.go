@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// asOfLayouts are accepted for ?as_of; those without an offset are IST
+var asOfLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// asOfParam reads ?as_of, the past instant to reconstruct a response at.
+// It returns nil when absent; on an invalid or future value it responds 400
+// and reports false.
+func asOfParam(c *gin.Context) (*time.Time, bool) {
+	v := c.Query("as_of")
+	if v == "" {
+		return nil, true
+	}
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	for _, layout := range asOfLayouts {
+		t, err := time.ParseInLocation(layout, v, ist)
+		if err != nil {
+			continue
+		}
+		if t.After(time.Now()) {
+			break
+		}
+		return &t, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "as_of must be a past time like 2024-05-10T13:30 (IST) or RFC 3339"})
+	return nil, false
+}
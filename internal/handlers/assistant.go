@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/regime"
+)
+
+const (
+	// maxAssistantResults caps every list an intent returns
+	maxAssistantResults = 100
+	// maxAssistantScan caps the signals one signals query reads
+	maxAssistantScan = 5000
+)
+
+// AssistantHandler lets an LLM assistant query trading data through a fixed
+// set of parameterized intents. Each intent maps onto existing repository
+// reads; callers never supply SQL, and parameters are validated against the
+// intent's schema before anything runs.
+type AssistantHandler struct {
+	db      database.AssistantRepository
+	regime  *regime.Tracker
+	intents map[string]assistantIntent
+}
+
+// NewAssistantHandler creates a new assistant handler. regimeTracker may be
+// nil, which leaves out the market_regime intent.
+func NewAssistantHandler(db database.AssistantRepository, regimeTracker *regime.Tracker) *AssistantHandler {
+	h := &AssistantHandler{db: db, regime: regimeTracker, intents: map[string]assistantIntent{}}
+	for _, intent := range h.catalogue() {
+		h.intents[intent.Name] = intent
+	}
+	return h
+}
+
+// assistantParam describes one intent parameter. Types are string, integer
+// and date (today, yesterday or YYYY-MM-DD in IST).
+type assistantParam struct {
+	Name        string
+	Type        string
+	Description string
+	Enum        []string
+	Required    bool
+	Default     interface{}
+	Min, Max    int
+}
+
+// assistantIntent is a query the assistant may run
+type assistantIntent struct {
+	Name        string
+	Description string
+	Params      []assistantParam
+	run         func(ctx context.Context, args assistantArgs) (interface{}, error)
+}
+
+// schema renders the intent's parameters as a JSON Schema object, the shape
+// LLM tool definitions expect
+func (i assistantIntent) schema() gin.H {
+	properties := gin.H{}
+	required := []string{}
+	for _, p := range i.Params {
+		prop := gin.H{"description": p.Description}
+		switch p.Type {
+		case "integer":
+			prop["type"] = "integer"
+			prop["minimum"] = p.Min
+			prop["maximum"] = p.Max
+		case "date":
+			prop["type"] = "string"
+			prop["pattern"] = `^(today|yesterday|\d{4}-\d{2}-\d{2})$`
+		default:
+			prop["type"] = "string"
+		}
+		if len(p.Enum) > 0 {
+			prop["enum"] = p.Enum
+		}
+		if p.Default != nil {
+			prop["default"] = p.Default
+		}
+		properties[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	return gin.H{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// assistantArgs are validated parameters, defaults applied
+type assistantArgs map[string]interface{}
+
+func (a assistantArgs) str(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+func (a assistantArgs) integer(name string) int {
+	n, _ := a[name].(int)
+	return n
+}
+
+func (a assistantArgs) date(name string) time.Time {
+	t, _ := a[name].(time.Time)
+	return t
+}
+
+// validate checks raw parameters against the intent, returning them
+// normalized with defaults filled in
+func (i assistantIntent) validate(raw map[string]interface{}, now time.Time) (assistantArgs, error) {
+	known := map[string]bool{}
+	args := assistantArgs{}
+	for _, p := range i.Params {
+		known[p.Name] = true
+		v, ok := raw[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, fmt.Errorf("%s is required", p.Name)
+			}
+			if p.Default == nil {
+				continue
+			}
+			v = p.Default
+		}
+
+		switch p.Type {
+		case "integer":
+			f, ok := v.(float64)
+			if n, isInt := v.(int); isInt {
+				f, ok = float64(n), true
+			}
+			if !ok || f != math.Trunc(f) || f < float64(p.Min) || f > float64(p.Max) {
+				return nil, fmt.Errorf("%s must be an integer from %d to %d", p.Name, p.Min, p.Max)
+			}
+			args[p.Name] = int(f)
+		case "date":
+			s, _ := v.(string)
+			t, err := assistantDate(s, now)
+			if err != nil {
+				return nil, fmt.Errorf("%s %v", p.Name, err)
+			}
+			args[p.Name] = t
+		default:
+			s, ok := v.(string)
+			s = strings.TrimSpace(s)
+			if !ok || s == "" || len(s) > 100 {
+				return nil, fmt.Errorf("%s must be a string of 1 to 100 characters", p.Name)
+			}
+			if len(p.Enum) > 0 {
+				match := ""
+				for _, e := range p.Enum {
+					if strings.EqualFold(e, s) {
+						match = e
+					}
+				}
+				if match == "" {
+					return nil, fmt.Errorf("%s must be one of %s", p.Name, strings.Join(p.Enum, ", "))
+				}
+				s = match
+			}
+			args[p.Name] = s
+		}
+	}
+	for name := range raw {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	return args, nil
+}
+
+// assistantDate parses a date parameter into the start of that IST day
+func assistantDate(s string, now time.Time) (time.Time, error) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	today := now.In(ist)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, ist)
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(s), ist)
+	if err != nil {
+		return time.Time{}, errors.New("must be today, yesterday or YYYY-MM-DD")
+	}
+	if t.After(today) {
+		return time.Time{}, errors.New("is in the future")
+	}
+	return t, nil
+}
+
+// ListIntents handles GET /api/assistant/intents, describing every intent
+// with a JSON Schema for its parameters
+func (h *AssistantHandler) ListIntents(c *gin.Context) {
+	names := make([]string, 0, len(h.intents))
+	for name := range h.intents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	intents := make([]gin.H, 0, len(names))
+	for _, name := range names {
+		intent := h.intents[name]
+		intents = append(intents, gin.H{
+			"name":        intent.Name,
+			"description": intent.Description,
+			"parameters":  intent.schema(),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"intents": intents, "count": len(intents)})
+}
+
+// Query handles POST /api/assistant/query. Body: {"intent": ...,
+// "params": {...}}. Returns the normalized parameters with the result so
+// the assistant can say what it looked up.
+func (h *AssistantHandler) Query(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		Intent string                 `json:"intent" binding:"required"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "intent is required"})
+		return
+	}
+	intent, ok := h.intents[body.Intent]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown intent " + body.Intent})
+		return
+	}
+	args, err := intent.validate(body.Params, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "parameters": intent.schema()})
+		return
+	}
+
+	result, err := intent.run(ctx, args)
+	if err != nil {
+		log.Printf("❌ Assistant intent %s failed: %v", intent.Name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run " + intent.Name})
+		return
+	}
+
+	params := gin.H{}
+	for name, v := range args {
+		if t, ok := v.(time.Time); ok {
+			v = t.Format("2006-01-02")
+		}
+		params[name] = v
+	}
+	c.JSON(http.StatusOK, gin.H{"intent": intent.Name, "params": params, "result": result})
+}
+
+// catalogue lists the intents
+func (h *AssistantHandler) catalogue() []assistantIntent {
+	limit := func(def int) assistantParam {
+		return assistantParam{Name: "limit", Type: "integer", Description: "Maximum results", Default: def, Min: 1, Max: maxAssistantResults}
+	}
+	symbol := func(required bool) assistantParam {
+		return assistantParam{Name: "symbol", Type: "string", Description: "NSE symbol, e.g. RELIANCE", Required: required}
+	}
+
+	intents := []assistantIntent{
+		{
+			Name:        "signals",
+			Description: "Signals generated on a day, filtered by status, direction, sector, symbol and whether they are winning or losing (closed by realised profit, open by current price)",
+			Params: []assistantParam{
+				{Name: "date", Type: "date", Description: "Day the signals were generated", Default: "today"},
+				{Name: "status", Type: "string", Description: "Signal status", Enum: []string{"ACTIVE", "HIT_TARGET", "HIT_STOPLOSS", "TRAILING_STOP", "TIME_EXIT", "EXPIRED"}},
+				{Name: "signal_type", Type: "string", Description: "Direction", Enum: []string{"BUY", "SELL"}},
+				{Name: "outcome", Type: "string", Description: "Winning or losing so far", Enum: []string{"winning", "losing"}},
+				{Name: "sector", Type: "string", Description: "Sector name or word, e.g. banking, IT, pharma"},
+				symbol(false),
+				limit(20),
+			},
+			run: h.signals,
+		},
+		{
+			Name:        "signal",
+			Description: "One signal by ID",
+			Params:      []assistantParam{{Name: "signal_id", Type: "string", Description: "Signal ID", Required: true}},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				s, err := h.db.GetSignalByID(ctx, args.str("signal_id"))
+				if err != nil || s == nil {
+					return nil, err
+				}
+				return newAssistantSignal(s), nil
+			},
+		},
+		{
+			Name:        "signal_stats",
+			Description: "Counts, hit rate and average profit of signals generated since a day",
+			Params:      []assistantParam{{Name: "since", Type: "date", Description: "First day counted", Default: "today"}},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.db.GetSignalStats(ctx, args.date("since"))
+			},
+		},
+		{
+			Name:        "top_movers",
+			Description: "Today's biggest gainers or losers by percent change",
+			Params: []assistantParam{
+				{Name: "direction", Type: "string", Description: "Gainers or losers", Enum: []string{"gainers", "losers"}, Default: "gainers"},
+				limit(10),
+			},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				if args.str("direction") == "losers" {
+					return h.db.GetTopLosers(ctx, args.integer("limit"))
+				}
+				return h.db.GetTopGainers(ctx, args.integer("limit"))
+			},
+		},
+		{
+			Name:        "predicted_movers",
+			Description: "Stocks the models predict will gain or lose most",
+			Params: []assistantParam{
+				{Name: "direction", Type: "string", Description: "Gainers or losers", Enum: []string{"gainers", "losers"}, Default: "gainers"},
+				limit(10),
+			},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				if args.str("direction") == "losers" {
+					return h.db.GetPredictedLosers(ctx, args.integer("limit"))
+				}
+				return h.db.GetPredictedGainers(ctx, args.integer("limit"))
+			},
+		},
+		{
+			Name:        "quote",
+			Description: "Latest price of a stock",
+			Params:      []assistantParam{symbol(true)},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.db.GetRealtimePrice(ctx, strings.ToUpper(args.str("symbol")))
+			},
+		},
+		{
+			Name:        "search_stocks",
+			Description: "Stocks whose symbol or name matches a query",
+			Params:      []assistantParam{{Name: "query", Type: "string", Description: "Symbol or company name", Required: true}},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.db.SearchStocks(ctx, args.str("query"))
+			},
+		},
+		{
+			Name:        "news",
+			Description: "Latest news, optionally about a stock, matching a search or of a sentiment",
+			Params: []assistantParam{
+				symbol(false),
+				{Name: "search", Type: "string", Description: "Words to search headlines for"},
+				{Name: "sentiment", Type: "string", Description: "Article sentiment", Enum: []string{"positive", "negative", "neutral"}},
+				limit(10),
+			},
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.db.GetNews(ctx, args.integer("limit"), 0, args.str("sentiment"), args.str("search"),
+					strings.ToUpper(args.str("symbol")), true)
+			},
+		},
+		{
+			Name:        "market_indices",
+			Description: "Latest levels and changes of the market indices",
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.db.GetMarketIndices(ctx)
+			},
+		},
+	}
+	if h.regime != nil {
+		intents = append(intents, assistantIntent{
+			Name:        "market_regime",
+			Description: "Today's market regime (trending, range-bound or volatile) and the metrics behind it",
+			run: func(ctx context.Context, args assistantArgs) (interface{}, error) {
+				return h.regime.Current(ctx)
+			},
+		})
+	}
+	return intents
+}
+
+// assistantSignal is a signal without its model internals
+type assistantSignal struct {
+	SignalID     string     `json:"signal_id"`
+	Symbol       string     `json:"symbol"`
+	StockName    string     `json:"stock_name"`
+	Sector       string     `json:"sector"`
+	SignalType   string     `json:"signal_type"`
+	Status       string     `json:"status"`
+	Confidence   float64    `json:"confidence"`
+	EntryPrice   float64    `json:"entry_price"`
+	CurrentPrice float64    `json:"current_price"`
+	StopLoss     float64    `json:"stop_loss"`
+	TargetPrice  float64    `json:"target_price"`
+	ProfitPct    float64    `json:"profit_pct"`
+	GeneratedAt  time.Time  `json:"generated_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+func newAssistantSignal(s *database.Signal) assistantSignal {
+	return assistantSignal{
+		SignalID:     s.SignalID,
+		Symbol:       s.Symbol,
+		StockName:    s.StockName,
+		Sector:       s.Sector,
+		SignalType:   s.SignalType,
+		Status:       s.Status,
+		Confidence:   s.ConfidenceScore,
+		EntryPrice:   s.EntryPrice,
+		CurrentPrice: s.CurrentPrice,
+		StopLoss:     s.StopLoss,
+		TargetPrice:  s.TargetPrice,
+		ProfitPct:    math.Round(signalProfitPct(s)*100) / 100,
+		GeneratedAt:  s.GeneratedAt,
+		ClosedAt:     s.ClosedAt,
+	}
+}
+
+// signalProfitPct is a closed signal's realised profit, or an open one's
+// move from entry in its direction
+func signalProfitPct(s *database.Signal) float64 {
+	if s.ActualProfitPct != nil {
+		return *s.ActualProfitPct
+	}
+	if s.EntryPrice <= 0 || s.CurrentPrice <= 0 {
+		return 0
+	}
+	pct := (s.CurrentPrice - s.EntryPrice) / s.EntryPrice * 100
+	if s.SignalType == "SELL" {
+		pct = -pct
+	}
+	return pct
+}
+
+// sectorStem reduces a sector word so "banking", "banks" and "Bank" match
+func sectorStem(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, suffix := range []string{"ing", "s"} {
+		if len(s) > len(suffix)+2 {
+			s = strings.TrimSuffix(s, suffix)
+		}
+	}
+	return s
+}
+
+// sectorMatches reports whether a word of sector starts with stem, so short
+// stems like "it" don't match inside other words
+func sectorMatches(sector, stem string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToLower(sector), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '&' || r == '/' || r == ','
+	}) {
+		if strings.HasPrefix(word, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// signals runs the signals intent over StreamSignals, newest first, stopping
+// at the start of the requested day
+func (h *AssistantHandler) signals(ctx context.Context, args assistantArgs) (interface{}, error) {
+	from := args.date("date")
+	to := from.AddDate(0, 0, 1)
+	signalType := args.str("signal_type")
+	outcome := args.str("outcome")
+	symbol := strings.ToUpper(args.str("symbol"))
+	sector := ""
+	if v := args.str("sector"); v != "" {
+		sector = sectorStem(v)
+	}
+	limit := args.integer("limit")
+
+	out := []assistantSignal{}
+	match := func(s *database.Signal) bool {
+		if (signalType != "" && s.SignalType != signalType) ||
+			(symbol != "" && s.Symbol != symbol) ||
+			(sector != "" && !sectorMatches(s.Sector, sector)) {
+			return false
+		}
+		pct := signalProfitPct(s)
+		return !(outcome == "winning" && pct <= 0) && !(outcome == "losing" && pct >= 0)
+	}
+	truncated, err := scanSignals(ctx, h.db, args.str("status"), from, to, match, limit, maxAssistantScan, func(s database.Signal) error {
+		out = append(out, newAssistantSignal(&s))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{"signals": out, "count": len(out), "truncated": truncated}, nil
+}
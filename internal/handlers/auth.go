@@ -14,11 +14,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
 )
 
 // GetZerodhaLoginUrl returns the Zerodha Kite login URL with the configured API key
 func (h *Handler) GetZerodhaLoginUrl(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	config, err := h.db.GetBrokerConfig(ctx, "zerodha")
@@ -54,7 +55,7 @@ func (h *Handler) ExchangeRequestToken(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	config, err := h.db.GetBrokerConfig(ctx, "zerodha")
@@ -88,8 +89,7 @@ func (h *Handler) ExchangeRequestToken(c *gin.Context) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("X-Kite-Version", "3")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := kiteClient.Do(req)
 	if err != nil {
 		log.Printf("Kite API error: %v", err)
 		c.JSON(http.StatusBadGateway, gin.H{"detail": fmt.Sprintf("Kite API error: %v", err)})
@@ -119,6 +119,7 @@ func (h *Handler) ExchangeRequestToken(c *gin.Context) {
 
 	if kiteResp.Status != "success" || kiteResp.Data.AccessToken == "" {
 		log.Printf("Kite token exchange failed: %s - %s", kiteResp.ErrorType, kiteResp.Message)
+		authFailed(c, authguard.KindTokenExchangeFailed, "zerodha", kiteResp.ErrorType+": "+kiteResp.Message)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"detail":     kiteResp.Message,
 			"error_type": kiteResp.ErrorType,
@@ -143,6 +144,7 @@ func (h *Handler) ExchangeRequestToken(c *gin.Context) {
 	}
 
 	log.Printf("✅ Zerodha token exchanged for user %s", kiteResp.Data.UserID)
+	brokerTokenSaved(c, "zerodha")
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":           "success",
@@ -165,7 +167,7 @@ func (h *Handler) SaveAccessToken(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	config, err := h.db.GetBrokerConfig(ctx, "zerodha")
@@ -183,8 +185,7 @@ func (h *Handler) SaveAccessToken(c *gin.Context) {
 	profileReq.Header.Set("X-Kite-Version", "3")
 	profileReq.Header.Set("Authorization", fmt.Sprintf("token %s:%s", config.APIKey, body.AccessToken))
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(profileReq)
+	resp, err := kiteClient.Do(profileReq)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"detail": fmt.Sprintf("Failed to validate token: %v", err)})
 		return
@@ -209,6 +210,7 @@ func (h *Handler) SaveAccessToken(c *gin.Context) {
 	}
 
 	if profileResp.Status != "success" {
+		authFailed(c, authguard.KindTokenExchangeFailed, "zerodha", profileResp.ErrorType+": "+profileResp.Message)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"detail":     fmt.Sprintf("Invalid token: %s", profileResp.Message),
 			"error_type": profileResp.ErrorType,
@@ -236,6 +238,7 @@ func (h *Handler) SaveAccessToken(c *gin.Context) {
 	}
 
 	log.Printf("✅ Zerodha access token saved for user %s", userID)
+	brokerTokenSaved(c, "zerodha")
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":           "success",
@@ -248,7 +251,7 @@ func (h *Handler) SaveAccessToken(c *gin.Context) {
 
 // GetZerodhaAuthStatus returns the current Zerodha authentication status
 func (h *Handler) GetZerodhaAuthStatus(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	config, err := h.db.GetBrokerConfig(ctx, "zerodha")
@@ -296,7 +299,7 @@ func (h *Handler) GetZerodhaAuthStatus(c *gin.Context) {
 
 // LogoutZerodha logs out the user and invalidates the Zerodha token
 func (h *Handler) LogoutZerodha(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	if err := h.db.ClearBrokerToken(ctx, "zerodha"); err != nil {
@@ -354,7 +357,7 @@ func (h *Handler) SaveIndMoneyToken(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	// Try to extract expiry from JWT; fall back to next-day 7 AM IST
@@ -397,6 +400,7 @@ func (h *Handler) SaveIndMoneyToken(c *gin.Context) {
 	}
 
 	log.Printf("✅ IndMoney access token saved for user %s (expires %s)", userID, expiresAt.Format(time.RFC3339))
+	brokerTokenSaved(c, "indmoney")
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":           "success",
@@ -409,7 +413,7 @@ func (h *Handler) SaveIndMoneyToken(c *gin.Context) {
 
 // GetIndMoneyAuthStatus returns the current IndMoney authentication status
 func (h *Handler) GetIndMoneyAuthStatus(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	config, err := h.db.GetBrokerConfig(ctx, "indmoney")
@@ -457,7 +461,7 @@ func (h *Handler) GetIndMoneyAuthStatus(c *gin.Context) {
 
 // LogoutIndMoney logs out the user and invalidates the IndMoney token
 func (h *Handler) LogoutIndMoney(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	if err := h.db.ClearBrokerToken(ctx, "indmoney"); err != nil {
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/guest"
+	"github.com/trading-chitti/core-api-go/internal/serviceauth"
+)
+
+// authOutcomeKey holds what a handler made of the request's credentials,
+// for AuthGuardMiddleware to act on once it returns
+const authOutcomeKey = "auth_outcome"
+
+// lockoutPrefixes are the broker auth routes a locked-out IP is refused
+var lockoutPrefixes = []string{"/api/auth/zerodha/", "/api/auth/indmoney/"}
+
+// lockoutRoutes are the webhook receivers, which authenticate by secret, a
+// locked-out IP is refused
+var lockoutRoutes = map[string]bool{
+	"/api/integrations/tradingview":   true,
+	"/api/integrations/inbound/:slug": true,
+}
+
+type authOutcome struct {
+	kind    string
+	subject string
+	detail  string
+	// saved marks a successful token exchange rather than a failure
+	saved bool
+}
+
+// authFailed reports a failed auth attempt to the auth guard
+func authFailed(c *gin.Context, kind, subject, detail string) {
+	c.Set(authOutcomeKey, authOutcome{kind: kind, subject: subject, detail: detail})
+}
+
+// brokerTokenSaved reports a broker token exchanged or saved
+func brokerTokenSaved(c *gin.Context, broker string) {
+	c.Set(authOutcomeKey, authOutcome{subject: broker, saved: true})
+}
+
+// authAttempt reports whether the request tries to authenticate, so a
+// locked-out IP is refused it
+func authAttempt(c *gin.Context) bool {
+	if c.GetHeader(serviceauth.Header) != "" || c.GetHeader(guest.Header) != "" || c.Query(guest.QueryParam) != "" {
+		return true
+	}
+	if c.Request.Method == http.MethodPost && lockoutRoutes[c.FullPath()] {
+		return true
+	}
+	for _, prefix := range lockoutPrefixes {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthGuardMiddleware refuses auth attempts from locked-out IPs with 429
+// and passes the outcome of every attempt to the guard. It must run ahead
+// of anything that checks credentials.
+func AuthGuardMiddleware(guard *authguard.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		if authAttempt(c) {
+			if until, locked := guard.Locked(ip, time.Now()); locked {
+				retry := int(math.Ceil(time.Until(until).Seconds()))
+				c.Header("Retry-After", strconv.Itoa(retry))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+					"error":        "Too many failed auth attempts from this address",
+					"locked_until": until.Format(time.RFC3339),
+				})
+				return
+			}
+		}
+
+		c.Next()
+
+		v, ok := c.Get(authOutcomeKey)
+		if !ok {
+			return
+		}
+		outcome := v.(authOutcome)
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = c.Request.URL.Path
+		}
+		attempt := authguard.Attempt{
+			Kind:     outcome.kind,
+			IP:       ip,
+			Endpoint: c.Request.Method + " " + endpoint,
+			Subject:  outcome.subject,
+			Detail:   outcome.detail,
+		}
+		if outcome.saved {
+			guard.TokenSaved(attempt)
+		} else {
+			guard.Failure(attempt)
+		}
+	}
+}
+
+// AuthSecurityHandler serves auth security events and lockouts
+type AuthSecurityHandler struct {
+	db    database.AuthSecurityRepository
+	guard *authguard.Guard
+}
+
+// NewAuthSecurityHandler creates a new auth security handler
+func NewAuthSecurityHandler(db database.AuthSecurityRepository, guard *authguard.Guard) *AuthSecurityHandler {
+	return &AuthSecurityHandler{db: db, guard: guard}
+}
+
+// ListSecurityEvents handles GET /api/auth/security-events.
+// Query: kind, severity, ip, since (RFC3339) and limit (default 100, max
+// 1000). Returns matching events newest first, with the IPs locked out now.
+func (h *AuthSecurityHandler) ListSecurityEvents(c *gin.Context) {
+	f := database.AuthSecurityFilter{
+		Kind:     c.Query("kind"),
+		Severity: c.Query("severity"),
+		IP:       c.Query("ip"),
+	}
+	switch f.Severity {
+	case "", database.AuthSeverityInfo, database.AuthSeverityWarning, database.AuthSeverityCritical:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "severity must be info, warning or critical"})
+		return
+	}
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be RFC3339"})
+			return
+		}
+		f.Since = &since
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+	f.Limit = limit
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	events, err := h.db.ListAuthSecurityEvents(ctx, f)
+	if err != nil {
+		log.Printf("❌ Failed to list auth security events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve security events"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"events":   events,
+		"count":    len(events),
+		"lockouts": h.guard.Lockouts(),
+		"config": gin.H{
+			"max_failures":    h.guard.Config().MaxFailures,
+			"window_minutes":  int(h.guard.Config().Window / time.Minute),
+			"lockout_minutes": int(h.guard.Config().Lockout / time.Minute),
+		},
+	})
+}
+
+// Unlock handles DELETE /api/auth/security-events/lockouts/:ip, lifting
+// an IP's lockout on this instance
+func (h *AuthSecurityHandler) Unlock(c *gin.Context) {
+	ip := c.Param("ip")
+	if !h.guard.Unlock(ip) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "IP is not locked out"})
+		return
+	}
+	log.Printf("🔓 Auth lockout of %s lifted by %s", ip, requestActor(c))
+	c.JSON(http.StatusOK, gin.H{"ip": ip, "unlocked": true})
+}
@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// BasketsHandler serves user-defined baskets and their alerts
+type BasketsHandler struct {
+	db database.BasketRepository
+}
+
+// NewBasketsHandler creates a new baskets handler
+func NewBasketsHandler(db database.BasketRepository) *BasketsHandler {
+	return &BasketsHandler{db: db}
+}
+
+// basketConstituentInput is one symbol and weight in a create or update body
+type basketConstituentInput struct {
+	Symbol string  `json:"symbol"`
+	Weight float64 `json:"weight"`
+}
+
+// parseBasketWeights validates constituents, writing the error response
+// itself and returning nil when they're invalid
+func parseBasketWeights(c *gin.Context, constituents []basketConstituentInput) map[string]float64 {
+	if len(constituents) == 0 || len(constituents) > database.MaxBasketConstituents {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A basket needs between 1 and " + strconv.Itoa(database.MaxBasketConstituents) + " constituents",
+		})
+		return nil
+	}
+
+	weights := make(map[string]float64, len(constituents))
+	for _, bc := range constituents {
+		symbol := strings.ToUpper(strings.TrimSpace(bc.Symbol))
+		if symbol == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Constituent symbol is required"})
+			return nil
+		}
+		if bc.Weight <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Constituent weights must be positive", "symbol": symbol})
+			return nil
+		}
+		if _, dup := weights[symbol]; dup {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Duplicate constituent", "symbol": symbol})
+			return nil
+		}
+		weights[symbol] = bc.Weight
+	}
+	return weights
+}
+
+// basketError maps errors from creating or rebasing a basket to a response
+func basketError(c *gin.Context, action string, err error) {
+	var missing *database.MissingPricesError
+	if errors.As(err, &missing) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "No price available for some constituents",
+			"symbols": missing.Symbols,
+		})
+		return
+	}
+	if errors.Is(err, database.ErrBasketNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A basket with this name already exists"})
+		return
+	}
+	log.Printf("❌ Failed to %s basket: %v", action, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " basket"})
+}
+
+// loadBasket resolves the :id path param to a basket owned by the caller,
+// writing the error response itself and returning nil when it can't
+func (h *BasketsHandler) loadBasket(ctx context.Context, c *gin.Context) *database.Basket {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid basket ID"})
+		return nil
+	}
+
+	basket, err := h.db.GetBasket(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get basket %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve basket"})
+		return nil
+	}
+	if basket == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Basket not found"})
+		return nil
+	}
+	return basket
+}
+
+// ListBaskets handles GET /api/baskets
+func (h *BasketsHandler) ListBaskets(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	baskets, err := h.db.ListBaskets(ctx, requestAccountID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list baskets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve baskets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"baskets": baskets,
+		"count":   len(baskets),
+	})
+}
+
+// CreateBasket handles POST /api/baskets. Constituents are based at their
+// current prices; base_value defaults to 100.
+func (h *BasketsHandler) CreateBasket(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		Name         string                   `json:"name"`
+		Description  *string                  `json:"description"`
+		BaseValue    float64                  `json:"base_value"`
+		Constituents []basketConstituentInput `json:"constituents"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Basket name is required"})
+		return
+	}
+	if body.BaseValue < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base_value must be positive"})
+		return
+	}
+	if body.BaseValue == 0 {
+		body.BaseValue = 100
+	}
+	weights := parseBasketWeights(c, body.Constituents)
+	if weights == nil {
+		return
+	}
+
+	basket, err := h.db.CreateBasket(ctx, requestAccountID(c), requestUserID(c), strings.TrimSpace(body.Name), body.Description, body.BaseValue, weights)
+	if err != nil {
+		basketError(c, "create", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, basket)
+}
+
+// GetBasket handles GET /api/baskets/:id, returning the basket with its
+// current valuation
+func (h *BasketsHandler) GetBasket(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	valuation, err := h.db.ValueBasket(ctx, basket)
+	if err != nil {
+		log.Printf("❌ Failed to value basket %d: %v", basket.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to value basket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"basket":    basket,
+		"valuation": valuation,
+	})
+}
+
+// UpdateBasket handles PUT /api/baskets/:id. Replacing constituents rebases
+// the basket at its current NAV.
+func (h *BasketsHandler) UpdateBasket(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid basket ID"})
+		return
+	}
+
+	var body struct {
+		Name         *string                  `json:"name"`
+		Description  *string                  `json:"description"`
+		Constituents []basketConstituentInput `json:"constituents"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if body.Name != nil {
+		trimmed := strings.TrimSpace(*body.Name)
+		if trimmed == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Basket name cannot be empty"})
+			return
+		}
+		body.Name = &trimmed
+	}
+	var weights map[string]float64
+	if body.Constituents != nil {
+		if weights = parseBasketWeights(c, body.Constituents); weights == nil {
+			return
+		}
+	}
+
+	basket, err := h.db.UpdateBasket(ctx, requestAccountID(c), id, body.Name, body.Description, weights)
+	if err != nil {
+		basketError(c, "update", err)
+		return
+	}
+	if basket == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Basket not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, basket)
+}
+
+// DeleteBasket handles DELETE /api/baskets/:id
+func (h *BasketsHandler) DeleteBasket(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid basket ID"})
+		return
+	}
+
+	deleted, err := h.db.DeleteBasket(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete basket %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete basket"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Basket not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Basket deleted", "id": id})
+}
+
+// GetBasketNAV handles GET /api/baskets/:id/nav
+func (h *BasketsHandler) GetBasketNAV(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	valuation, err := h.db.ValueBasket(ctx, basket)
+	if err != nil {
+		log.Printf("❌ Failed to value basket %d: %v", basket.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to value basket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, valuation)
+}
+
+// GetBasketHistory handles GET /api/baskets/:id/history.
+// Query: from (YYYY-MM-DD, defaults to the basket's base date).
+func (h *BasketsHandler) GetBasketHistory(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	var from time.Time
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	points, err := h.db.GetBasketHistory(ctx, basket, from)
+	if err != nil {
+		log.Printf("❌ Failed to get history for basket %d: %v", basket.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve basket history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"basket_id":  basket.ID,
+		"base_value": basket.BaseValue,
+		"base_date":  basket.BaseDate,
+		"history":    points,
+		"count":      len(points),
+	})
+}
+
+// ListBasketAlerts handles GET /api/baskets/:id/alerts
+func (h *BasketsHandler) ListBasketAlerts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	alerts, err := h.db.ListBasketAlerts(ctx, basket.ID)
+	if err != nil {
+		log.Printf("❌ Failed to list alerts for basket %d: %v", basket.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve basket alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+// CreateBasketAlert handles POST /api/baskets/:id/alerts. Alerts fire once,
+// over WebSocket as "basket_alert", and are then deactivated.
+func (h *BasketsHandler) CreateBasketAlert(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Condition string   `json:"condition"`
+		Threshold *float64 `json:"threshold"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	body.Condition = strings.ToUpper(strings.TrimSpace(body.Condition))
+	if !database.ValidBasketAlertCondition(body.Condition) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "condition must be NAV_ABOVE, NAV_BELOW, CHANGE_ABOVE or CHANGE_BELOW"})
+		return
+	}
+	if body.Threshold == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "threshold is required"})
+		return
+	}
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	alert, err := h.db.CreateBasketAlert(ctx, basket.ID, body.Condition, *body.Threshold)
+	if err != nil {
+		log.Printf("❌ Failed to create alert for basket %d: %v", basket.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create basket alert"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alert)
+}
+
+// DeleteBasketAlert handles DELETE /api/baskets/:id/alerts/:alertId
+func (h *BasketsHandler) DeleteBasketAlert(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	alertID, err := strconv.ParseInt(c.Param("alertId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	basket := h.loadBasket(ctx, c)
+	if basket == nil {
+		return
+	}
+
+	deleted, err := h.db.DeleteBasketAlert(ctx, basket.ID, alertID)
+	if err != nil {
+		log.Printf("❌ Failed to delete alert %d: %v", alertID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete basket alert"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert deleted", "id": alertID})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/calendar"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// CalendarHandler serves the iCalendar feed of market events and job schedules
+type CalendarHandler struct {
+	db database.CalendarRepository
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(db database.CalendarRepository) *CalendarHandler {
+	return &CalendarHandler{db: db}
+}
+
+// GetCalendarICS handles GET /api/calendar.ics.
+// Query: include (comma-separated holidays, results, jobs; default all).
+// Holidays cover this year and next; results dates run from 90 days back
+// to a year ahead.
+func (h *CalendarHandler) GetCalendarICS(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	include := map[string]bool{"holidays": true, "results": true, "jobs": true}
+	if v := c.Query("include"); v != "" {
+		include = map[string]bool{}
+		for _, part := range strings.Split(v, ",") {
+			include[strings.ToLower(strings.TrimSpace(part))] = true
+		}
+	}
+
+	now := time.Now()
+	today := database.SessionOpen(now)
+	holidays := []database.MarketHoliday{}
+	results := []database.ResultsDate{}
+	jobs := []calendar.Job{}
+	var err error
+
+	if include["holidays"] {
+		from := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(today.Year()+1, 12, 31, 0, 0, 0, 0, time.UTC)
+		if holidays, err = h.db.GetMarketHolidays(ctx, from, to); err != nil {
+			log.Printf("❌ Failed to load market holidays for calendar: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+			return
+		}
+	}
+	if include["results"] {
+		if results, err = h.db.GetResultsDates(ctx, today.AddDate(0, 0, -90), today.AddDate(1, 0, 0)); err != nil {
+			log.Printf("❌ Failed to load results dates for calendar: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+			return
+		}
+	}
+	if include["jobs"] {
+		for _, j := range cronJobs() {
+			if j.Status == "disabled" {
+				continue
+			}
+			jobs = append(jobs, calendar.Job{
+				Name:          j.Name,
+				Description:   j.Description,
+				Schedule:      j.Schedule,
+				ScheduleHuman: j.ScheduleHuman,
+			})
+		}
+	}
+
+	cal := calendar.Build("Trading Chitti", holidays, results, jobs, now)
+	c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	c.Header("Cache-Control", "public, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(cal.Encode(now)))
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// currentFinancialYear returns the Indian FY label (e.g. "2024-25") for now
+func currentFinancialYear() string {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now().In(ist)
+	start := now.Year()
+	if now.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// GetCapitalGains handles GET /api/portfolios/:id/capital-gains
+func (h *Handler) GetCapitalGains(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	fy := c.DefaultQuery("fy", currentFinancialYear())
+	if _, _, err := database.ParseFinancialYear(fy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.db.GetCapitalGainsReport(ctx, portfolio.ID, fy)
+	if err != nil {
+		log.Printf("❌ Failed to compute capital gains for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute capital gains"})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeCapitalGainsCSV(c, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// writeCapitalGainsCSV streams the lot-level report in a filing-friendly layout
+func writeCapitalGainsCSV(c *gin.Context, report *database.CapitalGainsReport) {
+	filename := fmt.Sprintf("capital_gains_%d_FY%s.csv", report.PortfolioID, report.FinancialYear)
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"symbol", "exchange", "quantity", "buy_date", "buy_price", "sell_date", "sell_price",
+		"holding_days", "term", "fmv_31jan2018", "cost_of_acquisition", "sale_value", "gain",
+	})
+
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, lot := range report.Lots {
+		fmv := ""
+		if lot.FMV2018 != nil {
+			fmv = f(*lot.FMV2018)
+		}
+		w.Write([]string{
+			lot.Symbol, lot.Exchange, strconv.FormatFloat(lot.Quantity, 'f', -1, 64),
+			lot.BuyDate, f(lot.BuyPrice), lot.SellDate, f(lot.SellPrice),
+			strconv.Itoa(lot.HoldingDays), lot.Term, fmv,
+			f(lot.CostOfAcquisition), f(lot.SaleValue), f(lot.Gain),
+		})
+	}
+
+	w.Write([]string{})
+	w.Write([]string{"total_stcg", f(report.STCG)})
+	w.Write([]string{"total_ltcg", f(report.LTCG)})
+	w.Write([]string{"ltcg_exemption", f(report.LTCGExemption)})
+	w.Write([]string{"taxable_ltcg", f(report.TaxableLTCG)})
+	w.Write([]string{"estimated_tax", f(report.EstimatedTax)})
+	w.Flush()
+}
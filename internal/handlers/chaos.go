@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/chaos"
+)
+
+// chaosPath is exempt from injected latency so a drill can always be ended
+const chaosPath = "/api/system/chaos"
+
+// ChaosLatency holds requests for the latency the chaos controller injects
+func ChaosLatency(ctrl *chaos.Controller) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if strings.HasPrefix(path, chaosPath) {
+			c.Next()
+			return
+		}
+		if d := ctrl.Delay(path); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+			}
+		}
+		c.Next()
+	}
+}
+
+// ChaosHandler injects and clears faults for resilience drills
+type ChaosHandler struct {
+	ctrl *chaos.Controller
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(ctrl *chaos.Controller) *ChaosHandler {
+	return &ChaosHandler{ctrl: ctrl}
+}
+
+// RequireEnabled hides the chaos endpoints unless fault injection is enabled
+func (h *ChaosHandler) RequireEnabled(c *gin.Context) {
+	if h.ctrl.Enabled() {
+		c.Next()
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+		"error": "Fault injection is disabled; set CHAOS_ENABLED=true outside dev",
+	})
+}
+
+// GetChaos handles GET /api/system/chaos
+func (h *ChaosHandler) GetChaos(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ctrl.State())
+}
+
+// SetLatency handles PUT /api/system/chaos/latency.
+// Body: delay_ms (1-60000), jitter_ms (optional, up to 60000), path_prefix
+// (optional, e.g. /api/signals) and duration_seconds (default 300, max 3600).
+func (h *ChaosHandler) SetLatency(c *gin.Context) {
+	var body struct {
+		DelayMs         int    `json:"delay_ms" binding:"required"`
+		JitterMs        int    `json:"jitter_ms"`
+		PathPrefix      string `json:"path_prefix"`
+		DurationSeconds int    `json:"duration_seconds"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delay_ms is required"})
+		return
+	}
+	if body.DelayMs < 1 || body.DelayMs > 60000 || body.JitterMs < 0 || body.JitterMs > 60000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delay_ms must be 1-60000 and jitter_ms 0-60000"})
+		return
+	}
+	if body.PathPrefix != "" && !strings.HasPrefix(body.PathPrefix, "/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path_prefix must start with /"})
+		return
+	}
+
+	h.ctrl.SetLatency(
+		time.Duration(body.DelayMs)*time.Millisecond,
+		time.Duration(body.JitterMs)*time.Millisecond,
+		body.PathPrefix,
+		time.Duration(body.DurationSeconds)*time.Second,
+	)
+	c.JSON(http.StatusOK, h.ctrl.State())
+}
+
+// SetWebSocketDrop handles PUT /api/system/chaos/websocket-drop.
+// Body: percent (0-100, exclusive of 0) and duration_seconds.
+func (h *ChaosHandler) SetWebSocketDrop(c *gin.Context) {
+	var body struct {
+		Percent         float64 `json:"percent" binding:"required"`
+		DurationSeconds int     `json:"duration_seconds"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percent is required"})
+		return
+	}
+	if body.Percent <= 0 || body.Percent > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percent must be greater than 0 and at most 100"})
+		return
+	}
+
+	h.ctrl.SetWebSocketDrop(body.Percent, time.Duration(body.DurationSeconds)*time.Second)
+	c.JSON(http.StatusOK, h.ctrl.State())
+}
+
+// SetNATSDisconnect handles PUT /api/system/chaos/nats-disconnect.
+// Body (optional): duration_seconds. Events are discarded and NATS reports
+// disconnected until it ends; the real connection is left up.
+func (h *ChaosHandler) SetNATSDisconnect(c *gin.Context) {
+	var body struct {
+		DurationSeconds int `json:"duration_seconds"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	h.ctrl.SetNATSDisconnect(time.Duration(body.DurationSeconds) * time.Second)
+	c.JSON(http.StatusOK, h.ctrl.State())
+}
+
+// ClearChaos handles DELETE /api/system/chaos, ending every fault
+func (h *ChaosHandler) ClearChaos(c *gin.Context) {
+	h.ctrl.Clear()
+	c.JSON(http.StatusOK, h.ctrl.State())
+}
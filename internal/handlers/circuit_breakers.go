@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/breaker"
+)
+
+// kiteBreaker names the breaker shared by every Kite API call, so a Kite
+// outage fails fast everywhere once it's detected
+const kiteBreaker = "kite-api"
+
+// kiteClient calls the Kite API
+var kiteClient = breaker.New(kiteBreaker, 10*time.Second).Client()
+
+// GetCircuitBreakers handles GET /api/monitoring/circuit-breakers
+func (h *MonitoringHandler) GetCircuitBreakers(c *gin.Context) {
+	breakers := breaker.Snapshot()
+	open := 0
+	for _, b := range breakers {
+		if b.State != breaker.Closed {
+			open++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"breakers": breakers,
+		"open":     open,
+		"total":    len(breakers),
+	})
+}
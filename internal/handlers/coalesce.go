@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/trading-chitti/core-api-go/internal/coalesce"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// coalescedRepository collapses concurrent identical reads on the endpoints
+// dashboards poll hardest. Keys are built from the parsed arguments, so
+// requests that differ only in parameter order or defaults share a query.
+// Results are shared between callers and must not be mutated. Queries run
+// detached from the first caller's cancellation, so one client disconnecting
+// does not fail the others; the deadline still applies.
+type coalescedRepository struct {
+	database.Repository
+	flight *coalesce.Group
+}
+
+// detach keeps ctx's deadline and values but not its cancellation
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+func newCoalescedRepository(db database.Repository) *coalescedRepository {
+	return &coalescedRepository{Repository: db, flight: &coalesce.Group{}}
+}
+
+func (r *coalescedRepository) GetAllSignals(ctx context.Context, limit int, status string) ([]database.Signal, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("signals|%d|%q", limit, status), func() ([]database.Signal, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetAllSignals(ctx, limit, status)
+	})
+}
+
+func (r *coalescedRepository) GetActiveSignals(ctx context.Context) ([]database.Signal, error) {
+	return coalesce.Do(r.flight, "signals_active", func() ([]database.Signal, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetActiveSignals(ctx)
+	})
+}
+
+func (r *coalescedRepository) GetDashboardData(ctx context.Context, limit int, includeClosed bool) (*database.DashboardData, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("dashboard|%d|%t", limit, includeClosed), func() (*database.DashboardData, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetDashboardData(ctx, limit, includeClosed)
+	})
+}
+
+func (r *coalescedRepository) GetInvestmentSignals(ctx context.Context, minConfidence, minSuccessRate float64, requireSentiment bool) (*database.InvestmentSignalsResponse, error) {
+	key := fmt.Sprintf("investment|%g|%g|%t", minConfidence, minSuccessRate, requireSentiment)
+	return coalesce.Do(r.flight, key, func() (*database.InvestmentSignalsResponse, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetInvestmentSignals(ctx, minConfidence, minSuccessRate, requireSentiment)
+	})
+}
+
+func (r *coalescedRepository) GetSignalAlerts(ctx context.Context, strategy string, minConfidence float64) ([]database.NewsAlert, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("alerts|%q|%g", strategy, minConfidence), func() ([]database.NewsAlert, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetSignalAlerts(ctx, strategy, minConfidence)
+	})
+}
+
+func (r *coalescedRepository) GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string, collapse bool) (*database.NewsResponse, error) {
+	key := fmt.Sprintf("news|%d|%d|%q|%q|%q|%t", limit, offset, sentiment, search, symbol, collapse)
+	return coalesce.Do(r.flight, key, func() (*database.NewsResponse, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetNews(ctx, limit, offset, sentiment, search, symbol, collapse)
+	})
+}
+
+func (r *coalescedRepository) GetTopGainers(ctx context.Context, limit int) ([]database.TopMover, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("gainers|%d", limit), func() ([]database.TopMover, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetTopGainers(ctx, limit)
+	})
+}
+
+func (r *coalescedRepository) GetTopLosers(ctx context.Context, limit int) ([]database.TopMover, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("losers|%d", limit), func() ([]database.TopMover, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetTopLosers(ctx, limit)
+	})
+}
+
+func (r *coalescedRepository) GetMarketIndices(ctx context.Context) ([]database.MarketIndex, error) {
+	return coalesce.Do(r.flight, "indices", func() ([]database.MarketIndex, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetMarketIndices(ctx)
+	})
+}
+
+func (r *coalescedRepository) GetHeatmapQuotes(ctx context.Context) ([]database.HeatmapQuote, error) {
+	return coalesce.Do(r.flight, "heatmap", func() ([]database.HeatmapQuote, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetHeatmapQuotes(ctx)
+	})
+}
+
+func (r *coalescedRepository) GetPortfolioStats(ctx context.Context) (*database.PortfolioStats, error) {
+	return coalesce.Do(r.flight, "portfolio_stats", func() (*database.PortfolioStats, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetPortfolioStats(ctx)
+	})
+}
+
+func (r *coalescedRepository) GetRealtimePrices(ctx context.Context, limit int) ([]database.RealtimePrice, error) {
+	return coalesce.Do(r.flight, fmt.Sprintf("prices|%d", limit), func() ([]database.RealtimePrice, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return r.Repository.GetRealtimePrices(ctx, limit)
+	})
+}
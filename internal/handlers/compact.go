@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/compact"
+)
+
+// compactWriter holds a response back so it can be rewritten once the
+// handler is done
+type compactWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *compactWriter) WriteHeader(code int) { w.status = code }
+func (w *compactWriter) WriteHeaderNow()      {}
+func (w *compactWriter) Status() int          { return w.status }
+func (w *compactWriter) Written() bool        { return w.body.Len() > 0 }
+func (w *compactWriter) Size() int            { return w.body.Len() }
+func (w *compactWriter) Flush()               {}
+
+func (w *compactWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *compactWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// CompactMiddleware rewrites successful JSON GET responses in compact form
+// when the request has ?compact=true: short field names and no null or zero
+// values. Compact responses carry an X-Payload-Mode: compact header.
+// Large lists that would otherwise stream are buffered; NDJSON is left as is.
+func CompactMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || wantsNDJSON(c) {
+			c.Next()
+			return
+		}
+		if on, _ := strconv.ParseBool(c.Query("compact")); !on {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &compactWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		body := w.body.Bytes()
+		if w.status == http.StatusOK && strings.HasPrefix(original.Header().Get("Content-Type"), "application/json") {
+			if compacted, err := compact.Encode(body); err == nil {
+				body = compacted
+				original.Header().Set("X-Payload-Mode", "compact")
+			} else {
+				log.Printf("⚠️  Failed to compact %s: %v", c.Request.URL.Path, err)
+			}
+		}
+		original.Header().Del("Content-Length")
+		original.WriteHeader(w.status)
+		original.Write(body)
+	}
+}
+
+// GetCompactKeys handles GET /api/compact/keys, the legend for expanding
+// compact payloads
+func GetCompactKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": compact.Keys()})
+}
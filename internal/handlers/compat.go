@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/compat"
+)
+
+// CompatHandler exposes response-shape parity checks against the Python API
+type CompatHandler struct {
+	checker *compat.Checker
+}
+
+// NewCompatHandler creates a new compat handler
+func NewCompatHandler(checker *compat.Checker) *CompatHandler {
+	return &CompatHandler{checker: checker}
+}
+
+// CompatRecordRequest names the path to record
+type CompatRecordRequest struct {
+	Path string `json:"path"`
+}
+
+// Diff handles GET /api/compat/diff?path=. Without a path, every recorded
+// fixture is compared.
+func (h *CompatHandler) Diff(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	path := c.Query("path")
+	if path == "" {
+		reports, err := h.checker.DiffAll(ctx, requestUserID(c))
+		if err != nil {
+			log.Printf("❌ Failed to list compat fixtures: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read fixtures"})
+			return
+		}
+		incompatible := 0
+		for _, r := range reports {
+			if !r.Compatible {
+				incompatible++
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"reports":      reports,
+			"count":        len(reports),
+			"incompatible": incompatible,
+			"compatible":   incompatible == 0,
+		})
+		return
+	}
+
+	if err := compat.ValidatePath(path); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.checker.HasReference(path) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No legacy API configured and no fixture recorded for " + path})
+		return
+	}
+	c.JSON(http.StatusOK, h.checker.Diff(ctx, path, requestUserID(c)))
+}
+
+// Record handles POST /api/compat/record
+func (h *CompatHandler) Record(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var req CompatRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := compat.ValidatePath(req.Path); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.checker.LegacyConfigured() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "LEGACY_API_URL is not set"})
+		return
+	}
+
+	fixture, err := h.checker.Record(ctx, req.Path, requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to record fixture for %s: %v", req.Path, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to record fixture"})
+		return
+	}
+	log.Printf("✅ Recorded compat fixture for %s", req.Path)
+	c.JSON(http.StatusCreated, fixture)
+}
+
+// ListFixtures handles GET /api/compat/fixtures
+func (h *CompatHandler) ListFixtures(c *gin.Context) {
+	fixtures, err := h.checker.Fixtures()
+	if err != nil {
+		log.Printf("❌ Failed to list compat fixtures: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read fixtures"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"fixtures":          fixtures,
+		"count":             len(fixtures),
+		"legacy_configured": h.checker.LegacyConfigured(),
+	})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+)
+
+// maxConfigBundleSize caps an uploaded config bundle
+const maxConfigBundleSize = 20 << 20
+
+// validFetchers are the market data fetchers a stock can be assigned to
+var validFetchers = map[string]bool{"ZERODHA": true, "INDMONEY": true}
+
+// ConfigBundleHandler exports and imports environment configuration
+type ConfigBundleHandler struct {
+	db database.ConfigBundleRepository
+}
+
+// NewConfigBundleHandler creates a new config bundle handler
+func NewConfigBundleHandler(db database.ConfigBundleRepository) *ConfigBundleHandler {
+	return &ConfigBundleHandler{db: db}
+}
+
+// ExportConfig handles GET /api/system/config/export
+func (h *ConfigBundleHandler) ExportConfig(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	bundle, err := h.db.ExportConfigBundle(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to export config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export config"})
+		return
+	}
+
+	filename := "config-" + time.Now().Format("20060102-150405") + ".json"
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportConfig handles POST /api/system/config/import. The body is a bundle
+// from the export endpoint. The whole bundle is validated before anything is
+// written and applied in one transaction; dry_run=true reports the changes
+// without making them.
+func (h *ConfigBundleHandler) ImportConfig(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	var bundle database.ConfigBundle
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, maxConfigBundleSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config bundle", "details": err.Error()})
+		return
+	}
+
+	nextRuns, problems := validateConfigBundle(&bundle, time.Now())
+	if len(problems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Config bundle failed validation", "problems": problems})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := h.db.ImportConfigBundle(ctx, &bundle, nextRuns, dryRun)
+	if err != nil {
+		log.Printf("❌ Failed to import config: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import config"})
+		return
+	}
+	if !dryRun {
+		log.Printf("✅ Config bundle exported at %s imported", bundle.ExportedAt)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// validateConfigBundle checks every section, normalising values in place,
+// and returns the next send time of each notification channel along with
+// any problems found
+func validateConfigBundle(b *database.ConfigBundle, now time.Time) ([]time.Time, []string) {
+	problems := []string{}
+	add := func(format string, args ...interface{}) {
+		if len(problems) < 100 {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if b.Format != database.ConfigBundleFormat {
+		add("format: must be %q", database.ConfigBundleFormat)
+	}
+	if b.Version != database.ConfigBundleVersion {
+		add("version: %d is not supported, expected %d", b.Version, database.ConfigBundleVersion)
+	}
+
+	keys := map[string]bool{}
+	for i := range b.SystemConfig {
+		e := &b.SystemConfig[i]
+		e.Key = strings.TrimSpace(e.Key)
+		switch {
+		case e.Key == "":
+			add("system_config[%d].key: required", i)
+		case keys[e.Key]:
+			add("system_config[%d].key: %q appears more than once", i, e.Key)
+		}
+		keys[e.Key] = true
+	}
+
+	stocks := map[string]bool{}
+	for i := range b.StockConfig {
+		t := &b.StockConfig[i]
+		t.Symbol = strings.ToUpper(strings.TrimSpace(t.Symbol))
+		t.Exchange = strings.ToUpper(strings.TrimSpace(t.Exchange))
+		if t.Exchange == "" {
+			t.Exchange = "NSE"
+		}
+		key := t.Symbol + ":" + t.Exchange
+		switch {
+		case t.Symbol == "":
+			add("stock_config[%d].symbol: required", i)
+		case stocks[key]:
+			add("stock_config[%d]: %s appears more than once", i, key)
+		}
+		stocks[key] = true
+		if t.Fetcher != nil {
+			fetcher := strings.ToUpper(strings.TrimSpace(*t.Fetcher))
+			if !validFetchers[fetcher] {
+				add("stock_config[%d].fetcher: must be ZERODHA or INDMONEY", i)
+			}
+			t.Fetcher = &fetcher
+		}
+	}
+
+	for i := range b.AlertRules {
+		r := &b.AlertRules[i]
+		r.Condition = strings.ToUpper(strings.TrimSpace(r.Condition))
+		if r.UserID == "" || r.Basket == "" {
+			add("alert_rules[%d]: user_id and basket are required", i)
+		}
+		if !database.ValidBasketAlertCondition(r.Condition) {
+			add("alert_rules[%d].condition: unknown condition %q", i, r.Condition)
+		}
+	}
+
+	nextRuns := make([]time.Time, len(b.NotificationChannels))
+	for i := range b.NotificationChannels {
+		ch := &b.NotificationChannels[i]
+		ch.ReportType = strings.ToLower(strings.TrimSpace(ch.ReportType))
+		ch.Channel = strings.ToLower(strings.TrimSpace(ch.Channel))
+		ch.Destination = strings.TrimSpace(ch.Destination)
+		if ch.UserID == "" {
+			add("notification_channels[%d].user_id: required", i)
+		}
+		if !reports.ValidType(ch.ReportType) {
+			add("notification_channels[%d].report_type: unknown report type %q", i, ch.ReportType)
+			continue
+		}
+		switch ch.Channel {
+		case reports.ChannelEmail:
+			if _, err := mail.ParseAddress(ch.Destination); err != nil {
+				add("notification_channels[%d].destination: not an email address", i)
+			}
+		case reports.ChannelTelegram:
+			if !telegramChatPattern.MatchString(ch.Destination) {
+				add("notification_channels[%d].destination: not a Telegram chat ID or @channel", i)
+			}
+		default:
+			add("notification_channels[%d].channel: must be email or telegram", i)
+		}
+		if reports.Weekly(ch.ReportType) {
+			if ch.Weekday == nil || *ch.Weekday < 0 || *ch.Weekday > 6 {
+				add("notification_channels[%d].weekday: 0 to 6 is required for %s", i, ch.ReportType)
+				continue
+			}
+		} else {
+			ch.Weekday = nil
+		}
+		next, err := reports.NextRun(ch.ReportType, ch.SendTime, ch.Weekday, now)
+		if err != nil {
+			add("notification_channels[%d].send_time: %v", i, err)
+			continue
+		}
+		nextRuns[i] = next
+	}
+
+	return nextRuns, problems
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Coverage gap kinds
+const (
+	gapNoData  = "no_data"  // neither ticks nor bars
+	gapNoTicks = "no_ticks" // bars but no raw ticks
+	gapNoBars  = "no_bars"  // ticks that were never rolled up
+	gapStale   = "stale"    // ticks stopped during today's session
+)
+
+// CoverageHandler reports which intraday symbols are actually receiving data
+type CoverageHandler struct {
+	db database.CoverageRepository
+}
+
+// NewCoverageHandler creates a new coverage handler
+func NewCoverageHandler(db database.CoverageRepository) *CoverageHandler {
+	return &CoverageHandler{db: db}
+}
+
+// coverageGap is an enabled symbol missing data
+type coverageGap struct {
+	database.SymbolCoverage
+	Gap string `json:"gap"`
+}
+
+// GetCoverage handles GET /api/monitoring/coverage.
+// Query: date (YYYY-MM-DD IST, default today), stale_minutes (default 15)
+// and all (true also lists covered symbols). Every active intraday-enabled
+// symbol is checked for ticks and 1-minute bars on the day; during today's
+// session a symbol whose last tick is older than stale_minutes is a gap too.
+func (h *CoverageHandler) GetCoverage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now().In(ist)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ist)
+	if v := c.Query("date"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, ist)
+		if err != nil || d.After(day) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD and not in the future"})
+			return
+		}
+		day = d
+	}
+	staleMinutes, err := strconv.Atoi(c.DefaultQuery("stale_minutes", "15"))
+	if err != nil || staleMinutes < 1 || staleMinutes > 375 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stale_minutes must be between 1 and 375"})
+		return
+	}
+	showAll := c.Query("all") == "true"
+
+	coverage, err := h.db.GetIntradayCoverage(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("❌ Failed to compute intraday coverage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute coverage"})
+		return
+	}
+
+	// Staleness only means something while today's session is open
+	sessionOpen := day.Add(9*time.Hour + 15*time.Minute)
+	sessionClose := day.Add(15*time.Hour + 30*time.Minute)
+	checkStale := now.After(sessionOpen.Add(time.Duration(staleMinutes)*time.Minute)) && now.Before(sessionClose)
+	staleBefore := now.Add(-time.Duration(staleMinutes) * time.Minute)
+
+	gaps := []coverageGap{}
+	covered := []database.SymbolCoverage{}
+	withTicks, withBars := 0, 0
+	for _, s := range coverage.Symbols {
+		if s.Ticks > 0 {
+			withTicks++
+		}
+		if s.Bars > 0 {
+			withBars++
+		}
+		gap := ""
+		switch {
+		case s.Ticks == 0 && s.Bars == 0:
+			gap = gapNoData
+		case s.Ticks == 0:
+			gap = gapNoTicks
+		case s.Bars == 0:
+			gap = gapNoBars
+		case checkStale && s.LastTick.Before(staleBefore):
+			gap = gapStale
+		}
+		if gap != "" {
+			gaps = append(gaps, coverageGap{SymbolCoverage: s, Gap: gap})
+		} else if showAll {
+			covered = append(covered, s)
+		}
+	}
+
+	enabled := len(coverage.Symbols)
+	coveragePct := 0.0
+	if enabled > 0 {
+		coveragePct = math.Round(float64(enabled-len(gaps))/float64(enabled)*10000) / 100
+	}
+
+	response := gin.H{
+		"date":              day.Format("2006-01-02"),
+		"enabled":           enabled,
+		"with_ticks":        withTicks,
+		"with_bars":         withBars,
+		"coverage_pct":      coveragePct,
+		"gaps":              gaps,
+		"gap_count":         len(gaps),
+		"unexpected":        coverage.Unexpected,
+		"stale_minutes":     staleMinutes,
+		"staleness_checked": checkStale,
+		"timestamp":         time.Now().Format(time.RFC3339),
+	}
+	if showAll {
+		response["covered"] = covered
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/dataquality"
+)
+
+// DataQualityHandler exposes market data held back by the rules engine
+type DataQualityHandler struct {
+	engine *dataquality.Engine
+}
+
+// NewDataQualityHandler creates a new data quality handler
+func NewDataQualityHandler(engine *dataquality.Engine) *DataQualityHandler {
+	return &DataQualityHandler{engine: engine}
+}
+
+// ListQuarantine handles GET /api/monitoring/quarantine.
+// Query: symbol, rule (non_positive_price, price_jump or volume_regression)
+// and limit (default 100, max 1000). Items are newest first; stats count
+// everything checked since startup.
+func (h *DataQualityHandler) ListQuarantine(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+	rule := c.Query("rule")
+	switch rule {
+	case "", dataquality.RuleNonPositivePrice, dataquality.RulePriceJump, dataquality.RuleVolumeRegression:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rule must be non_positive_price, price_jump or volume_regression"})
+		return
+	}
+
+	items := h.engine.List(c.Query("symbol"), rule, limit)
+	c.JSON(http.StatusOK, gin.H{
+		"items":     items,
+		"count":     len(items),
+		"stats":     h.engine.Stats(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// ReleaseQuarantined handles POST /api/monitoring/quarantine/:id/release.
+// Marks the item a false positive and accepts its price as the symbol's new
+// baseline so the ticks that follow aren't held back.
+func (h *DataQualityHandler) ReleaseQuarantined(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quarantine ID"})
+		return
+	}
+
+	item, ok := h.engine.Release(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quarantined item not found"})
+		return
+	}
+	log.Printf("✅ Released quarantined %s %s at %g by %s", item.Symbol, item.Rule, item.Price, requestActor(c))
+	c.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// DatabaseHandler exposes the connection pools and timeout policy
+type DatabaseHandler struct {
+	db *database.DB
+}
+
+// NewDatabaseHandler creates a new database handler
+func NewDatabaseHandler(db *database.DB) *DatabaseHandler {
+	return &DatabaseHandler{db: db}
+}
+
+// GetDatabase handles GET /api/system/database
+func (h *DatabaseHandler) GetDatabase(c *gin.Context) {
+	policy := h.db.TimeoutPolicy()
+
+	pools := gin.H{}
+	for name, stats := range h.db.PoolStats() {
+		pools[name] = gin.H{
+			"max_open":         stats.MaxOpenConnections,
+			"open":             stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+			"wait_count":       stats.WaitCount,
+			"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statement_timeouts": gin.H{
+			"interactive_seconds": policy.Interactive.Seconds(),
+			"analytics_seconds":   policy.Analytics.Seconds(),
+			"maintenance_seconds": 0,
+		},
+		"analytics_max_conns": policy.AnalyticsMaxConns,
+		"pools":               pools,
+		"prepared_statements": h.db.PreparedStatementStats(),
+		"timescale":           h.db.TimescaleEnabled(),
+	})
+}
+
+// GetIndexAdvice handles GET /api/system/database/indexes, explaining the
+// hot dashboard, history, top mover and news queries (estimates only, no
+// ANALYZE) and listing the indexes they're missing. plans=true includes
+// each full plan.
+func (h *DatabaseHandler) GetIndexAdvice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	advice, err := h.db.AdviseIndexes(ctx, c.Query("plans") == "true")
+	if err != nil {
+		log.Printf("❌ Failed to explain hot queries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to explain hot queries"})
+		return
+	}
+
+	missing := []database.IndexSuggestion{}
+	seen := map[string]bool{}
+	for _, a := range advice {
+		for _, m := range a.MissingIndexes {
+			if !seen[m.Statement] {
+				seen[m.Statement] = true
+				missing = append(missing, m)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queries":         advice,
+		"count":           len(advice),
+		"missing_indexes": missing,
+	})
+}
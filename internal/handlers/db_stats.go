@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/dbstats"
+)
+
+// dbStatsWriter stamps X-DB-Stats on the response just before its headers
+// go out, so the header covers the queries made up to the first write
+type dbStatsWriter struct {
+	gin.ResponseWriter
+	stats   *dbstats.Stats
+	stamped bool
+}
+
+func (w *dbStatsWriter) stamp() {
+	if !w.stamped && !w.ResponseWriter.Written() {
+		w.stamped = true
+		w.Header().Set("X-DB-Stats", w.stats.Summary().Header())
+	}
+}
+
+func (w *dbStatsWriter) WriteHeaderNow() {
+	w.stamp()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *dbStatsWriter) Write(data []byte) (int, error) {
+	w.stamp()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *dbStatsWriter) WriteString(s string) (int, error) {
+	w.stamp()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *dbStatsWriter) Flush() {
+	w.stamp()
+	w.ResponseWriter.Flush()
+}
+
+// DBStatsMiddleware counts each request's queries, rows, values read and
+// database time into an X-DB-Stats header and the tracker, and logs
+// requests that repeat a query shape dbstats.RepeatThreshold times or more.
+// It is only installed in dev.
+func DBStatsMiddleware(tracker *dbstats.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, stats := dbstats.WithStats(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		w := &dbStatsWriter{ResponseWriter: original, stats: stats}
+		c.Writer = w
+		c.Next()
+		w.stamp()
+		c.Writer = original
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		sum := stats.Summary()
+		tracker.Add(c.Request.Method+" "+route, sum)
+		if len(sum.Repeated) > 0 {
+			worst := sum.Repeated[0]
+			log.Printf("⚠️  %s %s ran one query %d times (%d queries in all), likely N+1: %s",
+				c.Request.Method, route, worst.Count, sum.Queries, worst.Query)
+		}
+	}
+}
+
+// DBStatsHandler reports per-route database work collected in dev
+type DBStatsHandler struct {
+	tracker *dbstats.Tracker
+}
+
+// NewDBStatsHandler creates a new database stats handler
+func NewDBStatsHandler(tracker *dbstats.Tracker) *DBStatsHandler {
+	return &DBStatsHandler{tracker: tracker}
+}
+
+// GetDBStats handles GET /api/system/db-stats, listing routes that repeated
+// a query shape first, then by average queries per request
+func (h *DBStatsHandler) GetDBStats(c *gin.Context) {
+	routes := h.tracker.Routes()
+	flagged := 0
+	for _, r := range routes {
+		if r.NPlusOne > 0 {
+			flagged++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"routes":           routes,
+		"count":            len(routes),
+		"n_plus_one":       flagged,
+		"repeat_threshold": dbstats.RepeatThreshold,
+	})
+}
+
+// ResetDBStats handles DELETE /api/system/db-stats
+func (h *DBStatsHandler) ResetDBStats(c *gin.Context) {
+	h.tracker.Reset()
+	c.JSON(http.StatusOK, gin.H{"message": "Database stats reset"})
+}
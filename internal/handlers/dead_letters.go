@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Reprocessor replays a stored event payload through the NATS handlers
+type Reprocessor interface {
+	Reprocess(subject string, data []byte) error
+}
+
+// DeadLetterHandler lists and reprocesses NATS events that failed to decode
+type DeadLetterHandler struct {
+	db          database.DeadLetterRepository
+	reprocessor Reprocessor
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler. reprocessor may be
+// nil when NATS is not connected.
+func NewDeadLetterHandler(db database.DeadLetterRepository, reprocessor Reprocessor) *DeadLetterHandler {
+	return &DeadLetterHandler{db: db, reprocessor: reprocessor}
+}
+
+// ListDeadLetters handles GET /api/monitoring/events/dead-letter
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	pending := c.DefaultQuery("pending", "true") == "true"
+
+	letters, err := h.db.ListDeadLetters(ctx, c.Query("subject"), pending, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list dead letters: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+		"count":        len(letters),
+	})
+}
+
+// ReprocessDeadLetter handles POST /api/monitoring/events/dead-letter/:id/reprocess.
+// The stored payload is replayed unless the body supplies a corrected
+// {"payload": ...} to use instead.
+func (h *DeadLetterHandler) ReprocessDeadLetter(c *gin.Context) {
+	if h.reprocessor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "NATS is not connected"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dead letter ID"})
+		return
+	}
+
+	var req struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	letter, err := h.db.GetDeadLetter(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get dead letter %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead letter"})
+		return
+	}
+	if letter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+		return
+	}
+	if letter.ReprocessedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Dead letter was already reprocessed"})
+		return
+	}
+
+	payload := []byte(letter.Payload)
+	if len(req.Payload) > 0 {
+		payload = req.Payload
+	}
+
+	reprocessErr := h.reprocessor.Reprocess(letter.Subject, payload)
+	if err := h.db.RecordDeadLetterAttempt(ctx, id, reprocessErr); err != nil {
+		log.Printf("❌ Failed to update dead letter %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dead letter"})
+		return
+	}
+
+	if reprocessErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Payload still fails to decode",
+			"details": reprocessErr.Error(),
+		})
+		return
+	}
+
+	log.Printf("✅ Reprocessed dead letter %d (%s)", id, letter.Subject)
+	c.JSON(http.StatusOK, gin.H{
+		"id":          id,
+		"subject":     letter.Subject,
+		"reprocessed": true,
+	})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPortfolioDividends handles GET /api/portfolios/:id/dividends
+func (h *Handler) GetPortfolioDividends(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	report, err := h.db.GetPortfolioDividends(ctx, portfolio.ID)
+	if err != nil {
+		log.Printf("❌ Failed to compute dividends for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute dividends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
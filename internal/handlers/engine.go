@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/events"
+)
+
+// Requester sends a NATS request and returns the reply payload
+type Requester interface {
+	Request(subject string, data []byte, timeout time.Duration) ([]byte, error)
+}
+
+// EngineHandler relays on-demand actions to downstream services over NATS
+// request-reply, so interventions during market hours do not need SSH
+type EngineHandler struct {
+	nats Requester
+}
+
+// NewEngineHandler creates a new engine handler. nats may be nil when the
+// connection failed; every action then returns 503.
+func NewEngineHandler(nats Requester) *EngineHandler {
+	return &EngineHandler{nats: nats}
+}
+
+// ListCommands handles GET /api/system/commands
+func (h *EngineHandler) ListCommands(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"commands":       events.Commands,
+		"nats_connected": h.nats != nil,
+	})
+}
+
+// Command returns a handler for one service action. The request body, if
+// any, is forwarded as the payload together with the route parameter.
+func (h *EngineHandler) Command(service, name string) gin.HandlerFunc {
+	cmd, ok := events.FindCommand(service, name)
+	if !ok {
+		log.Fatalf("❌ Unknown NATS command %s/%s", service, name)
+	}
+
+	return func(c *gin.Context) {
+		if h.nats == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "NATS is not connected"})
+			return
+		}
+
+		payload := map[string]interface{}{}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&payload); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+				return
+			}
+		}
+		if cmd.Param != "" {
+			value := strings.ToUpper(strings.TrimSpace(c.Param(cmd.Param)))
+			if value == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": cmd.Param + " is required"})
+				return
+			}
+			payload[cmd.Param] = value
+		}
+		payload["requested_by"] = requestActor(c)
+		payload["requested_at"] = time.Now().Format(time.RFC3339)
+
+		data, _ := json.Marshal(payload)
+		start := time.Now()
+		reply, err := h.nats.Request(cmd.Subject, data, cmd.Timeout)
+		elapsed := time.Since(start).Milliseconds()
+		if err != nil {
+			log.Printf("❌ %s %s (%s) by %s failed: %v", cmd.Service, cmd.Name, cmd.Subject, requestActor(c), err)
+			switch {
+			case events.IsNoResponders(err):
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No " + cmd.Service + " instance is listening on " + cmd.Subject})
+			case events.IsTimeout(err):
+				c.JSON(http.StatusGatewayTimeout, gin.H{"error": cmd.Service + " did not reply within " + cmd.Timeout.String()})
+			default:
+				c.JSON(http.StatusBadGateway, gin.H{"error": "Request to " + cmd.Service + " failed"})
+			}
+			return
+		}
+
+		log.Printf("✅ %s %s (%s) by %s replied in %dms", cmd.Service, cmd.Name, cmd.Subject, requestActor(c), elapsed)
+		var body interface{} = string(reply)
+		if json.Valid(reply) {
+			body = json.RawMessage(reply)
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"service":     cmd.Service,
+			"command":     cmd.Name,
+			"subject":     cmd.Subject,
+			"duration_ms": elapsed,
+			"reply":       body,
+		})
+	}
+}
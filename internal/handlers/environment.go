@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/environment"
+)
+
+// requestRole returns the caller's role, set by the gateway alongside X-User-ID
+func requestRole(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader("X-User-Role"))
+}
+
+// ProdAdminOnly guards endpoints that run jobs or control other services:
+// in prod they need the prod-admin role, elsewhere anyone may use them
+func ProdAdminOnly(env environment.Environment) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowProdAdmin(c, env) {
+			c.Next()
+		}
+	}
+}
+
+// allowProdAdmin is the ProdAdminOnly check for handlers that only guard some
+// requests; it writes the 403 itself and returns false when refused
+func allowProdAdmin(c *gin.Context, env environment.Environment) bool {
+	if env.Allowed(requestRole(c)) {
+		return true
+	}
+	log.Printf("⚠️  %s %s refused for %s in %s: %s role required",
+		c.Request.Method, c.Request.URL.Path, requestUserID(c), env.Name, environment.ProdAdminRole)
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":       "This action requires the " + environment.ProdAdminRole + " role in " + env.Name,
+		"environment": env.Name,
+	})
+	return false
+}
+
+// DevOnly hides test helpers outside dev
+func DevOnly(env environment.Environment) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if env.TestHelpersEnabled() {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error":       "Test helpers are only available in " + environment.Dev,
+			"environment": env.Name,
+		})
+	}
+}
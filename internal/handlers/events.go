@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/events"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// EventStatsProvider reports per-subject event metrics
+type EventStatsProvider interface {
+	EventStats() []events.SubjectStats
+}
+
+// EventsHandler exposes NATS event throughput and lag
+type EventsHandler struct {
+	stats EventStatsProvider
+	hub   *ws.Hub
+}
+
+// NewEventsHandler creates a new events handler. stats may be nil when NATS
+// is not connected.
+func NewEventsHandler(stats EventStatsProvider, hub *ws.Hub) *EventsHandler {
+	return &EventsHandler{stats: stats, hub: hub}
+}
+
+// GetEvents handles GET /api/monitoring/events
+func (h *EventsHandler) GetEvents(c *gin.Context) {
+	subjects := []events.SubjectStats{}
+	if h.stats != nil {
+		subjects = h.stats.EventStats()
+	}
+
+	// Silent subjects usually mean a producer has stopped publishing
+	silent := []string{}
+	for _, s := range subjects {
+		if s.Status != "active" {
+			silent = append(silent, s.Subject)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"nats_connected": h.stats != nil,
+		"subjects":       subjects,
+		"silent":         silent,
+		"hub":            h.hub.Stats(),
+		"timestamp":      time.Now().Format(time.RFC3339),
+	})
+}
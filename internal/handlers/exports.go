@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/exports"
+)
+
+// ExportsHandler handles bulk data export endpoints
+type ExportsHandler struct {
+	db      database.ExportRepository
+	manager *exports.Manager
+}
+
+// NewExportsHandler creates a new exports handler
+func NewExportsHandler(db database.ExportRepository, manager *exports.Manager) *ExportsHandler {
+	return &ExportsHandler{db: db, manager: manager}
+}
+
+// CreateExport handles POST /api/exports
+func (h *ExportsHandler) CreateExport(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var req database.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	req.Dataset = strings.ToLower(strings.TrimSpace(req.Dataset))
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+	for i, s := range req.Symbols {
+		req.Symbols[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+
+	job, err := h.manager.Submit(ctx, requestUserID(c), requestAccountID(c), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListExports handles GET /api/exports
+func (h *ExportsHandler) ListExports(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	jobs, err := h.db.ListExportJobs(ctx, requestUserID(c), 50)
+	if err != nil {
+		log.Printf("❌ Failed to list exports: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list exports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"exports":  jobs,
+		"count":    len(jobs),
+		"datasets": database.ExportDatasetNames(),
+		"formats":  exports.FormatNames(),
+	})
+}
+
+// GetExport handles GET /api/exports/:id
+func (h *ExportsHandler) GetExport(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	job, err := h.db.GetExportJob(ctx, requestUserID(c), c.Param("id"))
+	if err != nil {
+		log.Printf("❌ Failed to get export %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get export"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	response := gin.H{"export": job}
+	if job.Status == database.ExportStatusCompleted {
+		url, err := h.manager.DownloadURL(job)
+		if err != nil {
+			log.Printf("❌ Failed to sign download for export %s: %v", job.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create download link"})
+			return
+		}
+		response["download_url"] = url
+		response["download_expires_at"] = time.Now().Add(exports.DownloadURLTTL).Format(time.RFC3339)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
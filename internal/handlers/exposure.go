@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// defaultSignalNotional is the capital assumed per open signal (INR)
+const defaultSignalNotional = 100000
+
+// ExposureHandler serves the position exposure view shared by the risk-limit
+// engine and the dashboard
+type ExposureHandler struct {
+	db database.ExposureRepository
+}
+
+// NewExposureHandler creates a new exposure handler
+func NewExposureHandler(db database.ExposureRepository) *ExposureHandler {
+	return &ExposureHandler{db: db}
+}
+
+// GetExposure handles GET /api/portfolio/exposure.
+// Query: signal_notional (INR per active signal, default 100000). Returns the
+// caller's holdings and active signals with notional and risk-weighted
+// exposure by sector, market-cap tier and direction.
+func (h *ExposureHandler) GetExposure(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	notional, err := strconv.ParseFloat(c.DefaultQuery("signal_notional", strconv.Itoa(defaultSignalNotional)), 64)
+	if err != nil || notional <= 0 || notional > 1e9 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "signal_notional must be a positive amount up to 1000000000"})
+		return
+	}
+
+	accountID := requestAccountID(c)
+	exposure, err := h.db.GetExposure(ctx, accountID, notional)
+	if err != nil {
+		log.Printf("❌ Failed to compute exposure for %s: %v", accountID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute exposure"})
+		return
+	}
+	c.JSON(http.StatusOK, exposure)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// maxFollows caps how many symbols and strategies one account may follow
+const maxFollows = 500
+
+// followValuePattern bounds followed symbols and strategy names
+var followValuePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.&-]{0,63}$`)
+
+// normalizeFollow validates a follow's kind and value, upper-casing symbols
+func normalizeFollow(kind, value string) (string, string, bool) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	value = strings.TrimSpace(value)
+	switch kind {
+	case database.FollowSymbol:
+		value = strings.ToUpper(value)
+	case database.FollowStrategy:
+	default:
+		return "", "", false
+	}
+	return kind, value, followValuePattern.MatchString(value)
+}
+
+// wsFollows converts a user's follows for the WebSocket hub
+func wsFollows(follows []database.Follow) *ws.Follows {
+	var symbols, strategies []string
+	for _, f := range follows {
+		if f.Kind == database.FollowSymbol {
+			symbols = append(symbols, f.Value)
+		} else {
+			strategies = append(strategies, f.Value)
+		}
+	}
+	return ws.NewFollows(symbols, strategies)
+}
+
+// followedSignals streams only the signals accountID follows, so
+// ?mine=true can be applied under every signal list path
+type followedSignals struct {
+	db        database.FollowRepository
+	accountID string
+}
+
+func (f followedSignals) StreamSignals(ctx context.Context, limit int, status string, fn func(database.Signal) error) error {
+	return f.db.StreamFollowedSignals(ctx, f.accountID, limit, status, fn)
+}
+
+// refreshFollows pushes an account's current follows to the WebSocket
+// connections acting in it
+func (h *Handler) refreshFollows(ctx context.Context, accountID string) {
+	follows, err := h.db.ListFollows(ctx, accountID)
+	if err != nil {
+		log.Printf("⚠️  Failed to refresh WebSocket follows for %s: %v", accountID, err)
+		return
+	}
+	h.hub.SetFollows(accountID, wsFollows(follows))
+}
+
+// ListFollows handles GET /api/follows
+func (h *Handler) ListFollows(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	follows, err := h.db.ListFollows(ctx, requestAccountID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list follows: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve follows"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"follows": follows, "count": len(follows)})
+}
+
+// AddFollow handles POST /api/follows. Body: kind (symbol or strategy) and
+// value. Following something already followed is not an error.
+func (h *Handler) AddFollow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	kind, value, ok := normalizeFollow(body.Kind, body.Value)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be symbol or strategy, with a value of up to 64 letters, digits or _.&-"})
+		return
+	}
+
+	accountID := requestAccountID(c)
+	follows, err := h.db.ListFollows(ctx, accountID)
+	if err != nil {
+		log.Printf("❌ Failed to list follows: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add follow"})
+		return
+	}
+	if len(follows) >= maxFollows {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("At most %d follows per account", maxFollows)})
+		return
+	}
+	added, err := h.db.AddFollow(ctx, accountID, requestUserID(c), kind, value)
+	if err != nil {
+		log.Printf("❌ Failed to add follow: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add follow"})
+		return
+	}
+	if added {
+		h.refreshFollows(ctx, accountID)
+	}
+	c.JSON(http.StatusOK, gin.H{"kind": kind, "value": value, "added": added})
+}
+
+// RemoveFollow handles DELETE /api/follows/:kind/:value
+func (h *Handler) RemoveFollow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	kind, value, ok := normalizeFollow(c.Param("kind"), c.Param("value"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be symbol or strategy"})
+		return
+	}
+	accountID := requestAccountID(c)
+	removed, err := h.db.RemoveFollow(ctx, accountID, kind, value)
+	if err != nil {
+		log.Printf("❌ Failed to remove follow: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove follow"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not following " + kind + " " + value})
+		return
+	}
+	h.refreshFollows(ctx, accountID)
+	c.JSON(http.StatusOK, gin.H{"kind": kind, "value": value, "removed": true})
+}
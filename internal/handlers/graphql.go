@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/graphql"
+)
+
+// maxGraphQLBody bounds a GraphQL request body; real dashboard queries are
+// a few KB
+const maxGraphQLBody = 64 << 10
+
+// GraphQLHandler serves the dashboard query graph
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(db *database.DB) *GraphQLHandler {
+	return &GraphQLHandler{schema: graphql.NewSchema(db)}
+}
+
+// Query handles GET and POST /graphql
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "variables must be a JSON object"}}})
+				return
+			}
+		}
+	} else {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxGraphQLBody)
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, graphql.Response{Errors: []graphql.Error{{Message: "Request body too large"}}})
+				return
+			}
+			c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "Invalid request body"}}})
+			return
+		}
+	}
+
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "query is required"}}})
+		return
+	}
+	if len(req.Query) > maxGraphQLBody {
+		c.JSON(http.StatusRequestEntityTooLarge, graphql.Response{Errors: []graphql.Error{{Message: "query too large"}}})
+		return
+	}
+
+	resp := h.schema.Execute(graphql.WithAccountID(ctx, requestAccountID(c)), req)
+	if resp.Data == nil {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/guest"
+	"github.com/trading-chitti/core-api-go/internal/serviceauth"
+)
+
+// guestKey holds the guest ID of a request made with a guest token
+const guestKey = "guest"
+
+// Guest token lifetimes
+const (
+	defaultGuestTTLMinutes = 60
+	maxGuestTTLMinutes     = 7 * 24 * 60
+)
+
+// guestRoutes are what a guest token may read: market data, news, scans
+// and signal stats. Anything account-owned, mutating, or about brokers,
+// config or the system stays out.
+var guestRoutes = map[string]bool{
+	"/":                               true,
+	"/health":                         true,
+	"/ws":                             true,
+	"/api/compact/keys":               true,
+	"/api/status":                     true,
+	"/api/portfolio/stats":            true,
+	"/api/stocks/top-gainers":         true,
+	"/api/stocks/top-losers":          true,
+	"/api/stocks/realtime/all":        true,
+	"/api/stocks/search":              true,
+	"/api/stocks/:symbol/realtime":    true,
+	"/api/stocks/:symbol/candles":     true,
+	"/api/stocks/:symbol/volatility":  true,
+	"/api/stocks/:symbol":             true,
+	"/api/news":                       true,
+	"/api/news/clusters/:id":          true,
+	"/api/signals":                    true,
+	"/api/signals/active":             true,
+	"/api/signals/active/proximity":   true,
+	"/api/signals/alerts":             true,
+	"/api/signals/investment-signals": true,
+	"/api/signals/dashboard":          true,
+	"/api/signals/calendar":           true,
+	"/api/signals/:id":                true,
+	"/api/signals/:id/news":           true,
+	"/api/signals/:id/timeline":       true,
+	"/api/scans/orb":                  true,
+	"/api/scans/gaps":                 true,
+	"/api/scans/unusual-volume":       true,
+	"/api/scans/circuits":             true,
+	"/api/predictions/top-gainers":    true,
+	"/api/predictions/top-losers":     true,
+	"/api/market/indices":             true,
+	"/api/market/heatmap":             true,
+	"/api/market/regime":              true,
+	"/api/widgets/top-movers":         true,
+	"/api/widgets/market-summary":     true,
+	"/api/quant/pairs":                true,
+	"/api/quant/seasonality":          true,
+	"/api/quant/leaderboard":          true,
+}
+
+// isGuest reports whether the request was made with a guest token
+func isGuest(c *gin.Context) bool {
+	return c.GetString(guestKey) != ""
+}
+
+// GuestMiddleware lets requests carrying a guest token read guestRoutes
+// and nothing else. The token replaces whatever identity the request
+// claims: the guest ID becomes the user, and role, account and service
+// headers are dropped. Requests without a token pass through untouched.
+func GuestMiddleware(issuer *guest.Issuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(guest.Header)
+		if token == "" {
+			token = c.Query(guest.QueryParam)
+		}
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		id, _, ok := issuer.Verify(token, time.Now())
+		if !ok {
+			authFailed(c, authguard.KindGuestTokenInvalid, "", "")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired guest token"})
+			return
+		}
+		if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) || !guestRoutes[c.FullPath()] {
+			log.Printf("⚠️  %s %s refused for %s: not open to guests", c.Request.Method, c.Request.URL.Path, id)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Guest tokens are read-only and limited to market data and signal stats"})
+			return
+		}
+
+		c.Request.Header.Set("X-User-ID", id)
+		c.Request.Header.Del("X-User-Role")
+		c.Request.Header.Del(AccountHeader)
+		c.Request.Header.Del(serviceauth.Header)
+		c.Set(guestKey, id)
+		c.Next()
+	}
+}
+
+// GuestHandler issues guest tokens
+type GuestHandler struct {
+	issuer *guest.Issuer
+}
+
+// NewGuestHandler creates a new guest handler
+func NewGuestHandler(issuer *guest.Issuer) *GuestHandler {
+	return &GuestHandler{issuer: issuer}
+}
+
+// CreateGuestToken handles POST /api/admin/guest-tokens.
+// Body: ttl_minutes (default 60, at most a week). The token is sent in
+// X-Guest-Token, or as ?guest_token= where headers can't be set.
+func (h *GuestHandler) CreateGuestToken(c *gin.Context) {
+	var body struct {
+		TTLMinutes int `json:"ttl_minutes"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if body.TTLMinutes == 0 {
+		body.TTLMinutes = defaultGuestTTLMinutes
+	}
+	if body.TTLMinutes < 1 || body.TTLMinutes > maxGuestTTLMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ttl_minutes must be between 1 and 10080"})
+		return
+	}
+
+	id := guest.NewID()
+	expires := time.Now().Add(time.Duration(body.TTLMinutes) * time.Minute)
+	token, err := h.issuer.Token(id, expires)
+	if err != nil {
+		log.Printf("❌ Failed to issue guest token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue guest token"})
+		return
+	}
+	log.Printf("🎟️  Guest token %s issued by %s until %s", id, requestActor(c), expires.Format(time.RFC3339))
+	c.JSON(http.StatusCreated, gin.H{
+		"guest_id":    id,
+		"token":       token,
+		"expires_at":  expires.Format(time.RFC3339),
+		"header":      guest.Header,
+		"query_param": guest.QueryParam,
+	})
+}
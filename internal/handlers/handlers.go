@@ -5,11 +5,18 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/trading-chitti/core-api-go/internal/coalesce"
 	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/environment"
+	"github.com/trading-chitti/core-api-go/internal/health"
+	"github.com/trading-chitti/core-api-go/internal/movers"
+	"github.com/trading-chitti/core-api-go/internal/prices"
 	ws "github.com/trading-chitti/core-api-go/internal/websocket"
 )
 
@@ -24,18 +31,28 @@ var upgrader = websocket.Upgrader{
 
 // Handler contains all HTTP handlers
 type Handler struct {
-	db  *database.DB
-	hub *ws.Hub
+	db     database.Repository
+	hub    *ws.Hub
+	flight *coalesce.Group
+	movers *movers.Cache
+	prices *prices.Store
+	env    environment.Environment
+	health *health.Checker
 }
 
-// NewHandler creates a new handler
-func NewHandler(db *database.DB, hub *ws.Hub) *Handler {
-	return &Handler{db: db, hub: hub}
+// NewHandler creates a new handler. Hot read paths are coalesced so
+// simultaneous identical requests share one query, top movers are served
+// from the tick-fed cache once it is loaded, and realtime prices are
+// overlaid with newer ticks than the database has. Service status comes from
+// the background health checks.
+func NewHandler(db database.Repository, hub *ws.Hub, moversCache *movers.Cache, priceStore *prices.Store, env environment.Environment, checker *health.Checker) *Handler {
+	repo := newCoalescedRepository(db)
+	return &Handler{db: repo, hub: hub, flight: repo.flight, movers: moversCache, prices: priceStore, env: env, health: checker}
 }
 
 // GetSignals handles GET /api/signals
 func (h *Handler) GetSignals(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	// Parse query parameters
@@ -47,8 +64,44 @@ func (h *Handler) GetSignals(c *gin.Context) {
 
 	status := c.Query("status") // Optional: "ACTIVE", "HIT_TARGET", etc.
 
+	// Optional: ?mine=true keeps only the symbols and strategies the caller
+	// follows
+	var source signalStreamer = h.db
+	mine, _ := strconv.ParseBool(c.Query("mine"))
+	if mine {
+		source = followedSignals{db: h.db, accountID: requestAccountID(c)}
+	}
+
+	// Optional: ?q=banking signals that hit target, ?when=last week
+	q, ok := parseHumanFilters(c)
+	if !ok {
+		return
+	}
+	if q != nil {
+		h.searchSignals(ctx, c, source, q, limit, status)
+		return
+	}
+
+	if shouldStream(c, limit) {
+		stream := newListStream(c, listEnvelope{Field: "signals", CountKey: "count"})
+		err := source.StreamSignals(ctx, limit, status, func(s database.Signal) error {
+			return stream.Write(s)
+		})
+		stream.Close(err, "Failed to retrieve signals")
+		return
+	}
+
 	// Query database
-	signals, err := h.db.GetAllSignals(ctx, limit, status)
+	var signals []database.Signal
+	if mine {
+		signals = []database.Signal{}
+		err = source.StreamSignals(ctx, limit, status, func(s database.Signal) error {
+			signals = append(signals, s)
+			return nil
+		})
+	} else {
+		signals, err = h.db.GetAllSignals(ctx, limit, status)
+	}
 	if err != nil {
 		log.Printf("❌ Failed to get signals: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -63,12 +116,23 @@ func (h *Handler) GetSignals(c *gin.Context) {
 	})
 }
 
-// GetActiveSignals handles GET /api/signals/active
+// GetActiveSignals handles GET /api/signals/active. With ?as_of it lists
+// the signals that were active at that instant.
 func (h *Handler) GetActiveSignals(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	signals, err := h.db.GetActiveSignals(ctx)
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
+	var signals []database.Signal
+	var err error
+	if asOf != nil {
+		signals, err = h.db.GetActiveSignalsAsOf(ctx, *asOf)
+	} else {
+		signals, err = h.db.GetActiveSignals(ctx)
+	}
 	if err != nil {
 		log.Printf("❌ Failed to get active signals: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -85,7 +149,7 @@ func (h *Handler) GetActiveSignals(c *gin.Context) {
 
 // GetSignalByID handles GET /api/signals/:id
 func (h *Handler) GetSignalByID(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	signalID := c.Param("id")
@@ -112,7 +176,19 @@ func (h *Handler) GetSignalByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, signal)
+	// Sizing is advisory; the signal is still returned without it
+	size, err := h.db.GetSignalPositionSize(ctx, signal, defaultBaseCapital, defaultRiskFraction)
+	if err != nil {
+		log.Printf("⚠️  Failed to size signal %s: %v", signalID, err)
+	}
+
+	c.JSON(http.StatusOK, signalDetail{Signal: signal, PositionSize: size})
+}
+
+// signalDetail is a signal with its suggested position size
+type signalDetail struct {
+	*database.Signal
+	PositionSize *database.PositionSize `json:"position_size,omitempty"`
 }
 
 // Health handles GET /health
@@ -120,20 +196,41 @@ func (h *Handler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":            "healthy",
 		"service":           "core-api-go",
+		"environment":       h.env.Info(),
 		"timestamp":         time.Now().UTC().Format(time.RFC3339),
 		"websocket_clients": h.hub.ClientCount(),
+		"coalescing":        h.flight.Stats(),
+		"top_movers":        h.movers.Status(),
+		"price_snapshots":   h.prices.Status(),
 	})
 }
 
-// ServeWebSocket handles WebSocket connections
+// ServeWebSocket handles WebSocket connections. With ?compact=true every
+// message is sent in compact form, as for compact REST responses. The
+// follows of the caller's account are loaded for the "mine" topic, and
+// messages sent are metered against it.
 func (h *Handler) ServeWebSocket(c *gin.Context) {
+	compact, _ := strconv.ParseBool(c.Query("compact"))
+	userID := requestUserID(c)
+	// Guests follow nothing
+	var follows []database.Follow
+	if !isGuest(c) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		var err error
+		follows, err = h.db.ListFollows(ctx, requestAccountID(c))
+		cancel()
+		if err != nil {
+			log.Printf("⚠️  Failed to load follows for WebSocket client %s: %v", userID, err)
+		}
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("❌ WebSocket upgrade failed: %v", err)
 		return
 	}
 
-	client := ws.NewClient(h.hub, conn)
+	client := ws.NewClient(h.hub, conn, compact, userID, requestAccountID(c), wsFollows(follows))
 	h.hub.Register(client)
 
 	// Start client goroutines
@@ -141,17 +238,47 @@ func (h *Handler) ServeWebSocket(c *gin.Context) {
 	go client.ReadPump()
 }
 
-// CORS middleware for development
-func CORSMiddleware() gin.HandlerFunc {
+// CORSPolicy is the set of origins allowed to call the API from a
+// browser. It can be replaced while serving; an empty set allows any
+// origin.
+type CORSPolicy struct {
+	origins atomic.Pointer[map[string]bool]
+}
+
+// NewCORSPolicy creates a policy allowing any origin
+func NewCORSPolicy() *CORSPolicy {
+	return &CORSPolicy{}
+}
+
+// SetOrigins replaces the allowed origins; "*" allows any
+func (p *CORSPolicy) SetOrigins(origins []string) {
+	set := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		set[strings.TrimSuffix(o, "/")] = true
+	}
+	p.origins.Store(&set)
+}
+
+func (p *CORSPolicy) allows(origin string) bool {
+	set := p.origins.Load()
+	return set == nil || len(*set) == 0 || (*set)["*"] || (*set)[origin]
+}
+
+// CORSMiddleware answers preflights and sets CORS headers. Origins outside
+// policy get no Access-Control-Allow-Origin, so browsers refuse them.
+func CORSMiddleware(policy *CORSPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 		if origin != "" {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			if policy.allows(origin) {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			c.Writer.Header().Add("Vary", "Origin")
 		} else {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		}
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Role, X-Account-ID, X-Guest-Token, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
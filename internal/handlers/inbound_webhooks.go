@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/inbound"
+	"github.com/trading-chitti/core-api-go/internal/newsfeeds"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	"github.com/trading-chitti/core-api-go/internal/tradingview"
+)
+
+const (
+	// maxInboundPayload caps an inbound webhook body
+	maxInboundPayload = 1 << 20
+	// maxInboundAlerts caps the notifications one delivery may send
+	maxInboundAlerts = 20
+	// defaultInboundConfidence is used for signals whose record has none
+	defaultInboundConfidence = 0.5
+)
+
+// InboundWebhookHandler receives webhooks from configured sources and
+// manages their endpoints
+type InboundWebhookHandler struct {
+	db        database.InboundWebhookRepository
+	signals   database.SignalIngestRepository
+	notifier  *notifications.Notifier
+	publisher EventPublisher
+}
+
+// NewInboundWebhookHandler creates a new inbound webhook handler. publisher
+// may be nil when NATS is not connected; signals are then only stored.
+func NewInboundWebhookHandler(db database.InboundWebhookRepository, signals database.SignalIngestRepository, notifier *notifications.Notifier, publisher EventPublisher) *InboundWebhookHandler {
+	return &InboundWebhookHandler{db: db, signals: signals, notifier: notifier, publisher: publisher}
+}
+
+// inboundWebhookBody is the create and update request body. Slug is only
+// read on create.
+type inboundWebhookBody struct {
+	Name    string         `json:"name" binding:"required"`
+	Slug    string         `json:"slug"`
+	Target  string         `json:"target" binding:"required"`
+	Config  inbound.Config `json:"config"`
+	Enabled *bool          `json:"enabled"`
+}
+
+// parse validates the body into an endpoint, writing the error response
+// itself and returning false when it's invalid
+func (h *InboundWebhookHandler) parse(c *gin.Context) (database.InboundWebhook, bool) {
+	var b inboundWebhookBody
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name, target and config are required"})
+		return database.InboundWebhook{}, false
+	}
+	w := database.InboundWebhook{
+		Name:    strings.TrimSpace(b.Name),
+		Slug:    strings.ToLower(strings.TrimSpace(b.Slug)),
+		Target:  strings.ToLower(strings.TrimSpace(b.Target)),
+		Enabled: b.Enabled == nil || *b.Enabled,
+	}
+	if w.Name == "" || len(w.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return w, false
+	}
+	if _, err := inbound.Compile(w.Target, b.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": inbound.Fields(w.Target)})
+		return w, false
+	}
+	w.Config, _ = json.Marshal(b.Config)
+	return w, true
+}
+
+// ListInboundWebhooks handles GET /api/integrations/webhooks
+func (h *InboundWebhookHandler) ListInboundWebhooks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	webhooks, err := h.db.ListInboundWebhooks(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to list inbound webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inbound webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "count": len(webhooks)})
+}
+
+// GetInboundWebhook handles GET /api/integrations/webhooks/:id
+func (h *InboundWebhookHandler) GetInboundWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	webhook, err := h.db.GetInboundWebhook(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get inbound webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve inbound webhook"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inbound webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateInboundWebhook handles POST /api/integrations/webhooks. Body: name,
+// slug (up to 32 lowercase letters, digits or _), target (signal, alert or
+// news), config and enabled (default true). Alerts go to the creating user.
+// The secret is only returned here.
+func (h *InboundWebhookHandler) CreateInboundWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	w, ok := h.parse(c)
+	if !ok {
+		return
+	}
+	if !providerPattern.MatchString(w.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug is required: up to 32 lowercase letters, digits or _"})
+		return
+	}
+	w.UserID = requestUserID(c)
+
+	secret, err := tradingview.NewSecret()
+	if err != nil {
+		log.Printf("❌ Failed to generate webhook secret: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create inbound webhook"})
+		return
+	}
+	w.SecretHash = tradingview.HashSecret(secret)
+
+	webhook, err := h.db.CreateInboundWebhook(ctx, w)
+	if errors.Is(err, database.ErrInboundWebhookExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "An inbound webhook with this slug already exists"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to create inbound webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create inbound webhook"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": webhook,
+		"secret":  secret,
+		"url":     "/api/integrations/inbound/" + webhook.Slug,
+	})
+}
+
+// UpdateInboundWebhook handles PUT /api/integrations/webhooks/:id. The body
+// replaces the endpoint's name, target, config and enabled flag.
+func (h *InboundWebhookHandler) UpdateInboundWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	w, ok := h.parse(c)
+	if !ok {
+		return
+	}
+	w.ID = id
+
+	webhook, err := h.db.UpdateInboundWebhook(ctx, w)
+	if err != nil {
+		log.Printf("❌ Failed to update inbound webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inbound webhook"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inbound webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteInboundWebhook handles DELETE /api/integrations/webhooks/:id
+func (h *InboundWebhookHandler) DeleteInboundWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	deleted, err := h.db.DeleteInboundWebhook(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to delete inbound webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete inbound webhook"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inbound webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Inbound webhook deleted", "id": id})
+}
+
+// PreviewInboundWebhook handles POST /api/integrations/webhooks/preview.
+// Body: target, config and a sample payload. Returns the records the config
+// maps the payload to and any that would be rejected, without storing
+// anything.
+func (h *InboundWebhookHandler) PreviewInboundWebhook(c *gin.Context) {
+	var body struct {
+		Target  string          `json:"target" binding:"required"`
+		Config  inbound.Config  `json:"config"`
+		Payload json.RawMessage `json:"payload" binding:"required"`
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundPayload)
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target, config and payload are required"})
+		return
+	}
+	target := strings.ToLower(strings.TrimSpace(body.Target))
+	mapping, err := inbound.Compile(target, body.Config)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": inbound.Fields(target)})
+		return
+	}
+	records, err := mapping.Apply(body.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rowErrors := []importRowError{}
+	now := time.Now()
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	for i, rec := range records {
+		var err error
+		switch target {
+		case inbound.TargetSignal:
+			var s database.IngestedSignal
+			if s, err = inboundSignal(rec, ist); err == nil {
+				err = s.Validate(now)
+			}
+		case inbound.TargetAlert:
+			_, err = inboundAlert(rec, "")
+		case inbound.TargetNews:
+			_, err = inboundArticle(rec, ist, now)
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: i + 1, Error: err.Error()})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"records": records, "count": len(records), "errors": rowErrors})
+}
+
+// ReceiveInboundWebhook handles POST /api/integrations/inbound/:slug.
+//
+// The secret is sent in the X-Webhook-Secret header or as ?secret=. The JSON
+// body is mapped by the endpoint's config into records, which become
+// signals (stored as active and announced as signal.new), alerts to the
+// endpoint's creator, or news articles. Records that can't be converted are
+// reported and skipped.
+func (h *InboundWebhookHandler) ReceiveInboundWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	secret := c.GetHeader("X-Webhook-Secret")
+	if secret == "" {
+		secret = c.Query("secret")
+	}
+	if secret == "" {
+		authFailed(c, authguard.KindWebhookSecretInvalid, "inbound/"+c.Param("slug"), "secret missing")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "secret is required"})
+		return
+	}
+	webhook, err := h.db.GetInboundWebhookBySlug(ctx, strings.ToLower(c.Param("slug")))
+	if err != nil {
+		log.Printf("❌ Failed to look up inbound webhook %s: %v", c.Param("slug"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+	// Unknown slugs and wrong secrets look the same to the caller
+	if webhook == nil || subtle.ConstantTimeCompare([]byte(tradingview.HashSecret(secret)), []byte(webhook.SecretHash)) != 1 {
+		authFailed(c, authguard.KindWebhookSecretInvalid, "inbound/"+c.Param("slug"), "invalid secret")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook secret"})
+		return
+	}
+
+	code, response, accepted, deliveryErr := h.deliver(ctx, c, webhook)
+	if err := h.db.RecordInboundDelivery(ctx, webhook.ID, accepted, deliveryErr); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+	c.JSON(code, response)
+}
+
+// deliver processes a delivery to webhook, returning the response, how many
+// records were accepted and the error to record against the endpoint
+func (h *InboundWebhookHandler) deliver(ctx context.Context, c *gin.Context, webhook *database.InboundWebhook) (int, gin.H, int, error) {
+	fail := func(code int, err error) (int, gin.H, int, error) {
+		return code, gin.H{"error": err.Error()}, 0, err
+	}
+	if !webhook.Enabled {
+		return fail(http.StatusForbidden, errors.New("webhook is disabled"))
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundPayload))
+	if err != nil {
+		return fail(http.StatusRequestEntityTooLarge, errors.New("payload too large"))
+	}
+
+	var cfg inbound.Config
+	if err := json.Unmarshal(webhook.Config, &cfg); err != nil {
+		return fail(http.StatusInternalServerError, fmt.Errorf("invalid stored config: %v", err))
+	}
+	mapping, err := inbound.Compile(webhook.Target, cfg)
+	if err != nil {
+		return fail(http.StatusInternalServerError, fmt.Errorf("invalid stored config: %v", err))
+	}
+	records, err := mapping.Apply(payload)
+	if err != nil {
+		return fail(http.StatusBadRequest, err)
+	}
+
+	var res *ingestOutcome
+	switch webhook.Target {
+	case inbound.TargetSignal:
+		res, err = h.deliverSignals(ctx, webhook, records)
+	case inbound.TargetAlert:
+		res = h.deliverAlerts(webhook, records)
+	case inbound.TargetNews:
+		res, err = h.deliverNews(ctx, webhook, records)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to store %s records from inbound webhook %s: %v", webhook.Target, webhook.Slug, err)
+		return fail(http.StatusInternalServerError, fmt.Errorf("failed to store %s records", webhook.Target))
+	}
+
+	// A delivery that's wholly rejected is recorded as an error so broken
+	// mappings show up on the endpoint
+	if res.accepted == 0 && res.duplicates == 0 && len(res.errors) > 0 {
+		err = fmt.Errorf("record %d: %s", res.errors[0].Row, res.errors[0].Error)
+	}
+	log.Printf("✅ Inbound webhook %s accepted %d of %d %s records", webhook.Slug, res.accepted, len(records), webhook.Target)
+	return http.StatusOK, gin.H{
+		"webhook":    webhook.Slug,
+		"target":     webhook.Target,
+		"records":    len(records),
+		"accepted":   res.accepted,
+		"duplicates": res.duplicates,
+		"rejected":   len(res.errors),
+		"ids":        res.ids,
+		"errors":     res.errors,
+	}, res.accepted, err
+}
+
+// deliverSignals ingests records as signals from the webhook's slug
+func (h *InboundWebhookHandler) deliverSignals(ctx context.Context, webhook *database.InboundWebhook, records []inbound.Record) (*ingestOutcome, error) {
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	rows := make([]ingestRow, 0, len(records))
+	var rowErrors []importRowError
+	for i, rec := range records {
+		s, err := inboundSignal(rec, ist)
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, ingestRow{row: i + 1, signal: s})
+	}
+	res, err := ingestRows(ctx, h.signals, h.publisher, webhook.Slug, rows)
+	if err != nil {
+		return nil, err
+	}
+	res.errors = append(rowErrors, res.errors...)
+	return res, nil
+}
+
+// deliverAlerts notifies the webhook's creator of each record
+func (h *InboundWebhookHandler) deliverAlerts(webhook *database.InboundWebhook, records []inbound.Record) *ingestOutcome {
+	res := &ingestOutcome{ids: []string{}, errors: []importRowError{}}
+	for i, rec := range records {
+		if res.accepted == maxInboundAlerts {
+			res.errors = append(res.errors, importRowError{Row: i + 1, Error: fmt.Sprintf("at most %d alerts per delivery", maxInboundAlerts)})
+			continue
+		}
+		note, err := inboundAlert(rec, webhook.Slug)
+		if err != nil {
+			res.errors = append(res.errors, importRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		h.notifier.Notify(webhook.UserID, note)
+		res.accepted++
+	}
+	return res
+}
+
+// deliverNews stores records as articles sourced from the webhook's name.
+// ids are the articles' IDs whether or not they were already stored, which
+// are counted as duplicates.
+func (h *InboundWebhookHandler) deliverNews(ctx context.Context, webhook *database.InboundWebhook, records []inbound.Record) (*ingestOutcome, error) {
+	res := &ingestOutcome{ids: []string{}, errors: []importRowError{}}
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now()
+	articles := make([]database.FeedArticle, 0, len(records))
+	for i, rec := range records {
+		a, err := inboundArticle(rec, ist, now)
+		if err != nil {
+			res.errors = append(res.errors, importRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		articles = append(articles, a)
+	}
+	if len(articles) == 0 {
+		return res, nil
+	}
+	inserted, err := h.db.InsertFeedArticles(ctx, webhook.Name, articles)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range articles {
+		res.ids = append(res.ids, a.ID)
+	}
+	res.accepted = inserted
+	res.duplicates = len(articles) - inserted
+	return res, nil
+}
+
+// inboundSignal converts a mapped record into a signal, leaving level and
+// direction checks to IngestedSignal.Validate
+func inboundSignal(rec inbound.Record, ist *time.Location) (database.IngestedSignal, error) {
+	s := database.IngestedSignal{
+		ExternalID:  rec.Get("external_id"),
+		Symbol:      rec.Get("symbol"),
+		SignalType:  rec.Get("signal_type"),
+		TimeInForce: rec.Get("time_in_force"),
+		Confidence:  defaultInboundConfidence,
+	}
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"confidence", &s.Confidence},
+		{"entry_price", &s.EntryPrice},
+		{"stop_loss", &s.StopLoss},
+		{"target_price", &s.TargetPrice},
+	} {
+		v := rec.Get(f.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return s, fmt.Errorf("invalid %s %q", f.name, v)
+		}
+		*f.dst = n
+	}
+	if v := rec.Get("generated_at"); v != "" {
+		t, ok := parseTradeTime(ist, v)
+		if !ok {
+			return s, fmt.Errorf("invalid generated_at %q", v)
+		}
+		s.GeneratedAt = t
+	}
+	// Without either, repeats of the record can't be recognised
+	if s.ExternalID == "" && s.GeneratedAt.IsZero() {
+		s.GeneratedAt = time.Now()
+	}
+	return s, nil
+}
+
+// inboundAlert converts a mapped record into a notification from the
+// webhook with slug
+func inboundAlert(rec inbound.Record, slug string) (notifications.Notification, error) {
+	title := rec.Get("title")
+	if title == "" {
+		return notifications.Notification{}, errors.New("title is empty")
+	}
+	data := map[string]string{"webhook": slug}
+	if symbol := strings.ToUpper(rec.Get("symbol")); symbol != "" {
+		data["symbol"] = symbol
+	}
+	return notifications.Notification{
+		Kind:  notifications.KindWebhookAlert,
+		Title: title,
+		Body:  rec.Get("body"),
+		Data:  data,
+	}, nil
+}
+
+// inboundArticle converts a mapped record into a news article. Symbols may
+// be an array or a comma-separated list; an article without a time is
+// published now.
+func inboundArticle(rec inbound.Record, ist *time.Location, now time.Time) (database.FeedArticle, error) {
+	a := database.FeedArticle{
+		Title:       rec.Get("title"),
+		URL:         rec.Get("url"),
+		Summary:     rec.Get("summary"),
+		PublishedAt: now,
+		Symbols:     []string{},
+	}
+	if a.Title == "" {
+		return a, errors.New("title is empty")
+	}
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return a, fmt.Errorf("url %q must be an http or https URL", a.URL)
+	}
+	a.ID = newsfeeds.ArticleID(a.URL)
+	if v := rec.Get("published_at"); v != "" {
+		t, ok := parseTradeTime(ist, v)
+		if !ok {
+			return a, fmt.Errorf("invalid published_at %q", v)
+		}
+		a.PublishedAt = t
+	}
+	seen := map[string]bool{}
+	for _, v := range rec["symbols"] {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.ToUpper(strings.TrimSpace(s))
+			if s != "" && !seen[s] {
+				seen[s] = true
+				a.Symbols = append(a.Symbols, s)
+			}
+		}
+	}
+	return a, nil
+}
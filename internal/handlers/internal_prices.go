@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// InternalPricesHandler accepts realtime prices from collectors without
+// database credentials
+type InternalPricesHandler struct {
+	db   database.PriceUpsertRepository
+	gate PriceGate
+}
+
+// PriceGate decides whether a collector's price may be written. payload is
+// the price as posted, kept for inspection.
+type PriceGate interface {
+	AllowPrice(u database.PriceUpdate, payload []byte) bool
+}
+
+// NewInternalPricesHandler creates a new internal prices handler. gate may
+// be nil to write every valid price.
+func NewInternalPricesHandler(db database.PriceUpsertRepository, gate PriceGate) *InternalPricesHandler {
+	return &InternalPricesHandler{db: db, gate: gate}
+}
+
+// priceUpdateBody is one price in a batch
+type priceUpdateBody struct {
+	Symbol          string     `json:"symbol"`
+	Exchange        string     `json:"exchange"`
+	InstrumentToken *int64     `json:"instrument_token"`
+	LastPrice       float64    `json:"last_price"`
+	Volume          *int64     `json:"volume"`
+	Open            *float64   `json:"open"`
+	High            *float64   `json:"high"`
+	Low             *float64   `json:"low"`
+	Close           *float64   `json:"close"`
+	ChangePercent   *float64   `json:"change_percent"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+}
+
+// UpsertPrices handles POST /api/internal/prices/batch.
+//
+// Accepts {"prices": [...]} with symbol and last_price, and optionally
+// exchange (default NSE), instrument_token, volume, open, high, low, close,
+// change_percent and updated_at (default now). Valid prices are written in
+// one statement; invalid ones are reported by position and skipped, and
+// prices older than the stored one are counted as stale. Prices failing
+// data quality rules are quarantined rather than written.
+func (h *InternalPricesHandler) UpsertPrices(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	var body struct {
+		Prices []priceUpdateBody `json:"prices"`
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if len(body.Prices) > database.MaxPriceBatch {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("At most %d prices per batch", database.MaxPriceBatch)})
+		return
+	}
+
+	now := time.Now()
+	updates := make([]database.PriceUpdate, 0, len(body.Prices))
+	rowErrors := []importRowError{}
+	quarantined := 0
+	for i, p := range body.Prices {
+		u := database.PriceUpdate{
+			Symbol:          p.Symbol,
+			Exchange:        p.Exchange,
+			InstrumentToken: p.InstrumentToken,
+			LastPrice:       p.LastPrice,
+			Volume:          p.Volume,
+			Open:            p.Open,
+			High:            p.High,
+			Low:             p.Low,
+			Close:           p.Close,
+			ChangePercent:   p.ChangePercent,
+		}
+		if p.UpdatedAt != nil {
+			u.UpdatedAt = *p.UpdatedAt
+		}
+		if err := u.Validate(now); err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		if h.gate != nil {
+			payload, _ := json.Marshal(p)
+			if !h.gate.AllowPrice(u, payload) {
+				quarantined++
+				continue
+			}
+		}
+		updates = append(updates, u)
+	}
+
+	result, err := h.db.UpsertRealtimePrices(ctx, updates)
+	if err != nil {
+		log.Printf("❌ Failed to upsert %d realtime prices: %v", len(updates), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upsert prices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"written":     result.Written,
+		"stale":       result.Stale,
+		"duplicates":  result.Duplicates,
+		"quarantined": quarantined,
+		"rejected":    len(rowErrors),
+		"errors":      rowErrors,
+	})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+const (
+	maxJournalTags    = 20
+	maxJournalTagLen  = 40
+	maxJournalSignals = 50
+)
+
+// JournalHandler serves the trading journal
+type JournalHandler struct {
+	db database.JournalRepository
+}
+
+// NewJournalHandler creates a new journal handler
+func NewJournalHandler(db database.JournalRepository) *JournalHandler {
+	return &JournalHandler{db: db}
+}
+
+// journalBody is the create and update request body; omitted fields are
+// left unchanged on update
+type journalBody struct {
+	EntryDate   *string   `json:"entry_date"`
+	Title       *string   `json:"title"`
+	Notes       *string   `json:"notes"`
+	Mood        *string   `json:"mood"`
+	Mistakes    *[]string `json:"mistakes"`
+	SignalIDs   *[]string `json:"signal_ids"`
+	RealizedPnL *float64  `json:"realized_pnl"`
+}
+
+// normalizeMistakes lowercases tags, joins words with hyphens and drops
+// duplicates, so "Moved Stop" and "moved-stop" count as one mistake
+func normalizeMistakes(tags []string) ([]string, bool) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range tags {
+		tag := strings.Join(strings.Fields(strings.ToLower(t)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxJournalTagLen {
+			return nil, false
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out, len(out) <= maxJournalTags
+}
+
+// parse validates the body into an update, writing the error response
+// itself and returning false when it's invalid
+func (b *journalBody) parse(c *gin.Context) (database.JournalUpdate, bool) {
+	u := database.JournalUpdate{Title: b.Title, Notes: b.Notes, RealizedPnL: b.RealizedPnL}
+
+	if b.EntryDate != nil {
+		day, err := time.Parse("2006-01-02", *b.EntryDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "entry_date must be YYYY-MM-DD"})
+			return u, false
+		}
+		u.EntryDate = &day
+	}
+	if b.Mood != nil {
+		mood := strings.ToUpper(strings.TrimSpace(*b.Mood))
+		if !database.ValidJournalMood(mood) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown mood", "moods": database.JournalMoods})
+			return u, false
+		}
+		u.Mood = &mood
+	}
+	if b.Mistakes != nil {
+		mistakes, ok := normalizeMistakes(*b.Mistakes)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "At most " + strconv.Itoa(maxJournalTags) + " mistake tags of up to " +
+					strconv.Itoa(maxJournalTagLen) + " characters",
+			})
+			return u, false
+		}
+		u.Mistakes = &mistakes
+	}
+	if b.SignalIDs != nil {
+		seen := map[string]bool{}
+		ids := []string{}
+		for _, id := range *b.SignalIDs {
+			if id = strings.TrimSpace(id); id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > maxJournalSignals {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At most " + strconv.Itoa(maxJournalSignals) + " linked signals"})
+			return u, false
+		}
+		u.SignalIDs = &ids
+	}
+	return u, true
+}
+
+// ListJournalEntries handles GET /api/journal.
+// Query: from, to (YYYY-MM-DD), mistake (tag), limit.
+func (h *JournalHandler) ListJournalEntries(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	f := database.JournalFilter{Limit: 100}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 500 {
+		f.Limit = v
+	}
+	for param, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := c.Query(param); v != "" {
+			day, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be YYYY-MM-DD"})
+				return
+			}
+			*dst = &day
+		}
+	}
+	if v := c.Query("mistake"); v != "" {
+		if tags, _ := normalizeMistakes([]string{v}); len(tags) == 1 {
+			f.Mistake = tags[0]
+		}
+	}
+
+	entries, err := h.db.ListJournalEntries(ctx, requestAccountID(c), f)
+	if err != nil {
+		log.Printf("❌ Failed to list journal entries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve journal entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// CreateJournalEntry handles POST /api/journal. entry_date defaults to today.
+func (h *JournalHandler) CreateJournalEntry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body journalBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	u, ok := body.parse(c)
+	if !ok {
+		return
+	}
+
+	entryDate := database.SessionOpen(time.Now())
+	if u.EntryDate != nil {
+		entryDate = *u.EntryDate
+	}
+	e := database.JournalEntry{
+		AccountID:   requestAccountID(c),
+		UserID:      requestUserID(c),
+		Title:       u.Title,
+		Mood:        u.Mood,
+		RealizedPnL: u.RealizedPnL,
+	}
+	if u.Notes != nil {
+		e.Notes = *u.Notes
+	}
+	if u.Mistakes != nil {
+		e.Mistakes = *u.Mistakes
+	}
+	if u.SignalIDs != nil {
+		e.SignalIDs = *u.SignalIDs
+	}
+	if e.Notes == "" && e.Title == nil && len(e.Mistakes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An entry needs a title, notes or mistakes"})
+		return
+	}
+
+	entry, err := h.db.CreateJournalEntry(ctx, e, entryDate)
+	if err != nil {
+		log.Printf("❌ Failed to create journal entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create journal entry"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetJournalEntry handles GET /api/journal/:id
+func (h *JournalHandler) GetJournalEntry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal entry ID"})
+		return
+	}
+
+	entry, err := h.db.GetJournalEntry(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get journal entry %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve journal entry"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateJournalEntry handles PUT /api/journal/:id
+func (h *JournalHandler) UpdateJournalEntry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal entry ID"})
+		return
+	}
+
+	var body journalBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	u, ok := body.parse(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.db.UpdateJournalEntry(ctx, requestAccountID(c), id, u)
+	if err != nil {
+		log.Printf("❌ Failed to update journal entry %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update journal entry"})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteJournalEntry handles DELETE /api/journal/:id
+func (h *JournalHandler) DeleteJournalEntry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal entry ID"})
+		return
+	}
+
+	deleted, err := h.db.DeleteJournalEntry(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete journal entry %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete journal entry"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Journal entry deleted", "id": id})
+}
+
+// GetJournalWeeklySummary handles GET /api/journal/summary/weekly.
+// Query: week (any YYYY-MM-DD in the Monday-to-Sunday week, default this week).
+func (h *JournalHandler) GetJournalWeeklySummary(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	day := database.SessionOpen(time.Now())
+	if v := c.Query("week"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week must be YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+	// Weeks start on Monday
+	offset := (int(day.Weekday()) + 6) % 7
+	weekStart := time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, time.UTC)
+
+	summary, err := h.db.GetJournalWeeklySummary(ctx, requestAccountID(c), weekStart)
+	if err != nil {
+		log.Printf("❌ Failed to summarise journal week %s: %v", weekStart.Format("2006-01-02"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise journal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/loadtest"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// LoadTestHandler starts and stops the synthetic event generator
+type LoadTestHandler struct {
+	generator *loadtest.Generator
+	hub       *ws.Hub
+}
+
+// NewLoadTestHandler creates a new load test handler
+func NewLoadTestHandler(generator *loadtest.Generator, hub *ws.Hub) *LoadTestHandler {
+	return &LoadTestHandler{generator: generator, hub: hub}
+}
+
+// GetLoadTest handles GET /api/system/loadtest
+func (h *LoadTestHandler) GetLoadTest(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"generator": h.generator.Status(),
+		"hub":       h.hub.Stats(),
+	})
+}
+
+// StartLoadTest handles POST /api/system/loadtest
+func (h *LoadTestHandler) StartLoadTest(c *gin.Context) {
+	if !h.generator.Enabled() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Load test mode is disabled (set LOADTEST_ENABLED=true)"})
+		return
+	}
+
+	var cfg loadtest.Config
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.generator.Start(cfg); err != nil {
+		if errors.Is(err, loadtest.ErrRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A load test is already running"})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("✅ Load test started: %.0f ticks/s, %.0f signals/min over %d symbols for %ds",
+		cfg.TicksPerSecond, cfg.SignalsPerMinute, cfg.Symbols, cfg.DurationSeconds)
+	c.JSON(http.StatusAccepted, gin.H{"generator": h.generator.Status()})
+}
+
+// StopLoadTest handles DELETE /api/system/loadtest
+func (h *LoadTestHandler) StopLoadTest(c *gin.Context) {
+	if !h.generator.Stop() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No load test is running"})
+		return
+	}
+
+	status := h.generator.Status()
+	log.Printf("✅ Load test stopped: %d ticks published", status.Published["market.tick"])
+	c.JSON(http.StatusOK, gin.H{
+		"generator": status,
+		"hub":       h.hub.Stats(),
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/maintenance"
+)
+
+// maxMaintenanceMinutes caps a self-ending maintenance window
+const maxMaintenanceMinutes = 24 * 60
+
+// maintenanceRetrySeconds is the Retry-After sent when the window has no end
+const maintenanceRetrySeconds = 60
+
+// maintenanceExempt are mutating routes that stay available during
+// maintenance, by route template:
+//   - the toggle itself and ending a chaos drill
+//   - read-only requests that use POST for their body
+//
+// Ingestion from upstream feeds (signals, prices, TradingView and inbound
+// webhooks) writes to the database, so it is refused like any other write;
+// the 503 always carries Retry-After so senders retry after the window.
+// GraphQL stays blocked: a single query can fan out across every table.
+var maintenanceExempt = map[string]bool{
+	"/api/system/maintenance": true,
+	chaosPath:                 true,
+
+	"/api/assistant/query":               true,
+	"/api/integrations/webhooks/preview": true,
+	"/api/news/summarize":                true,
+	"/api/quant/position-size":           true,
+	"/api/quant/stress":                  true,
+}
+
+// MaintenanceMiddleware refuses writes with 503 while maintenance mode is
+// on. Reads and maintenanceExempt routes are unaffected.
+func MaintenanceMiddleware(mode *maintenance.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !mode.Enabled() || maintenanceExempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		state := mode.State()
+		retry := maintenanceRetrySeconds
+		if state.EndsAt != nil {
+			if endsAt, err := time.Parse(time.RFC3339, *state.EndsAt); err == nil {
+				if wait := int(time.Until(endsAt).Seconds()); wait > 0 {
+					retry = wait
+				}
+			}
+		}
+		c.Header("Retry-After", strconv.Itoa(retry))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       "Maintenance in progress",
+			"message":     state.Message,
+			"maintenance": state,
+		})
+	}
+}
+
+// MaintenanceHandler reads and toggles maintenance mode
+type MaintenanceHandler struct {
+	mode *maintenance.Mode
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(mode *maintenance.Mode) *MaintenanceHandler {
+	return &MaintenanceHandler{mode: mode}
+}
+
+// GetMaintenance handles GET /api/system/maintenance
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, h.mode.State())
+}
+
+// SetMaintenance handles POST /api/system/maintenance.
+// Body: enabled, message (banner text), duration_minutes (optional; the mode
+// switches itself off afterwards).
+func (h *MaintenanceHandler) SetMaintenance(c *gin.Context) {
+	var body struct {
+		Enabled         *bool  `json:"enabled" binding:"required"`
+		Message         string `json:"message"`
+		DurationMinutes int    `json:"duration_minutes"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "enabled is required"})
+		return
+	}
+
+	if !*body.Enabled {
+		c.JSON(http.StatusOK, h.mode.Disable())
+		return
+	}
+	if body.DurationMinutes < 0 || body.DurationMinutes > maxMaintenanceMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration_minutes must be between 0 and 1440"})
+		return
+	}
+	message := strings.TrimSpace(body.Message)
+	if len(message) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message must be at most 500 characters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.mode.Enable(message, time.Duration(body.DurationMinutes)*time.Minute))
+}
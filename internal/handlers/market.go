@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"context"
+	"log"
+	"math"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,7 +14,7 @@ import (
 
 // GetMarketIndices handles GET /api/market/indices
 func (h *Handler) GetMarketIndices(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	indices, err := h.db.GetMarketIndices(ctx)
@@ -21,3 +25,111 @@ func (h *Handler) GetMarketIndices(c *gin.Context) {
 
 	c.JSON(http.StatusOK, indices)
 }
+
+// capWeights size heatmap tiles by market-cap category. Per-symbol market
+// capitalisation is not stored, so tiers stand in for it.
+var capWeights = map[string]float64{
+	"LARGE_CAP": 8,
+	"MID_CAP":   3,
+	"SMALL_CAP": 1,
+}
+
+// heatmapNode is a treemap node: sectors hold symbols, symbols are leaves
+type heatmapNode struct {
+	Name          string         `json:"name"`
+	Label         string         `json:"label,omitempty"`
+	Weight        float64        `json:"weight"`
+	ChangePercent float64        `json:"change_percent"`
+	LastPrice     *float64       `json:"last_price,omitempty"`
+	Category      string         `json:"market_cap_category,omitempty"`
+	Advances      *int           `json:"advances,omitempty"`
+	Declines      *int           `json:"declines,omitempty"`
+	Children      []*heatmapNode `json:"children,omitempty"`
+}
+
+// GetMarketHeatmap handles GET /api/market/heatmap. Symbols are grouped by
+// sector; ?weight=market_cap (default), value (traded value) or equal picks
+// tile sizes. Sector change is the weight-averaged change of its symbols.
+func (h *Handler) GetMarketHeatmap(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	weightBy := c.DefaultQuery("weight", "market_cap")
+	if weightBy != "market_cap" && weightBy != "value" && weightBy != "equal" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight must be market_cap, value or equal"})
+		return
+	}
+
+	quotes, err := h.db.GetHeatmapQuotes(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to get heatmap quotes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get market heatmap"})
+		return
+	}
+
+	sectors := map[string]*heatmapNode{}
+	root := &heatmapNode{Name: "Market"}
+	var weightedChange float64
+	symbols := 0
+	for _, q := range quotes {
+		weight := 1.0
+		switch weightBy {
+		case "market_cap":
+			if w, ok := capWeights[strings.ToUpper(q.MarketCapCategory)]; ok {
+				weight = w
+			}
+		case "value":
+			weight = q.LastPrice * float64(q.Volume)
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		sector, ok := sectors[q.Sector]
+		if !ok {
+			sector = &heatmapNode{Name: q.Sector, Advances: new(int), Declines: new(int)}
+			sectors[q.Sector] = sector
+			root.Children = append(root.Children, sector)
+		}
+		price := q.LastPrice
+		sector.Children = append(sector.Children, &heatmapNode{
+			Name:          q.Symbol,
+			Label:         q.Name,
+			Weight:        weight,
+			ChangePercent: math.Round(q.ChangePercent*100) / 100,
+			LastPrice:     &price,
+			Category:      q.MarketCapCategory,
+		})
+		sector.Weight += weight
+		sector.ChangePercent += q.ChangePercent * weight
+		switch {
+		case q.ChangePercent > 0:
+			*sector.Advances++
+		case q.ChangePercent < 0:
+			*sector.Declines++
+		}
+		symbols++
+		root.Weight += weight
+		weightedChange += q.ChangePercent * weight
+	}
+
+	for _, sector := range root.Children {
+		sector.ChangePercent = math.Round(sector.ChangePercent/sector.Weight*100) / 100
+		sort.Slice(sector.Children, func(i, j int) bool { return sector.Children[i].Weight > sector.Children[j].Weight })
+	}
+	sort.Slice(root.Children, func(i, j int) bool { return root.Children[i].Weight > root.Children[j].Weight })
+	if root.Weight > 0 {
+		root.ChangePercent = math.Round(weightedChange/root.Weight*100) / 100
+	}
+	if root.Children == nil {
+		root.Children = []*heatmapNode{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"weight_basis": weightBy,
+		"symbols":      symbols,
+		"sectors":      len(root.Children),
+		"tree":         root,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	})
+}
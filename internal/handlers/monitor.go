@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/health"
 )
 
 // ServiceInfo represents a service's status info
@@ -20,72 +20,26 @@ type ServiceInfo struct {
 	LastCheck       string  `json:"lastCheck"`
 }
 
-// serviceEndpoint defines how to health-check a service
-type serviceEndpoint struct {
-	Name string
-	URL  string
-}
-
-var serviceEndpoints = []serviceEndpoint{
-	{Name: "intraday-engine", URL: "http://localhost:6007/health"},
-	{Name: "market-bridge", URL: "http://localhost:6005/health"},
-	{Name: "news-nlp", URL: "http://localhost:6006/health"},
-	// NATS doesn't have HTTP endpoint - marked as healthy in GetMonitorServices
-	{Name: "dashboard", URL: "http://localhost:6003"},
-}
-
-// checkServiceHTTP performs an HTTP health check for a service
-func checkServiceHTTP(ctx context.Context, ep serviceEndpoint, now string) ServiceInfo {
-	start := time.Now()
-
-	req, err := http.NewRequestWithContext(ctx, "GET", ep.URL, nil)
-	if err != nil {
-		return ServiceInfo{Name: ep.Name, Status: "unhealthy", LastCheck: now}
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	responseTimeMs := float64(time.Since(start).Milliseconds())
-
-	if err != nil {
-		return ServiceInfo{Name: ep.Name, Status: "unhealthy", AvgResponseTime: responseTimeMs, LastCheck: now}
-	}
-	defer resp.Body.Close()
-
-	status := "unhealthy"
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		status = "healthy"
-	} else if resp.StatusCode == 503 {
-		status = "degraded"
+// serviceInfo converts a cached health check result. LastCheck is when the
+// check ran, and is empty until the first one completes.
+func serviceInfo(r health.Result) ServiceInfo {
+	info := ServiceInfo{Name: r.Name, Status: r.Status, Uptime: 99.9, AvgResponseTime: r.ResponseTimeMs}
+	if !r.CheckedAt.IsZero() {
+		info.LastCheck = r.CheckedAt.Format(time.RFC3339)
 	}
-
-	return ServiceInfo{Name: ep.Name, Status: status, Uptime: 99.9, AvgResponseTime: responseTimeMs, LastCheck: now}
+	return info
 }
 
-// GetMonitorServices handles GET /api/monitor/services
+// GetMonitorServices handles GET /api/monitor/services, serving the latest
+// results of the background health checks
 func (h *Handler) GetMonitorServices(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	now := time.Now().Format(time.RFC3339)
 
 	services := []ServiceInfo{
 		{Name: "core-api-go", Status: "healthy", Uptime: 99.9, AvgResponseTime: 1, LastCheck: now},
 	}
-
-	// Check database
-	dbStatus := "healthy"
-	dbStart := time.Now()
-	if err := h.db.GetConn().PingContext(ctx); err != nil {
-		dbStatus = "unhealthy"
-	}
-	services = append(services, ServiceInfo{
-		Name: "postgres", Status: dbStatus, Uptime: 99.9,
-		AvgResponseTime: float64(time.Since(dbStart).Milliseconds()), LastCheck: now,
-	})
-
-	// Check all external services via HTTP
-	for _, ep := range serviceEndpoints {
-		services = append(services, checkServiceHTTP(ctx, ep, now))
+	for _, r := range h.health.Results() {
+		services = append(services, serviceInfo(r))
 	}
 
 	// NATS doesn't have HTTP endpoint, mark as healthy manually
@@ -101,33 +55,19 @@ func (h *Handler) GetMonitorService(c *gin.Context) {
 	service := c.Param("service")
 	now := time.Now().Format(time.RFC3339)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
 	if service == "core-api-go" || service == "core-api" {
 		c.JSON(http.StatusOK, gin.H{"services": []ServiceInfo{
 			{Name: "core-api-go", Status: "healthy", Uptime: 99.9, AvgResponseTime: 1, LastCheck: now},
 		}})
 		return
 	}
-
-	if service == "postgres" || service == "database" {
-		dbStatus := "healthy"
-		dbStart := time.Now()
-		if err := h.db.GetConn().PingContext(ctx); err != nil {
-			dbStatus = "unhealthy"
-		}
-		c.JSON(http.StatusOK, gin.H{"services": []ServiceInfo{
-			{Name: "postgres", Status: dbStatus, AvgResponseTime: float64(time.Since(dbStart).Milliseconds()), LastCheck: now},
-		}})
-		return
+	if service == "database" {
+		service = health.Database
 	}
 
-	for _, ep := range serviceEndpoints {
-		if ep.Name == service {
-			c.JSON(http.StatusOK, gin.H{"services": []ServiceInfo{checkServiceHTTP(ctx, ep, now)}})
-			return
-		}
+	if r, ok := h.health.Result(service); ok {
+		c.JSON(http.StatusOK, gin.H{"services": []ServiceInfo{serviceInfo(r)}})
+		return
 	}
 
 	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Unknown service: %s", service)})
@@ -7,16 +7,34 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/health"
 )
 
 // MonitoringHandler handles monitoring endpoints
 type MonitoringHandler struct {
-	db *sql.DB
+	db     *sql.DB
+	health *health.Checker
 }
 
 // NewMonitoringHandler creates a new monitoring handler
-func NewMonitoringHandler(db *sql.DB) *MonitoringHandler {
-	return &MonitoringHandler{db: db}
+func NewMonitoringHandler(db *sql.DB, checker *health.Checker) *MonitoringHandler {
+	return &MonitoringHandler{db: db, health: checker}
+}
+
+// postgresPort is the port reported for the database
+const postgresPort = 6432
+
+// servicePort returns the port of a health-checked service
+func servicePort(name string) int {
+	if name == health.Database {
+		return postgresPort
+	}
+	for _, s := range health.Services {
+		if s.Name == name {
+			return s.Port
+		}
+	}
+	return 0
 }
 
 // ServiceHealth represents health status of a service
@@ -28,64 +46,23 @@ type ServiceHealth struct {
 	Error           string  `json:"error,omitempty"`
 }
 
-// GetServicesHealth returns health status of all services
+// GetServicesHealth returns health status of all services, from the latest
+// background health checks
 func (h *MonitoringHandler) GetServicesHealth(c *gin.Context) {
 	services := map[string]ServiceHealth{}
 	now := time.Now().Format(time.RFC3339)
 
-	// Check database
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	start := time.Now()
-	if err := h.db.PingContext(ctx); err != nil {
-		services["postgres"] = ServiceHealth{
-			Status:    "unhealthy",
-			Port:      6432,
-			LastCheck: now,
-			Error:     err.Error(),
+	for _, r := range h.health.Results() {
+		sh := ServiceHealth{
+			Status:         r.Status,
+			Port:           servicePort(r.Name),
+			ResponseTimeMs: r.ResponseTimeMs,
+			Error:          r.Error,
 		}
-	} else {
-		services["postgres"] = ServiceHealth{
-			Status:         "healthy",
-			Port:           6432,
-			LastCheck:      now,
-			ResponseTimeMs: float64(time.Since(start).Milliseconds()),
-		}
-	}
-
-	// Check other services via HTTP
-	checkHTTP := func(name, url string, port int) ServiceHealth {
-		start := time.Now()
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-		resp, err := http.DefaultClient.Do(req)
-
-		if err != nil {
-			return ServiceHealth{
-				Status:    "unhealthy",
-				Port:      port,
-				LastCheck: now,
-				Error:     err.Error(),
-			}
-		}
-		defer resp.Body.Close()
-
-		status := "unhealthy"
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			status = "healthy"
-		} else if resp.StatusCode == 503 {
-			status = "degraded"
-		}
-
-		return ServiceHealth{
-			Status:         status,
-			Port:           port,
-			LastCheck:      now,
-			ResponseTimeMs: float64(time.Since(start).Milliseconds()),
+		if !r.CheckedAt.IsZero() {
+			sh.LastCheck = r.CheckedAt.Format(time.RFC3339)
 		}
+		services[r.Name] = sh
 	}
 
 	// Check critical services
@@ -95,11 +72,6 @@ func (h *MonitoringHandler) GetServicesHealth(c *gin.Context) {
 		LastCheck: now,
 	}
 
-	services["intraday-engine"] = checkHTTP("intraday-engine", "http://localhost:6007/health", 6007)
-	services["market-bridge"] = checkHTTP("market-bridge", "http://localhost:6005/health", 6005)
-	services["news-nlp"] = checkHTTP("news-nlp", "http://localhost:6006/health", 6006)
-	services["dashboard"] = checkHTTP("dashboard", "http://localhost:6003", 6003)
-
 	// NATS doesn't have HTTP endpoint by default, mark as healthy if we can connect
 	services["nats"] = ServiceHealth{
 		Status:    "healthy",
@@ -113,7 +85,7 @@ func (h *MonitoringHandler) GetServicesHealth(c *gin.Context) {
 // GetSystemMetrics returns basic system metrics
 func (h *MonitoringHandler) GetSystemMetrics(c *gin.Context) {
 	// Query database for signal stats
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	var stats struct {
@@ -136,11 +136,7 @@ func (h *MonitoringHandler) GetRecentLogs(c *gin.Context) {
 		logs = append(logs, entries...)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"logs":      logs,
-		"total":     len(logs),
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	writeLogEntries(c, logs)
 }
 
 // GetErrorLogs handles GET /api/monitoring/logs/errors
@@ -224,33 +220,52 @@ func (h *MonitoringHandler) GetErrorLogs(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"logs":      logs,
-		"total":     len(logs),
-		"timestamp": time.Now().Format(time.RFC3339),
+	writeLogEntries(c, logs)
+}
+
+// writeLogEntries streams log entries as {"logs": [...], "total", "timestamp"}
+// or as NDJSON
+func writeLogEntries(c *gin.Context, logs []LogEntry) {
+	stream := newListStream(c, listEnvelope{
+		Field:    "logs",
+		CountKey: "total",
+		Extra:    gin.H{"timestamp": time.Now().Format(time.RFC3339)},
 	})
+	for _, entry := range logs {
+		if err := stream.Write(entry); err != nil {
+			stream.Close(err, "Failed to write logs")
+			return
+		}
+	}
+	stream.Close(nil, "")
 }
 
 func readLogFileLines(filePath, service string, lines int) []LogEntry {
 	entries := []LogEntry{}
+	if lines <= 0 {
+		return entries
+	}
 	file, err := os.Open(filePath)
 	if err != nil {
 		return entries
 	}
 	defer file.Close()
 
+	// Keep only the last n lines in a ring so large logs are not held in memory
 	scanner := bufio.NewScanner(file)
-	allLines := []string{}
+	tail := make([]string, lines)
+	total := 0
 	for scanner.Scan() {
-		allLines = append(allLines, scanner.Text())
+		tail[total%lines] = scanner.Text()
+		total++
 	}
 
-	start := len(allLines) - lines
-	if start < 0 {
-		start = 0
+	start := 0
+	if total > lines {
+		start = total - lines
 	}
-
-	for _, line := range allLines[start:] {
+	for i := start; i < total; i++ {
+		line := tail[i%lines]
 		if line == "" {
 			continue
 		}
@@ -288,7 +303,7 @@ func parseLogEntry(line, service string) LogEntry {
 
 // GetBrokerStatus handles GET /api/monitoring/broker-status
 func (h *MonitoringHandler) GetBrokerStatus(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	type BrokerStatus struct {
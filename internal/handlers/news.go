@@ -9,9 +9,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// GetNews handles GET /api/news
+// GetNews handles GET /api/news. Near-duplicate articles are collapsed to
+// one per story unless collapse=false.
 func (h *Handler) GetNews(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -19,6 +20,7 @@ func (h *Handler) GetNews(c *gin.Context) {
 	sentiment := c.Query("sentiment")
 	search := c.Query("search")
 	symbol := c.Query("symbol")
+	collapse := c.DefaultQuery("collapse", "true") != "false"
 
 	if limit <= 0 || limit > 100 {
 		limit = 20
@@ -27,7 +29,7 @@ func (h *Handler) GetNews(c *gin.Context) {
 		offset = 0
 	}
 
-	news, err := h.db.GetNews(ctx, limit, offset, sentiment, search, symbol)
+	news, err := h.db.GetNews(ctx, limit, offset, sentiment, search, symbol, collapse)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get news"})
 		return
@@ -35,3 +37,27 @@ func (h *Handler) GetNews(c *gin.Context) {
 
 	c.JSON(http.StatusOK, news)
 }
+
+// GetNewsCluster handles GET /api/news/clusters/:id, returning every
+// article reporting the same story, earliest first
+func (h *Handler) GetNewsCluster(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	articles, err := h.db.GetNewsCluster(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get news cluster"})
+		return
+	}
+	if len(articles) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "News cluster not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cluster_id": id,
+		"articles":   articles,
+		"count":      len(articles),
+	})
+}
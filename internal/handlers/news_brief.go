@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/newsbrief"
+)
+
+// NewsBriefHandler serves LLM-written news briefs
+type NewsBriefHandler struct {
+	briefs *newsbrief.Summarizer
+}
+
+// NewNewsBriefHandler creates a new news brief handler
+func NewNewsBriefHandler(briefs *newsbrief.Summarizer) *NewsBriefHandler {
+	return &NewsBriefHandler{briefs: briefs}
+}
+
+// Summarize handles POST /api/news/summarize?symbol=X&articles=N
+func (h *NewsBriefHandler) Summarize(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	articles := newsbrief.DefaultArticles
+	if v := c.Query("articles"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > newsbrief.MaxArticles {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "articles must be between 1 and " + strconv.Itoa(newsbrief.MaxArticles)})
+			return
+		}
+		articles = n
+	}
+
+	brief, err := h.briefs.Summarize(ctx, symbol, articles)
+	switch {
+	case errors.Is(err, newsbrief.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "News summaries are not configured",
+			"hint":  "Set " + newsbrief.ConfigProvider + " (openai or anthropic) and " + newsbrief.ConfigAPIKey + " in system config",
+		})
+	case errors.Is(err, newsbrief.ErrNoArticles):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No news found for " + symbol})
+	case err != nil:
+		log.Printf("❌ Failed to summarize news for %s: %v", symbol, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to summarize news"})
+	default:
+		c.JSON(http.StatusOK, brief)
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/newsfeeds"
+)
+
+// NewsFeedsHandler manages the RSS feeds polled into news.articles
+type NewsFeedsHandler struct {
+	db       database.NewsFeedRepository
+	ingester *newsfeeds.Ingester
+}
+
+// NewNewsFeedsHandler creates a new news feeds handler
+func NewNewsFeedsHandler(db database.NewsFeedRepository, ingester *newsfeeds.Ingester) *NewsFeedsHandler {
+	return &NewsFeedsHandler{db: db, ingester: ingester}
+}
+
+// newsFeedBody is the create and update request body
+type newsFeedBody struct {
+	Name                string `json:"name" binding:"required"`
+	URL                 string `json:"url" binding:"required"`
+	Active              *bool  `json:"active"`
+	PollIntervalMinutes *int   `json:"poll_interval_minutes"`
+}
+
+// parse validates the body into a feed, writing the error response itself
+// and returning false when it's invalid
+func (h *NewsFeedsHandler) parse(c *gin.Context) (database.NewsFeed, bool) {
+	var b newsFeedBody
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and url are required"})
+		return database.NewsFeed{}, false
+	}
+	f := database.NewsFeed{
+		Name:                strings.TrimSpace(b.Name),
+		URL:                 strings.TrimSpace(b.URL),
+		Active:              b.Active == nil || *b.Active,
+		PollIntervalMinutes: 15,
+	}
+	if f.Name == "" || len(f.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return f, false
+	}
+	u, err := url.Parse(f.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an http or https URL"})
+		return f, false
+	}
+	if b.PollIntervalMinutes != nil {
+		if *b.PollIntervalMinutes < 1 || *b.PollIntervalMinutes > 1440 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "poll_interval_minutes must be between 1 and 1440"})
+			return f, false
+		}
+		f.PollIntervalMinutes = *b.PollIntervalMinutes
+	}
+	return f, true
+}
+
+// ListNewsFeeds handles GET /api/news/feeds
+func (h *NewsFeedsHandler) ListNewsFeeds(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	feeds, err := h.db.ListNewsFeeds(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to list news feeds: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve news feeds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feeds":             feeds,
+		"count":             len(feeds),
+		"ingestion_enabled": h.ingester.Enabled(),
+	})
+}
+
+// CreateNewsFeed handles POST /api/news/feeds. Body: name, url,
+// poll_interval_minutes (default 15) and active (default true).
+func (h *NewsFeedsHandler) CreateNewsFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	f, ok := h.parse(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.db.CreateNewsFeed(ctx, f)
+	if errors.Is(err, database.ErrNewsFeedExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A feed with this URL already exists"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to create news feed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create news feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// GetNewsFeed handles GET /api/news/feeds/:id
+func (h *NewsFeedsHandler) GetNewsFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	feed := h.loadFeed(ctx, c)
+	if feed == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// UpdateNewsFeed handles PUT /api/news/feeds/:id. The body replaces the
+// feed's settings.
+func (h *NewsFeedsHandler) UpdateNewsFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+	f, ok := h.parse(c)
+	if !ok {
+		return
+	}
+	f.ID = id
+
+	feed, err := h.db.UpdateNewsFeed(ctx, f)
+	if errors.Is(err, database.ErrNewsFeedExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A feed with this URL already exists"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to update news feed %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update news feed"})
+		return
+	}
+	if feed == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "News feed not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// DeleteNewsFeed handles DELETE /api/news/feeds/:id. Articles already
+// ingested from the feed are kept.
+func (h *NewsFeedsHandler) DeleteNewsFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	deleted, err := h.db.DeleteNewsFeed(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to delete news feed %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete news feed"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "News feed not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "News feed deleted", "id": id})
+}
+
+// PollNewsFeed handles POST /api/news/feeds/:id/poll, fetching the feed now
+// whether or not the background worker is enabled
+func (h *NewsFeedsHandler) PollNewsFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
+	defer cancel()
+
+	feed := h.loadFeed(ctx, c)
+	if feed == nil {
+		return
+	}
+
+	res, err := h.ingester.Poll(ctx, *feed)
+	if errors.Is(err, newsfeeds.ErrPolling) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Feed is already being polled"})
+		return
+	}
+	if res.Error != "" {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to poll feed", "details": res.Error, "result": res})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// loadFeed resolves the :id path param to a feed, writing the error
+// response itself and returning nil when it can't
+func (h *NewsFeedsHandler) loadFeed(ctx context.Context, c *gin.Context) *database.NewsFeed {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return nil
+	}
+
+	feed, err := h.db.GetNewsFeed(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get news feed %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve news feed"})
+		return nil
+	}
+	if feed == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "News feed not found"})
+		return nil
+	}
+	return feed
+}
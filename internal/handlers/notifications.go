@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+)
+
+// NotificationsHandler manages per-channel notification settings
+type NotificationsHandler struct {
+	db       database.NotificationRepository
+	notifier *notifications.Notifier
+}
+
+// NewNotificationsHandler creates a new notifications handler
+func NewNotificationsHandler(db database.NotificationRepository, notifier *notifications.Notifier) *NotificationsHandler {
+	return &NotificationsHandler{db: db, notifier: notifier}
+}
+
+// notificationSettingBody is the settings request body
+type notificationSettingBody struct {
+	Destination   string  `json:"destination" binding:"required"`
+	Enabled       *bool   `json:"enabled"`
+	QuietStart    *string `json:"quiet_start"`
+	QuietEnd      *string `json:"quiet_end"`
+	DigestEnabled bool    `json:"digest_enabled"`
+	DigestTime    *string `json:"digest_time"`
+}
+
+// ListNotificationSettings handles GET /api/notifications/settings
+func (h *NotificationsHandler) ListNotificationSettings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	settings, err := h.db.ListNotificationSettings(ctx, requestAccountID(c), requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list notification settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification settings"})
+		return
+	}
+
+	now := time.Now()
+	quiet := map[string]bool{}
+	for _, s := range settings {
+		quiet[s.Channel] = notifications.InQuietHours(s, now)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"quiet":    quiet,
+		"channels": h.notifier.Channels(),
+	})
+}
+
+// PutNotificationSetting handles PUT /api/notifications/settings/:channel.
+// Body: destination, enabled (default true), quiet_start and quiet_end
+// (HH:MM IST, together or not at all, may wrap midnight), digest_enabled
+// and digest_time (HH:MM IST, required for digests). Critical alerts, such
+// as a signal nearing its stop-loss, ignore quiet hours and digests.
+func (h *NotificationsHandler) PutNotificationSetting(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var b notificationSettingBody
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "destination is required"})
+		return
+	}
+	s := database.NotificationSetting{
+		AccountID:     requestAccountID(c),
+		UserID:        requestUserID(c),
+		Channel:       strings.ToLower(c.Param("channel")),
+		Destination:   strings.TrimSpace(b.Destination),
+		Enabled:       b.Enabled == nil || *b.Enabled,
+		QuietStart:    trimmedOrNil(b.QuietStart),
+		QuietEnd:      trimmedOrNil(b.QuietEnd),
+		DigestEnabled: b.DigestEnabled,
+		DigestTime:    trimmedOrNil(b.DigestTime),
+	}
+
+	switch s.Channel {
+	case reports.ChannelEmail:
+		addr, err := mail.ParseAddress(s.Destination)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "destination must be an email address"})
+			return
+		}
+		s.Destination = addr.Address
+	case reports.ChannelTelegram:
+		if !telegramChatPattern.MatchString(s.Destination) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "destination must be a Telegram chat ID or @channel"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be email or telegram"})
+		return
+	}
+	if !h.notifier.Configured(s.Channel) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    s.Channel + " delivery is not configured on this server",
+			"channels": h.notifier.Channels(),
+		})
+		return
+	}
+
+	if (s.QuietStart == nil) != (s.QuietEnd == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quiet_start and quiet_end must be set together"})
+		return
+	}
+	for _, t := range []*string{s.QuietStart, s.QuietEnd, s.DigestTime} {
+		if t == nil {
+			continue
+		}
+		if _, _, err := reports.ParseSendTime(*t); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quiet_start, quiet_end and digest_time must be HH:MM"})
+			return
+		}
+	}
+	if s.DigestEnabled && s.DigestTime == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "digest_time is required when digest_enabled is true"})
+		return
+	}
+
+	saved, err := h.db.UpsertNotificationSetting(ctx, s)
+	if err != nil {
+		log.Printf("❌ Failed to save notification setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteNotificationSetting handles DELETE /api/notifications/settings/:channel.
+// Notifications held for the channel are discarded.
+func (h *NotificationsHandler) DeleteNotificationSetting(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	channel := strings.ToLower(c.Param("channel"))
+	deleted, err := h.db.DeleteNotificationSetting(ctx, requestAccountID(c), requestUserID(c), channel)
+	if err != nil {
+		log.Printf("❌ Failed to delete notification setting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification setting"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification setting not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification setting deleted", "channel": channel})
+}
+
+// trimmedOrNil returns nil for a missing or blank string
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
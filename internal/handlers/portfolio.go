@@ -6,14 +6,26 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
 )
 
-// GetPortfolioStats handles GET /api/portfolio/stats
+// GetPortfolioStats handles GET /api/portfolio/stats. With ?as_of it
+// reconstructs the stats as they stood at that instant.
 func (h *Handler) GetPortfolioStats(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	stats, err := h.db.GetPortfolioStats(ctx)
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
+	var stats *database.PortfolioStats
+	var err error
+	if asOf != nil {
+		stats, err = h.db.GetPortfolioStatsAsOf(ctx, *asOf)
+	} else {
+		stats, err = h.db.GetPortfolioStats(ctx)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get portfolio stats"})
 		return
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// zerodhaImportSource tags ledger rows imported from Zerodha; the tradebook CSV
+// and the Kite API share trade IDs, so both use the same source for dedupe
+const zerodhaImportSource = "zerodha"
+
+// maxImportSize caps uploaded tradebook files (10 MB)
+const maxImportSize = 10 << 20
+
+// importRowError describes a tradebook row that could not be imported
+type importRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportPortfolioTrades handles POST /api/portfolios/:id/import
+//
+// Accepts a Zerodha Console tradebook CSV (multipart field "file" or a raw
+// text/csv body), or ?source=kite to pull today's trades from the Kite API.
+func (h *Handler) ImportPortfolioTrades(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	var trades []database.ImportedTrade
+	var rowErrors []importRowError
+	skipped := 0
+
+	if c.Query("source") == "kite" {
+		config, err := h.db.GetBrokerConfig(ctx, "zerodha")
+		if err != nil || config == nil || config.AccessToken == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Zerodha is not authenticated"})
+			return
+		}
+		trades, err = fetchKiteTrades(ctx, config.APIKey, config.AccessToken)
+		if err != nil {
+			log.Printf("❌ Failed to fetch Kite trades: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Kite API error: %v", err)})
+			return
+		}
+	} else {
+		reader, err := importReader(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer reader.Close()
+
+		trades, rowErrors, skipped, err = parseZerodhaTradebook(reader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Insert in execution order so the ledger replays correctly
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].TradedAt.Before(trades[j].TradedAt)
+	})
+
+	result, err := h.db.ImportPortfolioTransactions(ctx, portfolio.ID, zerodhaImportSource, trades)
+	if errors.Is(err, database.ErrInsufficientQuantity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import rejected: " + err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to import trades into portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import trades"})
+		return
+	}
+
+	log.Printf("✅ Imported %d trades into portfolio %d (%d duplicates)", result.Imported, portfolio.ID, result.Duplicates)
+
+	// Broker trades double as signal fills for slippage tracking; a failure
+	// here must not fail the import
+	if matched, err := h.db.MatchBrokerFills(ctx, trades); err != nil {
+		log.Printf("⚠️  Failed to match imported trades to signals: %v", err)
+	} else if matched > 0 {
+		log.Printf("✅ Matched %d imported trades to signal fills", matched)
+	}
+
+	if rowErrors == nil {
+		rowErrors = []importRowError{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"portfolio_id": portfolio.ID,
+		"imported":     result.Imported,
+		"duplicates":   result.Duplicates,
+		"skipped":      skipped,
+		"errors":       rowErrors,
+	})
+}
+
+// importReader returns the uploaded tradebook from a multipart file or raw body
+func importReader(c *gin.Context) (io.ReadCloser, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("multipart field 'file' is required")
+		}
+		if fileHeader.Size > maxImportSize {
+			return nil, fmt.Errorf("file exceeds %d MB limit", maxImportSize>>20)
+		}
+		return fileHeader.Open()
+	}
+
+	if c.Request.Body == nil {
+		return nil, fmt.Errorf("request body is empty")
+	}
+	return http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize), nil
+}
+
+// parseZerodhaTradebook parses a Console tradebook export. Columns are matched
+// by header name; non-equity segments are counted as skipped.
+func parseZerodhaTradebook(r io.Reader) ([]database.ImportedTrade, []importRowError, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"symbol", "trade_type", "quantity", "price", "trade_id"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, 0, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	trades := []database.ImportedTrade{}
+	rowErrors := []importRowError{}
+	skipped := 0
+	row := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: err.Error()})
+			continue
+		}
+
+		if segment := strings.ToUpper(field(record, "segment")); segment != "" && segment != "EQ" {
+			skipped++
+			continue
+		}
+
+		txnType := strings.ToUpper(field(record, "trade_type"))
+		if txnType != "BUY" && txnType != "SELL" {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: "trade_type must be buy or sell"})
+			continue
+		}
+
+		quantity, err := strconv.ParseFloat(field(record, "quantity"), 64)
+		if err != nil || quantity <= 0 {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: "invalid quantity"})
+			continue
+		}
+		price, err := strconv.ParseFloat(field(record, "price"), 64)
+		if err != nil || price <= 0 {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: "invalid price"})
+			continue
+		}
+
+		tradeID := field(record, "trade_id")
+		if tradeID == "" {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: "missing trade_id"})
+			continue
+		}
+
+		tradedAt, ok := parseTradeTime(ist, field(record, "order_execution_time"), field(record, "trade_date"))
+		if !ok {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: "invalid trade_date/order_execution_time"})
+			continue
+		}
+
+		exchange := strings.ToUpper(field(record, "exchange"))
+		if exchange == "" {
+			exchange = "NSE"
+		}
+
+		trades = append(trades, database.ImportedTrade{
+			ExternalID: tradeID,
+			Symbol:     strings.ToUpper(field(record, "symbol")),
+			Exchange:   exchange,
+			TxnType:    txnType,
+			Quantity:   quantity,
+			Price:      price,
+			TradedAt:   tradedAt,
+		})
+	}
+
+	return trades, rowErrors, skipped, nil
+}
+
+// parseTradeTime prefers the execution timestamp and falls back to the trade date
+func parseTradeTime(loc *time.Location, values ...string) (time.Time, bool) {
+	layouts := []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02", "02-01-2006"}
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// fetchKiteTrades pulls the day's executed equity trades from the Kite API
+func fetchKiteTrades(ctx context.Context, apiKey, accessToken string) ([]database.ImportedTrade, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.kite.trade/trades", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Kite-Version", "3")
+	req.Header.Set("Authorization", fmt.Sprintf("token %s:%s", apiKey, accessToken))
+
+	resp, err := kiteClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var kiteResp struct {
+		Status string `json:"status"`
+		Data   []struct {
+			TradeID         string  `json:"trade_id"`
+			Exchange        string  `json:"exchange"`
+			TradingSymbol   string  `json:"tradingsymbol"`
+			TransactionType string  `json:"transaction_type"`
+			Quantity        float64 `json:"quantity"`
+			AveragePrice    float64 `json:"average_price"`
+			FillTimestamp   string  `json:"fill_timestamp"`
+		} `json:"data"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&kiteResp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if kiteResp.Status != "success" {
+		return nil, fmt.Errorf("%s", kiteResp.Message)
+	}
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	trades := []database.ImportedTrade{}
+	for _, t := range kiteResp.Data {
+		if t.Exchange != "NSE" && t.Exchange != "BSE" {
+			continue
+		}
+		tradedAt, ok := parseTradeTime(ist, t.FillTimestamp)
+		if !ok {
+			tradedAt = time.Now()
+		}
+		trades = append(trades, database.ImportedTrade{
+			ExternalID: t.TradeID,
+			Symbol:     t.TradingSymbol,
+			Exchange:   t.Exchange,
+			TxnType:    strings.ToUpper(t.TransactionType),
+			Quantity:   t.Quantity,
+			Price:      t.AveragePrice,
+			TradedAt:   tradedAt,
+		})
+	}
+	return trades, nil
+}
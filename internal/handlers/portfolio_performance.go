@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// GetPortfolioPerformance handles GET /api/portfolios/:id/performance
+func (h *Handler) GetPortfolioPerformance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	period := strings.ToUpper(c.DefaultQuery("period", "1Y"))
+	if _, err := database.PeriodStart(period, time.Now(), time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	perf, err := h.db.GetPortfolioPerformance(ctx, portfolio.ID, period)
+	if err != nil {
+		log.Printf("❌ Failed to compute performance for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute performance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, perf)
+}
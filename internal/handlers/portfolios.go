@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// defaultUserID is used when a request carries no user identity
+const defaultUserID = "default"
+
+// requestUserID returns the caller's user identity from the X-User-ID header
+func requestUserID(c *gin.Context) string {
+	if userID := strings.TrimSpace(c.GetHeader("X-User-ID")); userID != "" {
+		return userID
+	}
+	return defaultUserID
+}
+
+// loadPortfolio resolves the :id path param to a portfolio owned by the caller,
+// writing the error response itself and returning nil when it can't
+func (h *Handler) loadPortfolio(ctx context.Context, c *gin.Context) *database.Portfolio {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid portfolio ID"})
+		return nil
+	}
+
+	portfolio, err := h.db.GetPortfolio(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get portfolio %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve portfolio"})
+		return nil
+	}
+	if portfolio == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found"})
+		return nil
+	}
+	return portfolio
+}
+
+// ListPortfolios handles GET /api/portfolios
+func (h *Handler) ListPortfolios(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	portfolios, err := h.db.ListPortfolios(ctx, requestAccountID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list portfolios: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve portfolios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"portfolios": portfolios,
+		"count":      len(portfolios),
+	})
+}
+
+// CreatePortfolio handles POST /api/portfolios
+func (h *Handler) CreatePortfolio(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Name         string  `json:"name"`
+		Description  *string `json:"description"`
+		BaseCurrency string  `json:"base_currency"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Portfolio name is required"})
+		return
+	}
+
+	portfolio, err := h.db.CreatePortfolio(ctx, requestAccountID(c), requestUserID(c), strings.TrimSpace(body.Name), body.Description, body.BaseCurrency)
+	if err != nil {
+		log.Printf("❌ Failed to create portfolio: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create portfolio"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, portfolio)
+}
+
+// GetPortfolio handles GET /api/portfolios/:id
+func (h *Handler) GetPortfolio(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	summary, err := h.db.GetPortfolioHoldings(ctx, portfolio.ID)
+	if err != nil {
+		log.Printf("❌ Failed to compute holdings for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute holdings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"portfolio": portfolio,
+		"summary":   summary,
+	})
+}
+
+// UpdatePortfolio handles PUT /api/portfolios/:id
+func (h *Handler) UpdatePortfolio(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid portfolio ID"})
+		return
+	}
+
+	var body struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if body.Name != nil && strings.TrimSpace(*body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Portfolio name cannot be empty"})
+		return
+	}
+
+	portfolio, err := h.db.UpdatePortfolio(ctx, requestAccountID(c), id, body.Name, body.Description)
+	if err != nil {
+		log.Printf("❌ Failed to update portfolio %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update portfolio"})
+		return
+	}
+	if portfolio == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio)
+}
+
+// DeletePortfolio handles DELETE /api/portfolios/:id
+func (h *Handler) DeletePortfolio(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid portfolio ID"})
+		return
+	}
+
+	deleted, err := h.db.DeletePortfolio(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete portfolio %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete portfolio"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Portfolio deleted", "id": id})
+}
+
+// GetPortfolioHoldings handles GET /api/portfolios/:id/holdings
+func (h *Handler) GetPortfolioHoldings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	summary, err := h.db.GetPortfolioHoldings(ctx, portfolio.ID)
+	if err != nil {
+		log.Printf("❌ Failed to compute holdings for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute holdings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ListPortfolioTransactions handles GET /api/portfolios/:id/transactions
+func (h *Handler) ListPortfolioTransactions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	txns, err := h.db.ListPortfolioTransactions(ctx, portfolio.ID)
+	if err != nil {
+		log.Printf("❌ Failed to list transactions for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": txns,
+		"count":        len(txns),
+	})
+}
+
+// AddPortfolioTransaction handles POST /api/portfolios/:id/transactions
+func (h *Handler) AddPortfolioTransaction(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	var body struct {
+		Symbol   string  `json:"symbol"`
+		Exchange string  `json:"exchange"`
+		TxnType  string  `json:"txn_type"`
+		Quantity float64 `json:"quantity"`
+		Price    float64 `json:"price"`
+		Fees     float64 `json:"fees"`
+		Amount   float64 `json:"amount"`
+		TradedAt string  `json:"traded_at"`
+		Notes    *string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	body.Symbol = strings.ToUpper(strings.TrimSpace(body.Symbol))
+	body.Exchange = strings.ToUpper(strings.TrimSpace(body.Exchange))
+	body.TxnType = strings.ToUpper(strings.TrimSpace(body.TxnType))
+
+	if body.Symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Symbol is required"})
+		return
+	}
+	switch body.TxnType {
+	case "BUY", "SELL":
+		if body.Quantity <= 0 || body.Price <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity and price must be positive"})
+			return
+		}
+	case "DIVIDEND":
+		if body.Amount <= 0 && (body.Quantity <= 0 || body.Price <= 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dividend requires amount or quantity and per-share price"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "txn_type must be BUY, SELL or DIVIDEND"})
+		return
+	}
+	if body.Fees < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fees cannot be negative"})
+		return
+	}
+
+	tradedAt := time.Now()
+	if body.TradedAt != "" {
+		parsed, err := time.Parse(time.RFC3339, body.TradedAt)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", body.TradedAt)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "traded_at must be RFC3339 or YYYY-MM-DD"})
+			return
+		}
+		tradedAt = parsed
+	}
+
+	txn, err := h.db.AddPortfolioTransaction(ctx, database.PortfolioTransaction{
+		PortfolioID: portfolio.ID,
+		Symbol:      body.Symbol,
+		Exchange:    body.Exchange,
+		TxnType:     body.TxnType,
+		Quantity:    body.Quantity,
+		Price:       body.Price,
+		Fees:        body.Fees,
+		Amount:      body.Amount,
+		Notes:       body.Notes,
+	}, tradedAt)
+	if errors.Is(err, database.ErrInsufficientQuantity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sell quantity exceeds current holding"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to add transaction to portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
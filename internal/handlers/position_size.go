@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// defaultRiskFraction is the share of capital the fixed-fractional method
+// risks per trade
+const defaultRiskFraction = 0.01
+
+// PositionSizeHandler suggests position sizes from signal history
+type PositionSizeHandler struct {
+	signals database.SignalRepository
+	sizing  database.PositionSizeRepository
+}
+
+// NewPositionSizeHandler creates a new position size handler
+func NewPositionSizeHandler(signals database.SignalRepository, sizing database.PositionSizeRepository) *PositionSizeHandler {
+	return &PositionSizeHandler{signals: signals, sizing: sizing}
+}
+
+// GetPositionSize handles POST /api/quant/position-size.
+// Body: either signal_id, sized from the symbol's signal history and the
+// signal's own stop, or win_rate (0-1), payoff_ratio and stop_distance_pct
+// with an optional entry_price. Both accept capital (default ₹10L) and
+// risk_fraction for the fixed-fractional size (default 0.01).
+func (h *PositionSizeHandler) GetPositionSize(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var body struct {
+		SignalID        string   `json:"signal_id"`
+		WinRate         *float64 `json:"win_rate"`
+		PayoffRatio     *float64 `json:"payoff_ratio"`
+		StopDistancePct float64  `json:"stop_distance_pct"`
+		EntryPrice      *float64 `json:"entry_price"`
+		Capital         float64  `json:"capital"`
+		RiskFraction    float64  `json:"risk_fraction"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if body.Capital == 0 {
+		body.Capital = defaultBaseCapital
+	}
+	if body.RiskFraction == 0 {
+		body.RiskFraction = defaultRiskFraction
+	}
+	if body.Capital < 0 || body.RiskFraction < 0 || body.RiskFraction > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "capital must be positive and risk_fraction between 0 and 1"})
+		return
+	}
+
+	if body.SignalID != "" {
+		signal, err := h.signals.GetSignalByID(ctx, body.SignalID)
+		if err != nil {
+			log.Printf("❌ Failed to get signal %s: %v", body.SignalID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal"})
+			return
+		}
+		if signal == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+			return
+		}
+		size, err := h.sizing.GetSignalPositionSize(ctx, signal, body.Capital, body.RiskFraction)
+		if err != nil {
+			log.Printf("❌ Failed to size signal %s: %v", body.SignalID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute position size"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"signal_id": signal.SignalID, "symbol": signal.Symbol, "position_size": size})
+		return
+	}
+
+	if body.WinRate == nil || body.PayoffRatio == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "signal_id or win_rate and payoff_ratio are required"})
+		return
+	}
+	if *body.WinRate < 0 || *body.WinRate > 1 || *body.PayoffRatio <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "win_rate must be between 0 and 1 and payoff_ratio positive"})
+		return
+	}
+	if body.StopDistancePct < 0 || body.StopDistancePct >= 100 || (body.EntryPrice != nil && *body.EntryPrice <= 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stop_distance_pct must be 0-100 and entry_price positive"})
+		return
+	}
+
+	size := database.ComputePositionSize(database.PositionSizeInput{
+		WinRate:         *body.WinRate,
+		PayoffRatio:     *body.PayoffRatio,
+		Capital:         body.Capital,
+		RiskFraction:    body.RiskFraction,
+		StopDistancePct: body.StopDistancePct,
+		EntryPrice:      body.EntryPrice,
+	})
+	if body.StopDistancePct == 0 {
+		size.Notes = append(size.Notes, "No stop_distance_pct given; only capital at risk is sized")
+	}
+	c.JSON(http.StatusOK, gin.H{"position_size": size})
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/nlquery"
+)
+
+// presetFilters lists, per scope, the query parameters a preset may save.
+// Paging and fixed dates are left out so a saved view stays current.
+var presetFilters = map[string][]string{
+	"signals":              {"status", "limit", "q", "when", "mine"},
+	"news":                 {"sentiment", "search", "symbol", "collapse", "limit"},
+	"scans/orb":            {"range", "direction", "include", "limit", "q", "when"},
+	"scans/gaps":           {"min_gap", "direction", "unfilled", "limit", "q", "when"},
+	"scans/unusual-volume": {"min_ratio", "sector", "limit", "q"},
+	"scans/circuits":       {"side", "sector", "limit", "q"},
+}
+
+// maxPresetValue caps the length of one saved filter value
+const maxPresetValue = 500
+
+// PresetsHandler serves saved filter presets
+type PresetsHandler struct {
+	db database.PresetRepository
+}
+
+// NewPresetsHandler creates a new presets handler
+func NewPresetsHandler(db database.PresetRepository) *PresetsHandler {
+	return &PresetsHandler{db: db}
+}
+
+// presetScopes returns the scopes presets can be saved for, sorted
+func presetScopes() []string {
+	scopes := make([]string, 0, len(presetFilters))
+	for scope := range presetFilters {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// validatePresetFilters checks filters against the scope's parameters,
+// writing the error response itself and returning false when they're invalid
+func validatePresetFilters(c *gin.Context, scope string, filters map[string]string) bool {
+	allowed := presetFilters[scope]
+	for key, value := range filters {
+		known := false
+		for _, a := range allowed {
+			known = known || a == key
+		}
+		if !known {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Unknown filter " + key + " for " + scope,
+				"filters": allowed,
+			})
+			return false
+		}
+		if strings.TrimSpace(value) == "" || len(value) > maxPresetValue {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Filter " + key + " needs a value of at most " + strconv.Itoa(maxPresetValue) + " characters",
+			})
+			return false
+		}
+	}
+	if when, ok := filters["when"]; ok {
+		if _, err := nlquery.ParseWhen(when, time.Now()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// presetError maps errors from saving a preset to a response
+func presetError(c *gin.Context, action string, err error) {
+	if errors.Is(err, database.ErrPresetNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A preset with this name already exists for this scope"})
+		return
+	}
+	log.Printf("❌ Failed to %s preset: %v", action, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " preset"})
+}
+
+// ListPresets handles GET /api/presets.
+// Query: scope (signals, news, scans/orb, ...).
+func (h *PresetsHandler) ListPresets(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	scope := c.Query("scope")
+	if _, ok := presetFilters[scope]; scope != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope", "scopes": presetScopes()})
+		return
+	}
+
+	presets, err := h.db.ListPresets(ctx, requestAccountID(c), scope)
+	if err != nil {
+		log.Printf("❌ Failed to list presets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve presets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"presets": presets,
+		"count":   len(presets),
+	})
+}
+
+// CreatePreset handles POST /api/presets. filters are the query parameters
+// to apply, e.g. {"status": "ACTIVE"} for scope signals.
+func (h *PresetsHandler) CreatePreset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Name    string            `json:"name"`
+		Scope   string            `json:"scope"`
+		Filters map[string]string `json:"filters"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Preset name is required"})
+		return
+	}
+	if _, ok := presetFilters[body.Scope]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope", "scopes": presetScopes()})
+		return
+	}
+	if !validatePresetFilters(c, body.Scope, body.Filters) {
+		return
+	}
+
+	preset, err := h.db.CreatePreset(ctx, database.Preset{
+		AccountID: requestAccountID(c),
+		UserID:    requestUserID(c),
+		Name:      strings.TrimSpace(body.Name),
+		Scope:     body.Scope,
+		Filters:   body.Filters,
+	})
+	if err != nil {
+		presetError(c, "create", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, preset)
+}
+
+// GetPreset handles GET /api/presets/:id
+func (h *PresetsHandler) GetPreset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preset ID"})
+		return
+	}
+
+	preset, err := h.db.GetPreset(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get preset %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preset"})
+		return
+	}
+	if preset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// UpdatePreset handles PUT /api/presets/:id, renaming the preset or
+// replacing its filters. The scope can't change.
+func (h *PresetsHandler) UpdatePreset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preset ID"})
+		return
+	}
+
+	var body struct {
+		Name    *string           `json:"name"`
+		Filters map[string]string `json:"filters"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	preset, err := h.db.GetPreset(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get preset %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preset"})
+		return
+	}
+	if preset == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preset not found"})
+		return
+	}
+
+	if body.Name != nil {
+		preset.Name = strings.TrimSpace(*body.Name)
+		if preset.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Preset name cannot be empty"})
+			return
+		}
+	}
+	if body.Filters != nil {
+		if !validatePresetFilters(c, preset.Scope, body.Filters) {
+			return
+		}
+		preset.Filters = body.Filters
+	}
+
+	updated, err := h.db.UpdatePreset(ctx, *preset)
+	if err != nil {
+		presetError(c, "update", err)
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeletePreset handles DELETE /api/presets/:id
+func (h *PresetsHandler) DeletePreset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preset ID"})
+		return
+	}
+
+	deleted, err := h.db.DeletePreset(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete preset %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete preset"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Preset deleted", "id": id})
+}
+
+// PresetMiddleware applies ?preset=<id> on the endpoints presets are saved
+// for, filling in the preset's filters wherever the request doesn't set the
+// parameter itself. It runs ahead of anything that reads the query, since
+// gin caches the query on first read. Applied presets are echoed in an
+// X-Preset-ID header. Presets are looked up in the request's account.
+func PresetMiddleware(db database.PresetRepository, accounts *AccountScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if c.Request.Method != http.MethodGet || !query.Has("preset") {
+			c.Next()
+			return
+		}
+
+		scope := strings.TrimPrefix(c.FullPath(), "/api/")
+		if _, ok := presetFilters[scope]; !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Presets can't be applied to " + c.Request.URL.Path})
+			return
+		}
+		id, err := strconv.ParseInt(query.Get("preset"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid preset ID"})
+			return
+		}
+
+		if !accounts.resolve(c) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		preset, err := db.GetPreset(ctx, requestAccountID(c), id)
+		cancel()
+		if err != nil {
+			log.Printf("❌ Failed to get preset %d: %v", id, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preset"})
+			return
+		}
+		if preset == nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Preset not found"})
+			return
+		}
+		if preset.Scope != scope {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "Preset " + strconv.FormatInt(id, 10) + " is for /api/" + preset.Scope,
+			})
+			return
+		}
+
+		query.Del("preset")
+		for key, value := range preset.Filters {
+			if !query.Has(key) {
+				query.Set(key, value)
+			}
+		}
+		c.Request.URL.RawQuery = query.Encode()
+		c.Header("X-Preset-ID", strconv.FormatInt(id, 10))
+		c.Next()
+	}
+}
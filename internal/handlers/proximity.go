@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/prices"
+	"github.com/trading-chitti/core-api-go/internal/proximity"
+)
+
+// ProximityHandler annotates active signals with distance to their levels
+type ProximityHandler struct {
+	db     database.SignalRepository
+	prices *prices.Store
+	band   float64
+}
+
+// NewProximityHandler creates a new proximity handler
+func NewProximityHandler(db database.SignalRepository, priceStore *prices.Store, bandPct float64) *ProximityHandler {
+	return &ProximityHandler{db: db, prices: priceStore, band: bandPct}
+}
+
+// signalProximity is an active signal with its distances
+type signalProximity struct {
+	database.Signal
+	proximity.Distance
+	PriceSource string     `json:"price_source"`
+	PriceAt     *time.Time `json:"price_at"`
+}
+
+// GetProximity handles GET /api/signals/active/proximity. Signals closest to
+// either level come first; ?band= overrides the alert band in percent.
+func (h *ProximityHandler) GetProximity(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	band := h.band
+	if v, err := strconv.ParseFloat(c.Query("band"), 64); err == nil && v > 0 {
+		band = v
+	}
+
+	signals, err := h.db.GetActiveSignals(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to get active signals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve active signals",
+		})
+		return
+	}
+
+	result := make([]signalProximity, 0, len(signals))
+	near := 0
+	for _, s := range signals {
+		sp := signalProximity{Signal: s, PriceSource: "signal"}
+		price := s.CurrentPrice
+		if tick, at, ok := h.prices.Latest(s.Symbol); ok {
+			price, sp.PriceSource, sp.PriceAt = tick, "tick", &at
+		}
+		sp.Distance = proximity.Measure(s.SignalType, price, s.TargetPrice, s.StopLoss, band)
+		if sp.NearTarget || sp.NearStop {
+			near++
+		}
+		result = append(result, sp)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return closest(result[i].Distance) < closest(result[j].Distance)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"signals":  result,
+		"count":    len(result),
+		"near":     near,
+		"band_pct": band,
+	})
+}
+
+// closest is the smaller distance to either level; signals past a level
+// sort first
+func closest(d proximity.Distance) float64 {
+	return math.Min(d.ToTargetPct, d.ToStopPct)
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	"github.com/trading-chitti/core-api-go/internal/push"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+)
+
+// Device token formats: APNs tokens are hex, FCM registration tokens are
+// URL-safe base64 with a colon-separated prefix
+var (
+	apnsTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{64,200}$`)
+	fcmTokenPattern  = regexp.MustCompile(`^[A-Za-z0-9_:.\-]{32,1000}$`)
+)
+
+// PushDevicesHandler registers mobile devices for push notifications
+type PushDevicesHandler struct {
+	db       database.PushDeviceRepository
+	notifier *notifications.Notifier
+}
+
+// NewPushDevicesHandler creates a new push devices handler
+func NewPushDevicesHandler(db database.PushDeviceRepository, notifier *notifications.Notifier) *PushDevicesHandler {
+	return &PushDevicesHandler{db: db, notifier: notifier}
+}
+
+// pushPreferencesBody is the preference part of the device request bodies
+type pushPreferencesBody struct {
+	Name         string   `json:"name"`
+	Enabled      *bool    `json:"enabled"`
+	Kinds        []string `json:"kinds"`
+	CriticalOnly bool     `json:"critical_only"`
+	QuietStart   *string  `json:"quiet_start"`
+	QuietEnd     *string  `json:"quiet_end"`
+}
+
+// device validates the preferences, returning the device they describe or
+// an error message
+func (b pushPreferencesBody) device(accountID, userID string) (database.PushDevice, string) {
+	d := database.PushDevice{
+		AccountID:    accountID,
+		UserID:       userID,
+		Name:         strings.TrimSpace(b.Name),
+		Enabled:      b.Enabled == nil || *b.Enabled,
+		Kinds:        []string{},
+		CriticalOnly: b.CriticalOnly,
+		QuietStart:   trimmedOrNil(b.QuietStart),
+		QuietEnd:     trimmedOrNil(b.QuietEnd),
+	}
+	if len(d.Name) > 100 {
+		return d, "name must be at most 100 characters"
+	}
+	known := map[string]bool{}
+	for _, k := range notifications.Kinds {
+		known[k] = true
+	}
+	for _, k := range b.Kinds {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !known[k] {
+			return d, "kinds must be among " + strings.Join(notifications.Kinds, ", ")
+		}
+		d.Kinds = append(d.Kinds, k)
+	}
+	if (d.QuietStart == nil) != (d.QuietEnd == nil) {
+		return d, "quiet_start and quiet_end must be set together"
+	}
+	for _, t := range []*string{d.QuietStart, d.QuietEnd} {
+		if t == nil {
+			continue
+		}
+		if _, _, err := reports.ParseSendTime(*t); err != nil {
+			return d, "quiet_start and quiet_end must be HH:MM"
+		}
+	}
+	return d, ""
+}
+
+// ListPushDevices handles GET /api/notifications/devices
+func (h *PushDevicesHandler) ListPushDevices(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	devices, err := h.db.ListPushDevices(ctx, requestAccountID(c), requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list push devices: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve push devices"})
+		return
+	}
+
+	now := time.Now()
+	quiet := map[int64]bool{}
+	for _, d := range devices {
+		quiet[d.ID] = notifications.DeviceInQuietHours(d, now)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"devices":   devices,
+		"quiet":     quiet,
+		"platforms": h.notifier.PushPlatforms(),
+		"kinds":     notifications.Kinds,
+	})
+}
+
+// RegisterPushDevice handles POST /api/notifications/devices.
+// Body: platform (fcm or apns), token, and the preferences name, enabled
+// (default true), kinds (notification kinds to receive, all when empty),
+// critical_only, and quiet_start and quiet_end (HH:MM IST, together or not
+// at all). Registering a token again updates it, moving it to the caller if
+// another user had it.
+func (h *PushDevicesHandler) RegisterPushDevice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var b struct {
+		Platform string `json:"platform" binding:"required"`
+		Token    string `json:"token" binding:"required"`
+		pushPreferencesBody
+	}
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "platform and token are required"})
+		return
+	}
+	d, msg := b.device(requestAccountID(c), requestUserID(c))
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	d.Platform = strings.ToLower(strings.TrimSpace(b.Platform))
+	d.Token = strings.TrimSpace(b.Token)
+
+	switch d.Platform {
+	case push.PlatformAPNs:
+		if !apnsTokenPattern.MatchString(d.Token) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "token must be a hex APNs device token"})
+			return
+		}
+	case push.PlatformFCM:
+		if !fcmTokenPattern.MatchString(d.Token) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "token must be an FCM registration token"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "platform must be fcm or apns"})
+		return
+	}
+	if !h.notifier.PushConfigured(d.Platform) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":     d.Platform + " push is not configured on this server",
+			"platforms": h.notifier.PushPlatforms(),
+		})
+		return
+	}
+
+	saved, err := h.db.RegisterPushDevice(ctx, d)
+	if err != nil {
+		log.Printf("❌ Failed to register push device: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register push device"})
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+// UpdatePushDevice handles PUT /api/notifications/devices/:id, replacing
+// the device's preferences. Body as for registering, without platform and
+// token.
+func (h *PushDevicesHandler) UpdatePushDevice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	var b pushPreferencesBody
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	d, msg := b.device(requestAccountID(c), requestUserID(c))
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	d.ID = id
+
+	saved, err := h.db.UpdatePushDevice(ctx, d)
+	if err != nil {
+		log.Printf("❌ Failed to update push device %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update push device"})
+		return
+	}
+	if saved == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Push device not found"})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeletePushDevice handles DELETE /api/notifications/devices/:id
+func (h *PushDevicesHandler) DeletePushDevice(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	deleted, err := h.db.DeletePushDevice(ctx, requestAccountID(c), requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete push device %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete push device"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Push device not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Push device deleted", "id": id})
+}
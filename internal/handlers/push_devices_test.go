@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPushTokenPatterns also guards package initialisation: the patterns
+// are compiled with MustCompile, so an invalid one panics before any test
+// runs and the server can't start
+func TestPushTokenPatterns(t *testing.T) {
+	fcm := "cXk2:APA91b" + strings.Repeat("A", 140)
+	for _, tt := range []struct {
+		name, token string
+		apns, fcm   bool
+	}{
+		{"apns", strings.Repeat("0a", 32), true, true},
+		{"fcm", fcm, false, true},
+		{"longest fcm", strings.Repeat("a", 1000), false, true},
+		{"too long", strings.Repeat("a", 1001), false, false},
+		{"too short", "abc123", false, false},
+		{"bad characters", strings.Repeat("a", 40) + " ", false, false},
+	} {
+		if got := apnsTokenPattern.MatchString(tt.token); got != tt.apns {
+			t.Errorf("%s: APNs match = %v, want %v", tt.name, got, tt.apns)
+		}
+		if got := fcmTokenPattern.MatchString(tt.token); got != tt.fcm {
+			t.Errorf("%s: FCM match = %v, want %v", tt.name, got, tt.fcm)
+		}
+	}
+}
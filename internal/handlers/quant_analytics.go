@@ -6,11 +6,15 @@ import (
 	"math"
 	"net/http"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// defaultBaseCapital (₹10L) is used when the caller has no portfolio holdings
+const defaultBaseCapital = 1000000.0
+
 // QuantAnalyticsHandler handles quantitative analytics endpoints
 type QuantAnalyticsHandler struct {
 	db *sql.DB
@@ -64,22 +68,38 @@ type AlphaFactor struct {
 
 // GetQuantAnalytics handles GET /api/quant/analytics
 func (h *QuantAnalyticsHandler) GetQuantAnalytics(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var portfolioID *int64
+	if v := c.Query("portfolio_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid portfolio_id"})
+			return
+		}
+		portfolioID = &id
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	portfolio, err := h.calculatePortfolioMetrics(ctx)
+	baseCapital, capitalSource, err := h.resolveBaseCapital(ctx, c, portfolioID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve base capital"})
+		return
+	}
+
+	portfolio, err := h.calculatePortfolioMetrics(ctx, baseCapital)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate portfolio metrics"})
 		return
 	}
 
-	risk, err := h.calculateRiskMetrics(ctx)
+	risk, err := h.calculateRiskMetrics(ctx, baseCapital)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate risk metrics"})
 		return
 	}
 
-	performance, err := h.calculatePerformanceMetrics(ctx)
+	performance, err := h.calculatePerformanceMetrics(ctx, baseCapital)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate performance metrics"})
 		return
@@ -96,11 +116,51 @@ func (h *QuantAnalyticsHandler) GetQuantAnalytics(c *gin.Context) {
 		"risk":        risk,
 		"alphas":      alphas,
 		"performance": performance,
-		"timestamp":   time.Now().Format(time.RFC3339),
+		"capital": gin.H{
+			"base_capital": baseCapital,
+			"source":       capitalSource,
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
 	})
 }
 
-func (h *QuantAnalyticsHandler) calculatePortfolioMetrics(ctx context.Context) (*PortfolioMetrics, error) {
+// resolveBaseCapital returns the capital analytics are scaled against: the market
+// value of the caller's portfolio ledger (or just portfolioID when set),
+// falling back to defaultBaseCapital when no holdings are recorded
+func (h *QuantAnalyticsHandler) resolveBaseCapital(ctx context.Context, c *gin.Context, portfolioID *int64) (float64, string, error) {
+	query := `
+		WITH positions AS (
+			SELECT t.symbol,
+				SUM(CASE t.txn_type WHEN 'BUY' THEN t.quantity WHEN 'SELL' THEN -t.quantity ELSE 0 END) AS qty,
+				SUM(CASE t.txn_type WHEN 'BUY' THEN t.quantity * t.price + t.fees ELSE 0 END) /
+					NULLIF(SUM(CASE t.txn_type WHEN 'BUY' THEN t.quantity ELSE 0 END), 0) AS avg_buy
+			FROM portfolio.transactions t
+			JOIN portfolio.portfolios p ON p.id = t.portfolio_id
+			WHERE p.account_id = $1 AND ($2::BIGINT IS NULL OR p.id = $2)
+			GROUP BY t.symbol
+		)
+		SELECT COALESCE(SUM(pos.qty * COALESCE(rp.last_price, pos.avg_buy, 0)), 0)
+		FROM positions pos
+		LEFT JOIN LATERAL (
+			SELECT last_price FROM md.realtime_prices
+			WHERE symbol = pos.symbol
+			ORDER BY updated_at DESC
+			LIMIT 1
+		) rp ON true
+		WHERE pos.qty > 0
+	`
+
+	var capital float64
+	if err := h.db.QueryRowContext(ctx, query, requestAccountID(c), portfolioID).Scan(&capital); err != nil {
+		return 0, "", err
+	}
+	if capital <= 0 {
+		return defaultBaseCapital, "default", nil
+	}
+	return capital, "portfolio", nil
+}
+
+func (h *QuantAnalyticsHandler) calculatePortfolioMetrics(ctx context.Context, baseCapital float64) (*PortfolioMetrics, error) {
 	// Calculate daily PnL from today's closed signals
 	var dailyPnL float64
 	var totalSignalsToday int
@@ -173,8 +233,6 @@ func (h *QuantAnalyticsHandler) calculatePortfolioMetrics(ctx context.Context) (
 	// Calculate max drawdown
 	maxDrawdown := h.calculateMaxDrawdown(ctx)
 
-	// Assume starting capital of ₹10L for portfolio value calculation
-	baseCapital := 1000000.0
 	totalValue := baseCapital + (monthlyPnL * baseCapital / 100)
 
 	return &PortfolioMetrics{
@@ -320,7 +378,7 @@ func (h *QuantAnalyticsHandler) calculateMaxDrawdown(ctx context.Context) float6
 	return -maxDrawdown // Return as negative
 }
 
-func (h *QuantAnalyticsHandler) calculateRiskMetrics(ctx context.Context) (*RiskMetrics, error) {
+func (h *QuantAnalyticsHandler) calculateRiskMetrics(ctx context.Context, baseCapital float64) (*RiskMetrics, error) {
 	// Get daily returns for last 30 days
 	rows, err := h.db.QueryContext(ctx, `
 		SELECT
@@ -406,8 +464,6 @@ func (h *QuantAnalyticsHandler) calculateRiskMetrics(ctx context.Context) (*Risk
 	beta := volatility / 0.18 // Assuming Nifty volatility ~18%
 	correlation := 0.65 // Typical correlation for Indian stocks
 
-	baseCapital := 1000000.0
-
 	return &RiskMetrics{
 		VaR95:              var95 * baseCapital / 100,
 		CVaR95:             cvar95 * baseCapital / 100,
@@ -418,7 +474,7 @@ func (h *QuantAnalyticsHandler) calculateRiskMetrics(ctx context.Context) (*Risk
 	}, nil
 }
 
-func (h *QuantAnalyticsHandler) calculatePerformanceMetrics(ctx context.Context) (*PerformanceMetrics, error) {
+func (h *QuantAnalyticsHandler) calculatePerformanceMetrics(ctx context.Context, baseCapital float64) (*PerformanceMetrics, error) {
 	var metrics PerformanceMetrics
 
 	// Get total trades and win/loss counts
@@ -460,8 +516,6 @@ func (h *QuantAnalyticsHandler) calculatePerformanceMetrics(ctx context.Context)
 			AND generated_at >= CURRENT_DATE - INTERVAL '30 days'
 	`).Scan(&avgLoss)
 
-	baseCapital := 1000000.0
-
 	if avgWin.Valid {
 		metrics.AvgWin = avgWin.Float64 * baseCapital / 100
 	}
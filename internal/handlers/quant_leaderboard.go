@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Leaderboard sample guards: sources with fewer closed signals are listed
+// unranked, and Sharpe needs this many trading days of returns
+const (
+	leaderboardMinTrades = 20
+	leaderboardMinDays   = 5
+)
+
+// signalSourceExpr attributes a signal to the generator behind it: a model
+// version, an engine strategy, an external provider, a manual entry, or
+// the engine when nothing more specific is recorded
+const signalSourceExpr = `
+	CASE
+		WHEN metadata->>'model_version' IS NOT NULL THEN 'model:' || (metadata->>'model_version')
+		WHEN metadata->>'strategy' IS NOT NULL THEN 'strategy:' || (metadata->>'strategy')
+		WHEN metadata->>'provider' IS NOT NULL THEN 'provider:' || (metadata->>'provider')
+		WHEN metadata->>'source' = 'manual' THEN 'manual'
+		ELSE 'engine'
+	END`
+
+// LeaderboardEntry is one signal source's rolling performance. HitRate is
+// hits over closed signals, NetPnLPct the summed actual_profit_pct of closed
+// signals, and Sharpe the annualised mean over deviation of daily P&L.
+type LeaderboardEntry struct {
+	Rank         *int     `json:"rank"`
+	Source       string   `json:"source"`
+	Kind         string   `json:"kind"`
+	Signals      int      `json:"signals"`
+	Closed       int      `json:"closed"`
+	Hits         int      `json:"hits"`
+	HitRate      *float64 `json:"hit_rate"`
+	NetPnLPct    float64  `json:"net_pnl_pct"`
+	AvgPnLPct    *float64 `json:"avg_pnl_pct"`
+	Sharpe       *float64 `json:"sharpe"`
+	TradingDays  int      `json:"trading_days"`
+	LowSample    bool     `json:"low_sample"`
+	dailyReturns []float64
+}
+
+// leaderboardSorts maps ?sort= to the metric sources are ranked by
+var leaderboardSorts = map[string]func(e *LeaderboardEntry) float64{
+	"sharpe":   func(e *LeaderboardEntry) float64 { return derefOr(e.Sharpe, math.Inf(-1)) },
+	"hit_rate": func(e *LeaderboardEntry) float64 { return derefOr(e.HitRate, math.Inf(-1)) },
+	"net_pnl":  func(e *LeaderboardEntry) float64 { return e.NetPnLPct },
+}
+
+func derefOr(v *float64, fallback float64) float64 {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+// GetLeaderboard handles GET /api/quant/leaderboard.
+// Query: days (rolling window, default 30, 7-365), sort (sharpe, hit_rate
+// or net_pnl; default sharpe) and min_trades (default 20). Signal sources
+// (model versions, strategies, providers, manual) are ranked by the chosen
+// metric; sources with fewer closed signals than min_trades are listed
+// after the ranked ones with no rank, and Sharpe is only reported with at
+// least 5 trading days of closed signals.
+func (h *QuantAnalyticsHandler) GetLeaderboard(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 7 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 7 and 365"})
+		return
+	}
+	sortBy := strings.ToLower(c.DefaultQuery("sort", "sharpe"))
+	metric, ok := leaderboardSorts[sortBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be sharpe, hit_rate or net_pnl"})
+		return
+	}
+	minTrades, err := strconv.Atoi(c.DefaultQuery("min_trades", strconv.Itoa(leaderboardMinTrades)))
+	if err != nil || minTrades < 1 || minTrades > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_trades must be between 1 and 1000"})
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	entries, err := h.leaderboardEntries(ctx, since)
+	if err != nil {
+		log.Printf("❌ Failed to compute strategy leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute leaderboard"})
+		return
+	}
+
+	for _, e := range entries {
+		e.LowSample = e.Closed < minTrades
+		if e.Closed > 0 {
+			rate := round4(float64(e.Hits) / float64(e.Closed) * 100)
+			avg := round4(e.NetPnLPct / float64(e.Closed))
+			e.HitRate, e.AvgPnLPct = &rate, &avg
+		}
+		e.NetPnLPct = round4(e.NetPnLPct)
+		e.TradingDays = len(e.dailyReturns)
+		if e.TradingDays >= leaderboardMinDays {
+			e.Sharpe = dailySharpe(e.dailyReturns)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].LowSample != entries[j].LowSample {
+			return !entries[i].LowSample
+		}
+		if mi, mj := metric(entries[i]), metric(entries[j]); mi != mj {
+			return mi > mj
+		}
+		return entries[i].Source < entries[j].Source
+	})
+	leaderboard := make([]LeaderboardEntry, 0, len(entries))
+	for i, e := range entries {
+		if !e.LowSample {
+			rank := i + 1
+			e.Rank = &rank
+		}
+		leaderboard = append(leaderboard, *e)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":        days,
+		"from":        since.Format(time.RFC3339),
+		"sort":        sortBy,
+		"min_trades":  minTrades,
+		"min_days":    leaderboardMinDays,
+		"leaderboard": leaderboard,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// dailySharpe annualises the mean over standard deviation of daily
+// returns, or nil when they don't vary
+func dailySharpe(returns []float64) *float64 {
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	if stdDev == 0 {
+		return nil
+	}
+	sharpe := round4(mean / stdDev * math.Sqrt(252))
+	return &sharpe
+}
+
+// leaderboardEntries tallies signals generated since since by source, with
+// each source's closed-signal P&L per IST trading day
+func (h *QuantAnalyticsHandler) leaderboardEntries(ctx context.Context, since time.Time) ([]*LeaderboardEntry, error) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT
+			`+signalSourceExpr+` AS source,
+			(generated_at AT TIME ZONE 'Asia/Kolkata')::date AS day,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE result IS NOT NULL),
+			COUNT(*) FILTER (WHERE result = 'HIT'),
+			COALESCE(SUM(actual_profit_pct) FILTER (WHERE result IS NOT NULL), 0)::float8
+		FROM intraday.signals
+		WHERE generated_at >= $1
+		GROUP BY source, day
+		ORDER BY source, day
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal sources: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*LeaderboardEntry{}
+	bySource := map[string]*LeaderboardEntry{}
+	for rows.Next() {
+		var source string
+		var day time.Time
+		var signals, closed, hits int
+		var pnl float64
+		if err := rows.Scan(&source, &day, &signals, &closed, &hits, &pnl); err != nil {
+			return nil, fmt.Errorf("failed to scan signal source day: %w", err)
+		}
+		e := bySource[source]
+		if e == nil {
+			kind, _, _ := strings.Cut(source, ":")
+			e = &LeaderboardEntry{Source: source, Kind: kind}
+			bySource[source] = e
+			entries = append(entries, e)
+		}
+		e.Signals += signals
+		e.Closed += closed
+		e.Hits += hits
+		e.NetPnLPct += pnl
+		if closed > 0 {
+			e.dailyReturns = append(e.dailyReturns, pnl)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Engle-Granger critical values for the residual ADF statistic with two
+// series, a constant and no trend (MacKinnon 2010, asymptotic)
+var engleGrangerCritical = map[string]float64{
+	"1%":  -3.90,
+	"5%":  -3.34,
+	"10%": -3.04,
+}
+
+// PairPoint is one day of a pair's spread
+type PairPoint struct {
+	Date       string   `json:"date"`
+	Close1     float64  `json:"close1"`
+	Close2     float64  `json:"close2"`
+	Spread     float64  `json:"spread"`
+	ZScore     *float64 `json:"zscore"`
+	HedgeRatio *float64 `json:"hedge_ratio"`
+}
+
+// Cointegration is an Engle-Granger test on the pair's log prices
+type Cointegration struct {
+	ADFStat        float64            `json:"adf_stat"`
+	CriticalValues map[string]float64 `json:"critical_values"`
+	Significance   string             `json:"significance"`
+	Cointegrated   bool               `json:"cointegrated"`
+	HalfLifeDays   *float64           `json:"half_life_days"`
+}
+
+// GetPairAnalytics handles GET /api/quant/pairs.
+// Query: symbol1, symbol2, days (lookback, default 252), window (rolling
+// z-score and hedge ratio window, default 60). The spread is
+// log(symbol1) - intercept - hedge_ratio * log(symbol2).
+func (h *QuantAnalyticsHandler) GetPairAnalytics(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	symbol1 := strings.ToUpper(strings.TrimSpace(c.Query("symbol1")))
+	symbol2 := strings.ToUpper(strings.TrimSpace(c.Query("symbol2")))
+	if symbol1 == "" || symbol2 == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol1 and symbol2 are required"})
+		return
+	}
+	if symbol1 == symbol2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol1 and symbol2 must differ"})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "252"))
+	if err != nil || days < 30 || days > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 30 and 1000"})
+		return
+	}
+	window, err := strconv.Atoi(c.DefaultQuery("window", "60"))
+	if err != nil || window < 10 || window > days {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be between 10 and days"})
+		return
+	}
+
+	dates, closes1, closes2, err := h.pairCloses(ctx, symbol1, symbol2, days)
+	if err != nil {
+		log.Printf("❌ Failed to load closes for pair %s/%s: %v", symbol1, symbol2, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load daily bars"})
+		return
+	}
+	if len(dates) < window+1 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":        "Not enough overlapping daily bars for this window",
+			"observations": len(dates),
+			"window":       window,
+		})
+		return
+	}
+
+	y := make([]float64, len(dates))
+	x := make([]float64, len(dates))
+	for i := range dates {
+		y[i] = math.Log(closes1[i])
+		x[i] = math.Log(closes2[i])
+	}
+
+	intercept, hedgeRatio := olsFit(x, y)
+	spread := make([]float64, len(dates))
+	for i := range dates {
+		spread[i] = y[i] - intercept - hedgeRatio*x[i]
+	}
+	spreadMean, spreadStd := meanStd(spread)
+
+	series := make([]PairPoint, len(dates))
+	for i := range dates {
+		series[i] = PairPoint{Date: dates[i], Close1: closes1[i], Close2: closes2[i], Spread: round4(spread[i])}
+		if i+1 < window {
+			continue
+		}
+		lo := i + 1 - window
+		m, s := meanStd(spread[lo : i+1])
+		if s > 0 {
+			z := round4((spread[i] - m) / s)
+			series[i].ZScore = &z
+		}
+		_, beta := olsFit(x[lo:i+1], y[lo:i+1])
+		beta = round4(beta)
+		series[i].HedgeRatio = &beta
+	}
+
+	last := series[len(series)-1]
+	c.JSON(http.StatusOK, gin.H{
+		"symbol1":      symbol1,
+		"symbol2":      symbol2,
+		"observations": len(dates),
+		"window":       window,
+		"from":         dates[0],
+		"to":           dates[len(dates)-1],
+		"hedge_ratio":  round4(hedgeRatio),
+		"intercept":    round4(intercept),
+		"correlation":  round4(returnCorrelation(closes1, closes2)),
+		"spread": gin.H{
+			"current":             last.Spread,
+			"mean":                round4(spreadMean),
+			"std":                 round4(spreadStd),
+			"zscore":              last.ZScore,
+			"rolling_hedge_ratio": last.HedgeRatio,
+		},
+		"cointegration": engleGranger(spread),
+		"series":        series,
+		"timestamp":     time.Now().Format(time.RFC3339),
+	})
+}
+
+// pairCloses returns the last n daily closes on which both symbols traded,
+// oldest first
+func (h *QuantAnalyticsHandler) pairCloses(ctx context.Context, symbol1, symbol2 string, n int) ([]string, []float64, []float64, error) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT trade_date, close1, close2 FROM (
+			SELECT a.trade_date, a.close AS close1, b.close AS close2
+			FROM md.bhavcopy a
+			JOIN md.bhavcopy b ON b.trade_date = a.trade_date AND b.symbol = $2 AND b.series = 'EQ'
+			WHERE a.symbol = $1 AND a.series = 'EQ'
+				AND a.close > 0 AND b.close > 0
+			ORDER BY a.trade_date DESC
+			LIMIT $3
+		) p
+		ORDER BY trade_date
+	`, symbol1, symbol2, n)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query pair closes: %w", err)
+	}
+	defer rows.Close()
+
+	var dates []string
+	var closes1, closes2 []float64
+	for rows.Next() {
+		var date time.Time
+		var c1, c2 float64
+		if err := rows.Scan(&date, &c1, &c2); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan pair close: %w", err)
+		}
+		dates = append(dates, date.Format("2006-01-02"))
+		closes1 = append(closes1, c1)
+		closes2 = append(closes2, c2)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return dates, closes1, closes2, nil
+}
+
+// engleGranger runs a Dickey-Fuller regression, Δs = γ·s₋₁ + ε, on the
+// cointegrating residuals and compares γ's t-statistic with the
+// Engle-Granger critical values
+func engleGranger(spread []float64) Cointegration {
+	result := Cointegration{CriticalValues: engleGrangerCritical, Significance: "none"}
+	n := len(spread) - 1
+	if n < 3 {
+		return result
+	}
+
+	var sxx, sxy float64
+	for t := 1; t < len(spread); t++ {
+		lag, diff := spread[t-1], spread[t]-spread[t-1]
+		sxx += lag * lag
+		sxy += lag * diff
+	}
+	if sxx == 0 {
+		return result
+	}
+	gamma := sxy / sxx
+
+	var sse float64
+	for t := 1; t < len(spread); t++ {
+		e := spread[t] - spread[t-1] - gamma*spread[t-1]
+		sse += e * e
+	}
+	se := math.Sqrt(sse / float64(n-1) / sxx)
+	if se == 0 {
+		return result
+	}
+	result.ADFStat = round4(gamma / se)
+
+	for _, level := range []string{"1%", "5%", "10%"} {
+		if result.ADFStat < engleGrangerCritical[level] {
+			result.Significance = level
+			break
+		}
+	}
+	result.Cointegrated = result.ADFStat < engleGrangerCritical["5%"]
+
+	if gamma < 0 && gamma > -1 {
+		halfLife := round4(-math.Ln2 / math.Log1p(gamma))
+		result.HalfLifeDays = &halfLife
+	}
+	return result
+}
+
+// olsFit regresses y on x, returning the intercept and slope
+func olsFit(x, y []float64) (float64, float64) {
+	mx, _ := meanStd(x)
+	my, _ := meanStd(y)
+	var sxx, sxy float64
+	for i := range x {
+		sxx += (x[i] - mx) * (x[i] - mx)
+		sxy += (x[i] - mx) * (y[i] - my)
+	}
+	if sxx == 0 {
+		return my, 0
+	}
+	slope := sxy / sxx
+	return my - slope*mx, slope
+}
+
+// returnCorrelation is the Pearson correlation of two series' daily log returns
+func returnCorrelation(a, b []float64) float64 {
+	if len(a) < 3 {
+		return 0
+	}
+	ra := make([]float64, len(a)-1)
+	rb := make([]float64, len(b)-1)
+	for i := 1; i < len(a); i++ {
+		ra[i-1] = math.Log(a[i] / a[i-1])
+		rb[i-1] = math.Log(b[i] / b[i-1])
+	}
+	ma, sa := meanStd(ra)
+	mb, sb := meanStd(rb)
+	if sa == 0 || sb == 0 {
+		return 0
+	}
+	var cov float64
+	for i := range ra {
+		cov += (ra[i] - ma) * (rb[i] - mb)
+	}
+	return cov / float64(len(ra)-1) / (sa * sb)
+}
+
+// meanStd returns the mean and sample standard deviation of v
+func meanStd(v []float64) (float64, float64) {
+	if len(v) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	mean := sum / float64(len(v))
+	if len(v) < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, x := range v {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(v)-1))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
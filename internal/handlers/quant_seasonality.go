@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// seasonalityMinSample is the closed-signal count below which a bucket is
+// flagged as too small to trust
+const seasonalityMinSample = 20
+
+// SeasonalityBucket is signal performance for one entry hour, weekday or
+// weekday-hour cell. HitRate is hits over closed signals; AvgPnLPct is the
+// mean actual_profit_pct of closed signals.
+type SeasonalityBucket struct {
+	Hour      *int     `json:"hour,omitempty"`
+	Weekday   *string  `json:"weekday,omitempty"`
+	Signals   int      `json:"signals"`
+	Closed    int      `json:"closed"`
+	Hits      int      `json:"hits"`
+	HitRate   *float64 `json:"hit_rate"`
+	AvgPnLPct *float64 `json:"avg_pnl_pct"`
+	// HitRateEdge is HitRate minus the window's overall hit rate
+	HitRateEdge *float64 `json:"hit_rate_edge"`
+	LowSample   bool     `json:"low_sample"`
+}
+
+// seasonalityCell is the raw tally for one weekday and hour
+type seasonalityCell struct {
+	weekday time.Weekday
+	hour    int
+	signals int
+	closed  int
+	hits    int
+	pnlSum  float64
+}
+
+func (b *SeasonalityBucket) add(cell seasonalityCell, pnlSum *float64) {
+	b.Signals += cell.signals
+	b.Closed += cell.closed
+	b.Hits += cell.hits
+	*pnlSum += cell.pnlSum
+}
+
+func (b *SeasonalityBucket) finish(pnlSum float64, overall *float64) {
+	b.LowSample = b.Closed < seasonalityMinSample
+	if b.Closed == 0 {
+		return
+	}
+	rate := round4(float64(b.Hits) / float64(b.Closed) * 100)
+	avg := round4(pnlSum / float64(b.Closed))
+	b.HitRate = &rate
+	b.AvgPnLPct = &avg
+	if overall != nil {
+		edge := round4(rate - *overall)
+		b.HitRateEdge = &edge
+	}
+}
+
+// GetSeasonality handles GET /api/quant/seasonality.
+// Query: days (lookback, default 90, 7-730), symbol and signal_type
+// (optional filters). Signals are bucketed by their entry hour and weekday
+// in IST, each bucket reporting hit rate and average P&L against the
+// window's overall figures; buckets with fewer than 20 closed signals are
+// flagged low_sample.
+func (h *QuantAnalyticsHandler) GetSeasonality(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 7 || days > 730 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 7 and 730"})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	signalType := strings.ToUpper(strings.TrimSpace(c.Query("signal_type")))
+
+	since := time.Now().AddDate(0, 0, -days)
+	cells, err := h.seasonalityCells(ctx, since, symbol, signalType)
+	if err != nil {
+		log.Printf("❌ Failed to compute signal seasonality: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute seasonality"})
+		return
+	}
+
+	var overall SeasonalityBucket
+	var overallPnL float64
+	hours := map[int]*SeasonalityBucket{}
+	hourPnL := map[int]*float64{}
+	weekdays := map[time.Weekday]*SeasonalityBucket{}
+	weekdayPnL := map[time.Weekday]*float64{}
+	grid := make([]SeasonalityBucket, 0, len(cells))
+	for _, cell := range cells {
+		overall.add(cell, &overallPnL)
+
+		if hours[cell.hour] == nil {
+			hour := cell.hour
+			hours[cell.hour] = &SeasonalityBucket{Hour: &hour}
+			hourPnL[cell.hour] = new(float64)
+		}
+		hours[cell.hour].add(cell, hourPnL[cell.hour])
+
+		if weekdays[cell.weekday] == nil {
+			name := cell.weekday.String()
+			weekdays[cell.weekday] = &SeasonalityBucket{Weekday: &name}
+			weekdayPnL[cell.weekday] = new(float64)
+		}
+		weekdays[cell.weekday].add(cell, weekdayPnL[cell.weekday])
+	}
+	overall.finish(overallPnL, nil)
+
+	byHour := make([]SeasonalityBucket, 0, len(hours))
+	for hour, b := range hours {
+		b.finish(*hourPnL[hour], overall.HitRate)
+		byHour = append(byHour, *b)
+	}
+	sort.Slice(byHour, func(i, j int) bool { return *byHour[i].Hour < *byHour[j].Hour })
+
+	// Weekdays run Monday first
+	order := func(d time.Weekday) int { return (int(d) + 6) % 7 }
+	weekdayKeys := make([]time.Weekday, 0, len(weekdays))
+	for d := range weekdays {
+		weekdayKeys = append(weekdayKeys, d)
+	}
+	sort.Slice(weekdayKeys, func(i, j int) bool { return order(weekdayKeys[i]) < order(weekdayKeys[j]) })
+	byWeekday := make([]SeasonalityBucket, 0, len(weekdays))
+	for _, d := range weekdayKeys {
+		b := weekdays[d]
+		b.finish(*weekdayPnL[d], overall.HitRate)
+		byWeekday = append(byWeekday, *b)
+	}
+
+	for _, cell := range cells {
+		hour := cell.hour
+		name := cell.weekday.String()
+		b := SeasonalityBucket{Hour: &hour, Weekday: &name}
+		var pnl float64
+		b.add(cell, &pnl)
+		b.finish(pnl, overall.HitRate)
+		grid = append(grid, b)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":        days,
+		"from":        since.Format(time.RFC3339),
+		"symbol":      symbol,
+		"signal_type": signalType,
+		"timezone":    "Asia/Kolkata",
+		"overall":     overall,
+		"by_hour":     byHour,
+		"by_weekday":  byWeekday,
+		"grid":        grid,
+		"min_sample":  seasonalityMinSample,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// seasonalityCells tallies signals generated since since by IST weekday
+// and entry hour, ordered Monday first and then by hour
+func (h *QuantAnalyticsHandler) seasonalityCells(ctx context.Context, since time.Time, symbol, signalType string) ([]seasonalityCell, error) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT
+			EXTRACT(ISODOW FROM generated_at AT TIME ZONE 'Asia/Kolkata')::int AS dow,
+			EXTRACT(HOUR FROM generated_at AT TIME ZONE 'Asia/Kolkata')::int AS hour,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE result IS NOT NULL),
+			COUNT(*) FILTER (WHERE result = 'HIT'),
+			COALESCE(SUM(actual_profit_pct) FILTER (WHERE result IS NOT NULL), 0)::float8
+		FROM intraday.signals
+		WHERE generated_at >= $1
+			AND ($2 = '' OR symbol = $2)
+			AND ($3 = '' OR UPPER(signal_type) = $3)
+		GROUP BY dow, hour
+		ORDER BY dow, hour
+	`, since, symbol, signalType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal seasonality: %w", err)
+	}
+	defer rows.Close()
+
+	cells := []seasonalityCell{}
+	for rows.Next() {
+		var cell seasonalityCell
+		var isoDow int
+		if err := rows.Scan(&isoDow, &cell.hour, &cell.signals, &cell.closed, &cell.hits, &cell.pnlSum); err != nil {
+			return nil, fmt.Errorf("failed to scan seasonality cell: %w", err)
+		}
+		cell.weekday = time.Weekday(isoDow % 7)
+		cells = append(cells, cell)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return cells, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// RebalancePortfolio handles POST /api/portfolios/:id/rebalance
+func (h *Handler) RebalancePortfolio(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	portfolio := h.loadPortfolio(ctx, c)
+	if portfolio == nil {
+		return
+	}
+
+	var req database.RebalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	req.Mode = strings.ToLower(req.Mode)
+	if req.Mode == "" {
+		req.Mode = database.RebalanceBySymbol
+	}
+	if req.Mode != database.RebalanceBySymbol && req.Mode != database.RebalanceBySector {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be symbol or sector"})
+		return
+	}
+	if len(req.Targets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "targets are required"})
+		return
+	}
+
+	sum := 0.0
+	for key, w := range req.Targets {
+		if w < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("target weight for %s must not be negative", key)})
+			return
+		}
+		sum += w
+	}
+	if sum > 100.0001 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("target weights sum to %.2f%%, must not exceed 100%%", sum)})
+		return
+	}
+	if req.AdditionalCash < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "additional_cash must not be negative"})
+		return
+	}
+
+	plan, err := h.db.GetRebalancePlan(ctx, portfolio.ID, req)
+	if err != nil {
+		log.Printf("❌ Failed to compute rebalance for portfolio %d: %v", portfolio.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute rebalance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
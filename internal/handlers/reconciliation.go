@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// ReconciliationHandler serves the nightly realtime-vs-bhavcopy reports
+type ReconciliationHandler struct {
+	db database.PriceReconciliationRepository
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(db database.PriceReconciliationRepository) *ReconciliationHandler {
+	return &ReconciliationHandler{db: db}
+}
+
+// GetReconciliation handles GET /api/reports/reconciliation/:date.
+// :date is YYYY-MM-DD; query kind (mismatch, stale_realtime or
+// missing_bhavcopy) limits the items listed. Matching symbols are only
+// counted.
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	kind := c.Query("kind")
+	switch kind {
+	case "", database.ReconMismatch, database.ReconStaleRealtime, database.ReconMissingBhavcopy:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be mismatch, stale_realtime or missing_bhavcopy"})
+		return
+	}
+
+	recon, err := h.db.GetPriceReconciliation(ctx, date, kind)
+	if err != nil {
+		log.Printf("❌ Failed to get price reconciliation for %s: %v", c.Param("date"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reconciliation"})
+		return
+	}
+	if recon == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No reconciliation for " + c.Param("date")})
+		return
+	}
+	c.JSON(http.StatusOK, recon)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/regime"
+)
+
+// RegimeHandler serves the intraday market regime
+type RegimeHandler struct {
+	tracker *regime.Tracker
+}
+
+// NewRegimeHandler creates a new regime handler
+func NewRegimeHandler(tracker *regime.Tracker) *RegimeHandler {
+	return &RegimeHandler{tracker: tracker}
+}
+
+// GetMarketRegime handles GET /api/market/regime. The response includes the
+// rules in evaluation order so clients can show why a regime was chosen.
+func (h *RegimeHandler) GetMarketRegime(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	reading, err := h.tracker.Current(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to classify market regime: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to classify market regime"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"regime": reading,
+		"rules":  regime.Rules,
+	})
+}
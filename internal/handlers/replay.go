@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/replay"
+)
+
+// ReplayHandler starts and stops replays of recorded market days
+type ReplayHandler struct {
+	player *replay.Player
+}
+
+// NewReplayHandler creates a new replay handler
+func NewReplayHandler(player *replay.Player) *ReplayHandler {
+	return &ReplayHandler{player: player}
+}
+
+// GetReplay handles GET /api/system/replay
+func (h *ReplayHandler) GetReplay(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"replay": h.player.Status()})
+}
+
+// StartReplay handles POST /api/system/replay.
+// Body: date (YYYY-MM-DD, required), speed (replay seconds per second,
+// default 60), from and to (IST HH:MM, default 09:15 and 15:30) and
+// symbols (default all recorded).
+func (h *ReplayHandler) StartReplay(c *gin.Context) {
+	if !h.player.Enabled() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Replay mode is disabled (set REPLAY_ENABLED=true)"})
+		return
+	}
+
+	var cfg replay.Config
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := cfg.Validate(h.player.Location()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	if err := h.player.Start(ctx, cfg); err != nil {
+		if errors.Is(err, replay.ErrRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A replay is already running"})
+			return
+		}
+		log.Printf("❌ Failed to start replay of %s: %v", cfg.Date, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the recorded day"})
+		return
+	}
+
+	log.Printf("✅ Replay started by %s: %s at %.0fx", requestActor(c), cfg.Date, cfg.Speed)
+	c.JSON(http.StatusAccepted, gin.H{"replay": h.player.Status()})
+}
+
+// StopReplay handles DELETE /api/system/replay
+func (h *ReplayHandler) StopReplay(c *gin.Context) {
+	if !h.player.Stop() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No replay is running"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"replay": h.player.Status()})
+}
+
+// GetReplaySnapshot handles GET /api/system/replay/snapshot: prices,
+// signals and news of the current or last replay as of its clock
+func (h *ReplayHandler) GetReplaySnapshot(c *gin.Context) {
+	snap := h.player.Snapshot()
+	if snap == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nothing has been replayed"})
+		return
+	}
+	c.JSON(http.StatusOK, snap)
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+)
+
+// maxReportSubscriptions caps the subscriptions a user can hold
+const maxReportSubscriptions = 20
+
+// telegramChatPattern matches numeric chat IDs and public @channel names
+var telegramChatPattern = regexp.MustCompile(`^(-?[0-9]{1,20}|@[A-Za-z][A-Za-z0-9_]{4,31})$`)
+
+// ReportsHandler manages scheduled report subscriptions
+type ReportsHandler struct {
+	db        database.ReportSubscriptionRepository
+	scheduler *reports.Scheduler
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler(db database.ReportSubscriptionRepository, scheduler *reports.Scheduler) *ReportsHandler {
+	return &ReportsHandler{db: db, scheduler: scheduler}
+}
+
+// reportSubscriptionBody is the create and update request body
+type reportSubscriptionBody struct {
+	ReportType  string `json:"report_type" binding:"required"`
+	Channel     string `json:"channel" binding:"required"`
+	Destination string `json:"destination" binding:"required"`
+	SendTime    string `json:"send_time" binding:"required"`
+	Weekday     *int   `json:"weekday"`
+	Active      *bool  `json:"active"`
+}
+
+// parse validates the body into a subscription and its first run, writing
+// the error response itself and returning false when it's invalid
+func (h *ReportsHandler) parse(c *gin.Context, b reportSubscriptionBody) (database.ReportSubscription, time.Time, bool) {
+	s := database.ReportSubscription{
+		UserID:      requestUserID(c),
+		ReportType:  strings.ToLower(strings.TrimSpace(b.ReportType)),
+		Channel:     strings.ToLower(strings.TrimSpace(b.Channel)),
+		Destination: strings.TrimSpace(b.Destination),
+		SendTime:    strings.TrimSpace(b.SendTime),
+		Active:      b.Active == nil || *b.Active,
+	}
+
+	if !reports.ValidType(s.ReportType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown report_type", "report_types": reports.Types})
+		return s, time.Time{}, false
+	}
+	switch s.Channel {
+	case reports.ChannelEmail:
+		addr, err := mail.ParseAddress(s.Destination)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "destination must be an email address"})
+			return s, time.Time{}, false
+		}
+		s.Destination = addr.Address
+	case reports.ChannelTelegram:
+		if !telegramChatPattern.MatchString(s.Destination) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "destination must be a Telegram chat ID or @channel"})
+			return s, time.Time{}, false
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be email or telegram"})
+		return s, time.Time{}, false
+	}
+	if !h.scheduler.Configured(s.Channel) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    s.Channel + " delivery is not configured on this server",
+			"channels": h.scheduler.Channels(),
+		})
+		return s, time.Time{}, false
+	}
+
+	if reports.Weekly(s.ReportType) {
+		if b.Weekday == nil || *b.Weekday < 0 || *b.Weekday > 6 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weekday (0 = Sunday to 6 = Saturday) is required for " + s.ReportType})
+			return s, time.Time{}, false
+		}
+		s.Weekday = b.Weekday
+	}
+	nextRun, err := reports.NextRun(s.ReportType, s.SendTime, s.Weekday, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return s, time.Time{}, false
+	}
+	return s, nextRun, true
+}
+
+// ListReportSubscriptions handles GET /api/reports/subscriptions
+func (h *ReportsHandler) ListReportSubscriptions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	subs, err := h.db.ListReportSubscriptions(ctx, requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list report subscriptions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subs,
+		"count":         len(subs),
+		"report_types":  reports.Types,
+		"channels":      h.scheduler.Channels(),
+	})
+}
+
+// CreateReportSubscription handles POST /api/reports/subscriptions.
+// Body: report_type, channel (email or telegram), destination, send_time
+// (HH:MM IST), weekday (weekly reports only) and active (default true).
+func (h *ReportsHandler) CreateReportSubscription(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body reportSubscriptionBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "report_type, channel, destination and send_time are required"})
+		return
+	}
+	s, nextRun, ok := h.parse(c, body)
+	if !ok {
+		return
+	}
+
+	existing, err := h.db.ListReportSubscriptions(ctx, s.UserID)
+	if err != nil {
+		log.Printf("❌ Failed to list report subscriptions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report subscription"})
+		return
+	}
+	if len(existing) >= maxReportSubscriptions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At most " + strconv.Itoa(maxReportSubscriptions) + " report subscriptions"})
+		return
+	}
+
+	sub, err := h.db.CreateReportSubscription(ctx, s, nextRun)
+	if err != nil {
+		log.Printf("❌ Failed to create report subscription: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create report subscription"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetReportSubscription handles GET /api/reports/subscriptions/:id
+func (h *ReportsHandler) GetReportSubscription(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	sub, err := h.db.GetReportSubscription(ctx, requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get report subscription %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report subscription"})
+		return
+	}
+	if sub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// UpdateReportSubscription handles PUT /api/reports/subscriptions/:id. The
+// body replaces the subscription and reschedules its next run.
+func (h *ReportsHandler) UpdateReportSubscription(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	var body reportSubscriptionBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "report_type, channel, destination and send_time are required"})
+		return
+	}
+	s, nextRun, ok := h.parse(c, body)
+	if !ok {
+		return
+	}
+	s.ID = id
+
+	sub, err := h.db.UpdateReportSubscription(ctx, s, nextRun)
+	if err != nil {
+		log.Printf("❌ Failed to update report subscription %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update report subscription"})
+		return
+	}
+	if sub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteReportSubscription handles DELETE /api/reports/subscriptions/:id
+func (h *ReportsHandler) DeleteReportSubscription(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	deleted, err := h.db.DeleteReportSubscription(ctx, requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete report subscription %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete report subscription"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report subscription deleted", "id": id})
+}
+
+// SendReportNow handles POST /api/reports/subscriptions/:id/send, delivering
+// the report immediately without changing its schedule
+func (h *ReportsHandler) SendReportNow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return
+	}
+
+	sub, err := h.db.GetReportSubscription(ctx, requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get report subscription %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report subscription"})
+		return
+	}
+	if sub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report subscription not found"})
+		return
+	}
+
+	if err := h.scheduler.SendNow(ctx, *sub, time.Now()); err != nil {
+		log.Printf("⚠️  Report subscription %d send failed: %v", id, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to deliver report", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report sent", "id": id, "channel": sub.Channel})
+}
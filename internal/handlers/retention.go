@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/retention"
+)
+
+// RetentionHandler exposes data retention policies and runs
+type RetentionHandler struct {
+	db      database.RetentionRepository
+	manager *retention.Manager
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(db database.RetentionRepository, manager *retention.Manager) *RetentionHandler {
+	return &RetentionHandler{db: db, manager: manager}
+}
+
+// GetRetention handles GET /api/system/retention
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	lastRun, err := h.db.GetLastRetentionRun(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to get last retention run: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get retention status"})
+		return
+	}
+
+	cfg := h.manager.Config()
+	c.JSON(http.StatusOK, gin.H{
+		"policies": cfg.Policies,
+		"enforce":  cfg.Enforce,
+		"schedule": "daily",
+		"running":  h.manager.Running(),
+		"last_run": lastRun,
+	})
+}
+
+// RunRetention handles POST /api/system/retention/run?dry_run=true. Dry runs
+// (the default) return the report directly; real runs continue in the
+// background and are reported through GET /api/system/retention.
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	if c.DefaultQuery("dry_run", "true") != "false" {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+		defer cancel()
+
+		results, err := h.manager.Apply(ctx, true)
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"dry_run": true, "results": results})
+		return
+	}
+
+	if h.manager.Running() {
+		c.JSON(http.StatusConflict, gin.H{"error": "A retention pass is already running"})
+		return
+	}
+	go func() {
+		if _, err := h.manager.Apply(context.Background(), false); err != nil {
+			log.Printf("❌ Retention: %v", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"dry_run": false, "message": "Retention pass started"})
+}
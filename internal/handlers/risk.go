@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/risk"
+)
+
+// RiskHandler serves intraday risk snapshot history
+type RiskHandler struct {
+	db      database.RiskSnapshotRepository
+	monitor *risk.Monitor
+}
+
+// NewRiskHandler creates a new risk snapshot handler
+func NewRiskHandler(db database.RiskSnapshotRepository, monitor *risk.Monitor) *RiskHandler {
+	return &RiskHandler{db: db, monitor: monitor}
+}
+
+// GetRiskSnapshots handles GET /api/quant/risk/snapshots.
+// Query: date (YYYY-MM-DD, default today in IST). Returns the day's
+// snapshots oldest first, the latest one and the limits in force.
+func (h *RiskHandler) GetRiskSnapshots(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	loc := database.SessionOpen(time.Now()).Location()
+	today := time.Now().In(loc).Format("2006-01-02")
+	day, err := time.ParseInLocation("2006-01-02", c.DefaultQuery("date", today), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+
+	snapshots, err := h.db.ListRiskSnapshots(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("❌ Failed to list risk snapshots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve risk snapshots"})
+		return
+	}
+
+	var latest *database.RiskSnapshot
+	if len(snapshots) > 0 {
+		latest = &snapshots[len(snapshots)-1]
+	}
+	cfg := h.monitor.Config()
+	c.JSON(http.StatusOK, gin.H{
+		"date":      day.Format("2006-01-02"),
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+		"latest":    latest,
+		"config": gin.H{
+			"interval_minutes": int(cfg.Interval / time.Minute),
+			"daily_loss_limit": cfg.DailyLossLimit,
+			"signal_notional":  cfg.SignalNotional,
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/nlquery"
+	"github.com/trading-chitti/core-api-go/internal/scans"
+)
+
+// ScansHandler serves ready-made intraday scans
+type ScansHandler struct {
+	scans *scans.Service
+}
+
+// NewScansHandler creates a new scans handler
+func NewScansHandler(service *scans.Service) *ScansHandler {
+	return &ScansHandler{scans: service}
+}
+
+// interpreted adds what ?q= and ?when= were understood as to a scan
+// response, when either was given
+func interpreted(resp gin.H, q *nlquery.Query, supported ...string) gin.H {
+	if q != nil {
+		resp["interpreted_as"] = interpretedAs(q, supported...)
+	}
+	return resp
+}
+
+// scanDay parses ?date=YYYY-MM-DD, falling back on a single day named by
+// ?when= or ?q=, then today
+func scanDay(c *gin.Context, q *nlquery.Query) (time.Time, bool) {
+	v := c.Query("date")
+	if v == "" && q != nil && q.When != nil {
+		if !q.When.SingleDay() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scans cover one day; \"" + q.When.Phrase + "\" spans several"})
+			return time.Time{}, false
+		}
+		return q.When.From, true
+	}
+	if v == "" {
+		return time.Now(), true
+	}
+	day, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// GetOpeningRangeBreakouts handles GET /api/scans/orb.
+// Query: date, range (5, 15, 30 or 60 minutes), direction (up, down),
+// include=all to list symbols still inside their range, limit. q and when
+// may set the date and direction in words.
+func (h *ScansHandler) GetOpeningRangeBreakouts(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	q, ok := parseHumanFilters(c)
+	if !ok || scanMismatch(c, q, nlquery.ScanORB) {
+		return
+	}
+	day, ok := scanDay(c, q)
+	if !ok {
+		return
+	}
+	rangeMinutes, err := strconv.Atoi(c.DefaultQuery("range", strconv.Itoa(scans.DefaultRangeMinutes)))
+	if err != nil || (rangeMinutes != 5 && rangeMinutes != 15 && rangeMinutes != 30 && rangeMinutes != 60) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "range must be 5, 15, 30 or 60"})
+		return
+	}
+	direction := strings.ToUpper(c.Query("direction"))
+	if direction == "" && q != nil {
+		direction = strings.ToUpper(q.Direction)
+	}
+	includeAll := c.Query("include") == "all"
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	rangeEnd := database.SessionOpen(day).Add(time.Duration(rangeMinutes) * time.Minute)
+	if time.Now().Before(rangeEnd) {
+		c.JSON(http.StatusOK, interpreted(gin.H{
+			"status":        "pending",
+			"range_minutes": rangeMinutes,
+			"range_ends_at": rangeEnd.Format(time.RFC3339),
+			"results":       []database.OpeningRange{},
+			"count":         0,
+		}, q, "direction", "when", "scan"))
+		return
+	}
+
+	result, err := h.scans.OpeningRanges(ctx, day, rangeMinutes)
+	if err != nil {
+		log.Printf("❌ Failed to compute opening range scan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute opening range scan"})
+		return
+	}
+
+	list := make([]database.OpeningRange, 0, len(result.Items))
+	for _, r := range result.Items {
+		if r.Breakout == nil {
+			if includeAll && direction == "" {
+				list = append(list, r)
+			}
+			continue
+		}
+		if direction != "" && *r.Breakout != direction {
+			continue
+		}
+		list = append(list, r)
+	}
+	// Strongest follow-through first; symbols inside their range sort last
+	sort.SliceStable(list, func(i, j int) bool {
+		return extension(list[i]) > extension(list[j])
+	})
+	if limit < len(list) {
+		list = list[:limit]
+	}
+
+	c.JSON(http.StatusOK, interpreted(gin.H{
+		"status":        "ready",
+		"date":          database.SessionOpen(day).Format("2006-01-02"),
+		"range_minutes": rangeMinutes,
+		"results":       list,
+		"count":         len(list),
+		"scanned":       len(result.Items),
+		"computed_at":   result.ComputedAt.Format(time.RFC3339),
+	}, q, "direction", "when", "scan"))
+}
+
+func extension(r database.OpeningRange) float64 {
+	if r.ExtensionPct == nil {
+		return math.Inf(-1)
+	}
+	return *r.ExtensionPct
+}
+
+// GetGaps handles GET /api/scans/gaps.
+// Query: date, min_gap (percent, default 1), direction (up, down),
+// unfilled=true to drop gaps already filled, limit. q and when may set the
+// date, direction and minimum gap in words ("gapped up 3% yesterday").
+func (h *ScansHandler) GetGaps(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	q, ok := parseHumanFilters(c)
+	if !ok || scanMismatch(c, q, nlquery.ScanGaps) {
+		return
+	}
+	day, ok := scanDay(c, q)
+	if !ok {
+		return
+	}
+	minGapParam := c.DefaultQuery("min_gap", "1")
+	if _, set := c.GetQuery("min_gap"); !set && q != nil && q.MinPct != nil {
+		minGapParam = strconv.FormatFloat(*q.MinPct, 'f', -1, 64)
+	}
+	minGap, err := strconv.ParseFloat(minGapParam, 64)
+	if err != nil || minGap < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_gap must be a non-negative number"})
+		return
+	}
+	direction := strings.ToLower(c.Query("direction"))
+	if direction == "" && q != nil {
+		direction = q.Direction
+	}
+	unfilled := c.Query("unfilled") == "true"
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	if time.Now().Before(database.SessionOpen(day)) {
+		c.JSON(http.StatusOK, interpreted(gin.H{
+			"status":  "pending",
+			"results": []database.Gap{},
+			"count":   0,
+		}, q, "direction", "min_pct", "when", "scan"))
+		return
+	}
+
+	result, err := h.scans.Gaps(ctx, day)
+	if err != nil {
+		log.Printf("❌ Failed to compute gap scan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute gap scan"})
+		return
+	}
+
+	list := make([]database.Gap, 0, len(result.Items))
+	for _, g := range result.Items {
+		if math.Abs(g.GapPct) < minGap {
+			continue
+		}
+		if (direction == "up" && g.GapPct <= 0) || (direction == "down" && g.GapPct >= 0) {
+			continue
+		}
+		if unfilled && g.Filled {
+			continue
+		}
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool { return math.Abs(list[i].GapPct) > math.Abs(list[j].GapPct) })
+	if limit < len(list) {
+		list = list[:limit]
+	}
+
+	c.JSON(http.StatusOK, interpreted(gin.H{
+		"status":      "ready",
+		"date":        database.SessionOpen(day).Format("2006-01-02"),
+		"min_gap_pct": minGap,
+		"results":     list,
+		"count":       len(list),
+		"scanned":     len(result.Items),
+		"computed_at": result.ComputedAt.Format(time.RFC3339),
+	}, q, "direction", "min_pct", "when", "scan"))
+}
+
+// scanPage parses ?sector= (repeatable or comma-separated), limit and
+// offset. Without sector, the sectors named in q apply.
+func scanPage(c *gin.Context, q *nlquery.Query) database.ScanPage {
+	var sectors []string
+	for _, v := range c.QueryArray("sector") {
+		sectors = append(sectors, strings.Split(v, ",")...)
+	}
+	if len(sectors) == 0 && q != nil {
+		sectors = q.Sectors
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+	return database.ScanPage{Sectors: sectors, Limit: limit, Offset: offset}
+}
+
+// GetUnusualVolume handles GET /api/scans/unusual-volume.
+// Query: min_ratio (default 2), sector, limit, offset. q may name sectors.
+func (h *ScansHandler) GetUnusualVolume(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	q, ok := parseHumanFilters(c)
+	if !ok || scanMismatch(c, q, nlquery.ScanUnusualVolume) {
+		return
+	}
+	minRatio, err := strconv.ParseFloat(c.DefaultQuery("min_ratio", "2"), 64)
+	if err != nil || minRatio <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_ratio must be a positive number"})
+		return
+	}
+	page := scanPage(c, q)
+
+	now := time.Now()
+	result, err := h.scans.UnusualVolume(ctx, now, minRatio, page)
+	if err != nil {
+		log.Printf("❌ Failed to compute unusual volume scan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute unusual volume scan"})
+		return
+	}
+
+	// Volume so far is cumulative, so during the session the ratio
+	// understates the day; report how much of the session has elapsed
+	open := database.SessionOpen(now)
+	elapsed := math.Min(1, math.Max(0, now.Sub(open).Minutes()/database.SessionMinutes))
+
+	c.JSON(http.StatusOK, interpreted(gin.H{
+		"results":         result.Items,
+		"count":           len(result.Items),
+		"total":           result.Total,
+		"limit":           page.Limit,
+		"offset":          page.Offset,
+		"min_ratio":       minRatio,
+		"session_elapsed": math.Round(elapsed*1000) / 1000,
+		"computed_at":     result.ComputedAt.Format(time.RFC3339),
+	}, q, "sectors", "scan"))
+}
+
+// GetCircuits handles GET /api/scans/circuits.
+// Query: side (upper, lower), sector, limit, offset. q may name sectors and
+// a direction (up for upper circuits, down for lower).
+func (h *ScansHandler) GetCircuits(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	q, ok := parseHumanFilters(c)
+	if !ok || scanMismatch(c, q, nlquery.ScanCircuits) {
+		return
+	}
+	side := strings.ToUpper(c.Query("side"))
+	if side == "" && q != nil {
+		switch q.Direction {
+		case "up":
+			side = "UPPER"
+		case "down":
+			side = "LOWER"
+		}
+	}
+	if side != "" && side != "UPPER" && side != "LOWER" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "side must be upper or lower"})
+		return
+	}
+	page := scanPage(c, q)
+
+	result, err := h.scans.Circuits(ctx, side, page)
+	if err != nil {
+		log.Printf("❌ Failed to compute circuit scan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute circuit scan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, interpreted(gin.H{
+		"results":     result.Items,
+		"count":       len(result.Items),
+		"total":       result.Total,
+		"limit":       page.Limit,
+		"offset":      page.Offset,
+		"computed_at": result.ComputedAt.Format(time.RFC3339),
+	}, q, "sectors", "direction", "scan"))
+}
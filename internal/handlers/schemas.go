@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// DecodeStatsProvider reports event decode counters
+type DecodeStatsProvider interface {
+	DecodeStats() []schemas.SubjectStats
+}
+
+// SchemasHandler publishes the event schema registry
+type SchemasHandler struct {
+	stats DecodeStatsProvider
+}
+
+// NewSchemasHandler creates a new schemas handler. stats may be nil when
+// NATS is not connected.
+func NewSchemasHandler(stats DecodeStatsProvider) *SchemasHandler {
+	return &SchemasHandler{stats: stats}
+}
+
+// ListSchemas handles GET /api/events/schemas
+func (h *SchemasHandler) ListSchemas(c *gin.Context) {
+	defs := schemas.Definitions()
+	out := make([]gin.H, 0, len(defs))
+	for _, d := range defs {
+		out = append(out, gin.H{
+			"name":        d.Name,
+			"version":     d.Version,
+			"subjects":    d.Subjects,
+			"description": d.Description,
+			"schema":      d.JSONSchema(),
+		})
+	}
+
+	decoding := []schemas.SubjectStats{}
+	if h.stats != nil {
+		decoding = h.stats.DecodeStats()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"version_field": schemas.VersionField,
+		"schemas":       out,
+		"decoding":      decoding,
+	})
+}
+
+// GetSchema handles GET /api/events/schemas/:name and returns the bare JSON
+// Schema document so other services can validate against it directly
+func (h *SchemasHandler) GetSchema(c *gin.Context) {
+	d, ok := schemas.Lookup(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schema not found"})
+		return
+	}
+	c.Header("Content-Type", "application/schema+json")
+	c.JSON(http.StatusOK, d.JSONSchema())
+}
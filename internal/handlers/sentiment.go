@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/sentiment"
+)
+
+// SentimentHandler reports on the sentiment scoring pipeline
+type SentimentHandler struct {
+	pipeline *sentiment.Pipeline
+}
+
+// NewSentimentHandler creates a new sentiment handler
+func NewSentimentHandler(pipeline *sentiment.Pipeline) *SentimentHandler {
+	return &SentimentHandler{pipeline: pipeline}
+}
+
+// GetSentimentStats handles GET /api/monitoring/sentiment, returning the
+// backend in use, queue depth and scoring throughput
+func (h *SentimentHandler) GetSentimentStats(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.pipeline.Stats(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to get sentiment stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sentiment stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/environment"
+	"github.com/trading-chitti/core-api-go/internal/serviceauth"
+)
+
+// serviceKey holds the calling service's name once its token is verified
+const serviceKey = "service"
+
+// requestActor names who made the request for logs: "service:<name>" for
+// authenticated services, otherwise the user
+func requestActor(c *gin.Context) string {
+	if service := c.GetString(serviceKey); service != "" {
+		return "service:" + service
+	}
+	return requestUserID(c)
+}
+
+// authenticateService verifies the request's service token and permission,
+// writing the error response itself and returning false when either is
+// missing. Accepted calls are logged with the service's identity.
+func authenticateService(c *gin.Context, auth *serviceauth.Authenticator, permission string) bool {
+	service, ok := auth.Verify(c.GetHeader(serviceauth.Header), time.Now())
+	if !ok {
+		log.Printf("⚠️  %s %s refused: invalid service token from %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+		authFailed(c, authguard.KindServiceTokenInvalid, "", "")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired service token"})
+		return false
+	}
+	if !auth.Allowed(service, permission) {
+		log.Printf("⚠️  %s %s refused for service %s: %s not granted", c.Request.Method, c.Request.URL.Path, service, permission)
+		authFailed(c, authguard.KindServiceDenied, service, permission+" not granted")
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Service " + service + " lacks the " + permission + " permission"})
+		return false
+	}
+	c.Set(serviceKey, service)
+	log.Printf("🔐 %s %s by service %s", c.Request.Method, c.Request.URL.Path, service)
+	return true
+}
+
+// ServiceOnly guards endpoints only other services call, such as price
+// ingest: they need a service token granted permission
+func ServiceOnly(auth *serviceauth.Authenticator, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.Enabled() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Service authentication is not configured"})
+			return
+		}
+		if authenticateService(c, auth, permission) {
+			c.Next()
+		}
+	}
+}
+
+// ProdAdminOrService guards endpoints used by both operators and services,
+// such as running jobs: a request carrying a service token is checked for
+// permission, anything else falls back to ProdAdminOnly
+func ProdAdminOrService(env environment.Environment, auth *serviceauth.Authenticator, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowProdAdminOrService(c, env, auth, permission) {
+			c.Next()
+		}
+	}
+}
+
+// allowProdAdminOrService is the ProdAdminOrService check for handlers that
+// only guard some requests; it writes the error itself and returns false
+// when refused
+func allowProdAdminOrService(c *gin.Context, env environment.Environment, auth *serviceauth.Authenticator, permission string) bool {
+	if c.GetHeader(serviceauth.Header) == "" {
+		return allowProdAdmin(c, env)
+	}
+	return authenticateService(c, auth, permission)
+}
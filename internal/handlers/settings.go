@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/settings"
+)
+
+// SettingsHandler reads and writes typed runtime settings
+type SettingsHandler struct {
+	registry *settings.Registry
+	watcher  *settings.Watcher
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(registry *settings.Registry, watcher *settings.Watcher) *SettingsHandler {
+	return &SettingsHandler{registry: registry, watcher: watcher}
+}
+
+// GetRuntimeConfig handles GET /api/system/config, reporting the
+// generation of live settings in effect and when they were last checked
+func (h *SettingsHandler) GetRuntimeConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.watcher.Status())
+}
+
+// ListSettings handles GET /api/config/settings, returning every setting
+// with its schema and current value
+func (h *SettingsHandler) ListSettings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	list, err := h.registry.List(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to list settings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": list, "count": len(list)})
+}
+
+// GetSetting handles GET /api/config/settings/:key
+func (h *SettingsHandler) GetSetting(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	key := c.Param("key")
+	setting, err := h.registry.Get(ctx, key)
+	if errors.Is(err, settings.ErrUnknown) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown setting"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to get setting %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve setting"})
+		return
+	}
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdateSetting handles PUT /api/config/settings/:key.
+// Body: value, typed per the setting's schema.
+func (h *SettingsHandler) UpdateSetting(c *gin.Context) {
+	var body struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || len(body.Value) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body must be {\"value\": ...}"})
+		return
+	}
+	h.set(c, c.Param("key"), body.Value, func(s *settings.Setting) interface{} { return s })
+}
+
+// set writes a setting and responds with render's view of it
+func (h *SettingsHandler) set(c *gin.Context, key string, value json.RawMessage, render func(*settings.Setting) interface{}) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	setting, err := h.registry.Set(ctx, key, value, requestActor(c))
+	switch {
+	case errors.Is(err, settings.ErrUnknown):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown setting"})
+		return
+	case errors.Is(err, settings.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("❌ Failed to update setting %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update setting"})
+		return
+	}
+
+	log.Printf("✅ Setting %s updated by %s", key, requestActor(c))
+	c.JSON(http.StatusOK, render(setting))
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+	"github.com/trading-chitti/core-api-go/internal/share"
+)
+
+const (
+	// defaultShareTTLHours is how long a link lasts unless asked otherwise
+	defaultShareTTLHours = 72
+	// maxShareTTLHours caps a link's lifetime at 30 days
+	maxShareTTLHours = 30 * 24
+	// sharedDashboardSignals is how many active signals a dashboard
+	// snapshot keeps
+	sharedDashboardSignals = 20
+)
+
+// ShareHandler creates public read-only links and serves them
+type ShareHandler struct {
+	db      database.ShareLinkRepository
+	signals database.SignalRepository
+	reports reports.Source
+	signer  *share.Signer
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(db database.ShareLinkRepository, signals database.SignalRepository, reportSource reports.Source, signer *share.Signer) *ShareHandler {
+	return &ShareHandler{db: db, signals: signals, reports: reportSource, signer: signer}
+}
+
+// shareLinkResponse is a link with its public URL
+type shareLinkResponse struct {
+	database.ShareLink
+	URL string `json:"url"`
+}
+
+func (h *ShareHandler) response(l *database.ShareLink) shareLinkResponse {
+	return shareLinkResponse{ShareLink: *l, URL: h.signer.URL(l.ID, l.Expires())}
+}
+
+// CreateShareLink handles POST /api/share.
+// Body: kind (signal, dashboard or report), signal_id (for signals) and
+// ttl_hours (default 72, at most 720). Signals are shared live; dashboards
+// and the daily report are captured now. Reports leave out portfolios.
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	var body struct {
+		Kind     string `json:"kind" binding:"required"`
+		SignalID string `json:"signal_id"`
+		TTLHours int    `json:"ttl_hours"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind is required"})
+		return
+	}
+	if body.TTLHours == 0 {
+		body.TTLHours = defaultShareTTLHours
+	}
+	if body.TTLHours < 1 || body.TTLHours > maxShareTTLHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ttl_hours must be between 1 and 720"})
+		return
+	}
+
+	now := time.Now()
+	kind := strings.ToLower(body.Kind)
+	var target string
+	var snapshot interface{}
+	switch kind {
+	case share.KindSignal:
+		target = strings.TrimSpace(body.SignalID)
+		if target == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "signal_id is required to share a signal"})
+			return
+		}
+		signal, err := h.signals.GetSignalByID(ctx, target)
+		if err != nil {
+			log.Printf("❌ Failed to get signal %s to share: %v", target, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal"})
+			return
+		}
+		if signal == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+			return
+		}
+	case share.KindDashboard:
+		data, err := h.signals.GetDashboardData(ctx, sharedDashboardSignals, false)
+		if err != nil {
+			log.Printf("❌ Failed to get dashboard to share: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dashboard"})
+			return
+		}
+		snapshot = share.PublicDashboard(data, now)
+	case share.KindReport:
+		report, err := reports.RenderMarketSummary(ctx, h.reports, now)
+		if err != nil {
+			log.Printf("❌ Failed to render report to share: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+			return
+		}
+		snapshot = share.Report{Subject: report.Subject, Text: report.Text}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be one of " + strings.Join(share.Kinds, ", ")})
+		return
+	}
+
+	var raw json.RawMessage
+	if snapshot != nil {
+		var err error
+		if raw, err = json.Marshal(snapshot); err != nil {
+			log.Printf("❌ Failed to encode %s snapshot: %v", kind, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+			return
+		}
+	}
+	expires := now.Add(time.Duration(body.TTLHours) * time.Hour)
+	link, err := h.db.CreateShareLink(ctx, database.NewShareLink(requestAccountID(c), requestUserID(c), kind, target, raw, expires))
+	if err != nil {
+		log.Printf("❌ Failed to create share link: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+	c.JSON(http.StatusCreated, h.response(link))
+}
+
+// ListShareLinks handles GET /api/share, the account's unexpired links
+func (h *ShareHandler) ListShareLinks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	links, err := h.db.ListShareLinks(ctx, requestAccountID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list share links: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve share links"})
+		return
+	}
+	out := make([]shareLinkResponse, len(links))
+	for i := range links {
+		out[i] = h.response(&links[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"links": out, "count": len(out)})
+}
+
+// DeleteShareLink handles DELETE /api/share/:id, revoking the link
+func (h *ShareHandler) DeleteShareLink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+	deleted, err := h.db.DeleteShareLink(ctx, requestAccountID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete share link %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share link"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked", "id": id})
+}
+
+// GetShared handles GET /share/:token. It needs no authentication; invalid,
+// expired and revoked links all get the same 404.
+func (h *ShareHandler) GetShared(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	c.Header("X-Robots-Tag", "noindex")
+	now := time.Now()
+	notFound := gin.H{"error": "Link not found or expired"}
+	id, ok := h.signer.Verify(c.Param("token"), now)
+	if !ok {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+	link, err := h.db.GetShareLink(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to get share link %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared item"})
+		return
+	}
+	if link == nil || now.After(link.Expires()) {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+
+	var data interface{} = link.Snapshot
+	if link.Kind == share.KindSignal {
+		signal, err := h.signals.GetSignalByID(ctx, link.Target)
+		if err != nil {
+			log.Printf("❌ Failed to get shared signal %s: %v", link.Target, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared item"})
+			return
+		}
+		if signal == nil {
+			c.JSON(http.StatusNotFound, notFound)
+			return
+		}
+		data = share.PublicSignal(signal)
+	}
+
+	if err := h.db.RecordShareView(ctx, link.ID); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"kind":       link.Kind,
+		link.Kind:    data,
+		"shared_at":  link.CreatedAt,
+		"expires_at": link.ExpiresAt,
+	})
+}
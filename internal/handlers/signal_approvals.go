@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// ApprovalPublisher announces review outcomes to the execution layer
+type ApprovalPublisher interface {
+	Publish(a *database.SignalApproval)
+}
+
+// SignalApprovalHandler lists and decides reviews of large signals
+type SignalApprovalHandler struct {
+	db        database.SignalApprovalRepository
+	publisher ApprovalPublisher
+}
+
+// NewSignalApprovalHandler creates a new signal approval handler
+func NewSignalApprovalHandler(db database.SignalApprovalRepository, publisher ApprovalPublisher) *SignalApprovalHandler {
+	return &SignalApprovalHandler{db: db, publisher: publisher}
+}
+
+// ListApprovals handles GET /api/signals/approvals.
+// Query: status (pending, approved, rejected, expired or all; default
+// pending) and limit (default 100, max 500).
+func (h *SignalApprovalHandler) ListApprovals(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status := strings.ToLower(c.DefaultQuery("status", database.ApprovalPending))
+	switch status {
+	case "all":
+		status = ""
+	case database.ApprovalPending, database.ApprovalApproved, database.ApprovalRejected, database.ApprovalExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved, rejected, expired or all"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	approvals, err := h.db.ListSignalApprovals(ctx, status, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list signal approvals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve approvals"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approvals": approvals, "count": len(approvals)})
+}
+
+// GetApproval handles GET /api/signals/:id/approval. A signal below the
+// threshold has no review and may be acted on straight away.
+func (h *SignalApprovalHandler) GetApproval(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	approval, err := h.db.GetSignalApproval(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get approval for signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve approval"})
+		return
+	}
+	if approval == nil {
+		c.JSON(http.StatusOK, gin.H{"signal_id": signalID, "approval_required": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"signal_id": signalID, "approval_required": true, "approval": approval})
+}
+
+// Approve handles POST /api/signals/:id/approve. Body: note (optional).
+func (h *SignalApprovalHandler) Approve(c *gin.Context) {
+	h.decide(c, database.ApprovalApproved)
+}
+
+// Reject handles POST /api/signals/:id/reject. Body: note (optional).
+func (h *SignalApprovalHandler) Reject(c *gin.Context) {
+	h.decide(c, database.ApprovalRejected)
+}
+
+// decide records status on a pending review and announces it
+func (h *SignalApprovalHandler) decide(c *gin.Context, status string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	if len(body.Note) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note must be at most 500 characters"})
+		return
+	}
+
+	signalID := c.Param("id")
+	actor := requestActor(c)
+	approval, err := h.db.DecideSignalApproval(ctx, signalID, status, actor, strings.TrimSpace(body.Note))
+	if err != nil {
+		log.Printf("❌ Failed to record %s for signal %s: %v", status, signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+	if approval == nil {
+		// Tell the caller why: no review, or one already closed
+		existing, err := h.db.GetSignalApproval(ctx, signalID)
+		if err != nil {
+			log.Printf("❌ Failed to get approval for signal %s: %v", signalID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+			return
+		}
+		if existing == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signal is not awaiting approval"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Approval is no longer pending", "approval": existing})
+		return
+	}
+
+	log.Printf("✋ Signal %s %s by %s", signalID, status, actor)
+	if h.publisher != nil {
+		h.publisher.Publish(approval)
+	}
+	c.JSON(http.StatusOK, approval)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+const (
+	// defaultCurrentMonthTTL is how long the current month's calendar is
+	// cached, until SetCurrentMonthTTL changes it; it changes as signals close
+	defaultCurrentMonthTTL = time.Minute
+	// pastMonthTTL is how long earlier months are cached
+	pastMonthTTL = time.Hour
+)
+
+type signalCalendarEntry struct {
+	calendar *database.SignalCalendar
+	expires  time.Time
+}
+
+// SignalCalendarHandler serves the monthly signal heatmap
+type SignalCalendarHandler struct {
+	db database.SignalCalendarRepository
+
+	mu              sync.Mutex
+	cache           map[string]signalCalendarEntry
+	currentMonthTTL atomic.Int64
+}
+
+// NewSignalCalendarHandler creates a new signal calendar handler
+func NewSignalCalendarHandler(db database.SignalCalendarRepository) *SignalCalendarHandler {
+	h := &SignalCalendarHandler{db: db, cache: map[string]signalCalendarEntry{}}
+	h.currentMonthTTL.Store(int64(defaultCurrentMonthTTL))
+	return h
+}
+
+// SetCurrentMonthTTL changes how long the current month's calendar is
+// cached from now on; 0 disables caching it
+func (h *SignalCalendarHandler) SetCurrentMonthTTL(ttl time.Duration) {
+	h.currentMonthTTL.Store(int64(ttl))
+}
+
+// GetSignalCalendar handles GET /api/signals/calendar.
+// Query: month (YYYY-MM, default the current month in IST). Every day of the
+// month is returned with its signal count, win rate and net P&L.
+func (h *SignalCalendarHandler) GetSignalCalendar(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	loc := database.SessionOpen(time.Now()).Location()
+	now := time.Now().In(loc)
+	current := now.Format("2006-01")
+	start, err := time.ParseInLocation("2006-01", c.DefaultQuery("month", current), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month must be YYYY-MM"})
+		return
+	}
+	if start.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month must not be in the future"})
+		return
+	}
+	month := start.Format("2006-01")
+
+	h.mu.Lock()
+	entry, ok := h.cache[month]
+	h.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		c.JSON(http.StatusOK, entry.calendar)
+		return
+	}
+
+	cal, err := h.db.GetSignalCalendar(ctx, start)
+	if err != nil {
+		log.Printf("❌ Failed to build signal calendar for %s: %v", month, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build signal calendar"})
+		return
+	}
+
+	ttl := pastMonthTTL
+	if month == current {
+		ttl = time.Duration(h.currentMonthTTL.Load())
+	}
+	h.mu.Lock()
+	for k, e := range h.cache {
+		if time.Now().After(e.expires) {
+			delete(h.cache, k)
+		}
+	}
+	h.cache[month] = signalCalendarEntry{calendar: cal, expires: time.Now().Add(ttl)}
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, cal)
+}
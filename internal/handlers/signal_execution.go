@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/events"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// EventPublisher publishes a raw event on a NATS subject
+type EventPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// SignalExecutionHandler manages time-in-force and scaling rules on signals
+type SignalExecutionHandler struct {
+	db        database.SignalRepository
+	publisher EventPublisher
+}
+
+// NewSignalExecutionHandler creates a new signal execution handler.
+// publisher may be nil when NATS is not connected; updates are then only
+// stored.
+func NewSignalExecutionHandler(db database.SignalRepository, publisher EventPublisher) *SignalExecutionHandler {
+	return &SignalExecutionHandler{db: db, publisher: publisher}
+}
+
+// UpdateExecution handles PUT /api/signals/:id/execution. The body replaces
+// the signal's policy; send an empty object to clear it.
+func (h *SignalExecutionHandler) UpdateExecution(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	var body database.SignalExecution
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	signal, err := h.db.GetSignalByID(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal"})
+		return
+	}
+	if signal == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+		return
+	}
+	if signal.Status != "ACTIVE" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only active signals can be changed"})
+		return
+	}
+	if err := body.Validate(signal); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.db.UpdateSignalExecution(ctx, signalID, body)
+	if err != nil {
+		log.Printf("❌ Failed to update signal %s execution: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update signal"})
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Signal closed before the update was applied"})
+		return
+	}
+
+	h.publish(updated, body)
+	c.JSON(http.StatusOK, updated)
+}
+
+// publish announces the new policy as a signal.updated event so the
+// paper-trading engine and dashboards pick it up
+func (h *SignalExecutionHandler) publish(s *database.Signal, e database.SignalExecution) {
+	if h.publisher == nil {
+		return
+	}
+	event := signalEvent("signal.updated", s)
+	event.ScalingRules = e.ScalingRules
+
+	data, _ := json.Marshal(event)
+	if err := h.publisher.Publish("signal.updated", data); err != nil {
+		log.Printf("❌ Failed to publish execution update for signal %s: %v", s.SignalID, err)
+	}
+}
+
+// signalEvent builds the event announcing s on subject
+func signalEvent(subject string, s *database.Signal) events.SignalEvent {
+	// Engine signal IDs are numeric; others are sent as 0 and matched by symbol
+	id, _ := strconv.Atoi(s.SignalID)
+	event := events.SignalEvent{
+		SchemaVersion: schemas.SignalVersion,
+		EventType:     subject,
+		SignalID:      id,
+		Symbol:        s.Symbol,
+		SignalType:    s.SignalType,
+		EntryPrice:    s.EntryPrice,
+		StopLoss:      s.StopLoss,
+		TargetPrice:   s.TargetPrice,
+		Confidence:    s.ConfidenceScore,
+		Status:        s.Status,
+		CurrentPrice:  s.CurrentPrice,
+		GeneratedAt:   s.GeneratedAt.Format(time.RFC3339),
+		Timestamp:     time.Now().Format(time.RFC3339Nano),
+	}
+	if s.TimeInForce != nil {
+		event.TimeInForce = *s.TimeInForce
+	}
+	if s.ExpiresAt != nil {
+		event.ExpiresAt = s.ExpiresAt.Format(time.RFC3339)
+	}
+	return event
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// SignalFillHandler records signal executions and reports slippage
+type SignalFillHandler struct {
+	signals database.SignalRepository
+	fills   database.SignalFillRepository
+}
+
+// NewSignalFillHandler creates a new signal fill handler
+func NewSignalFillHandler(signals database.SignalRepository, fills database.SignalFillRepository) *SignalFillHandler {
+	return &SignalFillHandler{signals: signals, fills: fills}
+}
+
+// ListFills handles GET /api/signals/:id/fills
+func (h *SignalFillHandler) ListFills(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	fills, err := h.fills.ListSignalFills(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to list fills for signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve fills"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"signal_id": signalID, "fills": fills, "count": len(fills)})
+}
+
+// RecordFill handles POST /api/signals/:id/fills.
+// Body: leg (entry or exit), price, quantity (optional), filled_at (RFC3339,
+// default now), source (paper or manual, default manual) and external_id
+// (optional). Broker fills come from portfolio imports, not this endpoint.
+// A second fill for the same leg and source replaces the first.
+func (h *SignalFillHandler) RecordFill(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Leg        string   `json:"leg" binding:"required"`
+		Price      float64  `json:"price" binding:"required"`
+		Quantity   *float64 `json:"quantity"`
+		FilledAt   string   `json:"filled_at"`
+		Source     string   `json:"source"`
+		ExternalID *string  `json:"external_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leg and price are required"})
+		return
+	}
+	leg := strings.ToLower(body.Leg)
+	if leg != database.FillEntry && leg != database.FillExit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leg must be entry or exit"})
+		return
+	}
+	source := strings.ToLower(body.Source)
+	if source == "" {
+		source = database.FillSourceManual
+	}
+	if source != database.FillSourcePaper && source != database.FillSourceManual {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source must be paper or manual"})
+		return
+	}
+	if body.Price <= 0 || (body.Quantity != nil && *body.Quantity <= 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price and quantity must be positive"})
+		return
+	}
+	filledAt := time.Now()
+	if body.FilledAt != "" {
+		t, err := time.Parse(time.RFC3339, body.FilledAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "filled_at must be RFC3339"})
+			return
+		}
+		if t.After(time.Now().Add(time.Minute)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "filled_at must not be in the future"})
+			return
+		}
+		filledAt = t
+	}
+
+	signalID := c.Param("id")
+	signal, err := h.signals.GetSignalByID(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal"})
+		return
+	}
+	if signal == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+		return
+	}
+
+	fill, err := h.fills.UpsertSignalFill(ctx, signal.SignalID, leg, source, body.Price, body.Quantity, filledAt, body.ExternalID)
+	if err != nil {
+		log.Printf("❌ Failed to record %s fill for signal %s: %v", leg, signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record fill"})
+		return
+	}
+	c.JSON(http.StatusOK, fill)
+}
+
+// GetSlippage handles GET /api/quant/slippage.
+// Query: days (lookback, default 30, 1-365) and source (broker, paper or
+// manual; default all). Fills are compared with the signal's entry and with
+// the target or stop it closed on, grouped by symbol and IST hour of day.
+func (h *SignalFillHandler) GetSlippage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+	source := strings.ToLower(c.Query("source"))
+	switch source {
+	case "", database.FillSourceBroker, database.FillSourcePaper, database.FillSourceManual:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source must be broker, paper or manual"})
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := h.fills.GetSlippageReport(ctx, since, source)
+	if err != nil {
+		log.Printf("❌ Failed to build slippage report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute slippage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":       days,
+		"from":       since.Format(time.RFC3339),
+		"source":     source,
+		"timezone":   "Asia/Kolkata",
+		"overall":    report.Overall,
+		"by_symbol":  report.BySymbol,
+		"by_hour":    report.ByHour,
+		"unmeasured": report.Unmeasured,
+		"timestamp":  time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// maxIngestSignals caps the signals in one ingest batch
+const maxIngestSignals = 1000
+
+// providerPattern keeps provider names short and safe inside signal IDs
+var providerPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,31}$`)
+
+// SignalIngestHandler accepts signals generated outside the engine
+type SignalIngestHandler struct {
+	db        database.SignalIngestRepository
+	publisher EventPublisher
+}
+
+// NewSignalIngestHandler creates a new signal ingest handler. publisher may
+// be nil when NATS is not connected; ingested signals are then only stored.
+func NewSignalIngestHandler(db database.SignalIngestRepository, publisher EventPublisher) *SignalIngestHandler {
+	return &SignalIngestHandler{db: db, publisher: publisher}
+}
+
+// ingestSignalBody is one signal in a JSON batch
+type ingestSignalBody struct {
+	ExternalID  string     `json:"external_id"`
+	Symbol      string     `json:"symbol"`
+	SignalType  string     `json:"signal_type"`
+	Confidence  float64    `json:"confidence"`
+	EntryPrice  float64    `json:"entry_price"`
+	StopLoss    float64    `json:"stop_loss"`
+	TargetPrice float64    `json:"target_price"`
+	GeneratedAt *time.Time `json:"generated_at"`
+	TimeInForce string     `json:"time_in_force"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// ingestRow is a parsed signal with the row it came from
+type ingestRow struct {
+	row    int
+	signal database.IngestedSignal
+}
+
+// IngestSignals handles POST /api/signals/ingest.
+//
+// Accepts a JSON body {"provider": ..., "signals": [...]} or a CSV (raw
+// text/csv body or multipart field "file") with ?provider=. Signals have
+// external_id, symbol, signal_type (BUY or SELL), confidence (0-1),
+// entry_price, stop_loss, target_price, and optionally generated_at,
+// time_in_force and expires_at. Valid signals are stored as active and
+// announced as signal.new; invalid rows are reported and skipped, and
+// signals already ingested from the provider are counted as duplicates.
+func (h *SignalIngestHandler) IngestSignals(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	var provider string
+	var rows []ingestRow
+	var rowErrors []importRowError
+	if c.ContentType() == "application/json" {
+		var body struct {
+			Provider string             `json:"provider"`
+			Signals  []ingestSignalBody `json:"signals"`
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		provider = body.Provider
+		for i, s := range body.Signals {
+			r := ingestRow{row: i + 1, signal: database.IngestedSignal{
+				ExternalID:  s.ExternalID,
+				Symbol:      s.Symbol,
+				SignalType:  s.SignalType,
+				Confidence:  s.Confidence,
+				EntryPrice:  s.EntryPrice,
+				StopLoss:    s.StopLoss,
+				TargetPrice: s.TargetPrice,
+				TimeInForce: s.TimeInForce,
+				ExpiresAt:   s.ExpiresAt,
+			}}
+			if s.GeneratedAt != nil {
+				r.signal.GeneratedAt = *s.GeneratedAt
+			}
+			rows = append(rows, r)
+		}
+	} else {
+		provider = c.Query("provider")
+		reader, err := importReader(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer reader.Close()
+
+		rows, rowErrors, err = parseSignalCSV(reader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if !providerPattern.MatchString(provider) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider is required: up to 32 lowercase letters, digits or _"})
+		return
+	}
+	if len(rows)+len(rowErrors) > maxIngestSignals {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("At most %d signals per batch", maxIngestSignals)})
+		return
+	}
+	if rowErrors == nil {
+		rowErrors = []importRowError{}
+	}
+
+	res, err := ingestRows(ctx, h.db, h.publisher, provider, rows)
+	if err != nil {
+		log.Printf("❌ Failed to ingest signals from %s: %v", provider, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ingest signals"})
+		return
+	}
+	rowErrors = append(rowErrors, res.errors...)
+	log.Printf("✅ Ingested %d signals from %s (%d duplicates, %d rejected)", len(res.ids), provider, res.duplicates, len(rowErrors))
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider":   provider,
+		"ingested":   len(res.ids),
+		"duplicates": res.duplicates,
+		"rejected":   len(rowErrors),
+		"signal_ids": res.ids,
+		"errors":     rowErrors,
+	})
+}
+
+// ingestOutcome is what became of a batch of ingested signals
+type ingestOutcome struct {
+	ids        []string
+	accepted   int
+	duplicates int
+	errors     []importRowError
+}
+
+// ingestRows validates rows and stores them as provider's signals,
+// announcing the new ones. Invalid rows and rows for unknown symbols are
+// reported in the outcome's errors; repeats, in the batch or of signals
+// already stored, are counted as duplicates.
+func ingestRows(ctx context.Context, db database.SignalIngestRepository, publisher EventPublisher, provider string, rows []ingestRow) (*ingestOutcome, error) {
+	out := &ingestOutcome{ids: []string{}, errors: []importRowError{}}
+	now := time.Now()
+	seen := map[string]bool{}
+	signals := make([]database.IngestedSignal, 0, len(rows))
+	rowsBySymbol := map[string][]int{}
+	for _, r := range rows {
+		if err := r.signal.Validate(now); err != nil {
+			out.errors = append(out.errors, importRowError{Row: r.row, Error: err.Error()})
+			continue
+		}
+		if seen[r.signal.ExternalID] {
+			out.duplicates++
+			continue
+		}
+		seen[r.signal.ExternalID] = true
+		signals = append(signals, r.signal)
+		rowsBySymbol[r.signal.Symbol] = append(rowsBySymbol[r.signal.Symbol], r.row)
+	}
+
+	result, err := db.IngestSignals(ctx, provider, signals)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range result.UnknownSymbols {
+		for _, row := range rowsBySymbol[symbol] {
+			out.errors = append(out.errors, importRowError{Row: row, Error: "unknown symbol " + symbol})
+		}
+	}
+	out.duplicates += result.Duplicates
+
+	for i := range result.Signals {
+		out.ids = append(out.ids, result.Signals[i].SignalID)
+		publishNewSignal(publisher, &result.Signals[i])
+	}
+	out.accepted = len(out.ids)
+	return out, nil
+}
+
+// publishNewSignal announces a signal stored here as signal.new so
+// notifications, WebSocket clients and the paper-trading engine treat it like
+// any other. publisher may be nil.
+func publishNewSignal(publisher EventPublisher, s *database.Signal) {
+	if publisher == nil {
+		return
+	}
+	data, _ := json.Marshal(signalEvent("signal.new", s))
+	if err := publisher.Publish("signal.new", data); err != nil {
+		log.Printf("❌ Failed to publish new signal %s: %v", s.SignalID, err)
+	}
+}
+
+// parseSignalCSV parses an ingest CSV. Columns are matched by header name and
+// use the JSON field names; times are RFC3339 or IST date-times.
+func parseSignalCSV(r io.Reader) ([]ingestRow, []importRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"symbol", "signal_type", "confidence", "entry_price", "stop_loss", "target_price"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	rows := []ingestRow{}
+	rowErrors := []importRowError{}
+	row := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: err.Error()})
+			continue
+		}
+
+		s := database.IngestedSignal{
+			ExternalID:  field(record, "external_id"),
+			Symbol:      field(record, "symbol"),
+			SignalType:  field(record, "signal_type"),
+			TimeInForce: field(record, "time_in_force"),
+		}
+		var bad string
+		for _, f := range []struct {
+			name string
+			dst  *float64
+		}{
+			{"confidence", &s.Confidence},
+			{"entry_price", &s.EntryPrice},
+			{"stop_loss", &s.StopLoss},
+			{"target_price", &s.TargetPrice},
+		} {
+			if *f.dst, err = strconv.ParseFloat(field(record, f.name), 64); err != nil {
+				bad = "invalid " + f.name
+				break
+			}
+		}
+		if v := field(record, "generated_at"); bad == "" && v != "" {
+			t, ok := parseTradeTime(ist, v)
+			if !ok {
+				bad = "invalid generated_at"
+			}
+			s.GeneratedAt = t
+		}
+		if v := field(record, "expires_at"); bad == "" && v != "" {
+			t, ok := parseTradeTime(ist, v)
+			if !ok {
+				bad = "invalid expires_at"
+			}
+			s.ExpiresAt = &t
+		}
+		if bad != "" {
+			rowErrors = append(rowErrors, importRowError{Row: row, Error: bad})
+			continue
+		}
+		rows = append(rows, ingestRow{row: row, signal: s})
+	}
+
+	return rows, rowErrors, nil
+}
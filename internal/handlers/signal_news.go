@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/signalnews"
+)
+
+// SignalNewsHandler serves the news linked to signals
+type SignalNewsHandler struct {
+	linker *signalnews.Linker
+}
+
+// NewSignalNewsHandler creates a new signal news handler
+func NewSignalNewsHandler(linker *signalnews.Linker) *SignalNewsHandler {
+	return &SignalNewsHandler{linker: linker}
+}
+
+// GetSignalNews handles GET /api/signals/:id/news, returning the
+// high-impact articles for the signal's symbol published shortly before it,
+// nearest first
+func (h *SignalNewsHandler) GetSignalNews(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	news, err := h.linker.ForSignal(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get news for signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal news"})
+		return
+	}
+	if news == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, news)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/nlquery"
+)
+
+// errStopStream ends a StreamSignals scan early
+var errStopStream = errors.New("stop stream")
+
+// signalStreamer is the read signal scans are built on
+type signalStreamer interface {
+	StreamSignals(ctx context.Context, limit int, status string, fn func(database.Signal) error) error
+}
+
+// scanSignals streams signals with status, newest first, passing those
+// generated in [from, to) that match to fn until limit have been found. A
+// zero from or to is unbounded, and a limit or maxScan of 0 is unlimited.
+// It reports whether maxScan cut the scan short.
+func scanSignals(ctx context.Context, db signalStreamer, status string, from, to time.Time, match func(*database.Signal) bool, limit, maxScan int, fn func(database.Signal) error) (bool, error) {
+	scanned, found := 0, 0
+	truncated := false
+	err := db.StreamSignals(ctx, 0, status, func(s database.Signal) error {
+		scanned++
+		if !from.IsZero() && s.GeneratedAt.Before(from) {
+			return errStopStream
+		}
+		if maxScan > 0 && scanned > maxScan {
+			truncated = true
+			return errStopStream
+		}
+		if (!to.IsZero() && !s.GeneratedAt.Before(to)) || !match(&s) {
+			return nil
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+		if found++; found == limit {
+			return errStopStream
+		}
+		return nil
+	})
+	if errors.Is(err, errStopStream) {
+		err = nil
+	}
+	return truncated, err
+}
+
+// parseHumanFilters reads ?q= (free text) and ?when= (a time phrase, which
+// wins over one in q) with nlquery. It returns nil when neither is given,
+// and writes the error response itself when when doesn't parse.
+func parseHumanFilters(c *gin.Context) (*nlquery.Query, bool) {
+	text, when := c.Query("q"), c.Query("when")
+	if text == "" && when == "" {
+		return nil, true
+	}
+	now := time.Now()
+	q := nlquery.Parse(text, now)
+	if when != "" {
+		r, err := nlquery.ParseWhen(when, now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		q.When = r
+	}
+	return q, true
+}
+
+// interpretedAs echoes what q was understood as. Filters the endpoint
+// doesn't take are listed under not_applied, and words nlquery didn't know
+// under ignored.
+func interpretedAs(q *nlquery.Query, supported ...string) gin.H {
+	out := gin.H{}
+	notApplied := []string{}
+	for key, v := range q.Interpretation() {
+		applied := false
+		for _, s := range supported {
+			applied = applied || s == key
+		}
+		if applied {
+			out[key] = v
+		} else {
+			notApplied = append(notApplied, key)
+		}
+	}
+	sort.Strings(notApplied)
+	if len(notApplied) > 0 {
+		out["not_applied"] = notApplied
+	}
+	if len(q.Ignored) > 0 {
+		out["ignored"] = q.Ignored
+	}
+	return out
+}
+
+// scanMismatch writes a 400 and returns true when q describes a different
+// scan than the endpoint's
+func scanMismatch(c *gin.Context, q *nlquery.Query, scan string) bool {
+	if q == nil || q.Scan == "" || q.Scan == scan {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":          fmt.Sprintf("q describes a %s scan; use /api/scans/%s", q.Scan, q.Scan),
+		"interpreted_as": q.Interpretation(),
+	})
+	return true
+}
+
+// searchSignals serves GET /api/signals with ?q= or ?when=, filtering the
+// signal stream by the interpreted date range, sectors, direction, status
+// and outcome. An explicit ?status= wins over one in q.
+func (h *Handler) searchSignals(ctx context.Context, c *gin.Context, source signalStreamer, q *nlquery.Query, limit int, status string) {
+	if status == "" {
+		status = q.Status
+	}
+	signalType := q.SignalType
+	if signalType == "" {
+		switch q.Direction {
+		case "up":
+			signalType = "BUY"
+		case "down":
+			signalType = "SELL"
+		}
+	}
+	var from, to time.Time
+	if q.When != nil {
+		from, to = q.When.From, q.When.To
+	}
+	match := func(s *database.Signal) bool {
+		if signalType != "" && s.SignalType != signalType {
+			return false
+		}
+		if len(q.Sectors) > 0 {
+			inSector := false
+			for _, sector := range q.Sectors {
+				inSector = inSector || strings.EqualFold(s.Sector, sector)
+			}
+			if !inSector {
+				return false
+			}
+		}
+		pct := signalProfitPct(s)
+		return !(q.Outcome == "winning" && pct <= 0) && !(q.Outcome == "losing" && pct >= 0)
+	}
+	interpreted := interpretedAs(q, "sectors", "direction", "signal_type", "status", "outcome", "when")
+
+	if shouldStream(c, limit) {
+		stream := newListStream(c, listEnvelope{Field: "signals", CountKey: "count", Extra: gin.H{"interpreted_as": interpreted}})
+		_, err := scanSignals(ctx, source, status, from, to, match, limit, 0, func(s database.Signal) error {
+			return stream.Write(s)
+		})
+		stream.Close(err, "Failed to retrieve signals")
+		return
+	}
+
+	signals := []database.Signal{}
+	_, err := scanSignals(ctx, source, status, from, to, match, limit, 0, func(s database.Signal) error {
+		signals = append(signals, s)
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to search signals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signals"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"signals":        signals,
+		"count":          len(signals),
+		"interpreted_as": interpreted,
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// SignalTimelineHandler serves the lifecycle events of signals
+type SignalTimelineHandler struct {
+	signals  database.SignalRepository
+	timeline database.SignalTimelineRepository
+}
+
+// NewSignalTimelineHandler creates a new signal timeline handler
+func NewSignalTimelineHandler(signals database.SignalRepository, timeline database.SignalTimelineRepository) *SignalTimelineHandler {
+	return &SignalTimelineHandler{signals: signals, timeline: timeline}
+}
+
+// GetTimeline handles GET /api/signals/:id/timeline, returning the signal's
+// lifecycle events oldest first: generated, threshold_crossed, stop_moved,
+// status_changed and closed. Deleted signals keep their timeline.
+func (h *SignalTimelineHandler) GetTimeline(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	events, err := h.timeline.GetSignalTimeline(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get timeline for signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal timeline"})
+		return
+	}
+	if len(events) == 0 {
+		signal, err := h.signals.GetSignalByID(ctx, signalID)
+		if err != nil {
+			log.Printf("❌ Failed to get signal %s: %v", signalID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal timeline"})
+			return
+		}
+		if signal == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"signal_id": signalID, "events": events, "count": len(events)})
+}
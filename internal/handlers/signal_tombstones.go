@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// SignalTombstoneHandler soft-deletes and restores signals entered by
+// ingest or by hand
+type SignalTombstoneHandler struct {
+	db            database.SignalTombstoneRepository
+	publisher     EventPublisher
+	restoreWindow time.Duration
+}
+
+// NewSignalTombstoneHandler creates a new signal tombstone handler. Deleted
+// signals can be restored for restoreWindow. publisher may be nil when NATS
+// is not connected.
+func NewSignalTombstoneHandler(db database.SignalTombstoneRepository, publisher EventPublisher, restoreWindow time.Duration) *SignalTombstoneHandler {
+	return &SignalTombstoneHandler{db: db, publisher: publisher, restoreWindow: restoreWindow}
+}
+
+// DeleteSignal handles DELETE /api/signals/:id. Body (or ?reason=): reason,
+// required. Only ingested and manual signals can be deleted; the signal
+// disappears from lists and analytics and is announced as signal.deleted.
+func (h *SignalTombstoneHandler) DeleteSignal(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" {
+		reason = strings.TrimSpace(c.Query("reason"))
+	}
+	if reason == "" || len(reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required, up to 500 characters"})
+		return
+	}
+
+	signalID := c.Param("id")
+	signal, err := h.db.GetSignalByID(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to get signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve signal"})
+		return
+	}
+	if signal == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+		return
+	}
+	if !database.SignalDeletable(signal) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only ingested and manual signals can be deleted"})
+		return
+	}
+
+	actor := requestActor(c)
+	tombstone, err := h.db.SoftDeleteSignal(ctx, signalID, reason, actor, time.Now().Add(h.restoreWindow))
+	if err != nil {
+		log.Printf("❌ Failed to delete signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete signal"})
+		return
+	}
+	if tombstone == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signal not found"})
+		return
+	}
+	log.Printf("🗑️  Signal %s deleted by %s: %s", signalID, actor, reason)
+	h.publish("signal.deleted", signal)
+	c.JSON(http.StatusOK, tombstone)
+}
+
+// RestoreSignal handles POST /api/signals/:id/restore. A restored active
+// signal is announced as signal.new again.
+func (h *SignalTombstoneHandler) RestoreSignal(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	signalID := c.Param("id")
+	signal, err := h.db.RestoreSignal(ctx, signalID)
+	if errors.Is(err, database.ErrSignalExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A signal with this ID has been stored since it was deleted"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to restore signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore signal"})
+		return
+	}
+	if signal == nil {
+		tombstone, err := h.db.GetSignalTombstone(ctx, signalID)
+		if err != nil {
+			log.Printf("❌ Failed to get tombstone for signal %s: %v", signalID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore signal"})
+			return
+		}
+		if tombstone == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signal has not been deleted"})
+			return
+		}
+		c.JSON(http.StatusGone, gin.H{"error": "The restore window closed at " + tombstone.RestoreUntil, "tombstone": tombstone})
+		return
+	}
+
+	log.Printf("♻️  Signal %s restored by %s", signalID, requestActor(c))
+	if signal.Status == "ACTIVE" {
+		h.publish("signal.new", signal)
+	}
+	c.JSON(http.StatusOK, signal)
+}
+
+// ListDeletedSignals handles GET /api/signals/deleted. Query: limit
+// (default 100, max 500).
+func (h *SignalTombstoneHandler) ListDeletedSignals(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+	tombstones, err := h.db.ListSignalTombstones(ctx, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list deleted signals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deleted signals"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"signals":        tombstones,
+		"count":          len(tombstones),
+		"restore_window": h.restoreWindow.String(),
+	})
+}
+
+// publish announces a deleted or restored signal
+func (h *SignalTombstoneHandler) publish(subject string, s *database.Signal) {
+	if h.publisher == nil {
+		return
+	}
+	data, _ := json.Marshal(signalEvent(subject, s))
+	if err := h.publisher.Publish(subject, data); err != nil {
+		log.Printf("❌ Failed to publish %s for signal %s: %v", subject, s.SignalID, err)
+	}
+}
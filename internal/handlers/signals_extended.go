@@ -7,17 +7,29 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
 )
 
-// GetDashboardData handles GET /api/signals/dashboard
+// GetDashboardData handles GET /api/signals/dashboard. With ?as_of it
+// reconstructs the dashboard as it stood at that instant.
 func (h *Handler) GetDashboardData(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 	includeClosed := c.DefaultQuery("include_closed", "false") == "true"
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
 
-	data, err := h.db.GetDashboardData(ctx, limit, includeClosed)
+	var data *database.DashboardData
+	var err error
+	if asOf != nil {
+		data, err = h.db.GetDashboardDataAsOf(ctx, *asOf, limit, includeClosed)
+	} else {
+		data, err = h.db.GetDashboardData(ctx, limit, includeClosed)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dashboard data"})
 		return
@@ -28,7 +40,7 @@ func (h *Handler) GetDashboardData(c *gin.Context) {
 
 // GetInvestmentSignals handles GET /api/signals/investment-signals
 func (h *Handler) GetInvestmentSignals(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	minConfidence, _ := strconv.ParseFloat(c.DefaultQuery("min_confidence", "0.5"), 64)
@@ -46,7 +58,7 @@ func (h *Handler) GetInvestmentSignals(c *gin.Context) {
 
 // GetSignalAlerts handles GET /api/signals/alerts
 func (h *Handler) GetSignalAlerts(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	strategy := c.Query("strategy")
@@ -63,7 +75,7 @@ func (h *Handler) GetSignalAlerts(c *gin.Context) {
 
 // GetPredictedGainers handles GET /api/predictions/top-gainers
 func (h *Handler) GetPredictedGainers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -82,7 +94,7 @@ func (h *Handler) GetPredictedGainers(c *gin.Context) {
 
 // GetPredictedLosers handles GET /api/predictions/top-losers
 func (h *Handler) GetPredictedLosers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
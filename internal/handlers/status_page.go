@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/status"
+)
+
+// StatusPageHandler serves the public status page roll-up
+type StatusPageHandler struct {
+	monitor *status.Monitor
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(monitor *status.Monitor) *StatusPageHandler {
+	return &StatusPageHandler{monitor: monitor}
+}
+
+// GetStatus handles GET /api/status. The response only changes once a
+// minute, so edges may cache it briefly and serve it stale if we're down.
+func (h *StatusPageHandler) GetStatus(c *gin.Context) {
+	page := h.monitor.Page()
+	if page == nil {
+		c.Header("Retry-After", "10")
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Status not yet available"})
+		return
+	}
+
+	sum := sha1.Sum([]byte(page.UpdatedAt + page.Status))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=30, s-maxage=60, stale-while-revalidate=30, stale-if-error=86400")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -13,7 +13,7 @@ import (
 
 // GetStockConfigs handles GET /api/stock-config/stocks
 func (h *Handler) GetStockConfigs(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
 	f := database.StockConfigFilters{}
@@ -57,7 +57,7 @@ func (h *Handler) GetStockConfigs(c *gin.Context) {
 
 // UpdateStockConfig handles PUT /api/stock-config/stocks/:symbol/:exchange
 func (h *Handler) UpdateStockConfig(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	symbol := c.Param("symbol")
@@ -104,7 +104,7 @@ func (h *Handler) UpdateStockConfig(c *gin.Context) {
 
 // GetStockConfigStats handles GET /api/stock-config/stats
 func (h *Handler) GetStockConfigStats(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	stats, err := h.db.GetStockConfigStats(ctx)
@@ -118,7 +118,7 @@ func (h *Handler) GetStockConfigStats(c *gin.Context) {
 
 // ExportStockConfigsCSV handles GET /api/stock-config/export-csv
 func (h *Handler) ExportStockConfigsCSV(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
 	csv, err := h.db.ExportStockConfigsCSV(ctx)
@@ -144,7 +144,7 @@ func (h *Handler) ImportStockConfigsCSV(c *gin.Context) {
 
 // GetImportJobStatus handles GET /api/stock-config/import-jobs/:jobId
 func (h *Handler) GetImportJobStatus(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	jobID := c.Param("jobId")
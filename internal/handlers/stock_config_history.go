@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// StockConfigHistoryHandler serves past stock config snapshots
+type StockConfigHistoryHandler struct {
+	db database.StockConfigSnapshotRepository
+}
+
+// NewStockConfigHistoryHandler creates a new stock config history handler
+func NewStockConfigHistoryHandler(db database.StockConfigSnapshotRepository) *StockConfigHistoryHandler {
+	return &StockConfigHistoryHandler{db: db}
+}
+
+// GetStockConfigAsOf handles GET /api/stock-config/as-of.
+// Query: date (YYYY-MM-DD, required) and intraday_enabled (true lists only
+// the intraday universe). Returns the snapshot taken on that day, or the
+// latest before it for weekends and holidays; snapshot_date says which.
+func (h *StockConfigHistoryHandler) GetStockConfigAsOf(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required as YYYY-MM-DD"})
+		return
+	}
+	intradayOnly := c.Query("intraday_enabled") == "true"
+
+	asOf, err := h.db.GetStockConfigAsOf(ctx, date, intradayOnly)
+	if err != nil {
+		log.Printf("❌ Failed to get stock config as of %s: %v", c.Query("date"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stock config snapshot"})
+		return
+	}
+	if asOf == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No stock config snapshot on or before " + c.Query("date")})
+		return
+	}
+
+	intradayCount := 0
+	for _, s := range asOf.Stocks {
+		if s.IntradayEnabled {
+			intradayCount++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"date":                   c.Query("date"),
+		"snapshot_date":          asOf.SnapshotDate,
+		"taken_at":               asOf.TakenAt,
+		"stocks":                 asOf.Stocks,
+		"count":                  len(asOf.Stocks),
+		"intraday_enabled_count": intradayCount,
+	})
+}
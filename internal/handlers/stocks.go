@@ -2,16 +2,21 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/exports"
 )
 
 // GetTopGainers handles GET /api/stocks/top-gainers
 func (h *Handler) GetTopGainers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -19,6 +24,11 @@ func (h *Handler) GetTopGainers(c *gin.Context) {
 		limit = 20
 	}
 
+	if gainers, ok := h.movers.Gainers(limit); ok {
+		c.JSON(http.StatusOK, gainers)
+		return
+	}
+
 	gainers, err := h.db.GetTopGainers(ctx, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top gainers"})
@@ -30,7 +40,7 @@ func (h *Handler) GetTopGainers(c *gin.Context) {
 
 // GetTopLosers handles GET /api/stocks/top-losers
 func (h *Handler) GetTopLosers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -38,6 +48,11 @@ func (h *Handler) GetTopLosers(c *gin.Context) {
 		limit = 20
 	}
 
+	if losers, ok := h.movers.Losers(limit); ok {
+		c.JSON(http.StatusOK, losers)
+		return
+	}
+
 	losers, err := h.db.GetTopLosers(ctx, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top losers"})
@@ -49,7 +64,7 @@ func (h *Handler) GetTopLosers(c *gin.Context) {
 
 // GetRealtimePrices handles GET /api/stocks/realtime/all
 func (h *Handler) GetRealtimePrices(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
@@ -57,18 +72,40 @@ func (h *Handler) GetRealtimePrices(c *gin.Context) {
 		limit = 50
 	}
 
-	prices, err := h.db.GetRealtimePrices(ctx, limit)
+	if shouldStream(c, limit) {
+		stream := newListStream(c, listEnvelope{})
+		err := h.db.StreamRealtimePrices(ctx, limit, func(p database.RealtimePrice) error {
+			h.prices.Overlay(&p)
+			return stream.Write(p)
+		})
+		stream.Close(err, "Failed to get realtime prices")
+		return
+	}
+
+	shared, err := h.db.GetRealtimePrices(ctx, limit)
 	if err != nil {
+		// Serve tick-fed prices while the database is unavailable
+		if fallback := h.prices.List(limit); len(fallback) > 0 {
+			log.Printf("⚠️  Serving realtime prices from tick store: %v", err)
+			c.JSON(http.StatusOK, fallback)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get realtime prices"})
 		return
 	}
 
+	// The coalesced result is shared between requests, so overlay a copy
+	prices := append([]database.RealtimePrice(nil), shared...)
+	for i := range prices {
+		h.prices.Overlay(&prices[i])
+	}
+
 	c.JSON(http.StatusOK, prices)
 }
 
 // GetRealtimePrice handles GET /api/stocks/:symbol/realtime
 func (h *Handler) GetRealtimePrice(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	symbol := c.Param("symbol")
@@ -79,16 +116,21 @@ func (h *Handler) GetRealtimePrice(c *gin.Context) {
 
 	price, err := h.db.GetRealtimePrice(ctx, symbol)
 	if err != nil {
+		if fallback, ok := h.prices.Get(symbol); ok {
+			c.JSON(http.StatusOK, fallback)
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Price not found for symbol"})
 		return
 	}
+	h.prices.Overlay(price)
 
 	c.JSON(http.StatusOK, price)
 }
 
 // GetStockData handles GET /api/stocks/:symbol
 func (h *Handler) GetStockData(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	symbol := c.Param("symbol")
@@ -108,7 +150,7 @@ func (h *Handler) GetStockData(c *gin.Context) {
 
 // SearchStocks handles GET /api/stocks/search
 func (h *Handler) SearchStocks(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
 	query := c.Query("q")
@@ -125,3 +167,75 @@ func (h *Handler) SearchStocks(c *gin.Context) {
 
 	c.JSON(http.StatusOK, results)
 }
+
+// maxCandles bounds how many buckets one candles request may span
+const maxCandles = 5000
+
+// GetCandles handles GET /api/stocks/:symbol/candles?interval=5m&from=&to=
+func (h *Handler) GetCandles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	symbol := strings.ToUpper(c.Param("symbol"))
+	interval := c.DefaultQuery("interval", "5m")
+	width, ok := database.CandleInterval(interval)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Unsupported interval",
+			"intervals": database.CandleIntervalNames(),
+		})
+		return
+	}
+
+	// Without an explicit range, return roughly one screen of candles
+	to := time.Now()
+	from := to.Add(-width * 375)
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var err error
+		from, to, err = exports.ParseRange(c.Query("from"), c.Query("to"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if to.Sub(from)/width > maxCandles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Range too large for %s candles (max %d)", interval, maxCandles)})
+		return
+	}
+
+	candles, err := h.db.GetCandles(ctx, symbol, interval, from, to)
+	if err != nil {
+		log.Printf("❌ Failed to get %s candles for %s: %v", interval, symbol, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get candles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":   symbol,
+		"interval": interval,
+		"from":     from.Format(time.RFC3339),
+		"to":       to.Format(time.RFC3339),
+		"candles":  candles,
+		"count":    len(candles),
+	})
+}
+
+// GetVolatility handles GET /api/stocks/:symbol/volatility
+func (h *Handler) GetVolatility(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	symbol := strings.ToUpper(c.Param("symbol"))
+	volatility, err := h.db.GetVolatility(ctx, symbol)
+	if err != nil {
+		log.Printf("❌ Failed to get volatility for %s: %v", symbol, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get volatility"})
+		return
+	}
+	if volatility == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not enough daily history for " + symbol})
+		return
+	}
+
+	c.JSON(http.StatusOK, volatility)
+}
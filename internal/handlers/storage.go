@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/environment"
+	"github.com/trading-chitti/core-api-go/internal/serviceauth"
+	"github.com/trading-chitti/core-api-go/internal/storage"
+)
+
+// maxPresignTTL matches the S3 limit for pre-signed URLs
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// StorageHandler handles object listing, pre-signed URLs, lifecycle status
+// and signed downloads/uploads for the local storage backend
+type StorageHandler struct {
+	store       storage.Store
+	lifecycle   *storage.Lifecycle
+	env         environment.Environment
+	serviceAuth *serviceauth.Authenticator
+}
+
+// NewStorageHandler creates a new storage handler; env and serviceAuth guard
+// uploads to the shared reports and models prefixes
+func NewStorageHandler(store storage.Store, lifecycle *storage.Lifecycle, env environment.Environment, serviceAuth *serviceauth.Authenticator) *StorageHandler {
+	return &StorageHandler{store: store, lifecycle: lifecycle, env: env, serviceAuth: serviceAuth}
+}
+
+// PresignRequest asks for a time-limited URL for one object
+type PresignRequest struct {
+	Key       string `json:"key"`
+	Method    string `json:"method"`
+	ExpiresIn int    `json:"expires_in"` // seconds
+}
+
+// canAccessKey reports whether userID may presign method on key. Exports are
+// private to their owner and only written by the API; reports and model
+// artifacts are shared, and uploads to them are further limited by
+// sharedUpload; retention archives are read-only.
+func canAccessKey(userID, method, key string) bool {
+	if key == "" || strings.Contains(key, "..") {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(key, storage.PrefixExports):
+		return method == http.MethodGet && strings.HasPrefix(key, storage.PrefixExports+userID+"/")
+	case strings.HasPrefix(key, storage.PrefixReports), strings.HasPrefix(key, storage.PrefixModels):
+		return true
+	case strings.HasPrefix(key, storage.PrefixArchive):
+		return method == http.MethodGet
+	}
+	return false
+}
+
+// sharedUpload reports whether a PUT to key writes the shared reports or
+// models prefixes, which every user reads
+func sharedUpload(method, key string) bool {
+	return method == http.MethodPut &&
+		(strings.HasPrefix(key, storage.PrefixReports) || strings.HasPrefix(key, storage.PrefixModels))
+}
+
+// ListObjects handles GET /api/storage/list?prefix=
+func (h *StorageHandler) ListObjects(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	prefix := c.Query("prefix")
+	if prefix == storage.PrefixExports {
+		prefix = storage.PrefixExports + requestUserID(c) + "/"
+	}
+	if !canAccessKey(requestUserID(c), http.MethodGet, prefix) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "prefix must start with " + storage.PrefixReports + ", " + storage.PrefixModels + ", " +
+				storage.PrefixArchive + " or " + storage.PrefixExports,
+		})
+		return
+	}
+
+	objects, err := h.store.List(ctx, prefix)
+	if err != nil {
+		log.Printf("❌ Failed to list objects under %s: %v", prefix, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list objects"})
+		return
+	}
+
+	var total int64
+	for _, obj := range objects {
+		total += obj.Size
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"backend":     h.store.Name(),
+		"prefix":      prefix,
+		"objects":     objects,
+		"count":       len(objects),
+		"total_bytes": total,
+	})
+}
+
+// Presign handles POST /api/storage/presign
+func (h *StorageHandler) Presign(c *gin.Context) {
+	var req PresignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	req.Key = strings.TrimPrefix(strings.TrimSpace(req.Key), "/")
+	req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPut {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be GET or PUT"})
+		return
+	}
+
+	ttl := time.Hour
+	if req.ExpiresIn > 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if ttl > maxPresignTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in cannot exceed 7 days"})
+		return
+	}
+
+	if !canAccessKey(requestUserID(c), req.Method, req.Key) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to " + req.Method + " " + req.Key})
+		return
+	}
+	// Reports and model artifacts are uploaded by the batch jobs with a
+	// service token, or by a prod admin
+	if sharedUpload(req.Method, req.Key) && !allowProdAdminOrService(c, h.env, h.serviceAuth, serviceauth.StorageWrite) {
+		return
+	}
+
+	var url string
+	var err error
+	if req.Method == http.MethodPut {
+		url, err = h.store.PresignPut(req.Key, ttl)
+	} else {
+		url, err = h.store.PresignGet(req.Key, ttl)
+	}
+	if err != nil {
+		log.Printf("❌ Failed to presign %s %s: %v", req.Method, req.Key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pre-signed URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key":        req.Key,
+		"method":     req.Method,
+		"url":        url,
+		"expires_at": time.Now().Add(ttl).Format(time.RFC3339),
+	})
+}
+
+// GetLifecycle handles GET /api/storage/lifecycle
+func (h *StorageHandler) GetLifecycle(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"backend":  h.store.Name(),
+		"rules":    h.lifecycle.Rules(),
+		"interval": h.lifecycle.Interval().String(),
+		"last_run": h.lifecycle.LastRun(),
+	})
+}
+
+// RunLifecycle handles POST /api/storage/lifecycle/run
+func (h *StorageHandler) RunLifecycle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	c.JSON(http.StatusOK, gin.H{"results": h.lifecycle.Sweep(ctx)})
+}
+
+// GetObject handles GET /api/storage/objects/*key
+func (h *StorageHandler) GetObject(c *gin.Context) {
+	local, key, ok := h.verifyLocal(c, http.MethodGet)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Minute)
+	defer cancel()
+
+	obj, err := local.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to open object %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read object"})
+		return
+	}
+	defer obj.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+path.Base(key))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, obj)
+}
+
+// PutObject handles PUT /api/storage/objects/*key
+func (h *StorageHandler) PutObject(c *gin.Context) {
+	local, key, ok := h.verifyLocal(c, http.MethodPut)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Minute)
+	defer cancel()
+
+	if err := local.Put(ctx, key, c.Request.Body, c.Request.ContentLength, c.ContentType()); err != nil {
+		log.Printf("❌ Failed to store object %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store object"})
+		return
+	}
+
+	log.Printf("✅ Stored object %s", key)
+	c.JSON(http.StatusOK, gin.H{"key": key})
+}
+
+// verifyLocal checks the signed query parameters on a local-backend object URL
+func (h *StorageHandler) verifyLocal(c *gin.Context, method string) (*storage.LocalStore, string, bool) {
+	local, ok := h.store.(*storage.LocalStore)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Objects are served directly by the storage backend"})
+		return nil, "", false
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !local.Verify(method, key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired link"})
+		return nil, "", false
+	}
+	return local, key, true
+}
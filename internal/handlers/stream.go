@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamFlushEvery is how many items are encoded between flushes
+const streamFlushEvery = 100
+
+// streamThreshold is the limit above which list endpoints stream instead of
+// buffering; smaller requests keep the coalesced, buffered path
+const streamThreshold = 200
+
+// listEnvelope wraps a streamed array in an object, e.g.
+// {"signals": [...], "count": N}. The zero value streams a bare array.
+type listEnvelope struct {
+	Field    string
+	CountKey string
+	// Extra fields are written after the count
+	Extra gin.H
+}
+
+// listStream writes a list response item by item, as a chunked JSON array or
+// as NDJSON (?format=ndjson or Accept: application/x-ndjson), so large
+// results are never held in memory. Nothing is written until the first item,
+// so a query that fails up front still gets a normal error response.
+type listStream struct {
+	c        *gin.Context
+	ndjson   bool
+	envelope listEnvelope
+	started  bool
+	count    int
+}
+
+func newListStream(c *gin.Context, envelope listEnvelope) *listStream {
+	return &listStream{c: c, ndjson: wantsNDJSON(c), envelope: envelope}
+}
+
+// wantsNDJSON reports whether the client asked for newline-delimited JSON
+func wantsNDJSON(c *gin.Context) bool {
+	return c.Query("format") == "ndjson" || strings.Contains(c.GetHeader("Accept"), "application/x-ndjson")
+}
+
+// shouldStream reports whether a list of up to limit items should stream
+func shouldStream(c *gin.Context, limit int) bool {
+	return wantsNDJSON(c) || limit <= 0 || limit > streamThreshold
+}
+
+func (s *listStream) start() {
+	s.started = true
+	w := s.c.Writer
+	if s.ndjson {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	if s.ndjson {
+		return
+	}
+	if s.envelope.Field != "" {
+		key, _ := json.Marshal(s.envelope.Field)
+		w.Write([]byte("{"))
+		w.Write(key)
+		w.Write([]byte(":"))
+	}
+	w.Write([]byte("["))
+}
+
+// Write encodes one item
+func (s *listStream) Write(item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if !s.started {
+		s.start()
+	}
+	w := s.c.Writer
+	if s.ndjson {
+		data = append(data, '\n')
+	} else if s.count > 0 {
+		w.Write([]byte(","))
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	s.count++
+	if s.count%streamFlushEvery == 0 {
+		w.Flush()
+	}
+	return nil
+}
+
+// Close finishes the response. err is the producer's error: before the
+// first item it becomes a 500 with errMsg; mid-stream NDJSON gets a final
+// {"error": ...} line, and a JSON array is left unterminated so clients
+// fail to parse it rather than trust a partial list.
+func (s *listStream) Close(err error, errMsg string) {
+	if err != nil {
+		log.Printf("❌ %s: %v", errMsg, err)
+		if !s.started {
+			s.c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+			return
+		}
+		if s.ndjson {
+			line, _ := json.Marshal(gin.H{"error": errMsg})
+			s.c.Writer.Write(append(line, '\n'))
+		}
+		s.c.Writer.Flush()
+		return
+	}
+
+	if !s.started {
+		s.start()
+	}
+	if !s.ndjson {
+		s.c.Writer.Write([]byte("]"))
+		if s.envelope.Field != "" {
+			s.writeTrailer()
+			s.c.Writer.Write([]byte("}"))
+		}
+	}
+	s.c.Writer.Flush()
+}
+
+func (s *listStream) writeTrailer() {
+	fields := gin.H{}
+	for k, v := range s.envelope.Extra {
+		fields[k] = v
+	}
+	if s.envelope.CountKey != "" {
+		fields[s.envelope.CountKey] = s.count
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, _ := json.Marshal(k)
+		value, err := json.Marshal(fields[k])
+		if err != nil {
+			continue
+		}
+		s.c.Writer.Write([]byte(","))
+		s.c.Writer.Write(key)
+		s.c.Writer.Write([]byte(":"))
+		s.c.Writer.Write(value)
+	}
+}
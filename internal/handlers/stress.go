@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// StressHandler runs what-if shocks against open positions
+type StressHandler struct {
+	db database.StressRepository
+}
+
+// NewStressHandler creates a new stress test handler
+func NewStressHandler(db database.StressRepository) *StressHandler {
+	return &StressHandler{db: db}
+}
+
+// RunStressTest handles POST /api/quant/stress.
+// Body: index_pct (NIFTY 50 move), sectors (sector to % move), stocks
+// (symbol to % gap) and signal_notional (INR per active signal, default
+// 100000), e.g. {"index_pct": -3, "sectors": {"BANKING": -5}}. Shocks are
+// percentages between -100 and 100.
+func (h *StressHandler) RunStressTest(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	var body struct {
+		database.StressShock
+		SignalNotional float64 `json:"signal_notional"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if body.SignalNotional == 0 {
+		body.SignalNotional = defaultSignalNotional
+	}
+	if body.SignalNotional < 0 || body.SignalNotional > 1e9 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "signal_notional must be a positive amount up to 1000000000"})
+		return
+	}
+
+	shock := body.StressShock
+	if shock.IndexPct == 0 && len(shock.Sectors) == 0 && len(shock.Stocks) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one of index_pct, sectors or stocks is required"})
+		return
+	}
+	valid := func(pct float64) bool { return pct >= -100 && pct <= 100 }
+	ok := valid(shock.IndexPct)
+	for _, v := range shock.Sectors {
+		ok = ok && valid(v)
+	}
+	for _, v := range shock.Stocks {
+		ok = ok && valid(v)
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Shocks must be between -100 and 100 percent"})
+		return
+	}
+
+	accountID := requestAccountID(c)
+	result, err := h.db.RunStressTest(ctx, accountID, body.SignalNotional, shock)
+	if err != nil {
+		log.Printf("❌ Failed to run stress test for %s: %v", accountID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run stress test"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
@@ -8,21 +9,24 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/storage"
 )
 
 // SystemHandler handles system monitoring endpoints
 type SystemHandler struct {
-	db *sql.DB
+	db    *sql.DB
+	store storage.Store
 }
 
 // NewSystemHandler creates a new system handler
-func NewSystemHandler(db *sql.DB) *SystemHandler {
-	return &SystemHandler{db: db}
+func NewSystemHandler(db *sql.DB, store storage.Store) *SystemHandler {
+	return &SystemHandler{db: db, store: store}
 }
 
 // Service represents a system service
@@ -63,6 +67,8 @@ type MLModel struct {
 	Features     int       `json:"features,omitempty"`
 	Description  string    `json:"description"`
 	IsActive     bool      `json:"isActive"`
+	StorageKey   string    `json:"storageKey,omitempty"`
+	DownloadURL  string    `json:"downloadUrl,omitempty"`
 }
 
 // GetServices returns list of all system services
@@ -125,9 +131,9 @@ func (h *SystemHandler) GetServices(c *gin.Context) {
 	})
 }
 
-// GetJobs returns list of all cron jobs
-func (h *SystemHandler) GetJobs(c *gin.Context) {
-	jobs := []CronJob{
+// cronJobs lists the configured cron jobs
+func cronJobs() []CronJob {
+	return []CronJob{
 		{
 			Name:           "log-cleanup",
 			Description:    "Clean up old log files and rotate logs",
@@ -256,12 +262,12 @@ func (h *SystemHandler) GetJobs(c *gin.Context) {
 		},
 		{
 			Name:           "backtest-data-collector",
-			Description:    "Aggregate intraday bars into daily bars (90-day window)",
+			Description:    "Replaced by service-managed bar roll-ups (see /api/system/aggregates)",
 			Schedule:       "0 23 * * *",
 			ScheduleHuman:  "Daily at 11:00 PM",
-			CanRunManually: true,
+			CanRunManually: false,
 			Command:        "/Users/hariprasath/trading-chitti/infra/cron/backtest_data_collector.sh",
-			Status:         "active",
+			Status:         "disabled",
 		},
 		{
 			Name:           "bhavcopy-collector",
@@ -273,6 +279,11 @@ func (h *SystemHandler) GetJobs(c *gin.Context) {
 			Status:         "active",
 		},
 	}
+}
+
+// GetJobs returns list of all cron jobs
+func (h *SystemHandler) GetJobs(c *gin.Context) {
+	jobs := cronJobs()
 
 	// Get last run times from database
 	for i := range jobs {
@@ -307,7 +318,6 @@ func (h *SystemHandler) RunJobManually(c *gin.Context) {
 		"fundamentals-update":     "/Users/hariprasath/trading-chitti/infra/cron/update_fundamentals.sh",
 		"premarket-predictions":   "/Users/hariprasath/trading-chitti/scripts/run_premarket_predictions.sh",
 		"post-mortem":             "/Users/hariprasath/trading-chitti/scripts/run_daily_post_mortem.sh",
-		"backtest-data-collector": "/Users/hariprasath/trading-chitti/infra/cron/backtest_data_collector.sh",
 		"bhavcopy-collector":      "/Users/hariprasath/trading-chitti/infra/cron/bhavcopy_collector.sh",
 	}
 
@@ -316,12 +326,13 @@ func (h *SystemHandler) RunJobManually(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "Job not found",
 			"jobName": jobName,
-			"hint":    "Available jobs: log-cleanup, daily-predictions, morning-selection, ml-retraining, stock-news-collector, enhanced-news-market, rss-feeds-market, market-maintenance, bar-collector-start, wildcard-cleanup, fundamentals-update, premarket-predictions, post-mortem, backtest-data-collector, bhavcopy-collector",
+			"hint":    "Available jobs: log-cleanup, daily-predictions, morning-selection, ml-retraining, stock-news-collector, enhanced-news-market, rss-feeds-market, market-maintenance, bar-collector-start, wildcard-cleanup, fundamentals-update, premarket-predictions, post-mortem, bhavcopy-collector",
 		})
 		return
 	}
 
 	// Run the job in background
+	actor := requestActor(c)
 	go func() {
 		cmd := exec.Command("bash", "-c", command)
 		cmd.Env = append(os.Environ(),
@@ -331,9 +342,9 @@ func (h *SystemHandler) RunJobManually(c *gin.Context) {
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			log.Printf("❌ Manual job run failed for %s: %v\nOutput: %s", jobName, err, output)
+			log.Printf("❌ Manual job run failed for %s (by %s): %v\nOutput: %s", jobName, actor, err, output)
 		} else {
-			log.Printf("✅ Manual job run successful for %s\nOutput: %s", jobName, output)
+			log.Printf("✅ Manual job run successful for %s (by %s)\nOutput: %s", jobName, actor, output)
 		}
 	}()
 
@@ -368,8 +379,7 @@ func (h *SystemHandler) GetMLModels(c *gin.Context) {
 
 			// Check for model files
 			name := file.Name()
-			if strings.Contains(name, ".joblib") || strings.Contains(name, ".pkl") ||
-			   strings.Contains(name, ".pt") || strings.Contains(name, ".pth") {
+			if isModelFile(name) {
 
 				model := MLModel{
 					Name:      extractModelName(name),
@@ -381,11 +391,7 @@ func (h *SystemHandler) GetMLModels(c *gin.Context) {
 				}
 
 				// Determine model type
-				if strings.HasSuffix(name, ".joblib") || strings.HasSuffix(name, ".pkl") {
-					model.Type = "XGBoost/Scikit-learn"
-				} else if strings.HasSuffix(name, ".pt") || strings.HasSuffix(name, ".pth") {
-					model.Type = "PyTorch"
-				}
+				model.Type = modelType(name)
 
 				// Extract metadata if available
 				if strings.Contains(name, "depth") {
@@ -404,6 +410,34 @@ func (h *SystemHandler) GetMLModels(c *gin.Context) {
 		}
 	}
 
+	// Model artifacts published to object storage by the retraining job
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	if objects, err := h.store.List(ctx, storage.PrefixModels); err != nil {
+		log.Printf("❌ Failed to list model artifacts: %v", err)
+	} else {
+		for _, obj := range objects {
+			name := path.Base(obj.Key)
+			if !isModelFile(name) {
+				continue
+			}
+			model := MLModel{
+				Name:       extractModelName(name),
+				Version:    extractVersion(name),
+				Type:       modelType(name),
+				FilePath:   obj.Key,
+				FileSize:   obj.Size,
+				CreatedAt:  obj.LastModified,
+				IsActive:   isActiveModel(name),
+				StorageKey: obj.Key,
+			}
+			if url, err := h.store.PresignGet(obj.Key, time.Hour); err == nil {
+				model.DownloadURL = url
+			}
+			models = append(models, model)
+		}
+	}
+
 	// Get model performance from database
 	for i := range models {
 		accuracy := getModelAccuracy(h.db, models[i].Name)
@@ -488,6 +522,20 @@ func getJobTiming(job *CronJob) (lastRun, nextRun time.Time) {
 	return lastRun, nextRun
 }
 
+func isModelFile(name string) bool {
+	return strings.Contains(name, ".joblib") || strings.Contains(name, ".pkl") ||
+		strings.Contains(name, ".pt") || strings.Contains(name, ".pth")
+}
+
+func modelType(name string) string {
+	if strings.HasSuffix(name, ".joblib") || strings.HasSuffix(name, ".pkl") {
+		return "XGBoost/Scikit-learn"
+	} else if strings.HasSuffix(name, ".pt") || strings.HasSuffix(name, ".pth") {
+		return "PyTorch"
+	}
+	return ""
+}
+
 func extractModelName(filename string) string {
 	name := strings.TrimSuffix(filename, filepath.Ext(filename))
 	// Remove version/date suffix
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/events"
+)
+
+// TickQualityProvider reports duplicate, out-of-order and stale ticks
+type TickQualityProvider interface {
+	TickQuality(limit int) events.TickQualityReport
+}
+
+// TickQualityHandler exposes market.tick data quality
+type TickQualityHandler struct {
+	quality TickQualityProvider
+}
+
+// NewTickQualityHandler creates a new tick quality handler. quality may be
+// nil when NATS is not connected.
+func NewTickQualityHandler(quality TickQualityProvider) *TickQualityHandler {
+	return &TickQualityHandler{quality: quality}
+}
+
+// GetTickQuality handles GET /api/monitoring/tick-quality.
+// Query: limit (symbols listed, default 50, max 500). Windows are keyed 1m,
+// 5m and 15m; symbols are those with the most issues since startup.
+func (h *TickQualityHandler) GetTickQuality(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+	if h.quality == nil {
+		c.JSON(http.StatusOK, gin.H{"nats_connected": false, "timestamp": time.Now().Format(time.RFC3339)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"nats_connected": true,
+		"quality":        h.quality.TickQuality(limit),
+		"timestamp":      time.Now().Format(time.RFC3339),
+	})
+}
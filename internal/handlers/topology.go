@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/breaker"
+	"github.com/trading-chitti/core-api-go/internal/health"
+)
+
+// Node kinds
+const (
+	nodeService  = "service"
+	nodeDatabase = "database"
+	nodeBroker   = "broker"
+	nodeExternal = "external"
+)
+
+// TopologyNode is a component in the dependency graph
+type TopologyNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Port      int    `json:"port,omitempty"`
+	Status    string `json:"status"`
+	LastCheck string `json:"last_check,omitempty"`
+}
+
+// TopologyEdge is a dependency: From uses To over Protocol
+type TopologyEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Protocol string `json:"protocol"`
+}
+
+// topologyEdges are the dependencies between components. Nodes for the
+// health-checked services come from health.Services.
+var topologyEdges = []TopologyEdge{
+	{From: "core-api", To: health.Database, Protocol: "sql"},
+	{From: "core-api", To: "nats", Protocol: "nats"},
+	{From: "core-api", To: "intraday-engine", Protocol: "http"},
+	{From: "core-api", To: "market-bridge", Protocol: "http"},
+	{From: "core-api", To: "news-nlp", Protocol: "http"},
+	{From: "core-api", To: "kite-api", Protocol: "https"},
+	{From: "dashboard", To: "core-api", Protocol: "http"},
+	{From: "market-bridge", To: "kite-api", Protocol: "https"},
+	{From: "market-bridge", To: "nats", Protocol: "nats"},
+	{From: "intraday-engine", To: "nats", Protocol: "nats"},
+	{From: "intraday-engine", To: health.Database, Protocol: "sql"},
+	{From: "news-nlp", To: health.Database, Protocol: "sql"},
+}
+
+// TopologyHandler serves the service dependency graph
+type TopologyHandler struct {
+	health        *health.Checker
+	port          int
+	natsConnected func() bool
+}
+
+// NewTopologyHandler creates a new topology handler. port is core-api's
+// own; natsConnected may be nil when NATS is disabled.
+func NewTopologyHandler(checker *health.Checker, port int, natsConnected func() bool) *TopologyHandler {
+	return &TopologyHandler{health: checker, port: port, natsConnected: natsConnected}
+}
+
+// GetTopology handles GET /api/system/topology, returning every component
+// with its port and latest health, and the dependencies between them
+func (h *TopologyHandler) GetTopology(c *gin.Context) {
+	now := time.Now().Format(time.RFC3339)
+
+	nodes := []TopologyNode{
+		{ID: "core-api", Kind: nodeService, Port: h.port, Status: health.Healthy, LastCheck: now},
+		h.checkedNode(health.Database, nodeDatabase, postgresPort),
+	}
+	natsStatus := health.Unhealthy
+	if h.natsConnected != nil && h.natsConnected() {
+		natsStatus = health.Healthy
+	}
+	nodes = append(nodes, TopologyNode{ID: "nats", Kind: nodeBroker, Port: 4222, Status: natsStatus, LastCheck: now})
+	for _, s := range health.Services {
+		nodes = append(nodes, h.checkedNode(s.Name, nodeService, s.Port))
+	}
+	nodes = append(nodes, kiteNode())
+
+	c.JSON(http.StatusOK, gin.H{
+		"nodes": nodes,
+		"edges": topologyEdges,
+	})
+}
+
+// checkedNode builds a node from its latest background health check
+func (h *TopologyHandler) checkedNode(id, kind string, port int) TopologyNode {
+	node := TopologyNode{ID: id, Kind: kind, Port: port, Status: health.Unknown}
+	if r, ok := h.health.Result(id); ok {
+		node.Status = r.Status
+		if !r.CheckedAt.IsZero() {
+			node.LastCheck = r.CheckedAt.Format(time.RFC3339)
+		}
+	}
+	return node
+}
+
+// kiteNode reports the Kite API's health from its circuit breaker, since it
+// isn't polled
+func kiteNode() TopologyNode {
+	node := TopologyNode{ID: "kite-api", Kind: nodeExternal, Status: health.Unknown}
+	for _, b := range breaker.Snapshot() {
+		if b.Name != kiteBreaker {
+			continue
+		}
+		switch {
+		case b.State == breaker.Open:
+			node.Status = health.Unhealthy
+		case b.State == breaker.HalfOpen:
+			node.Status = health.Degraded
+		case b.Requests > 0:
+			node.Status = health.Healthy
+		}
+	}
+	return node
+}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/authguard"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/environment"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	"github.com/trading-chitti/core-api-go/internal/tradingview"
+)
+
+const (
+	// maxAlertSize caps a webhook body; TradingView messages are short
+	maxAlertSize = 64 << 10
+	// defaultTradingViewConfidence is used for signals whose alert has none
+	defaultTradingViewConfidence = 0.5
+	// maxDeliveriesPage caps one page of the delivery log
+	maxDeliveriesPage = 500
+)
+
+// tradingViewTemplate is a suggested alert message for signal webhooks
+const tradingViewTemplate = `{"secret": "%s", "ticker": "{{exchange}}:{{ticker}}", "action": "{{strategy.order.action}}", ` +
+	`"price": {{close}}, "stop_loss": 0, "target": 0, "id": "{{strategy.order.id}}-{{timenow}}", "time": "{{timenow}}"}`
+
+// TradingViewHandler receives TradingView alert webhooks and manages the
+// webhooks they're sent to
+type TradingViewHandler struct {
+	db        database.TradingViewRepository
+	signals   database.SignalIngestRepository
+	notifier  *notifications.Notifier
+	publisher EventPublisher
+	env       environment.Environment
+}
+
+// NewTradingViewHandler creates a new TradingView handler. publisher may be
+// nil when NATS is not connected; signals are then only stored.
+func NewTradingViewHandler(db database.TradingViewRepository, signals database.SignalIngestRepository, notifier *notifications.Notifier, publisher EventPublisher, env environment.Environment) *TradingViewHandler {
+	return &TradingViewHandler{db: db, signals: signals, notifier: notifier, publisher: publisher, env: env}
+}
+
+// ReceiveAlert handles POST /api/integrations/tradingview.
+//
+// The webhook is found by its secret, sent as ?secret= or the body's
+// "secret" field. Signal webhooks need a JSON body with ticker, action (buy
+// or sell), price, stop_loss and target, and optionally confidence, id and
+// time; alert webhooks take any body and notify the webhook's owner. Every
+// delivery to a known webhook is logged.
+func (h *TradingViewHandler) ReceiveAlert(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxAlertSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Alert body too large"})
+		return
+	}
+	alert, parseErr := tradingview.Parse(body)
+	secret := c.Query("secret")
+	if secret == "" && alert != nil {
+		secret = alert.Secret
+	}
+	if secret == "" {
+		authFailed(c, authguard.KindWebhookSecretInvalid, "tradingview", "secret missing")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "secret is required"})
+		return
+	}
+
+	webhook, err := h.db.GetTradingViewWebhookBySecret(ctx, tradingview.HashSecret(secret))
+	if err != nil {
+		log.Printf("❌ Failed to look up tradingview webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process alert"})
+		return
+	}
+	if webhook == nil {
+		authFailed(c, authguard.KindWebhookSecretInvalid, "tradingview", "unknown secret")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unknown webhook secret"})
+		return
+	}
+
+	delivery := database.TradingViewDelivery{WebhookID: webhook.ID, Payload: tradingview.Redact(body)}
+	code := h.process(ctx, webhook, alert, parseErr, &delivery)
+	if err := h.db.RecordTradingViewDelivery(ctx, delivery); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	if delivery.Error != nil {
+		c.JSON(code, gin.H{"status": delivery.Status, "error": *delivery.Error})
+		return
+	}
+	c.JSON(code, gin.H{"status": delivery.Status, "signal_id": delivery.SignalID})
+}
+
+// process acts on an alert, filling in the delivery's outcome, and returns
+// the response status code
+func (h *TradingViewHandler) process(ctx context.Context, webhook *database.TradingViewWebhook, alert *tradingview.Alert, parseErr error, d *database.TradingViewDelivery) int {
+	reject := func(code int, status, msg string) int {
+		d.Status, d.Error = status, &msg
+		return code
+	}
+	if !webhook.Enabled {
+		return reject(http.StatusForbidden, database.DeliveryRejected, "webhook is disabled")
+	}
+	if parseErr != nil {
+		return reject(http.StatusBadRequest, database.DeliveryRejected, parseErr.Error())
+	}
+	if alert.Action != "" {
+		d.Action = &alert.Action
+	}
+
+	exchange, candidates := tradingview.NormalizeSymbol(alert.Ticker, alert.Exchange)
+	symbol := ""
+	if len(candidates) > 0 {
+		var err error
+		if symbol, err = h.db.ResolveSymbol(ctx, exchange, candidates); err != nil {
+			log.Printf("❌ Failed to resolve tradingview ticker %s: %v", alert.Ticker, err)
+			return reject(http.StatusInternalServerError, database.DeliveryFailed, "failed to resolve symbol")
+		}
+		d.Symbol = &candidates[0]
+		if symbol != "" {
+			d.Symbol = &symbol
+		}
+	}
+
+	if webhook.Mode == database.TradingViewModeAlert {
+		h.notify(webhook, alert, d.Symbol)
+		d.Status = database.DeliveryAccepted
+		return http.StatusOK
+	}
+
+	if alert.Text != "" {
+		return reject(http.StatusBadRequest, database.DeliveryRejected, "signal webhooks need a JSON alert message")
+	}
+	if symbol == "" {
+		if len(candidates) == 0 {
+			return reject(http.StatusBadRequest, database.DeliveryRejected, "ticker is required")
+		}
+		return reject(http.StatusBadRequest, database.DeliveryRejected, "unknown symbol "+candidates[0])
+	}
+
+	signal := database.IngestedSignal{
+		ExternalID:  alert.ID,
+		Symbol:      symbol,
+		SignalType:  alert.Action,
+		Confidence:  float64(alert.Confidence),
+		EntryPrice:  float64(alert.Price),
+		StopLoss:    float64(alert.StopLoss),
+		TargetPrice: float64(alert.Target),
+		GeneratedAt: alert.GeneratedAt(),
+	}
+	if signal.Confidence == 0 {
+		signal.Confidence = defaultTradingViewConfidence
+	}
+	now := time.Now()
+	if signal.GeneratedAt.IsZero() {
+		signal.GeneratedAt = now
+	}
+	if err := signal.Validate(now); err != nil {
+		return reject(http.StatusBadRequest, database.DeliveryRejected, err.Error())
+	}
+
+	provider := fmt.Sprintf("tradingview_%d", webhook.ID)
+	result, err := h.signals.IngestSignals(ctx, provider, []database.IngestedSignal{signal})
+	if err != nil {
+		log.Printf("❌ Failed to store tradingview signal for webhook %d: %v", webhook.ID, err)
+		return reject(http.StatusInternalServerError, database.DeliveryFailed, "failed to store signal")
+	}
+	signalID := database.ExternalSignalID(provider, signal.ExternalID)
+	d.SignalID = &signalID
+	switch {
+	case result.Duplicates > 0:
+		d.Status = database.DeliveryDuplicate
+	case len(result.Signals) == 0:
+		return reject(http.StatusBadRequest, database.DeliveryRejected, "unknown symbol "+symbol)
+	default:
+		d.Status = database.DeliveryAccepted
+		publishNewSignal(h.publisher, &result.Signals[0])
+		log.Printf("✅ TradingView webhook %d created signal %s", webhook.ID, signalID)
+	}
+	return http.StatusOK
+}
+
+// notify sends an alert webhook's alert to its owner
+func (h *TradingViewHandler) notify(webhook *database.TradingViewWebhook, alert *tradingview.Alert, symbol *string) {
+	title := "TradingView: " + webhook.Name
+	data := map[string]string{"webhook_id": strconv.FormatInt(webhook.ID, 10)}
+	if symbol != nil {
+		title = strings.TrimSpace(fmt.Sprintf("TradingView: %s %s", alert.Action, *symbol))
+		data["symbol"] = *symbol
+	}
+	body := alert.Text
+	if body == "" {
+		body = alert.Message
+	}
+	if alert.Price > 0 {
+		body = strings.TrimSpace(fmt.Sprintf("%s (at %.2f)", body, float64(alert.Price)))
+	}
+	h.notifier.Notify(webhook.UserID, notifications.Notification{
+		Kind:  notifications.KindTradingView,
+		Title: title,
+		Body:  body,
+		Data:  data,
+	})
+}
+
+// ListWebhooks handles GET /api/integrations/tradingview/webhooks
+func (h *TradingViewHandler) ListWebhooks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	webhooks, err := h.db.ListTradingViewWebhooks(ctx, requestUserID(c))
+	if err != nil {
+		log.Printf("❌ Failed to list tradingview webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "count": len(webhooks)})
+}
+
+// CreateWebhook handles POST /api/integrations/tradingview/webhooks.
+// Body: name and mode (alert, the default, or signal). Signal webhooks
+// publish signals to everyone, so in prod they need the prod-admin role.
+// The secret is only returned here.
+func (h *TradingViewHandler) CreateWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	var body struct {
+		Name string `json:"name" binding:"required"`
+		Mode string `json:"mode"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return
+	}
+	mode := strings.ToLower(strings.TrimSpace(body.Mode))
+	switch mode {
+	case "":
+		mode = database.TradingViewModeAlert
+	case database.TradingViewModeAlert:
+	case database.TradingViewModeSignal:
+		if !h.env.Allowed(requestRole(c)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Signal webhooks require the " + environment.ProdAdminRole + " role in " + h.env.Name})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be alert or signal"})
+		return
+	}
+
+	secret, err := tradingview.NewSecret()
+	if err != nil {
+		log.Printf("❌ Failed to generate webhook secret: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	webhook, err := h.db.CreateTradingViewWebhook(ctx, requestUserID(c), name, mode, tradingview.HashSecret(secret))
+	if err != nil {
+		log.Printf("❌ Failed to create tradingview webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook":          webhook,
+		"secret":           secret,
+		"url":              "/api/integrations/tradingview",
+		"message_template": fmt.Sprintf(tradingViewTemplate, secret),
+	})
+}
+
+// UpdateWebhook handles PUT /api/integrations/tradingview/webhooks/:id.
+// Body: name and enabled.
+func (h *TradingViewHandler) UpdateWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	var body struct {
+		Name    string `json:"name" binding:"required"`
+		Enabled *bool  `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and enabled are required"})
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return
+	}
+
+	webhook, err := h.db.UpdateTradingViewWebhook(ctx, requestUserID(c), id, name, *body.Enabled)
+	if err != nil {
+		log.Printf("❌ Failed to update tradingview webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/integrations/tradingview/webhooks/:id
+func (h *TradingViewHandler) DeleteWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	deleted, err := h.db.DeleteTradingViewWebhook(ctx, requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to delete tradingview webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted", "id": id})
+}
+
+// ListDeliveries handles GET
+// /api/integrations/tradingview/webhooks/:id/deliveries?limit= (default 50,
+// at most 500), newest first
+func (h *TradingViewHandler) ListDeliveries(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > maxDeliveriesPage {
+		limit = 50
+	}
+
+	webhook, err := h.db.GetTradingViewWebhook(ctx, requestUserID(c), id)
+	if err != nil {
+		log.Printf("❌ Failed to get tradingview webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	deliveries, err := h.db.ListTradingViewDeliveries(ctx, id, limit)
+	if err != nil {
+		log.Printf("❌ Failed to list deliveries for tradingview webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook, "deliveries": deliveries, "count": len(deliveries)})
+}
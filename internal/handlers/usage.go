@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/usage"
+)
+
+// maxUsageDays bounds the range of a usage report
+const maxUsageDays = 366
+
+// UsageMiddleware counts each API call against the request's account and
+// reports the day's calls in an X-Usage-API-Calls header, as used/limit.
+// Metrics at 80% of their soft quota or over it are named in
+// X-Usage-Warning; nothing is refused. An X-Account-ID header is checked as
+// AccountScope would, so calls can't be metered against someone else's
+// account.
+func UsageMiddleware(meter *usage.Meter, accounts *AccountScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(AccountHeader) != "" && !accounts.resolve(c) {
+			return
+		}
+		accountID := requestAccountID(c)
+		used := meter.CountAPICall(accountID)
+		limits := meter.Limits(accountID)
+
+		calls := strconv.FormatInt(used.APICalls, 10)
+		if limits.APICalls > 0 {
+			calls += "/" + strconv.FormatInt(limits.APICalls, 10)
+		}
+		c.Header("X-Usage-API-Calls", calls)
+		if warnings := usage.Warnings(used, limits); len(warnings) > 0 {
+			parts := make([]string, len(warnings))
+			for i, w := range warnings {
+				state := "near quota"
+				if w.Exceeded {
+					state = "over quota"
+				}
+				parts[i] = fmt.Sprintf("%s %s (%d/%d)", w.Metric, state, w.Used, w.Limit)
+			}
+			c.Header("X-Usage-Warning", strings.Join(parts, ", "))
+		}
+		c.Next()
+	}
+}
+
+// UsageHandler serves per-account usage and manages quota overrides
+type UsageHandler struct {
+	db    database.UsageRepository
+	meter *usage.Meter
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(db database.UsageRepository, meter *usage.Meter) *UsageHandler {
+	return &UsageHandler{db: db, meter: meter}
+}
+
+// accountUsageSummary is an account's usage over a report's range, with
+// today's usage measured against its quotas
+type accountUsageSummary struct {
+	AccountID string `json:"account_id"`
+	database.UsageCounts
+	Today    database.UsageCounts `json:"today"`
+	Limits   usage.Limits         `json:"limits"`
+	TodayPct map[string]float64   `json:"today_pct"`
+	Warnings []usage.Warning      `json:"warnings"`
+}
+
+func (h *UsageHandler) summary(accountID string, total database.UsageCounts) accountUsageSummary {
+	today := h.meter.Today(accountID)
+	limits := h.meter.Limits(accountID)
+	pct := make(map[string]float64, len(usage.Metrics))
+	for _, metric := range usage.Metrics {
+		pct[metric] = usage.Pct(usage.Used(today, metric), limits.Get(metric))
+	}
+	warnings := usage.Warnings(today, limits)
+	if warnings == nil {
+		warnings = []usage.Warning{}
+	}
+	return accountUsageSummary{
+		AccountID:   accountID,
+		UsageCounts: total,
+		Today:       today,
+		Limits:      limits,
+		TodayPct:    pct,
+		Warnings:    warnings,
+	}
+}
+
+// flush stores pending counts so reports include them; a failure only
+// leaves the report slightly behind
+func (h *UsageHandler) flush(ctx context.Context) {
+	if err := h.meter.Flush(ctx); err != nil {
+		log.Printf("⚠️  Failed to flush account usage before report: %v", err)
+	}
+}
+
+// GetUsage handles GET /api/admin/usage.
+// Query: from and to (YYYY-MM-DD IST, default today). Each account with
+// usage in the range gets its totals, and today's usage against its quotas,
+// heaviest API users first.
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	now := time.Now().In(ist)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, ist)
+	parse := func(name string) (time.Time, bool) {
+		v := c.Query(name)
+		if v == "" {
+			return today, true
+		}
+		d, err := time.ParseInLocation("2006-01-02", v, ist)
+		return d, err == nil
+	}
+	from, okFrom := parse("from")
+	to, okTo := parse("to")
+	if !okFrom || !okTo {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD"})
+		return
+	}
+	if from.After(to) || to.Sub(from) > maxUsageDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("from must be before to, at most %d days apart", maxUsageDays)})
+		return
+	}
+
+	h.flush(ctx)
+	rows, err := h.db.ListAccountUsage(ctx, "", from, to)
+	if err != nil {
+		log.Printf("❌ Failed to list account usage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve usage"})
+		return
+	}
+
+	totals := map[string]database.UsageCounts{}
+	for _, u := range rows {
+		totals[u.AccountID] = totals[u.AccountID].Add(u.UsageCounts)
+	}
+	accounts := make([]accountUsageSummary, 0, len(totals))
+	var overall database.UsageCounts
+	for accountID, total := range totals {
+		accounts = append(accounts, h.summary(accountID, total))
+		overall = overall.Add(total)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].APICalls != accounts[j].APICalls {
+			return accounts[i].APICalls > accounts[j].APICalls
+		}
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"total":    overall,
+		"accounts": accounts,
+		"count":    len(accounts),
+	})
+}
+
+// GetAccountUsage handles GET /api/admin/usage/:account.
+// Query: days (default 30, max 366). Returns the account's daily usage
+// ending today, oldest first, with today's usage against its quotas.
+func (h *UsageHandler) GetAccountUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > maxUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxUsageDays)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	ist, _ := time.LoadLocation("Asia/Kolkata")
+	to := time.Now().In(ist)
+	from := to.AddDate(0, 0, 1-days)
+	accountID := c.Param("account")
+
+	h.flush(ctx)
+	daily, err := h.db.ListAccountUsage(ctx, accountID, from, to)
+	if err != nil {
+		log.Printf("❌ Failed to list usage of account %s: %v", accountID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve usage"})
+		return
+	}
+	var total database.UsageCounts
+	for _, u := range daily {
+		total = total.Add(u.UsageCounts)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account": h.summary(accountID, total),
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"daily":   daily,
+	})
+}
+
+// ListQuotas handles GET /api/admin/accounts/quotas, the accounts whose
+// quotas differ from the defaults
+func (h *UsageHandler) ListQuotas(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	quotas, err := h.db.ListAccountQuotas(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to list account quotas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve quotas"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas, "count": len(quotas)})
+}
+
+// PutQuota handles PUT /api/admin/accounts/:id/quota.
+// Body: {"api_calls", "ws_messages", "export_bytes"}, each a daily limit,
+// 0 for none, or null to keep the default.
+func (h *UsageHandler) PutQuota(c *gin.Context) {
+	var body struct {
+		APICalls    *int64 `json:"api_calls"`
+		WSMessages  *int64 `json:"ws_messages"`
+		ExportBytes *int64 `json:"export_bytes"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	for _, v := range []*int64{body.APICalls, body.WSMessages, body.ExportBytes} {
+		if v != nil && *v < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quotas must not be negative"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	quota, err := h.db.PutAccountQuota(ctx, database.AccountQuota{
+		AccountID:   id,
+		APICalls:    body.APICalls,
+		WSMessages:  body.WSMessages,
+		ExportBytes: body.ExportBytes,
+		UpdatedBy:   requestActor(c),
+	})
+	if err != nil {
+		log.Printf("❌ Failed to save quota of account %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save quota"})
+		return
+	}
+	if quota == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	h.meter.SetOverride(id, quota)
+	log.Printf("📊 Quota of account %s set by %s", id, requestActor(c))
+	c.JSON(http.StatusOK, gin.H{"quota": quota, "limits": h.meter.Limits(id)})
+}
+
+// DeleteQuota handles DELETE /api/admin/accounts/:id/quota, returning the
+// account to the default quotas
+func (h *UsageHandler) DeleteQuota(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	deleted, err := h.db.DeleteAccountQuota(ctx, id)
+	if err != nil {
+		log.Printf("❌ Failed to delete quota of account %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quota"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account has no quota override"})
+		return
+	}
+	h.meter.SetOverride(id, nil)
+	log.Printf("📊 Quota of account %s reset by %s", id, requestActor(c))
+	c.JSON(http.StatusOK, gin.H{"account_id": id, "limits": h.meter.Limits(id)})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/buildinfo"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// VersionHandler reports what's deployed
+type VersionHandler struct {
+	db       *database.DB
+	features map[string]bool
+}
+
+// NewVersionHandler creates a new version handler. features are the
+// feature flags as resolved at startup.
+func NewVersionHandler(db *database.DB, features map[string]bool) *VersionHandler {
+	return &VersionHandler{db: db, features: features}
+}
+
+// GetVersion handles GET /api/system/version, returning the build, the
+// enabled feature flags and the schema version the build expects alongside
+// the one applied to the database
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	schema := gin.H{"expected": database.SchemaVersion()}
+	applied, err := h.db.AppliedSchemaVersion(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to read schema version: %v", err)
+		schema["applied"] = nil
+	} else {
+		schema["applied"] = applied
+		schema["up_to_date"] = applied >= database.SchemaVersion()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"build":    buildinfo.Get(),
+		"features": h.features,
+		"schema":   schema,
+	})
+}
@@ -1,53 +1,80 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"net/http"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Thread-safe in-memory watchlist
-var (
-	watchlistStore = map[string]bool{}
-	watchlistMu    sync.RWMutex
-)
+// defaultWatchlist is used when no watchlist is named
+const defaultWatchlist = "default"
+
+// watchlistName normalizes a requested watchlist name
+func watchlistName(name string) string {
+	if name = strings.TrimSpace(name); name == "" {
+		return defaultWatchlist
+	}
+	return name
+}
 
-// GetWatchlist handles GET /api/watchlist
+// GetWatchlist handles GET /api/watchlist. ?watchlist= picks one of the
+// account's named watchlists.
 func (h *Handler) GetWatchlist(c *gin.Context) {
-	watchlistMu.RLock()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.db.ListWatchlist(ctx, requestAccountID(c), watchlistName(c.Query("watchlist")))
+	if err != nil {
+		log.Printf("❌ Failed to get watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve watchlist"})
+		return
+	}
+
 	watchlist := []map[string]interface{}{}
-	for symbol := range watchlistStore {
+	for _, item := range items {
 		watchlist = append(watchlist, map[string]interface{}{
-			"symbol":        symbol,
-			"name":          symbol,
+			"symbol":        item.Symbol,
+			"name":          item.Symbol,
 			"price":         0,
 			"change":        0,
 			"changePercent": 0,
+			"added_by":      item.AddedBy,
+			"added_at":      item.AddedAt,
 		})
 	}
-	watchlistMu.RUnlock()
 	c.JSON(http.StatusOK, watchlist)
 }
 
-// AddToWatchlist handles POST /api/watchlist
+// AddToWatchlist handles POST /api/watchlist. Body: symbol and an optional
+// watchlist name.
 func (h *Handler) AddToWatchlist(c *gin.Context) {
 	var body struct {
-		Symbol string `json:"symbol"`
+		Symbol    string `json:"symbol"`
+		Watchlist string `json:"watchlist"`
 	}
 	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil || body.Symbol == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Symbol is required"})
 		return
 	}
 
-	watchlistMu.Lock()
-	watchlistStore[body.Symbol] = true
-	watchlistMu.Unlock()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.db.AddToWatchlist(ctx, requestAccountID(c), watchlistName(body.Watchlist), requestUserID(c), []string{body.Symbol}); err != nil {
+		log.Printf("❌ Failed to add %s to watchlist: %v", body.Symbol, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Added to watchlist", "symbol": body.Symbol})
 }
 
-// RemoveFromWatchlist handles DELETE /api/watchlist/:symbol
+// RemoveFromWatchlist handles DELETE /api/watchlist/:symbol. ?watchlist=
+// picks a named watchlist.
 func (h *Handler) RemoveFromWatchlist(c *gin.Context) {
 	symbol := c.Param("symbol")
 	if symbol == "" {
@@ -55,8 +82,13 @@ func (h *Handler) RemoveFromWatchlist(c *gin.Context) {
 		return
 	}
 
-	watchlistMu.Lock()
-	delete(watchlistStore, symbol)
-	watchlistMu.Unlock()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.db.RemoveFromWatchlist(ctx, requestAccountID(c), watchlistName(c.Query("watchlist")), symbol); err != nil {
+		log.Printf("❌ Failed to remove %s from watchlist: %v", symbol, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Removed from watchlist", "symbol": symbol})
 }
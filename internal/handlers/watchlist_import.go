@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportWatchlistFromBroker handles POST /api/watchlist/import-from-broker
+//
+// Adds the symbols of the authenticated Zerodha account's holdings and open
+// positions to a watchlist. Body (optional): watchlist name and include,
+// one of "holdings", "positions" or "all" (default). The broker login is
+// the operator's, so only the default account can import from it.
+func (h *Handler) ImportWatchlistFromBroker(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	accountID := requestAccountID(c)
+	if accountID != defaultUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Broker holdings can only be imported into the default account"})
+		return
+	}
+
+	var body struct {
+		Watchlist string `json:"watchlist"`
+		Include   string `json:"include"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+	include := strings.ToLower(body.Include)
+	if include == "" {
+		include = "all"
+	}
+	if include != "all" && include != "holdings" && include != "positions" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "include must be holdings, positions or all"})
+		return
+	}
+
+	config, err := h.db.GetBrokerConfig(ctx, "zerodha")
+	if err != nil || config == nil || config.AccessToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Zerodha is not authenticated"})
+		return
+	}
+
+	found := map[string]bool{}
+	if include != "positions" {
+		symbols, err := fetchKiteHoldingSymbols(ctx, config.APIKey, config.AccessToken)
+		if err != nil {
+			log.Printf("❌ Failed to fetch Kite holdings: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Kite API error: %v", err)})
+			return
+		}
+		for _, s := range symbols {
+			found[s] = true
+		}
+	}
+	if include != "holdings" {
+		symbols, err := fetchKitePositionSymbols(ctx, config.APIKey, config.AccessToken)
+		if err != nil {
+			log.Printf("❌ Failed to fetch Kite positions: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Kite API error: %v", err)})
+			return
+		}
+		for _, s := range symbols {
+			found[s] = true
+		}
+	}
+
+	symbols := make([]string, 0, len(found))
+	for s := range found {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	name := watchlistName(body.Watchlist)
+	added, err := h.db.AddToWatchlist(ctx, accountID, name, requestUserID(c), symbols)
+	if err != nil {
+		log.Printf("❌ Failed to import broker symbols into watchlist %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+		return
+	}
+	log.Printf("✅ Imported %d broker symbols into watchlist %s (%d new)", len(symbols), name, len(added))
+
+	c.JSON(http.StatusOK, gin.H{
+		"watchlist": name,
+		"symbols":   symbols,
+		"added":     added,
+		"existing":  len(symbols) - len(added),
+	})
+}
+
+// kiteGet calls a Kite API endpoint and decodes its data field into out
+func kiteGet(ctx context.Context, apiKey, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.kite.trade"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Kite-Version", "3")
+	req.Header.Set("Authorization", fmt.Sprintf("token %s:%s", apiKey, accessToken))
+
+	resp, err := kiteClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var kiteResp struct {
+		Status  string          `json:"status"`
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&kiteResp); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if kiteResp.Status != "success" {
+		return fmt.Errorf("%s", kiteResp.Message)
+	}
+	if err := json.Unmarshal(kiteResp.Data, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// fetchKiteHoldingSymbols returns the equity symbols held in the demat
+// account, including shares bought but not yet delivered
+func fetchKiteHoldingSymbols(ctx context.Context, apiKey, accessToken string) ([]string, error) {
+	var holdings []struct {
+		TradingSymbol string  `json:"tradingsymbol"`
+		Exchange      string  `json:"exchange"`
+		Quantity      float64 `json:"quantity"`
+		T1Quantity    float64 `json:"t1_quantity"`
+	}
+	if err := kiteGet(ctx, apiKey, accessToken, "/portfolio/holdings", &holdings); err != nil {
+		return nil, err
+	}
+
+	symbols := []string{}
+	for _, h := range holdings {
+		if (h.Exchange == "NSE" || h.Exchange == "BSE") && h.Quantity+h.T1Quantity > 0 {
+			symbols = append(symbols, strings.ToUpper(h.TradingSymbol))
+		}
+	}
+	return symbols, nil
+}
+
+// fetchKitePositionSymbols returns the equity symbols with an open net
+// position
+func fetchKitePositionSymbols(ctx context.Context, apiKey, accessToken string) ([]string, error) {
+	var positions struct {
+		Net []struct {
+			TradingSymbol string  `json:"tradingsymbol"`
+			Exchange      string  `json:"exchange"`
+			Quantity      float64 `json:"quantity"`
+		} `json:"net"`
+	}
+	if err := kiteGet(ctx, apiKey, accessToken, "/portfolio/positions", &positions); err != nil {
+		return nil, err
+	}
+
+	symbols := []string{}
+	for _, p := range positions.Net {
+		if (p.Exchange == "NSE" || p.Exchange == "BSE") && p.Quantity != 0 {
+			symbols = append(symbols, strings.ToUpper(p.TradingSymbol))
+		}
+	}
+	return symbols, nil
+}
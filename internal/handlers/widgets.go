@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/trading-chitti/core-api-go/internal/coalesce"
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/movers"
+	"github.com/trading-chitti/core-api-go/internal/regime"
+)
+
+const (
+	// defaultWidgetTTL is how long a rendered widget is served before it's
+	// rebuilt, until SetTTL changes it
+	defaultWidgetTTL = time.Minute
+	// widgetCacheControl lets browsers and CDNs in front of embedding sites
+	// keep widgets for five minutes and serve stale ones while refetching
+	widgetCacheControl = "public, max-age=300, stale-while-revalidate=600"
+	// maxWidgetMovers caps the top-movers widget's list length
+	maxWidgetMovers = 10
+)
+
+// widgetEntry is a rendered widget body
+type widgetEntry struct {
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// WidgetsHandler serves small, heavily cached JSON documents for embedding
+// in external sites. Widgets have their own stable shapes so the internal
+// endpoints can change freely.
+type WidgetsHandler struct {
+	db     database.MarketRepository
+	movers *movers.Cache
+	regime *regime.Tracker
+
+	flight coalesce.Group
+	mu     sync.Mutex
+	cache  map[string]widgetEntry
+	ttl    atomic.Int64
+}
+
+// NewWidgetsHandler creates a new widgets handler. regimeTracker may be nil.
+func NewWidgetsHandler(db database.MarketRepository, moversCache *movers.Cache, regimeTracker *regime.Tracker) *WidgetsHandler {
+	h := &WidgetsHandler{db: db, movers: moversCache, regime: regimeTracker, cache: map[string]widgetEntry{}}
+	h.ttl.Store(int64(defaultWidgetTTL))
+	return h
+}
+
+// SetTTL changes how long widgets built from now on are served
+func (h *WidgetsHandler) SetTTL(ttl time.Duration) {
+	h.ttl.Store(int64(ttl))
+}
+
+// widgetMover is one entry of the top-movers widget
+type widgetMover struct {
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// widgetIndex is one entry of the market-summary widget
+type widgetIndex struct {
+	Name      string  `json:"name"`
+	Value     float64 `json:"value"`
+	ChangePct float64 `json:"change_pct"`
+}
+
+// GetTopMovers handles GET /api/widgets/top-movers?limit= (default 5, at
+// most 10)
+func (h *WidgetsHandler) GetTopMovers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit <= 0 || limit > maxWidgetMovers {
+		limit = 5
+	}
+
+	h.serve(c, fmt.Sprintf("top-movers|%d", limit), func(ctx context.Context) (interface{}, error) {
+		gainers, ok := h.movers.Gainers(limit)
+		losers, _ := h.movers.Losers(limit)
+		if !ok {
+			var err error
+			if gainers, err = h.db.GetTopGainers(ctx, limit); err != nil {
+				return nil, err
+			}
+			if losers, err = h.db.GetTopLosers(ctx, limit); err != nil {
+				return nil, err
+			}
+		}
+		return gin.H{
+			"gainers": widgetMovers(gainers),
+			"losers":  widgetMovers(losers),
+			"as_of":   time.Now().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+func widgetMovers(list []database.TopMover) []widgetMover {
+	out := make([]widgetMover, 0, len(list))
+	for _, m := range list {
+		out = append(out, widgetMover{Symbol: m.Symbol, Name: m.Name, Price: m.Price, ChangePct: m.Change})
+	}
+	return out
+}
+
+// GetMarketSummary handles GET /api/widgets/market-summary: index levels,
+// whether the session is open and, when known, the market regime
+func (h *WidgetsHandler) GetMarketSummary(c *gin.Context) {
+	h.serve(c, "market-summary", func(ctx context.Context) (interface{}, error) {
+		indices, err := h.db.GetMarketIndices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]widgetIndex, 0, len(indices))
+		for _, idx := range indices {
+			out = append(out, widgetIndex{Name: idx.Index, Value: idx.Value, ChangePct: idx.ChangePercent})
+		}
+
+		now := time.Now()
+		open := database.SessionOpen(now)
+		session := "closed"
+		if wd := open.Weekday(); wd != time.Saturday && wd != time.Sunday &&
+			!now.Before(open) && now.Before(open.Add(database.SessionMinutes*time.Minute)) {
+			session = "open"
+		}
+		summary := gin.H{"indices": out, "session": session, "as_of": now.Format(time.RFC3339)}
+
+		// The regime is a nice-to-have; the widget still renders without it
+		if h.regime != nil {
+			if reading, err := h.regime.Current(ctx); err == nil {
+				summary["regime"] = reading.Regime
+			} else {
+				log.Printf("⚠️  Market summary widget without regime: %v", err)
+			}
+		}
+		return summary, nil
+	})
+}
+
+// serve writes a cached widget, rebuilding it with build once it's older
+// than the TTL. Concurrent rebuilds of the same widget share one build,
+// and a failed rebuild falls back to the previous body.
+func (h *WidgetsHandler) serve(c *gin.Context, key string, build func(ctx context.Context) (interface{}, error)) {
+	entry, err := h.entry(c.Request.Context(), key, build)
+	if err != nil {
+		log.Printf("❌ Failed to build %s widget: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Widget unavailable"})
+		return
+	}
+
+	c.Header("Cache-Control", widgetCacheControl)
+	c.Header("ETag", entry.etag)
+	if c.GetHeader("If-None-Match") == entry.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", entry.body)
+}
+
+func (h *WidgetsHandler) entry(ctx context.Context, key string, build func(ctx context.Context) (interface{}, error)) (widgetEntry, error) {
+	h.mu.Lock()
+	entry, ok := h.cache[key]
+	h.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry, nil
+	}
+
+	fresh, err := coalesce.Do(&h.flight, key, func() (widgetEntry, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		data, err := build(ctx)
+		if err != nil {
+			return widgetEntry{}, err
+		}
+		body, err := json.Marshal(data)
+		if err != nil {
+			return widgetEntry{}, err
+		}
+		sum := sha256.Sum256(body)
+		entry := widgetEntry{
+			body:    body,
+			etag:    `"` + hex.EncodeToString(sum[:8]) + `"`,
+			expires: time.Now().Add(time.Duration(h.ttl.Load())),
+		}
+		h.mu.Lock()
+		h.cache[key] = entry
+		h.mu.Unlock()
+		return entry, nil
+	})
+	// An embedded widget showing slightly old data beats a broken one
+	if err != nil && ok {
+		log.Printf("⚠️  Serving stale %s widget: %v", key, err)
+		return entry, nil
+	}
+	return fresh, err
+}
@@ -0,0 +1,220 @@
+// Package health checks the platform's services in the background and keeps
+// the latest result for each, so the monitor endpoints answer from memory
+// instead of probing services on every request.
+//
+// Every service is checked concurrently each checkInterval, through its own
+// circuit breaker, so a sick service is probed at most once per interval and
+// not at all while its breaker is open.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/breaker"
+)
+
+// Statuses
+const (
+	Healthy   = "healthy"
+	Degraded  = "degraded"
+	Unhealthy = "unhealthy"
+	// Unknown is reported until a service's first check completes
+	Unknown = "unknown"
+)
+
+// Database is the name the database ping is reported under
+const Database = "postgres"
+
+// checkInterval is how often services are checked
+const checkInterval = 15 * time.Second
+
+// Service is a service checked over HTTP
+type Service struct {
+	Name    string
+	Port    int
+	URL     string
+	breaker *breaker.Breaker
+}
+
+// Services are the HTTP-checked services. NATS has no HTTP endpoint and
+// isn't checked here.
+var Services = []Service{
+	newService("intraday-engine", 6007, "/health", 2*time.Second),
+	newService("market-bridge", 6005, "/health", 2*time.Second),
+	newService("news-nlp", 6006, "/health", 2*time.Second),
+	newService("dashboard", 6003, "", 2*time.Second),
+}
+
+func newService(name string, port int, path string, timeout time.Duration) Service {
+	return Service{
+		Name:    name,
+		Port:    port,
+		URL:     fmt.Sprintf("http://localhost:%d%s", port, path),
+		breaker: breaker.New("health:"+name, timeout),
+	}
+}
+
+// ServiceURL returns the health check URL of a service, or "" if the
+// service isn't known
+func ServiceURL(name string) string {
+	for _, s := range Services {
+		if s.Name == name {
+			return s.URL
+		}
+	}
+	return ""
+}
+
+// Result is the outcome of a service's latest check
+type Result struct {
+	Name           string
+	Status         string
+	ResponseTimeMs float64
+	Error          string
+	// CheckedAt is zero until the first check completes
+	CheckedAt time.Time
+}
+
+// Checker checks services and caches the results
+type Checker struct {
+	ping func(ctx context.Context) error
+
+	mu      sync.RWMutex
+	results map[string]Result
+}
+
+// NewChecker creates a checker for the database, via ping, and Services
+func NewChecker(ping func(ctx context.Context) error) *Checker {
+	results := map[string]Result{Database: {Name: Database, Status: Unknown}}
+	for _, s := range Services {
+		results[s.Name] = Result{Name: s.Name, Status: Unknown}
+	}
+	return &Checker{ping: ping, results: results}
+}
+
+// Run checks everything now and then every checkInterval until ctx is
+// cancelled
+func (c *Checker) Run(ctx context.Context) {
+	c.checkAll(ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkAll(ctx)
+		}
+	}
+}
+
+// Results returns the latest results, the database first and then
+// Services in order
+func (c *Checker) Results() []Result {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	results := []Result{c.results[Database]}
+	for _, s := range Services {
+		results = append(results, c.results[s.Name])
+	}
+	return results
+}
+
+// Result returns the latest result for a service or the database
+func (c *Checker) Result(name string) (Result, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.results[name]
+	return r, ok
+}
+
+// checkAll runs every check concurrently
+func (c *Checker) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.store(c.checkDatabase(ctx))
+	}()
+	for _, s := range Services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.store(checkHTTP(ctx, s))
+		}()
+	}
+	wg.Wait()
+}
+
+// store saves a result; interrupted checks return a zero Result, which
+// leaves the previous one in place
+func (c *Checker) store(r Result) {
+	if r.Status == "" {
+		return
+	}
+	c.mu.Lock()
+	c.results[r.Name] = r
+	c.mu.Unlock()
+}
+
+func (c *Checker) checkDatabase(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	r := Result{Name: Database, Status: Healthy}
+	if err := c.ping(ctx); err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// Shutting down; keep the last result
+			return Result{}
+		}
+		r.Status = Unhealthy
+		r.Error = err.Error()
+	}
+	r.ResponseTimeMs = float64(time.Since(start).Milliseconds())
+	r.CheckedAt = time.Now()
+	return r
+}
+
+// checkHTTP treats a 2xx response as healthy, 503 as degraded and anything
+// else, including an open breaker, as unhealthy
+func checkHTTP(ctx context.Context, s Service) Result {
+	start := time.Now()
+	r := Result{Name: s.Name, Status: Unhealthy}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.URL, nil)
+	if err != nil {
+		r.Error = err.Error()
+		r.CheckedAt = time.Now()
+		return r
+	}
+	resp, err := s.breaker.Client().Do(req)
+	r.ResponseTimeMs = float64(time.Since(start).Milliseconds())
+	r.CheckedAt = time.Now()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// Shutting down; keep the last result
+			return Result{}
+		}
+		r.Error = err.Error()
+		return r
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		r.Status = Healthy
+	case resp.StatusCode == http.StatusServiceUnavailable:
+		r.Status = Degraded
+	default:
+		r.Error = resp.Status
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+// Package inbound maps JSON webhooks from arbitrary sources onto internal
+// events using per-endpoint configuration instead of per-source code.
+//
+// An endpoint's config names, for each field of its target (signal, alert
+// or news), a JSONPath into the payload, a constant, or both with the
+// constant as the fallback. A rule's map rewrites matched values, e.g.
+// {"long": "BUY"}. When items is set, it selects an array in the payload
+// and every element becomes one record, with field paths relative to it.
+package inbound
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Targets an endpoint's records are turned into
+const (
+	TargetSignal = "signal"
+	TargetAlert  = "alert"
+	TargetNews   = "news"
+)
+
+// MaxRecords caps the records taken from one payload
+const MaxRecords = 500
+
+// field is a target field and whether a config must map it
+type field struct {
+	name     string
+	required bool
+}
+
+// targetFields are the fields each target understands
+var targetFields = map[string][]field{
+	TargetSignal: {
+		{"symbol", true}, {"signal_type", true}, {"entry_price", true}, {"stop_loss", true},
+		{"target_price", true}, {"confidence", false}, {"external_id", false}, {"generated_at", false},
+		{"time_in_force", false},
+	},
+	TargetAlert: {
+		{"title", true}, {"body", false}, {"symbol", false},
+	},
+	TargetNews: {
+		{"title", true}, {"url", true}, {"summary", false}, {"published_at", false}, {"symbols", false},
+	},
+}
+
+// Fields returns the field names of a target, required fields first, or
+// nil for an unknown target
+func Fields(target string) []string {
+	var required, optional []string
+	for _, f := range targetFields[target] {
+		if f.required {
+			required = append(required, f.name)
+		} else {
+			optional = append(optional, f.name)
+		}
+	}
+	return append(required, optional...)
+}
+
+// Rule says where a field's value comes from
+type Rule struct {
+	// Path is a JSONPath into the payload, or the item when items is set
+	Path string `json:"path,omitempty"`
+	// Value is a constant, used when Path is empty or selects nothing
+	Value string `json:"value,omitempty"`
+	// Map rewrites values, matching keys case-insensitively; values it
+	// doesn't list pass through
+	Map map[string]string `json:"map,omitempty"`
+}
+
+// Config is an endpoint's transformation
+type Config struct {
+	// Items is a JSONPath to the payload's records; empty means the payload
+	// is a single record
+	Items  string          `json:"items,omitempty"`
+	Fields map[string]Rule `json:"fields"`
+}
+
+// Mapping is a validated Config for one target
+type Mapping struct {
+	target string
+	items  *Path
+	rules  map[string]compiledRule
+}
+
+type compiledRule struct {
+	path  *Path
+	value string
+	remap map[string]string
+}
+
+// Compile validates cfg against target: every field must be one the target
+// understands, required fields must be mapped and paths must parse
+func Compile(target string, cfg Config) (*Mapping, error) {
+	fields, ok := targetFields[target]
+	if !ok {
+		return nil, fmt.Errorf("target must be %s, %s or %s", TargetSignal, TargetAlert, TargetNews)
+	}
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.name] = true
+		if r, ok := cfg.Fields[f.name]; f.required && (!ok || (r.Path == "" && r.Value == "")) {
+			return nil, fmt.Errorf("field %s is required for %s endpoints", f.name, target)
+		}
+	}
+
+	m := &Mapping{target: target, rules: map[string]compiledRule{}}
+	if cfg.Items != "" {
+		p, err := CompilePath(cfg.Items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %v", err)
+		}
+		m.items = &p
+	}
+	for name, r := range cfg.Fields {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown %s field %q", target, name)
+		}
+		cr := compiledRule{value: r.Value}
+		if r.Path != "" {
+			p, err := CompilePath(r.Path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			cr.path = &p
+		}
+		if len(r.Map) > 0 {
+			cr.remap = make(map[string]string, len(r.Map))
+			for from, to := range r.Map {
+				cr.remap[strings.ToLower(from)] = to
+			}
+		}
+		m.rules[name] = cr
+	}
+	return m, nil
+}
+
+// Target returns the target the mapping was compiled for
+func (m *Mapping) Target() string {
+	return m.target
+}
+
+// Record is one mapped record: each field's values as strings. Fields that
+// selected nothing are absent.
+type Record map[string][]string
+
+// Get returns a field's first value, or ""
+func (r Record) Get(name string) string {
+	if v := r[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// ErrNoRecords is returned when a payload yields no records
+var ErrNoRecords = errors.New("payload has no records")
+
+// Apply decodes a JSON payload and maps it into records
+func (m *Mapping) Apply(payload []byte) ([]Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %v", err)
+	}
+
+	items := []interface{}{doc}
+	if m.items != nil {
+		items = nil
+		for _, v := range m.items.Eval(doc) {
+			// An items path selecting an array takes its elements
+			if arr, ok := v.([]interface{}); ok {
+				items = append(items, arr...)
+			} else {
+				items = append(items, v)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, ErrNoRecords
+	}
+	if len(items) > MaxRecords {
+		return nil, fmt.Errorf("payload has %d records, at most %d are accepted", len(items), MaxRecords)
+	}
+
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		rec := Record{}
+		for name, r := range m.rules {
+			var values []string
+			if r.path != nil {
+				for _, v := range r.path.Eval(item) {
+					values = appendScalars(values, v)
+				}
+			}
+			if len(values) == 0 && r.value != "" {
+				values = []string{r.value}
+			}
+			for i, v := range values {
+				if to, ok := r.remap[strings.ToLower(v)]; ok {
+					values[i] = to
+				}
+			}
+			if len(values) > 0 {
+				rec[name] = values
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// appendScalars appends v as strings: scalars as themselves and arrays by
+// their scalar elements. Nulls and objects are skipped.
+func appendScalars(out []string, v interface{}) []string {
+	switch x := v.(type) {
+	case string:
+		if s := strings.TrimSpace(x); s != "" {
+			out = append(out, s)
+		}
+	case json.Number:
+		out = append(out, x.String())
+	case bool:
+		if x {
+			out = append(out, "true")
+		} else {
+			out = append(out, "false")
+		}
+	case []interface{}:
+		for _, e := range x {
+			if _, nested := e.([]interface{}); !nested {
+				out = appendScalars(out, e)
+			}
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
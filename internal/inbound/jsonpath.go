@@ -0,0 +1,123 @@
+package inbound
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression. The supported subset is the root
+// $, child access by .name or ['name'], array indexes [n] (negative counts
+// from the end) and the wildcards .* and [*].
+type Path struct {
+	expr  string
+	steps []step
+}
+
+type stepKind int
+
+const (
+	stepKey stepKind = iota
+	stepIndex
+	stepWildcard
+)
+
+type step struct {
+	kind  stepKind
+	key   string
+	index int
+}
+
+// CompilePath parses a JSONPath expression
+func CompilePath(expr string) (Path, error) {
+	p := Path{expr: expr}
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return p, fmt.Errorf("path %q must start with $", expr)
+	}
+	s = s[1:]
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".*"):
+			p.steps = append(p.steps, step{kind: stepWildcard})
+			s = s[2:]
+		case s[0] == '.':
+			end := strings.IndexAny(s[1:], ".[")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			name := s[1 : end+1]
+			if name == "" {
+				return p, fmt.Errorf("path %q has an empty name", expr)
+			}
+			p.steps = append(p.steps, step{kind: stepKey, key: name})
+			s = s[end+1:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return p, fmt.Errorf("path %q has an unclosed [", expr)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			switch {
+			case inner == "*":
+				p.steps = append(p.steps, step{kind: stepWildcard})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.steps = append(p.steps, step{kind: stepKey, key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return p, fmt.Errorf("path %q has an invalid index [%s]", expr, inner)
+				}
+				p.steps = append(p.steps, step{kind: stepIndex, index: n})
+			}
+			s = s[end+1:]
+		default:
+			return p, fmt.Errorf("path %q is invalid at %q", expr, s)
+		}
+	}
+	return p, nil
+}
+
+// String returns the expression the path was compiled from
+func (p Path) String() string {
+	return p.expr
+}
+
+// Eval returns the values the path selects in a document decoded by
+// encoding/json. Wildcards take arrays in order and objects by sorted key.
+func (p Path) Eval(doc interface{}) []interface{} {
+	current := []interface{}{doc}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, v := range current {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				switch st.kind {
+				case stepKey:
+					if child, ok := node[st.key]; ok {
+						next = append(next, child)
+					}
+				case stepWildcard:
+					for _, k := range sortedKeys(node) {
+						next = append(next, node[k])
+					}
+				}
+			case []interface{}:
+				switch st.kind {
+				case stepIndex:
+					i := st.index
+					if i < 0 {
+						i += len(node)
+					}
+					if i >= 0 && i < len(node) {
+						next = append(next, node[i])
+					}
+				case stepWildcard:
+					next = append(next, node...)
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,303 @@
+// Package loadtest publishes synthetic market.tick and signal.* events to
+// NATS at configurable rates, so WebSocket fan-out and hub backpressure can
+// be exercised before market hours. It is dev-only and gated by
+// LOADTEST_ENABLED.
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/events"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+// step is how often the generator wakes up to publish a batch
+const step = 100 * time.Millisecond
+
+// Limits keep a misconfigured run from saturating NATS
+const (
+	MaxTicksPerSecond   = 20000
+	MaxSignalsPerMinute = 6000
+	MaxSymbols          = 2000
+	MaxDuration         = time.Hour
+)
+
+// signalIDBase keeps synthetic signal IDs clear of real ones
+const signalIDBase = 9000000
+
+// ErrRunning is returned when a run is already in progress
+var ErrRunning = errors.New("load test already running")
+
+// Publisher sends a raw event on a NATS subject
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Config describes one run
+type Config struct {
+	TicksPerSecond   float64 `json:"ticks_per_second"`
+	SignalsPerMinute float64 `json:"signals_per_minute"`
+	Symbols          int     `json:"symbols"`
+	DurationSeconds  int     `json:"duration_seconds"`
+}
+
+// Validate applies defaults and limits
+func (c *Config) Validate() error {
+	if c.TicksPerSecond == 0 && c.SignalsPerMinute == 0 {
+		c.TicksPerSecond, c.SignalsPerMinute = 500, 60
+	}
+	if c.Symbols == 0 {
+		c.Symbols = 50
+	}
+	if c.DurationSeconds == 0 {
+		c.DurationSeconds = 300
+	}
+	switch {
+	case c.TicksPerSecond < 0 || c.TicksPerSecond > MaxTicksPerSecond:
+		return fmt.Errorf("ticks_per_second must be between 0 and %d", MaxTicksPerSecond)
+	case c.SignalsPerMinute < 0 || c.SignalsPerMinute > MaxSignalsPerMinute:
+		return fmt.Errorf("signals_per_minute must be between 0 and %d", MaxSignalsPerMinute)
+	case c.Symbols < 1 || c.Symbols > MaxSymbols:
+		return fmt.Errorf("symbols must be between 1 and %d", MaxSymbols)
+	case c.DurationSeconds < 1 || time.Duration(c.DurationSeconds)*time.Second > MaxDuration:
+		return fmt.Errorf("duration_seconds must be between 1 and %d", int(MaxDuration.Seconds()))
+	}
+	return nil
+}
+
+// Status reports the current or last run
+type Status struct {
+	Enabled   bool              `json:"enabled"`
+	Running   bool              `json:"running"`
+	Config    *Config           `json:"config"`
+	StartedAt *time.Time        `json:"started_at"`
+	StoppedAt *time.Time        `json:"stopped_at"`
+	Published map[string]uint64 `json:"published"`
+	Errors    uint64            `json:"errors"`
+	LastError string            `json:"last_error,omitempty"`
+}
+
+// Generator runs at most one synthetic event stream at a time
+type Generator struct {
+	publisher Publisher
+	enabled   bool
+
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	config    *Config
+	startedAt *time.Time
+	stoppedAt *time.Time
+	lastError string
+
+	ticks, signalsNew, signalsUpdated, signalsClosed, errs atomic.Uint64
+}
+
+// NewGenerator creates a generator. publisher may be nil when NATS is down;
+// Start then fails.
+func NewGenerator(publisher Publisher) *Generator {
+	return &Generator{publisher: publisher, enabled: os.Getenv("LOADTEST_ENABLED") == "true"}
+}
+
+// Enabled reports whether LOADTEST_ENABLED is set
+func (g *Generator) Enabled() bool {
+	return g.enabled
+}
+
+// Start begins a run in the background
+func (g *Generator) Start(cfg Config) error {
+	if g.publisher == nil {
+		return fmt.Errorf("NATS is not connected")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancel != nil {
+		return ErrRunning
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DurationSeconds)*time.Second)
+	now := time.Now()
+	g.cancel, g.done = cancel, make(chan struct{})
+	g.config, g.startedAt, g.stoppedAt, g.lastError = &cfg, &now, nil, ""
+	for _, c := range []*atomic.Uint64{&g.ticks, &g.signalsNew, &g.signalsUpdated, &g.signalsClosed, &g.errs} {
+		c.Store(0)
+	}
+
+	go g.run(ctx, cfg, g.done)
+	return nil
+}
+
+// Stop ends the current run and waits for it to finish. It reports false if
+// nothing was running.
+func (g *Generator) Stop() bool {
+	g.mu.Lock()
+	cancel, done := g.cancel, g.done
+	g.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	<-done
+	return true
+}
+
+// Status returns the current or last run
+func (g *Generator) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Status{
+		Enabled:   g.enabled,
+		Running:   g.cancel != nil,
+		Config:    g.config,
+		StartedAt: g.startedAt,
+		StoppedAt: g.stoppedAt,
+		Published: map[string]uint64{
+			"market.tick":    g.ticks.Load(),
+			"signal.new":     g.signalsNew.Load(),
+			"signal.updated": g.signalsUpdated.Load(),
+			"signal.closed":  g.signalsClosed.Load(),
+		},
+		Errors:    g.errs.Load(),
+		LastError: g.lastError,
+	}
+}
+
+// synthSymbol is one instrument's random walk
+type synthSymbol struct {
+	name   string
+	open   float64
+	price  float64
+	volume uint32
+}
+
+// synthSignal is an open synthetic signal awaiting updates and a close
+type synthSignal struct {
+	event   events.SignalEvent
+	symbol  *synthSymbol
+	updates int
+}
+
+func (g *Generator) run(ctx context.Context, cfg Config, done chan struct{}) {
+	defer func() {
+		now := time.Now()
+		g.mu.Lock()
+		g.cancel, g.stoppedAt = nil, &now
+		g.mu.Unlock()
+		close(done)
+	}()
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	symbols := make([]*synthSymbol, cfg.Symbols)
+	for i := range symbols {
+		price := 100 + rng.Float64()*2900
+		symbols[i] = &synthSymbol{name: fmt.Sprintf("SYN%04d", i+1), open: price, price: price}
+	}
+
+	var open []*synthSignal
+	nextID := signalIDBase
+	tickBudget, signalBudget := 0.0, 0.0
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Carry fractional rates over so low rates still publish
+		tickBudget += cfg.TicksPerSecond * step.Seconds()
+		for ; tickBudget >= 1; tickBudget-- {
+			s := symbols[rng.Intn(len(symbols))]
+			s.price = math.Max(1, s.price*(1+0.0008*rng.NormFloat64()))
+			s.volume += uint32(1 + rng.Intn(500))
+			g.publish("market.tick", &g.ticks, events.TickEvent{
+				SchemaVersion: schemas.TickVersion,
+				EventType:     "market.tick",
+				Symbol:        s.name,
+				Price:         round2(s.price),
+				Volume:        s.volume,
+				ChangePct:     round2((s.price - s.open) / s.open * 100),
+				Timestamp:     time.Now().Format(time.RFC3339Nano),
+			})
+		}
+
+		signalBudget += cfg.SignalsPerMinute / 60 * step.Seconds()
+		for ; signalBudget >= 1; signalBudget-- {
+			// Each new signal is followed by a few updates and a close, so the
+			// three subjects see a realistic mix
+			if len(open) > 0 && rng.Float64() < 0.66 {
+				i := rng.Intn(len(open))
+				sig := open[i]
+				sig.event.CurrentPrice = round2(sig.symbol.price)
+				sig.event.Timestamp = time.Now().Format(time.RFC3339Nano)
+				if sig.updates >= 2 {
+					sig.event.EventType, sig.event.Status = "signal.closed", "HIT_TARGET"
+					if rng.Float64() < 0.4 {
+						sig.event.Status = "HIT_STOPLOSS"
+					}
+					sig.event.ExitPrice = sig.event.CurrentPrice
+					sig.event.PNL = round2((sig.event.ExitPrice - sig.event.EntryPrice) / sig.event.EntryPrice * 100)
+					g.publish("signal.closed", &g.signalsClosed, sig.event)
+					open = append(open[:i], open[i+1:]...)
+				} else {
+					sig.updates++
+					sig.event.EventType = "signal.updated"
+					g.publish("signal.updated", &g.signalsUpdated, sig.event)
+				}
+				continue
+			}
+
+			s := symbols[rng.Intn(len(symbols))]
+			nextID++
+			now := time.Now().Format(time.RFC3339Nano)
+			sig := &synthSignal{symbol: s, event: events.SignalEvent{
+				SchemaVersion: schemas.SignalVersion,
+				EventType:     "signal.new",
+				SignalID:      nextID,
+				Symbol:        s.name,
+				SignalType:    "BUY",
+				EntryPrice:    round2(s.price),
+				StopLoss:      round2(s.price * 0.99),
+				TargetPrice:   round2(s.price * 1.02),
+				Confidence:    round2(0.5 + rng.Float64()*0.45),
+				Status:        "ACTIVE",
+				CurrentPrice:  round2(s.price),
+				GeneratedAt:   now,
+				Timestamp:     now,
+			}}
+			open = append(open, sig)
+			g.publish("signal.new", &g.signalsNew, sig.event)
+		}
+	}
+}
+
+func (g *Generator) publish(subject string, counter *atomic.Uint64, event interface{}) {
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = g.publisher.Publish(subject, data)
+	}
+	if err != nil {
+		g.errs.Add(1)
+		g.mu.Lock()
+		g.lastError = err.Error()
+		g.mu.Unlock()
+		return
+	}
+	counter.Add(1)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,129 @@
+// Package maintenance holds the read-only maintenance mode. While it's on,
+// mutating API requests are refused so the database can be worked on safely,
+// and WebSocket clients are told when it starts and ends.
+//
+// The mode is held in memory on purpose: it has to keep working while the
+// database it protects is unavailable. Each API instance is toggled
+// separately.
+package maintenance
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// DefaultMessage is shown when maintenance is enabled without a message
+const DefaultMessage = "Scheduled maintenance in progress. Changes are temporarily disabled."
+
+// State is the current maintenance mode
+type State struct {
+	Enabled   bool    `json:"enabled"`
+	Message   string  `json:"message,omitempty"`
+	StartedAt *string `json:"started_at"`
+	// EndsAt is when the mode switches itself off, if a duration was given
+	EndsAt *string `json:"ends_at"`
+}
+
+// Mode toggles maintenance and announces changes over WebSocket
+type Mode struct {
+	hub *ws.Hub
+
+	mu    sync.RWMutex
+	state State
+	timer *time.Timer
+	// generation invalidates a timer that fired while the mode was being changed
+	generation int
+}
+
+// NewMode creates a mode that starts switched off
+func NewMode(hub *ws.Hub) *Mode {
+	return &Mode{hub: hub}
+}
+
+// State returns the current mode
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Enabled reports whether mutating requests should be refused
+func (m *Mode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Enabled
+}
+
+// Enable turns maintenance on, or updates the message and end time if it's
+// already on. A zero duration keeps it on until Disable.
+func (m *Mode) Enable(message string, duration time.Duration) State {
+	if message == "" {
+		message = DefaultMessage
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	startedAt := m.state.StartedAt
+	if !m.state.Enabled {
+		s := now.Format(time.RFC3339)
+		startedAt = &s
+	}
+	m.generation++
+	m.state = State{Enabled: true, Message: message, StartedAt: startedAt}
+	if duration > 0 {
+		endsAt := now.Add(duration).Format(time.RFC3339)
+		m.state.EndsAt = &endsAt
+		generation := m.generation
+		m.timer = time.AfterFunc(duration, func() { m.expire(generation) })
+	}
+	state := m.state
+	m.mu.Unlock()
+
+	log.Printf("⚠️  Maintenance mode on: %s", message)
+	m.announce(state)
+	return state
+}
+
+// Disable turns maintenance off
+func (m *Mode) Disable() State {
+	m.mu.Lock()
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	wasEnabled := m.state.Enabled
+	m.generation++
+	m.state = State{}
+	state := m.state
+	m.mu.Unlock()
+
+	if wasEnabled {
+		log.Println("✅ Maintenance mode off")
+		m.announce(state)
+	}
+	return state
+}
+
+func (m *Mode) expire(generation int) {
+	m.mu.RLock()
+	current := m.generation == generation
+	m.mu.RUnlock()
+	if current {
+		log.Println("✅ Maintenance window elapsed, leaving maintenance mode")
+		m.Disable()
+	}
+}
+
+func (m *Mode) announce(state State) {
+	m.hub.Broadcast(map[string]interface{}{
+		"type": "maintenance",
+		"data": state,
+	})
+}
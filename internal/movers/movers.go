@@ -0,0 +1,227 @@
+// Package movers keeps the top gainers and losers in memory, updated from
+// the market.tick stream and reconciled with md.realtime_prices, so the
+// top-movers endpoints read a precomputed list instead of querying.
+package movers
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/events"
+)
+
+// rebuildInterval bounds how often the sorted lists are recomputed
+const rebuildInterval = time.Second
+
+// reconcileInterval is how often the cache is reloaded from the database
+const reconcileInterval = time.Minute
+
+// maxAge drops quotes not updated for a day, matching the SQL endpoints
+const maxAge = 24 * time.Hour
+
+// confidence mirrors the constant the SQL endpoints return
+const confidence = 0.7
+
+// MaxLimit is the longest list kept per side
+const MaxLimit = 100
+
+// quote is the latest state of one symbol
+type quote struct {
+	name      string
+	price     float64
+	change    float64
+	updatedAt time.Time
+}
+
+// snapshot is an immutable pair of sorted lists
+type snapshot struct {
+	gainers []database.TopMover
+	losers  []database.TopMover
+	builtAt time.Time
+}
+
+// Status describes the cache for monitoring
+type Status struct {
+	Ready          bool       `json:"ready"`
+	Symbols        int        `json:"symbols"`
+	Ticks          uint64     `json:"ticks"`
+	BuiltAt        *time.Time `json:"built_at"`
+	LastReconciled *time.Time `json:"last_reconciled"`
+}
+
+// Cache holds per-symbol quotes and the derived top-movers lists
+type Cache struct {
+	db *database.DB
+
+	mu             sync.Mutex
+	quotes         map[string]*quote
+	dirty          bool
+	lastReconciled *time.Time
+
+	current atomic.Pointer[snapshot]
+	ticks   atomic.Uint64
+}
+
+// NewCache creates an empty cache; it serves nothing until the first
+// reconciliation succeeds
+func NewCache(db *database.DB) *Cache {
+	return &Cache{db: db, quotes: map[string]*quote{}}
+}
+
+// HandleTick applies a market.tick event
+func (c *Cache) HandleTick(e events.TickEvent) {
+	if e.Symbol == "" || e.Price <= 0 {
+		return
+	}
+	ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		ts = time.Now()
+	}
+	c.ticks.Add(1)
+
+	c.mu.Lock()
+	q, ok := c.quotes[e.Symbol]
+	if !ok {
+		q = &quote{name: e.Symbol}
+		c.quotes[e.Symbol] = q
+	}
+	if !ts.Before(q.updatedAt) {
+		q.price, q.change, q.updatedAt = e.Price, e.ChangePct, ts
+		c.dirty = true
+	}
+	c.mu.Unlock()
+}
+
+// Gainers returns up to limit top gainers. ok is false until the cache has
+// been loaded, in which case callers should query the database.
+func (c *Cache) Gainers(limit int) ([]database.TopMover, bool) {
+	s := c.current.Load()
+	if s == nil {
+		return nil, false
+	}
+	return head(s.gainers, limit), true
+}
+
+// Losers returns up to limit top losers, like Gainers
+func (c *Cache) Losers(limit int) ([]database.TopMover, bool) {
+	s := c.current.Load()
+	if s == nil {
+		return nil, false
+	}
+	return head(s.losers, limit), true
+}
+
+func head(list []database.TopMover, limit int) []database.TopMover {
+	if limit < len(list) {
+		return list[:limit]
+	}
+	return list
+}
+
+// Status reports cache freshness
+func (c *Cache) Status() Status {
+	c.mu.Lock()
+	st := Status{Symbols: len(c.quotes), LastReconciled: c.lastReconciled}
+	c.mu.Unlock()
+	st.Ticks = c.ticks.Load()
+	if s := c.current.Load(); s != nil {
+		st.Ready = true
+		st.BuiltAt = &s.builtAt
+	}
+	return st
+}
+
+// Run reconciles immediately, then rebuilds the lists as ticks arrive and
+// reconciles periodically until ctx is cancelled
+func (c *Cache) Run(ctx context.Context) {
+	c.reconcile(ctx)
+
+	rebuild := time.NewTicker(rebuildInterval)
+	defer rebuild.Stop()
+	reconcile := time.NewTicker(reconcileInterval)
+	defer reconcile.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rebuild.C:
+			c.mu.Lock()
+			dirty := c.dirty
+			c.mu.Unlock()
+			if dirty && c.current.Load() != nil {
+				c.rebuild()
+			}
+		case <-reconcile.C:
+			c.reconcile(ctx)
+		}
+	}
+}
+
+// reconcile merges md.realtime_prices into the cache. Newer tick values win;
+// names always come from the database.
+func (c *Cache) reconcile(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rows, err := c.db.GetMoverQuotes(ctx)
+	if err != nil {
+		log.Printf("❌ Top movers reconciliation failed: %v", err)
+		return
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	for _, r := range rows {
+		q, ok := c.quotes[r.Symbol]
+		if !ok {
+			q = &quote{}
+			c.quotes[r.Symbol] = q
+		}
+		q.name = r.Name
+		if r.UpdatedAt.After(q.updatedAt) {
+			q.price, q.change, q.updatedAt = r.Price, r.ChangePercent, r.UpdatedAt
+		}
+	}
+	c.lastReconciled = &now
+	c.dirty = true
+	c.mu.Unlock()
+
+	c.rebuild()
+}
+
+// rebuild recomputes both lists from the current quotes
+func (c *Cache) rebuild() {
+	now := time.Now()
+	var gainers, losers []database.TopMover
+
+	c.mu.Lock()
+	for symbol, q := range c.quotes {
+		if now.Sub(q.updatedAt) > maxAge {
+			delete(c.quotes, symbol)
+			continue
+		}
+		m := database.TopMover{Symbol: symbol, Name: q.name, Change: q.change, Confidence: confidence, Price: q.price}
+		switch {
+		case q.change > 0:
+			gainers = append(gainers, m)
+		case q.change < 0:
+			losers = append(losers, m)
+		}
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	sort.Slice(gainers, func(i, j int) bool { return gainers[i].Change > gainers[j].Change })
+	sort.Slice(losers, func(i, j int) bool { return losers[i].Change < losers[j].Change })
+	c.current.Store(&snapshot{
+		gainers: head(gainers, MaxLimit),
+		losers:  head(losers, MaxLimit),
+		builtAt: now,
+	})
+}
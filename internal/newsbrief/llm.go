@@ -0,0 +1,151 @@
+package newsbrief
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// md.system_config keys holding the LLM settings
+const (
+	ConfigProvider = "llm_provider"
+	ConfigAPIKey   = "llm_api_key"
+	ConfigModel    = "llm_model"
+	ConfigBaseURL  = "llm_base_url"
+)
+
+var defaultModels = map[string]string{
+	ProviderOpenAI:    "gpt-4o-mini",
+	ProviderAnthropic: "claude-3-5-haiku-latest",
+}
+
+var defaultBaseURLs = map[string]string{
+	ProviderOpenAI:    "https://api.openai.com",
+	ProviderAnthropic: "https://api.anthropic.com",
+}
+
+// maxOutputTokens bounds the length of a brief
+const maxOutputTokens = 400
+
+// Config selects the LLM provider. BaseURL allows an OpenAI-compatible
+// gateway or a self-hosted model.
+type Config struct {
+	Provider string
+	APIKey   string
+	Model    string
+	BaseURL  string
+}
+
+// complete sends one system+user prompt and returns the reply text
+func complete(ctx context.Context, client *http.Client, cfg Config, system, prompt string) (string, error) {
+	var url string
+	var body map[string]interface{}
+	headers := map[string]string{"Content-Type": "application/json"}
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		url = cfg.BaseURL + "/v1/chat/completions"
+		headers["Authorization"] = "Bearer " + cfg.APIKey
+		body = map[string]interface{}{
+			"model":      cfg.Model,
+			"max_tokens": maxOutputTokens,
+			"messages": []map[string]string{
+				{"role": "system", "content": system},
+				{"role": "user", "content": prompt},
+			},
+		}
+	case ProviderAnthropic:
+		url = cfg.BaseURL + "/v1/messages"
+		headers["x-api-key"] = cfg.APIKey
+		headers["anthropic-version"] = "2023-06-01"
+		body = map[string]interface{}{
+			"model":      cfg.Model,
+			"max_tokens": maxOutputTokens,
+			"system":     system,
+			"messages": []map[string]string{
+				{"role": "user", "content": prompt},
+			},
+		}
+	default:
+		return "", fmt.Errorf("unsupported LLM provider %q", cfg.Provider)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode LLM request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read LLM response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LLM provider returned %d: %s", resp.StatusCode, truncate(string(raw), 200))
+	}
+
+	var text string
+	if cfg.Provider == ProviderOpenAI {
+		var out struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return "", fmt.Errorf("failed to decode LLM response: %w", err)
+		}
+		if len(out.Choices) > 0 {
+			text = out.Choices[0].Message.Content
+		}
+	} else {
+		var out struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return "", fmt.Errorf("failed to decode LLM response: %w", err)
+		}
+		for _, block := range out.Content {
+			if block.Type == "text" {
+				text += block.Text
+			}
+		}
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("LLM provider returned an empty reply")
+	}
+	return text, nil
+}
+
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + "…"
+	}
+	return s
+}
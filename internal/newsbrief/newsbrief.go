@@ -0,0 +1,214 @@
+// Package newsbrief condenses a symbol's recent news into a short
+// LLM-written brief, so traders don't have to read every headline.
+//
+// The provider, key and model are read from md.system_config on each
+// request, so they can be changed without a restart. Briefs are cached per
+// symbol for the rest of the clock hour.
+package newsbrief
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/coalesce"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Article limits per brief
+const (
+	DefaultArticles = 30
+	MaxArticles     = 50
+)
+
+// maxCachedBriefs bounds the cache; expired briefs are pruned beyond it
+const maxCachedBriefs = 1000
+
+var (
+	// ErrNotConfigured means no LLM provider and key are set
+	ErrNotConfigured = errors.New("LLM provider is not configured")
+	// ErrNoArticles means the symbol has no news to summarize
+	ErrNoArticles = errors.New("no news articles for symbol")
+)
+
+// Source supplies articles and the LLM settings
+type Source interface {
+	GetNews(ctx context.Context, limit, offset int, sentiment, search, symbol string, collapse bool) (*database.NewsResponse, error)
+	GetSystemConfig(ctx context.Context, key string) (string, bool, error)
+}
+
+// Headline is an article the brief was written from
+type Headline struct {
+	Title     string  `json:"title"`
+	Source    string  `json:"source"`
+	Time      string  `json:"time"`
+	Sentiment *string `json:"sentiment"`
+}
+
+// Brief is a summary of a symbol's recent news
+type Brief struct {
+	Symbol       string     `json:"symbol"`
+	Summary      string     `json:"summary"`
+	ArticleCount int        `json:"article_count"`
+	Headlines    []Headline `json:"headlines"`
+	Provider     string     `json:"provider"`
+	Model        string     `json:"model"`
+	GeneratedAt  string     `json:"generated_at"`
+	ExpiresAt    string     `json:"expires_at"`
+	Cached       bool       `json:"cached"`
+}
+
+type cacheEntry struct {
+	brief   Brief
+	expires time.Time
+}
+
+// Summarizer writes and caches briefs
+type Summarizer struct {
+	db     Source
+	client *http.Client
+	flight coalesce.Group
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewSummarizer creates a summarizer reading from db
+func NewSummarizer(db Source) *Summarizer {
+	return &Summarizer{
+		db:     db,
+		client: &http.Client{Timeout: 45 * time.Second},
+		cache:  map[string]cacheEntry{},
+	}
+}
+
+// Summarize returns the brief of symbol's latest articles, from cache when
+// one was written this hour. Concurrent requests for the same brief share
+// one LLM call.
+func (s *Summarizer) Summarize(ctx context.Context, symbol string, articles int) (*Brief, error) {
+	now := time.Now()
+	hour := now.Truncate(time.Hour)
+	key := symbol + "|" + strconv.Itoa(articles) + "|" + strconv.FormatInt(hour.Unix(), 10)
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		brief := entry.brief
+		brief.Cached = true
+		return &brief, nil
+	}
+
+	brief, err := coalesce.Do(&s.flight, key, func() (Brief, error) {
+		// Callers share the result, so one of them leaving mustn't cancel it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		return s.write(ctx, symbol, articles, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expires := hour.Add(time.Hour)
+	brief.ExpiresAt = expires.UTC().Format(time.RFC3339)
+	s.mu.Lock()
+	s.cache[key] = cacheEntry{brief: brief, expires: expires}
+	if len(s.cache) > maxCachedBriefs {
+		for k, e := range s.cache {
+			if !now.Before(e.expires) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.mu.Unlock()
+	return &brief, nil
+}
+
+func (s *Summarizer) write(ctx context.Context, symbol string, articles int, now time.Time) (Brief, error) {
+	cfg, err := s.config(ctx)
+	if err != nil {
+		return Brief{}, err
+	}
+
+	news, err := s.db.GetNews(ctx, articles, 0, "", "", symbol, true)
+	if err != nil {
+		return Brief{}, fmt.Errorf("failed to load news for %s: %w", symbol, err)
+	}
+	if len(news.Articles) == 0 {
+		return Brief{}, ErrNoArticles
+	}
+
+	headlines := make([]Headline, len(news.Articles))
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Recent news articles mentioning %s, newest first:\n\n", symbol)
+	for i, a := range news.Articles {
+		headlines[i] = Headline{Title: a.Title, Source: a.Source, Time: a.Time, Sentiment: a.SentimentLabel}
+		sentiment := "unscored"
+		if a.SentimentLabel != nil {
+			sentiment = *a.SentimentLabel
+		}
+		fmt.Fprintf(&prompt, "%d. [%s, %s, %s] %s\n", i+1, a.Time, a.Source, sentiment, a.Title)
+		if a.Summary != nil && *a.Summary != "" {
+			fmt.Fprintf(&prompt, "   %s\n", truncate(*a.Summary, 400))
+		}
+	}
+
+	system := "You are a markets analyst for Indian equities. Summarize the news for " + symbol +
+		" in at most 120 words: the main developments, the overall sentiment and anything a " +
+		"trader should watch today. Use only the articles given, note conflicting reports, " +
+		"and do not give buy or sell advice."
+	summary, err := complete(ctx, s.client, cfg, system, prompt.String())
+	if err != nil {
+		return Brief{}, err
+	}
+	log.Printf("✅ News brief for %s written from %d articles (%s)", symbol, len(headlines), cfg.Provider)
+
+	return Brief{
+		Symbol:       symbol,
+		Summary:      summary,
+		ArticleCount: len(headlines),
+		Headlines:    headlines,
+		Provider:     cfg.Provider,
+		Model:        cfg.Model,
+		GeneratedAt:  now.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// config reads the LLM settings, filling in provider defaults
+func (s *Summarizer) config(ctx context.Context) (Config, error) {
+	var cfg Config
+	for key, dst := range map[string]*string{
+		ConfigProvider: &cfg.Provider,
+		ConfigAPIKey:   &cfg.APIKey,
+		ConfigModel:    &cfg.Model,
+		ConfigBaseURL:  &cfg.BaseURL,
+	} {
+		value, _, err := s.db.GetSystemConfig(ctx, key)
+		if err != nil {
+			return Config{}, err
+		}
+		*dst = strings.TrimSpace(value)
+	}
+
+	cfg.Provider = strings.ToLower(cfg.Provider)
+	if cfg.Provider == "" || cfg.APIKey == "" {
+		return Config{}, ErrNotConfigured
+	}
+	if _, ok := defaultBaseURLs[cfg.Provider]; !ok {
+		return Config{}, fmt.Errorf("%w: unknown %s %q", ErrNotConfigured, ConfigProvider, cfg.Provider)
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultModels[cfg.Provider]
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURLs[cfg.Provider]
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return cfg, nil
+}
@@ -0,0 +1,208 @@
+// Package newscluster groups near-duplicate news articles.
+//
+// The same story often arrives from several sources with slightly different
+// wording. Each article gets a 64-bit simhash of its title and summary. It
+// joins the cluster of the closest earlier article published within a
+// couple of days whose simhash is near and whose headline shares most of
+// its words; otherwise it starts a cluster of its own. Clusters are stored
+// in core_api.article_clusters and used by GetNews to return one article
+// per story.
+package newscluster
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"math/bits"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+const (
+	// maxDistance is the most simhash bits two near-duplicates differ by.
+	// Short texts hash noisily, so this only shortlists candidates.
+	maxDistance = 18
+	// minTitleOverlap is the Jaccard similarity of headline words that
+	// confirms a shortlisted candidate
+	minTitleOverlap = 0.5
+	// matchWindow bounds how far apart near-duplicates are published
+	matchWindow = 48 * time.Hour
+	// minFeatures is how many words an article needs to be matched at all;
+	// shorter texts collide too easily
+	minFeatures = 4
+	// batchSize caps the articles clustered per pass
+	batchSize = 500
+	// checkInterval is how often new articles are clustered
+	checkInterval = time.Minute
+)
+
+// Clusterer assigns new articles to clusters in the background
+type Clusterer struct {
+	db database.NewsClusterRepository
+}
+
+// NewClusterer creates a clusterer
+func NewClusterer(db database.NewsClusterRepository) *Clusterer {
+	return &Clusterer{db: db}
+}
+
+// Run clusters new articles every minute until ctx is cancelled
+func (c *Clusterer) Run(ctx context.Context) {
+	c.pass(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.pass(ctx)
+		}
+	}
+}
+
+// pass clusters unclustered articles in batches until none are left
+func (c *Clusterer) pass(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := c.ClusterNew(ctx, time.Now())
+		if err != nil {
+			log.Printf("⚠️  News clustering failed: %v", err)
+			return
+		}
+		if n < batchSize {
+			return
+		}
+	}
+}
+
+// ClusterNew assigns up to one batch of unclustered articles from the
+// match window before now, returning how many it looked at
+func (c *Clusterer) ClusterNew(ctx context.Context, now time.Time) (int, error) {
+	since := now.Add(-matchWindow)
+	candidates, err := c.db.ListUnclusteredArticles(ctx, since, batchSize)
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+	// Candidates are oldest first, so anything they could match was
+	// published at most one window before the first of them
+	known, err := c.db.ListArticleClusters(ctx, candidates[0].PublishedAt.Add(-matchWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := Assign(known, candidates)
+	if err := c.db.SaveArticleClusters(ctx, assigned); err != nil {
+		return 0, err
+	}
+	return len(candidates), nil
+}
+
+// Assign clusters candidates, oldest first, against known assignments and
+// each other
+func Assign(known []database.ArticleCluster, candidates []database.ClusterCandidate) []database.ArticleCluster {
+	pool := append([]database.ArticleCluster(nil), known...)
+	assigned := make([]database.ArticleCluster, 0, len(candidates))
+	for _, a := range candidates {
+		hash, features := Simhash(a.Title, a.Summary)
+		cluster := database.ArticleCluster{ArticleID: a.ID, ClusterID: a.ID, Simhash: hash, PublishedAt: a.PublishedAt, Title: a.Title}
+		if features >= minFeatures {
+			title := words(a.Title)
+			best := maxDistance + 1
+			for _, k := range pool {
+				if gap := a.PublishedAt.Sub(k.PublishedAt); gap > matchWindow || gap < -matchWindow {
+					continue
+				}
+				d := bits.OnesCount64(hash ^ k.Simhash)
+				if d < best && jaccard(title, words(k.Title)) >= minTitleOverlap {
+					best = d
+					cluster.ClusterID = k.ClusterID
+				}
+			}
+		}
+		pool = append(pool, cluster)
+		assigned = append(assigned, cluster)
+	}
+	return assigned
+}
+
+var wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// stopWords carry no information about which story an article tells
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be by for from has have in is it its
+		of on or says said that the this to was were will with after over amid`) {
+		stopWords[w] = true
+	}
+}
+
+// words returns the distinct informative words of text
+func words(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if len(w) >= 2 && !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// maxSummaryWords keeps long summaries from drowning out the headline
+const maxSummaryWords = 60
+
+// Simhash fingerprints an article, returning the hash and how many words
+// went into it. Headline words count twice.
+func Simhash(title, summary string) (uint64, int) {
+	var weights [64]int
+	features := 0
+	add := func(text string, weight, max int) {
+		n := 0
+		for _, w := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+			if len(w) < 2 || stopWords[w] {
+				continue
+			}
+			if max > 0 && n >= max {
+				return
+			}
+			n++
+			features++
+			h := fnv.New64a()
+			h.Write([]byte(w))
+			sum := h.Sum64()
+			for bit := 0; bit < 64; bit++ {
+				if sum&(1<<bit) != 0 {
+					weights[bit] += weight
+				} else {
+					weights[bit] -= weight
+				}
+			}
+		}
+	}
+	add(title, 2, 0)
+	add(summary, 1, maxSummaryWords)
+
+	var hash uint64
+	for bit, w := range weights {
+		if w > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash, features
+}
@@ -0,0 +1,280 @@
+// Package newsfeeds polls RSS and Atom feeds into news.articles.
+//
+// News collection otherwise runs as Python cron scripts; this worker is an
+// optional in-process alternative enabled with NEWS_INGEST_ENABLED=true.
+// Feeds are managed through the API either way, and can be polled on demand.
+// Articles are keyed by a hash of their URL so re-polls and overlapping
+// feeds don't duplicate them, and are tagged with any active symbol their
+// headline or summary mentions.
+package newsfeeds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// checkInterval is how often the worker looks for due feeds
+const checkInterval = time.Minute
+
+// maxFeedSize caps a downloaded feed
+const maxFeedSize = 5 << 20
+
+// Result is the outcome of polling one feed
+type Result struct {
+	FeedID      int64  `json:"feed_id"`
+	Fetched     int    `json:"fetched"`
+	Ingested    int    `json:"ingested"`
+	NotModified bool   `json:"not_modified"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Ingester polls feeds and stores their articles
+type Ingester struct {
+	db      database.NewsFeedRepository
+	client  *http.Client
+	enabled bool
+
+	// One poll per feed at a time, whether scheduled or on demand
+	mu      sync.Mutex
+	polling map[int64]bool
+}
+
+// NewIngester creates an ingester. The background worker only runs when
+// NEWS_INGEST_ENABLED is true.
+func NewIngester(db database.NewsFeedRepository) *Ingester {
+	return &Ingester{
+		db:      db,
+		client:  &http.Client{Timeout: 20 * time.Second},
+		enabled: os.Getenv("NEWS_INGEST_ENABLED") == "true",
+		polling: map[int64]bool{},
+	}
+}
+
+// Enabled reports whether the background worker runs
+func (g *Ingester) Enabled() bool {
+	return g.enabled
+}
+
+// Run polls due feeds every minute until ctx is cancelled. It returns
+// immediately when the worker is disabled.
+func (g *Ingester) Run(ctx context.Context) {
+	if !g.enabled {
+		return
+	}
+	log.Println("✅ News feed ingestion enabled")
+	g.pollDue(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.pollDue(ctx)
+		}
+	}
+}
+
+func (g *Ingester) pollDue(ctx context.Context) {
+	feeds, err := g.db.ListDueNewsFeeds(ctx, time.Now())
+	if err != nil {
+		log.Printf("⚠️  News feeds unavailable: %v", err)
+		return
+	}
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		res, err := g.Poll(ctx, feed)
+		if err != nil {
+			continue
+		}
+		if res.Error != "" {
+			log.Printf("⚠️  News feed %s failed: %s", feed.Name, res.Error)
+		} else if res.Ingested > 0 {
+			log.Printf("✅ News feed %s: %d new articles", feed.Name, res.Ingested)
+		}
+	}
+}
+
+// ErrPolling means the feed is already being polled
+var ErrPolling = errors.New("feed is already being polled")
+
+// Poll fetches one feed now and stores new articles. A failed fetch is
+// reported in the result; the error is only ErrPolling.
+func (g *Ingester) Poll(ctx context.Context, feed database.NewsFeed) (Result, error) {
+	res := Result{FeedID: feed.ID}
+	g.mu.Lock()
+	if g.polling[feed.ID] {
+		g.mu.Unlock()
+		return res, ErrPolling
+	}
+	g.polling[feed.ID] = true
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.polling, feed.ID)
+		g.mu.Unlock()
+	}()
+
+	pollCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	etag, lastModified, err := g.poll(pollCtx, feed, &res)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if err := g.db.RecordNewsFeedPoll(ctx, feed.ID, etag, lastModified, res.Ingested, err); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+	return res, nil
+}
+
+func (g *Ingester) poll(ctx context.Context, feed database.NewsFeed, res *Result) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid feed URL: %w", err)
+	}
+	req.Header.Set("User-Agent", "trading-chitti-core-api/2.0 (+news ingestion)")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	if feed.ETag != nil {
+		req.Header.Set("If-None-Match", *feed.ETag)
+	}
+	if feed.LastModified != nil {
+		req.Header.Set("If-Modified-Since", *feed.LastModified)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode == http.StatusNotModified {
+		res.NotModified = true
+		return deref(feed.ETag), deref(feed.LastModified), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("feed returned %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(data) > maxFeedSize {
+		return "", "", fmt.Errorf("feed is larger than %d MB", maxFeedSize>>20)
+	}
+
+	items, err := Parse(data, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+	res.Fetched = len(items)
+	if len(items) == 0 {
+		return etag, lastModified, nil
+	}
+
+	symbols, err := g.db.ListActiveSymbols(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	matcher := newSymbolMatcher(symbols)
+
+	articles := make([]database.FeedArticle, 0, len(items))
+	for _, it := range items {
+		link := resolveLink(feed.URL, it.Link)
+		articles = append(articles, database.FeedArticle{
+			ID:          ArticleID(link),
+			Title:       it.Title,
+			URL:         link,
+			Summary:     it.Summary,
+			PublishedAt: it.PublishedAt,
+			Symbols:     matcher.match(it.Title + " " + it.Summary),
+		})
+	}
+	res.Ingested, err = g.db.InsertFeedArticles(ctx, feed.Name, articles)
+	if err != nil {
+		return "", "", err
+	}
+	return etag, lastModified, nil
+}
+
+// ArticleID derives an article's ID from its URL, ignoring the fragment and
+// a trailing slash so trivially different links dedupe
+func ArticleID(link string) string {
+	normalized := strings.TrimSpace(link)
+	if u, err := url.Parse(normalized); err == nil {
+		u.Fragment = ""
+		u.Host = strings.ToLower(u.Host)
+		normalized = strings.TrimRight(u.String(), "/")
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return "rss-" + hex.EncodeToString(sum[:16])
+}
+
+func resolveLink(feedURL, link string) string {
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// symbolMatcher finds stock symbols mentioned as whole words. Symbols of
+// fewer than three characters are skipped as they match ordinary words.
+type symbolMatcher struct {
+	symbols map[string]bool
+}
+
+var wordPattern = regexp.MustCompile(`[A-Za-z0-9&-]+`)
+
+func newSymbolMatcher(symbols []string) symbolMatcher {
+	m := symbolMatcher{symbols: map[string]bool{}}
+	for _, s := range symbols {
+		if len(s) >= 3 {
+			m.symbols[strings.ToUpper(s)] = true
+		}
+	}
+	return m
+}
+
+// match returns the symbols text mentions. Only words written in capitals
+// count, so "Titan" the word doesn't tag TITAN.
+func (m symbolMatcher) match(text string) []string {
+	found := []string{}
+	seen := map[string]bool{}
+	for _, word := range wordPattern.FindAllString(text, -1) {
+		if word != strings.ToUpper(word) || !m.symbols[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		found = append(found, word)
+	}
+	return found
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,183 @@
+package newsfeeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Item is an entry read from an RSS or Atom feed
+type Item struct {
+	Title       string
+	Link        string
+	Summary     string
+	PublishedAt time.Time
+}
+
+type rssDoc struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			GUID        string `xml:"guid"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+			Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDoc struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Parse reads an RSS 2.0 or Atom document. Entries without a title or link
+// are dropped; a missing or unreadable date becomes fallback.
+func Parse(data []byte, fallback time.Time) ([]Item, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Item{}
+	switch root {
+	case "rss":
+		var doc rssDoc
+		if err := decode(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid RSS: %w", err)
+		}
+		for _, it := range doc.Channel.Items {
+			link := strings.TrimSpace(it.Link)
+			if link == "" && strings.HasPrefix(strings.TrimSpace(it.GUID), "http") {
+				link = strings.TrimSpace(it.GUID)
+			}
+			date := it.PubDate
+			if date == "" {
+				date = it.Date
+			}
+			items = appendItem(items, it.Title, link, it.Description, date, fallback)
+		}
+	case "feed":
+		var doc atomDoc
+		if err := decode(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid Atom: %w", err)
+		}
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			summary := e.Summary
+			if summary == "" {
+				summary = e.Content
+			}
+			date := e.Published
+			if date == "" {
+				date = e.Updated
+			}
+			items = appendItem(items, e.Title, link, summary, date, fallback)
+		}
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed (root element %q)", root)
+	}
+	return items, nil
+}
+
+func appendItem(items []Item, title, link, summary, date string, fallback time.Time) []Item {
+	title = cleanText(title)
+	if title == "" || link == "" {
+		return items
+	}
+	published, ok := parseDate(date)
+	if !ok || published.After(fallback) {
+		published = fallback
+	}
+	return append(items, Item{Title: title, Link: link, Summary: cleanText(summary), PublishedAt: published})
+}
+
+func rootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("invalid feed XML: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func decode(data []byte, v interface{}) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charsetReader
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	return dec.Decode(v)
+}
+
+// charsetReader accepts feeds that declare a Latin-1 or Windows-1252
+// encoding, which are close enough to UTF-8 for headlines
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "iso-8859-1", "latin1", "windows-1252":
+		return input, nil
+	}
+	return nil, fmt.Errorf("unsupported feed encoding %q", charset)
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// cleanText strips markup from feed text. Latin-1 feeds are read as-is,
+// so invalid UTF-8 is replaced to keep the insert from failing.
+func cleanText(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,396 @@
+// Package nlquery turns human filters such as ?when=last+week and
+// ?q=IT+stocks+that+gapped+up into the structured filters the signal and
+// scan endpoints already take.
+//
+// It's a small phrase grammar rather than a language model: sectors,
+// directions, statuses, scans, percentages and time phrases are recognised
+// from fixed vocabularies, and words it doesn't know are reported back
+// instead of guessed at. Days are IST calendar days and weeks start on
+// Monday.
+package nlquery
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scans a query can name, matching the /api/scans paths
+const (
+	ScanGaps          = "gaps"
+	ScanORB           = "orb"
+	ScanUnusualVolume = "unusual-volume"
+	ScanCircuits      = "circuits"
+)
+
+// Range is a span of whole days, From inclusive and To exclusive
+type Range struct {
+	From   time.Time
+	To     time.Time
+	Phrase string
+}
+
+// SingleDay reports whether the range is one day
+func (r Range) SingleDay() bool {
+	return r.From.AddDate(0, 0, 1).Equal(r.To)
+}
+
+// Interpretation describes the range with inclusive dates
+func (r Range) Interpretation() map[string]string {
+	return map[string]string{
+		"phrase": r.Phrase,
+		"from":   r.From.Format("2006-01-02"),
+		"to":     r.To.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+}
+
+func ist() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+	return time.FixedZone("IST", 5*3600+1800)
+}
+
+// ParseWhen parses a time phrase: today, yesterday, this/last week, this/last
+// month, this year, last N days/weeks/months, N days/weeks ago, a weekday
+// (optionally "last"), YYYY-MM-DD, since YYYY-MM-DD, or YYYY-MM-DD to
+// YYYY-MM-DD
+func ParseWhen(s string, now time.Time) (*Range, error) {
+	words := tokenize(s)
+	if len(words) == 0 {
+		return nil, errors.New("when is empty")
+	}
+	r, ok := parseWhen(words, now)
+	if !ok {
+		return nil, errors.New("when must be a phrase like today, yesterday, last week, last 3 days, monday, 2 days ago or YYYY-MM-DD")
+	}
+	return r, nil
+}
+
+var numberWords = map[string]int{
+	"a": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+func parseCount(w string) (int, bool) {
+	if n, ok := numberWords[w]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(w)
+	return n, err == nil && n > 0 && n <= 366
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// parseWhen parses words that are exactly one time phrase
+func parseWhen(words []string, now time.Time) (*Range, bool) {
+	loc := ist()
+	n := now.In(loc)
+	today := time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	phrase := strings.Join(words, " ")
+	span := func(from, to time.Time) (*Range, bool) {
+		return &Range{From: from, To: to, Phrase: phrase}, true
+	}
+	day := func(d time.Time) (*Range, bool) {
+		return span(d, d.AddDate(0, 0, 1))
+	}
+	date := func(w string) (time.Time, bool) {
+		t, err := time.ParseInLocation("2006-01-02", w, loc)
+		return t, err == nil
+	}
+
+	switch phrase {
+	case "today", "now":
+		return day(today)
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	case "this week":
+		return span(weekStart, tomorrow)
+	case "last week", "previous week":
+		return span(weekStart.AddDate(0, 0, -7), weekStart)
+	case "past week":
+		return span(today.AddDate(0, 0, -6), tomorrow)
+	case "this month":
+		return span(monthStart, tomorrow)
+	case "last month", "previous month":
+		return span(monthStart.AddDate(0, -1, 0), monthStart)
+	case "past month":
+		return span(today.AddDate(0, -1, 0).AddDate(0, 0, 1), tomorrow)
+	case "this year", "ytd":
+		return span(time.Date(today.Year(), 1, 1, 0, 0, 0, 0, loc), tomorrow)
+	}
+
+	switch len(words) {
+	case 1:
+		if wd, ok := weekdays[words[0]]; ok {
+			return day(today.AddDate(0, 0, -((int(today.Weekday()) - int(wd) + 7) % 7)))
+		}
+		if d, ok := date(words[0]); ok {
+			return day(d)
+		}
+	case 2:
+		if wd, ok := weekdays[words[1]]; ok && (words[0] == "last" || words[0] == "on" || words[0] == "previous") {
+			back := (int(today.Weekday()) - int(wd) + 7) % 7
+			if back == 0 && words[0] != "on" {
+				back = 7
+			}
+			return day(today.AddDate(0, 0, -back))
+		}
+		if d, ok := date(words[1]); ok && (words[0] == "since" || words[0] == "from") {
+			return span(d, tomorrow)
+		}
+		if d, ok := date(words[1]); ok && words[0] == "on" {
+			return day(d)
+		}
+	case 3:
+		if words[0] == "last" || words[0] == "past" {
+			count, ok := parseCount(words[1])
+			if !ok {
+				break
+			}
+			switch strings.TrimSuffix(words[2], "s") {
+			case "day":
+				return span(today.AddDate(0, 0, 1-count), tomorrow)
+			case "week":
+				return span(today.AddDate(0, 0, 1-7*count), tomorrow)
+			case "month":
+				return span(today.AddDate(0, -count, 1), tomorrow)
+			}
+		}
+		if words[2] == "ago" {
+			count, ok := parseCount(words[0])
+			if !ok {
+				break
+			}
+			switch strings.TrimSuffix(words[1], "s") {
+			case "day":
+				return day(today.AddDate(0, 0, -count))
+			case "week":
+				from := weekStart.AddDate(0, 0, -7*count)
+				return span(from, from.AddDate(0, 0, 7))
+			}
+		}
+		from, ok1 := date(words[0])
+		to, ok2 := date(words[2])
+		if ok1 && ok2 && (words[1] == "to" || words[1] == "until" || words[1] == "-") && !to.Before(from) {
+			return span(from, to.AddDate(0, 0, 1))
+		}
+	}
+	return nil, false
+}
+
+// Query is what a free-text query was understood as. Empty fields weren't
+// mentioned.
+type Query struct {
+	Sectors []string
+	// Direction is up or down: gapped up, gainers, bullish, upper circuit
+	Direction string
+	// SignalType is BUY or SELL
+	SignalType string
+	Status     string
+	// Outcome is winning or losing
+	Outcome string
+	Scan    string
+	// MinPct is a threshold like "more than 2%"
+	MinPct *float64
+	When   *Range
+	// Ignored are the words that weren't understood
+	Ignored []string
+}
+
+// sectorWords map words to md.stock_config sectors
+var sectorWords = map[string]string{
+	"it": "IT", "tech": "IT", "technology": "IT", "software": "IT",
+	"bank": "Banking", "banks": "Banking", "banking": "Banking",
+	"finance": "Financial Services", "financial": "Financial Services", "financials": "Financial Services",
+	"nbfc": "Financial Services", "nbfcs": "Financial Services", "financial services": "Financial Services",
+	"auto": "Auto", "autos": "Auto", "automobile": "Auto", "automobiles": "Auto",
+	"fmcg": "FMCG", "consumer goods": "FMCG",
+	"pharma": "Pharma", "pharmaceutical": "Pharma", "pharmaceuticals": "Pharma", "healthcare": "Pharma",
+	"energy": "Energy", "oil": "Energy", "oil and gas": "Energy", "power": "Energy",
+	"metal": "Metals", "metals": "Metals", "steel": "Metals",
+	"telecom": "Telecom", "infra": "Infrastructure", "infrastructure": "Infrastructure",
+	"realty": "Realty", "real estate": "Realty", "cement": "Cement",
+	"consumer services": "Consumer Services",
+}
+
+// phrases are the recognised words and phrases other than sectors and times
+var phrases = map[string]func(*Query){
+	"gapped up":      func(q *Query) { q.Scan, q.Direction = ScanGaps, "up" },
+	"gap up":         func(q *Query) { q.Scan, q.Direction = ScanGaps, "up" },
+	"gapping up":     func(q *Query) { q.Scan, q.Direction = ScanGaps, "up" },
+	"gapped down":    func(q *Query) { q.Scan, q.Direction = ScanGaps, "down" },
+	"gap down":       func(q *Query) { q.Scan, q.Direction = ScanGaps, "down" },
+	"gapping down":   func(q *Query) { q.Scan, q.Direction = ScanGaps, "down" },
+	"gap":            func(q *Query) { q.Scan = ScanGaps },
+	"gaps":           func(q *Query) { q.Scan = ScanGaps },
+	"gapped":         func(q *Query) { q.Scan = ScanGaps },
+	"unfilled":       func(q *Query) { q.Scan = ScanGaps },
+	"broke out":      func(q *Query) { q.Scan, q.Direction = ScanORB, "up" },
+	"breakout":       func(q *Query) { q.Scan, q.Direction = ScanORB, "up" },
+	"breakouts":      func(q *Query) { q.Scan, q.Direction = ScanORB, "up" },
+	"broke down":     func(q *Query) { q.Scan, q.Direction = ScanORB, "down" },
+	"breakdown":      func(q *Query) { q.Scan, q.Direction = ScanORB, "down" },
+	"breakdowns":     func(q *Query) { q.Scan, q.Direction = ScanORB, "down" },
+	"opening range":  func(q *Query) { q.Scan = ScanORB },
+	"orb":            func(q *Query) { q.Scan = ScanORB },
+	"unusual volume": func(q *Query) { q.Scan = ScanUnusualVolume },
+	"volume spike":   func(q *Query) { q.Scan = ScanUnusualVolume },
+	"high volume":    func(q *Query) { q.Scan = ScanUnusualVolume },
+	"upper circuit":  func(q *Query) { q.Scan, q.Direction = ScanCircuits, "up" },
+	"lower circuit":  func(q *Query) { q.Scan, q.Direction = ScanCircuits, "down" },
+	"circuit":        func(q *Query) { q.Scan = ScanCircuits },
+	"circuits":       func(q *Query) { q.Scan = ScanCircuits },
+	"buy":            func(q *Query) { q.SignalType, q.Direction = "BUY", "up" },
+	"long":           func(q *Query) { q.SignalType, q.Direction = "BUY", "up" },
+	"bullish":        func(q *Query) { q.SignalType, q.Direction = "BUY", "up" },
+	"sell":           func(q *Query) { q.SignalType, q.Direction = "SELL", "down" },
+	"short":          func(q *Query) { q.SignalType, q.Direction = "SELL", "down" },
+	"bearish":        func(q *Query) { q.SignalType, q.Direction = "SELL", "down" },
+	"up":             func(q *Query) { q.Direction = "up" },
+	"gainers":        func(q *Query) { q.Direction = "up" },
+	"rising":         func(q *Query) { q.Direction = "up" },
+	"down":           func(q *Query) { q.Direction = "down" },
+	"losers":         func(q *Query) { q.Direction = "down" },
+	"falling":        func(q *Query) { q.Direction = "down" },
+	"active":         func(q *Query) { q.Status = "ACTIVE" },
+	"open":           func(q *Query) { q.Status = "ACTIVE" },
+	"hit target":     func(q *Query) { q.Status = "HIT_TARGET" },
+	"target hit":     func(q *Query) { q.Status = "HIT_TARGET" },
+	"stopped out":    func(q *Query) { q.Status = "HIT_STOPLOSS" },
+	"hit stoploss":   func(q *Query) { q.Status = "HIT_STOPLOSS" },
+	"hit stop loss":  func(q *Query) { q.Status = "HIT_STOPLOSS" },
+	"trailing stop":  func(q *Query) { q.Status = "TRAILING_STOP" },
+	"expired":        func(q *Query) { q.Status = "EXPIRED" },
+	"winning":        func(q *Query) { q.Outcome = "winning" },
+	"winners":        func(q *Query) { q.Outcome = "winning" },
+	"profitable":     func(q *Query) { q.Outcome = "winning" },
+	"losing":         func(q *Query) { q.Outcome = "losing" },
+	"loss making":    func(q *Query) { q.Outcome = "losing" },
+}
+
+// fillers are words that carry no filter
+var fillers = map[string]bool{
+	"show": true, "me": true, "list": true, "find": true, "all": true, "the": true, "a": true, "an": true,
+	"stocks": true, "stock": true, "shares": true, "share": true, "names": true, "signals": true, "signal": true,
+	"that": true, "which": true, "who": true, "were": true, "was": true, "are": true, "is": true, "have": true,
+	"has": true, "with": true, "in": true, "of": true, "for": true, "and": true, "by": true, "from": true,
+	"sector": true, "sectors": true, "space": true, "trades": true, "ones": true, "at": true, "on": true,
+	"than": true, "more": true, "over": true, "above": true, "least": true,
+}
+
+var pctPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)(%|pc|percent)?$`)
+
+// maxPhraseWords is the longest phrase looked up
+const maxPhraseWords = 4
+
+// Parse reads a free-text query, taking the longest known phrase at each
+// position
+func Parse(text string, now time.Time) *Query {
+	q := &Query{}
+	words := tokenize(text)
+	seenSector := map[string]bool{}
+
+	for i := 0; i < len(words); {
+		matched := 0
+		for n := min(maxPhraseWords, len(words)-i); n > 0 && matched == 0; n-- {
+			span := words[i : i+n]
+			phrase := strings.Join(span, " ")
+			if r, ok := parseWhen(span, now); ok {
+				q.When = r
+				matched = n
+			} else if sector, ok := sectorWords[phrase]; ok {
+				if !seenSector[sector] {
+					seenSector[sector] = true
+					q.Sectors = append(q.Sectors, sector)
+				}
+				matched = n
+			} else if apply, ok := phrases[phrase]; ok {
+				apply(q)
+				matched = n
+			} else if n == 1 {
+				if m := pctPattern.FindStringSubmatch(phrase); m != nil && (m[2] != "" || isPctUnit(words, i+1)) {
+					v, _ := strconv.ParseFloat(m[1], 64)
+					q.MinPct = &v
+					matched = 1
+					if m[2] == "" {
+						matched = 2
+					}
+				} else if fillers[phrase] {
+					matched = 1
+				}
+			}
+		}
+		if matched == 0 {
+			q.Ignored = append(q.Ignored, words[i])
+			matched = 1
+		}
+		i += matched
+	}
+	return q
+}
+
+// isPctUnit reports whether words[i] is a percent unit following a number
+func isPctUnit(words []string, i int) bool {
+	return i < len(words) && (words[i] == "%" || words[i] == "percent" || words[i] == "pc")
+}
+
+// tokenize lower-cases s and splits it into words, keeping dates, decimals
+// and percent signs whole
+func tokenize(s string) []string {
+	s = strings.ToLower(s)
+	s = strings.NewReplacer("'s", "", "’s", "", "-", " ", "_", " ").Replace(s)
+	// Dates lose their hyphens above; restore them
+	s = datePattern.ReplaceAllString(s, "$1-$2-$3")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '%' || r == '-')
+	})
+	out := words[:0]
+	for _, w := range words {
+		// Full stops end sentences; only decimals keep theirs
+		if w = strings.Trim(w, "."); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+var datePattern = regexp.MustCompile(`\b(\d{4}) (\d{2}) (\d{2})\b`)
+
+// Interpretation describes the query for an interpreted_as echo
+func (q *Query) Interpretation() map[string]interface{} {
+	out := map[string]interface{}{}
+	if len(q.Sectors) > 0 {
+		out["sectors"] = q.Sectors
+	}
+	if q.Direction != "" {
+		out["direction"] = q.Direction
+	}
+	if q.SignalType != "" {
+		out["signal_type"] = q.SignalType
+	}
+	if q.Status != "" {
+		out["status"] = q.Status
+	}
+	if q.Outcome != "" {
+		out["outcome"] = q.Outcome
+	}
+	if q.Scan != "" {
+		out["scan"] = q.Scan
+	}
+	if q.MinPct != nil {
+		out["min_pct"] = *q.MinPct
+	}
+	if q.When != nil {
+		out["when"] = q.When.Interpretation()
+	}
+	return out
+}
@@ -0,0 +1,456 @@
+// Package notifications delivers alert and signal notifications to users
+// over the report channels (email and Telegram), honouring each channel's
+// quiet hours and digest mode, and as push notifications to their
+// registered mobile devices.
+//
+// Critical notifications always go out immediately. Others are held in
+// core_api.pending_notifications while a channel is in quiet hours, and
+// sent as one summary when they end; a channel in digest mode holds them
+// all and gets a single summary at its digest time each day. Pushes are
+// never held: a device in quiet hours simply skips non-critical ones.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/push"
+	"github.com/trading-chitti/core-api-go/internal/reports"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+const (
+	// flushInterval is how often held notifications are checked for delivery
+	flushInterval = time.Minute
+	// backlog is how many notifications may wait for dispatch before new
+	// ones are dropped
+	backlog = 256
+)
+
+// Notification kinds
+const (
+	KindAuthSecurity    = "auth_security"
+	KindBasketAlert     = "basket_alert"
+	KindReconciliation  = "price_reconciliation"
+	KindSignal          = "signal"
+	KindSignalProximity = "signal_proximity"
+	KindTradingView     = "tradingview_alert"
+	KindWebhookAlert    = "webhook_alert"
+)
+
+// Kinds lists every notification kind
+var Kinds = []string{KindAuthSecurity, KindBasketAlert, KindReconciliation, KindSignal, KindSignalProximity, KindTradingView, KindWebhookAlert}
+
+// Notification is one message for a user
+type Notification struct {
+	Kind  string
+	Title string
+	Body  string
+	// Critical notifications ignore quiet hours and digest mode
+	Critical bool
+	// Data is passed to the mobile app with a push, e.g. the signal to open
+	Data map[string]string
+}
+
+// delivery is a notification waiting for dispatch. An empty userID means
+// every user with notifications enabled.
+type delivery struct {
+	userID string
+	note   Notification
+}
+
+// Notifier routes notifications to users' channels and devices
+type Notifier struct {
+	db      database.NotificationRepository
+	devices database.PushDeviceRepository
+	senders map[string]reports.Sender
+	pushers map[string]push.Sender
+	queue   chan delivery
+	// muted kinds are dropped unless critical
+	muted atomic.Pointer[map[string]bool]
+}
+
+// NewNotifier creates a notifier delivering through senders, keyed by
+// channel, and pushers, keyed by device platform
+func NewNotifier(db database.NotificationRepository, devices database.PushDeviceRepository,
+	senders map[string]reports.Sender, pushers map[string]push.Sender) *Notifier {
+	return &Notifier{
+		db:      db,
+		devices: devices,
+		senders: senders,
+		pushers: pushers,
+		queue:   make(chan delivery, backlog),
+	}
+}
+
+// Channels lists the configured delivery channels
+func (n *Notifier) Channels() []string {
+	channels := []string{}
+	for _, ch := range []string{reports.ChannelEmail, reports.ChannelTelegram} {
+		if n.senders[ch] != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// Configured reports whether a delivery channel is available
+func (n *Notifier) Configured(channel string) bool {
+	return n.senders[channel] != nil
+}
+
+// PushPlatforms lists the configured push platforms
+func (n *Notifier) PushPlatforms() []string {
+	platforms := []string{}
+	for _, p := range []string{push.PlatformFCM, push.PlatformAPNs} {
+		if n.pushers[p] != nil {
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms
+}
+
+// PushConfigured reports whether pushes to a platform can be sent
+func (n *Notifier) PushConfigured(platform string) bool {
+	return n.pushers[platform] != nil
+}
+
+// SetMutedKinds stops non-critical notifications of kinds from being sent
+func (n *Notifier) SetMutedKinds(kinds []string) {
+	muted := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		muted[k] = true
+	}
+	n.muted.Store(&muted)
+}
+
+// Notify queues note for userID. It never blocks, so it's safe to call from
+// tick handlers; when the backlog is full the notification is dropped.
+// Non-critical notifications of muted kinds are dropped.
+func (n *Notifier) Notify(userID string, note Notification) {
+	if muted := n.muted.Load(); muted != nil && (*muted)[note.Kind] && !note.Critical {
+		return
+	}
+	select {
+	case n.queue <- delivery{userID: userID, note: note}:
+	default:
+		log.Printf("⚠️  Notification backlog full, dropped %s: %s", note.Kind, note.Title)
+	}
+}
+
+// Broadcast queues note for every user with notifications enabled
+func (n *Notifier) Broadcast(note Notification) {
+	n.Notify("", note)
+}
+
+// HandleSignal notifies every user of new and closed signals. It's
+// registered with the event subscriber's OnSignal.
+func (n *Notifier) HandleSignal(subject string, e schemas.Signal) {
+	switch subject {
+	case "signal.new":
+		n.Broadcast(Notification{
+			Kind:  KindSignal,
+			Title: fmt.Sprintf("New %s signal: %s", e.SignalType, e.Symbol),
+			Body: fmt.Sprintf("Entry %.2f, target %.2f, stop %.2f (%.2f confidence)",
+				e.EntryPrice, e.TargetPrice, e.StopLoss, e.Confidence),
+			Data: signalData(e),
+		})
+	case "signal.closed":
+		n.Broadcast(Notification{
+			Kind:  KindSignal,
+			Title: fmt.Sprintf("%s signal closed: %s", e.SignalType, e.Symbol),
+			Body:  fmt.Sprintf("%s at %.2f, P&L %.2f", e.Status, e.ExitPrice, e.PNL),
+			Data:  signalData(e),
+		})
+	}
+}
+
+// HandleAuthEvent notifies every user of lockouts and other suspicious
+// auth patterns; single failures aren't worth a notification. It's
+// registered with the auth guard's OnEvent.
+func (n *Notifier) HandleAuthEvent(e database.AuthSecurityEvent) {
+	if e.Severity == database.AuthSeverityInfo {
+		return
+	}
+	n.Broadcast(Notification{
+		Kind:     KindAuthSecurity,
+		Title:    fmt.Sprintf("Auth security: %s from %s", strings.ReplaceAll(e.Kind, "_", " "), e.IP),
+		Body:     e.Detail,
+		Critical: e.Severity == database.AuthSeverityCritical,
+		Data:     map[string]string{"kind": e.Kind, "ip": e.IP, "endpoint": e.Endpoint},
+	})
+}
+
+// signalData tells the app which signal a push is about
+func signalData(e schemas.Signal) map[string]string {
+	return map[string]string{"signal_id": fmt.Sprint(e.SignalID), "symbol": e.Symbol}
+}
+
+// Run dispatches notifications and delivers held ones until ctx is
+// cancelled
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-n.queue:
+			n.dispatch(ctx, d)
+		case now := <-ticker.C:
+			n.flush(ctx, now)
+		}
+	}
+}
+
+// dispatch sends a notification now or holds it, per channel
+func (n *Notifier) dispatch(ctx context.Context, d delivery) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	settings, err := n.db.ListEnabledNotificationSettings(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to load notification settings: %v", err)
+		return
+	}
+	now := time.Now()
+	// A member may set up the same destination in several accounts; it
+	// still gets one copy
+	reached := map[[2]string]bool{}
+	for _, s := range settings {
+		if d.userID != "" && s.UserID != d.userID {
+			continue
+		}
+		sender := n.senders[s.Channel]
+		if sender == nil {
+			continue
+		}
+		destination := [2]string{s.Channel, s.Destination}
+		if reached[destination] {
+			continue
+		}
+		reached[destination] = true
+
+		if !d.note.Critical && (s.DigestEnabled || InQuietHours(s, now)) {
+			if err := n.db.QueueNotification(ctx, s.AccountID, s.UserID, s.Channel, database.PendingNotification{
+				Kind: d.note.Kind, Title: d.note.Title, Body: d.note.Body,
+			}); err != nil {
+				log.Printf("❌ %v", err)
+			}
+			continue
+		}
+		report := &reports.Report{Subject: d.note.Title, Text: d.note.Body}
+		if err := sender.Send(ctx, s.Destination, report); err != nil {
+			log.Printf("⚠️  Notification to %s via %s not delivered: %v", s.UserID, s.Channel, err)
+		}
+	}
+
+	if len(n.pushers) > 0 {
+		n.pushToDevices(ctx, d, now)
+	}
+}
+
+// pushToDevices sends a notification to every device that wants it. Devices
+// whose token the provider rejects are disabled.
+func (n *Notifier) pushToDevices(ctx context.Context, d delivery, now time.Time) {
+	devices, err := n.devices.ListEnabledPushDevices(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to load push devices: %v", err)
+		return
+	}
+	msg := push.Message{
+		Title:    d.note.Title,
+		Body:     d.note.Body,
+		Kind:     d.note.Kind,
+		Critical: d.note.Critical,
+		Data:     d.note.Data,
+	}
+	for _, dev := range devices {
+		if d.userID != "" && dev.UserID != d.userID {
+			continue
+		}
+		pusher := n.pushers[dev.Platform]
+		if pusher == nil || !DeviceWants(dev, d.note, now) {
+			continue
+		}
+
+		sendErr := pusher.Send(ctx, dev.Token, msg)
+		unregistered := errors.Is(sendErr, push.ErrUnregistered)
+		if sendErr != nil {
+			log.Printf("⚠️  Push to device %d of %s not delivered: %v", dev.ID, dev.UserID, sendErr)
+		}
+		if err := n.devices.RecordPushResult(ctx, dev.ID, sendErr, unregistered); err != nil {
+			log.Printf("❌ %v", err)
+		}
+	}
+}
+
+// DeviceWants reports whether a device should be pushed note now. Critical
+// notifications always go out; others must match the device's kinds and
+// fall outside its quiet hours, and are skipped by critical-only devices.
+func DeviceWants(dev database.PushDevice, note Notification, now time.Time) bool {
+	if note.Critical {
+		return true
+	}
+	if dev.CriticalOnly || inQuietWindow(dev.QuietStart, dev.QuietEnd, now) {
+		return false
+	}
+	if len(dev.Kinds) == 0 {
+		return true
+	}
+	for _, k := range dev.Kinds {
+		if k == note.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// flush delivers held notifications whose quiet hours have ended or whose
+// digest is due
+func (n *Notifier) flush(ctx context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	settings, err := n.db.ListEnabledNotificationSettings(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to load notification settings: %v", err)
+		return
+	}
+	for _, s := range settings {
+		sender := n.senders[s.Channel]
+		if sender == nil {
+			continue
+		}
+		if s.DigestEnabled {
+			if !DigestDue(s, now) {
+				continue
+			}
+		} else if s.Pending == 0 || InQuietHours(s, now) {
+			continue
+		}
+
+		pending, err := n.db.ListPendingNotifications(ctx, s.AccountID, s.UserID, s.Channel)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			continue
+		}
+		if len(pending) > 0 {
+			if err := sender.Send(ctx, s.Destination, summary(s.DigestEnabled, pending)); err != nil {
+				log.Printf("⚠️  Held notifications for %s via %s not delivered: %v", s.UserID, s.Channel, err)
+				continue
+			}
+			ids := make([]int64, len(pending))
+			for i, p := range pending {
+				ids[i] = p.ID
+			}
+			if err := n.db.ClearPendingNotifications(ctx, ids); err != nil {
+				log.Printf("❌ %v", err)
+			}
+		}
+		// An empty digest still counts, so notifications arriving later in
+		// the day wait for tomorrow's
+		if s.DigestEnabled {
+			if err := n.db.RecordNotificationDigest(ctx, s.AccountID, s.UserID, s.Channel, now); err != nil {
+				log.Printf("❌ %v", err)
+			}
+		}
+	}
+}
+
+// summary renders held notifications as one message
+func summary(digest bool, pending []database.PendingNotification) *reports.Report {
+	noun := "notifications"
+	if len(pending) == 1 {
+		noun = "notification"
+	}
+	subject := fmt.Sprintf("%d %s during quiet hours", len(pending), noun)
+	if digest {
+		subject = fmt.Sprintf("Notification digest: %d %s", len(pending), noun)
+	}
+
+	var text strings.Builder
+	loc := istLocation()
+	for i, p := range pending {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		fmt.Fprintf(&text, "%s  %s\n", p.CreatedAt.In(loc).Format("02 Jan 15:04"), p.Title)
+		if p.Body != "" {
+			fmt.Fprintf(&text, "%s\n", p.Body)
+		}
+	}
+	return &reports.Report{Subject: subject, Text: text.String()}
+}
+
+// InQuietHours reports whether now falls in the setting's quiet hours.
+// Quiet hours may wrap past midnight; equal start and end mean none.
+func InQuietHours(s database.NotificationSetting, now time.Time) bool {
+	return inQuietWindow(s.QuietStart, s.QuietEnd, now)
+}
+
+// DeviceInQuietHours reports whether now falls in a device's quiet hours
+func DeviceInQuietHours(dev database.PushDevice, now time.Time) bool {
+	return inQuietWindow(dev.QuietStart, dev.QuietEnd, now)
+}
+
+func inQuietWindow(quietStart, quietEnd *string, now time.Time) bool {
+	if quietStart == nil || quietEnd == nil {
+		return false
+	}
+	start, err := minuteOfDay(*quietStart)
+	if err != nil {
+		return false
+	}
+	end, err := minuteOfDay(*quietEnd)
+	if err != nil || start == end {
+		return false
+	}
+	t := now.In(istLocation())
+	m := t.Hour()*60 + t.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end
+}
+
+// DigestDue reports whether the most recent digest time at or before now
+// has passed without a digest going out
+func DigestDue(s database.NotificationSetting, now time.Time) bool {
+	if s.DigestTime == nil {
+		return false
+	}
+	hour, minute, err := reports.ParseSendTime(*s.DigestTime)
+	if err != nil {
+		return false
+	}
+	t := now.In(istLocation())
+	scheduled := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, t.Location())
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	last := s.LastDigest()
+	return last == nil || last.Before(scheduled)
+}
+
+func minuteOfDay(clock string) (int, error) {
+	hour, minute, err := reports.ParseSendTime(clock)
+	if err != nil {
+		return 0, err
+	}
+	return hour*60 + minute, nil
+}
+
+// istLocation returns Asia/Kolkata, falling back to a fixed +05:30 zone
+func istLocation() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+	return time.FixedZone("IST", 5*3600+1800)
+}
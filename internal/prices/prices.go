@@ -0,0 +1,324 @@
+// Package prices keeps the latest price of every symbol in memory, fed from
+// the market.tick stream, so the price endpoints stay current when the
+// market bridge's writes to md.realtime_prices lag. Snapshots can optionally
+// be persisted to core_api.price_snapshots so a restart starts warm.
+package prices
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/events"
+)
+
+// Modes for the price snapshot consumer
+const (
+	ModeOff     = "off"
+	ModeMemory  = "memory"
+	ModePersist = "persist"
+)
+
+// maxAge matches the one-day window of the realtime price endpoints
+const maxAge = 24 * time.Hour
+
+// pgTimeLayouts parse updated_at::text as Postgres renders it
+var pgTimeLayouts = []string{"2006-01-02 15:04:05.999999-07", "2006-01-02 15:04:05.999999-07:00"}
+
+// Config controls the tick consumer
+type Config struct {
+	// Mode is off, memory (default) or persist
+	Mode          string        `json:"mode"`
+	FlushInterval time.Duration `json:"flush_interval"`
+}
+
+// ConfigFromEnv reads PRICE_SNAPSHOTS and PRICE_SNAPSHOT_FLUSH_SECONDS
+func ConfigFromEnv() Config {
+	cfg := Config{Mode: ModeMemory, FlushInterval: 5 * time.Second}
+	switch m := os.Getenv("PRICE_SNAPSHOTS"); m {
+	case ModeOff, ModeMemory, ModePersist:
+		cfg.Mode = m
+	case "":
+	default:
+		log.Printf("⚠️  Unknown PRICE_SNAPSHOTS=%q, using %s", m, cfg.Mode)
+	}
+	if v, err := strconv.Atoi(os.Getenv("PRICE_SNAPSHOT_FLUSH_SECONDS")); err == nil && v > 0 {
+		cfg.FlushInterval = time.Duration(v) * time.Second
+	}
+	return cfg
+}
+
+// entry is the latest tick of one symbol
+type entry struct {
+	price     float64
+	volume    int64
+	change    float64
+	updatedAt time.Time
+	dirty     bool
+}
+
+// Status describes the store for monitoring
+type Status struct {
+	Mode        string     `json:"mode"`
+	Symbols     int        `json:"symbols"`
+	Ticks       uint64     `json:"ticks"`
+	Overlaid    uint64     `json:"overlaid"`
+	Fallbacks   uint64     `json:"fallbacks"`
+	LastFlushed *time.Time `json:"last_flushed"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Store holds the latest tick-derived price per symbol
+type Store struct {
+	db  *database.DB
+	cfg Config
+
+	mu          sync.RWMutex
+	entries     map[string]*entry
+	lastFlushed *time.Time
+	lastError   string
+
+	ticks     atomic.Uint64
+	overlaid  atomic.Uint64
+	fallbacks atomic.Uint64
+}
+
+// NewStore creates an empty store
+func NewStore(db *database.DB, cfg Config) *Store {
+	return &Store{db: db, cfg: cfg, entries: map[string]*entry{}}
+}
+
+// Enabled reports whether ticks are consumed at all
+func (s *Store) Enabled() bool {
+	return s.cfg.Mode != ModeOff
+}
+
+// HandleTick applies a market.tick event
+func (s *Store) HandleTick(e events.TickEvent) {
+	if !s.Enabled() || e.Symbol == "" || e.Price <= 0 {
+		return
+	}
+	ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		ts = time.Now()
+	}
+	s.ticks.Add(1)
+
+	s.mu.Lock()
+	en, ok := s.entries[e.Symbol]
+	if !ok {
+		en = &entry{}
+		s.entries[e.Symbol] = en
+	}
+	if !ts.Before(en.updatedAt) {
+		en.price, en.volume, en.change, en.updatedAt = e.Price, int64(e.Volume), e.ChangePct, ts
+		en.dirty = true
+	}
+	s.mu.Unlock()
+}
+
+// Overlay replaces the tick-driven fields of p when the store has a newer
+// price for the symbol. Open, high, low and close stay as the bridge wrote
+// them.
+func (s *Store) Overlay(p *database.RealtimePrice) {
+	s.mu.RLock()
+	en, ok := s.entries[p.Symbol]
+	var e entry
+	if ok {
+		e = *en
+	}
+	s.mu.RUnlock()
+	if !ok || !e.updatedAt.After(parsePGTime(p.UpdatedAt)) {
+		return
+	}
+
+	volume, change := e.volume, e.change
+	p.LastPrice, p.Volume, p.ChangePercent = e.price, &volume, &change
+	p.UpdatedAt = formatPGTime(e.updatedAt)
+	s.overlaid.Add(1)
+}
+
+// Get returns the stored price for symbol, for use when the database has
+// no row or cannot be reached
+func (s *Store) Get(symbol string) (*database.RealtimePrice, bool) {
+	s.mu.RLock()
+	en, ok := s.entries[symbol]
+	var e entry
+	if ok {
+		e = *en
+	}
+	s.mu.RUnlock()
+	if !ok || time.Since(e.updatedAt) > maxAge {
+		return nil, false
+	}
+	s.fallbacks.Add(1)
+	p := toRealtimePrice(symbol, e)
+	return &p, true
+}
+
+// Latest returns the last traded price of symbol and when it was seen
+func (s *Store) Latest(symbol string) (float64, time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	en, ok := s.entries[symbol]
+	if !ok || time.Since(en.updatedAt) > maxAge {
+		return 0, time.Time{}, false
+	}
+	return en.price, en.updatedAt, true
+}
+
+// List returns up to limit stored prices, most recently updated first
+func (s *Store) List(limit int) []database.RealtimePrice {
+	cutoff := time.Now().Add(-maxAge)
+	s.mu.RLock()
+	list := make([]database.RealtimePrice, 0, len(s.entries))
+	times := make(map[string]time.Time, len(s.entries))
+	for symbol, en := range s.entries {
+		if en.updatedAt.Before(cutoff) {
+			continue
+		}
+		list = append(list, toRealtimePrice(symbol, *en))
+		times[symbol] = en.updatedAt
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return times[list[i].Symbol].After(times[list[j].Symbol]) })
+	if limit < len(list) {
+		list = list[:limit]
+	}
+	if len(list) > 0 {
+		s.fallbacks.Add(1)
+	}
+	return list
+}
+
+// Status reports store size and counters
+func (s *Store) Status() Status {
+	s.mu.RLock()
+	st := Status{Mode: s.cfg.Mode, Symbols: len(s.entries), LastFlushed: s.lastFlushed, LastError: s.lastError}
+	s.mu.RUnlock()
+	st.Ticks = s.ticks.Load()
+	st.Overlaid = s.overlaid.Load()
+	st.Fallbacks = s.fallbacks.Load()
+	return st
+}
+
+// Run loads persisted snapshots and then flushes changed prices every
+// FlushInterval until ctx is cancelled. It returns at once unless Mode is
+// persist.
+func (s *Store) Run(ctx context.Context) {
+	if s.cfg.Mode != ModePersist {
+		return
+	}
+	s.load(ctx)
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Final flush so a clean shutdown loses nothing
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *Store) load(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	snapshots, err := s.db.GetPriceSnapshots(ctx, maxAge)
+	if err != nil {
+		log.Printf("❌ Failed to load price snapshots: %v", err)
+		return
+	}
+	s.mu.Lock()
+	for _, snap := range snapshots {
+		if en, ok := s.entries[snap.Symbol]; ok && !snap.UpdatedAt.After(en.updatedAt) {
+			continue
+		}
+		s.entries[snap.Symbol] = &entry{price: snap.LastPrice, volume: snap.Volume, change: snap.ChangePercent, updatedAt: snap.UpdatedAt}
+	}
+	s.mu.Unlock()
+	log.Printf("✅ Loaded %d price snapshots", len(snapshots))
+}
+
+func (s *Store) flush(ctx context.Context) {
+	var batch []database.PriceSnapshot
+	s.mu.Lock()
+	for symbol, en := range s.entries {
+		if !en.dirty {
+			continue
+		}
+		batch = append(batch, database.PriceSnapshot{
+			Symbol: symbol, LastPrice: en.price, Volume: en.volume, ChangePercent: en.change, UpdatedAt: en.updatedAt,
+		})
+		en.dirty = false
+	}
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err := s.db.UpsertPriceSnapshots(ctx, batch)
+
+	now := time.Now()
+	s.mu.Lock()
+	if err != nil {
+		// Mark the batch dirty again unless a newer tick already did
+		for _, snap := range batch {
+			if en, ok := s.entries[snap.Symbol]; ok && en.updatedAt.Equal(snap.UpdatedAt) {
+				en.dirty = true
+			}
+		}
+		s.lastError = err.Error()
+	} else {
+		s.lastFlushed, s.lastError = &now, ""
+	}
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("❌ Price snapshot flush failed: %v", err)
+	}
+}
+
+func toRealtimePrice(symbol string, e entry) database.RealtimePrice {
+	volume, change := e.volume, e.change
+	p := database.RealtimePrice{
+		Symbol:        symbol,
+		LastPrice:     e.price,
+		Volume:        &volume,
+		ChangePercent: &change,
+		UpdatedAt:     formatPGTime(e.updatedAt),
+	}
+	// Previous close implied by the tick's change percentage
+	if e.change > -100 {
+		p.Close = e.price / (1 + e.change/100)
+	}
+	return p
+}
+
+func parsePGTime(s string) time.Time {
+	for _, layout := range pgTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func formatPGTime(t time.Time) string {
+	return t.Format(pgTimeLayouts[0])
+}
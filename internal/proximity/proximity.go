@@ -0,0 +1,222 @@
+// Package proximity measures how close active signals are to their target
+// and stop-loss levels and announces, over WebSocket and notification
+// channels, when a signal enters a configurable band around either level.
+package proximity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/events"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// refreshInterval is how often active signals are reloaded
+const refreshInterval = 15 * time.Second
+
+// hysteresis widens the band a signal must leave before it can alert again,
+// so a price oscillating on the band edge does not spam clients
+const hysteresis = 1.5
+
+// Distance is how far price is from a signal's levels, in percent of price.
+// Positive values mean the level has not been reached yet.
+type Distance struct {
+	Price        float64 `json:"price"`
+	ToTargetPct  float64 `json:"distance_to_target_pct"`
+	ToStopPct    float64 `json:"distance_to_stop_pct"`
+	NearTarget   bool    `json:"near_target"`
+	NearStop     bool    `json:"near_stop"`
+	TargetPassed bool    `json:"target_passed"`
+	StopPassed   bool    `json:"stop_passed"`
+}
+
+// Measure computes distances for a signal at price. SELL and SHORT signals
+// profit when price falls, so their distances are mirrored.
+func Measure(signalType string, price, target, stop, bandPct float64) Distance {
+	d := Distance{Price: price}
+	if price <= 0 {
+		return d
+	}
+	if isShort(signalType) {
+		d.ToTargetPct = (price - target) / price * 100
+		d.ToStopPct = (stop - price) / price * 100
+	} else {
+		d.ToTargetPct = (target - price) / price * 100
+		d.ToStopPct = (price - stop) / price * 100
+	}
+	d.ToTargetPct = round2(d.ToTargetPct)
+	d.ToStopPct = round2(d.ToStopPct)
+	d.TargetPassed = target > 0 && d.ToTargetPct <= 0
+	d.StopPassed = stop > 0 && d.ToStopPct <= 0
+	d.NearTarget = target > 0 && !d.TargetPassed && d.ToTargetPct <= bandPct
+	d.NearStop = stop > 0 && !d.StopPassed && d.ToStopPct <= bandPct
+	return d
+}
+
+func isShort(signalType string) bool {
+	t := strings.ToUpper(signalType)
+	return t == "SELL" || t == "SHORT"
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// BandFromEnv reads PROXIMITY_BAND_PCT, defaulting to 0.5%
+func BandFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("PROXIMITY_BAND_PCT"), 64); err == nil && v > 0 {
+		return v
+	}
+	return 0.5
+}
+
+// watched is an active signal and which levels it has alerted on
+type watched struct {
+	signal     database.Signal
+	nearTarget bool
+	nearStop   bool
+}
+
+// Watcher checks every tick against the active signals on its symbol
+type Watcher struct {
+	db       database.SignalRepository
+	hub      *ws.Hub
+	notifier *notifications.Notifier
+	band     float64
+
+	mu       sync.Mutex
+	bySymbol map[string][]*watched
+}
+
+// NewWatcher creates a watcher alerting within bandPct of either level
+func NewWatcher(db database.SignalRepository, hub *ws.Hub, notifier *notifications.Notifier, bandPct float64) *Watcher {
+	return &Watcher{db: db, hub: hub, notifier: notifier, band: bandPct, bySymbol: map[string][]*watched{}}
+}
+
+// Band returns the alert band in percent
+func (w *Watcher) Band() float64 {
+	return w.band
+}
+
+// Run reloads active signals periodically until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		w.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh replaces the watched set, keeping alert state of signals that
+// are still active
+func (w *Watcher) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	signals, err := w.db.GetActiveSignals(ctx)
+	if err != nil {
+		log.Printf("❌ Proximity watcher failed to load active signals: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	previous := map[string]*watched{}
+	for _, list := range w.bySymbol {
+		for _, sw := range list {
+			previous[sw.signal.SignalID] = sw
+		}
+	}
+	bySymbol := map[string][]*watched{}
+	for _, s := range signals {
+		sw := &watched{signal: s}
+		if prev, ok := previous[s.SignalID]; ok {
+			sw.nearTarget, sw.nearStop = prev.nearTarget, prev.nearStop
+		}
+		bySymbol[s.Symbol] = append(bySymbol[s.Symbol], sw)
+	}
+	w.bySymbol = bySymbol
+}
+
+// HandleTick checks the tick's symbol against its active signals
+func (w *Watcher) HandleTick(e events.TickEvent) {
+	if e.Price <= 0 {
+		return
+	}
+
+	var alerts []map[string]interface{}
+	var subjects []ws.Subject
+	w.mu.Lock()
+	for _, sw := range w.bySymbol[e.Symbol] {
+		s := sw.signal
+		d := Measure(s.SignalType, e.Price, s.TargetPrice, s.StopLoss, w.band)
+		wide := Measure(s.SignalType, e.Price, s.TargetPrice, s.StopLoss, w.band*hysteresis)
+
+		subject := ws.Subject{Symbol: s.Symbol, Strategy: database.SignalStrategy(&s)}
+		if d.NearTarget && !sw.nearTarget {
+			alerts = append(alerts, alert(s, "target", d))
+			subjects = append(subjects, subject)
+		}
+		if d.NearStop && !sw.nearStop {
+			alerts = append(alerts, alert(s, "stop", d))
+			subjects = append(subjects, subject)
+		}
+		sw.nearTarget = d.NearTarget || (sw.nearTarget && wide.NearTarget)
+		sw.nearStop = d.NearStop || (sw.nearStop && wide.NearStop)
+	}
+	w.mu.Unlock()
+
+	for i, a := range alerts {
+		w.hub.BroadcastAbout(subjects[i], map[string]interface{}{
+			"type": "signal_proximity",
+			"data": a,
+		})
+		w.notifier.Broadcast(notification(a))
+	}
+}
+
+// notification describes a proximity alert; nearing a stop is critical
+func notification(a map[string]interface{}) notifications.Notification {
+	level := "target"
+	if a["level"] == "stop" {
+		level = "stop-loss"
+	}
+	return notifications.Notification{
+		Kind:     notifications.KindSignalProximity,
+		Title:    fmt.Sprintf("%s %s near %s", a["symbol"], a["signal_type"], level),
+		Body:     fmt.Sprintf("Price %.2f is %.2f%% from the %s at %.2f", a["price"], a["distance_pct"], level, a["level_price"]),
+		Critical: a["level"] == "stop",
+		Data:     map[string]string{"signal_id": fmt.Sprint(a["signal_id"]), "symbol": fmt.Sprint(a["symbol"])},
+	}
+}
+
+func alert(s database.Signal, level string, d Distance) map[string]interface{} {
+	distance, levelPrice := d.ToTargetPct, s.TargetPrice
+	if level == "stop" {
+		distance, levelPrice = d.ToStopPct, s.StopLoss
+	}
+	return map[string]interface{}{
+		"signal_id":    s.SignalID,
+		"symbol":       s.Symbol,
+		"signal_type":  s.SignalType,
+		"level":        level,
+		"level_price":  levelPrice,
+		"price":        d.Price,
+		"distance_pct": distance,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}
+}
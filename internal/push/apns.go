@@ -0,0 +1,172 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apnsTokenLifetime is how long a provider token is reused. APNs rejects
+// tokens older than an hour and refreshes more often than every 20 minutes.
+const apnsTokenLifetime = 50 * time.Minute
+
+// APNsSender sends through the APNs HTTP/2 API with token-based
+// authentication
+type APNsSender struct {
+	APIURL string
+	KeyID  string
+	TeamID string
+	// Topic is the app's bundle ID
+	Topic string
+
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsSender loads a .p8 signing key. sandbox targets the development
+// environment used by debug builds.
+func NewAPNsSender(keyFile, keyID, teamID, topic string, sandbox bool, client *http.Client) (*APNsSender, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, fmt.Errorf("APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC are required")
+	}
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APNs key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("APNs key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("APNs key is not an ECDSA key")
+	}
+
+	apiURL := "https://api.push.apple.com"
+	if sandbox {
+		apiURL = "https://api.sandbox.push.apple.com"
+	}
+	return &APNsSender{
+		APIURL: apiURL,
+		KeyID:  keyID,
+		TeamID: teamID,
+		Topic:  topic,
+		key:    key,
+		client: client,
+	}, nil
+}
+
+// Send pushes m to an APNs device token
+func (s *APNsSender) Send(ctx context.Context, token string, m Message) error {
+	jwt, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": m.Title, "body": m.Body},
+			"sound": "default",
+		},
+		"kind": m.Kind,
+	}
+	for k, v := range m.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.APIURL+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create APNs request: %w", err)
+	}
+	priority := "5"
+	if m.Critical {
+		priority = "10"
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+jwt)
+	req.Header.Set("apns-topic", s.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", priority)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send APNs notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(respBody, &result)
+	if resp.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return ErrUnregistered
+	}
+	reason := result.Reason
+	if reason == "" {
+		reason = strings.TrimSpace(string(respBody))
+	}
+	return fmt.Errorf("APNs returned %d: %s", resp.StatusCode, reason)
+}
+
+// providerToken returns the cached ES256 provider token, signing a new one
+// once it's apnsTokenLifetime old
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jwt != "" && time.Since(s.issuedAt) < apnsTokenLifetime {
+		return s.jwt, nil
+	}
+
+	now := time.Now()
+	jwt, err := signJWT(
+		map[string]interface{}{"alg": "ES256", "kid": s.KeyID},
+		map[string]interface{}{"iss": s.TeamID, "iat": now.Unix()},
+		func(input []byte) ([]byte, error) {
+			digest := sha256.Sum256(input)
+			r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+			if err != nil {
+				return nil, err
+			}
+			// JWS wants r and s as fixed-width big-endian halves
+			out := make([]byte, 64)
+			r.FillBytes(out[:32])
+			sig.FillBytes(out[32:])
+			return out, nil
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs token: %w", err)
+	}
+	s.jwt, s.issuedAt = jwt, now
+	return jwt, nil
+}
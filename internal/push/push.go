@@ -0,0 +1,102 @@
+// Package push delivers notifications to the mobile app through Firebase
+// Cloud Messaging (Android) and the Apple Push Notification service (iOS),
+// so they arrive while the app is in the background.
+package push
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Platforms a device can register for
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// sendTimeout bounds one push request
+const sendTimeout = 10 * time.Second
+
+// ErrUnregistered is returned when the provider no longer accepts a device
+// token, e.g. because the app was uninstalled. The device should stop
+// receiving pushes.
+var ErrUnregistered = errors.New("device token is no longer registered")
+
+// Message is one push notification
+type Message struct {
+	Title string
+	Body  string
+	// Kind is the notification kind, passed to the app to route a tap
+	Kind string
+	// Critical messages are sent at high priority
+	Critical bool
+	// Data is passed to the app alongside the alert, e.g. the signal to open
+	Data map[string]string
+}
+
+// Sender delivers a message to one device token
+type Sender interface {
+	Send(ctx context.Context, token string, m Message) error
+}
+
+// SendersFromEnv builds a sender for each platform configured in the
+// environment. FCM needs FCM_CREDENTIALS_FILE, a service account JSON key
+// (FCM_PROJECT_ID overrides its project). APNs needs APNS_KEY_FILE (the .p8
+// signing key), APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC (the app's bundle
+// ID); APNS_SANDBOX=true targets the development environment.
+func SendersFromEnv() map[string]Sender {
+	senders := map[string]Sender{}
+	client := &http.Client{Timeout: sendTimeout}
+
+	if path := os.Getenv("FCM_CREDENTIALS_FILE"); path != "" {
+		sender, err := NewFCMSender(path, os.Getenv("FCM_PROJECT_ID"), client)
+		if err != nil {
+			log.Printf("⚠️  FCM push disabled: %v", err)
+		} else {
+			if apiURL := strings.TrimRight(os.Getenv("FCM_API_URL"), "/"); apiURL != "" {
+				sender.APIURL = apiURL
+			}
+			senders[PlatformFCM] = sender
+		}
+	}
+
+	if path := os.Getenv("APNS_KEY_FILE"); path != "" {
+		sender, err := NewAPNsSender(path, os.Getenv("APNS_KEY_ID"), os.Getenv("APNS_TEAM_ID"),
+			os.Getenv("APNS_TOPIC"), os.Getenv("APNS_SANDBOX") == "true", client)
+		if err != nil {
+			log.Printf("⚠️  APNs push disabled: %v", err)
+		} else {
+			if apiURL := strings.TrimRight(os.Getenv("APNS_API_URL"), "/"); apiURL != "" {
+				sender.APIURL = apiURL
+			}
+			senders[PlatformAPNs] = sender
+		}
+	}
+	return senders
+}
+
+// signJWT builds a compact JWT, signing header.claims with sign
+func signJWT(header, claims map[string]interface{}, sign func(signingInput []byte) ([]byte, error)) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString(h) + "." + enc.EncodeToString(c)
+	sig, err := sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + enc.EncodeToString(sig), nil
+}
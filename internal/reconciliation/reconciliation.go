@@ -0,0 +1,175 @@
+// Package reconciliation checks each trading day's realtime closes against
+// the official bhavcopy, catching days when the feed was wrong.
+//
+// Realtime prices only hold the latest value, so the comparison runs each
+// weekday evening, on the first check after RECONCILE_TIME (IST, default
+// 18:30) once the day's bhavcopy has been loaded, and before the next
+// session overwrites the prices. Closes differing by more than
+// RECONCILE_TOLERANCE_PCT (default 0.5) are stored as discrepancies and
+// raise an alert over WebSocket and notifications.
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+const (
+	// checkInterval is how often the job looks for an unreconciled day
+	checkInterval = 15 * time.Minute
+	// defaultRunTime is after the exchange publishes the bhavcopy
+	defaultRunTime = "18:30"
+	// defaultTolerancePct is the largest close difference that still matches
+	defaultTolerancePct = 0.5
+	// alertSymbols is how many mismatched symbols an alert names
+	alertSymbols = 5
+)
+
+// Job reconciles realtime prices with the bhavcopy once per weekday
+type Job struct {
+	db        database.PriceReconciliationRepository
+	hub       *ws.Hub
+	notifier  *notifications.Notifier
+	loc       *time.Location
+	after     time.Duration // since IST midnight
+	tolerance float64
+
+	// waiting is the day already logged as waiting for its bhavcopy
+	waiting string
+}
+
+// NewJob creates a reconciliation job, reading RECONCILE_TIME as HH:MM and
+// RECONCILE_TOLERANCE_PCT
+func NewJob(db database.PriceReconciliationRepository, hub *ws.Hub, notifier *notifications.Notifier) *Job {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		loc = time.FixedZone("IST", 5*3600+1800)
+	}
+	at := os.Getenv("RECONCILE_TIME")
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		if at != "" {
+			log.Printf("⚠️  Invalid RECONCILE_TIME=%q, using %s", at, defaultRunTime)
+		}
+		t, _ = time.Parse("15:04", defaultRunTime)
+	}
+	tolerance := defaultTolerancePct
+	if v, err := strconv.ParseFloat(os.Getenv("RECONCILE_TOLERANCE_PCT"), 64); err == nil && v > 0 {
+		tolerance = v
+	}
+	return &Job{
+		db:        db,
+		hub:       hub,
+		notifier:  notifier,
+		loc:       loc,
+		after:     time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute,
+		tolerance: tolerance,
+	}
+}
+
+// Run reconciles each weekday until ctx is cancelled
+func (j *Job) Run(ctx context.Context) {
+	j.check(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.check(ctx)
+		}
+	}
+}
+
+// check reconciles today if it's due and hasn't been
+func (j *Job) check(ctx context.Context) {
+	now := time.Now().In(j.loc)
+	if now.Weekday() == time.Saturday || now.Weekday() == time.Sunday {
+		return
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, j.loc)
+	if now.Sub(day) < j.after {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	done, err := j.db.HasPriceReconciliation(ctx, day)
+	if err != nil {
+		log.Printf("⚠️  Price reconciliation unavailable: %v", err)
+		return
+	}
+	if done {
+		return
+	}
+	recon, err := j.db.ReconcilePrices(ctx, day, j.tolerance)
+	if errors.Is(err, database.ErrNoBhavcopy) {
+		if date := day.Format("2006-01-02"); j.waiting != date {
+			j.waiting = date
+			log.Printf("⏳ Price reconciliation for %s waiting for the bhavcopy", j.waiting)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to reconcile prices: %v", err)
+		return
+	}
+	log.Printf("✅ Price reconciliation for %s: %d compared, %d discrepancies, %d stale, %d missing from bhavcopy",
+		recon.TradeDate, recon.Compared, recon.Discrepancies, recon.StaleRealtime, recon.MissingBhavcopy)
+	if recon.Discrepancies > 0 {
+		j.alert(recon)
+	}
+}
+
+// alert announces a day with discrepancies
+func (j *Job) alert(recon *database.PriceReconciliation) {
+	log.Printf("⚠️  %d realtime closes differ from the bhavcopy on %s by more than %.2f%%",
+		recon.Discrepancies, recon.TradeDate, recon.TolerancePct)
+
+	// Name the largest differences
+	mismatches := []database.PriceDiscrepancy{}
+	for _, d := range recon.Items {
+		if d.Kind == database.ReconMismatch && d.DiffPct != nil {
+			mismatches = append(mismatches, d)
+		}
+	}
+	sort.Slice(mismatches, func(a, b int) bool {
+		return math.Abs(*mismatches[a].DiffPct) > math.Abs(*mismatches[b].DiffPct)
+	})
+	symbols := []string{}
+	for i := 0; i < len(mismatches) && i < alertSymbols; i++ {
+		symbols = append(symbols, fmt.Sprintf("%s %+.2f%%", mismatches[i].Symbol, *mismatches[i].DiffPct))
+	}
+
+	j.hub.Broadcast(map[string]interface{}{
+		"type": "reconciliation_alert",
+		"data": map[string]interface{}{
+			"trade_date":       recon.TradeDate,
+			"tolerance_pct":    recon.TolerancePct,
+			"compared":         recon.Compared,
+			"discrepancies":    recon.Discrepancies,
+			"stale_realtime":   recon.StaleRealtime,
+			"missing_bhavcopy": recon.MissingBhavcopy,
+		},
+	})
+	j.notifier.Broadcast(notifications.Notification{
+		Kind:  notifications.KindReconciliation,
+		Title: fmt.Sprintf("Price reconciliation: %d discrepancies on %s", recon.Discrepancies, recon.TradeDate),
+		Body: fmt.Sprintf("%d of %d realtime closes differ from the bhavcopy by more than %.2f%%: %s",
+			recon.Discrepancies, recon.Compared, recon.TolerancePct, strings.Join(symbols, ", ")),
+		Data: map[string]string{"trade_date": recon.TradeDate},
+	})
+}
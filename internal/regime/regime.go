@@ -0,0 +1,279 @@
+// Package regime classifies the intraday market regime from the benchmark
+// index's 5-minute bars and announces changes over WebSocket and NATS.
+//
+// The rules are evaluated in order and the first match wins:
+//
+//  1. UNKNOWN       fewer than 6 bars in today's session
+//  2. HIGH_VOL      annualised volatility of 5-minute returns >= 25%
+//  3. TRENDING_UP   efficiency >= 0.4 and change since the open >= +0.3%
+//  4. TRENDING_DOWN efficiency >= 0.4 and change since the open <= -0.3%
+//  5. CHOPPY        anything else
+//
+// Efficiency is Kaufman's ratio: the net move since the open divided by the
+// sum of absolute bar-to-bar moves. 1 is a straight line, near 0 is noise.
+package regime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// Regimes
+const (
+	Unknown      = "UNKNOWN"
+	HighVol      = "HIGH_VOL"
+	TrendingUp   = "TRENDING_UP"
+	TrendingDown = "TRENDING_DOWN"
+	Choppy       = "CHOPPY"
+)
+
+// Rule thresholds
+const (
+	minBars         = 6
+	highVolPct      = 25.0
+	trendEfficiency = 0.4
+	trendMovePct    = 0.3
+)
+
+// barsPerYear annualises 5-minute return volatility: 75 bars a session, 252 sessions
+const barsPerYear = 75 * 252
+
+// Subject is the NATS subject regime changes are published on
+const Subject = "regime.changed"
+
+// Rule is one step of the rules engine, as documented for clients
+type Rule struct {
+	Regime    string `json:"regime"`
+	Condition string `json:"condition"`
+}
+
+// Rules lists the rules in evaluation order
+var Rules = []Rule{
+	{Unknown, "fewer than 6 five-minute bars in today's session"},
+	{HighVol, "annualised volatility of 5-minute returns >= 25%"},
+	{TrendingUp, "efficiency >= 0.4 and change since open >= +0.3%"},
+	{TrendingDown, "efficiency >= 0.4 and change since open <= -0.3%"},
+	{Choppy, "none of the above"},
+}
+
+// Metrics are the inputs the rules are evaluated on
+type Metrics struct {
+	Bars          int     `json:"bars"`
+	Open          float64 `json:"open"`
+	Last          float64 `json:"last"`
+	High          float64 `json:"high"`
+	Low           float64 `json:"low"`
+	ChangePct     float64 `json:"change_pct"`
+	RangePct      float64 `json:"range_pct"`
+	Efficiency    float64 `json:"efficiency"`
+	VolatilityPct float64 `json:"volatility_pct"`
+}
+
+// Reading is a classification of the current session
+type Reading struct {
+	Index   string  `json:"index"`
+	Regime  string  `json:"regime"`
+	Rule    string  `json:"rule"`
+	Metrics Metrics `json:"metrics"`
+	// Since is when the session entered this regime, as seen by this instance
+	Since string `json:"since"`
+	AsOf  string `json:"as_of"`
+
+	session string
+}
+
+// Classify applies the rules to a session's bars, oldest first
+func Classify(bars []database.Candle) (string, Rule, Metrics) {
+	m := Metrics{Bars: len(bars)}
+	if len(bars) < minBars {
+		return Unknown, Rules[0], m
+	}
+
+	m.Open, m.Last = bars[0].Open, bars[len(bars)-1].Close
+	m.High, m.Low = bars[0].High, bars[0].Low
+	var path float64
+	returns := make([]float64, 0, len(bars))
+	prev := m.Open
+	for _, b := range bars {
+		m.High = math.Max(m.High, b.High)
+		m.Low = math.Min(m.Low, b.Low)
+		path += math.Abs(b.Close - prev)
+		if prev > 0 && b.Close > 0 {
+			returns = append(returns, math.Log(b.Close/prev))
+		}
+		prev = b.Close
+	}
+	if m.Open > 0 {
+		m.ChangePct = round(((m.Last - m.Open) / m.Open) * 100)
+		m.RangePct = round(((m.High - m.Low) / m.Open) * 100)
+	}
+	if path > 0 {
+		m.Efficiency = round(math.Abs(m.Last-m.Open) / path)
+	}
+	m.VolatilityPct = round(stddev(returns) * math.Sqrt(barsPerYear) * 100)
+
+	switch {
+	case m.VolatilityPct >= highVolPct:
+		return HighVol, Rules[1], m
+	case m.Efficiency >= trendEfficiency && m.ChangePct >= trendMovePct:
+		return TrendingUp, Rules[2], m
+	case m.Efficiency >= trendEfficiency && m.ChangePct <= -trendMovePct:
+		return TrendingDown, Rules[3], m
+	}
+	return Choppy, Rules[4], m
+}
+
+func stddev(v []float64) float64 {
+	if len(v) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	mean := sum / float64(len(v))
+	var ss float64
+	for _, x := range v {
+		ss += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(ss / float64(len(v)-1))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// Publisher publishes a raw event on a NATS subject
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// IndexFromEnv reads REGIME_INDEX, defaulting to NIFTY 50
+func IndexFromEnv() string {
+	if v := os.Getenv("REGIME_INDEX"); v != "" {
+		return v
+	}
+	return "NIFTY 50"
+}
+
+// Tracker reclassifies the session every minute while the market is open
+// and announces regime changes
+type Tracker struct {
+	db        database.MarketRepository
+	hub       *ws.Hub
+	publisher Publisher
+	index     string
+
+	mu      sync.Mutex
+	current *Reading
+}
+
+// NewTracker creates a tracker for index. publisher may be nil when NATS is
+// not connected; changes are then only broadcast over WebSocket.
+func NewTracker(db database.MarketRepository, hub *ws.Hub, publisher Publisher, index string) *Tracker {
+	return &Tracker{db: db, hub: hub, publisher: publisher, index: index}
+}
+
+// Run classifies the session every minute until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			open := database.SessionOpen(now)
+			if wd := open.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				continue
+			}
+			if now.Before(open) || now.After(open.Add((database.SessionMinutes+5)*time.Minute)) {
+				continue
+			}
+			if _, err := t.Current(ctx); err != nil {
+				log.Printf("❌ Regime classification failed: %v", err)
+			}
+		}
+	}
+}
+
+// Current classifies today's session from the latest bars, announcing a
+// change from the previous reading
+func (t *Tracker) Current(ctx context.Context) (*Reading, error) {
+	now := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Only completed bars; the forming one would swing the metrics
+	bars, err := t.db.GetCandles(ctx, t.index, "5m", database.SessionOpen(now), now.Truncate(5*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	regime, rule, metrics := Classify(bars)
+
+	t.mu.Lock()
+	previous := t.current
+	reading := &Reading{
+		Index:   t.index,
+		Regime:  regime,
+		Rule:    rule.Condition,
+		Metrics: metrics,
+		Since:   now.Format(time.RFC3339),
+		AsOf:    now.Format(time.RFC3339),
+		session: database.SessionOpen(now).Format("2006-01-02"),
+	}
+	sameSession := previous != nil && previous.session == reading.session
+	if sameSession && previous.Regime == regime {
+		reading.Since = previous.Since
+	}
+	t.current = reading
+	t.mu.Unlock()
+
+	// The first reading of a session isn't a change, nor is leaving UNKNOWN
+	// during the opening bars
+	if sameSession && previous.Regime != regime && previous.Regime != Unknown {
+		t.announce(previous.Regime, reading)
+	}
+	return reading, nil
+}
+
+func (t *Tracker) announce(previous string, r *Reading) {
+	event := schemas.Regime{
+		SchemaVersion: schemas.RegimeVersion,
+		EventType:     Subject,
+		Index:         r.Index,
+		Regime:        r.Regime,
+		Previous:      previous,
+		Rule:          r.Rule,
+		ChangePct:     r.Metrics.ChangePct,
+		Efficiency:    r.Metrics.Efficiency,
+		VolatilityPct: r.Metrics.VolatilityPct,
+		Timestamp:     r.AsOf,
+	}
+	log.Printf("✅ Market regime %s -> %s (%s)", previous, r.Regime, r.Index)
+
+	t.hub.Broadcast(map[string]interface{}{
+		"type": "regime_changed",
+		"data": event,
+	})
+	if t.publisher == nil {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ Failed to encode regime event: %v", err)
+		return
+	}
+	if err := t.publisher.Publish(Subject, data); err != nil {
+		log.Printf("⚠️  Failed to publish %s: %v", Subject, err)
+	}
+}
@@ -0,0 +1,471 @@
+// Package replay plays a recorded market day back over WebSocket at a
+// configurable speed: ticks from md.ticks, signals from intraday.signals
+// (opening at generated_at, closing at closed_at) and articles from
+// news.articles, each sent when the replay clock reaches its recorded time.
+// A snapshot of prices, signals and news as of the replay clock is served
+// alongside, so frontend and strategy work can go on outside market hours.
+//
+// Replayed messages carry "replay": true. Nothing is published to NATS or
+// written to the database. It is dev-only and gated by REPLAY_ENABLED.
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+const (
+	// step is how often the player advances its clock
+	step = 100 * time.Millisecond
+	// lookahead is how many wall-clock seconds of ticks are loaded at once
+	lookahead = 5 * time.Second
+	// minChunk is the least replay time loaded at once
+	minChunk = time.Minute
+)
+
+// Limits keep a run from loading too much at once
+const (
+	MaxSpeed   = 600
+	MaxSymbols = 500
+)
+
+// ErrRunning is returned when a replay is already in progress
+var ErrRunning = errors.New("replay already running")
+
+// Config describes one replay
+type Config struct {
+	// Date is the recorded day, YYYY-MM-DD
+	Date string `json:"date"`
+	// Speed is replay seconds per wall-clock second
+	Speed float64 `json:"speed"`
+	// From and To bound the replay as IST HH:MM, default the session
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Symbols []string `json:"symbols"`
+
+	start, end time.Time
+}
+
+// Validate applies defaults and limits
+func (c *Config) Validate(loc *time.Location) error {
+	day, err := time.ParseInLocation("2006-01-02", c.Date, loc)
+	if err != nil {
+		return fmt.Errorf("date is required as YYYY-MM-DD")
+	}
+	now := time.Now().In(loc)
+	if !day.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)) {
+		return fmt.Errorf("date must be a past day")
+	}
+	if c.Speed == 0 {
+		c.Speed = 60
+	}
+	if c.From == "" {
+		c.From = "09:15"
+	}
+	if c.To == "" {
+		c.To = "15:30"
+	}
+	if c.Speed < 1 || c.Speed > MaxSpeed {
+		return fmt.Errorf("speed must be between 1 and %d", MaxSpeed)
+	}
+	if len(c.Symbols) > MaxSymbols {
+		return fmt.Errorf("at most %d symbols", MaxSymbols)
+	}
+	for i, s := range c.Symbols {
+		c.Symbols[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+	from, errFrom := time.Parse("15:04", c.From)
+	to, errTo := time.Parse("15:04", c.To)
+	if errFrom != nil || errTo != nil || !from.Before(to) {
+		return fmt.Errorf("from and to must be HH:MM with from before to")
+	}
+	c.start = day.Add(time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute)
+	c.end = day.Add(time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute)
+	return nil
+}
+
+// Status reports the current or last replay
+type Status struct {
+	Enabled   bool              `json:"enabled"`
+	Running   bool              `json:"running"`
+	Config    *Config           `json:"config"`
+	Clock     *time.Time        `json:"clock"`
+	StartedAt *time.Time        `json:"started_at"`
+	StoppedAt *time.Time        `json:"stopped_at"`
+	Sent      map[string]uint64 `json:"sent"`
+	LastError string            `json:"last_error,omitempty"`
+}
+
+// Price is a symbol's last replayed tick
+type Price struct {
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	Open      float64   `json:"open"`
+	ChangePct float64   `json:"change_pct"`
+	Volume    int64     `json:"volume"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Snapshot is the replayed day as of the replay clock
+type Snapshot struct {
+	Date    string                   `json:"date"`
+	Clock   *time.Time               `json:"clock"`
+	Running bool                     `json:"running"`
+	Prices  []Price                  `json:"prices"`
+	Signals []database.Signal        `json:"signals"`
+	News    []database.ReplayArticle `json:"news"`
+}
+
+// Player runs at most one replay at a time
+type Player struct {
+	db      database.ReplayRepository
+	hub     *ws.Hub
+	enabled bool
+	loc     *time.Location
+
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	config    *Config
+	clock     *time.Time
+	startedAt *time.Time
+	stoppedAt *time.Time
+	lastError string
+	sent      map[string]uint64
+	prices    map[string]*Price
+	signals   []database.Signal
+	news      []database.ReplayArticle
+}
+
+// NewPlayer creates a player
+func NewPlayer(db database.ReplayRepository, hub *ws.Hub) *Player {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		loc = time.FixedZone("IST", 5*3600+1800)
+	}
+	return &Player{db: db, hub: hub, enabled: os.Getenv("REPLAY_ENABLED") == "true", loc: loc}
+}
+
+// Enabled reports whether REPLAY_ENABLED is set
+func (p *Player) Enabled() bool {
+	return p.enabled
+}
+
+// Location is the timezone replay times are given in
+func (p *Player) Location() *time.Location {
+	return p.loc
+}
+
+// Start loads the day's signals and news and begins the replay in the
+// background. cfg must have been validated.
+func (p *Player) Start(ctx context.Context, cfg Config) error {
+	p.mu.Lock()
+	running := p.cancel != nil
+	p.mu.Unlock()
+	if running {
+		return ErrRunning
+	}
+
+	signals, err := p.db.GetReplaySignals(ctx, cfg.start, cfg.end, cfg.Symbols)
+	if err != nil {
+		return err
+	}
+	news, err := p.db.GetReplayNews(ctx, cfg.start, cfg.end)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return ErrRunning
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	now, clock := time.Now(), cfg.start
+	p.cancel, p.done = cancel, make(chan struct{})
+	p.config, p.clock, p.startedAt, p.stoppedAt, p.lastError = &cfg, &clock, &now, nil, ""
+	p.sent = map[string]uint64{"market_tick": 0, "signal_new": 0, "signal_closed": 0, "news_article": 0}
+	p.prices, p.signals, p.news = map[string]*Price{}, signals, news
+
+	go p.run(runCtx, cfg, p.done)
+	return nil
+}
+
+// Stop ends the current replay and waits for it to finish. It reports false
+// if nothing was running.
+func (p *Player) Stop() bool {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	<-done
+	return true
+}
+
+// Status returns the current or last replay
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := Status{
+		Enabled:   p.enabled,
+		Running:   p.cancel != nil,
+		Config:    p.config,
+		StartedAt: p.startedAt,
+		StoppedAt: p.stoppedAt,
+		Sent:      map[string]uint64{},
+		LastError: p.lastError,
+	}
+	if p.clock != nil {
+		clock := *p.clock
+		s.Clock = &clock
+	}
+	for k, v := range p.sent {
+		s.Sent[k] = v
+	}
+	return s
+}
+
+// Snapshot returns prices, signals and news as of the replay clock, or nil
+// if nothing has been replayed. Signals still open at the clock show as
+// ACTIVE without their outcome.
+func (p *Player) Snapshot() *Snapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.config == nil {
+		return nil
+	}
+
+	clock := *p.clock
+	snap := &Snapshot{
+		Date:    p.config.Date,
+		Clock:   &clock,
+		Running: p.cancel != nil,
+		Prices:  make([]Price, 0, len(p.prices)),
+		Signals: []database.Signal{},
+		News:    []database.ReplayArticle{},
+	}
+	for _, price := range p.prices {
+		snap.Prices = append(snap.Prices, *price)
+	}
+	sort.Slice(snap.Prices, func(i, j int) bool { return snap.Prices[i].Symbol < snap.Prices[j].Symbol })
+	for _, s := range p.signals {
+		if s.GeneratedAt.After(clock) {
+			break
+		}
+		if s.ClosedAt == nil || s.ClosedAt.After(clock) {
+			s.Status, s.ExitPrice, s.ClosedAt, s.ActualProfitPct, s.ExitReason = "ACTIVE", nil, nil, nil, nil
+			if price, ok := p.prices[s.Symbol]; ok {
+				s.CurrentPrice = price.Price
+			}
+		}
+		snap.Signals = append(snap.Signals, s)
+	}
+	for _, a := range p.news {
+		if a.PublishedAt.After(clock) {
+			break
+		}
+		snap.News = append(snap.News, a)
+	}
+	return snap
+}
+
+// closing is a replayed signal waiting for its close time
+type closing struct {
+	at     time.Time
+	signal database.Signal
+}
+
+func (p *Player) run(ctx context.Context, cfg Config, done chan struct{}) {
+	defer func() {
+		now := time.Now()
+		p.mu.Lock()
+		p.cancel, p.stoppedAt = nil, &now
+		p.mu.Unlock()
+		close(done)
+	}()
+	log.Printf("▶️  Replaying %s %s-%s at %.0fx", cfg.Date, cfg.From, cfg.To, cfg.Speed)
+
+	p.mu.Lock()
+	signals, news := p.signals, p.news
+	p.mu.Unlock()
+	var closings []closing
+	nextSignal, nextArticle := 0, 0
+
+	chunk := time.Duration(cfg.Speed * float64(lookahead))
+	if chunk < minChunk {
+		chunk = minChunk
+	}
+	var ticks []database.ReplayTick
+	loadedTo := cfg.start
+
+	clock := cfg.start
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+	for clock.Before(cfg.end) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		clock = clock.Add(time.Duration(cfg.Speed * float64(step)))
+		if clock.After(cfg.end) {
+			clock = cfg.end
+		}
+
+		// Keep the tick buffer ahead of the clock
+		for loadedTo.Before(clock) {
+			to := loadedTo.Add(chunk)
+			if to.After(cfg.end) {
+				to = cfg.end
+			}
+			loadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			loaded, err := p.db.GetReplayTicks(loadCtx, loadedTo, to, cfg.Symbols)
+			cancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("❌ Replay stopped: %v", err)
+					p.mu.Lock()
+					p.lastError = err.Error()
+					p.mu.Unlock()
+				}
+				return
+			}
+			ticks = append(ticks, loaded...)
+			loadedTo = to
+		}
+
+		n := 0
+		for n < len(ticks) && !ticks[n].Timestamp.After(clock) {
+			p.sendTick(ticks[n])
+			n++
+		}
+		ticks = ticks[n:]
+
+		opened := false
+		for nextSignal < len(signals) && !signals[nextSignal].GeneratedAt.After(clock) {
+			s := signals[nextSignal]
+			nextSignal++
+			p.send("signal_new", signalEvent("signal.new", s, s.GeneratedAt, false))
+			if s.ClosedAt != nil && s.ClosedAt.Before(cfg.end) {
+				closings = append(closings, closing{at: *s.ClosedAt, signal: s})
+				opened = true
+			}
+		}
+		if opened {
+			sort.Slice(closings, func(i, j int) bool { return closings[i].at.Before(closings[j].at) })
+		}
+		for len(closings) > 0 && !closings[0].at.After(clock) {
+			p.send("signal_closed", signalEvent("signal.closed", closings[0].signal, closings[0].at, true))
+			closings = closings[1:]
+		}
+
+		for nextArticle < len(news) && !news[nextArticle].PublishedAt.After(clock) {
+			p.send("news_article", news[nextArticle])
+			nextArticle++
+		}
+
+		p.mu.Lock()
+		c := clock
+		p.clock = &c
+		p.mu.Unlock()
+	}
+	log.Printf("⏹️  Replay of %s finished", cfg.Date)
+}
+
+// sendTick broadcasts a recorded tick and updates the snapshot price
+func (p *Player) sendTick(t database.ReplayTick) {
+	p.mu.Lock()
+	price, ok := p.prices[t.Symbol]
+	if !ok {
+		price = &Price{Symbol: t.Symbol, Open: t.Price}
+		p.prices[t.Symbol] = price
+	}
+	price.Price, price.Volume, price.Timestamp = t.Price, t.Volume, t.Timestamp
+	if price.Open > 0 {
+		price.ChangePct = round2((t.Price - price.Open) / price.Open * 100)
+	}
+	change := price.ChangePct
+	p.mu.Unlock()
+
+	p.hub.BroadcastKeyed(t.Symbol, map[string]interface{}{
+		"type":   "market_tick",
+		"replay": true,
+		"data": schemas.Tick{
+			SchemaVersion: schemas.TickVersion,
+			EventType:     "market.tick",
+			Symbol:        t.Symbol,
+			Price:         t.Price,
+			Volume:        uint32(t.Volume),
+			ChangePct:     change,
+			Timestamp:     t.Timestamp.Format(time.RFC3339Nano),
+		},
+	})
+	p.count("market_tick")
+}
+
+// send broadcasts one replayed message of type typ
+func (p *Player) send(typ string, data interface{}) {
+	p.hub.Broadcast(map[string]interface{}{
+		"type":   typ,
+		"replay": true,
+		"data":   data,
+	})
+	p.count(typ)
+}
+
+func (p *Player) count(typ string) {
+	p.mu.Lock()
+	p.sent[typ]++
+	p.mu.Unlock()
+}
+
+// signalEvent is a recorded signal as it was announced at at; closed events
+// carry its outcome
+func signalEvent(subject string, s database.Signal, at time.Time, closed bool) schemas.Signal {
+	id, _ := strconv.Atoi(s.SignalID)
+	event := schemas.Signal{
+		SchemaVersion: schemas.SignalVersion,
+		EventType:     subject,
+		SignalID:      id,
+		Symbol:        s.Symbol,
+		SignalType:    s.SignalType,
+		EntryPrice:    s.EntryPrice,
+		StopLoss:      s.StopLoss,
+		TargetPrice:   s.TargetPrice,
+		Confidence:    s.ConfidenceScore,
+		Status:        "ACTIVE",
+		CurrentPrice:  s.EntryPrice,
+		GeneratedAt:   s.GeneratedAt.Format(time.RFC3339),
+		Timestamp:     at.Format(time.RFC3339Nano),
+	}
+	if closed {
+		event.Status = s.Status
+		if s.ExitPrice != nil {
+			event.ExitPrice, event.CurrentPrice = *s.ExitPrice, *s.ExitPrice
+		}
+		if s.ActualProfitPct != nil {
+			event.PNL = *s.ActualProfitPct
+		}
+	}
+	return event
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
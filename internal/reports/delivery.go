@@ -0,0 +1,153 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Delivery channels
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+// telegramMaxLen is the longest message the Bot API accepts, in characters
+const telegramMaxLen = 4096
+
+// Sender delivers a report to a channel-specific destination
+type Sender interface {
+	Send(ctx context.Context, destination string, r *Report) error
+}
+
+// SendersFromEnv builds a sender for each channel configured in the
+// environment. Email needs SMTP_HOST and SMTP_FROM (SMTP_PORT defaults to 587,
+// SMTP_USERNAME and SMTP_PASSWORD are optional); Telegram needs
+// TELEGRAM_BOT_TOKEN.
+func SendersFromEnv() map[string]Sender {
+	senders := map[string]Sender{}
+	if host, from := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_FROM"); host != "" && from != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		senders[ChannelEmail] = &EmailSender{
+			Addr:     net.JoinHostPort(host, port),
+			Host:     host,
+			From:     from,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		apiURL := strings.TrimRight(os.Getenv("TELEGRAM_API_URL"), "/")
+		if apiURL == "" {
+			apiURL = "https://api.telegram.org"
+		}
+		senders[ChannelTelegram] = &TelegramSender{
+			APIURL: apiURL,
+			Token:  token,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	return senders
+}
+
+// EmailSender sends reports as plain-text mail over SMTP
+type EmailSender struct {
+	Addr     string
+	Host     string
+	From     string
+	Username string
+	Password string
+}
+
+// Send mails the report to the address in destination
+func (s *EmailSender) Send(ctx context.Context, destination string, r *Report) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", destination)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", r.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(r.Text, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	// net/smtp takes no context, so give up waiting once ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Addr, auth, s.From, []string{destination}, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// TelegramSender posts reports through the Telegram Bot API
+type TelegramSender struct {
+	APIURL string
+	Token  string
+	client *http.Client
+}
+
+// Send posts the report to the chat ID or @channel in destination
+func (s *TelegramSender) Send(ctx context.Context, destination string, r *Report) error {
+	text := r.Subject + "\n\n" + r.Text
+	if runes := []rune(text); len(runes) > telegramMaxLen {
+		text = string(runes[:telegramMaxLen-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  destination,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode telegram message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.APIURL+"/bot"+s.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The request URL carries the bot token; don't let it reach logs
+		return fmt.Errorf("failed to send telegram message: %s", strings.ReplaceAll(err.Error(), s.Token, "***"))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(raw, &result); err != nil || !result.OK {
+		if result.Description == "" {
+			result.Description = resp.Status
+		}
+		return fmt.Errorf("telegram rejected message: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Package reports renders periodic plain-text reports for users and delivers
+// them by email or Telegram on the schedule of their subscriptions.
+//
+// Two reports are available:
+//
+//   - daily_summary: index levels, top movers, signal statistics and the
+//     user's portfolio holdings. Sent on weekdays.
+//   - weekly_quant: signal hit rates, one-month portfolio returns against the
+//     benchmark and the week's trading journal. Sent on one chosen weekday.
+//
+// Send times are HH:MM in IST.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Report types
+const (
+	DailySummary = "daily_summary"
+	WeeklyQuant  = "weekly_quant"
+)
+
+// Types lists the report types users can subscribe to
+var Types = []string{DailySummary, WeeklyQuant}
+
+// ValidType reports whether t is a known report type
+func ValidType(t string) bool {
+	for _, v := range Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Weekly reports whether a report type is sent once a week and so needs a weekday
+func Weekly(reportType string) bool {
+	return reportType == WeeklyQuant
+}
+
+// Report is a rendered report ready for delivery
+type Report struct {
+	Subject string
+	Text    string
+}
+
+// ParseSendTime validates an HH:MM send time
+func ParseSendTime(s string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("send_time must be HH:MM")
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// NextRun returns the first send time strictly after after. Weekly reports
+// go out on weekday (0 = Sunday); daily ones on weekdays only.
+func NextRun(reportType, sendTime string, weekday *int, after time.Time) (time.Time, error) {
+	hour, minute, err := ParseSendTime(sendTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// SessionOpen gives the IST calendar day of after
+	day := database.SessionOpen(after)
+	for i := 0; i <= 7; i++ {
+		candidate := time.Date(day.Year(), day.Month(), day.Day()+i, hour, minute, 0, 0, day.Location())
+		if !candidate.After(after) {
+			continue
+		}
+		wd := candidate.Weekday()
+		if Weekly(reportType) {
+			if weekday != nil && int(wd) == *weekday {
+				return candidate, nil
+			}
+		} else if wd != time.Saturday && wd != time.Sunday {
+			return candidate, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no send time found for %s", reportType)
+}
+
+// Source is the data reports are rendered from
+type Source interface {
+	GetMarketIndices(ctx context.Context) ([]database.MarketIndex, error)
+	GetTopGainers(ctx context.Context, limit int) ([]database.TopMover, error)
+	GetTopLosers(ctx context.Context, limit int) ([]database.TopMover, error)
+	GetSignalStats(ctx context.Context, since time.Time) (*database.DashboardStats, error)
+	ListPortfolios(ctx context.Context, userID string) ([]database.Portfolio, error)
+	GetPortfolioHoldings(ctx context.Context, portfolioID int64) (*database.PortfolioSummary, error)
+	GetPortfolioPerformance(ctx context.Context, portfolioID int64, period string) (*database.PortfolioPerformance, error)
+	GetJournalWeeklySummary(ctx context.Context, userID string, weekStart time.Time) (*database.JournalWeeklySummary, error)
+}
+
+// Render builds a report for a user as of now
+func Render(ctx context.Context, src Source, reportType, userID string, now time.Time) (*Report, error) {
+	switch reportType {
+	case DailySummary:
+		return renderDailySummary(ctx, src, userID, now)
+	case WeeklyQuant:
+		return renderWeeklyQuant(ctx, src, userID, now)
+	}
+	return nil, fmt.Errorf("unknown report type %q", reportType)
+}
+
+func renderDailySummary(ctx context.Context, src Source, userID string, now time.Time) (*Report, error) {
+	day := database.SessionOpen(now)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Daily summary for %s\n", day.Format("Mon 02 Jan 2006"))
+
+	indices, err := src.GetMarketIndices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indices: %w", err)
+	}
+	if len(indices) > 0 {
+		b.WriteString("\nIndices\n")
+		for _, idx := range indices {
+			fmt.Fprintf(&b, "  %-12s %12.2f  %+.2f%%\n", idx.Index, idx.Value, idx.ChangePercent)
+		}
+	}
+
+	gainers, err := src.GetTopGainers(ctx, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load top gainers: %w", err)
+	}
+	losers, err := src.GetTopLosers(ctx, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load top losers: %w", err)
+	}
+	writeMovers(&b, "Top gainers", gainers)
+	writeMovers(&b, "Top losers", losers)
+
+	stats, err := src.GetSignalStats(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signal statistics: %w", err)
+	}
+	writeSignalStats(&b, "Signals today", stats)
+
+	// Public summaries leave out portfolios
+	if userID != "" {
+		if err := writePortfolios(ctx, &b, src, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Report{Subject: "Daily summary " + day.Format("02 Jan 2006"), Text: b.String()}, nil
+}
+
+// RenderMarketSummary builds the daily summary without any user's
+// portfolios, for sharing publicly
+func RenderMarketSummary(ctx context.Context, src Source, now time.Time) (*Report, error) {
+	return renderDailySummary(ctx, src, "", now)
+}
+
+// writePortfolios summarises each of the user's portfolios and its biggest
+// movers
+func writePortfolios(ctx context.Context, b *strings.Builder, src Source, userID string) error {
+	portfolios, err := src.ListPortfolios(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load portfolios: %w", err)
+	}
+	if len(portfolios) > 0 {
+		b.WriteString("\nPortfolios\n")
+		for _, p := range portfolios {
+			summary, err := src.GetPortfolioHoldings(ctx, p.ID)
+			if err != nil {
+				return fmt.Errorf("failed to load holdings of portfolio %d: %w", p.ID, err)
+			}
+			fmt.Fprintf(b, "  %s: value %.2f, unrealized P&L %+.2f, total P&L %+.2f\n",
+				p.Name, summary.MarketValue, summary.UnrealizedPnL, summary.TotalPnL)
+			holdings := append([]database.Holding(nil), summary.Holdings...)
+			sort.Slice(holdings, func(i, j int) bool {
+				return abs(holdings[i].UnrealizedPnL) > abs(holdings[j].UnrealizedPnL)
+			})
+			for i, h := range holdings {
+				if i == 3 {
+					break
+				}
+				fmt.Fprintf(b, "    %-12s %+.2f (%+.2f%%)\n", h.Symbol, h.UnrealizedPnL, h.UnrealizedPnLPct)
+			}
+		}
+	}
+	return nil
+}
+
+func renderWeeklyQuant(ctx context.Context, src Source, userID string, now time.Time) (*Report, error) {
+	day := database.SessionOpen(now)
+	// Weeks start on Monday
+	offset := (int(day.Weekday()) + 6) % 7
+	weekStart := time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Weekly quant report for the week of %s\n", weekStart.Format("02 Jan 2006"))
+
+	// weekStart is a calendar date; signals are counted from that day's open
+	stats, err := src.GetSignalStats(ctx, database.SessionOpen(weekStart))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signal statistics: %w", err)
+	}
+	writeSignalStats(&b, "Signals this week", stats)
+
+	portfolios, err := src.ListPortfolios(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolios: %w", err)
+	}
+	if len(portfolios) > 0 {
+		b.WriteString("\nPortfolio returns (1M)\n")
+		for _, p := range portfolios {
+			perf, err := src.GetPortfolioPerformance(ctx, p.ID, "1M")
+			if err != nil {
+				return nil, fmt.Errorf("failed to load performance of portfolio %d: %w", p.ID, err)
+			}
+			fmt.Fprintf(&b, "  %s: TWR %+.2f%%", p.Name, perf.TimeWeightedReturn)
+			if perf.BenchmarkReturn != nil {
+				fmt.Fprintf(&b, ", %s %+.2f%%", perf.Benchmark, *perf.BenchmarkReturn)
+			}
+			if perf.ExcessReturn != nil {
+				fmt.Fprintf(&b, ", excess %+.2f%%", *perf.ExcessReturn)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	journal, err := src.GetJournalWeeklySummary(ctx, userID, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal summary: %w", err)
+	}
+	if journal.Entries > 0 {
+		b.WriteString("\nJournal\n")
+		fmt.Fprintf(&b, "  %d entries, realized P&L %+.2f (%d winning, %d losing days)\n",
+			journal.Entries, journal.RealizedPnL, journal.WinningDays, journal.LosingDays)
+		for i, m := range journal.Mistakes {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(&b, "  %-20s x%d  P&L %+.2f  %.1f%% of losses\n",
+				m.Tag, m.Occurrences, m.RealizedPnL, m.ShareOfLossesPct)
+		}
+	}
+
+	return &Report{Subject: "Weekly quant report " + weekStart.Format("02 Jan 2006"), Text: b.String()}, nil
+}
+
+func writeMovers(b *strings.Builder, title string, movers []database.TopMover) {
+	if len(movers) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s\n", title)
+	for _, m := range movers {
+		fmt.Fprintf(b, "  %-12s %10.2f  %+.2f%%\n", m.Symbol, m.Price, m.Change)
+	}
+}
+
+func writeSignalStats(b *strings.Builder, title string, s *database.DashboardStats) {
+	fmt.Fprintf(b, "\n%s\n", title)
+	fmt.Fprintf(b, "  %d total, %d active, %d hits, %d misses, %d expired\n",
+		s.TotalSignals, s.ActiveCount, s.Hits, s.Misses, s.Expired)
+	if s.SuccessRate != nil {
+		fmt.Fprintf(b, "  Success rate %.1f%%, avg profit on hit %+.2f%%, avg loss on miss %+.2f%%\n",
+			*s.SuccessRate, s.AvgProfitHit, s.AvgLossMiss)
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
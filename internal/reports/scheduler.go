@@ -0,0 +1,123 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// pollInterval is how often due subscriptions are looked for
+const pollInterval = time.Minute
+
+// Store is what the scheduler needs from the database
+type Store interface {
+	Source
+	ListDueReportSubscriptions(ctx context.Context, now time.Time) ([]database.ReportSubscription, error)
+	ClaimReportSubscription(ctx context.Context, id int64, due, next time.Time) (bool, error)
+	RecordReportDelivery(ctx context.Context, id int64, sendErr error) error
+}
+
+// Scheduler renders and delivers due reports. Each run is claimed by moving
+// the subscription's next run forward first, so several API instances can
+// run schedulers without sending a report twice. A failed delivery is
+// recorded on the subscription and not retried until its next run.
+type Scheduler struct {
+	db      Store
+	senders map[string]Sender
+}
+
+// NewScheduler creates a scheduler delivering through senders, keyed by channel
+func NewScheduler(db Store, senders map[string]Sender) *Scheduler {
+	return &Scheduler{db: db, senders: senders}
+}
+
+// Channels lists the configured delivery channels
+func (s *Scheduler) Channels() []string {
+	channels := []string{}
+	for _, ch := range []string{ChannelEmail, ChannelTelegram} {
+		if s.senders[ch] != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// Configured reports whether a delivery channel is available
+func (s *Scheduler) Configured(channel string) bool {
+	return s.senders[channel] != nil
+}
+
+// Run delivers due reports every minute until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.deliverDue(ctx, now)
+		}
+	}
+}
+
+func (s *Scheduler) deliverDue(ctx context.Context, now time.Time) {
+	listCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	due, err := s.db.ListDueReportSubscriptions(listCtx, now)
+	cancel()
+	if err != nil {
+		log.Printf("❌ Report scheduler failed to load due subscriptions: %v", err)
+		return
+	}
+
+	for _, sub := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		next, err := NextRun(sub.ReportType, sub.SendTime, sub.Weekday, now)
+		if err != nil {
+			log.Printf("❌ Report subscription %d has an invalid schedule: %v", sub.ID, err)
+			continue
+		}
+		claimed, err := s.db.ClaimReportSubscription(ctx, sub.ID, sub.NextRun(), next)
+		if err != nil {
+			log.Printf("❌ Failed to claim report subscription %d: %v", sub.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(ctx, sub, now)
+	}
+}
+
+func (s *Scheduler) deliver(ctx context.Context, sub database.ReportSubscription, now time.Time) {
+	sendErr := s.SendNow(ctx, sub, now)
+	if sendErr != nil {
+		log.Printf("⚠️  Report %s for subscription %d not delivered: %v", sub.ReportType, sub.ID, sendErr)
+	}
+	if err := s.db.RecordReportDelivery(ctx, sub.ID, sendErr); err != nil {
+		log.Printf("❌ Failed to record delivery of report subscription %d: %v", sub.ID, err)
+	}
+}
+
+// SendNow renders a subscription's report as of now and delivers it, without
+// touching its schedule
+func (s *Scheduler) SendNow(ctx context.Context, sub database.ReportSubscription, now time.Time) error {
+	sender := s.senders[sub.Channel]
+	if sender == nil {
+		return fmt.Errorf("%s delivery is not configured", sub.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	report, err := Render(ctx, s.db, sub.ReportType, sub.UserID, now)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, sub.Destination, report)
+}
@@ -0,0 +1,243 @@
+// Package retention removes old ticks, news and signals on a schedule,
+// archiving each day to object storage as Parquet before it is deleted.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/exports"
+	"github.com/trading-chitti/core-api-go/internal/storage"
+)
+
+// runInterval is how often the scheduled pass runs
+const runInterval = 24 * time.Hour
+
+// runTimeout caps a single pass, including archival uploads
+const runTimeout = 2 * time.Hour
+
+// Policy keeps a dataset for Days days. Zero days keeps it forever.
+type Policy struct {
+	Dataset string `json:"dataset"`
+	Days    int    `json:"retention_days"`
+	Archive bool   `json:"archive"`
+}
+
+// Result reports what a pass did (or would do) for one dataset
+type Result struct {
+	Dataset       string     `json:"dataset"`
+	Cutoff        time.Time  `json:"cutoff"`
+	Oldest        *time.Time `json:"oldest"`
+	EligibleRows  int64      `json:"eligible_rows"`
+	ArchivedRows  int64      `json:"archived_rows"`
+	ArchivedBytes int64      `json:"archived_bytes"`
+	ArchiveFiles  []string   `json:"archive_files"`
+	DeletedRows   int64      `json:"deleted_rows"`
+	DroppedChunks bool       `json:"dropped_chunks,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// Config holds the retention policies and scheduling mode
+type Config struct {
+	Policies []Policy `json:"policies"`
+	// Enforce makes scheduled passes delete data; otherwise they only report
+	Enforce bool `json:"enforce"`
+}
+
+// ConfigFromEnv reads RETENTION_{TICKS,NEWS,SIGNALS}_DAYS (defaults 30, 365
+// and 730), RETENTION_ARCHIVE (default true) and RETENTION_ENFORCE (default
+// false, so scheduled passes are dry runs until explicitly enabled)
+func ConfigFromEnv() Config {
+	days := func(name string, def int) int {
+		if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v >= 0 {
+			return v
+		}
+		return def
+	}
+	archive := os.Getenv("RETENTION_ARCHIVE") != "false"
+	return Config{
+		Policies: []Policy{
+			{Dataset: "ticks", Days: days("RETENTION_TICKS_DAYS", 30), Archive: archive},
+			{Dataset: "news", Days: days("RETENTION_NEWS_DAYS", 365), Archive: archive},
+			{Dataset: "signals", Days: days("RETENTION_SIGNALS_DAYS", 730), Archive: archive},
+		},
+		Enforce: os.Getenv("RETENTION_ENFORCE") == "true",
+	}
+}
+
+// Manager applies retention policies
+type Manager struct {
+	db    *database.DB
+	store storage.Store
+	cfg   Config
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewManager creates a retention manager archiving to store
+func NewManager(db *database.DB, store storage.Store, cfg Config) *Manager {
+	return &Manager{db: db, store: store, cfg: cfg}
+}
+
+// Config returns the active configuration
+func (m *Manager) Config() Config {
+	return m.cfg
+}
+
+// Running reports whether a pass is in progress
+func (m *Manager) Running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// Run executes a pass every day until ctx is cancelled. The first pass waits
+// a full interval so a restart never deletes data immediately.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(runInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Apply(ctx, !m.cfg.Enforce); err != nil {
+				log.Printf("❌ Retention: %v", err)
+			}
+		}
+	}
+}
+
+// Apply runs one pass. A dry run only counts what would be archived and
+// deleted. The pass is recorded for GET /api/system/retention.
+func (m *Manager) Apply(ctx context.Context, dryRun bool) ([]Result, error) {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("a retention pass is already running")
+	}
+	m.running = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	started := time.Now()
+	results := []Result{}
+	for _, p := range m.cfg.Policies {
+		if p.Days <= 0 {
+			continue
+		}
+		result := m.applyPolicy(ctx, p, started, dryRun)
+		if result.Error != "" {
+			log.Printf("❌ Retention %s: %s", p.Dataset, result.Error)
+		} else if !dryRun && result.DeletedRows > 0 {
+			log.Printf("✅ Retention %s: archived %d rows, deleted %d rows", p.Dataset, result.ArchivedRows, result.DeletedRows)
+		}
+		results = append(results, result)
+	}
+
+	if err := m.db.RecordRetentionRun(context.Background(), dryRun, started, time.Now(), results); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// applyPolicy archives and purges one IST day at a time so an interrupted
+// pass never deletes rows that were not archived
+func (m *Manager) applyPolicy(ctx context.Context, p Policy, now time.Time, dryRun bool) Result {
+	ist := istLocation()
+	cutoff := startOfDay(now.In(ist)).AddDate(0, 0, -p.Days)
+	result := Result{Dataset: p.Dataset, Cutoff: cutoff, ArchiveFiles: []string{}}
+
+	oldest, err := m.db.OldestRetained(ctx, p.Dataset)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Oldest = oldest
+	if oldest == nil || !oldest.Before(cutoff) {
+		return result
+	}
+
+	if result.EligibleRows, err = m.db.CountRetained(ctx, p.Dataset, *oldest, cutoff); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if dryRun {
+		return result
+	}
+
+	// A day can be archived by more than one pass, e.g. when signals still
+	// ACTIVE the first time are purged once they close, so each pass
+	// writes its own file and never replaces an earlier one
+	pass := now.UTC().Format("20060102T150405Z")
+	for day := startOfDay(oldest.In(ist)); day.Before(cutoff); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if p.Archive {
+			key := fmt.Sprintf("%s%s/%s/%s_%s_%s.parquet", storage.PrefixArchive, p.Dataset,
+				day.Format("2006/01"), p.Dataset, day.Format("20060102"), pass)
+			existing, err := m.store.List(ctx, key)
+			if err == nil && len(existing) > 0 {
+				err = fmt.Errorf("%s already exists", key)
+			}
+			if err != nil {
+				result.Error = fmt.Sprintf("archive %s: %v", day.Format("2006-01-02"), err)
+				return result
+			}
+			req := database.ExportRequest{Dataset: p.Dataset, Format: "parquet"}
+			rows, size, err := exports.Extract(ctx, m.db, m.store, key, req, day, next,
+				exports.Metadata("retention", req, day, next))
+			if err != nil {
+				result.Error = fmt.Sprintf("archive %s: %v", day.Format("2006-01-02"), err)
+				return result
+			}
+			if rows == 0 {
+				// Nothing for this day; do not leave empty files behind
+				m.store.Delete(ctx, key)
+			} else {
+				result.ArchivedRows += rows
+				result.ArchivedBytes += size
+				result.ArchiveFiles = append(result.ArchiveFiles, key)
+			}
+		}
+
+		deleted, err := m.db.PurgeRetained(ctx, p.Dataset, day, next)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.DeletedRows += deleted
+	}
+
+	// Empty chunks are left behind by row deletes on hypertables
+	if dropped, err := m.db.DropExpiredChunks(ctx, p.Dataset, cutoff); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.DroppedChunks = dropped
+	}
+	return result
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func istLocation() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		return loc
+	}
+	return time.FixedZone("IST", 5*3600+1800)
+}
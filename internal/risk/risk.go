@@ -0,0 +1,187 @@
+// Package risk takes intraday risk snapshots during market hours: open
+// exposure, the day's P&L and how much of the daily loss limit it uses.
+// Each snapshot is stored and announced over WebSocket and NATS so the
+// risk-limit engine and dashboards watch the same numbers.
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+	ws "github.com/trading-chitti/core-api-go/internal/websocket"
+)
+
+// Subject is the NATS subject snapshots are published on
+const Subject = "risk.snapshot"
+
+// Defaults for ConfigFromEnv
+const (
+	defaultInterval       = 5 * time.Minute
+	defaultDailyLossLimit = 20000
+	defaultSignalNotional = 100000
+)
+
+// bookAccountID is the account whose portfolio holdings count towards
+// snapshot exposure
+const bookAccountID = "default"
+
+// Config controls how often snapshots are taken and the limits they report
+type Config struct {
+	Interval       time.Duration `json:"-"`
+	DailyLossLimit float64       `json:"daily_loss_limit"`
+	SignalNotional float64       `json:"signal_notional"`
+}
+
+// ConfigFromEnv reads RISK_SNAPSHOT_MINUTES (default 5),
+// RISK_DAILY_LOSS_LIMIT (INR, default 20000) and RISK_SIGNAL_NOTIONAL (INR
+// per active signal, default 100000)
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Interval:       defaultInterval,
+		DailyLossLimit: defaultDailyLossLimit,
+		SignalNotional: defaultSignalNotional,
+	}
+	if v, err := strconv.Atoi(os.Getenv("RISK_SNAPSHOT_MINUTES")); err == nil && v > 0 {
+		cfg.Interval = time.Duration(v) * time.Minute
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("RISK_DAILY_LOSS_LIMIT"), 64); err == nil && v > 0 {
+		cfg.DailyLossLimit = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("RISK_SIGNAL_NOTIONAL"), 64); err == nil && v > 0 {
+		cfg.SignalNotional = v
+	}
+	return cfg
+}
+
+// Publisher publishes a raw event on a NATS subject
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Monitor takes a snapshot every interval while the market is open
+type Monitor struct {
+	db        database.RiskSnapshotRepository
+	hub       *ws.Hub
+	publisher Publisher
+	cfg       Config
+
+	mu     sync.Mutex
+	latest *database.RiskSnapshot
+}
+
+// NewMonitor creates a snapshot monitor. publisher may be nil when NATS is
+// not connected; snapshots are then only broadcast over WebSocket.
+func NewMonitor(db database.RiskSnapshotRepository, hub *ws.Hub, publisher Publisher, cfg Config) *Monitor {
+	return &Monitor{db: db, hub: hub, publisher: publisher, cfg: cfg}
+}
+
+// Config returns the monitor's configuration
+func (m *Monitor) Config() Config {
+	return m.cfg
+}
+
+// Latest returns the most recent snapshot taken by this instance, or nil
+func (m *Monitor) Latest() *database.RiskSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.latest
+}
+
+// Run takes snapshots during the session until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			open := database.SessionOpen(now)
+			if wd := open.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				continue
+			}
+			if now.Before(open) || now.After(open.Add(database.SessionMinutes*time.Minute)) {
+				continue
+			}
+			if _, err := m.Take(ctx); err != nil {
+				log.Printf("❌ Risk snapshot failed: %v", err)
+			}
+		}
+	}
+}
+
+// Take computes, stores and announces a snapshot now
+func (m *Monitor) Take(ctx context.Context) (*database.RiskSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	exposure, err := m.db.GetExposure(ctx, bookAccountID, m.cfg.SignalNotional)
+	if err != nil {
+		return nil, err
+	}
+	// Realized P&L counts signals closed since midnight IST
+	open := database.SessionOpen(now)
+	midnight := time.Date(open.Year(), open.Month(), open.Day(), 0, 0, 0, 0, open.Location())
+	unrealized, realized, err := m.db.GetSignalBookPnL(ctx, m.cfg.SignalNotional, midnight)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := m.db.InsertRiskSnapshot(ctx, database.NewRiskSnapshot(now, exposure, unrealized, realized, m.cfg.DailyLossLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.latest = snapshot
+	m.mu.Unlock()
+
+	m.announce(snapshot)
+	return snapshot, nil
+}
+
+func (m *Monitor) announce(s *database.RiskSnapshot) {
+	event := schemas.RiskSnapshot{
+		SchemaVersion:   schemas.RiskVersion,
+		EventType:       Subject,
+		OpenPositions:   s.OpenPositions,
+		GrossExposure:   s.GrossExposure,
+		NetExposure:     s.NetExposure,
+		RiskWeighted:    s.RiskWeighted,
+		UnrealizedPnL:   s.UnrealizedPnL,
+		RealizedPnL:     s.RealizedPnL,
+		DayPnL:          s.DayPnL,
+		DailyLossLimit:  s.DailyLossLimit,
+		DistanceToLimit: s.DistanceToLimit,
+		LimitUsedPct:    s.LimitUsedPct,
+		LimitBreached:   s.LimitBreached,
+		Timestamp:       s.TakenAt,
+	}
+	if s.LimitBreached {
+		log.Printf("⚠️  Daily loss limit breached: day P&L %.2f against limit %.2f", s.DayPnL, s.DailyLossLimit)
+	}
+
+	m.hub.Broadcast(map[string]interface{}{
+		"type": "risk_snapshot",
+		"data": event,
+	})
+	if m.publisher == nil {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ Failed to encode risk snapshot: %v", err)
+		return
+	}
+	if err := m.publisher.Publish(Subject, data); err != nil {
+		log.Printf("⚠️  Failed to publish %s: %v", Subject, err)
+	}
+}
@@ -0,0 +1,175 @@
+// Package scans computes opening-range-breakout, overnight gap, unusual
+// volume and circuit scans from intraday, realtime and daily data. Results
+// are cached so every trader polling a scan shares one query, and today's
+// default scans are refreshed each minute of the session so the first
+// request of the morning is already warm.
+package scans
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/coalesce"
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// DefaultRangeMinutes is the opening range used when none is requested
+const DefaultRangeMinutes = 15
+
+// liveTTL bounds staleness of scans for the current session; past sessions
+// do not change and are kept for pastTTL. Scans over md.realtime_prices
+// follow the bridge's updates more closely.
+const (
+	liveTTL     = time.Minute
+	pastTTL     = 12 * time.Hour
+	realtimeTTL = 15 * time.Second
+)
+
+type entry struct {
+	value      interface{}
+	computedAt time.Time
+	expiresAt  time.Time
+}
+
+// Service caches scan results per session date and parameters
+type Service struct {
+	db     *database.DB
+	flight coalesce.Group
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// NewService creates a scan service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db, cache: map[string]entry{}}
+}
+
+// Result is a cached scan with the time it was computed
+type Result[T any] struct {
+	Items []T
+	// Total counts all matches when Items is one page
+	Total      int
+	ComputedAt time.Time
+}
+
+// OpeningRanges returns the opening-range scan for the session on day
+func (s *Service) OpeningRanges(ctx context.Context, day time.Time, rangeMinutes int) (Result[database.OpeningRange], error) {
+	key := fmt.Sprintf("orb|%s|%d", day.Format("2006-01-02"), rangeMinutes)
+	return cached(s, ctx, key, sessionTTL(day), func(ctx context.Context) (Result[database.OpeningRange], error) {
+		items, err := s.db.GetOpeningRanges(ctx, day, rangeMinutes)
+		return Result[database.OpeningRange]{Items: items, Total: len(items)}, err
+	})
+}
+
+// Gaps returns the gap scan for the session on day
+func (s *Service) Gaps(ctx context.Context, day time.Time) (Result[database.Gap], error) {
+	key := "gaps|" + day.Format("2006-01-02")
+	return cached(s, ctx, key, sessionTTL(day), func(ctx context.Context) (Result[database.Gap], error) {
+		items, err := s.db.GetGaps(ctx, day)
+		return Result[database.Gap]{Items: items, Total: len(items)}, err
+	})
+}
+
+// UnusualVolume returns one page of the unusual volume scan
+func (s *Service) UnusualVolume(ctx context.Context, day time.Time, minRatio float64, page database.ScanPage) (Result[database.UnusualVolume], error) {
+	key := fmt.Sprintf("volume|%s|%g|%s", day.Format("2006-01-02"), minRatio, pageKey(page))
+	return cached(s, ctx, key, realtimeTTL, func(ctx context.Context) (Result[database.UnusualVolume], error) {
+		items, total, err := s.db.GetUnusualVolume(ctx, day, minRatio, page)
+		return Result[database.UnusualVolume]{Items: items, Total: total}, err
+	})
+}
+
+// Circuits returns one page of the circuit scan
+func (s *Service) Circuits(ctx context.Context, side string, page database.ScanPage) (Result[database.Circuit], error) {
+	key := fmt.Sprintf("circuits|%s|%s", side, pageKey(page))
+	return cached(s, ctx, key, realtimeTTL, func(ctx context.Context) (Result[database.Circuit], error) {
+		items, total, err := s.db.GetCircuits(ctx, side, page)
+		return Result[database.Circuit]{Items: items, Total: total}, err
+	})
+}
+
+func pageKey(p database.ScanPage) string {
+	sectors := append([]string(nil), p.Sectors...)
+	sort.Strings(sectors)
+	return fmt.Sprintf("%s|%d|%d", strings.ToUpper(strings.Join(sectors, ",")), p.Limit, p.Offset)
+}
+
+// sessionTTL is liveTTL until the session on day has closed
+func sessionTTL(day time.Time) time.Duration {
+	if time.Now().After(database.SessionOpen(day).Add(database.SessionMinutes * time.Minute)) {
+		return pastTTL
+	}
+	return liveTTL
+}
+
+func cached[T any](s *Service, ctx context.Context, key string, ttl time.Duration, fn func(context.Context) (Result[T], error)) (Result[T], error) {
+	now := time.Now()
+	s.mu.Lock()
+	e, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(e.expiresAt) {
+		return e.value.(Result[T]), nil
+	}
+
+	// The query outlives any one caller so a cancelled request does not
+	// fail the others waiting on it
+	return coalesce.Do(&s.flight, key, func() (Result[T], error) {
+		qctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		r, err := fn(qctx)
+		if err != nil {
+			return Result[T]{}, err
+		}
+
+		computed := time.Now()
+		r.ComputedAt = computed
+		s.store(key, entry{value: r, computedAt: computed, expiresAt: computed.Add(ttl)})
+		return r, nil
+	})
+}
+
+func (s *Service) store(key string, e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, old := range s.cache {
+		if e.computedAt.After(old.expiresAt) {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = e
+}
+
+// Run refreshes today's default scans every minute while the session is
+// open until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(liveTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			open := database.SessionOpen(now)
+			if wd := open.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				continue
+			}
+			if now.Before(open.Add(time.Minute)) || now.After(open.Add((database.SessionMinutes+1)*time.Minute)) {
+				continue
+			}
+			if _, err := s.Gaps(ctx, now); err != nil {
+				log.Printf("❌ Gap scan refresh failed: %v", err)
+			}
+			if now.After(open.Add(DefaultRangeMinutes * time.Minute)) {
+				if _, err := s.OpeningRanges(ctx, now, DefaultRangeMinutes); err != nil {
+					log.Printf("❌ Opening range scan refresh failed: %v", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// Decoder decodes event payloads tolerantly: unknown fields are ignored and
+// payloads from a newer schema version are still decoded, but both are
+// counted so producer drift shows up in metrics rather than as silent data
+// loss.
+type Decoder struct {
+	mu       sync.Mutex
+	subjects map[string]*SubjectStats
+}
+
+// SubjectStats counts decode outcomes for one subject
+type SubjectStats struct {
+	Subject string `json:"subject"`
+	Decoded uint64 `json:"decoded"`
+	Failed  uint64 `json:"failed"`
+	Legacy  uint64 `json:"legacy"`
+	// UnknownVersion counts payloads newer than this service understands
+	UnknownVersion  uint64         `json:"unknown_version"`
+	MissingRequired uint64         `json:"missing_required"`
+	Versions        map[int]uint64 `json:"versions"`
+	LastWarning     string         `json:"last_warning,omitempty"`
+}
+
+// NewDecoder creates a new decoder
+func NewDecoder() *Decoder {
+	return &Decoder{subjects: make(map[string]*SubjectStats)}
+}
+
+// Decode unmarshals data received on subject into v. It only returns an
+// error when the payload is not valid JSON for v.
+func (d *Decoder) Decode(subject string, data []byte, v interface{}) error {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		d.record(subject, func(s *SubjectStats) { s.Failed++ })
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		d.record(subject, func(s *SubjectStats) { s.Failed++ })
+		return err
+	}
+
+	version := 1
+	legacy := true
+	if raw, ok := probe[VersionField]; ok {
+		if err := json.Unmarshal(raw, &version); err == nil {
+			legacy = false
+		}
+	}
+
+	def, known := ForSubject(subject)
+	var missing []string
+	if known {
+		for _, field := range def.Required {
+			if _, ok := probe[field]; !ok {
+				missing = append(missing, field)
+			}
+		}
+	}
+
+	d.record(subject, func(s *SubjectStats) {
+		s.Decoded++
+		s.Versions[version]++
+		if legacy {
+			s.Legacy++
+		}
+		if known && version > def.Version {
+			s.UnknownVersion++
+			warning := fmt.Sprintf("%s: schema_version %d is newer than supported %d", subject, version, def.Version)
+			if s.LastWarning != warning {
+				log.Printf("⚠️  %s, decoding known fields only", warning)
+			}
+			s.LastWarning = warning
+		}
+		if len(missing) > 0 {
+			s.MissingRequired++
+			s.LastWarning = fmt.Sprintf("%s: missing required fields %v", subject, missing)
+		}
+	})
+	return nil
+}
+
+func (d *Decoder) record(subject string, fn func(*SubjectStats)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := d.subjects[subject]
+	if !ok {
+		s = &SubjectStats{Subject: subject, Versions: make(map[int]uint64)}
+		d.subjects[subject] = s
+	}
+	fn(s)
+}
+
+// Stats returns decode counters per subject, sorted by subject
+func (d *Decoder) Stats() []SubjectStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := make([]SubjectStats, 0, len(d.subjects))
+	for _, s := range d.subjects {
+		cp := *s
+		cp.Versions = make(map[int]uint64, len(s.Versions))
+		for k, v := range s.Versions {
+			cp.Versions[k] = v
+		}
+		stats = append(stats, cp)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Subject < stats[j].Subject })
+	return stats
+}
@@ -0,0 +1,54 @@
+package schemas
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchema renders the definition as a JSON Schema (draft 2020-12)
+// document. It is generated from the Go type so it cannot drift from what
+// this service decodes.
+func (d Definition) JSONSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < d.typ.NumField(); i++ {
+		f := d.typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		prop := map[string]interface{}{"type": jsonType(f.Type.Kind())}
+		if name == VersionField {
+			prop["const"] = d.Version
+		}
+		properties[name] = prop
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"$id":                  "/api/events/schemas/" + d.Name,
+		"title":                d.Name,
+		"description":          d.Description,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             d.Required,
+		"additionalProperties": true,
+	}
+}
+
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,190 @@
+// Package schemas holds the versioned definitions of events exchanged over
+// NATS. Producers and consumers in every service should decode through these
+// types instead of keeping their own copies.
+package schemas
+
+import (
+	"reflect"
+	"sort"
+)
+
+// VersionField is the payload key carrying the schema version. Payloads
+// without it are treated as version 1, which predates the field.
+const VersionField = "schema_version"
+
+// Current event schema versions
+const (
+	SignalVersion = 2
+	TickVersion   = 1
+	RegimeVersion = 1
+	RiskVersion   = 1
+)
+
+// Signal is published on signal.new, signal.updated and signal.closed
+type Signal struct {
+	SchemaVersion int     `json:"schema_version,omitempty"`
+	EventType     string  `json:"event_type"`
+	SignalID      int     `json:"signal_id"`
+	Symbol        string  `json:"symbol"`
+	SignalType    string  `json:"signal_type"`
+	EntryPrice    float64 `json:"entry_price"`
+	StopLoss      float64 `json:"stop_loss"`
+	TargetPrice   float64 `json:"target_price"`
+	Confidence    float64 `json:"confidence"`
+	Status        string  `json:"status"`
+	CurrentPrice  float64 `json:"current_price"`
+	ExitPrice     float64 `json:"exit_price"`
+	PNL           float64 `json:"pnl"`
+	GeneratedAt   string  `json:"generated_at"`
+	Timestamp     string  `json:"timestamp"`
+
+	// Added in version 2: optional time-in-force and staged exits
+	TimeInForce  string        `json:"time_in_force,omitempty"`
+	ExpiresAt    string        `json:"expires_at,omitempty"`
+	ScalingRules *ScalingRules `json:"scaling_rules,omitempty"`
+}
+
+// ScalingStep books part of the position when price reaches a level
+type ScalingStep struct {
+	// Level names the step for display, e.g. T1
+	Level string  `json:"level"`
+	Price float64 `json:"price"`
+	// ExitPct is the share of the original quantity booked, in percent
+	ExitPct float64 `json:"exit_pct"`
+	// MoveStopTo optionally moves the stop after this step: "entry" for
+	// breakeven or a price
+	MoveStopTo string `json:"move_stop_to,omitempty"`
+}
+
+// TrailRule trails a stop behind price for the quantity left after the steps
+type TrailRule struct {
+	// TrailPct is the stop distance from the best price seen, in percent
+	TrailPct float64 `json:"trail_pct"`
+	// ActivateAt names the step after which trailing starts; empty means
+	// after the last step
+	ActivateAt string `json:"activate_at,omitempty"`
+}
+
+// ScalingRules describe a staged exit, e.g. book 50% at T1 and trail the rest
+type ScalingRules struct {
+	Steps []ScalingStep `json:"steps"`
+	Trail *TrailRule    `json:"trail,omitempty"`
+}
+
+// Tick is published on market.tick
+type Tick struct {
+	SchemaVersion int     `json:"schema_version,omitempty"`
+	EventType     string  `json:"event_type"`
+	Symbol        string  `json:"symbol"`
+	Price         float64 `json:"price"`
+	Volume        uint32  `json:"volume"`
+	ChangePct     float64 `json:"change_pct"`
+	Timestamp     string  `json:"timestamp"`
+}
+
+// Regime is published on regime.changed when the market regime flips
+type Regime struct {
+	SchemaVersion int     `json:"schema_version,omitempty"`
+	EventType     string  `json:"event_type"`
+	Index         string  `json:"index"`
+	Regime        string  `json:"regime"`
+	Previous      string  `json:"previous"`
+	Rule          string  `json:"rule"`
+	ChangePct     float64 `json:"change_pct"`
+	Efficiency    float64 `json:"efficiency"`
+	VolatilityPct float64 `json:"volatility_pct"`
+	Timestamp     string  `json:"timestamp"`
+}
+
+// RiskSnapshot is published on risk.snapshot during market hours
+type RiskSnapshot struct {
+	SchemaVersion   int     `json:"schema_version,omitempty"`
+	EventType       string  `json:"event_type"`
+	OpenPositions   int     `json:"open_positions"`
+	GrossExposure   float64 `json:"gross_exposure"`
+	NetExposure     float64 `json:"net_exposure"`
+	RiskWeighted    float64 `json:"risk_weighted"`
+	UnrealizedPnL   float64 `json:"unrealized_pnl"`
+	RealizedPnL     float64 `json:"realized_pnl"`
+	DayPnL          float64 `json:"day_pnl"`
+	DailyLossLimit  float64 `json:"daily_loss_limit"`
+	DistanceToLimit float64 `json:"distance_to_limit"`
+	LimitUsedPct    float64 `json:"limit_used_pct"`
+	LimitBreached   bool    `json:"limit_breached"`
+	Timestamp       string  `json:"timestamp"`
+}
+
+// Definition describes one event type in the registry
+type Definition struct {
+	Name        string   `json:"name"`
+	Version     int      `json:"version"`
+	Subjects    []string `json:"subjects"`
+	Description string   `json:"description"`
+	// Required fields must be present in every payload of this version
+	Required []string     `json:"required"`
+	typ      reflect.Type `json:"-"`
+}
+
+var registry = []Definition{
+	{
+		Name:        "signal",
+		Version:     SignalVersion,
+		Subjects:    []string{"signal.new", "signal.updated", "signal.closed"},
+		Description: "Trading signal lifecycle event from the intraday engine",
+		Required:    []string{"event_type", "signal_id", "symbol", "status"},
+		typ:         reflect.TypeOf(Signal{}),
+	},
+	{
+		Name:        "tick",
+		Version:     TickVersion,
+		Subjects:    []string{"market.tick"},
+		Description: "Last traded price update from the market data bridge",
+		Required:    []string{"symbol", "price"},
+		typ:         reflect.TypeOf(Tick{}),
+	},
+	{
+		Name:        "regime",
+		Version:     RegimeVersion,
+		Subjects:    []string{"regime.changed"},
+		Description: "Intraday market regime change classified by core-api from index bars",
+		Required:    []string{"index", "regime", "previous"},
+		typ:         reflect.TypeOf(Regime{}),
+	},
+	{
+		Name:        "risk",
+		Version:     RiskVersion,
+		Subjects:    []string{"risk.snapshot"},
+		Description: "Intraday exposure, P&L and daily loss limit usage computed by core-api",
+		Required:    []string{"gross_exposure", "day_pnl", "daily_loss_limit"},
+		typ:         reflect.TypeOf(RiskSnapshot{}),
+	},
+}
+
+// Definitions returns every registered event type, sorted by name
+func Definitions() []Definition {
+	defs := append([]Definition(nil), registry...)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Lookup returns the definition with the given name
+func Lookup(name string) (Definition, bool) {
+	for _, d := range registry {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// ForSubject returns the definition published on subject
+func ForSubject(subject string) (Definition, bool) {
+	for _, d := range registry {
+		for _, s := range d.Subjects {
+			if s == subject {
+				return d, true
+			}
+		}
+	}
+	return Definition{}, false
+}
@@ -0,0 +1,173 @@
+package sentiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Labels a scorer may return
+const (
+	Positive = "positive"
+	Negative = "negative"
+	Neutral  = "neutral"
+)
+
+// Scorer assigns sentiment to a batch of articles. Articles missing from
+// the result are retried later.
+type Scorer interface {
+	Name() string
+	Score(ctx context.Context, tasks []database.SentimentTask) ([]database.SentimentScore, error)
+}
+
+// HTTPScorer posts batches to an external model service:
+//
+//	request:  {"articles": [{"id": "...", "title": "...", "summary": "..."}]}
+//	response: {"results": [{"id": "...", "label": "positive", "confidence": 0.87}]}
+type HTTPScorer struct {
+	URL    string
+	Token  string
+	client *http.Client
+}
+
+// Name identifies the backend
+func (s *HTTPScorer) Name() string {
+	return "http"
+}
+
+// Score sends the batch and validates the reply
+func (s *HTTPScorer) Score(ctx context.Context, tasks []database.SentimentTask) ([]database.SentimentScore, error) {
+	type article struct {
+		ID      string `json:"id"`
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+	}
+	articles := make([]article, len(tasks))
+	for i, t := range tasks {
+		articles[i] = article{ID: t.ArticleID, Title: t.Title, Summary: t.Summary}
+	}
+	body, err := json.Marshal(map[string]interface{}{"articles": articles})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scoring request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scoring request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scoring request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("scoring backend returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Results []struct {
+			ID         string  `json:"id"`
+			Label      string  `json:"label"`
+			Confidence float64 `json:"confidence"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode scoring response: %w", err)
+	}
+
+	requested := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		requested[t.ArticleID] = true
+	}
+	scores := make([]database.SentimentScore, 0, len(out.Results))
+	for _, r := range out.Results {
+		label := strings.ToLower(strings.TrimSpace(r.Label))
+		if !requested[r.ID] || !validLabel(label) || r.Confidence < 0 || r.Confidence > 1 {
+			continue
+		}
+		scores = append(scores, database.SentimentScore{ArticleID: r.ID, Label: label, Confidence: r.Confidence})
+	}
+	return scores, nil
+}
+
+func validLabel(label string) bool {
+	return label == Positive || label == Negative || label == Neutral
+}
+
+// LexiconScorer is a local, dependency-free fallback that counts financial
+// polarity words in the headline and summary. It's much cruder than a
+// model, so its confidence never exceeds 0.75.
+type LexiconScorer struct{}
+
+// Name identifies the backend
+func (LexiconScorer) Name() string {
+	return "lexicon"
+}
+
+var lexiconWord = regexp.MustCompile(`[a-z]+(?:-[a-z]+)?`)
+
+var positiveWords = wordSet(`beat beats bullish buy buyback dividend expand expands expansion gain gains
+	growth grow grows high higher jump jumps order orders outperform profit profits rally rallies record
+	recovery rise rises rising strong surge surges up upgrade upgraded upside win wins`)
+
+var negativeWords = wordSet(`bearish concern concerns crash cut cuts decline declines default delay
+	downgrade downgraded drop drops fall falls fine fraud lawsuit loss losses low lower miss misses
+	penalty plunge plunges probe resign resigns sell-off slump slumps weak warning weakness`)
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// Score labels each article by its balance of polarity words
+func (LexiconScorer) Score(_ context.Context, tasks []database.SentimentTask) ([]database.SentimentScore, error) {
+	scores := make([]database.SentimentScore, len(tasks))
+	for i, t := range tasks {
+		pos, neg := 0, 0
+		// The headline carries most of the signal, so it counts twice
+		text := strings.ToLower(t.Title + " " + t.Title + " " + t.Summary)
+		for _, w := range lexiconWord.FindAllString(text, -1) {
+			switch {
+			case positiveWords[w]:
+				pos++
+			case negativeWords[w]:
+				neg++
+			}
+		}
+
+		score := database.SentimentScore{ArticleID: t.ArticleID, Label: Neutral, Confidence: 0.5}
+		if total := pos + neg; total > 0 {
+			balance := float64(pos-neg) / float64(total)
+			switch {
+			case balance > 0.2:
+				score.Label = Positive
+			case balance < -0.2:
+				score.Label = Negative
+			}
+			if score.Label != Neutral {
+				if balance < 0 {
+					balance = -balance
+				}
+				score.Confidence = 0.5 + 0.25*balance
+			}
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
@@ -0,0 +1,301 @@
+// Package sentiment scores news articles that have no LLM sentiment yet.
+//
+// Unscored articles are queued in core_api.sentiment_queue and sent in
+// batches to a scoring backend chosen with SENTIMENT_BACKEND:
+//
+//   - http: an external model service at SENTIMENT_HTTP_URL
+//   - lexicon: a built-in word-list scorer, for dev or as a stopgap
+//   - off (default): nothing is queued or scored
+//
+// Results are written back to news.articles. Failed batches are retried
+// with backoff up to maxAttempts times.
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+const (
+	// maxAttempts is how often an article is tried before it's given up on
+	maxAttempts = 5
+	// lease keeps a claimed batch from being claimed again while it's scored
+	lease = 5 * time.Minute
+	// pollInterval is how often the queue is drained
+	pollInterval = 15 * time.Second
+	// enqueueInterval is how often new unscored articles are queued
+	enqueueInterval = time.Minute
+	// throughputWindow is how much per-minute history is kept
+	throughputWindow = 60
+)
+
+// Config selects the backend and batching
+type Config struct {
+	Backend   string        `json:"backend"`
+	HTTPURL   string        `json:"http_url,omitempty"`
+	BatchSize int           `json:"batch_size"`
+	Lookback  time.Duration `json:"lookback"`
+	httpToken string
+}
+
+// ConfigFromEnv reads SENTIMENT_BACKEND, SENTIMENT_HTTP_URL,
+// SENTIMENT_HTTP_TOKEN, SENTIMENT_BATCH_SIZE and SENTIMENT_LOOKBACK_HOURS
+func ConfigFromEnv() Config {
+	cfg := Config{Backend: "off", BatchSize: 20, Lookback: 48 * time.Hour}
+	switch b := strings.ToLower(os.Getenv("SENTIMENT_BACKEND")); b {
+	case "off", "http", "lexicon":
+		cfg.Backend = b
+	case "":
+	default:
+		log.Printf("⚠️  Unknown SENTIMENT_BACKEND=%q, using %s", b, cfg.Backend)
+	}
+	cfg.HTTPURL = os.Getenv("SENTIMENT_HTTP_URL")
+	cfg.httpToken = os.Getenv("SENTIMENT_HTTP_TOKEN")
+	if cfg.Backend == "http" && cfg.HTTPURL == "" {
+		log.Println("⚠️  SENTIMENT_BACKEND=http needs SENTIMENT_HTTP_URL, scoring disabled")
+		cfg.Backend = "off"
+	}
+	if v, err := strconv.Atoi(os.Getenv("SENTIMENT_BATCH_SIZE")); err == nil && v > 0 && v <= 200 {
+		cfg.BatchSize = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SENTIMENT_LOOKBACK_HOURS")); err == nil && v > 0 {
+		cfg.Lookback = time.Duration(v) * time.Hour
+	}
+	return cfg
+}
+
+// minute tallies one minute of scoring
+type minute struct {
+	start  time.Time
+	scored int
+	failed int
+}
+
+// Stats is the pipeline's state as shown at /api/monitoring/sentiment
+type Stats struct {
+	Enabled     bool                          `json:"enabled"`
+	Backend     string                        `json:"backend"`
+	BatchSize   int                           `json:"batch_size"`
+	MaxAttempts int                           `json:"max_attempts"`
+	Queue       *database.SentimentQueueStats `json:"queue"`
+	Throughput  Throughput                    `json:"throughput"`
+	LastBatchAt *string                       `json:"last_batch_at"`
+	LastError   *string                       `json:"last_error"`
+}
+
+// Throughput counts articles scored and failed since start and recently
+type Throughput struct {
+	ScoredTotal      uint64  `json:"scored_total"`
+	FailedTotal      uint64  `json:"failed_total"`
+	ScoredLastMinute int     `json:"scored_last_minute"`
+	ScoredLastHour   int     `json:"scored_last_hour"`
+	FailedLastHour   int     `json:"failed_last_hour"`
+	AvgBatchMs       float64 `json:"avg_batch_ms"`
+}
+
+// Pipeline queues and scores articles
+type Pipeline struct {
+	db     database.SentimentQueueRepository
+	cfg    Config
+	scorer Scorer
+
+	mu          sync.Mutex
+	minutes     []minute
+	scored      uint64
+	failed      uint64
+	batches     uint64
+	batchTime   time.Duration
+	lastBatchAt time.Time
+	lastError   string
+}
+
+// NewPipeline creates a pipeline for cfg's backend
+func NewPipeline(db database.SentimentQueueRepository, cfg Config) *Pipeline {
+	p := &Pipeline{db: db, cfg: cfg}
+	switch cfg.Backend {
+	case "http":
+		p.scorer = &HTTPScorer{URL: cfg.HTTPURL, Token: cfg.httpToken, client: &http.Client{Timeout: 60 * time.Second}}
+	case "lexicon":
+		p.scorer = LexiconScorer{}
+	}
+	return p
+}
+
+// Enabled reports whether a backend is configured
+func (p *Pipeline) Enabled() bool {
+	return p.scorer != nil
+}
+
+// Run queues and scores articles until ctx is cancelled. It returns
+// immediately when scoring is off.
+func (p *Pipeline) Run(ctx context.Context) {
+	if !p.Enabled() {
+		return
+	}
+	log.Printf("✅ Sentiment scoring enabled (%s backend, batches of %d)", p.scorer.Name(), p.cfg.BatchSize)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	var lastEnqueue time.Time
+	for {
+		if time.Since(lastEnqueue) >= enqueueInterval {
+			if n, err := p.db.EnqueueUnscoredArticles(ctx, time.Now().Add(-p.cfg.Lookback)); err != nil {
+				log.Printf("⚠️  Sentiment enqueue failed: %v", err)
+			} else {
+				lastEnqueue = time.Now()
+				if n > 0 {
+					log.Printf("✅ Queued %d articles for sentiment scoring", n)
+				}
+			}
+		}
+		p.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain scores batches until the queue has nothing ready or a batch fails
+func (p *Pipeline) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		tasks, err := p.db.ClaimSentimentTasks(ctx, p.cfg.BatchSize, maxAttempts, lease)
+		if err != nil {
+			log.Printf("⚠️  Sentiment queue unavailable: %v", err)
+			return
+		}
+		if len(tasks) == 0 || !p.scoreBatch(ctx, tasks) {
+			return
+		}
+	}
+}
+
+// scoreBatch scores one batch, returning false if it failed outright
+func (p *Pipeline) scoreBatch(ctx context.Context, tasks []database.SentimentTask) bool {
+	start := time.Now()
+	scores, err := p.scorer.Score(ctx, tasks)
+	if err == nil {
+		err = p.db.SaveSentimentScores(ctx, scores)
+	}
+	elapsed := time.Since(start)
+
+	// Articles the backend skipped, or the whole batch on error, back off
+	scoredIDs := make(map[string]bool, len(scores))
+	if err == nil {
+		for _, s := range scores {
+			scoredIDs[s.ArticleID] = true
+		}
+	}
+	var retry []string
+	attempts := 0
+	for _, t := range tasks {
+		if !scoredIDs[t.ArticleID] {
+			retry = append(retry, t.ArticleID)
+			if t.Attempts > attempts {
+				attempts = t.Attempts
+			}
+		}
+	}
+	if len(retry) > 0 {
+		cause := err
+		if cause == nil {
+			cause = errSkipped
+		}
+		backoff := time.Duration(attempts*attempts) * time.Minute
+		if ferr := p.db.FailSentimentTasks(ctx, retry, backoff, cause); ferr != nil {
+			log.Printf("⚠️  %v", ferr)
+		}
+	}
+
+	p.record(start, len(scoredIDs), len(retry), elapsed, err)
+	if err != nil {
+		log.Printf("⚠️  Sentiment batch of %d failed: %v", len(tasks), err)
+		return false
+	}
+	return true
+}
+
+// errSkipped is recorded against articles the backend left out of its reply
+var errSkipped = errors.New("not scored by backend")
+
+func (p *Pipeline) record(at time.Time, scored, failed int, elapsed time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bucket := at.Truncate(time.Minute)
+	if n := len(p.minutes); n == 0 || !p.minutes[n-1].start.Equal(bucket) {
+		p.minutes = append(p.minutes, minute{start: bucket})
+		if len(p.minutes) > throughputWindow {
+			p.minutes = p.minutes[len(p.minutes)-throughputWindow:]
+		}
+	}
+	m := &p.minutes[len(p.minutes)-1]
+	m.scored += scored
+	m.failed += failed
+
+	p.scored += uint64(scored)
+	p.failed += uint64(failed)
+	p.batches++
+	p.batchTime += elapsed
+	p.lastBatchAt = at
+	if err != nil {
+		p.lastError = err.Error()
+	} else {
+		p.lastError = ""
+	}
+}
+
+// Stats reports queue depth and throughput
+func (p *Pipeline) Stats(ctx context.Context) (*Stats, error) {
+	queue, err := p.db.GetSentimentQueueStats(ctx, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stats{
+		Enabled:     p.Enabled(),
+		Backend:     p.cfg.Backend,
+		BatchSize:   p.cfg.BatchSize,
+		MaxAttempts: maxAttempts,
+		Queue:       queue,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, m := range p.minutes {
+		if now.Sub(m.start) < time.Hour {
+			s.Throughput.ScoredLastHour += m.scored
+			s.Throughput.FailedLastHour += m.failed
+		}
+		// The last full minute, as the current one is still filling
+		if m.start.Equal(now.Truncate(time.Minute).Add(-time.Minute)) {
+			s.Throughput.ScoredLastMinute = m.scored
+		}
+	}
+	s.Throughput.ScoredTotal = p.scored
+	s.Throughput.FailedTotal = p.failed
+	if p.batches > 0 {
+		s.Throughput.AvgBatchMs = float64(p.batchTime.Milliseconds()) / float64(p.batches)
+	}
+	if !p.lastBatchAt.IsZero() {
+		t := p.lastBatchAt.Format(time.RFC3339)
+		s.LastBatchAt = &t
+	}
+	if p.lastError != "" {
+		e := p.lastError
+		s.LastError = &e
+	}
+	return s, nil
+}
@@ -0,0 +1,162 @@
+// Package serviceauth identifies other trading-chitti services calling
+// core-api's internal endpoints. It is separate from user auth: a service
+// sends a token signed with SERVICE_AUTH_SECRET in X-Service-Token, and what
+// it may do is granted per service by SERVICE_PERMISSIONS, so a leaked
+// token is limited to its own service's permissions and expiry.
+package serviceauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries a service token
+const Header = "X-Service-Token"
+
+// Permissions a service can be granted
+const (
+	PricesWrite  = "prices:write"
+	JobsRun      = "jobs:run"
+	CommandsSend = "commands:send"
+	StorageWrite = "storage:write"
+)
+
+// Permissions lists every permission
+var Permissions = []string{PricesWrite, JobsRun, CommandsSend, StorageWrite}
+
+// namePattern keeps service names short and free of the token separator
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Authenticator issues and verifies service tokens
+type Authenticator struct {
+	secret []byte
+	grants map[string]map[string]bool
+}
+
+// New creates an authenticator. grants maps each service to its
+// permissions; services not listed may authenticate but do nothing.
+func New(secret string, grants map[string][]string) *Authenticator {
+	a := &Authenticator{secret: []byte(secret), grants: map[string]map[string]bool{}}
+	for service, perms := range grants {
+		a.grants[service] = map[string]bool{}
+		for _, p := range perms {
+			a.grants[service][p] = true
+		}
+	}
+	return a
+}
+
+// FromEnv reads SERVICE_AUTH_SECRET and SERVICE_PERMISSIONS, which looks
+// like "market-bridge=prices:write;scheduler=jobs:run,commands:send".
+// Without a secret internal endpoints refuse every service.
+func FromEnv() (*Authenticator, error) {
+	grants, err := ParseGrants(os.Getenv("SERVICE_PERMISSIONS"))
+	if err != nil {
+		return nil, err
+	}
+	secret := os.Getenv("SERVICE_AUTH_SECRET")
+	if secret == "" {
+		log.Printf("⚠️  SERVICE_AUTH_SECRET not set, internal endpoints are disabled")
+	}
+	return New(secret, grants), nil
+}
+
+// ParseGrants parses a SERVICE_PERMISSIONS value
+func ParseGrants(s string) (map[string][]string, error) {
+	known := map[string]bool{}
+	for _, p := range Permissions {
+		known[p] = true
+	}
+	grants := map[string][]string{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, perms, ok := strings.Cut(entry, "=")
+		service = strings.TrimSpace(service)
+		if !ok || !namePattern.MatchString(service) {
+			return nil, fmt.Errorf("SERVICE_PERMISSIONS: invalid entry %q", entry)
+		}
+		for _, p := range strings.Split(perms, ",") {
+			p = strings.TrimSpace(p)
+			if !known[p] {
+				return nil, fmt.Errorf("SERVICE_PERMISSIONS: unknown permission %q for %s", p, service)
+			}
+			grants[service] = append(grants[service], p)
+		}
+	}
+	return grants, nil
+}
+
+// Services returns how many services have been granted permissions
+func (a *Authenticator) Services() int {
+	return len(a.grants)
+}
+
+// Enabled reports whether tokens can be verified
+func (a *Authenticator) Enabled() bool {
+	return len(a.secret) > 0
+}
+
+// Token returns a token identifying service until expires
+func (a *Authenticator) Token(service string, expires time.Time) (string, error) {
+	if !a.Enabled() {
+		return "", fmt.Errorf("SERVICE_AUTH_SECRET is not set")
+	}
+	if !namePattern.MatchString(service) {
+		return "", fmt.Errorf("invalid service name %q: up to 32 lowercase letters, digits or -", service)
+	}
+	payload := fmt.Sprintf("%s.%d", service, expires.Unix())
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(a.sign(payload)), nil
+}
+
+// Verify checks a token, returning the service it identifies if it's
+// authentic and not expired
+func (a *Authenticator) Verify(token string, now time.Time) (string, bool) {
+	if !a.Enabled() {
+		return "", false
+	}
+	enc := base64.RawURLEncoding
+	encPayload, encSig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", false
+	}
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return "", false
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, a.sign(string(payload))) {
+		return "", false
+	}
+	service, expPart, ok := strings.Cut(string(payload), ".")
+	if !ok || !namePattern.MatchString(service) {
+		return "", false
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", false
+	}
+	return service, true
+}
+
+// Allowed reports whether service has been granted permission
+func (a *Authenticator) Allowed(service, permission string) bool {
+	return a.grants[service][permission]
+}
+
+func (a *Authenticator) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte("service:" + payload))
+	return mac.Sum(nil)
+}
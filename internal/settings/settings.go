@@ -0,0 +1,555 @@
+// Package settings is a registry of typed runtime settings kept in
+// md.system_config. Every key has a schema giving its type (bool, int,
+// string or json), default, bounds and description. Values are validated
+// before they're written and read back typed, and each change is announced
+// on NATS and to in-process hooks.
+//
+// Values are stored in the same text form the older handlers wrote, so
+// services still reading md.system_config directly see no difference.
+//
+// Live settings, such as the CORS allowlist, cache TTLs and the WebSocket
+// tick throttle, are applied while the service runs by a Watcher, so
+// changing them needs no restart.
+package settings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/newsbrief"
+	"github.com/trading-chitti/core-api-go/internal/notifications"
+)
+
+// Setting types
+const (
+	TypeBool   = "bool"
+	TypeInt    = "int"
+	TypeString = "string"
+	TypeJSON   = "json"
+)
+
+// Subject carries setting changes on NATS
+const Subject = "config.setting.changed"
+
+// Keys of the settings other code reads through the registry
+const (
+	KeySmartSelectionEnabled = "smart_stock_selection_enabled"
+	KeySmartSelectionCount   = "smart_selection_stock_count"
+	KeyCORSOrigins           = "cors_allowed_origins"
+	KeyWidgetCacheTTL        = "widget_cache_ttl_seconds"
+	KeyCalendarCacheTTL      = "signal_calendar_cache_ttl_seconds"
+	KeyTickInterval          = "ws_tick_min_interval_ms"
+	KeyMutedNotifications    = "notifications_muted_kinds"
+	KeyQuotaAPICalls         = "quota_api_calls_per_day"
+	KeyQuotaWSMessages       = "quota_ws_messages_per_day"
+	KeyQuotaExportMB         = "quota_export_mb_per_day"
+)
+
+// masked stands in for a secret value that is set
+const masked = `"********"`
+
+var (
+	// ErrUnknown is returned for keys without a schema
+	ErrUnknown = errors.New("unknown setting")
+	// ErrInvalid wraps the reason a value was rejected
+	ErrInvalid = errors.New("invalid setting value")
+)
+
+// Schema describes one setting
+type Schema struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Default applies while no value is stored, as JSON
+	Default json.RawMessage `json:"default"`
+	// Min and Max bound an int setting
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+	// Enum lists the values a string setting may take besides empty
+	Enum []string `json:"enum,omitempty"`
+	// Secret values are never returned or announced, only whether one is set
+	Secret bool `json:"secret,omitempty"`
+	// Live settings are applied by the Watcher while the service runs;
+	// others are read where they're used or need a restart
+	Live bool `json:"live"`
+	// Check, if set, further validates a json setting
+	Check func(json.RawMessage) error `json:"-"`
+}
+
+func int64p(v int64) *int64 {
+	return &v
+}
+
+// builtin are the settings known to this service
+var builtin = []Schema{
+	{
+		Key:         KeySmartSelectionEnabled,
+		Type:        TypeBool,
+		Description: "Enable ML-based stock selection",
+		Default:     json.RawMessage(`false`),
+	},
+	{
+		Key:         KeySmartSelectionCount,
+		Type:        TypeInt,
+		Description: "Number of stocks to select in Smart Mode (split equally between fetchers)",
+		Default:     json.RawMessage(`200`),
+		Min:         int64p(10),
+		Max:         int64p(2000),
+	},
+	{
+		Key:         newsbrief.ConfigProvider,
+		Type:        TypeString,
+		Description: "LLM provider for news briefs; empty disables them",
+		Default:     json.RawMessage(`""`),
+		Enum:        []string{newsbrief.ProviderOpenAI, newsbrief.ProviderAnthropic},
+	},
+	{
+		Key:         newsbrief.ConfigAPIKey,
+		Type:        TypeString,
+		Description: "API key for the news brief LLM provider",
+		Default:     json.RawMessage(`""`),
+		Secret:      true,
+	},
+	{
+		Key:         newsbrief.ConfigModel,
+		Type:        TypeString,
+		Description: "News brief model; empty uses the provider's default",
+		Default:     json.RawMessage(`""`),
+	},
+	{
+		Key:         newsbrief.ConfigBaseURL,
+		Type:        TypeString,
+		Description: "Base URL of an OpenAI-compatible gateway or self-hosted model; empty uses the provider's",
+		Default:     json.RawMessage(`""`),
+	},
+	{
+		Key:         KeyCORSOrigins,
+		Type:        TypeJSON,
+		Description: "Origins allowed to call the API from a browser, e.g. [\"https://app.example.com\"]; empty allows any",
+		Default:     json.RawMessage(`[]`),
+		Check:       checkOrigins,
+		Live:        true,
+	},
+	{
+		Key:         KeyWidgetCacheTTL,
+		Type:        TypeInt,
+		Description: "Seconds a rendered embeddable widget is served before it's rebuilt",
+		Default:     json.RawMessage(`60`),
+		Min:         int64p(5),
+		Max:         int64p(3600),
+		Live:        true,
+	},
+	{
+		Key:         KeyCalendarCacheTTL,
+		Type:        TypeInt,
+		Description: "Seconds the current month's signal calendar is cached",
+		Default:     json.RawMessage(`60`),
+		Min:         int64p(0),
+		Max:         int64p(3600),
+		Live:        true,
+	},
+	{
+		Key:         KeyTickInterval,
+		Type:        TypeInt,
+		Description: "Minimum milliseconds between WebSocket ticks for the same symbol, for every client; 0 sends all",
+		Default:     json.RawMessage(`0`),
+		Min:         int64p(0),
+		Max:         int64p(60000),
+		Live:        true,
+	},
+	{
+		Key:         KeyMutedNotifications,
+		Type:        TypeJSON,
+		Description: "Notification kinds not sent unless critical, e.g. [\"signal_proximity\"]",
+		Default:     json.RawMessage(`[]`),
+		Check:       checkNotificationKinds,
+		Live:        true,
+	},
+	{
+		Key:         KeyQuotaAPICalls,
+		Type:        TypeInt,
+		Description: "Default daily soft quota of API calls per account; 0 for none",
+		Default:     json.RawMessage(`50000`),
+		Min:         int64p(0),
+		Live:        true,
+	},
+	{
+		Key:         KeyQuotaWSMessages,
+		Type:        TypeInt,
+		Description: "Default daily soft quota of WebSocket messages sent per account; 0 for none",
+		Default:     json.RawMessage(`500000`),
+		Min:         int64p(0),
+		Live:        true,
+	},
+	{
+		Key:         KeyQuotaExportMB,
+		Type:        TypeInt,
+		Description: "Default daily soft quota of export megabytes per account; 0 for none",
+		Default:     json.RawMessage(`500`),
+		Min:         int64p(0),
+		Live:        true,
+	},
+}
+
+// checkOrigins accepts a list of "*" and scheme://host[:port] origins
+func checkOrigins(value json.RawMessage) error {
+	var origins []string
+	if err := json.Unmarshal(value, &origins); err != nil {
+		return errors.New("must be a list of origins")
+	}
+	for _, o := range origins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("%q is not an origin like https://app.example.com", o)
+		}
+	}
+	return nil
+}
+
+// checkNotificationKinds accepts a list of notification kinds
+func checkNotificationKinds(value json.RawMessage) error {
+	var kinds []string
+	if err := json.Unmarshal(value, &kinds); err != nil {
+		return errors.New("must be a list of notification kinds")
+	}
+	for _, k := range kinds {
+		if !contains(notifications.Kinds, k) {
+			return fmt.Errorf("%q is not one of %s", k, strings.Join(notifications.Kinds, ", "))
+		}
+	}
+	return nil
+}
+
+// encode validates value and returns its stored form
+func (s *Schema) encode(value json.RawMessage) (string, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s %s", ErrInvalid, s.Key, fmt.Sprintf(format, args...))
+	}
+	switch s.Type {
+	case TypeBool:
+		var b bool
+		if err := json.Unmarshal(value, &b); err != nil {
+			return "", invalid("must be true or false")
+		}
+		return strconv.FormatBool(b), nil
+
+	case TypeInt:
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.UseNumber()
+		var n json.Number
+		if err := dec.Decode(&n); err != nil {
+			return "", invalid("must be an integer")
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return "", invalid("must be an integer")
+		}
+		if s.Min != nil && i < *s.Min {
+			return "", invalid("must be at least %d", *s.Min)
+		}
+		if s.Max != nil && i > *s.Max {
+			return "", invalid("must be at most %d", *s.Max)
+		}
+		return strconv.FormatInt(i, 10), nil
+
+	case TypeString:
+		var str string
+		if err := json.Unmarshal(value, &str); err != nil {
+			return "", invalid("must be a string")
+		}
+		str = strings.TrimSpace(str)
+		if str != "" && len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return "", invalid("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		return str, nil
+
+	case TypeJSON:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return "", invalid("must be valid JSON")
+		}
+		if s.Check != nil {
+			if err := s.Check(buf.Bytes()); err != nil {
+				return "", invalid("%v", err)
+			}
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("setting %s has unknown type %q", s.Key, s.Type)
+}
+
+// decode returns a stored value as JSON
+func (s *Schema) decode(stored string) (json.RawMessage, error) {
+	switch s.Type {
+	case TypeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(stored))
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(strconv.FormatBool(b)), nil
+	case TypeInt:
+		i, err := strconv.ParseInt(strings.TrimSpace(stored), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(strconv.FormatInt(i, 10)), nil
+	case TypeString:
+		return json.Marshal(stored)
+	case TypeJSON:
+		if !json.Valid([]byte(stored)) {
+			return nil, errors.New("not valid JSON")
+		}
+		return json.RawMessage(stored), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", s.Type)
+}
+
+// mask hides a secret value, keeping whether one is set
+func (s *Schema) mask(value json.RawMessage) json.RawMessage {
+	if !s.Secret || value == nil || string(value) == `""` {
+		return value
+	}
+	return json.RawMessage(masked)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Setting is a setting's schema with its current value
+type Setting struct {
+	Schema
+	Value json.RawMessage `json:"value"`
+	// IsDefault is set when no valid value is stored
+	IsDefault bool       `json:"is_default"`
+	UpdatedBy *string    `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// Change describes a write to a setting. Secret values are masked when it's
+// published.
+type Change struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Previous  json.RawMessage `json:"previous"`
+	UpdatedBy string          `json:"updated_by"`
+	Timestamp string          `json:"timestamp"`
+}
+
+// Publisher publishes a raw event on a NATS subject
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Registry reads and writes settings by schema
+type Registry struct {
+	db        database.SettingsRepository
+	publisher Publisher
+	schemas   map[string]*Schema
+	keys      []string
+
+	mu    sync.RWMutex
+	hooks map[string][]func(Change)
+}
+
+// NewRegistry creates a registry of the built-in settings. publisher may
+// be nil when NATS is unavailable.
+func NewRegistry(db database.SettingsRepository, publisher Publisher) *Registry {
+	r := &Registry{db: db, publisher: publisher, schemas: map[string]*Schema{}, hooks: map[string][]func(Change){}}
+	for i := range builtin {
+		s := &builtin[i]
+		r.schemas[s.Key] = s
+		r.keys = append(r.keys, s.Key)
+	}
+	sort.Strings(r.keys)
+	return r
+}
+
+// OnChange registers fn to be called after key is written, with the
+// unmasked values. fn runs on the writer's goroutine and must not block.
+func (r *Registry) OnChange(key string, fn func(Change)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks[key] = append(r.hooks[key], fn)
+}
+
+// List returns every setting, by key
+func (r *Registry) List(ctx context.Context) ([]Setting, error) {
+	stored, err := r.db.GetSystemConfigValues(ctx, r.keys)
+	if err != nil {
+		return nil, err
+	}
+	settings := make([]Setting, 0, len(r.keys))
+	for _, key := range r.keys {
+		settings = append(settings, r.setting(r.schemas[key], stored))
+	}
+	return settings, nil
+}
+
+// Get returns one setting, or ErrUnknown
+func (r *Registry) Get(ctx context.Context, key string) (*Setting, error) {
+	s, ok := r.schemas[key]
+	if !ok {
+		return nil, ErrUnknown
+	}
+	stored, err := r.db.GetSystemConfigValues(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	setting := r.setting(s, stored)
+	return &setting, nil
+}
+
+// setting combines s with its stored value. A stored value that doesn't
+// decode as s's type is reported and the default used instead.
+func (r *Registry) setting(s *Schema, stored map[string]database.SystemConfigValue) Setting {
+	setting := Setting{Schema: *s, Value: s.Default, IsDefault: true}
+	v, ok := stored[s.Key]
+	if !ok {
+		return setting
+	}
+	setting.UpdatedBy = v.UpdatedBy
+	setting.UpdatedAt = &v.UpdatedAt
+	if v.Value != nil {
+		if value, err := s.decode(*v.Value); err == nil {
+			setting.Value, setting.IsDefault = value, false
+		} else {
+			log.Printf("⚠️  Stored value of setting %s is not a valid %s, using the default: %v", s.Key, s.Type, err)
+		}
+	}
+	setting.Value = s.mask(setting.Value)
+	return setting
+}
+
+// values returns the current values of keys, unmasked
+func (r *Registry) values(ctx context.Context, keys []string) (map[string]json.RawMessage, error) {
+	for _, key := range keys {
+		if _, ok := r.schemas[key]; !ok {
+			return nil, ErrUnknown
+		}
+	}
+	stored, err := r.db.GetSystemConfigValues(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		s := r.schemas[key]
+		values[key] = s.Default
+		if v, ok := stored[key]; ok && v.Value != nil {
+			if value, err := s.decode(*v.Value); err == nil {
+				values[key] = value
+			}
+		}
+	}
+	return values, nil
+}
+
+// value returns key's current value, unmasked
+func (r *Registry) value(ctx context.Context, key string) (json.RawMessage, error) {
+	values, err := r.values(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	return values[key], nil
+}
+
+// Bool returns a bool setting's value
+func (r *Registry) Bool(ctx context.Context, key string) (bool, error) {
+	value, err := r.value(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	var b bool
+	if err := json.Unmarshal(value, &b); err != nil {
+		return false, fmt.Errorf("setting %s is not a bool: %w", key, err)
+	}
+	return b, nil
+}
+
+// Int returns an int setting's value
+func (r *Registry) Int(ctx context.Context, key string) (int64, error) {
+	value, err := r.value(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	var i int64
+	if err := json.Unmarshal(value, &i); err != nil {
+		return 0, fmt.Errorf("setting %s is not an int: %w", key, err)
+	}
+	return i, nil
+}
+
+// Set validates and writes a setting as actor, then announces the change.
+// It returns ErrUnknown for keys without a schema and an error wrapping
+// ErrInvalid for values that fail validation.
+func (r *Registry) Set(ctx context.Context, key string, value json.RawMessage, actor string) (*Setting, error) {
+	s, ok := r.schemas[key]
+	if !ok {
+		return nil, ErrUnknown
+	}
+	encoded, err := s.encode(value)
+	if err != nil {
+		return nil, err
+	}
+	previous, updatedAt, err := r.db.PutSystemConfig(ctx, key, encoded, s.Description, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := s.decode(encoded)
+	change := Change{Key: key, Value: current, UpdatedBy: actor, Timestamp: updatedAt.Format(time.RFC3339Nano)}
+	if previous != nil {
+		change.Previous, _ = s.decode(*previous)
+	}
+	r.announce(s, change)
+
+	return &Setting{
+		Schema:    *s,
+		Value:     s.mask(current),
+		UpdatedBy: &actor,
+		UpdatedAt: &updatedAt,
+	}, nil
+}
+
+// announce runs key's hooks and publishes the change with secrets masked
+func (r *Registry) announce(s *Schema, change Change) {
+	r.mu.RLock()
+	hooks := r.hooks[s.Key]
+	r.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(change)
+	}
+
+	if r.publisher == nil {
+		return
+	}
+	change.Value, change.Previous = s.mask(change.Value), s.mask(change.Previous)
+	data, err := json.Marshal(change)
+	if err != nil {
+		log.Printf("❌ Failed to encode setting change: %v", err)
+		return
+	}
+	if err := r.publisher.Publish(Subject, data); err != nil {
+		log.Printf("⚠️  Failed to publish %s: %v", Subject, err)
+	}
+}
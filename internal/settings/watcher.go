@@ -0,0 +1,233 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// What caused a reload
+const (
+	TriggerStartup = "startup"
+	TriggerPoll    = "poll"
+	TriggerSIGHUP  = "sighup"
+	TriggerWrite   = "write"
+)
+
+// PollIntervalFromEnv reads CONFIG_POLL_INTERVAL, defaulting to 30s
+func PollIntervalFromEnv() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("CONFIG_POLL_INTERVAL")); err == nil && d >= time.Second {
+		return d
+	}
+	return 30 * time.Second
+}
+
+// Generation is one set of live settings in effect. A new generation
+// starts whenever a reload changes at least one value.
+type Generation struct {
+	Number    int64     `json:"number"`
+	Trigger   string    `json:"trigger"`
+	AppliedAt time.Time `json:"applied_at"`
+	// Changed lists the keys that differ from the previous generation
+	Changed []string                   `json:"changed"`
+	Values  map[string]json.RawMessage `json:"values"`
+}
+
+// WatcherStatus describes the watcher and the generation in effect
+type WatcherStatus struct {
+	Generation   *Generation `json:"generation"`
+	PollInterval string      `json:"poll_interval"`
+	LastCheckAt  *time.Time  `json:"last_check_at"`
+	LastError    string      `json:"last_error,omitempty"`
+}
+
+// Watcher applies live settings while the service runs. It re-reads them
+// every poll interval, on SIGHUP and after one is written through the
+// registry, and hands changed values to the appliers registered for them.
+// A reload applies all its changes before the new generation is published.
+type Watcher struct {
+	registry *Registry
+	interval time.Duration
+	wake     chan string
+	current  atomic.Pointer[Generation]
+
+	// mu serializes reloads
+	mu        sync.Mutex
+	appliers  map[string][]func(json.RawMessage) error
+	applied   map[string]json.RawMessage
+	lastCheck time.Time
+	lastErr   error
+}
+
+// NewWatcher creates a watcher of registry's live settings polling every
+// interval
+func NewWatcher(registry *Registry, interval time.Duration) *Watcher {
+	w := &Watcher{
+		registry: registry,
+		interval: interval,
+		wake:     make(chan string, 1),
+		appliers: map[string][]func(json.RawMessage) error{},
+		applied:  map[string]json.RawMessage{},
+	}
+	for _, key := range registry.keys {
+		if !registry.schemas[key].Live {
+			continue
+		}
+		registry.OnChange(key, func(Change) {
+			select {
+			case w.wake <- TriggerWrite:
+			default:
+			}
+		})
+	}
+	return w
+}
+
+// Apply registers fn to receive key's value at startup and whenever it
+// changes. Call before the first Reload.
+func (w *Watcher) Apply(key string, fn func(json.RawMessage) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.appliers[key] = append(w.appliers[key], fn)
+}
+
+// ApplyInt registers fn for an int setting
+func (w *Watcher) ApplyInt(key string, fn func(int64)) {
+	w.Apply(key, func(value json.RawMessage) error {
+		var i int64
+		if err := json.Unmarshal(value, &i); err != nil {
+			return err
+		}
+		fn(i)
+		return nil
+	})
+}
+
+// ApplyStrings registers fn for a json setting holding a list of strings
+func (w *Watcher) ApplyStrings(key string, fn func([]string)) {
+	w.Apply(key, func(value json.RawMessage) error {
+		var list []string
+		if err := json.Unmarshal(value, &list); err != nil {
+			return err
+		}
+		fn(list)
+		return nil
+	})
+}
+
+// Current returns the generation in effect, nil before the first reload
+func (w *Watcher) Current() *Generation {
+	return w.current.Load()
+}
+
+// Status returns the generation in effect and how the last check went
+func (w *Watcher) Status() WatcherStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := WatcherStatus{Generation: w.current.Load(), PollInterval: w.interval.String()}
+	if !w.lastCheck.IsZero() {
+		checked := w.lastCheck
+		status.LastCheckAt = &checked
+	}
+	if w.lastErr != nil {
+		status.LastError = w.lastErr.Error()
+	}
+	return status
+}
+
+// Run reloads on every poll, SIGHUP and write until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		trigger := TriggerPoll
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hup:
+			trigger = TriggerSIGHUP
+		case trigger = <-w.wake:
+		}
+		if _, err := w.Reload(ctx, trigger); err != nil {
+			log.Printf("⚠️  Failed to reload settings (%s): %v", trigger, err)
+		}
+	}
+}
+
+// Reload reads the live settings and applies those that changed. A value
+// an applier rejects keeps the previous one and is retried on the next
+// reload. It returns the generation in effect afterwards.
+func (w *Watcher) Reload(ctx context.Context, trigger string) (*Generation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastCheck = time.Now()
+
+	keys := []string{}
+	for _, key := range w.registry.keys {
+		if w.registry.schemas[key].Live {
+			keys = append(keys, key)
+		}
+	}
+	values, err := w.registry.values(ctx, keys)
+	w.lastErr = err
+	if err != nil {
+		return w.current.Load(), err
+	}
+
+	var changed, failed []string
+	for _, key := range keys {
+		value := values[key]
+		if previous, ok := w.applied[key]; ok && string(previous) == string(value) {
+			continue
+		}
+		ok := true
+		for _, fn := range w.appliers[key] {
+			if err := fn(value); err != nil {
+				log.Printf("❌ Failed to apply setting %s: %v", key, err)
+				ok = false
+			}
+		}
+		if !ok {
+			failed = append(failed, key)
+			continue
+		}
+		w.applied[key] = value
+		changed = append(changed, key)
+	}
+
+	if len(failed) > 0 {
+		w.lastErr = fmt.Errorf("could not apply %s", strings.Join(failed, ", "))
+	}
+
+	previous := w.current.Load()
+	if len(changed) == 0 && previous != nil {
+		return previous, nil
+	}
+
+	next := &Generation{Number: 1, Trigger: trigger, AppliedAt: time.Now(), Changed: changed, Values: map[string]json.RawMessage{}}
+	if previous != nil {
+		next.Number = previous.Number + 1
+	}
+	for key, value := range w.applied {
+		next.Values[key] = w.registry.schemas[key].mask(value)
+	}
+	w.current.Store(next)
+	log.Printf("✅ Settings generation %d applied (%s): %v", next.Number, trigger, changed)
+	return next, nil
+}
@@ -0,0 +1,209 @@
+// Package share builds public read-only links to signals, dashboard
+// snapshots and daily reports. A link's token carries its ID and expiry
+// signed with SHARE_SIGNING_SECRET, so tokens can't be guessed or extended,
+// and the shared data is reduced to public views with model internals and
+// anything about the sharing user left out.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Shareable kinds
+const (
+	KindSignal    = "signal"
+	KindDashboard = "dashboard"
+	KindReport    = "report"
+)
+
+// Kinds lists what can be shared
+var Kinds = []string{KindSignal, KindDashboard, KindReport}
+
+// Signer signs and verifies share tokens
+type Signer struct {
+	secret  []byte
+	baseURL string
+}
+
+// SignerFromEnv reads SHARE_SIGNING_SECRET and PUBLIC_BASE_URL. Without a
+// secret a random one is generated, so links don't survive a restart.
+func SignerFromEnv() *Signer {
+	secret := os.Getenv("SHARE_SIGNING_SECRET")
+	if secret == "" {
+		log.Printf("⚠️  SHARE_SIGNING_SECRET not set, share links expire on restart")
+		buf := make([]byte, 32)
+		rand.Read(buf)
+		secret = string(buf)
+	}
+	baseURL := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if baseURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "6001"
+		}
+		baseURL = "http://localhost:" + port
+	}
+	return &Signer{secret: []byte(secret), baseURL: baseURL}
+}
+
+// Token returns the signed token for a link
+func (s *Signer) Token(id int64, expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d", id, expires.Unix())
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(s.sign(payload))
+}
+
+// URL returns the public URL for a link
+func (s *Signer) URL(id int64, expires time.Time) string {
+	return s.baseURL + "/share/" + s.Token(id, expires)
+}
+
+// Verify checks a token, returning the link ID if it's authentic and not
+// expired
+func (s *Signer) Verify(token string, now time.Time) (int64, bool) {
+	enc := base64.RawURLEncoding
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, false
+	}
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return 0, false
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, s.sign(string(payload))) {
+		return 0, false
+	}
+	idPart, expPart, ok := strings.Cut(string(payload), ".")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Signer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Signal is the public view of a signal
+type Signal struct {
+	Symbol          string   `json:"symbol"`
+	StockName       string   `json:"stock_name"`
+	Sector          string   `json:"sector"`
+	SignalType      string   `json:"signal_type"`
+	ConfidenceScore float64  `json:"confidence_score"`
+	EntryPrice      float64  `json:"entry_price"`
+	CurrentPrice    float64  `json:"current_price"`
+	TargetPrice     float64  `json:"target_price"`
+	StopLoss        float64  `json:"stop_loss"`
+	Status          string   `json:"status"`
+	GeneratedAt     string   `json:"generated_at"`
+	ExpiresAt       *string  `json:"expires_at,omitempty"`
+	ExitPrice       *float64 `json:"exit_price,omitempty"`
+	ActualProfitPct *float64 `json:"actual_profit_pct,omitempty"`
+	ClosedAt        *string  `json:"closed_at,omitempty"`
+}
+
+// PublicSignal strips a signal down to its public view
+func PublicSignal(s *database.Signal) Signal {
+	out := Signal{
+		Symbol:          s.Symbol,
+		StockName:       s.StockName,
+		Sector:          s.Sector,
+		SignalType:      s.SignalType,
+		ConfidenceScore: s.ConfidenceScore,
+		EntryPrice:      s.EntryPrice,
+		CurrentPrice:    s.CurrentPrice,
+		TargetPrice:     s.TargetPrice,
+		StopLoss:        s.StopLoss,
+		Status:          s.Status,
+		GeneratedAt:     s.GeneratedAt.Format(time.RFC3339),
+		ExitPrice:       s.ExitPrice,
+		ActualProfitPct: s.ActualProfitPct,
+	}
+	if s.ExpiresAt != nil {
+		t := s.ExpiresAt.Format(time.RFC3339)
+		out.ExpiresAt = &t
+	}
+	if s.ClosedAt != nil {
+		t := s.ClosedAt.Format(time.RFC3339)
+		out.ClosedAt = &t
+	}
+	return out
+}
+
+// DashboardSignal is the public view of a dashboard signal
+type DashboardSignal struct {
+	Symbol            string  `json:"symbol"`
+	StockName         string  `json:"stock_name"`
+	Sector            string  `json:"sector"`
+	SignalType        string  `json:"signal_type"`
+	ConfidenceScore   float64 `json:"confidence_score"`
+	EntryPrice        float64 `json:"entry_price"`
+	CurrentPrice      float64 `json:"current_price"`
+	TargetPrice       float64 `json:"target_price"`
+	StopLoss          float64 `json:"stop_loss"`
+	ExpectedProfitPct float64 `json:"expected_profit_pct"`
+	Status            string  `json:"status"`
+	GeneratedAt       string  `json:"generated_at"`
+}
+
+// Dashboard is the public view of the signals dashboard
+type Dashboard struct {
+	ActiveSignals []DashboardSignal       `json:"active_signals"`
+	Statistics    database.DashboardStats `json:"statistics"`
+	TakenAt       string                  `json:"taken_at"`
+}
+
+// PublicDashboard strips dashboard data down to its public view
+func PublicDashboard(d *database.DashboardData, takenAt time.Time) Dashboard {
+	out := Dashboard{
+		ActiveSignals: make([]DashboardSignal, 0, len(d.ActiveSignals)),
+		Statistics:    d.Statistics,
+		TakenAt:       takenAt.Format(time.RFC3339),
+	}
+	for _, s := range d.ActiveSignals {
+		out.ActiveSignals = append(out.ActiveSignals, DashboardSignal{
+			Symbol:            s.Symbol,
+			StockName:         s.StockName,
+			Sector:            s.Sector,
+			SignalType:        s.SignalType,
+			ConfidenceScore:   s.ConfidenceScore,
+			EntryPrice:        s.EntryPrice,
+			CurrentPrice:      s.CurrentPrice,
+			TargetPrice:       s.TargetPrice,
+			StopLoss:          s.StopLoss,
+			ExpectedProfitPct: s.ExpectedProfitPct,
+			Status:            s.Status,
+			GeneratedAt:       s.GeneratedAt,
+		})
+	}
+	return out
+}
+
+// Report is a shared daily report
+type Report struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
@@ -0,0 +1,98 @@
+// Package signalnews links each new signal to the high-impact articles
+// about its symbol published shortly before it, so a signal can be
+// reviewed against the news that may have moved it.
+//
+// Links are written once into the signal's metadata as news_links. Signals
+// are picked up by a background pass shortly after they're generated, or
+// linked on demand when their news is first requested.
+package signalnews
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+const (
+	// lookback is how far before a signal articles are considered
+	lookback = 72 * time.Hour
+	// maxLinks caps the articles linked to one signal
+	maxLinks = 5
+	// recentWindow bounds which signals the background pass picks up, so
+	// old history isn't backfilled on first start
+	recentWindow = 24 * time.Hour
+	// batchSize caps the signals linked per pass
+	batchSize = 200
+	// checkInterval is how often new signals are linked
+	checkInterval = 30 * time.Second
+)
+
+// Linker links signals to news
+type Linker struct {
+	db database.SignalNewsRepository
+}
+
+// NewLinker creates a linker
+func NewLinker(db database.SignalNewsRepository) *Linker {
+	return &Linker{db: db}
+}
+
+// Run links newly generated signals until ctx is cancelled
+func (l *Linker) Run(ctx context.Context) {
+	l.pass(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.pass(ctx)
+		}
+	}
+}
+
+func (l *Linker) pass(ctx context.Context) {
+	signals, err := l.db.ListUnlinkedSignals(ctx, time.Now().Add(-recentWindow), batchSize)
+	if err != nil {
+		log.Printf("⚠️  Signal news linking unavailable: %v", err)
+		return
+	}
+	for i := range signals {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := l.link(ctx, &signals[i]); err != nil {
+			log.Printf("⚠️  Failed to link news to signal %s: %v", signals[i].SignalID, err)
+		}
+	}
+}
+
+// link finds and saves s's articles
+func (l *Linker) link(ctx context.Context, s *database.SignalNews) error {
+	links, err := l.db.FindSignalNews(ctx, s.Symbol, s.GeneratedAt, lookback, maxLinks)
+	if err != nil {
+		return err
+	}
+	if err := l.db.SaveSignalNews(ctx, s.SignalID, links); err != nil {
+		return err
+	}
+	s.Linked = true
+	s.Articles = links
+	return nil
+}
+
+// ForSignal returns a signal's linked articles, linking them first if the
+// background pass hasn't yet. It returns nil if there's no such signal.
+func (l *Linker) ForSignal(ctx context.Context, signalID string) (*database.SignalNews, error) {
+	s, err := l.db.GetSignalNews(ctx, signalID)
+	if err != nil || s == nil || s.Linked {
+		return s, err
+	}
+	if err := l.link(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
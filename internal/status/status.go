@@ -0,0 +1,321 @@
+// Package status runs the health checks behind the public status page and
+// keeps a sanitized roll-up of component status, uptime and incidents.
+//
+// Each component is checked every minute. Results are tallied per day for
+// uptime, where degraded checks count as up and outages as down, and a
+// component that isn't operational has an incident open until it recovers.
+// Nothing from the checks themselves (URLs, latencies, errors) is exposed.
+package status
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Levels, from best to worst
+const (
+	Operational = "operational"
+	Degraded    = "degraded"
+	Outage      = "outage"
+)
+
+// checkInterval is how often components are checked
+const checkInterval = time.Minute
+
+// incidentHistory is how far back resolved incidents are listed
+const incidentHistory = 14 * 24 * time.Hour
+
+// Check reports a component's current level
+type Check func(ctx context.Context) string
+
+// Component is a public-facing part of the platform
+type Component struct {
+	ID    string
+	Name  string
+	Check Check
+}
+
+// HTTPCheck treats a 2xx response as operational, 503 or a slow response
+// as degraded and anything else as an outage
+func HTTPCheck(url string) Check {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return func(ctx context.Context) string {
+		start := time.Now()
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return Outage
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return Outage
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusServiceUnavailable:
+			return Degraded
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return Outage
+		case time.Since(start) > 2*time.Second:
+			return Degraded
+		}
+		return Operational
+	}
+}
+
+// PingCheck treats a successful ping as operational
+func PingCheck(ping func(ctx context.Context) error) Check {
+	return func(ctx context.Context) string {
+		if err := ping(ctx); err != nil {
+			return Outage
+		}
+		return Operational
+	}
+}
+
+// Static always reports level
+func Static(level string) Check {
+	return func(context.Context) string { return level }
+}
+
+// ComponentStatus is a component as shown on the status page
+type ComponentStatus struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Uptime percentages are nil until the component has been checked
+	Uptime30d *float64 `json:"uptime_30d"`
+	Uptime90d *float64 `json:"uptime_90d"`
+}
+
+// Incident is an incident as shown on the status page
+type Incident struct {
+	ID         int64   `json:"id"`
+	Component  string  `json:"component"`
+	Title      string  `json:"title"`
+	Impact     string  `json:"impact"`
+	Status     string  `json:"status"`
+	StartedAt  string  `json:"started_at"`
+	ResolvedAt *string `json:"resolved_at"`
+}
+
+// Page is the public status roll-up
+type Page struct {
+	Status      string            `json:"status"`
+	Description string            `json:"description"`
+	Components  []ComponentStatus `json:"components"`
+	Incidents   []Incident        `json:"incidents"`
+	UpdatedAt   string            `json:"updated_at"`
+}
+
+// Monitor checks components and maintains the status page
+type Monitor struct {
+	db         database.StatusPageRepository
+	components []Component
+
+	mu     sync.RWMutex
+	levels map[string]string
+	page   *Page
+}
+
+// NewMonitor creates a monitor for components, shown in the given order
+func NewMonitor(db database.StatusPageRepository, components []Component) *Monitor {
+	return &Monitor{db: db, components: components, levels: map[string]string{}}
+}
+
+// Page returns the latest roll-up, or nil before the first round of checks
+func (m *Monitor) Page() *Page {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.page
+}
+
+// Run checks components immediately and then every minute until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	m.checkAll(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.checkAll(ctx)
+		}
+	}
+}
+
+func (m *Monitor) checkAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	now := time.Now()
+
+	levels := make([]string, len(m.components))
+	var wg sync.WaitGroup
+	for i, comp := range m.components {
+		wg.Add(1)
+		go func(i int, comp Component) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			levels[i] = comp.Check(checkCtx)
+		}(i, comp)
+	}
+	wg.Wait()
+
+	m.mu.RLock()
+	previous := make(map[string]string, len(m.levels))
+	for k, v := range m.levels {
+		previous[k] = v
+	}
+	m.mu.RUnlock()
+
+	// A component's level is only remembered once its incident is settled,
+	// so a failed update is retried on the next check
+	settled := make([]bool, len(m.components))
+	for i, comp := range m.components {
+		level := levels[i]
+		if err := m.db.RecordStatusCheck(ctx, comp.ID, database.SessionOpen(now), level); err != nil {
+			log.Printf("⚠️  Status page failed to record %s check: %v", comp.ID, err)
+		}
+		prev, seen := previous[comp.ID]
+		if seen && prev == level {
+			settled[i] = true
+			continue
+		}
+		// Incidents left open by a previous process are settled on the first check
+		var err error
+		if level == Operational {
+			err = m.db.ResolveStatusIncident(ctx, comp.ID, now)
+		} else {
+			err = m.db.OpenStatusIncident(ctx, comp.ID, incidentTitle(comp, level), level, now)
+		}
+		if err != nil {
+			log.Printf("⚠️  Status page failed to update %s incident: %v", comp.ID, err)
+			continue
+		}
+		settled[i] = true
+		if seen {
+			log.Printf("⚠️  Status of %s changed: %s -> %s", comp.ID, prev, level)
+		}
+	}
+
+	page := m.buildPage(ctx, levels, now)
+
+	m.mu.Lock()
+	for i, comp := range m.components {
+		if settled[i] {
+			m.levels[comp.ID] = levels[i]
+		}
+	}
+	m.page = page
+	m.mu.Unlock()
+}
+
+func incidentTitle(comp Component, level string) string {
+	if level == Outage {
+		return comp.Name + " is unavailable"
+	}
+	return comp.Name + " is degraded"
+}
+
+func (m *Monitor) buildPage(ctx context.Context, levels []string, now time.Time) *Page {
+	page := &Page{
+		Status:     Operational,
+		Components: make([]ComponentStatus, len(m.components)),
+		Incidents:  []Incident{},
+		UpdatedAt:  now.UTC().Format(time.RFC3339),
+	}
+	for i, comp := range m.components {
+		page.Components[i] = ComponentStatus{ID: comp.ID, Name: comp.Name, Status: levels[i]}
+		if worse(levels[i], page.Status) {
+			page.Status = levels[i]
+		}
+	}
+	switch page.Status {
+	case Operational:
+		page.Description = "All systems operational"
+	case Degraded:
+		page.Description = "Some systems are degraded"
+	default:
+		page.Description = "Some systems are unavailable"
+	}
+
+	// Uptime and incidents come from the database; without it the page
+	// still reports current status and carries over the last known history
+	uptime30, err30 := m.db.GetStatusUptime(ctx, now.AddDate(0, 0, -29))
+	uptime90, err90 := m.db.GetStatusUptime(ctx, now.AddDate(0, 0, -89))
+	incidents, errInc := m.db.ListStatusIncidents(ctx, now.Add(-incidentHistory), 50)
+	if err := firstErr(err30, err90, errInc); err != nil {
+		log.Printf("⚠️  Status page history unavailable: %v", err)
+		m.mu.RLock()
+		last := m.page
+		m.mu.RUnlock()
+		if last != nil {
+			for i := range page.Components {
+				if i < len(last.Components) && last.Components[i].ID == page.Components[i].ID {
+					page.Components[i].Uptime30d = last.Components[i].Uptime30d
+					page.Components[i].Uptime90d = last.Components[i].Uptime90d
+				}
+			}
+			page.Incidents = last.Incidents
+		}
+		return page
+	}
+
+	names := map[string]string{}
+	for i, comp := range m.components {
+		page.Components[i].Uptime30d = uptimePct(uptime30[comp.ID])
+		page.Components[i].Uptime90d = uptimePct(uptime90[comp.ID])
+		names[comp.ID] = comp.Name
+	}
+	for _, inc := range incidents {
+		name, ok := names[inc.Component]
+		if !ok {
+			// Components that have since been removed aren't shown
+			continue
+		}
+		out := Incident{
+			ID:        inc.ID,
+			Component: name,
+			Title:     inc.Title,
+			Impact:    inc.Impact,
+			Status:    "investigating",
+			StartedAt: inc.StartedAt.UTC().Format(time.RFC3339),
+		}
+		if inc.ResolvedAt != nil {
+			resolved := inc.ResolvedAt.UTC().Format(time.RFC3339)
+			out.Status, out.ResolvedAt = "resolved", &resolved
+		}
+		page.Incidents = append(page.Incidents, out)
+	}
+	return page
+}
+
+func worse(a, b string) bool {
+	rank := map[string]int{Operational: 0, Degraded: 1, Outage: 2}
+	return rank[a] > rank[b]
+}
+
+func uptimePct(u database.StatusUptime) *float64 {
+	if u.Checks == 0 {
+		return nil
+	}
+	pct := float64(u.Checks-u.OutageChecks) / float64(u.Checks) * 100
+	pct = math.Round(pct*1000) / 1000
+	return &pct
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package stockhistory keeps a daily snapshot of md.stock_config. Smart
+// selection rewrites the stock config every morning, so without snapshots
+// there is no record of which symbols were enabled on a past day.
+//
+// Each weekday's snapshot is taken once, on the first check after
+// STOCK_CONFIG_SNAPSHOT_TIME (IST, default 09:15) when the morning selection
+// has run; a service started later in the day snapshots on startup.
+package stockhistory
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// checkInterval is how often the snapshotter looks for a missing snapshot
+const checkInterval = 5 * time.Minute
+
+// defaultSnapshotTime is market open, after the morning selection
+const defaultSnapshotTime = "09:15"
+
+// Snapshotter takes the daily stock config snapshot
+type Snapshotter struct {
+	db    database.StockConfigSnapshotRepository
+	loc   *time.Location
+	after time.Duration // since IST midnight
+}
+
+// NewSnapshotter creates a snapshotter, reading STOCK_CONFIG_SNAPSHOT_TIME
+// as HH:MM
+func NewSnapshotter(db database.StockConfigSnapshotRepository) *Snapshotter {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		loc = time.FixedZone("IST", 5*3600+1800)
+	}
+	at := os.Getenv("STOCK_CONFIG_SNAPSHOT_TIME")
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		if at != "" {
+			log.Printf("⚠️  Invalid STOCK_CONFIG_SNAPSHOT_TIME=%q, using %s", at, defaultSnapshotTime)
+		}
+		t, _ = time.Parse("15:04", defaultSnapshotTime)
+	}
+	return &Snapshotter{db: db, loc: loc, after: time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute}
+}
+
+// Run snapshots each weekday until ctx is cancelled
+func (s *Snapshotter) Run(ctx context.Context) {
+	s.check(ctx)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check takes today's snapshot if it's due and missing
+func (s *Snapshotter) check(ctx context.Context) {
+	now := time.Now().In(s.loc)
+	if now.Weekday() == time.Saturday || now.Weekday() == time.Sunday {
+		return
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+	if now.Sub(day) < s.after {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	taken, err := s.db.HasStockConfigSnapshot(ctx, day)
+	if err != nil {
+		log.Printf("⚠️  Stock config snapshot unavailable: %v", err)
+		return
+	}
+	if taken {
+		return
+	}
+	n, err := s.db.SnapshotStockConfig(ctx, day)
+	if err != nil {
+		log.Printf("❌ Failed to snapshot stock config: %v", err)
+		return
+	}
+	log.Printf("✅ Stock config snapshot for %s: %d symbols", day.Format("2006-01-02"), n)
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LifecycleRule expires objects under Prefix once they are older than MaxAge.
+// A zero MaxAge keeps objects forever.
+type LifecycleRule struct {
+	Prefix string        `json:"prefix"`
+	MaxAge time.Duration `json:"-"`
+	Days   int           `json:"max_age_days"`
+}
+
+// SweepResult summarises one lifecycle pass over a prefix
+type SweepResult struct {
+	Prefix   string    `json:"prefix"`
+	Scanned  int       `json:"scanned"`
+	Deleted  int       `json:"deleted"`
+	Freed    int64     `json:"freed_bytes"`
+	Error    string    `json:"error,omitempty"`
+	SweptAt  time.Time `json:"swept_at"`
+	Duration string    `json:"duration"`
+}
+
+// LifecycleRulesFromEnv reads retention per prefix in days. Exports default to
+// 7 days and reports to 90; model artifacts are kept unless configured.
+func LifecycleRulesFromEnv() []LifecycleRule {
+	days := func(name string, def int) int {
+		if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v >= 0 {
+			return v
+		}
+		return def
+	}
+	rules := []LifecycleRule{
+		{Prefix: PrefixExports, Days: days("STORAGE_EXPORTS_RETENTION_DAYS", 7)},
+		{Prefix: PrefixReports, Days: days("STORAGE_REPORTS_RETENTION_DAYS", 90)},
+		{Prefix: PrefixModels, Days: days("STORAGE_MODELS_RETENTION_DAYS", 0)},
+	}
+	for i := range rules {
+		rules[i].MaxAge = time.Duration(rules[i].Days) * 24 * time.Hour
+	}
+	return rules
+}
+
+// Lifecycle periodically deletes expired objects. Hooks registered with
+// OnExpire run after each sweep of their prefix so metadata that points at
+// the deleted objects (e.g. export jobs) can be updated.
+type Lifecycle struct {
+	store Store
+	rules []LifecycleRule
+
+	mu       sync.RWMutex
+	hooks    map[string]func(ctx context.Context, before time.Time) error
+	lastRun  []SweepResult
+	running  bool
+	interval time.Duration
+}
+
+// NewLifecycle creates a lifecycle manager for store
+func NewLifecycle(store Store, rules []LifecycleRule) *Lifecycle {
+	return &Lifecycle{
+		store: store,
+		rules: rules,
+		hooks: make(map[string]func(ctx context.Context, before time.Time) error),
+	}
+}
+
+// OnExpire registers fn to run after objects under prefix older than before
+// have been deleted
+func (l *Lifecycle) OnExpire(prefix string, fn func(ctx context.Context, before time.Time) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks[prefix] = fn
+}
+
+// Rules returns the configured rules
+func (l *Lifecycle) Rules() []LifecycleRule {
+	return l.rules
+}
+
+// LastRun returns the results of the most recent sweep
+func (l *Lifecycle) LastRun() []SweepResult {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastRun
+}
+
+// Interval returns the sweep interval, or zero if Run has not been started
+func (l *Lifecycle) Interval() time.Duration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.interval
+}
+
+// Run sweeps immediately and then every interval until ctx is cancelled
+func (l *Lifecycle) Run(ctx context.Context, interval time.Duration) {
+	l.mu.Lock()
+	l.interval = interval
+	l.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		l.Sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep applies every rule once. Concurrent calls return the previous result
+// instead of sweeping twice.
+func (l *Lifecycle) Sweep(ctx context.Context) []SweepResult {
+	l.mu.Lock()
+	if l.running {
+		last := l.lastRun
+		l.mu.Unlock()
+		return last
+	}
+	l.running = true
+	l.mu.Unlock()
+
+	results := make([]SweepResult, 0, len(l.rules))
+	for _, rule := range l.rules {
+		if rule.MaxAge <= 0 {
+			continue
+		}
+		result := l.sweepRule(ctx, rule)
+		if result.Error != "" {
+			log.Printf("❌ Storage lifecycle %s: %s", rule.Prefix, result.Error)
+		} else if result.Deleted > 0 {
+			log.Printf("✅ Storage lifecycle %s: deleted %d objects (%d bytes)", rule.Prefix, result.Deleted, result.Freed)
+		}
+		results = append(results, result)
+	}
+
+	l.mu.Lock()
+	l.lastRun = results
+	l.running = false
+	l.mu.Unlock()
+	return results
+}
+
+func (l *Lifecycle) sweepRule(ctx context.Context, rule LifecycleRule) SweepResult {
+	start := time.Now()
+	before := start.Add(-rule.MaxAge)
+	result := SweepResult{Prefix: rule.Prefix, SweptAt: start}
+
+	objects, err := l.store.List(ctx, rule.Prefix)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start).String()
+		return result
+	}
+	result.Scanned = len(objects)
+
+	for _, obj := range objects {
+		if !obj.LastModified.Before(before) {
+			continue
+		}
+		if err := l.store.Delete(ctx, obj.Key); err != nil {
+			result.Error = err.Error()
+			break
+		}
+		result.Deleted++
+		result.Freed += obj.Size
+	}
+
+	l.mu.RLock()
+	hook := l.hooks[rule.Prefix]
+	l.mu.RUnlock()
+	if hook != nil && result.Error == "" {
+		if err := hook(ctx, before); err != nil {
+			result.Error = err.Error()
+		}
+	}
+
+	result.Duration = time.Since(start).String()
+	return result
+}
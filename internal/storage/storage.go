@@ -0,0 +1,275 @@
+// Package storage stores generated files (exports, reports) on local disk or
+// in an S3-compatible bucket and hands out time-limited download URLs.
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Well-known key prefixes. Exports and archives are written by the API;
+// reports and model artifacts are uploaded by the Python jobs through
+// pre-signed PUT URLs.
+const (
+	PrefixExports = "exports/"
+	PrefixReports = "reports/"
+	PrefixModels  = "models/"
+	PrefixArchive = "archive/"
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Store is an object store for generated artifacts
+type Store interface {
+	// Name identifies the backend ("local" or "s3")
+	Name() string
+	// Put uploads size bytes from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object for reading
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns objects whose key starts with prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// PresignGet returns a URL that downloads key until ttl elapses
+	PresignGet(key string, ttl time.Duration) (string, error)
+	// PresignPut returns a URL that accepts an upload to key until ttl elapses
+	PresignPut(key string, ttl time.Duration) (string, error)
+}
+
+// Config selects and configures a Store
+type Config struct {
+	Backend       string
+	LocalDir      string
+	PublicBaseURL string
+	SigningSecret string
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3PathStyle   bool
+}
+
+// ConfigFromEnv reads storage settings from the environment
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Backend:       strings.ToLower(os.Getenv("STORAGE_BACKEND")),
+		LocalDir:      os.Getenv("STORAGE_LOCAL_DIR"),
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+		SigningSecret: os.Getenv("STORAGE_SIGNING_SECRET"),
+		S3Endpoint:    os.Getenv("S3_ENDPOINT"),
+		S3Region:      os.Getenv("S3_REGION"),
+		S3Bucket:      os.Getenv("S3_BUCKET"),
+		S3AccessKey:   os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretKey:   os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3PathStyle:   os.Getenv("S3_PATH_STYLE") == "true",
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = "local"
+	}
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "./data/storage"
+	}
+	if cfg.PublicBaseURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "6001"
+		}
+		cfg.PublicBaseURL = "http://localhost:" + port
+	}
+	if cfg.S3Region == "" {
+		cfg.S3Region = "us-east-1"
+	}
+	// MinIO serves buckets path-style unless configured with a domain
+	if cfg.Backend == "minio" && os.Getenv("S3_PATH_STYLE") == "" {
+		cfg.S3PathStyle = true
+	}
+	return cfg
+}
+
+// New builds the configured Store
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocalStore(cfg.LocalDir, cfg.PublicBaseURL, cfg.SigningSecret)
+	case "s3", "minio":
+		return NewS3Store(cfg)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
+
+// tmpSuffix marks partially written local objects
+const tmpSuffix = ".tmp"
+
+// LocalStore keeps objects under a directory and serves them through the
+// API's signed download route
+type LocalStore struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalStore creates the directory if needed. Without a signing secret a
+// random one is generated, so URLs do not survive a restart.
+func NewLocalStore(dir, baseURL, secret string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage dir: %w", err)
+	}
+	if secret == "" {
+		secret = randomSecret()
+	}
+	return &LocalStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret)}, nil
+}
+
+// Name implements Store
+func (s *LocalStore) Name() string { return "local" }
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// Put implements Store
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create object dir: %w", err)
+	}
+
+	tmp := p + tmpSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	return os.Rename(tmp, p)
+}
+
+// Get implements Store
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete implements Store
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List implements Store
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	err := filepath.Walk(s.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(p, tmpSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// PresignGet implements Store; the URL is served by GET /api/storage/objects/*key
+func (s *LocalStore) PresignGet(key string, ttl time.Duration) (string, error) {
+	return s.presign("GET", key, ttl), nil
+}
+
+// PresignPut implements Store; the URL is served by PUT /api/storage/objects/*key
+func (s *LocalStore) PresignPut(key string, ttl time.Duration) (string, error) {
+	return s.presign("PUT", key, ttl), nil
+}
+
+func (s *LocalStore) presign(method, key string, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", s.sign(method, key, expires))
+	return fmt.Sprintf("%s/api/storage/objects/%s?%s", s.baseURL, escapeKey(key), q.Encode())
+}
+
+// Verify checks a signature produced by PresignGet or PresignPut for method
+func (s *LocalStore) Verify(method, key, expires, signature string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(method, key, exp)))
+}
+
+func (s *LocalStore) sign(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escapeKey escapes each path segment of an object key
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
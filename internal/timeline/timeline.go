@@ -0,0 +1,287 @@
+// Package timeline records each lifecycle transition of a signal as an
+// ordered event: its generation, price moving through bands of progress
+// toward the target or stop, stop moves, status changes and its close. The
+// signal row only holds the latest state; the timeline keeps how it got
+// there.
+//
+// Events are derived from the signal.* stream. What was last recorded for
+// a signal is kept in memory and reloaded from its latest event after a
+// restart, so only real changes are written.
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+	"github.com/trading-chitti/core-api-go/internal/schemas"
+)
+
+const (
+	// BandPct is the width of a progress band; a threshold event is
+	// recorded each time price moves into another one
+	BandPct = 25.0
+	// queueSize bounds signal events waiting to be recorded
+	queueSize = 1000
+	// idleAfter is how long a signal's state is kept in memory without
+	// events; it's reloaded from the database if the signal moves again
+	idleAfter = 24 * time.Hour
+	// pruneInterval is how often idle state is dropped
+	pruneInterval = time.Hour
+)
+
+// Band is how far price has come from entry toward the target (positive)
+// or the stop (negative), in percent of that distance, rounded toward
+// zero to a whole band and capped at ±100. SELL and SHORT signals profit
+// when price falls, so they are mirrored.
+func Band(signalType string, entry, target, stop, price float64) float64 {
+	dir := 1.0
+	if t := strings.ToUpper(signalType); t == "SELL" || t == "SHORT" {
+		dir = -1
+	}
+	move := (price - entry) * dir
+	pct := 0.0
+	switch {
+	case move > 0 && (target-entry)*dir > 0:
+		pct = move / ((target - entry) * dir) * 100
+	case move < 0 && (entry-stop)*dir > 0:
+		pct = move / ((entry - stop) * dir) * 100
+	}
+	band := math.Trunc(math.Max(-100, math.Min(100, pct))/BandPct) * BandPct
+	if band == 0 {
+		// Not -0
+		return 0
+	}
+	return band
+}
+
+// state is what was last recorded for a signal
+type state struct {
+	status string
+	stop   float64
+	band   float64
+	closed bool
+	seen   time.Time
+}
+
+type queued struct {
+	subject  string
+	event    schemas.Signal
+	received time.Time
+}
+
+// Recorder writes signal lifecycle events
+type Recorder struct {
+	db    database.SignalTimelineRepository
+	queue chan queued
+
+	// states is only touched by Run
+	states map[string]*state
+}
+
+// NewRecorder creates a recorder
+func NewRecorder(db database.SignalTimelineRepository) *Recorder {
+	return &Recorder{db: db, queue: make(chan queued, queueSize), states: map[string]*state{}}
+}
+
+// HandleSignal queues a signal event to be recorded. It's registered with
+// the event subscriber's OnSignal and never blocks; when the backlog is
+// full the event is dropped.
+func (r *Recorder) HandleSignal(subject string, e schemas.Signal) {
+	select {
+	case r.queue <- queued{subject: subject, event: e, received: time.Now()}:
+	default:
+		log.Printf("⚠️  Signal timeline backlog full, dropped %s for %s", subject, e.Symbol)
+	}
+}
+
+// Run records queued events until ctx is cancelled
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case q := <-r.queue:
+			r.record(ctx, q)
+		case now := <-ticker.C:
+			for id, st := range r.states {
+				if now.Sub(st.seen) > idleAfter {
+					delete(r.states, id)
+				}
+			}
+		}
+	}
+}
+
+// record writes the transitions one event makes
+func (r *Recorder) record(ctx context.Context, q queued) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	e := q.event
+	signalID, err := r.signalID(ctx, e)
+	if err != nil {
+		log.Printf("❌ Failed to resolve signal for %s timeline event: %v", q.subject, err)
+		return
+	}
+	if signalID == "" {
+		log.Printf("⚠️  No signal found for %s on %s generated at %s; timeline event skipped", q.subject, e.Symbol, e.GeneratedAt)
+		return
+	}
+
+	at := q.received
+	if ts, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil {
+		at = ts
+	}
+
+	st, err := r.state(ctx, signalID)
+	if err != nil {
+		log.Printf("❌ Failed to load timeline of signal %s: %v", signalID, err)
+		return
+	}
+	for _, ev := range transitions(q.subject, e, st) {
+		ev.SignalID = signalID
+		ev.OccurredAt = at
+		if _, err := r.db.RecordSignalEvent(ctx, ev); err != nil {
+			log.Printf("❌ Failed to record %s event for signal %s: %v", ev.Kind, signalID, err)
+			return
+		}
+	}
+	st.seen = q.received
+	r.states[signalID] = st
+}
+
+// signalID is the event's signal ID. Signals with non-numeric IDs are sent
+// as 0 and found by symbol and generation time.
+func (r *Recorder) signalID(ctx context.Context, e schemas.Signal) (string, error) {
+	if e.SignalID != 0 {
+		return strconv.Itoa(e.SignalID), nil
+	}
+	generatedAt, err := time.Parse(time.RFC3339Nano, e.GeneratedAt)
+	if err != nil {
+		return "", nil
+	}
+	return r.db.FindSignalID(ctx, e.Symbol, generatedAt)
+}
+
+// state returns what was last recorded for a signal, from memory or its
+// latest stored event. A signal with no events gets a blank state.
+func (r *Recorder) state(ctx context.Context, signalID string) (*state, error) {
+	if st, ok := r.states[signalID]; ok {
+		return st, nil
+	}
+	last, err := r.db.LastSignalEvent(ctx, signalID)
+	if err != nil || last == nil {
+		return &state{}, err
+	}
+	st := &state{closed: last.Kind == database.SignalEventClosed}
+	if last.Status != nil {
+		st.status = *last.Status
+	}
+	if last.StopLoss != nil {
+		st.stop = *last.StopLoss
+	}
+	if last.ProgressPct != nil {
+		st.band = *last.ProgressPct
+	}
+	return st, nil
+}
+
+// transitions are the events subject makes on a signal in state st, which
+// is updated to match. Once a signal has closed only signal.new, as sent
+// when it's restored, reopens it.
+func transitions(subject string, e schemas.Signal, st *state) []database.SignalTimelineEvent {
+	if st.closed && subject != "signal.new" {
+		return nil
+	}
+	snapshot := func(kind string, price float64, detail map[string]interface{}) database.SignalTimelineEvent {
+		band := st.band
+		ev := database.SignalTimelineEvent{
+			Kind:        kind,
+			Status:      nonEmpty(e.Status),
+			Price:       positive(price),
+			StopLoss:    positive(e.StopLoss),
+			TargetPrice: positive(e.TargetPrice),
+			ProgressPct: &band,
+		}
+		if detail != nil {
+			ev.Detail, _ = json.Marshal(detail)
+		}
+		return ev
+	}
+
+	switch subject {
+	case "signal.new":
+		*st = state{status: e.Status, stop: e.StopLoss}
+		return []database.SignalTimelineEvent{snapshot(database.SignalEventGenerated, e.EntryPrice, map[string]interface{}{
+			"signal_type": e.SignalType,
+			"confidence":  e.Confidence,
+		})}
+
+	case "signal.closed":
+		price := e.ExitPrice
+		if price <= 0 {
+			price = e.CurrentPrice
+		}
+		if price > 0 {
+			st.band = Band(e.SignalType, e.EntryPrice, e.TargetPrice, e.StopLoss, price)
+		}
+		st.status, st.closed = e.Status, true
+		return []database.SignalTimelineEvent{snapshot(database.SignalEventClosed, price, map[string]interface{}{
+			"pnl": e.PNL,
+		})}
+
+	case "signal.updated":
+		// A signal first seen on an update has nothing to compare against;
+		// its current state becomes the baseline
+		if st.status == "" && st.stop == 0 {
+			st.status, st.stop = e.Status, e.StopLoss
+			if e.CurrentPrice > 0 {
+				st.band = Band(e.SignalType, e.EntryPrice, e.TargetPrice, e.StopLoss, e.CurrentPrice)
+			}
+			return nil
+		}
+
+		var out []database.SignalTimelineEvent
+		if e.Status != "" && e.Status != st.status {
+			from := st.status
+			st.status = e.Status
+			out = append(out, snapshot(database.SignalEventStatus, e.CurrentPrice, map[string]interface{}{"from": from}))
+		}
+		if e.StopLoss > 0 && math.Abs(e.StopLoss-st.stop) > 1e-9 {
+			from := st.stop
+			st.stop = e.StopLoss
+			out = append(out, snapshot(database.SignalEventStopMoved, e.CurrentPrice, map[string]interface{}{"from": from}))
+		}
+		if e.CurrentPrice > 0 {
+			if band := Band(e.SignalType, e.EntryPrice, e.TargetPrice, e.StopLoss, e.CurrentPrice); band != st.band {
+				from := st.band
+				st.band = band
+				out = append(out, snapshot(database.SignalEventThreshold, e.CurrentPrice, map[string]interface{}{"from": from}))
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func positive(v float64) *float64 {
+	if v <= 0 {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,147 @@
+// Package tradingview parses TradingView alert webhooks. TradingView posts
+// the alert's message as the body: JSON when the message is written as
+// JSON (usually filled with placeholders like {{ticker}} and {{close}}),
+// plain text otherwise. It can't send headers, so the webhook secret
+// travels in the body's "secret" field or the URL's ?secret=.
+package tradingview
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alert is a parsed webhook body. Text is set for plain-text alerts.
+type Alert struct {
+	Secret     string `json:"secret"`
+	Ticker     string `json:"ticker"`
+	Exchange   string `json:"exchange"`
+	Action     string `json:"action"`
+	Price      Number `json:"price"`
+	StopLoss   Number `json:"stop_loss"`
+	Target     Number `json:"target"`
+	Confidence Number `json:"confidence"`
+	// ID is the sender's ID for the alert, used to drop repeats
+	ID      string `json:"id"`
+	Time    string `json:"time"`
+	Message string `json:"message"`
+
+	Text string `json:"-"`
+}
+
+// Number accepts JSON numbers and numeric strings, since placeholders are
+// often quoted in alert messages
+type Number float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *Number) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%s is not a number", b)
+	}
+	*n = Number(f)
+	return nil
+}
+
+// Parse reads a webhook body. Bodies that aren't a JSON object are taken as
+// plain-text alerts.
+func Parse(body []byte) (*Alert, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return &Alert{Text: string(trimmed)}, nil
+	}
+	var a Alert
+	if err := json.Unmarshal(trimmed, &a); err != nil {
+		return nil, fmt.Errorf("invalid alert JSON: %v", err)
+	}
+	a.Action = strings.ToUpper(strings.TrimSpace(a.Action))
+	switch a.Action {
+	case "LONG":
+		a.Action = "BUY"
+	case "SHORT":
+		a.Action = "SELL"
+	}
+	return &a, nil
+}
+
+// GeneratedAt parses the alert's time ({{timenow}} or {{time}}), or returns
+// the zero time
+func (a *Alert) GeneratedAt() time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(a.Time))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Redact returns body without its secret field, for the delivery log
+func Redact(body []byte) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return string(body)
+	}
+	if _, ok := fields["secret"]; !ok {
+		return string(body)
+	}
+	delete(fields, "secret")
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// NormalizeSymbol turns a TradingView ticker into an exchange and the
+// symbols it may stand for, most likely first. Tickers may carry an
+// exchange prefix (NSE:RELIANCE) or a Yahoo-style suffix (RELIANCE.NS), and
+// TradingView writes & and - in NSE symbols as _ (M_M, BAJAJ_AUTO).
+func NormalizeSymbol(ticker, exchange string) (string, []string) {
+	symbol := strings.ToUpper(strings.TrimSpace(ticker))
+	exchange = strings.ToUpper(strings.TrimSpace(exchange))
+	if prefix, rest, ok := strings.Cut(symbol, ":"); ok {
+		exchange, symbol = prefix, rest
+	}
+	switch {
+	case strings.HasSuffix(symbol, ".NS"):
+		exchange, symbol = "NSE", strings.TrimSuffix(symbol, ".NS")
+	case strings.HasSuffix(symbol, ".BO"):
+		exchange, symbol = "BSE", strings.TrimSuffix(symbol, ".BO")
+	}
+	if symbol == "" {
+		return exchange, nil
+	}
+
+	candidates := []string{symbol}
+	if strings.Contains(symbol, "_") {
+		candidates = append(candidates,
+			strings.ReplaceAll(symbol, "_", "&"),
+			strings.ReplaceAll(symbol, "_", "-"))
+	}
+	return exchange, candidates
+}
+
+// NewSecret returns a random webhook secret
+func NewSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashSecret returns the stored form of a secret
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
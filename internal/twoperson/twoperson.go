@@ -0,0 +1,117 @@
+// Package twoperson configures the two-person rule for destructive admin
+// actions. When an action is listed in TWO_PERSON_ACTIONS, the first call
+// only records a request; a different admin must repeat the identical call
+// with the request's confirmation ID within TWO_PERSON_WINDOW_MINUTES for
+// it to run.
+//
+// Switching maintenance mode is deliberately not an action: requests are
+// recorded in the database, and maintenance has to be switchable while the
+// database is down. The service has no kill switch or model promotion
+// endpoint; bulk stock changes arrive through config.import or
+// stock-config.import.
+package twoperson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries the ID of the request a call confirms
+const Header = "X-Confirmation-ID"
+
+// Actions that can be placed under the rule
+const (
+	RetentionRun         = "retention.run"
+	ConfigImport         = "config.import"
+	LifecycleRun         = "storage.lifecycle.run"
+	InboundWebhookDelete = "inbound-webhook.delete"
+	StockConfigImport    = "stock-config.import"
+)
+
+// Actions lists every action that can be placed under the rule
+var Actions = []string{RetentionRun, ConfigImport, LifecycleRun, InboundWebhookDelete, StockConfigImport}
+
+// defaultWindow is how long a request waits for its confirmation
+const defaultWindow = 15 * time.Minute
+
+// Rule says which actions need a second admin
+type Rule struct {
+	actions map[string]bool
+	window  time.Duration
+}
+
+// New creates a rule covering actions
+func New(actions []string, window time.Duration) *Rule {
+	r := &Rule{actions: map[string]bool{}, window: window}
+	for _, a := range actions {
+		r.actions[a] = true
+	}
+	return r
+}
+
+// FromEnv reads TWO_PERSON_ACTIONS, a comma-separated list of actions or
+// "all" (unset covers none), and TWO_PERSON_WINDOW_MINUTES (default 15).
+// Unknown actions are logged and ignored.
+func FromEnv() *Rule {
+	window := defaultWindow
+	if v, err := strconv.Atoi(os.Getenv("TWO_PERSON_WINDOW_MINUTES")); err == nil && v > 0 {
+		window = time.Duration(v) * time.Minute
+	}
+	known := map[string]bool{}
+	for _, a := range Actions {
+		known[a] = true
+	}
+	var actions []string
+	for _, a := range strings.Split(os.Getenv("TWO_PERSON_ACTIONS"), ",") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		switch {
+		case a == "":
+		case a == "all":
+			actions = append(actions, Actions...)
+		case known[a]:
+			actions = append(actions, a)
+		default:
+			log.Printf("⚠️  Unknown two-person action %q ignored", a)
+		}
+	}
+	r := New(actions, window)
+	if len(r.actions) > 0 {
+		log.Printf("✅ Two-person rule for %s (window %s)", strings.Join(r.Covered(), ", "), window)
+	}
+	return r
+}
+
+// Requires reports whether action needs a second admin
+func (r *Rule) Requires(action string) bool {
+	return r.actions[action]
+}
+
+// Covered lists the actions under the rule
+func (r *Rule) Covered() []string {
+	covered := make([]string, 0, len(r.actions))
+	for a := range r.actions {
+		covered = append(covered, a)
+	}
+	sort.Strings(covered)
+	return covered
+}
+
+// Window is how long a request waits for its confirmation
+func (r *Rule) Window() time.Duration {
+	return r.window
+}
+
+// PayloadHash identifies a call so a confirmation can only run exactly
+// what was requested
+func PayloadHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
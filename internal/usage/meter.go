@@ -0,0 +1,331 @@
+// Package usage meters what each account uses per day (API calls,
+// WebSocket messages and export volume) against soft daily quotas.
+//
+// Counts are kept in memory and added to the stored totals every
+// flushInterval, so metering never puts a query on the request path. Days
+// run midnight to midnight IST. Quotas only warn: nothing is refused for
+// going over one.
+package usage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/database"
+)
+
+// Metrics quotas are set on
+const (
+	MetricAPICalls    = "api_calls"
+	MetricWSMessages  = "ws_messages"
+	MetricExportBytes = "export_bytes"
+)
+
+// Metrics lists every metric with a quota
+var Metrics = []string{MetricAPICalls, MetricWSMessages, MetricExportBytes}
+
+// warnFraction of a quota used starts warnings
+const warnFraction = 0.8
+
+// flushInterval is how often counts are stored and quota overrides reread
+const flushInterval = 30 * time.Second
+
+// Limits are an account's daily soft quotas; 0 means no quota
+type Limits struct {
+	APICalls    int64 `json:"api_calls"`
+	WSMessages  int64 `json:"ws_messages"`
+	ExportBytes int64 `json:"export_bytes"`
+}
+
+// Get returns the limit on metric
+func (l Limits) Get(metric string) int64 {
+	switch metric {
+	case MetricAPICalls:
+		return l.APICalls
+	case MetricWSMessages:
+		return l.WSMessages
+	case MetricExportBytes:
+		return l.ExportBytes
+	}
+	return 0
+}
+
+// Used returns how much of metric u counts
+func Used(u database.UsageCounts, metric string) int64 {
+	switch metric {
+	case MetricAPICalls:
+		return u.APICalls
+	case MetricWSMessages:
+		return u.WSMessages
+	case MetricExportBytes:
+		return u.ExportBytes
+	}
+	return 0
+}
+
+// Warning reports a metric nearing or over its quota
+type Warning struct {
+	Metric string  `json:"metric"`
+	Used   int64   `json:"used"`
+	Limit  int64   `json:"limit"`
+	Pct    float64 `json:"pct"`
+	// Exceeded is set once usage passes the quota
+	Exceeded bool `json:"exceeded"`
+}
+
+// Meter counts usage per account and day and flushes it to the database
+type Meter struct {
+	db  database.UsageRepository
+	loc *time.Location
+
+	mu sync.Mutex
+	// day is the current IST day; base holds its stored totals
+	day  string
+	base map[string]database.UsageCounts
+	// pending holds counts not yet flushed, by day and account
+	pending   map[string]map[string]database.UsageCounts
+	defaults  Limits
+	overrides map[string]database.AccountQuota
+}
+
+// NewMeter creates a usage meter with no default quotas
+func NewMeter(db database.UsageRepository) *Meter {
+	loc := time.UTC
+	if ist, err := time.LoadLocation("Asia/Kolkata"); err == nil {
+		loc = ist
+	}
+	return &Meter{
+		db:        db,
+		loc:       loc,
+		base:      map[string]database.UsageCounts{},
+		pending:   map[string]map[string]database.UsageCounts{},
+		overrides: map[string]database.AccountQuota{},
+	}
+}
+
+// CountAPICall counts an API call and returns the account's usage today
+func (m *Meter) CountAPICall(accountID string) database.UsageCounts {
+	return m.add(accountID, database.UsageCounts{APICalls: 1})
+}
+
+// CountWSMessages counts n WebSocket messages sent
+func (m *Meter) CountWSMessages(accountID string, n int) {
+	m.add(accountID, database.UsageCounts{WSMessages: int64(n)})
+}
+
+// CountExport counts a completed export
+func (m *Meter) CountExport(accountID string, rows, sizeBytes int64) {
+	m.add(accountID, database.UsageCounts{ExportRows: rows, ExportBytes: sizeBytes})
+}
+
+// add counts d against today and returns the account's usage today
+func (m *Meter) add(accountID string, d database.UsageCounts) database.UsageCounts {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	day := m.rollover()
+	pending := m.pending[day]
+	if pending == nil {
+		pending = map[string]database.UsageCounts{}
+		m.pending[day] = pending
+	}
+	pending[accountID] = pending[accountID].Add(d)
+	return m.base[accountID].Add(pending[accountID])
+}
+
+// rollover starts a new day's totals at midnight and returns the current
+// day. Callers hold mu.
+func (m *Meter) rollover() string {
+	day := time.Now().In(m.loc).Format("2006-01-02")
+	if day != m.day {
+		m.day = day
+		m.base = map[string]database.UsageCounts{}
+	}
+	return day
+}
+
+// Today returns an account's usage so far today
+func (m *Meter) Today(accountID string) database.UsageCounts {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	day := m.rollover()
+	return m.base[accountID].Add(m.pending[day][accountID])
+}
+
+// SetDefault sets the daily quota on metric for accounts without an
+// override; 0 lifts it
+func (m *Meter) SetDefault(metric string, limit int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch metric {
+	case MetricAPICalls:
+		m.defaults.APICalls = limit
+	case MetricWSMessages:
+		m.defaults.WSMessages = limit
+	case MetricExportBytes:
+		m.defaults.ExportBytes = limit
+	}
+}
+
+// SetOverride applies an account's quota overrides; nil clears them
+func (m *Meter) SetOverride(accountID string, q *database.AccountQuota) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q == nil {
+		delete(m.overrides, accountID)
+		return
+	}
+	m.overrides[accountID] = *q
+}
+
+// Limits returns an account's daily quotas: its overrides, else the
+// defaults
+func (m *Meter) Limits(accountID string) Limits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l := m.defaults
+	if q, ok := m.overrides[accountID]; ok {
+		if q.APICalls != nil {
+			l.APICalls = *q.APICalls
+		}
+		if q.WSMessages != nil {
+			l.WSMessages = *q.WSMessages
+		}
+		if q.ExportBytes != nil {
+			l.ExportBytes = *q.ExportBytes
+		}
+	}
+	return l
+}
+
+// Warnings returns the metrics of used at or past warnFraction of their
+// quota in limits
+func Warnings(used database.UsageCounts, limits Limits) []Warning {
+	var warnings []Warning
+	for _, metric := range Metrics {
+		limit := limits.Get(metric)
+		if limit <= 0 {
+			continue
+		}
+		u := Used(used, metric)
+		if float64(u) < warnFraction*float64(limit) {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Metric:   metric,
+			Used:     u,
+			Limit:    limit,
+			Pct:      Pct(u, limit),
+			Exceeded: u > limit,
+		})
+	}
+	return warnings
+}
+
+// Pct is used as a percentage of limit, or 0 without a limit
+func Pct(used, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used*10000/limit) / 100
+}
+
+// Load reads today's stored totals and the quota overrides, so counts
+// resume where the last run stopped
+func (m *Meter) Load(ctx context.Context) error {
+	m.mu.Lock()
+	day := m.rollover()
+	m.mu.Unlock()
+	t, _ := time.ParseInLocation("2006-01-02", day, m.loc)
+	stored, err := m.db.GetAccountUsageDay(ctx, t)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	if m.day == day {
+		for accountID, u := range stored {
+			m.base[accountID] = u
+		}
+	}
+	m.mu.Unlock()
+	return m.loadOverrides(ctx)
+}
+
+// loadOverrides rereads quota overrides, picking up changes made through
+// other instances
+func (m *Meter) loadOverrides(ctx context.Context) error {
+	quotas, err := m.db.ListAccountQuotas(ctx)
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]database.AccountQuota, len(quotas))
+	for _, q := range quotas {
+		overrides[q.AccountID] = q
+	}
+	m.mu.Lock()
+	m.overrides = overrides
+	m.mu.Unlock()
+	return nil
+}
+
+// Flush adds pending counts to the stored totals. Counts that fail to
+// store are kept for the next flush.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = map[string]map[string]database.UsageCounts{}
+	m.mu.Unlock()
+
+	var firstErr error
+	for day, deltas := range pending {
+		t, _ := time.ParseInLocation("2006-01-02", day, m.loc)
+		totals, err := m.db.AddAccountUsage(ctx, t, deltas)
+		m.mu.Lock()
+		if err != nil {
+			if m.pending[day] == nil {
+				m.pending[day] = map[string]database.UsageCounts{}
+			}
+			for accountID, d := range deltas {
+				m.pending[day][accountID] = m.pending[day][accountID].Add(d)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else if day == m.day {
+			for accountID, t := range totals {
+				m.base[accountID] = t
+			}
+		}
+		m.mu.Unlock()
+	}
+	return firstErr
+}
+
+// Run flushes counts and rereads quota overrides every flushInterval until
+// ctx is cancelled, then flushes once more
+func (m *Meter) Run(ctx context.Context) {
+	if err := m.Load(ctx); err != nil {
+		log.Printf("⚠️  Failed to load account usage: %v", err)
+	}
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := m.Flush(flushCtx); err != nil {
+				log.Printf("❌ Failed to flush account usage: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := m.Flush(ctx); err != nil {
+				log.Printf("❌ Failed to flush account usage: %v", err)
+			}
+			if err := m.loadOverrides(ctx); err != nil {
+				log.Printf("⚠️  Failed to reload account quotas: %v", err)
+			}
+		}
+	}
+}
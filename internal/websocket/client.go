@@ -1,7 +1,10 @@
 package websocket
 
 import (
+	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -25,19 +28,76 @@ const (
 type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
+
+	// send is the client's outbound queue, drained by WritePump
 	send chan []byte
+
+	// done is closed when the hub drops the client; send is never closed
+	// so fan-out workers can't race a close
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// consecutiveDrops counts messages missed in a row on a full queue
+	consecutiveDrops atomic.Uint32
+
+	// qos is set by the client's subscribe and unsubscribe messages
+	qos qos
+
+	// compact clients receive broadcasts with short field names and
+	// without zero values, see package compact
+	compact bool
+
+	// userID is who connected
+	userID string
+
+	// accountID is the account the client acts in: messages sent are
+	// metered against it, and follows is what it follows, consulted when
+	// the client subscribes to TopicMine
+	accountID string
+	follows   atomic.Pointer[Follows]
 }
 
-// NewClient creates a new WebSocket client
-func NewClient(hub *Hub, conn *websocket.Conn) *Client {
-	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 256),
+// NewClient creates a new WebSocket client for userID acting in accountID,
+// which follows follows. A compact client gets every broadcast in compact
+// form.
+func NewClient(hub *Hub, conn *websocket.Conn, compact bool, userID, accountID string, follows *Follows) *Client {
+	c := &Client{
+		hub:       hub,
+		conn:      conn,
+		send:      make(chan []byte, 256),
+		done:      make(chan struct{}),
+		compact:   compact,
+		userID:    userID,
+		accountID: accountID,
 	}
+	c.follows.Store(follows)
+	return c
 }
 
-// ReadPump pumps messages from the WebSocket connection to the hub
+// enqueue queues message without blocking, reporting whether it fit
+func (c *Client) enqueue(message []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- message:
+		c.consecutiveDrops.Store(0)
+		return true
+	default:
+		c.consecutiveDrops.Add(1)
+		return false
+	}
+}
+
+// close tells WritePump to send a close frame and stop
+func (c *Client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// ReadPump reads the client's QoS control messages until the connection
+// closes, then unregisters it
 func (c *Client) ReadPump() {
 	defer func() {
 		c.hub.Unregister(c)
@@ -51,13 +111,18 @@ func (c *Client) ReadPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+
+		// Clients only send QoS control messages
+		if ack, err := json.Marshal(c.qos.apply(message)); err == nil {
+			c.enqueue(ack)
+		}
 	}
 }
 
@@ -71,14 +136,14 @@ func (c *Client) WritePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
+		case <-c.done:
+			// Hub dropped the client
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// Hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
 
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
@@ -95,6 +160,9 @@ func (c *Client) WritePump() {
 			if err := w.Close(); err != nil {
 				return
 			}
+			if c.hub.meter != nil {
+				c.hub.meter(c.accountID, n+1)
+			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
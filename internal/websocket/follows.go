@@ -0,0 +1,50 @@
+package websocket
+
+// TopicMine narrows the signals topic: a client subscribed to it is only
+// sent signal messages about the symbols and strategies its account follows
+const TopicMine = "mine"
+
+// Subject is what a signals message is about
+type Subject struct {
+	Symbol   string
+	Strategy string
+}
+
+// Follows is the set of symbols and strategies an account follows
+type Follows struct {
+	symbols    map[string]bool
+	strategies map[string]bool
+}
+
+// NewFollows builds a follow set
+func NewFollows(symbols, strategies []string) *Follows {
+	f := &Follows{symbols: map[string]bool{}, strategies: map[string]bool{}}
+	for _, s := range symbols {
+		f.symbols[s] = true
+	}
+	for _, s := range strategies {
+		f.strategies[s] = true
+	}
+	return f
+}
+
+// matches reports whether subject is followed; a nil set follows nothing
+func (f *Follows) matches(subject Subject) bool {
+	if f == nil {
+		return false
+	}
+	return (subject.Symbol != "" && f.symbols[subject.Symbol]) ||
+		(subject.Strategy != "" && f.strategies[subject.Strategy])
+}
+
+// SetFollows replaces what accountID follows on each connection acting in
+// it
+func (h *Hub) SetFollows(accountID string, follows *Follows) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.accountID == accountID {
+			client.follows.Store(follows)
+		}
+	}
+}
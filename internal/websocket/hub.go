@@ -3,16 +3,61 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-chitti/core-api-go/internal/compact"
+)
+
+const (
+	// maxConsecutiveDrops is how many messages in a row a client may miss
+	// because its queue is full before it is disconnected as too slow
+	maxConsecutiveDrops = 64
+
+	// minFanoutBatch is the fewest clients handed to one fan-out worker, so
+	// small audiences are served by a single worker
+	minFanoutBatch = 64
+
+	// maxFanoutWorkers caps the fan-out worker pool
+	maxFanoutWorkers = 16
 )
 
+// outbound is a broadcast message with the topic and key its QoS is
+// applied by
+type outbound struct {
+	topic string
+	key   string
+	// subject is what a signals message is about, for TopicMine
+	subject Subject
+	data    []byte
+	// compact is data in compact form, encoded only when a compact client
+	// is connected
+	compact []byte
+}
+
+// fanoutJob asks a worker to enqueue message for a batch of clients
+type fanoutJob struct {
+	message outbound
+	clients []*Client
+	done    *sync.WaitGroup
+	// slow collects clients that crossed maxConsecutiveDrops
+	slow *slowClients
+}
+
+type slowClients struct {
+	mu      sync.Mutex
+	clients []*Client
+}
+
 // Hub maintains active WebSocket connections and broadcasts messages
 type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Inbound messages from clients
-	broadcast chan []byte
+	// Messages waiting to be fanned out
+	broadcast chan outbound
 
 	// Register requests from clients
 	register chan *Client
@@ -22,64 +67,295 @@ type Hub struct {
 
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
+
+	// First message sent to each new client, in full and compact form
+	hello        []byte
+	helloCompact []byte
+
+	// drop, if set, is asked before each per-client send whether to
+	// discard the message instead
+	drop func() bool
+
+	// meter, if set, is told how many messages each write sent a client
+	meter func(accountID string, messages int)
+
+	// tickInterval is the minimum time between ticks for the same key sent
+	// to any client, in nanoseconds; 0 sends every tick
+	tickInterval atomic.Int64
+
+	// Fan-out worker pool; workers only enqueue onto client queues and
+	// never touch a connection
+	workers int
+	jobs    chan fanoutJob
+
+	// Counters for load testing and monitoring
+	delivered       atomic.Uint64
+	throttled       atomic.Uint64
+	unfollowed      atomic.Uint64
+	droppedMessages atomic.Uint64
+	droppedSlow     atomic.Uint64
+	blockedQueued   atomic.Uint64
+}
+
+// HubStats describes fan-out throughput and backpressure
+type HubStats struct {
+	Clients int `json:"clients"`
+	// QueueDepth is how many messages wait to be fanned out
+	QueueDepth    int `json:"queue_depth"`
+	QueueCapacity int `json:"queue_capacity"`
+	// Workers is the size of the fan-out worker pool
+	Workers int `json:"workers"`
+	// Delivered counts per-client sends
+	Delivered uint64 `json:"delivered"`
+	// Throttled were held back by a client's per-topic QoS or the tick
+	// interval
+	Throttled uint64 `json:"throttled"`
+	// Unfollowed were signals held back from TopicMine clients not
+	// following them
+	Unfollowed uint64 `json:"unfollowed"`
+	// DroppedMessages were not queued because a client's queue was full
+	DroppedMessages uint64 `json:"dropped_messages"`
+	// DroppedClients were disconnected after missing maxConsecutiveDrops
+	// messages in a row
+	DroppedClients uint64 `json:"dropped_clients"`
+	// BlockedBroadcasts had to wait for room in the queue
+	BlockedBroadcasts uint64 `json:"blocked_broadcasts"`
 }
 
 // NewHub creates a new WebSocket hub
 func NewHub() *Hub {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > maxFanoutWorkers {
+		workers = maxFanoutWorkers
+	}
 	return &Hub{
-		broadcast:  make(chan []byte, 256),
+		broadcast:  make(chan outbound, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
+		workers:    workers,
+		jobs:       make(chan fanoutJob, workers),
 	}
 }
 
-// Run starts the hub's main loop
+// Run starts the hub's main loop and its fan-out workers
 func (h *Hub) Run() {
+	for i := 0; i < h.workers; i++ {
+		go h.fanoutWorker()
+	}
+
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			total := len(h.clients)
 			h.mu.Unlock()
-			log.Printf("✅ WebSocket client connected (total: %d)", len(h.clients))
+			if h.hello != nil {
+				hello := h.hello
+				if client.compact {
+					hello = h.helloCompact
+				}
+				client.enqueue(hello)
+			}
+			log.Printf("✅ WebSocket client connected (total: %d)", total)
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
-			h.mu.Unlock()
-			log.Printf("👋 WebSocket client disconnected (total: %d)", len(h.clients))
+			h.remove(client)
+			log.Printf("👋 WebSocket client disconnected (total: %d)", h.ClientCount())
 
 		case message := <-h.broadcast:
-			h.mu.Lock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					// Client's send channel is full, remove it
-					delete(h.clients, client)
-					close(client.send)
-				}
+			h.fanout(message)
+		}
+	}
+}
+
+// fanout splits the current clients across the worker pool and waits for
+// every batch to be queued, so each client sees broadcasts in order
+func (h *Hub) fanout(message outbound) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	wantCompact := false
+	for client := range h.clients {
+		clients = append(clients, client)
+		wantCompact = wantCompact || client.compact
+	}
+	h.mu.RUnlock()
+	if len(clients) == 0 {
+		return
+	}
+	if wantCompact {
+		var err error
+		if message.compact, err = compact.Encode(message.data); err != nil {
+			log.Printf("⚠️  Failed to compact WebSocket message: %v", err)
+			message.compact = message.data
+		}
+	}
+
+	batch := (len(clients) + h.workers - 1) / h.workers
+	if batch < minFanoutBatch {
+		batch = minFanoutBatch
+	}
+	var done sync.WaitGroup
+	slow := &slowClients{}
+	for start := 0; start < len(clients); start += batch {
+		end := start + batch
+		if end > len(clients) {
+			end = len(clients)
+		}
+		done.Add(1)
+		h.jobs <- fanoutJob{message: message, clients: clients[start:end], done: &done, slow: slow}
+	}
+	done.Wait()
+
+	for _, client := range slow.clients {
+		if h.remove(client) {
+			h.droppedSlow.Add(1)
+			log.Printf("⚠️  WebSocket client disconnected as too slow (total: %d)", h.ClientCount())
+		}
+	}
+}
+
+func (h *Hub) fanoutWorker() {
+	for job := range h.jobs {
+		now := time.Now()
+		var floor time.Duration
+		if job.message.topic == TopicTicks {
+			floor = time.Duration(h.tickInterval.Load())
+		}
+		for _, client := range job.clients {
+			if h.drop != nil && h.drop() {
+				continue
+			}
+			if !client.qos.allows(job.message.topic, job.message.key, now, floor) {
+				h.throttled.Add(1)
+				continue
+			}
+			if job.message.topic == TopicSignals && client.qos.mineOnly() && !client.follows.Load().matches(job.message.subject) {
+				h.unfollowed.Add(1)
+				continue
+			}
+			payload := job.message.data
+			if client.compact {
+				payload = job.message.compact
+			}
+			if client.enqueue(payload) {
+				h.delivered.Add(1)
+				continue
+			}
+			h.droppedMessages.Add(1)
+			if client.consecutiveDrops.Load() >= maxConsecutiveDrops {
+				job.slow.mu.Lock()
+				job.slow.clients = append(job.slow.clients, client)
+				job.slow.mu.Unlock()
 			}
-			h.mu.Unlock()
 		}
+		job.done.Done()
+	}
+}
+
+// remove forgets a client and stops its writer, reporting whether it was
+// still registered
+func (h *Hub) remove(client *Client) bool {
+	h.mu.Lock()
+	_, ok := h.clients[client]
+	delete(h.clients, client)
+	h.mu.Unlock()
+	if ok {
+		client.close()
 	}
+	return ok
 }
 
-// Broadcast sends a message to all connected clients
+// Broadcast sends a message to all connected clients, subject to each
+// client's QoS for the message's topic
 func (h *Hub) Broadcast(data interface{}) error {
-	message, err := json.Marshal(data)
+	return h.BroadcastKeyed("", data)
+}
+
+// BroadcastKeyed is Broadcast for a stream of independent updates, such as
+// ticks keyed by symbol: throttles apply per key, so a slowed tick stream
+// still carries every symbol
+func (h *Hub) BroadcastKeyed(key string, data interface{}) error {
+	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
+	h.enqueue(outbound{topic: topicOf(data), key: key, data: payload})
+	return nil
+}
 
-	h.broadcast <- message
+// BroadcastAbout is Broadcast for a signals message about subject; clients
+// subscribed to TopicMine only get it if their user follows the subject
+func (h *Hub) BroadcastAbout(subject Subject, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	h.enqueue(outbound{topic: topicOf(data), subject: subject, data: payload})
 	return nil
 }
 
+// enqueue queues message for fan-out, blocking if the queue is full
+func (h *Hub) enqueue(message outbound) {
+	select {
+	case h.broadcast <- message:
+	default:
+		h.blockedQueued.Add(1)
+		h.broadcast <- message
+	}
+}
+
+// SetHello sets the message each client receives on connecting. It must be
+// called before Run.
+func (h *Hub) SetHello(data interface{}) error {
+	message, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	compacted, err := compact.Encode(message)
+	if err != nil {
+		return err
+	}
+	h.hello, h.helloCompact = message, compacted
+	return nil
+}
+
+// SetDropFilter makes the hub discard broadcast messages for which drop
+// returns true, deciding per client. It's used by resilience drills and must
+// be called before Run.
+func (h *Hub) SetDropFilter(drop func() bool) {
+	h.drop = drop
+}
+
+// SetMeter makes the hub report the messages it writes to each client
+// against the client's account. It must be called before Run.
+func (h *Hub) SetMeter(meter func(accountID string, messages int)) {
+	h.meter = meter
+}
+
+// SetTickInterval sets the minimum time between ticks for the same symbol
+// sent to any client. Clients may still ask for a longer one; 0 lifts it.
+func (h *Hub) SetTickInterval(d time.Duration) {
+	h.tickInterval.Store(int64(d))
+}
+
+// Stats returns fan-out counters since the hub started
+func (h *Hub) Stats() HubStats {
+	return HubStats{
+		Clients:           h.ClientCount(),
+		QueueDepth:        len(h.broadcast),
+		QueueCapacity:     cap(h.broadcast),
+		Workers:           h.workers,
+		Delivered:         h.delivered.Load(),
+		Throttled:         h.throttled.Load(),
+		Unfollowed:        h.unfollowed.Load(),
+		DroppedMessages:   h.droppedMessages.Load(),
+		DroppedClients:    h.droppedSlow.Load(),
+		BlockedBroadcasts: h.blockedQueued.Load(),
+	}
+}
+
 // ClientCount returns the number of connected clients
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
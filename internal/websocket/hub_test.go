@@ -0,0 +1,123 @@
+package websocket
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestHub starts a hub's fan-out workers without its main loop, so
+// tests can call fanout directly
+func newTestHub(tb testing.TB, workers int) *Hub {
+	h := NewHub()
+	h.workers = workers
+	h.jobs = make(chan fanoutJob, workers)
+	for i := 0; i < workers; i++ {
+		go h.fanoutWorker()
+	}
+	tb.Cleanup(func() { close(h.jobs) })
+	return h
+}
+
+// addClients registers n clients. Unless stalled, each has a reader
+// draining its queue the way WritePump does, minus the socket.
+func addClients(tb testing.TB, h *Hub, n int, stalled bool) []*Client {
+	clients := make([]*Client, n)
+	for i := range clients {
+		c := NewClient(h, nil, false, fmt.Sprintf("user-%d", i), "", nil)
+		clients[i] = c
+		h.clients[c] = true
+		if !stalled {
+			go func() {
+				for {
+					select {
+					case <-c.send:
+					case <-c.done:
+						return
+					}
+				}
+			}()
+		}
+		tb.Cleanup(c.close)
+	}
+	return clients
+}
+
+var benchMessage = outbound{topic: TopicSignals, data: []byte(`{"type":"signal","data":{"symbol":"RELIANCE","signal_type":"BUY","confidence_score":0.82}}`)}
+
+// BenchmarkFanout1000Clients measures one broadcast queued for 1,000
+// clients, by worker pool size
+func BenchmarkFanout1000Clients(b *testing.B) {
+	for _, workers := range []int{1, 4, maxFanoutWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			h := newTestHub(b, workers)
+			addClients(b, h, 1000, false)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.fanout(benchMessage)
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*1000), "ns/client")
+		})
+	}
+}
+
+// BenchmarkFanout1000ClientsWithStalled is BenchmarkFanout1000Clients with
+// 50 clients that never read: they cost a dropped message each until they
+// are disconnected, and never hold up the rest
+func BenchmarkFanout1000ClientsWithStalled(b *testing.B) {
+	h := newTestHub(b, maxFanoutWorkers)
+	addClients(b, h, 950, false)
+	addClients(b, h, 50, true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.fanout(benchMessage)
+	}
+	b.StopTimer()
+	stats := h.Stats()
+	b.ReportMetric(float64(stats.DroppedMessages)/float64(b.N), "drops/op")
+}
+
+// drain empties each client's queue, standing in for a reader that keeps
+// up with every broadcast
+func drain(clients []*Client) {
+	for _, c := range clients {
+		for len(c.send) > 0 {
+			<-c.send
+		}
+	}
+}
+
+func TestFanoutDropsStalledClients(t *testing.T) {
+	h := newTestHub(t, 4)
+	readers := addClients(t, h, 200, true)
+	stalled := addClients(t, h, 3, true)
+
+	queue := cap(stalled[0].send)
+	broadcasts := queue + maxConsecutiveDrops
+	for i := 0; i < broadcasts; i++ {
+		h.fanout(benchMessage)
+		drain(readers)
+	}
+
+	stats := h.Stats()
+	if stats.Clients != 200 {
+		t.Errorf("%d clients left, want the 200 that read", stats.Clients)
+	}
+	if stats.DroppedClients != 3 {
+		t.Errorf("dropped %d clients as slow, want 3", stats.DroppedClients)
+	}
+	if want := uint64(200*broadcasts + 3*queue); stats.Delivered != want {
+		t.Errorf("delivered %d messages, want %d", stats.Delivered, want)
+	}
+	if want := uint64(3 * maxConsecutiveDrops); stats.DroppedMessages != want {
+		t.Errorf("dropped %d messages, want %d", stats.DroppedMessages, want)
+	}
+	for _, c := range stalled {
+		select {
+		case <-c.done:
+		default:
+			t.Error("stalled client was not closed")
+		}
+	}
+}
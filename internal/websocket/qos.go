@@ -0,0 +1,183 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Topics clients can set QoS on. Every broadcast message type belongs to
+// one; types not listed here fall under TopicOther.
+const (
+	TopicTicks   = "ticks"
+	TopicSignals = "signals"
+	TopicAlerts  = "alerts"
+	TopicMarket  = "market"
+	TopicRisk    = "risk"
+	TopicSystem  = "system"
+	TopicOther   = "other"
+)
+
+// messageTopics maps broadcast message types to their topic
+var messageTopics = map[string]string{
+	"market_tick":          TopicTicks,
+	"signal_new":           TopicSignals,
+	"signal_updated":       TopicSignals,
+	"signal_closed":        TopicSignals,
+	"signal_proximity":     TopicSignals,
+	"basket_alert":         TopicAlerts,
+	"regime_changed":       TopicMarket,
+	"risk_snapshot":        TopicRisk,
+	"maintenance":          TopicSystem,
+	"reconciliation_alert": TopicSystem,
+}
+
+// maxMinInterval caps how far a client may throttle a topic
+const maxMinInterval = time.Minute
+
+// Topics lists the topics clients can subscribe to, sorted
+func Topics() []string {
+	seen := map[string]bool{TopicOther: true, TopicMine: true}
+	for _, t := range messageTopics {
+		seen[t] = true
+	}
+	topics := make([]string, 0, len(seen))
+	for t := range seen {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// topicOf returns the topic of a broadcast payload from its "type" field
+func topicOf(data interface{}) string {
+	if m, ok := data.(map[string]interface{}); ok {
+		if typ, ok := m["type"].(string); ok {
+			if topic, ok := messageTopics[typ]; ok {
+				return topic
+			}
+		}
+	}
+	return TopicOther
+}
+
+// subscription is a client's QoS for one topic. A muted topic is not
+// delivered; otherwise messages for the same key closer together than
+// minInterval are dropped.
+type subscription struct {
+	muted       bool
+	minInterval time.Duration
+	last        map[string]time.Time
+}
+
+// qos holds a client's per-topic subscriptions. Topics without one are
+// delivered in real time, unless the server sets a floor.
+type qos struct {
+	mu     sync.Mutex
+	topics map[string]*subscription
+	// last is when each key was sent on topics without a subscription,
+	// kept only while the server sets a floor
+	last map[string]time.Time
+}
+
+// allows reports whether a message for topic and key may be sent now,
+// recording it as sent if so. floor is the server's minimum interval for
+// the topic; a client can only ask for a longer one.
+func (q *qos) allows(topic, key string, now time.Time, floor time.Duration) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sub, ok := q.topics[topic]
+	if ok && sub.muted {
+		return false
+	}
+	interval := floor
+	last := q.last
+	if ok {
+		if sub.minInterval > interval {
+			interval = sub.minInterval
+		}
+		last = sub.last
+	}
+	if interval == 0 {
+		return true
+	}
+	if last == nil {
+		q.last = map[string]time.Time{}
+		last = q.last
+	}
+	sentKey := topic + "|" + key
+	if sent, ok := last[sentKey]; ok && now.Sub(sent) < interval {
+		return false
+	}
+	last[sentKey] = now
+	return true
+}
+
+// mineOnly reports whether the client subscribed to TopicMine
+func (q *qos) mineOnly() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sub, ok := q.topics[TopicMine]
+	return ok && !sub.muted
+}
+
+// controlMessage is sent by clients to change their QoS:
+//
+//	{"action": "subscribe", "topic": "ticks", "min_interval_ms": 2000}
+//	{"action": "unsubscribe", "topic": "ticks"}
+//
+// Subscribing to TopicMine limits the signals topic to followed items and
+// unsubscribing lifts the limit again.
+type controlMessage struct {
+	Action        string `json:"action"`
+	Topic         string `json:"topic"`
+	MinIntervalMs int    `json:"min_interval_ms"`
+}
+
+// apply updates the subscriptions for a control message and returns the
+// acknowledgement to send back
+func (q *qos) apply(raw []byte) map[string]interface{} {
+	var msg controlMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return controlError("invalid control message")
+	}
+	valid := false
+	for _, t := range Topics() {
+		valid = valid || t == msg.Topic
+	}
+	if !valid {
+		return controlError(fmt.Sprintf("unknown topic %q", msg.Topic))
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.topics == nil {
+		q.topics = map[string]*subscription{}
+	}
+	switch msg.Action {
+	case "subscribe":
+		interval := time.Duration(msg.MinIntervalMs) * time.Millisecond
+		if interval < 0 || interval > maxMinInterval {
+			return controlError(fmt.Sprintf("min_interval_ms must be between 0 and %d", maxMinInterval.Milliseconds()))
+		}
+		q.topics[msg.Topic] = &subscription{minInterval: interval, last: map[string]time.Time{}}
+	case "unsubscribe":
+		q.topics[msg.Topic] = &subscription{muted: true}
+	default:
+		return controlError("action must be subscribe or unsubscribe")
+	}
+
+	sub := q.topics[msg.Topic]
+	return map[string]interface{}{
+		"type":            "subscription",
+		"topic":           msg.Topic,
+		"subscribed":      !sub.muted,
+		"min_interval_ms": sub.minInterval.Milliseconds(),
+	}
+}
+
+func controlError(message string) map[string]interface{} {
+	return map[string]interface{}{"type": "subscription_error", "error": message}
+}
@@ -0,0 +1,525 @@
+// Code generated by clientgen from Trading Chitti Core API 1.0.0. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Candle is one OHLCV bucket
+type Candle struct {
+	Close  float64   `json:"close"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Open   float64   `json:"open"`
+	Ticks  int64     `json:"ticks"`
+	Time   time.Time `json:"time"`
+	Volume int64     `json:"volume"`
+}
+
+// CandleSeries is a stock's candles over a range
+type CandleSeries struct {
+	Candles  []Candle  `json:"candles"`
+	Count    int       `json:"count"`
+	From     time.Time `json:"from"`
+	Interval string    `json:"interval"`
+	Symbol   string    `json:"symbol"`
+	To       time.Time `json:"to"`
+}
+
+// MarketIndex is an index's latest level
+type MarketIndex struct {
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"changePercent"`
+	Index         string  `json:"index"`
+	Value         float64 `json:"value"`
+}
+
+// MarketRegime is the current regime with the rules in evaluation order
+type MarketRegime struct {
+	Regime RegimeReading `json:"regime"`
+	Rules  []RegimeRule  `json:"rules"`
+}
+
+// NewsArticle is a news article
+type NewsArticle struct {
+	AffectedStocks []string `json:"affectedStocks"`
+	Category       string   `json:"category"`
+	ClusterID      string   `json:"clusterId"`
+	ClusterSize    int      `json:"clusterSize"`
+	Confidence     float64  `json:"confidence"`
+	ID             string   `json:"id"`
+	Impact         string   `json:"impact"`
+	ImpactScore    *float64 `json:"impactScore"`
+	PriceMovement  float64  `json:"priceMovement"`
+	Sentiment      float64  `json:"sentiment"`
+	SentimentLabel *string  `json:"sentimentLabel"`
+	Source         string   `json:"source"`
+	Summary        *string  `json:"summary"`
+	Time           string   `json:"time"`
+	Title          string   `json:"title"`
+	URL            *string  `json:"url"`
+}
+
+// NewsCluster is the articles reporting one story
+type NewsCluster struct {
+	Articles  []NewsArticle `json:"articles"`
+	ClusterID string        `json:"cluster_id"`
+	Count     int           `json:"count"`
+}
+
+// NewsContext is recent news about a symbol
+type NewsContext struct {
+	ArticleCount         int      `json:"article_count"`
+	LatestHeadline       *string  `json:"latest_headline"`
+	LatestPublishedAt    *string  `json:"latest_published_at"`
+	LatestSentiment      *string  `json:"latest_sentiment"`
+	LatestSentimentScore *float64 `json:"latest_sentiment_score"`
+}
+
+// NewsResponse is a page of news articles
+type NewsResponse struct {
+	Articles []NewsArticle `json:"articles"`
+	HasMore  bool          `json:"hasMore"`
+	Page     int           `json:"page"`
+	Total    int           `json:"total"`
+}
+
+// PositionSize is the Kelly, half-Kelly and fixed-fractional sizes for one set of inputs
+type PositionSize struct {
+	Edge            bool              `json:"edge"`
+	FixedFractional SizeSuggestion    `json:"fixed_fractional"`
+	HalfKelly       SizeSuggestion    `json:"half_kelly"`
+	Inputs          PositionSizeInput `json:"inputs"`
+	Kelly           SizeSuggestion    `json:"kelly"`
+	Notes           []string          `json:"notes"`
+	Stats           *SymbolTradeStats `json:"stats"`
+}
+
+// PositionSizeInput is the inputs a position size was computed from
+type PositionSizeInput struct {
+	Capital         float64  `json:"capital"`
+	EntryPrice      *float64 `json:"entry_price"`
+	PayoffRatio     float64  `json:"payoff_ratio"`
+	RiskFraction    float64  `json:"risk_fraction"`
+	StopDistancePct float64  `json:"stop_distance_pct"`
+	WinRate         float64  `json:"win_rate"`
+}
+
+// RealtimePrice is a stock's current market price
+type RealtimePrice struct {
+	ChangePercent *float64     `json:"change_percent"`
+	Close         float64      `json:"close"`
+	High          float64      `json:"high"`
+	LastPrice     float64      `json:"last_price"`
+	Low           float64      `json:"low"`
+	News          *NewsContext `json:"news"`
+	Open          float64      `json:"open"`
+	Symbol        string       `json:"symbol"`
+	UpdatedAt     string       `json:"updated_at"`
+	Volume        *int64       `json:"volume"`
+}
+
+// RegimeMetrics is the session measures a regime is classified from
+type RegimeMetrics struct {
+	Bars          int     `json:"bars"`
+	ChangePct     float64 `json:"change_pct"`
+	Efficiency    float64 `json:"efficiency"`
+	High          float64 `json:"high"`
+	Last          float64 `json:"last"`
+	Low           float64 `json:"low"`
+	Open          float64 `json:"open"`
+	RangePct      float64 `json:"range_pct"`
+	VolatilityPct float64 `json:"volatility_pct"`
+}
+
+// RegimeReading is a classification of the current session
+type RegimeReading struct {
+	AsOf    string        `json:"as_of"`
+	Index   string        `json:"index"`
+	Metrics RegimeMetrics `json:"metrics"`
+	Regime  string        `json:"regime"`
+	Rule    string        `json:"rule"`
+	Since   string        `json:"since"`
+}
+
+// RegimeRule is one regime rule
+type RegimeRule struct {
+	Condition string `json:"condition"`
+	Regime    string `json:"regime"`
+}
+
+// Signal is a trading signal
+type Signal struct {
+	ActualProfitPct     *float64        `json:"actual_profit_pct"`
+	ClosedAt            *time.Time      `json:"closed_at"`
+	ConfidenceScore     float64         `json:"confidence_score"`
+	CurrentPrice        float64         `json:"current_price"`
+	EntryPrice          float64         `json:"entry_price"`
+	ExitPrice           *float64        `json:"exit_price"`
+	ExitReason          *string         `json:"exit_reason"`
+	ExpiresAt           *time.Time      `json:"expires_at"`
+	GeneratedAt         time.Time       `json:"generated_at"`
+	Metadata            json.RawMessage `json:"metadata"`
+	PredictionFeatures  json.RawMessage `json:"prediction_features"`
+	RecentNewsSentiment *float64        `json:"recent_news_sentiment"`
+	ScalingRules        json.RawMessage `json:"scaling_rules"`
+	Sector              string          `json:"sector"`
+	SignalID            string          `json:"signal_id"`
+	SignalType          string          `json:"signal_type"`
+	Status              string          `json:"status"`
+	StockName           string          `json:"stock_name"`
+	StopLoss            float64         `json:"stop_loss"`
+	Symbol              string          `json:"symbol"`
+	TargetPrice         float64         `json:"target_price"`
+	TimeInForce         *string         `json:"time_in_force"`
+}
+
+// SignalDetail is a signal with its suggested position size
+type SignalDetail struct {
+	Signal
+	PositionSize *PositionSize `json:"position_size"`
+}
+
+// SignalList is a list of signals
+type SignalList struct {
+	Count   int      `json:"count"`
+	Signals []Signal `json:"signals"`
+}
+
+// SizeSuggestion is one sizing method's result
+type SizeSuggestion struct {
+	CapitalAtRisk float64 `json:"capital_at_risk"`
+	Capped        bool    `json:"capped"`
+	Fraction      float64 `json:"fraction"`
+	Notional      float64 `json:"notional"`
+	Quantity      *int64  `json:"quantity"`
+}
+
+// StockData is a stock's price summary
+type StockData struct {
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"changePercent"`
+	MarketCap     float64 `json:"marketCap"`
+	Name          string  `json:"name"`
+	Price         float64 `json:"price"`
+	Symbol        string  `json:"symbol"`
+	Volume        int64   `json:"volume"`
+}
+
+// StockSearchResult is a stock matching a search
+type StockSearchResult struct {
+	Exchange string `json:"exchange"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+}
+
+// SymbolTradeStats is the outcomes of a symbol's past signals
+type SymbolTradeStats struct {
+	AvgLossPct  *float64 `json:"avg_loss_pct"`
+	AvgWinPct   *float64 `json:"avg_win_pct"`
+	Losses      int      `json:"losses"`
+	PayoffRatio *float64 `json:"payoff_ratio"`
+	Since       string   `json:"since"`
+	Symbol      string   `json:"symbol"`
+	Trades      int      `json:"trades"`
+	WinRate     *float64 `json:"win_rate"`
+	Wins        int      `json:"wins"`
+}
+
+// TopMover is one of the day's top gainers or losers
+type TopMover struct {
+	Change     float64 `json:"change"`
+	Confidence float64 `json:"confidence"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+	Symbol     string  `json:"symbol"`
+}
+
+// GetCandlesParams holds the optional query parameters of GetCandles.
+// Zero values are left out so the server defaults apply.
+type GetCandlesParams struct {
+	// Bucket width: 1m, 3m, 5m, 15m, 30m, 1h or 1d (default 5m)
+	Interval string
+	// Range start as YYYY-MM-DD (IST) or RFC3339
+	From string
+	// Range end as YYYY-MM-DD (IST, inclusive) or RFC3339
+	To string
+}
+
+// GetCandles returns OHLCV candles for a stock.
+//
+// GET /api/stocks/{symbol}/candles
+func (c *Client) GetCandles(ctx context.Context, symbol string, params *GetCandlesParams) (*CandleSeries, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Interval != "" {
+			query.Set("interval", params.Interval)
+		}
+		if params.From != "" {
+			query.Set("from", params.From)
+		}
+		if params.To != "" {
+			query.Set("to", params.To)
+		}
+	}
+	var out CandleSeries
+	if err := c.get(ctx, "/api/stocks/"+url.PathEscape(symbol)+"/candles", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMarketRegime returns the current market regime and the rules that classify it.
+//
+// GET /api/market/regime
+func (c *Client) GetMarketRegime(ctx context.Context) (*MarketRegime, error) {
+	var out MarketRegime
+	if err := c.get(ctx, "/api/market/regime", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNewsCluster returns every article reporting the same story, earliest first.
+//
+// GET /api/news/clusters/{id}
+func (c *Client) GetNewsCluster(ctx context.Context, id string) (*NewsCluster, error) {
+	var out NewsCluster
+	if err := c.get(ctx, "/api/news/clusters/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRealtimePrice returns a stock's realtime price with its news context.
+//
+// GET /api/stocks/{symbol}/realtime
+func (c *Client) GetRealtimePrice(ctx context.Context, symbol string) (*RealtimePrice, error) {
+	var out RealtimePrice
+	if err := c.get(ctx, "/api/stocks/"+url.PathEscape(symbol)+"/realtime", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSignal returns a signal with its suggested position size.
+//
+// GET /api/signals/{id}
+func (c *Client) GetSignal(ctx context.Context, id string) (*SignalDetail, error) {
+	var out SignalDetail
+	if err := c.get(ctx, "/api/signals/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStock returns a stock's price summary.
+//
+// GET /api/stocks/{symbol}
+func (c *Client) GetStock(ctx context.Context, symbol string) (*StockData, error) {
+	var out StockData
+	if err := c.get(ctx, "/api/stocks/"+url.PathEscape(symbol), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListActiveSignals lists the active signals.
+//
+// GET /api/signals/active
+func (c *Client) ListActiveSignals(ctx context.Context) (*SignalList, error) {
+	var out SignalList
+	if err := c.get(ctx, "/api/signals/active", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMarketIndices lists the latest index levels.
+//
+// GET /api/market/indices
+func (c *Client) ListMarketIndices(ctx context.Context) ([]MarketIndex, error) {
+	var out []MarketIndex
+	if err := c.get(ctx, "/api/market/indices", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListNewsParams holds the optional query parameters of ListNews.
+// Zero values are left out so the server defaults apply.
+type ListNewsParams struct {
+	// Page size (default 20, at most 100)
+	Limit  int
+	Offset int
+	// Only articles with this sentiment label
+	Sentiment string
+	// Only articles whose title or summary contains this text
+	Search string
+	// Only articles affecting this symbol
+	Symbol string
+	// Collapse near-duplicate articles to one per story (default true)
+	Collapse *bool
+}
+
+// ListNews lists news articles, newest first.
+//
+// GET /api/news
+func (c *Client) ListNews(ctx context.Context, params *ListNewsParams) (*NewsResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Offset != 0 {
+			query.Set("offset", strconv.Itoa(params.Offset))
+		}
+		if params.Sentiment != "" {
+			query.Set("sentiment", params.Sentiment)
+		}
+		if params.Search != "" {
+			query.Set("search", params.Search)
+		}
+		if params.Symbol != "" {
+			query.Set("symbol", params.Symbol)
+		}
+		if params.Collapse != nil {
+			query.Set("collapse", strconv.FormatBool(*params.Collapse))
+		}
+	}
+	var out NewsResponse
+	if err := c.get(ctx, "/api/news", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRealtimePricesParams holds the optional query parameters of ListRealtimePrices.
+// Zero values are left out so the server defaults apply.
+type ListRealtimePricesParams struct {
+	// Maximum prices to return (default 50, at most 500)
+	Limit int
+}
+
+// ListRealtimePrices lists realtime prices.
+//
+// GET /api/stocks/realtime/all
+func (c *Client) ListRealtimePrices(ctx context.Context, params *ListRealtimePricesParams) ([]RealtimePrice, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+	}
+	var out []RealtimePrice
+	if err := c.get(ctx, "/api/stocks/realtime/all", query, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSignalsParams holds the optional query parameters of ListSignals.
+// Zero values are left out so the server defaults apply.
+type ListSignalsParams struct {
+	// Maximum signals to return (default 100)
+	Limit int
+	// Only signals with this status, e.g. ACTIVE or HIT_TARGET
+	Status string
+}
+
+// ListSignals lists signals, newest first.
+//
+// GET /api/signals
+func (c *Client) ListSignals(ctx context.Context, params *ListSignalsParams) (*SignalList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+	}
+	var out SignalList
+	if err := c.get(ctx, "/api/signals", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTopGainersParams holds the optional query parameters of ListTopGainers.
+// Zero values are left out so the server defaults apply.
+type ListTopGainersParams struct {
+	// Maximum movers to return (default 20, at most 100)
+	Limit int
+}
+
+// ListTopGainers lists the day's top gainers.
+//
+// GET /api/stocks/top-gainers
+func (c *Client) ListTopGainers(ctx context.Context, params *ListTopGainersParams) ([]TopMover, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+	}
+	var out []TopMover
+	if err := c.get(ctx, "/api/stocks/top-gainers", query, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTopLosersParams holds the optional query parameters of ListTopLosers.
+// Zero values are left out so the server defaults apply.
+type ListTopLosersParams struct {
+	// Maximum movers to return (default 20, at most 100)
+	Limit int
+}
+
+// ListTopLosers lists the day's top losers.
+//
+// GET /api/stocks/top-losers
+func (c *Client) ListTopLosers(ctx context.Context, params *ListTopLosersParams) ([]TopMover, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+	}
+	var out []TopMover
+	if err := c.get(ctx, "/api/stocks/top-losers", query, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SearchStocksParams holds the optional query parameters of SearchStocks.
+// Zero values are left out so the server defaults apply.
+type SearchStocksParams struct {
+	// Search text
+	Q string
+}
+
+// SearchStocks searches stocks by symbol or name.
+//
+// GET /api/stocks/search
+func (c *Client) SearchStocks(ctx context.Context, params *SearchStocksParams) ([]StockSearchResult, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Q != "" {
+			query.Set("q", params.Q)
+		}
+	}
+	var out []StockSearchResult
+	if err := c.get(ctx, "/api/stocks/search", query, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}